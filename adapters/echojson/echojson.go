@@ -0,0 +1,68 @@
+// Package echojson provides simdjson-based binding and rendering helpers
+// for echo handlers. Context is matched structurally by echo.Context, so
+// this module does not depend on echo:
+//
+//	func create(c echo.Context) error {
+//		var u User
+//		if err := echojson.Bind(c, &u); err != nil {
+//			return echo.NewHTTPError(httpjson.StatusCode(err), err.Error())
+//		}
+//		return echojson.JSON(c, http.StatusCreated, u)
+//	}
+//
+// To route c.Bind and c.JSON through simdjson as well, install an
+// echo.JSONSerializer. Its methods take an echo.Context, so the type has
+// to be declared where echo is imported:
+//
+//	type serializer struct{}
+//
+//	func (serializer) Serialize(c echo.Context, i interface{}, indent string) error {
+//		return echojson.Serialize(c.Response(), i)
+//	}
+//
+//	func (serializer) Deserialize(c echo.Context, i interface{}) error {
+//		return echojson.Deserialize(c.Request(), i)
+//	}
+//
+//	e.JSONSerializer = serializer{}
+package echojson
+
+import (
+	"io"
+	"net/http"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+	"github.com/biggeezerdevelopment/simdjson-go/httpjson"
+)
+
+// Context is the part of echo.Context the helpers use.
+type Context interface {
+	Request() *http.Request
+	JSONBlob(code int, b []byte) error
+}
+
+// Bind decodes the request body into v.
+func Bind(c Context, v interface{}) error {
+	return Deserialize(c.Request(), v)
+}
+
+// JSON sends v as a JSON response with status code.
+func JSON(c Context, code int, v interface{}) error {
+	b, err := simdjson.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.JSONBlob(code, b)
+}
+
+// Serialize writes v to w, for use in an echo.JSONSerializer. simdjson
+// has no indented output, so the serializer's indent argument is dropped.
+func Serialize(w io.Writer, v interface{}) error {
+	return simdjson.NewEncoder(w).Encode(v)
+}
+
+// Deserialize decodes the body of r into v with httpjson.DecodeRequest,
+// for use in an echo.JSONSerializer.
+func Deserialize(r *http.Request, v interface{}) error {
+	return httpjson.DecodeRequest(r, v)
+}
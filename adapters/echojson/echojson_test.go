@@ -0,0 +1,62 @@
+package echojson
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeContext implements the echo.Context methods Context needs.
+type fakeContext struct {
+	req  *http.Request
+	code int
+	body []byte
+}
+
+func (c *fakeContext) Request() *http.Request { return c.req }
+
+func (c *fakeContext) JSONBlob(code int, b []byte) error {
+	c.code, c.body = code, b
+	return nil
+}
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func TestBindAndJSON(t *testing.T) {
+	c := &fakeContext{req: httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"a"}`))}
+	var u user
+	if err := Bind(c, &u); err != nil || u.Name != "a" {
+		t.Errorf("Expected %q, got %q (%v)", "a", u.Name, err)
+	}
+
+	if err := JSON(c, http.StatusCreated, u); err != nil {
+		t.Fatal(err)
+	}
+	if c.code != http.StatusCreated || string(c.body) != `{"name":"a"}` {
+		t.Errorf("Unexpected response %d %s", c.code, c.body)
+	}
+	if err := JSON(c, http.StatusOK, make(chan int)); err == nil {
+		t.Error("Expected error for unsupported type")
+	}
+}
+
+func TestSerializer(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Serialize(&buf, user{Name: "a"}); err != nil || buf.String() != `{"name":"a"}` {
+		t.Errorf("Unexpected output %s (%v)", buf.String(), err)
+	}
+
+	var u user
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"b"}`))
+	if err := Deserialize(r, &u); err != nil || u.Name != "b" {
+		t.Errorf("Expected %q, got %q (%v)", "b", u.Name, err)
+	}
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name"`))
+	if err := Deserialize(r, &u); err == nil {
+		t.Error("Expected error for invalid body")
+	}
+}
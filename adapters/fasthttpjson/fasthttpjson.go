@@ -0,0 +1,43 @@
+// Package fasthttpjson decodes fasthttp request bodies and writes JSON
+// responses with simdjson. RequestCtx is matched structurally by
+// *fasthttp.RequestCtx, so this module does not depend on fasthttp:
+//
+//	func handler(ctx *fasthttp.RequestCtx) {
+//		var u User
+//		if err := fasthttpjson.Decode(ctx, &u); err != nil {
+//			ctx.Error(err.Error(), fasthttp.StatusBadRequest)
+//			return
+//		}
+//		fasthttpjson.Write(ctx, fasthttp.StatusOK, u)
+//	}
+package fasthttpjson
+
+import (
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+)
+
+// RequestCtx is the part of *fasthttp.RequestCtx the helpers use.
+type RequestCtx interface {
+	PostBody() []byte
+	SetContentType(contentType string)
+	SetStatusCode(statusCode int)
+	Write(p []byte) (int, error)
+}
+
+// Decode decodes the request body into v. fasthttp recycles the body once
+// the handler returns; Unmarshal copies strings out of it, so v stays
+// valid.
+func Decode(ctx RequestCtx, v interface{}) error {
+	return simdjson.Unmarshal(ctx.PostBody(), v)
+}
+
+// Write appends v to the response body as JSON and sets the status code
+// and Content-Type. If encoding fails, the response is left untouched.
+func Write(ctx RequestCtx, code int, v interface{}) error {
+	if err := simdjson.NewEncoder(ctx).Encode(v); err != nil {
+		return err
+	}
+	ctx.SetContentType("application/json; charset=utf-8")
+	ctx.SetStatusCode(code)
+	return nil
+}
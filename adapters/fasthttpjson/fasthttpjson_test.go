@@ -0,0 +1,62 @@
+package fasthttpjson
+
+import (
+	"testing"
+)
+
+// fakeCtx implements the *fasthttp.RequestCtx methods RequestCtx needs.
+type fakeCtx struct {
+	body        []byte
+	contentType string
+	code        int
+	out         []byte
+}
+
+func (c *fakeCtx) PostBody() []byte                  { return c.body }
+func (c *fakeCtx) SetContentType(contentType string) { c.contentType = contentType }
+func (c *fakeCtx) SetStatusCode(statusCode int)      { c.code = statusCode }
+
+func (c *fakeCtx) Write(p []byte) (int, error) {
+	c.out = append(c.out, p...)
+	return len(p), nil
+}
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func TestDecode(t *testing.T) {
+	ctx := &fakeCtx{body: []byte(`{"name":"a"}`)}
+	var u user
+	if err := Decode(ctx, &u); err != nil || u.Name != "a" {
+		t.Errorf("Expected %q, got %q (%v)", "a", u.Name, err)
+	}
+
+	// fasthttp reuses the body buffer after the handler returns
+	copy(ctx.body, `{"name":"z"}`)
+	if u.Name != "a" {
+		t.Errorf("Expected decoded string to be a copy, got %q", u.Name)
+	}
+
+	if err := Decode(&fakeCtx{}, &u); err == nil {
+		t.Error("Expected error for empty body")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	ctx := &fakeCtx{}
+	if err := Write(ctx, 201, user{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.code != 201 || ctx.contentType != "application/json; charset=utf-8" || string(ctx.out) != `{"name":"a"}` {
+		t.Errorf("Unexpected response %d %q %s", ctx.code, ctx.contentType, ctx.out)
+	}
+
+	ctx = &fakeCtx{}
+	if err := Write(ctx, 200, make(chan int)); err == nil {
+		t.Fatal("Expected error for unsupported type")
+	}
+	if ctx.code != 0 || len(ctx.out) != 0 {
+		t.Errorf("Expected untouched response, got %d %s", ctx.code, ctx.out)
+	}
+}
@@ -0,0 +1,499 @@
+package gatewayjson
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// decodeMessage stores the parsed object x in the message struct v. Its
+// errors are prefixed by Unmarshal.
+func (m *Marshaler) decodeMessage(x interface{}, v reflect.Value) error {
+	if kind := wellKnownKind(v.Type()); kind != notWellKnown {
+		return m.decodeWellKnown(x, kind, v)
+	}
+
+	obj, ok := x.(map[string]interface{})
+	if !ok {
+		return typeError(x, v.Type())
+	}
+	info := infoFor(v.Type())
+	for key, val := range obj {
+		if f, ok := info.lookup(key); ok {
+			if err := m.decodeField(val, v.Field(f.index)); err != nil {
+				return fieldError(key, err)
+			}
+			continue
+		}
+		if member, ok := info.oneofs[key]; ok {
+			if val == nil {
+				continue
+			}
+			wrapper := reflect.New(member.wrapper.Elem())
+			if err := m.decodeField(val, wrapper.Elem().Field(0)); err != nil {
+				return fieldError(key, err)
+			}
+			v.Field(member.index).Set(wrapper)
+			continue
+		}
+		if !m.DiscardUnknown {
+			if info.hasOneof() {
+				return fmt.Errorf("unknown field %q (decoding oneof members needs generated XXX_OneofWrappers)", key)
+			}
+			return fmt.Errorf("unknown field %q", key)
+		}
+	}
+	return nil
+}
+
+// lookup finds the non-oneof field named key in either naming style.
+func (info *messageInfo) lookup(key string) (field, bool) {
+	for _, f := range info.fields {
+		if !f.oneof && (f.jsonName == key || f.protoName == key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+func (info *messageInfo) hasOneof() bool {
+	for _, f := range info.fields {
+		if f.oneof {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldError(key string, err error) error {
+	return fmt.Errorf("field %q: %w", key, err)
+}
+
+func typeError(x interface{}, t reflect.Type) error {
+	return fmt.Errorf("cannot decode %s into %s", jsonKind(x), t)
+}
+
+func jsonKind(x interface{}) string {
+	switch x.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	return "number"
+}
+
+// decodeField stores the parsed value x in a field, list element or map
+// value v. A null clears the field.
+func (m *Marshaler) decodeField(x interface{}, v reflect.Value) error {
+	t := v.Type()
+	if x == nil {
+		v.Set(reflect.Zero(t))
+		return nil
+	}
+
+	switch {
+	case isMessage(t):
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return m.decodeMessage(x, v.Elem())
+	case isEnum(t):
+		return decodeEnum(x, v)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		// Scalar with explicit presence
+		p := reflect.New(t.Elem())
+		if err := m.decodeField(x, p.Elem()); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.Bool:
+		b, ok := x.(bool)
+		if !ok {
+			return typeError(x, t)
+		}
+		v.SetBool(b)
+	case reflect.Int32, reflect.Int64:
+		n, err := decodeInt(x, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint32, reflect.Uint64:
+		n, err := decodeUint(x, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := decodeFloat(x, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		s, ok := x.(string)
+		if !ok {
+			return typeError(x, t)
+		}
+		v.SetString(s)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			b, err := decodeBytes(x)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(b)
+			return nil
+		}
+		arr, ok := x.([]interface{})
+		if !ok {
+			return typeError(x, t)
+		}
+		s := reflect.MakeSlice(t, len(arr), len(arr))
+		for i, elem := range arr {
+			if elem == nil {
+				return errors.New("null in repeated field")
+			}
+			if err := m.decodeField(elem, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Map:
+		obj, ok := x.(map[string]interface{})
+		if !ok {
+			return typeError(x, t)
+		}
+		mv := reflect.MakeMapWithSize(t, len(obj))
+		for k, elem := range obj {
+			key := reflect.New(t.Key()).Elem()
+			if err := decodeMapKey(k, key); err != nil {
+				return err
+			}
+			val := reflect.New(t.Elem()).Elem()
+			if err := m.decodeField(elem, val); err != nil {
+				return err
+			}
+			mv.SetMapIndex(key, val)
+		}
+		v.Set(mv)
+	default:
+		return fmt.Errorf("unsupported field type %s", t)
+	}
+	return nil
+}
+
+// decodeMapKey parses a map key, which is always a JSON string.
+func decodeMapKey(k string, key reflect.Value) error {
+	switch key.Kind() {
+	case reflect.String:
+		key.SetString(k)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(k)
+		if err != nil || (k != "true" && k != "false") {
+			return fmt.Errorf("invalid map key %q", k)
+		}
+		key.SetBool(b)
+	case reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(k, 10, key.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid map key %q", k)
+		}
+		key.SetInt(n)
+	default:
+		n, err := strconv.ParseUint(k, 10, key.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid map key %q", k)
+		}
+		key.SetUint(n)
+	}
+	return nil
+}
+
+// decodeInt accepts integers as numbers, including integral floats like
+// 1e3, and as strings, the form 64-bit values are written in.
+func decodeInt(x interface{}, bits int) (int64, error) {
+	var n int64
+	switch x := x.(type) {
+	case int64:
+		n = x
+	case float64:
+		if x != math.Trunc(x) || x < math.MinInt64 || x >= math.MaxInt64 {
+			return 0, fmt.Errorf("invalid integer %v", x)
+		}
+		n = int64(x)
+	case string:
+		var err error
+		if n, err = strconv.ParseInt(x, 10, 64); err != nil {
+			f, ferr := strconv.ParseFloat(x, 64)
+			if ferr != nil {
+				return 0, fmt.Errorf("invalid integer %q", x)
+			}
+			return decodeInt(f, bits)
+		}
+	default:
+		return 0, fmt.Errorf("cannot decode %s into integer", jsonKind(x))
+	}
+	if bits < 64 && (n < -1<<(bits-1) || n >= 1<<(bits-1)) {
+		return 0, fmt.Errorf("integer %d overflows int%d", n, bits)
+	}
+	return n, nil
+}
+
+func decodeUint(x interface{}, bits int) (uint64, error) {
+	var n uint64
+	switch x := x.(type) {
+	case int64:
+		if x < 0 {
+			return 0, fmt.Errorf("invalid unsigned integer %d", x)
+		}
+		n = uint64(x)
+	case float64:
+		if x != math.Trunc(x) || x < 0 || x >= math.MaxUint64 {
+			return 0, fmt.Errorf("invalid unsigned integer %v", x)
+		}
+		n = uint64(x)
+	case string:
+		var err error
+		if n, err = strconv.ParseUint(x, 10, 64); err != nil {
+			f, ferr := strconv.ParseFloat(x, 64)
+			if ferr != nil {
+				return 0, fmt.Errorf("invalid unsigned integer %q", x)
+			}
+			return decodeUint(f, bits)
+		}
+	default:
+		return 0, fmt.Errorf("cannot decode %s into unsigned integer", jsonKind(x))
+	}
+	if bits < 64 && n >= 1<<bits {
+		return 0, fmt.Errorf("integer %d overflows uint%d", n, bits)
+	}
+	return n, nil
+}
+
+// decodeFloat accepts numbers and strings, including "NaN", "Infinity"
+// and "-Infinity".
+func decodeFloat(x interface{}, bits int) (float64, error) {
+	switch x := x.(type) {
+	case int64:
+		return float64(x), nil
+	case float64:
+		if bits == 32 && math.Abs(x) > math.MaxFloat32 {
+			return 0, fmt.Errorf("%v overflows float32", x)
+		}
+		return x, nil
+	case string:
+		switch x {
+		case "NaN":
+			return math.NaN(), nil
+		case "Infinity":
+			return math.Inf(1), nil
+		case "-Infinity":
+			return math.Inf(-1), nil
+		}
+		f, err := strconv.ParseFloat(x, bits)
+		if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+			return 0, fmt.Errorf("invalid number %q", x)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("cannot decode %s into number", jsonKind(x))
+}
+
+// decodeBytes accepts standard and URL-safe base64, padded or not.
+func decodeBytes(x interface{}) ([]byte, error) {
+	s, ok := x.(string)
+	if !ok {
+		return nil, fmt.Errorf("cannot decode %s into bytes", jsonKind(x))
+	}
+	enc := base64.StdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.URLEncoding
+	}
+	if len(s)%4 != 0 {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	b, err := enc.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 %q", s)
+	}
+	return b, nil
+}
+
+// decodeEnum accepts an enum value by name or number. Names are resolved
+// through the generated Descriptor method:
+//
+//	Descriptor().Values().ByName(name).Number()
+func decodeEnum(x interface{}, v reflect.Value) error {
+	if s, ok := x.(string); ok {
+		n, ok := enumNumber(v.Type(), s)
+		if !ok {
+			return fmt.Errorf("invalid value %q for enum %s", s, v.Type())
+		}
+		v.SetInt(n)
+		return nil
+	}
+	n, err := decodeInt(x, 32)
+	if err != nil {
+		return err
+	}
+	v.SetInt(n)
+	return nil
+}
+
+func enumNumber(t reflect.Type, name string) (int64, bool) {
+	desc := reflect.Zero(t).MethodByName("Descriptor")
+	if !desc.IsValid() {
+		return 0, false
+	}
+	values := call(desc.Call(nil)[0], "Values")
+	if !values.IsValid() {
+		return 0, false
+	}
+	byName := values.MethodByName("ByName")
+	if !byName.IsValid() || byName.Type().NumIn() != 1 || byName.Type().In(0).Kind() != reflect.String {
+		return 0, false
+	}
+	ev := byName.Call([]reflect.Value{reflect.ValueOf(name).Convert(byName.Type().In(0))})[0]
+	if ev.Kind() == reflect.Interface && ev.IsNil() {
+		return 0, false
+	}
+	number := call(ev, "Number")
+	if !number.IsValid() {
+		return 0, false
+	}
+	return number.Int(), true
+}
+
+// call invokes the niladic method name of v, or returns the zero Value.
+func call(v reflect.Value, name string) reflect.Value {
+	method := v.MethodByName(name)
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return reflect.Value{}
+	}
+	return method.Call(nil)[0]
+}
+
+// decodeWellKnown stores x in the message struct v of a well-known type.
+func (m *Marshaler) decodeWellKnown(x interface{}, kind wellKnown, v reflect.Value) error {
+	switch kind {
+	case wktTimestamp:
+		s, ok := x.(string)
+		if !ok {
+			return typeError(x, v.Type())
+		}
+		seconds, nanos, err := parseTimestamp(s)
+		if err != nil {
+			return err
+		}
+		v.FieldByName("Seconds").SetInt(seconds)
+		v.FieldByName("Nanos").SetInt(nanos)
+	case wktDuration:
+		s, ok := x.(string)
+		if !ok {
+			return typeError(x, v.Type())
+		}
+		seconds, nanos, err := parseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.FieldByName("Seconds").SetInt(seconds)
+		v.FieldByName("Nanos").SetInt(nanos)
+	case wktWrapper:
+		return m.decodeField(x, v.FieldByName("Value"))
+	case wktFieldMask:
+		s, ok := x.(string)
+		if !ok {
+			return typeError(x, v.Type())
+		}
+		var paths []string
+		if s != "" {
+			for _, p := range strings.Split(s, ",") {
+				paths = append(paths, snakeCase(p))
+			}
+		}
+		v.FieldByName("Paths").Set(reflect.ValueOf(paths))
+	case wktEmpty:
+		if obj, ok := x.(map[string]interface{}); !ok || len(obj) != 0 {
+			return errors.New("google.protobuf.Empty must be {}")
+		}
+	case wktStruct, wktValue, wktListValue:
+		return errors.New("decoding google.protobuf.Struct, Value and ListValue is not supported")
+	case wktAny:
+		return errors.New("google.protobuf.Any is not supported")
+	}
+	return nil
+}
+
+// parseTimestamp parses an RFC 3339 timestamp with any UTC offset.
+func parseTimestamp(s string) (int64, int64, error) {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid google.protobuf.Timestamp %q", s)
+	}
+	seconds := t.Unix()
+	if seconds < minTimestampSeconds || seconds > maxTimestampSeconds {
+		return 0, 0, fmt.Errorf("google.protobuf.Timestamp %q out of range", s)
+	}
+	return seconds, int64(t.Nanosecond()), nil
+}
+
+// parseDuration parses seconds with up to 9 fractional digits and an "s"
+// suffix, such as "-1.5s".
+func parseDuration(s string) (int64, int64, error) {
+	invalid := fmt.Errorf("invalid google.protobuf.Duration %q", s)
+	num, ok := strings.CutSuffix(s, "s")
+	if !ok || num == "" {
+		return 0, 0, invalid
+	}
+	neg := strings.HasPrefix(num, "-")
+	num = strings.TrimPrefix(num, "-")
+
+	whole, frac, _ := strings.Cut(num, ".")
+	if whole == "" || len(frac) > 9 || strings.ContainsAny(whole+frac, "+-") {
+		return 0, 0, invalid
+	}
+	seconds, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || seconds > maxDurationSeconds {
+		return 0, 0, invalid
+	}
+	var nanos int64
+	if frac != "" {
+		if nanos, err = strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64); err != nil {
+			return 0, 0, invalid
+		}
+	}
+	if neg {
+		seconds, nanos = -seconds, -nanos
+	}
+	return seconds, nanos, nil
+}
+
+// snakeCase converts a lowerCamelCase field path to snake_case.
+func snakeCase(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' {
+			sb.WriteByte('_')
+			c += 'a' - 'A'
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
@@ -0,0 +1,443 @@
+package gatewayjson
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+)
+
+// value adapts any Go value to simdjson.MarshalerTo, so that messages are
+// written with the proto3 mapping from inside simdjson's pooled encoder.
+type value struct {
+	m *Marshaler
+	v reflect.Value
+}
+
+func (x value) MarshalJSONTo(w *simdjson.Writer) error {
+	return x.m.encodeAny(w, x.v)
+}
+
+// encodeAny encodes v, which may or may not be a message.
+func (m *Marshaler) encodeAny(w *simdjson.Writer, v reflect.Value) error {
+	if !v.IsValid() {
+		w.Null()
+		return nil
+	}
+	if isMessage(v.Type()) {
+		if v.IsNil() {
+			w.RawString("{}")
+			return nil
+		}
+		return m.encodeMessage(w, v.Elem())
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			w.Null()
+			return nil
+		}
+		return m.encodeAny(w, v.Elem())
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		w.RawByte('{')
+		iter := v.MapRange()
+		for i := 0; iter.Next(); i++ {
+			if i > 0 {
+				w.RawByte(',')
+			}
+			w.String(iter.Key().String())
+			w.RawByte(':')
+			if err := m.encodeAny(w, iter.Value()); err != nil {
+				return err
+			}
+		}
+		w.RawByte('}')
+		return nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		w.RawByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				w.RawByte(',')
+			}
+			if err := m.encodeAny(w, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		w.RawByte(']')
+		return nil
+	}
+	return w.Value(v.Interface())
+}
+
+// encodeMessage encodes the message struct v.
+func (m *Marshaler) encodeMessage(w *simdjson.Writer, v reflect.Value) error {
+	if kind := wellKnownKind(v.Type()); kind != notWellKnown {
+		return m.encodeWellKnown(w, kind, v)
+	}
+
+	w.RawByte('{')
+	first := true
+	for _, f := range infoFor(v.Type()).fields {
+		fv := v.Field(f.index)
+		if f.oneof {
+			if fv.IsNil() {
+				continue
+			}
+			// The interface holds a pointer to a wrapper struct whose only
+			// field is the member that is set
+			wrapper := fv.Elem().Elem()
+			f = parseTag(wrapper.Type().Field(0).Tag.Get("protobuf"))
+			fv = wrapper.Field(0)
+		} else if !m.EmitUnpopulated && !populated(fv) {
+			continue
+		}
+
+		if !first {
+			w.RawByte(',')
+		}
+		first = false
+		if m.UseProtoNames {
+			w.String(f.protoName)
+		} else {
+			w.String(f.jsonName)
+		}
+		w.RawByte(':')
+		if err := m.encodeField(w, fv); err != nil {
+			return err
+		}
+	}
+	w.RawByte('}')
+	return nil
+}
+
+// populated reports whether a field is set in proto3 terms. Fields with
+// explicit presence are pointers and count as set even at a zero value.
+func populated(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return !v.IsNil()
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() > 0
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int32, reflect.Int64:
+		return v.Int() != 0
+	case reflect.Uint32, reflect.Uint64:
+		return v.Uint() != 0
+	case reflect.Float32, reflect.Float64:
+		// -0 is set
+		return v.Float() != 0 || math.Signbit(v.Float())
+	}
+	return true
+}
+
+// encodeField encodes the value of a message field, list element or map
+// value.
+func (m *Marshaler) encodeField(w *simdjson.Writer, v reflect.Value) error {
+	t := v.Type()
+	switch {
+	case isMessage(t):
+		if v.IsNil() {
+			w.Null()
+			return nil
+		}
+		return m.encodeMessage(w, v.Elem())
+	case isEnum(t):
+		return m.encodeEnum(w, v)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		// Scalar with explicit presence
+		if v.IsNil() {
+			w.Null()
+			return nil
+		}
+		return m.encodeField(w, v.Elem())
+	case reflect.Bool:
+		w.Bool(v.Bool())
+	case reflect.Int32:
+		w.Int(v.Int())
+	case reflect.Uint32:
+		w.Uint(v.Uint())
+	case reflect.Int64:
+		w.RawByte('"')
+		w.Int(v.Int())
+		w.RawByte('"')
+	case reflect.Uint64:
+		w.RawByte('"')
+		w.Uint(v.Uint())
+		w.RawByte('"')
+	case reflect.Float32:
+		return encodeFloat(w, v.Float(), 32)
+	case reflect.Float64:
+		return encodeFloat(w, v.Float(), 64)
+	case reflect.String:
+		w.String(v.String())
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			if v.IsNil() {
+				w.RawString(`""`)
+			} else {
+				w.Bytes(v.Bytes())
+			}
+			return nil
+		}
+		w.RawByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				w.RawByte(',')
+			}
+			if err := m.encodeField(w, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		w.RawByte(']')
+	case reflect.Map:
+		w.RawByte('{')
+		iter := v.MapRange()
+		for i := 0; iter.Next(); i++ {
+			if i > 0 {
+				w.RawByte(',')
+			}
+			// Map keys of every type are written as strings
+			k := iter.Key()
+			switch k.Kind() {
+			case reflect.String:
+				w.String(k.String())
+			case reflect.Bool:
+				w.String(strconv.FormatBool(k.Bool()))
+			case reflect.Int32, reflect.Int64:
+				w.String(strconv.FormatInt(k.Int(), 10))
+			default:
+				w.String(strconv.FormatUint(k.Uint(), 10))
+			}
+			w.RawByte(':')
+			if err := m.encodeField(w, iter.Value()); err != nil {
+				return err
+			}
+		}
+		w.RawByte('}')
+	default:
+		return fmt.Errorf("gatewayjson: unsupported field type %s", t)
+	}
+	return nil
+}
+
+// encodeFloat writes f, with NaN and the infinities as the strings the
+// proto3 mapping uses for them.
+func encodeFloat(w *simdjson.Writer, f float64, bitSize int) error {
+	switch {
+	case math.IsNaN(f):
+		w.RawString(`"NaN"`)
+	case math.IsInf(f, 1):
+		w.RawString(`"Infinity"`)
+	case math.IsInf(f, -1):
+		w.RawString(`"-Infinity"`)
+	default:
+		return w.Float(f, bitSize)
+	}
+	return nil
+}
+
+func (m *Marshaler) encodeEnum(w *simdjson.Writer, v reflect.Value) error {
+	if !m.UseEnumNumbers {
+		// String returns the number for values the enum does not define,
+		// which are then written as numbers too
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			if name := s.String(); name != strconv.FormatInt(v.Int(), 10) {
+				w.String(name)
+				return nil
+			}
+		}
+	}
+	w.Int(v.Int())
+	return nil
+}
+
+// encodeWellKnown encodes the message struct v of a well-known type.
+func (m *Marshaler) encodeWellKnown(w *simdjson.Writer, kind wellKnown, v reflect.Value) error {
+	switch kind {
+	case wktTimestamp:
+		s, err := formatTimestamp(v.FieldByName("Seconds").Int(), v.FieldByName("Nanos").Int())
+		if err != nil {
+			return err
+		}
+		w.String(s)
+	case wktDuration:
+		s, err := formatDuration(v.FieldByName("Seconds").Int(), v.FieldByName("Nanos").Int())
+		if err != nil {
+			return err
+		}
+		w.String(s)
+	case wktWrapper:
+		return m.encodeField(w, v.FieldByName("Value"))
+	case wktFieldMask:
+		paths := v.FieldByName("Paths")
+		var sb strings.Builder
+		for i := 0; i < paths.Len(); i++ {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(lowerCamel(paths.Index(i).String()))
+		}
+		w.String(sb.String())
+	case wktEmpty:
+		w.RawString("{}")
+	case wktStruct:
+		return m.encodeStructFields(w, v.FieldByName("Fields"))
+	case wktListValue:
+		values := v.FieldByName("Values")
+		w.RawByte('[')
+		for i := 0; i < values.Len(); i++ {
+			if i > 0 {
+				w.RawByte(',')
+			}
+			if err := m.encodeStructValue(w, values.Index(i)); err != nil {
+				return err
+			}
+		}
+		w.RawByte(']')
+	case wktValue:
+		return m.encodeStructValue(w, v.Addr())
+	case wktAny:
+		return errors.New("gatewayjson: google.protobuf.Any is not supported")
+	}
+	return nil
+}
+
+// encodeStructFields encodes the map[string]*structpb.Value of a Struct.
+func (m *Marshaler) encodeStructFields(w *simdjson.Writer, fields reflect.Value) error {
+	w.RawByte('{')
+	iter := fields.MapRange()
+	for i := 0; iter.Next(); i++ {
+		if i > 0 {
+			w.RawByte(',')
+		}
+		w.String(iter.Key().String())
+		w.RawByte(':')
+		if err := m.encodeStructValue(w, iter.Value()); err != nil {
+			return err
+		}
+	}
+	w.RawByte('}')
+	return nil
+}
+
+// encodeStructValue encodes a *structpb.Value, whose Kind oneof holds one
+// of the wrappers Value_NullValue, Value_NumberValue, Value_StringValue,
+// Value_BoolValue, Value_StructValue or Value_ListValue.
+func (m *Marshaler) encodeStructValue(w *simdjson.Writer, v reflect.Value) error {
+	if v.IsNil() {
+		w.Null()
+		return nil
+	}
+	kind := v.Elem().FieldByName("Kind")
+	if kind.IsNil() {
+		return errors.New("gatewayjson: google.protobuf.Value has no kind set")
+	}
+	wrapper := kind.Elem().Elem()
+	x := wrapper.Field(0)
+	switch wrapper.Type().Field(0).Name {
+	case "NullValue":
+		w.Null()
+	case "NumberValue":
+		f := x.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return errors.New("gatewayjson: google.protobuf.Value cannot hold NaN or infinity")
+		}
+		return w.Float(f, 64)
+	case "StringValue":
+		w.String(x.String())
+	case "BoolValue":
+		w.Bool(x.Bool())
+	case "StructValue":
+		if x.IsNil() {
+			w.RawString("{}")
+			return nil
+		}
+		return m.encodeStructFields(w, x.Elem().FieldByName("Fields"))
+	case "ListValue":
+		if x.IsNil() {
+			w.RawString("[]")
+			return nil
+		}
+		return m.encodeWellKnown(w, wktListValue, x.Elem())
+	}
+	return nil
+}
+
+// Valid range of Timestamp: 0001-01-01T00:00:00Z to 9999-12-31T23:59:59Z.
+const (
+	minTimestampSeconds = -62135596800
+	maxTimestampSeconds = 253402300799
+	maxDurationSeconds  = 315576000000
+)
+
+// formatTimestamp formats a Timestamp in RFC 3339 form in UTC, with 0, 3,
+// 6 or 9 fractional digits.
+func formatTimestamp(seconds, nanos int64) (string, error) {
+	if seconds < minTimestampSeconds || seconds > maxTimestampSeconds || nanos < 0 || nanos > 999999999 {
+		return "", errors.New("gatewayjson: google.protobuf.Timestamp out of range")
+	}
+	s := time.Unix(seconds, 0).UTC().Format("2006-01-02T15:04:05")
+	return s + formatNanos(nanos) + "Z", nil
+}
+
+// formatDuration formats a Duration as seconds with an "s" suffix and 0,
+// 3, 6 or 9 fractional digits.
+func formatDuration(seconds, nanos int64) (string, error) {
+	if seconds < -maxDurationSeconds || seconds > maxDurationSeconds || nanos <= -1e9 || nanos >= 1e9 ||
+		(seconds > 0 && nanos < 0) || (seconds < 0 && nanos > 0) {
+		return "", errors.New("gatewayjson: google.protobuf.Duration out of range")
+	}
+	sign := ""
+	if seconds < 0 || nanos < 0 {
+		sign = "-"
+		seconds, nanos = -seconds, -nanos
+	}
+	return sign + strconv.FormatInt(seconds, 10) + formatNanos(nanos) + "s", nil
+}
+
+func formatNanos(nanos int64) string {
+	switch {
+	case nanos == 0:
+		return ""
+	case nanos%1e6 == 0:
+		return fmt.Sprintf(".%03d", nanos/1e6)
+	case nanos%1e3 == 0:
+		return fmt.Sprintf(".%06d", nanos/1e3)
+	}
+	return fmt.Sprintf(".%09d", nanos)
+}
+
+// lowerCamel converts a snake_case field path to lowerCamelCase.
+func lowerCamel(s string) string {
+	var sb strings.Builder
+	upper := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '_' {
+			upper = true
+			continue
+		}
+		if upper && 'a' <= c && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upper = false
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
@@ -0,0 +1,161 @@
+package gatewayjson
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// field describes a field of a generated message struct, parsed from its
+// protobuf struct tag:
+//
+//	Name string `protobuf:"bytes,2,opt,name=user_name,json=userName,proto3"`
+type field struct {
+	index     int
+	protoName string
+	jsonName  string
+	oneof     bool // an interface field holding one of the oneof's wrappers
+}
+
+// messageInfo lists the fields of a message type and, for decoding, the
+// members of its oneofs.
+type messageInfo struct {
+	fields []field
+	oneofs map[string]oneofMember // by JSON and proto name
+}
+
+// oneofMember is one case of a oneof: the wrapper struct that is stored in
+// the interface field, and the wrapper's only field.
+type oneofMember struct {
+	index   int          // of the interface field in the message
+	wrapper reflect.Type // pointer to the wrapper struct
+	field   field
+}
+
+var messageInfos sync.Map // map[reflect.Type]*messageInfo
+
+// infoFor returns the fields of message struct type t.
+func infoFor(t reflect.Type) *messageInfo {
+	if info, ok := messageInfos.Load(t); ok {
+		return info.(*messageInfo)
+	}
+
+	info := &messageInfo{oneofs: make(map[string]oneofMember)}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		if name := sf.Tag.Get("protobuf_oneof"); name != "" {
+			info.fields = append(info.fields, field{index: i, protoName: name, jsonName: name, oneof: true})
+			continue
+		}
+		if tag := sf.Tag.Get("protobuf"); tag != "" {
+			f := parseTag(tag)
+			f.index = i
+			info.fields = append(info.fields, f)
+		}
+	}
+
+	// Generated code of older protoc-gen-go versions lists the oneof
+	// wrappers; newer versions keep them in the descriptor only
+	if m, ok := reflect.PointerTo(t).MethodByName("XXX_OneofWrappers"); ok {
+		wrappers := m.Func.Call([]reflect.Value{reflect.New(t)})[0].Interface().([]interface{})
+		for _, w := range wrappers {
+			wt := reflect.TypeOf(w)
+			member := oneofMember{wrapper: wt, field: parseTag(wt.Elem().Field(0).Tag.Get("protobuf"))}
+			for _, f := range info.fields {
+				if f.oneof && wt.Implements(t.Field(f.index).Type) {
+					member.index = f.index
+				}
+			}
+			info.oneofs[member.field.jsonName] = member
+			info.oneofs[member.field.protoName] = member
+		}
+	}
+
+	actual, _ := messageInfos.LoadOrStore(t, info)
+	return actual.(*messageInfo)
+}
+
+func parseTag(tag string) field {
+	var f field
+	for _, opt := range strings.Split(tag, ",") {
+		if name, ok := strings.CutPrefix(opt, "name="); ok {
+			f.protoName = name
+		} else if name, ok := strings.CutPrefix(opt, "json="); ok {
+			f.jsonName = name
+		}
+	}
+	// protoc-gen-go leaves json= out when it equals the name
+	if f.jsonName == "" {
+		f.jsonName = f.protoName
+	}
+	return f
+}
+
+// isMessage reports whether t is a pointer to a generated message struct.
+func isMessage(t reflect.Type) bool {
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	_, ok := t.MethodByName("ProtoReflect")
+	if !ok {
+		// Messages generated before the protobuf APIv2
+		_, ok = t.MethodByName("ProtoMessage")
+	}
+	return ok
+}
+
+// isEnum reports whether t is a generated enum type.
+func isEnum(t reflect.Type) bool {
+	if t.Kind() != reflect.Int32 {
+		return false
+	}
+	_, ok := t.MethodByName("Number")
+	return ok
+}
+
+// wellKnown identifies the well-known types that have a special JSON form.
+type wellKnown int
+
+const (
+	notWellKnown wellKnown = iota
+	wktTimestamp
+	wktDuration
+	wktStruct
+	wktValue
+	wktListValue
+	wktFieldMask
+	wktEmpty
+	wktWrapper
+	wktAny
+)
+
+// wellKnownTypes maps the Go package path and name of the well-known
+// types to their kind. Types of the github.com/golang/protobuf/ptypes
+// packages are aliases of these.
+var wellKnownTypes = map[string]wellKnown{
+	"google.golang.org/protobuf/types/known/timestamppb.Timestamp":  wktTimestamp,
+	"google.golang.org/protobuf/types/known/durationpb.Duration":    wktDuration,
+	"google.golang.org/protobuf/types/known/structpb.Struct":        wktStruct,
+	"google.golang.org/protobuf/types/known/structpb.Value":         wktValue,
+	"google.golang.org/protobuf/types/known/structpb.ListValue":     wktListValue,
+	"google.golang.org/protobuf/types/known/fieldmaskpb.FieldMask":  wktFieldMask,
+	"google.golang.org/protobuf/types/known/emptypb.Empty":          wktEmpty,
+	"google.golang.org/protobuf/types/known/anypb.Any":              wktAny,
+	"google.golang.org/protobuf/types/known/wrapperspb.DoubleValue": wktWrapper,
+	"google.golang.org/protobuf/types/known/wrapperspb.FloatValue":  wktWrapper,
+	"google.golang.org/protobuf/types/known/wrapperspb.Int64Value":  wktWrapper,
+	"google.golang.org/protobuf/types/known/wrapperspb.UInt64Value": wktWrapper,
+	"google.golang.org/protobuf/types/known/wrapperspb.Int32Value":  wktWrapper,
+	"google.golang.org/protobuf/types/known/wrapperspb.UInt32Value": wktWrapper,
+	"google.golang.org/protobuf/types/known/wrapperspb.BoolValue":   wktWrapper,
+	"google.golang.org/protobuf/types/known/wrapperspb.StringValue": wktWrapper,
+	"google.golang.org/protobuf/types/known/wrapperspb.BytesValue":  wktWrapper,
+}
+
+// wellKnownKind returns the kind of message struct type t.
+func wellKnownKind(t reflect.Type) wellKnown {
+	return wellKnownTypes[t.PkgPath()+"."+t.Name()]
+}
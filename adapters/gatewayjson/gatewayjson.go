@@ -0,0 +1,226 @@
+// Package gatewayjson is a simdjson-backed replacement for grpc-gateway's
+// JSONPb marshaler. It follows the proto3 JSON mapping for messages
+// generated by protoc-gen-go: lowerCamelCase field names, 64-bit integers
+// as strings, enums by name, base64 bytes, NaN and infinities as strings,
+// and the JSON forms of the well-known types Timestamp, Duration, Struct,
+// Value, ListValue, FieldMask, Empty and the wrappers.
+//
+// Messages are handled through reflection over the generated structs and
+// their protobuf struct tags, so this module does not depend on
+// google.golang.org/protobuf or grpc-gateway. The limits that follow from
+// that: google.protobuf.Any is not supported, and decoding oneof fields and
+// Struct, Value and ListValue needs the oneof wrapper types, which only
+// messages generated with an XXX_OneofWrappers method expose.
+//
+// grpc-gateway's runtime.Marshaler interface returns its own Decoder and
+// Encoder types, so register the marshaler through a small wrapper
+// declared next to your runtime import:
+//
+//	type marshaler struct{ *gatewayjson.Marshaler }
+//
+//	func (m marshaler) NewDecoder(r io.Reader) runtime.Decoder { return m.Marshaler.NewDecoder(r) }
+//	func (m marshaler) NewEncoder(w io.Writer) runtime.Encoder { return m.Marshaler.NewEncoder(w) }
+//
+//	mux := runtime.NewServeMux(
+//		runtime.WithMarshalerOption(runtime.MIMEWildcard, marshaler{&gatewayjson.Marshaler{}}),
+//	)
+package gatewayjson
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+)
+
+// Marshaler encodes and decodes protoc-gen-go messages following the
+// proto3 JSON mapping. The zero value matches grpc-gateway's default
+// JSONPb marshaler options.
+type Marshaler struct {
+	// UseProtoNames emits the field names of the .proto file (user_id)
+	// instead of their lowerCamelCase JSON names (userId). Both are
+	// accepted when decoding either way.
+	UseProtoNames bool
+
+	// UseEnumNumbers emits enum values as numbers instead of names.
+	UseEnumNumbers bool
+
+	// EmitUnpopulated emits fields holding their zero value, which are
+	// omitted by default.
+	EmitUnpopulated bool
+
+	// DiscardUnknown ignores unknown fields when decoding instead of
+	// failing.
+	DiscardUnknown bool
+}
+
+// ContentType returns the content type of the encoded messages.
+func (m *Marshaler) ContentType(v interface{}) string {
+	return "application/json"
+}
+
+// Delimiter returns the separator grpc-gateway writes between the
+// messages of a stream.
+func (m *Marshaler) Delimiter() []byte {
+	return []byte("\n")
+}
+
+// Marshal encodes v. Values that are not messages, such as the maps
+// grpc-gateway wraps stream errors in, are encoded like simdjson.Marshal
+// would, with any messages inside them following the proto3 mapping.
+func (m *Marshaler) Marshal(v interface{}) ([]byte, error) {
+	return simdjson.Marshal(value{m: m, v: reflect.ValueOf(v)})
+}
+
+// Unmarshal decodes data into v, which must be a pointer to a message or,
+// for other types, anything simdjson.Unmarshal accepts.
+func (m *Marshaler) Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if !isMessage(rv.Type()) {
+		return simdjson.Unmarshal(data, v)
+	}
+	if rv.IsNil() {
+		return errors.New("gatewayjson: unmarshal into nil message")
+	}
+
+	var tree interface{}
+	if err := simdjson.Unmarshal(data, &tree); err != nil {
+		return err
+	}
+	if tree == nil {
+		return errors.New("gatewayjson: unexpected null message")
+	}
+	if err := m.decodeMessage(tree, rv.Elem()); err != nil {
+		return fmt.Errorf("gatewayjson: %w", err)
+	}
+	return nil
+}
+
+// NewDecoder returns a Decoder reading a stream of JSON values from r.
+func (m *Marshaler) NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{m: m, r: bufio.NewReader(r)}
+}
+
+// NewEncoder returns an Encoder writing to w.
+func (m *Marshaler) NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{m: m, w: w}
+}
+
+// Decoder decodes consecutive JSON values from a stream, as grpc-gateway
+// does for client-streaming calls.
+type Decoder struct {
+	m   *Marshaler
+	r   *bufio.Reader
+	buf []byte
+}
+
+// Decode reads the next JSON value from the stream and decodes it into v.
+// At the end of the stream it returns io.EOF.
+func (d *Decoder) Decode(v interface{}) error {
+	raw, err := d.next()
+	if err != nil {
+		return err
+	}
+	return d.m.Unmarshal(raw, v)
+}
+
+// next reads one complete JSON value. It only frames the value; syntax
+// errors inside it are left to Unmarshal.
+func (d *Decoder) next() ([]byte, error) {
+	d.buf = d.buf[:0]
+
+	c, err := d.skipSpace()
+	if err != nil {
+		return nil, err
+	}
+	d.buf = append(d.buf, c)
+
+	switch c {
+	case '{', '[':
+		depth, inString, escaped := 1, false, false
+		for depth > 0 {
+			c, err := d.r.ReadByte()
+			if err != nil {
+				return nil, io.ErrUnexpectedEOF
+			}
+			d.buf = append(d.buf, c)
+			switch {
+			case escaped:
+				escaped = false
+			case inString:
+				escaped = c == '\\'
+				inString = c != '"'
+			case c == '"':
+				inString = true
+			case c == '{' || c == '[':
+				depth++
+			case c == '}' || c == ']':
+				depth--
+			}
+		}
+	case '"':
+		escaped := false
+		for {
+			c, err := d.r.ReadByte()
+			if err != nil {
+				return nil, io.ErrUnexpectedEOF
+			}
+			d.buf = append(d.buf, c)
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				break
+			}
+		}
+	default:
+		// Numbers and literals end at whitespace, a delimiter or EOF
+		for {
+			c, err := d.r.ReadByte()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if isSpace(c) || c == ',' || c == ']' || c == '}' {
+				d.r.UnreadByte()
+				break
+			}
+			d.buf = append(d.buf, c)
+		}
+	}
+	return d.buf, nil
+}
+
+func (d *Decoder) skipSpace() (byte, error) {
+	for {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !isSpace(c) {
+			return c, nil
+		}
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// Encoder writes JSON values to a stream.
+type Encoder struct {
+	m *Marshaler
+	w io.Writer
+}
+
+// Encode writes the encoding of v. grpc-gateway writes the Delimiter
+// between stream messages itself.
+func (e *Encoder) Encode(v interface{}) error {
+	return simdjson.NewEncoder(e.w).Encode(value{m: e.m, v: reflect.ValueOf(v)})
+}
@@ -0,0 +1,475 @@
+package gatewayjson
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The types below mimic what protoc-gen-go generates: protobuf struct
+// tags, unexported internal state, a ProtoReflect method and, for enums,
+// a Descriptor chain resolving names.
+
+type status int32
+
+const (
+	statusUnknown status = 0
+	statusActive  status = 1
+)
+
+var statusNames = map[status]string{statusUnknown: "STATUS_UNKNOWN", statusActive: "STATUS_ACTIVE"}
+
+func (s status) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return strconv.Itoa(int(s))
+}
+
+func (s status) Number() int32 { return int32(s) }
+
+func (status) Descriptor() enumDescriptor { return enumDescriptor{} }
+
+type enumName string
+
+type enumDescriptor struct{}
+
+func (enumDescriptor) Values() enumValues { return enumValues{} }
+
+type enumValues struct{}
+
+type enumValue interface{ Number() int32 }
+
+func (enumValues) ByName(name enumName) enumValue {
+	for s, n := range statusNames {
+		if n == string(name) {
+			return s
+		}
+	}
+	return nil
+}
+
+type user struct {
+	state    int
+	Id       int64            `protobuf:"varint,1,opt,name=id,proto3"`
+	UserName string           `protobuf:"bytes,2,opt,name=user_name,json=userName,proto3"`
+	Score    float64          `protobuf:"fixed64,3,opt,name=score,proto3"`
+	Status   status           `protobuf:"varint,4,opt,name=status,proto3,enum=test.Status"`
+	Avatar   []byte           `protobuf:"bytes,5,opt,name=avatar,proto3"`
+	Tags     []string         `protobuf:"bytes,6,rep,name=tags,proto3"`
+	Counts   map[int32]uint64 `protobuf:"bytes,7,rep,name=counts,proto3"`
+	Created  *timestamp       `protobuf:"bytes,8,opt,name=created,proto3"`
+	Ttl      *duration        `protobuf:"bytes,9,opt,name=ttl,proto3"`
+	Nickname *string          `protobuf:"bytes,10,opt,name=nickname,proto3,oneof"`
+	Friend   *user            `protobuf:"bytes,11,opt,name=friend,proto3"`
+	Limit    *int64Value      `protobuf:"bytes,12,opt,name=limit,proto3"`
+	Mask     *fieldMask       `protobuf:"bytes,13,opt,name=mask,proto3"`
+	Meta     *structMsg       `protobuf:"bytes,14,opt,name=meta,proto3"`
+	Contact  isUserContact    `protobuf_oneof:"contact"`
+}
+
+func (*user) ProtoReflect() {}
+
+func (*user) XXX_OneofWrappers() []interface{} {
+	return []interface{}{(*userEmail)(nil), (*userPhone)(nil)}
+}
+
+type isUserContact interface{ isUserContact() }
+
+type userEmail struct {
+	Email string `protobuf:"bytes,20,opt,name=email,proto3,oneof"`
+}
+
+type userPhone struct {
+	Phone int64 `protobuf:"varint,21,opt,name=phone_number,json=phoneNumber,proto3,oneof"`
+}
+
+func (*userEmail) isUserContact() {}
+func (*userPhone) isUserContact() {}
+
+type timestamp struct {
+	Seconds int64 `protobuf:"varint,1,opt,name=seconds,proto3"`
+	Nanos   int32 `protobuf:"varint,2,opt,name=nanos,proto3"`
+}
+
+type duration struct {
+	Seconds int64 `protobuf:"varint,1,opt,name=seconds,proto3"`
+	Nanos   int32 `protobuf:"varint,2,opt,name=nanos,proto3"`
+}
+
+type int64Value struct {
+	Value int64 `protobuf:"varint,1,opt,name=value,proto3"`
+}
+
+type fieldMask struct {
+	Paths []string `protobuf:"bytes,1,rep,name=paths,proto3"`
+}
+
+type structMsg struct {
+	Fields map[string]*structValue `protobuf:"bytes,1,rep,name=fields,proto3"`
+}
+
+type listValue struct {
+	Values []*structValue `protobuf:"bytes,1,rep,name=values,proto3"`
+}
+
+type structValue struct {
+	Kind isValueKind `protobuf_oneof:"kind"`
+}
+
+type isValueKind interface{ isValueKind() }
+
+type valueNull struct{ NullValue int32 }
+type valueNumber struct{ NumberValue float64 }
+type valueString struct{ StringValue string }
+type valueList struct{ ListValue *listValue }
+
+func (*valueNull) isValueKind()   {}
+func (*valueNumber) isValueKind() {}
+func (*valueString) isValueKind() {}
+func (*valueList) isValueKind()   {}
+
+func (*timestamp) ProtoReflect()   {}
+func (*duration) ProtoReflect()    {}
+func (*int64Value) ProtoReflect()  {}
+func (*fieldMask) ProtoReflect()   {}
+func (*structMsg) ProtoReflect()   {}
+func (*listValue) ProtoReflect()   {}
+func (*structValue) ProtoReflect() {}
+
+func init() {
+	for v, kind := range map[interface{}]wellKnown{
+		(*timestamp)(nil):   wktTimestamp,
+		(*duration)(nil):    wktDuration,
+		(*int64Value)(nil):  wktWrapper,
+		(*fieldMask)(nil):   wktFieldMask,
+		(*structMsg)(nil):   wktStruct,
+		(*listValue)(nil):   wktListValue,
+		(*structValue)(nil): wktValue,
+	} {
+		t := reflect.TypeOf(v).Elem()
+		wellKnownTypes[t.PkgPath()+"."+t.Name()] = kind
+	}
+}
+
+func str(s string) *string { return &s }
+
+func fullUser() *user {
+	return &user{
+		Id:       9007199254740993,
+		UserName: "ann",
+		Score:    1.5,
+		Status:   statusActive,
+		Avatar:   []byte{0xfb, 0xff},
+		Tags:     []string{"a", "b"},
+		Counts:   map[int32]uint64{-1: 2},
+		Created:  &timestamp{Seconds: 1700000000, Nanos: 5000000},
+		Ttl:      &duration{Seconds: -1, Nanos: -500000000},
+		Nickname: str(""),
+		Friend:   &user{UserName: "bob"},
+		Limit:    &int64Value{Value: 7},
+		Mask:     &fieldMask{Paths: []string{"user_name", "id"}},
+		Contact:  &userPhone{Phone: 5},
+	}
+}
+
+const fullUserJSON = `{"id":"9007199254740993","userName":"ann","score":1.5,"status":"STATUS_ACTIVE",` +
+	`"avatar":"+/8=","tags":["a","b"],"counts":{"-1":"2"},"created":"2023-11-14T22:13:20.005Z",` +
+	`"ttl":"-1.500s","nickname":"","friend":{"userName":"bob"},"limit":"7","mask":"userName,id",` +
+	`"phoneNumber":"5"}`
+
+func TestMarshal(t *testing.T) {
+	m := &Marshaler{}
+	got, err := m.Marshal(fullUser())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != fullUserJSON {
+		t.Errorf("Expected %s, got %s", fullUserJSON, got)
+	}
+
+	got, err = m.Marshal(&user{})
+	if err != nil || string(got) != "{}" {
+		t.Errorf("Expected {}, got %s (%v)", got, err)
+	}
+	got, err = m.Marshal((*user)(nil))
+	if err != nil || string(got) != "{}" {
+		t.Errorf("Expected {} for nil message, got %s (%v)", got, err)
+	}
+}
+
+func TestMarshalOptions(t *testing.T) {
+	u := &user{UserName: "ann", Status: statusActive, Contact: &userEmail{Email: "a@b"}}
+	tests := []struct {
+		name     string
+		m        Marshaler
+		expected string
+	}{
+		{"default", Marshaler{}, `{"userName":"ann","status":"STATUS_ACTIVE","email":"a@b"}`},
+		{"proto_names", Marshaler{UseProtoNames: true}, `{"user_name":"ann","status":"STATUS_ACTIVE","email":"a@b"}`},
+		{"enum_numbers", Marshaler{UseEnumNumbers: true}, `{"userName":"ann","status":1,"email":"a@b"}`},
+		{"emit_unpopulated", Marshaler{EmitUnpopulated: true},
+			`{"id":"0","userName":"ann","score":0,"status":"STATUS_ACTIVE","avatar":"","tags":[],"counts":{},` +
+				`"created":null,"ttl":null,"nickname":null,"friend":null,"limit":null,"mask":null,"meta":null,` +
+				`"email":"a@b"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.Marshal(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMarshalValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		u        *user
+		expected string
+	}{
+		{"nan", &user{Score: math.NaN()}, `{"score":"NaN"}`},
+		{"infinity", &user{Score: math.Inf(1)}, `{"score":"Infinity"}`},
+		{"negative_infinity", &user{Score: math.Inf(-1)}, `{"score":"-Infinity"}`},
+		{"negative_zero", &user{Score: math.Copysign(0, -1)}, `{"score":-0}`},
+		{"undefined_enum", &user{Status: 7}, `{"status":7}`},
+		{"struct", &user{Meta: &structMsg{Fields: map[string]*structValue{
+			"list": {Kind: &valueList{ListValue: &listValue{Values: []*structValue{
+				{Kind: &valueNumber{NumberValue: 1}},
+				{Kind: &valueString{StringValue: "x"}},
+				{Kind: &valueNull{}},
+			}}}},
+		}}}, `{"meta":{"list":[1,"x",null]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&Marshaler{}).Marshal(tt.u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	bad := []*user{
+		{Created: &timestamp{Seconds: maxTimestampSeconds + 1}},
+		{Ttl: &duration{Seconds: 1, Nanos: -1}},
+		{Meta: &structMsg{Fields: map[string]*structValue{"n": {Kind: &valueNumber{NumberValue: math.NaN()}}}}},
+	}
+	for _, u := range bad {
+		if _, err := (&Marshaler{}).Marshal(u); err == nil {
+			t.Errorf("Expected error for %+v", u)
+		}
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	m := &Marshaler{}
+	var got user
+	if err := m.Unmarshal([]byte(fullUserJSON), &got); err != nil {
+		t.Fatal(err)
+	}
+	if expected := fullUser(); !reflect.DeepEqual(&got, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, &got)
+	}
+
+	// Other accepted forms
+	input := `{"id":12,"user_name":"ann","score":"Infinity","status":1,"avatar":"-_8",` +
+		`"created":"2023-11-14T23:13:20.5+01:00","ttl":"3s","email":"a@b","friend":null}`
+	got = user{}
+	if err := m.Unmarshal([]byte(input), &got); err != nil {
+		t.Fatal(err)
+	}
+	expected := &user{
+		Id:       12,
+		UserName: "ann",
+		Score:    math.Inf(1),
+		Status:   statusActive,
+		Avatar:   []byte{0xfb, 0xff},
+		Created:  &timestamp{Seconds: 1700000000, Nanos: 500000000},
+		Ttl:      &duration{Seconds: 3},
+		Contact:  &userEmail{Email: "a@b"},
+	}
+	if !reflect.DeepEqual(&got, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, &got)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"unknown_field", `{"nope":1}`},
+		{"fractional_int", `{"id":1.5}`},
+		{"invalid_int_string", `{"id":"x"}`},
+		{"int32_overflow", `{"counts":{"4294967296":"1"}}`},
+		{"negative_uint", `{"counts":{"1":-1}}`},
+		{"unknown_enum", `{"status":"STATUS_NONE"}`},
+		{"invalid_base64", `{"avatar":"!"}`},
+		{"invalid_timestamp", `{"created":"yesterday"}`},
+		{"invalid_duration", `{"ttl":"1.0000000001s"}`},
+		{"null_in_list", `{"tags":["a",null]}`},
+		{"wrong_type", `{"tags":"a"}`},
+		{"nested", `{"friend":{"id":true}}`},
+		{"struct", `{"meta":{}}`},
+		{"invalid_json", `{"id":`},
+		{"null", `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var u user
+			err := (&Marshaler{}).Unmarshal([]byte(tt.input), &u)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+		})
+	}
+
+	var u user
+	if err := (&Marshaler{}).Unmarshal([]byte(`{"friend":{"id":true}}`), &u); err == nil ||
+		!strings.Contains(err.Error(), `field "friend": field "id"`) {
+		t.Errorf("Expected error naming the field path, got %v", err)
+	}
+	if err := (&Marshaler{DiscardUnknown: true}).Unmarshal([]byte(`{"nope":1,"id":"2"}`), &u); err != nil || u.Id != 2 {
+		t.Errorf("Expected unknown field to be discarded, got %d (%v)", u.Id, err)
+	}
+}
+
+func TestTimestampAndDuration(t *testing.T) {
+	timestamps := []struct {
+		seconds, nanos int64
+		text           string
+	}{
+		{0, 0, "1970-01-01T00:00:00Z"},
+		{minTimestampSeconds, 0, "0001-01-01T00:00:00Z"},
+		{maxTimestampSeconds, 999999999, "9999-12-31T23:59:59.999999999Z"},
+		{-1, 1000, "1969-12-31T23:59:59.000001Z"},
+	}
+	for _, tt := range timestamps {
+		s, err := formatTimestamp(tt.seconds, tt.nanos)
+		if err != nil || s != tt.text {
+			t.Errorf("Expected %s, got %s (%v)", tt.text, s, err)
+		}
+		seconds, nanos, err := parseTimestamp(tt.text)
+		if err != nil || seconds != tt.seconds || nanos != tt.nanos {
+			t.Errorf("Expected %d.%d for %s, got %d.%d (%v)", tt.seconds, tt.nanos, tt.text, seconds, nanos, err)
+		}
+	}
+
+	durations := []struct {
+		seconds, nanos int64
+		text           string
+	}{
+		{0, 0, "0s"},
+		{1, 0, "1s"},
+		{0, -10000000, "-0.010s"},
+		{-2, -5, "-2.000000005s"},
+		{maxDurationSeconds, 0, "315576000000s"},
+	}
+	for _, tt := range durations {
+		s, err := formatDuration(tt.seconds, tt.nanos)
+		if err != nil || s != tt.text {
+			t.Errorf("Expected %s, got %s (%v)", tt.text, s, err)
+		}
+		seconds, nanos, err := parseDuration(tt.text)
+		if err != nil || seconds != tt.seconds || nanos != tt.nanos {
+			t.Errorf("Expected %d.%d for %s, got %d.%d (%v)", tt.seconds, tt.nanos, tt.text, seconds, nanos, err)
+		}
+	}
+	for _, s := range []string{"", "s", "1", "1.s5", "+1s", "--1s", "315576000001s"} {
+		if _, _, err := parseDuration(s); err == nil {
+			t.Errorf("Expected error for %q", s)
+		}
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	stream := " {\"userName\":\"a\\\"}\"}\n{\"id\":\"2\",\"tags\":[\"]\"]}\r\n\t{}  "
+	dec := (&Marshaler{}).NewDecoder(strings.NewReader(stream))
+
+	var names []string
+	for {
+		var u user
+		err := dec.Decode(&u)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, u.UserName+strconv.FormatInt(u.Id, 10)+strings.Join(u.Tags, ""))
+	}
+	if expected := []string{`a"}0`, "2]", "0"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected %q, got %q", expected, names)
+	}
+
+	dec = (&Marshaler{}).NewDecoder(strings.NewReader(`1 "x" true`))
+	var n int
+	var s string
+	var b bool
+	if err := dec.Decode(&n); err != nil || n != 1 {
+		t.Errorf("Expected 1, got %d (%v)", n, err)
+	}
+	if err := dec.Decode(&s); err != nil || s != "x" {
+		t.Errorf("Expected x, got %q (%v)", s, err)
+	}
+	if err := dec.Decode(&b); err != nil || !b {
+		t.Errorf("Expected true, got %v (%v)", b, err)
+	}
+
+	dec = (&Marshaler{}).NewDecoder(strings.NewReader(`{"id":`))
+	if err := dec.Decode(&user{}); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	m := &Marshaler{}
+	enc := m.NewEncoder(&buf)
+	for _, u := range []*user{{Id: 1}, {Id: 2}} {
+		if err := enc.Encode(u); err != nil {
+			t.Fatal(err)
+		}
+		buf.Write(m.Delimiter())
+	}
+	if expected := "{\"id\":\"1\"}\n{\"id\":\"2\"}\n"; buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestNonMessages(t *testing.T) {
+	m := &Marshaler{}
+
+	// grpc-gateway wraps streamed messages and errors in plain maps
+	got, err := m.Marshal(map[string]interface{}{"result": &user{Id: 3}})
+	if err != nil || string(got) != `{"result":{"id":"3"}}` {
+		t.Errorf("Unexpected output %s (%v)", got, err)
+	}
+	got, err = m.Marshal([]interface{}{nil, 1, "a"})
+	if err != nil || string(got) != `[null,1,"a"]` {
+		t.Errorf("Unexpected output %s (%v)", got, err)
+	}
+
+	var v map[string]int
+	if err := m.Unmarshal([]byte(`{"a":1}`), &v); err != nil || v["a"] != 1 {
+		t.Errorf("Expected 1, got %v (%v)", v, err)
+	}
+	if ct := m.ContentType(nil); ct != "application/json" {
+		t.Errorf("Expected application/json, got %s", ct)
+	}
+}
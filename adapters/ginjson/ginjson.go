@@ -0,0 +1,81 @@
+// Package ginjson plugs simdjson into gin's request binding and response
+// rendering. Binding satisfies gin's binding.BindingBody interface and JSON
+// its render.Render interface; both are matched structurally, so this
+// module does not depend on gin:
+//
+//	var u User
+//	if err := c.ShouldBindWith(&u, ginjson.Binding); err != nil {
+//		...
+//	}
+//	c.Render(http.StatusOK, ginjson.JSON{Data: u})
+package ginjson
+
+import (
+	"errors"
+	"net/http"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+	"github.com/biggeezerdevelopment/simdjson-go/httpjson"
+)
+
+// Binding decodes without validation. To keep gin's struct validation, use
+// JSONBinding{Validate: binding.Validator.ValidateStruct} instead.
+var Binding = JSONBinding{}
+
+// JSONBinding is a gin binding.BindingBody that decodes with simdjson.
+type JSONBinding struct {
+	// Validate, if set, checks every decoded value.
+	Validate func(obj interface{}) error
+}
+
+// Name returns the name gin reports for the binding.
+func (JSONBinding) Name() string {
+	return "json"
+}
+
+// Bind decodes the request body into obj with httpjson.DecodeRequest, so
+// the body size limit and content-type check apply.
+func (b JSONBinding) Bind(req *http.Request, obj interface{}) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	if err := httpjson.DecodeRequest(req, obj); err != nil {
+		return err
+	}
+	return b.validate(obj)
+}
+
+// BindBody decodes body into obj. gin calls it when the body has already
+// been read, as with ShouldBindBodyWith.
+func (b JSONBinding) BindBody(body []byte, obj interface{}) error {
+	if err := simdjson.Unmarshal(body, obj); err != nil {
+		return err
+	}
+	return b.validate(obj)
+}
+
+func (b JSONBinding) validate(obj interface{}) error {
+	if b.Validate == nil {
+		return nil
+	}
+	return b.Validate(obj)
+}
+
+// JSON is a gin render.Render that encodes Data with simdjson.
+type JSON struct {
+	Data interface{}
+}
+
+// Render writes Data as the response body. gin has set the status code
+// already.
+func (r JSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return simdjson.NewEncoder(w).Encode(r.Data)
+}
+
+// WriteContentType sets the JSON Content-Type unless one is set already.
+func (r JSON) WriteContentType(w http.ResponseWriter) {
+	if h := w.Header(); h.Get("Content-Type") == "" {
+		h.Set("Content-Type", "application/json; charset=utf-8")
+	}
+}
@@ -0,0 +1,85 @@
+package ginjson
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// The gin interfaces the adapters are meant to satisfy, copied from
+// github.com/gin-gonic/gin/binding and github.com/gin-gonic/gin/render.
+type ginBinding interface {
+	Name() string
+	Bind(*http.Request, any) error
+}
+
+type ginBindingBody interface {
+	ginBinding
+	BindBody([]byte, any) error
+}
+
+type ginRender interface {
+	Render(http.ResponseWriter) error
+	WriteContentType(w http.ResponseWriter)
+}
+
+var (
+	_ ginBindingBody = Binding
+	_ ginRender      = JSON{}
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func TestBinding(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"a"}`))
+	req.Header.Set("Content-Type", "application/json")
+	var u user
+	if err := Binding.Bind(req, &u); err != nil || u.Name != "a" {
+		t.Errorf("Expected %q, got %q (%v)", "a", u.Name, err)
+	}
+
+	if err := Binding.BindBody([]byte(`{"name":"b"}`), &u); err != nil || u.Name != "b" {
+		t.Errorf("Expected %q, got %q (%v)", "b", u.Name, err)
+	}
+	if err := Binding.BindBody([]byte(`{"name":`), &u); err == nil {
+		t.Error("Expected error for invalid body")
+	}
+	if err := Binding.Bind(&http.Request{}, &u); err == nil {
+		t.Error("Expected error for missing body")
+	}
+
+	errInvalid := errors.New("name required")
+	validating := JSONBinding{Validate: func(obj interface{}) error {
+		if obj.(*user).Name == "" {
+			return errInvalid
+		}
+		return nil
+	}}
+	if err := validating.BindBody([]byte(`{}`), &user{}); !errors.Is(err, errInvalid) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+	if validating.Name() != "json" {
+		t.Errorf("Expected name json, got %q", validating.Name())
+	}
+}
+
+func TestRender(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := (JSON{Data: user{Name: "a"}}).Render(w); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Body.String(); got != `{"name":"a"}` {
+		t.Errorf("Unexpected body %s", got)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("Unexpected Content-Type %q", ct)
+	}
+
+	if err := (JSON{Data: make(chan int)}).Render(httptest.NewRecorder()); err == nil {
+		t.Error("Expected error for unsupported type")
+	}
+}
@@ -0,0 +1,302 @@
+package simdjson
+
+import (
+	"unsafe"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/pool"
+)
+
+// Slab sizes of an arena. Strings and arrays larger than an eighth of a
+// slab get their own allocation so one big value can't waste most of a
+// slab.
+const (
+	arenaStringSlab = 32 * 1024 // bytes
+	arenaValueSlab  = 1024      // interface{} elements
+	maxArenaSlabs   = 64        // slabs of each kind kept for reuse
+	maxArenaMaps    = 4096      // maps kept for reuse
+)
+
+// arena backs the interface{} tree of a Result. Strings are copied into
+// byte slabs and arrays are carved out of interface{} slabs, so a whole
+// document costs a handful of allocations instead of one per value. The
+// headers of strings and slices and the numbers stored in interface{}
+// values would each be boxed on the heap; they are kept in slabs too and
+// boxed in place. Maps cannot be placed in a slab; they are cleared and
+// reused instead. On reset every slab is recycled, which is what makes
+// Result.Release unsafe to combine with retained values.
+type arena struct {
+	strs    [][]byte // string slabs; strs[str] is being filled
+	str     int
+	vals    [][]interface{} // array slabs; vals[val] is being filled
+	val     int
+	strHdrs slab[string]
+	arrHdrs slab[[]interface{}]
+	ints    slab[int64]
+	floats  slab[float64]
+	maps    []map[string]interface{} // handed out since the last reset
+	free    []map[string]interface{}
+}
+
+var arenaPool = pool.New("arena", func() *arena {
+	return new(arena)
+}, (*arena).size)
+
+// slab hands out pointers to Ts from fixed-size chunks. A chunk is never
+// grown, so the pointers stay valid until reset.
+type slab[T any] struct {
+	chunks [][]T
+	cur    int
+}
+
+const slabChunk = 256
+
+func (s *slab[T]) alloc() *T {
+	if len(s.chunks) == 0 || len(s.chunks[s.cur]) == slabChunk {
+		if len(s.chunks) > 0 {
+			s.cur++
+		}
+		if s.cur == len(s.chunks) {
+			s.chunks = append(s.chunks, make([]T, 0, slabChunk))
+		}
+	}
+	var zero T
+	c := append(s.chunks[s.cur], zero)
+	s.chunks[s.cur] = c
+	return &c[len(c)-1]
+}
+
+// size returns the bytes of the chunks of s.
+func (s *slab[T]) size() int {
+	var zero T
+	n := 0
+	for _, c := range s.chunks {
+		n += cap(c)
+	}
+	return n * int(unsafe.Sizeof(zero))
+}
+
+func (s *slab[T]) reset() {
+	if len(s.chunks) > maxArenaSlabs {
+		clear(s.chunks[maxArenaSlabs:])
+		s.chunks = s.chunks[:maxArenaSlabs]
+	}
+	for i := range s.chunks {
+		clear(s.chunks[i])
+		s.chunks[i] = s.chunks[i][:0]
+	}
+	s.cur = 0
+}
+
+// eface is the layout of an interface{} value.
+type eface struct {
+	typ  unsafe.Pointer
+	data unsafe.Pointer
+}
+
+func typeOf(x interface{}) unsafe.Pointer {
+	return (*eface)(unsafe.Pointer(&x)).typ
+}
+
+var (
+	stringTypePtr  = typeOf("")
+	sliceTypePtr   = typeOf([]interface{}(nil))
+	int64TypePtr   = typeOf(int64(0))
+	float64TypePtr = typeOf(float64(0))
+)
+
+// box returns an interface{} of the given type whose data word is p, which
+// must point into one of the arena's slabs. This is what the runtime does
+// when converting to interface{}, minus the heap copy.
+func box(typ, p unsafe.Pointer) interface{} {
+	var x interface{}
+	e := (*eface)(unsafe.Pointer(&x))
+	e.typ = typ
+	e.data = p
+	return x
+}
+
+// string returns a copy of b that lives in the arena.
+func (a *arena) string(b []byte) string {
+	n := len(b)
+	if n == 0 {
+		return ""
+	}
+	if n > arenaStringSlab/8 {
+		return string(b)
+	}
+	if len(a.strs) == 0 || cap(a.strs[a.str])-len(a.strs[a.str]) < n {
+		a.nextStringSlab()
+	}
+	slab := a.strs[a.str]
+	start := len(slab)
+	slab = append(slab, b...)
+	a.strs[a.str] = slab
+	return unsafe.String(&slab[start], n)
+}
+
+func (a *arena) nextStringSlab() {
+	if len(a.strs) > 0 {
+		a.str++
+	}
+	if a.str == len(a.strs) {
+		a.strs = append(a.strs, make([]byte, 0, arenaStringSlab))
+	}
+}
+
+// stringValue boxes s in an interface{}.
+func (a *arena) stringValue(s string) interface{} {
+	p := a.strHdrs.alloc()
+	*p = s
+	return box(stringTypePtr, unsafe.Pointer(p))
+}
+
+// slice returns a copy of vals that lives in the arena. Its capacity is
+// its length, so appending to it reallocates instead of overwriting the
+// next array in the slab.
+func (a *arena) slice(vals []interface{}) []interface{} {
+	n := len(vals)
+	if n == 0 {
+		return []interface{}{}
+	}
+	if n > arenaValueSlab/8 {
+		s := make([]interface{}, n)
+		copy(s, vals)
+		return s
+	}
+	if len(a.vals) == 0 || cap(a.vals[a.val])-len(a.vals[a.val]) < n {
+		a.nextValueSlab()
+	}
+	slab := a.vals[a.val]
+	start := len(slab)
+	slab = append(slab, vals...)
+	a.vals[a.val] = slab
+	return slab[start : start+n : start+n]
+}
+
+func (a *arena) nextValueSlab() {
+	if len(a.vals) > 0 {
+		a.val++
+	}
+	if a.val == len(a.vals) {
+		a.vals = append(a.vals, make([]interface{}, 0, arenaValueSlab))
+	}
+}
+
+// sliceValue is slice boxed in an interface{}.
+func (a *arena) sliceValue(vals []interface{}) interface{} {
+	p := a.arrHdrs.alloc()
+	*p = a.slice(vals)
+	return box(sliceTypePtr, unsafe.Pointer(p))
+}
+
+// intValue and floatValue box numbers in the arena.
+func (a *arena) intValue(n int64) interface{} {
+	p := a.ints.alloc()
+	*p = n
+	return box(int64TypePtr, unsafe.Pointer(p))
+}
+
+func (a *arena) floatValue(f float64) interface{} {
+	p := a.floats.alloc()
+	*p = f
+	return box(float64TypePtr, unsafe.Pointer(p))
+}
+
+// newMap returns an empty map, reusing one from a released Result if any.
+func (a *arena) newMap() map[string]interface{} {
+	var m map[string]interface{}
+	if n := len(a.free); n > 0 {
+		m = a.free[n-1]
+		a.free = a.free[:n-1]
+	} else {
+		m = make(map[string]interface{})
+	}
+	a.maps = append(a.maps, m)
+	return m
+}
+
+// size returns the bytes of the slabs of a, not counting its maps.
+func (a *arena) size() int {
+	n := a.strHdrs.size() + a.arrHdrs.size() + a.ints.size() + a.floats.size()
+	for _, b := range a.strs {
+		n += cap(b)
+	}
+	for _, v := range a.vals {
+		n += 16 * cap(v)
+	}
+	return n
+}
+
+// reset recycles everything handed out since the last reset.
+func (a *arena) reset() {
+	// A single huge document must not pin its slabs in the pool forever
+	if len(a.strs) > maxArenaSlabs {
+		clear(a.strs[maxArenaSlabs:])
+		a.strs = a.strs[:maxArenaSlabs]
+	}
+	if len(a.vals) > maxArenaSlabs {
+		clear(a.vals[maxArenaSlabs:])
+		a.vals = a.vals[:maxArenaSlabs]
+	}
+	for i := range a.strs {
+		a.strs[i] = a.strs[i][:0]
+	}
+	a.str = 0
+	for i := range a.vals {
+		// Drop references so released values can be collected
+		clear(a.vals[i])
+		a.vals[i] = a.vals[i][:0]
+	}
+	a.val = 0
+	a.strHdrs.reset()
+	a.arrHdrs.reset()
+	a.ints.reset()
+	a.floats.reset()
+	for i, m := range a.maps {
+		if len(a.free) < maxArenaMaps {
+			clear(m)
+			a.free = append(a.free, m)
+		}
+		a.maps[i] = nil
+	}
+	a.maps = a.maps[:0]
+}
+
+// Result is a document parsed by Parser.Parse. Its strings, arrays and
+// objects are allocated from an arena that Release hands back for reuse
+// by later Parse calls.
+//
+// Ownership rules: everything reachable from Value belongs to the Result.
+// After Release, none of it may be used, including strings, slices and
+// maps copied out of the tree by assignment; copy what must outlive the
+// Result first. Calling Release is optional: an unreleased Result is
+// reclaimed by the garbage collector like any other value. Unless the
+// Parser is in zero-copy mode (see Options.CopyStrings), a Result does not
+// alias the input, which may be reused as soon as Parse returns.
+type Result struct {
+	value interface{}
+	arena *arena
+	file  []byte // the mapping of the file parsed by ParseFile, if any
+}
+
+// Value returns the root of the parsed document: a map[string]interface{},
+// []interface{}, string, int64, float64, bool or nil.
+func (r *Result) Value() interface{} {
+	return r.value
+}
+
+// Release recycles the memory of the Result. It must only be called when
+// no part of the value is in use anymore; calling it again has no effect.
+func (r *Result) Release() {
+	if r.arena == nil {
+		return
+	}
+	r.arena.reset()
+	arenaPool.Put(r.arena)
+	r.arena = nil
+	r.value = nil
+	if r.file != nil {
+		unmapFile(r.file)
+		r.file = nil
+	}
+}
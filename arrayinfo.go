@@ -0,0 +1,100 @@
+package simdjson
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// Type is the type of a JSON value, as ArrayInfo reports it.
+type Type uint8
+
+const (
+	TypeNone Type = iota // no one type: no values, or values of mixed types
+	TypeNull
+	TypeBool
+	TypeInteger // a number written without a fraction or exponent
+	TypeNumber  // any number
+	TypeString
+	TypeObject
+	TypeArray
+)
+
+var typeNames = [...]string{"none", "null", "bool", "integer", "number", "string", "object", "array"}
+
+func (t Type) String() string {
+	if int(t) < len(typeNames) {
+		return typeNames[t]
+	}
+	return "Type(" + strconv.Itoa(int(t)) + ")"
+}
+
+// ArrayInfo returns the number of elements of the array at path in data,
+// and the type they all have, so a caller can allocate a slice of exactly
+// that length and pick a decoding path for its elements before decoding
+// any. Both are read from the document's tokens, without decoding the
+// elements. An array of integers and other numbers has TypeNumber; an
+// empty array, or one of mixed types, TypeNone. Paths are as for Editor,
+// as in "results[0].scores", and the empty path is the whole document.
+func ArrayInfo(data []byte, path string) (length int, homogeneousType Type, err error) {
+	steps, err := parseEditPath(path)
+	if err != nil {
+		return 0, TypeNone, err
+	}
+	s := scanner.New()
+	defer s.Release()
+	d, err := parseDocument(s, data)
+	if err != nil {
+		return 0, TypeNone, err
+	}
+	defer d.release()
+
+	pos := 0
+	for _, st := range steps {
+		if pos, _ = d.child(pos, st); pos < 0 {
+			return 0, TypeNone, errors.New("path not found: " + path)
+		}
+	}
+	if d.kind(pos) != scanner.TokenArrayBegin {
+		return 0, TypeNone, errors.New("value at " + strconv.Quote(path) + " is not an array")
+	}
+
+	d.elements(pos, func(elem int) {
+		t := tokenType(d.tokens[elem])
+		switch {
+		case length == 0:
+			homogeneousType = t
+		case t == homogeneousType:
+		case t == TypeNumber && homogeneousType == TypeInteger,
+			t == TypeInteger && homogeneousType == TypeNumber:
+			homogeneousType = TypeNumber
+		default:
+			homogeneousType = TypeNone
+		}
+		length++
+	})
+	return length, homogeneousType, nil
+}
+
+// tokenType returns the type of the value starting with tok.
+func tokenType(tok scanner.Token) Type {
+	switch tok.Type {
+	case scanner.TokenNull:
+		return TypeNull
+	case scanner.TokenTrue, scanner.TokenFalse:
+		return TypeBool
+	case scanner.TokenNumber:
+		if tok.Flags&(scanner.FlagFraction|scanner.FlagExponent) != 0 {
+			return TypeNumber
+		}
+		return TypeInteger
+	case scanner.TokenString:
+		return TypeString
+	case scanner.TokenObjectBegin:
+		return TypeObject
+	case scanner.TokenArrayBegin:
+		return TypeArray
+	}
+	return TypeNone
+}
@@ -0,0 +1,52 @@
+package simdjson
+
+import "testing"
+
+func TestArrayInfo(t *testing.T) {
+	doc := []byte(`{"ints":[1,-2,30],"nums":[1,2.5,3e2],"strs":["a","b"],"mixed":[1,"a"],
+		"objs":[{"a":[1]},{}],"nested":[[1],[2,3]],"empty":[],"nulls":[null],"bools":[true,false],
+		"deep":{"list":[{"x":[true]}]},"n":1}`)
+	tests := []struct {
+		path     string
+		length   int
+		expected Type
+		err      bool
+	}{
+		{"ints", 3, TypeInteger, false},
+		{"nums", 3, TypeNumber, false},
+		{"strs", 2, TypeString, false},
+		{"mixed", 2, TypeNone, false},
+		{"objs", 2, TypeObject, false},
+		{"nested", 2, TypeArray, false},
+		{"nested[1]", 2, TypeInteger, false},
+		{"empty", 0, TypeNone, false},
+		{"nulls", 1, TypeNull, false},
+		{"bools", 2, TypeBool, false},
+		{"deep.list[0].x", 1, TypeBool, false},
+		{"n", 0, TypeNone, true},
+		{"", 0, TypeNone, true},
+		{"missing", 0, TypeNone, true},
+		{"ints[", 0, TypeNone, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			length, typ, err := ArrayInfo(doc, tt.path)
+			if (err != nil) != tt.err {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if length != tt.length || typ != tt.expected {
+				t.Errorf("Expected %d of %v, got %d of %v", tt.length, tt.expected, length, typ)
+			}
+		})
+	}
+
+	if n, typ, err := ArrayInfo([]byte(` [ 1.5 , -0 ] `), ""); err != nil || n != 2 || typ != TypeNumber {
+		t.Errorf("Expected 2 of number, got %d of %v, %v", n, typ, err)
+	}
+	if _, _, err := ArrayInfo([]byte(`[1,`), ""); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+	if s := TypeInteger.String(); s != "integer" {
+		t.Errorf("Expected integer, got %s", s)
+	}
+}
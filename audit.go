@@ -0,0 +1,176 @@
+package simdjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"reflect"
+	"sync/atomic"
+)
+
+// AuditConfig configures audit mode, in which Marshal and Unmarshal also
+// run encoding/json on every call they audit and report where the two
+// disagree, so a team can canary simdjson in production as a drop-in
+// replacement before relying on it. encoding/json runs in parallel with
+// simdjson, in its own goroutine, and the caller gets simdjson's result
+// either way, at the cost of the work being done twice.
+//
+// Marshal diverges when one of the two fails and the other does not, or
+// when their output differs by a byte. Unmarshal diverges when one fails
+// and the other does not, or when the values they decode differ as
+// reflect.DeepEqual compares them. Unmarshal into a value that is not
+// zero is not audited, as decoding merges into what is there and
+// encoding/json would need a deep copy of it.
+type AuditConfig struct {
+	// Every audits one call in Every, or every call if it is not
+	// positive.
+	Every int
+
+	// MaxInput caps the bytes of input a Divergence holds, 4096 if it is
+	// not positive.
+	MaxInput int
+
+	// Report is called with each divergence, from the goroutine that made
+	// the call. The default logs it with the log package.
+	Report func(d *Divergence)
+}
+
+// A Divergence is a call for which simdjson and encoding/json disagree.
+type Divergence struct {
+	Op   string       // "Marshal" or "Unmarshal"
+	Type reflect.Type // type of the value marshaled or decoded into
+
+	// Input is the document given to Unmarshal, or encoding/json's
+	// encoding of the value given to Marshal, cut to AuditConfig.MaxInput
+	// bytes; Truncated reports whether it was cut.
+	Input     []byte
+	Truncated bool
+
+	// Got and Want describe simdjson's and encoding/json's results: the
+	// encoding or decoded value, or the error.
+	Got, Want string
+}
+
+func (d *Divergence) String() string {
+	typ := "nil"
+	if d.Type != nil {
+		typ = d.Type.String()
+	}
+	s := "simdjson audit: " + d.Op + " of " + typ + " diverged from encoding/json: got " +
+		d.Got + ", want " + d.Want + "; input " + string(d.Input)
+	if d.Truncated {
+		s += "..."
+	}
+	return s
+}
+
+// auditor is the AuditConfig in effect, nil if audit mode is off.
+type auditor struct {
+	config AuditConfig
+	calls  atomic.Uint64
+}
+
+var currentAuditor atomic.Pointer[auditor]
+
+// SetAudit turns audit mode on as c describes, or off if c is nil.
+// Building with -tags simdjson_audit turns it on at startup with the zero
+// AuditConfig, auditing and logging every call.
+func SetAudit(c *AuditConfig) {
+	if c == nil {
+		currentAuditor.Store(nil)
+		return
+	}
+	a := &auditor{config: *c}
+	if a.config.MaxInput <= 0 {
+		a.config.MaxInput = 4096
+	}
+	if a.config.Report == nil {
+		a.config.Report = func(d *Divergence) { log.Print(d) }
+	}
+	currentAuditor.Store(a)
+}
+
+// sample reports whether this call is one to audit.
+func (a *auditor) sample() bool {
+	n := a.calls.Add(1)
+	return a.config.Every <= 1 || n%uint64(a.config.Every) == 0
+}
+
+// marshal is Marshal in audit mode.
+func (a *auditor) marshal(v interface{}) ([]byte, error) {
+	if !a.sample() {
+		return marshal(v)
+	}
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		data, err := json.Marshal(v)
+		done <- result{data, err}
+	}()
+	data, err := marshal(v)
+	want := <-done
+
+	if (err == nil) == (want.err == nil) && (err != nil || bytes.Equal(data, want.data)) {
+		return data, err
+	}
+	a.report("Marshal", reflect.TypeOf(v), want.data, describe(string(data), err), describe(string(want.data), want.err))
+	return data, err
+}
+
+// unmarshal is Unmarshal in audit mode.
+func (a *auditor) unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || !rv.Elem().IsZero() || !a.sample() {
+		return unmarshal(data, v)
+	}
+	want := reflect.New(rv.Elem().Type())
+	done := make(chan error, 1)
+	go func() {
+		done <- json.Unmarshal(data, want.Interface())
+	}()
+	err := unmarshal(data, v)
+	wantErr := <-done
+
+	if (err == nil) == (wantErr == nil) && (err != nil || reflect.DeepEqual(rv.Elem().Interface(), want.Elem().Interface())) {
+		return err
+	}
+	got, wantDesc := describeValue(rv.Elem(), err), describeValue(want.Elem(), wantErr)
+	if got == wantDesc {
+		got += " (types differ)"
+	}
+	a.report("Unmarshal", rv.Elem().Type(), data, got, wantDesc)
+	return err
+}
+
+func (a *auditor) report(op string, t reflect.Type, input []byte, got, want string) {
+	d := &Divergence{Op: op, Type: t, Got: got, Want: want}
+	if len(input) > a.config.MaxInput {
+		input, d.Truncated = input[:a.config.MaxInput], true
+	}
+	d.Input = bytes.Clone(input)
+	a.config.Report(d)
+}
+
+// describe returns the error, if any, or else s.
+func describe(s string, err error) string {
+	if err != nil {
+		return "error " + err.Error()
+	}
+	return s
+}
+
+// describeValue describes a decoded value by its encoding/json encoding,
+// which shows the types of interface values only as far as JSON can.
+func describeValue(v reflect.Value, err error) string {
+	if err != nil {
+		return describe("", err)
+	}
+	b, mErr := json.Marshal(v.Interface())
+	if mErr != nil {
+		return v.Type().String() + " value"
+	}
+	return string(b)
+}
@@ -0,0 +1,7 @@
+//go:build simdjson_audit
+
+package simdjson
+
+func init() {
+	SetAudit(&AuditConfig{})
+}
@@ -0,0 +1,114 @@
+package simdjson
+
+import (
+	"strings"
+	"testing"
+)
+
+// collectDivergences turns audit mode on with c, collecting what it
+// reports, until the test ends.
+func collectDivergences(t *testing.T, c AuditConfig) *[]*Divergence {
+	var got []*Divergence
+	c.Report = func(d *Divergence) { got = append(got, d) }
+	SetAudit(&c)
+	t.Cleanup(func() { SetAudit(nil) })
+	return &got
+}
+
+func TestAuditMarshal(t *testing.T) {
+	got := collectDivergences(t, AuditConfig{})
+
+	type user struct {
+		Name string `json:"name"`
+		Tags []string
+	}
+	if data, err := Marshal(user{Name: "<a>", Tags: []string{"x"}}); err != nil || string(data) != `{"name":"\u003ca\u003e","Tags":["x"]}` {
+		t.Fatalf("Expected simdjson's output, got %s, %v", data, err)
+	}
+	if _, err := Marshal(make(chan int)); err == nil {
+		t.Error("Expected an error for an unsupported type")
+	}
+	if len(*got) != 0 {
+		t.Fatalf("Expected no divergences, got %v", (*got)[0])
+	}
+
+	// encoding/json knows nothing of codecs
+	data, err := Marshal(codecCents(1234))
+	if err != nil || string(data) != `"12.34"` {
+		t.Fatalf(`Expected "12.34" whatever the audit finds, got %s, %v`, data, err)
+	}
+	if len(*got) != 1 {
+		t.Fatalf("Expected 1 divergence, got %d", len(*got))
+	}
+	d := (*got)[0]
+	if d.Op != "Marshal" || d.Type.String() != "simdjson.codecCents" || d.Got != `"12.34"` || d.Want != "1234" || string(d.Input) != "1234" {
+		t.Errorf("Unexpected divergence %+v", d)
+	}
+	if s := d.String(); !strings.Contains(s, `got "12.34", want 1234`) {
+		t.Errorf("Expected the report to show both results, got %s", s)
+	}
+}
+
+func TestAuditUnmarshal(t *testing.T) {
+	got := collectDivergences(t, AuditConfig{MaxInput: 8})
+
+	var s struct {
+		A int `json:"a"`
+	}
+	if err := Unmarshal([]byte(`{"a":1}`), &s); err != nil || s.A != 1 {
+		t.Fatalf("Expected a 1, got %+v, %v", s, err)
+	}
+	if len(*got) != 0 {
+		t.Fatalf("Expected no divergences, got %v", (*got)[0])
+	}
+
+	// Integers in interface values are int64, not float64
+	var v interface{}
+	if err := Unmarshal([]byte(`[1, 2, 3, 4, 5]`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if len(*got) != 1 {
+		t.Fatalf("Expected 1 divergence, got %d", len(*got))
+	}
+	d := (*got)[0]
+	if d.Op != "Unmarshal" || d.Got != "[1,2,3,4,5] (types differ)" || d.Want != "[1,2,3,4,5]" {
+		t.Errorf("Unexpected divergence %+v", d)
+	}
+	if string(d.Input) != "[1, 2, 3" || !d.Truncated {
+		t.Errorf("Expected the input cut to 8 bytes, got %q (%v)", d.Input, d.Truncated)
+	}
+
+	// encoding/json replaces invalid UTF-8 where simdjson rejects it
+	var str string
+	if err := Unmarshal([]byte("\"\xff\""), &str); err == nil {
+		t.Fatal("Expected simdjson's error")
+	}
+	if len(*got) != 2 || !strings.HasPrefix((*got)[1].Got, "error ") || (*got)[1].Want != `"�"` {
+		t.Errorf("Expected an error divergence, got %+v", (*got)[len(*got)-1])
+	}
+
+	// Decoding into a value that is not zero merges, so is not audited
+	v = map[string]interface{}{"a": "x"}
+	if err := Unmarshal([]byte(`{"b":1}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if len(*got) != 2 {
+		t.Errorf("Expected no audit of a non-zero value, got %+v", (*got)[2])
+	}
+}
+
+func TestAuditEvery(t *testing.T) {
+	got := collectDivergences(t, AuditConfig{Every: 3})
+	for i := 0; i < 9; i++ {
+		Marshal(codecCents(i))
+	}
+	if len(*got) != 3 {
+		t.Errorf("Expected 3 of 9 calls audited, got %d", len(*got))
+	}
+
+	SetAudit(nil)
+	Marshal(codecCents(1))
+	if len(*got) != 3 {
+		t.Error("Expected no audit once turned off")
+	}
+}
@@ -0,0 +1,61 @@
+package simdjson
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// UnmarshalBatch decodes each of docs into a new T, as Decode does, and
+// returns them in the same order. The documents are shared out among up
+// to GOMAXPROCS workers, each of which takes a decoder from the pool once
+// and reuses it for every document it decodes, so a batch of small
+// payloads, such as the messages of a queue, costs little more than their
+// decoding.
+//
+// errs is nil if every document decoded; otherwise errs[i] is the error of
+// docs[i], and values[i] holds what was decoded of it before the error.
+func UnmarshalBatch[T any](docs [][]byte) (values []T, errs []error) {
+	values = make([]T, len(docs))
+	if len(docs) == 0 {
+		return values, nil
+	}
+	plan := planFor(reflect.TypeFor[T]())
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(docs) {
+		workers = len(docs)
+	}
+	var (
+		next   atomic.Int64 // index of the next document to decode
+		failed atomic.Bool
+		wg     sync.WaitGroup
+	)
+	errs = make([]error, len(docs))
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d := newDecoder(nil)
+			defer d.release()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(docs) {
+					return
+				}
+				d.data = docs[i]
+				if err := d.run(plan, reflect.ValueOf(&values[i]).Elem()); err != nil {
+					errs[i] = err
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !failed.Load() {
+		errs = nil
+	}
+	return values, errs
+}
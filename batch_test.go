@@ -0,0 +1,62 @@
+package simdjson
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestUnmarshalBatch(t *testing.T) {
+	docs := make([][]byte, 500)
+	for i := range docs {
+		docs[i] = []byte(`{"name":"node ` + strconv.Itoa(i) + `","children":[{"name":"leaf"}]}`)
+	}
+	nodes, errs := UnmarshalBatch[decodeNode](docs)
+	if errs != nil {
+		t.Fatalf("Expected no errors, got %v", errs)
+	}
+	if len(nodes) != len(docs) {
+		t.Fatalf("Expected %d nodes, got %d", len(docs), len(nodes))
+	}
+	for i, n := range nodes {
+		if n.Name != "node "+strconv.Itoa(i) || len(n.Children) != 1 {
+			t.Fatalf("Expected node %d in order, got %+v", i, n)
+		}
+	}
+
+	// Errors are reported per document
+	docs[3] = []byte(`{"name":`)
+	docs[7] = []byte(`{"name":7}`)
+	nodes, errs = UnmarshalBatch[decodeNode](docs)
+	if len(errs) != len(docs) {
+		t.Fatalf("Expected an error slice of %d, got %d", len(docs), len(errs))
+	}
+	for i, err := range errs {
+		if (err != nil) != (i == 3 || i == 7) {
+			t.Errorf("Unexpected error %v for document %d", err, i)
+		}
+	}
+	if nodes[8].Name != "node 8" {
+		t.Errorf("Expected the other documents decoded, got %+v", nodes[8])
+	}
+
+	// Types with generated decoders
+	decimals, errs := UnmarshalBatch[bignumDecimal]([][]byte{[]byte(`1.10`), []byte(`2e5`)})
+	if errs != nil || decimals[0].text != "1.10" || decimals[1].text != "2e5" {
+		t.Errorf("Expected 1.10 and 2e5, got %v, %v", decimals, errs)
+	}
+
+	if values, errs := UnmarshalBatch[int](nil); len(values) != 0 || errs != nil {
+		t.Errorf("Expected nothing for no documents, got %v, %v", values, errs)
+	}
+}
+
+func BenchmarkUnmarshalBatch(b *testing.B) {
+	docs := make([][]byte, 1000)
+	for i := range docs {
+		docs[i] = []byte(`{"name":"node ` + strconv.Itoa(i) + `","children":[{"name":"leaf"},{"name":"leaf"}]}`)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		UnmarshalBatch[decodeNode](docs)
+	}
+}
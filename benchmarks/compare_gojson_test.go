@@ -0,0 +1,9 @@
+//go:build gojson
+
+package benchmarks
+
+import gojson "github.com/goccy/go-json"
+
+func init() {
+	libraries = append(libraries, library{"go-json", gojson.Unmarshal, gojson.Marshal, gojson.Valid})
+}
@@ -0,0 +1,10 @@
+//go:build jsoniter
+
+package benchmarks
+
+import jsoniter "github.com/json-iterator/go"
+
+func init() {
+	api := jsoniter.ConfigCompatibleWithStandardLibrary
+	libraries = append(libraries, library{"jsoniter", api.Unmarshal, api.Marshal, api.Valid})
+}
@@ -0,0 +1,10 @@
+//go:build sonic
+
+package benchmarks
+
+import "github.com/bytedance/sonic"
+
+func init() {
+	api := sonic.ConfigStd
+	libraries = append(libraries, library{"sonic", api.Unmarshal, api.Marshal, api.Valid})
+}
@@ -0,0 +1,112 @@
+package benchmarks
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+)
+
+// library is one JSON implementation in the comparison. Others register
+// themselves from files guarded by a build tag, so their modules are only
+// needed when they are benchmarked:
+//
+//	go get github.com/json-iterator/go github.com/goccy/go-json github.com/bytedance/sonic
+//	go test -tags 'jsoniter gojson sonic' -run '^$' -bench Compare -benchmem
+type library struct {
+	name      string
+	unmarshal func([]byte, interface{}) error
+	marshal   func(interface{}) ([]byte, error)
+	valid     func([]byte) bool
+}
+
+var libraries = []library{
+	{"std", json.Unmarshal, json.Marshal, json.Valid},
+	{"simdjson", simdjson.Unmarshal, simdjson.Marshal, simdjson.Valid},
+}
+
+// corpora are the documents of the simdjson benchmark suite, read from
+// ../testdata/corpus or, for the full upstream files fetched by
+// fetch_corpora.sh, from the directory named by SIMDJSON_CORPUS.
+var corpora = []string{"twitter.json", "citm_catalog.json", "canada.json"}
+
+func loadCorpus(b *testing.B, name string) []byte {
+	if dir := os.Getenv("SIMDJSON_CORPUS"); dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			b.Fatal(err)
+		}
+		return data
+	}
+	f, err := os.Open(filepath.Join("..", "testdata", "corpus", name+".gz"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		b.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data
+}
+
+// BenchmarkCompare decodes into interface{}, encodes the result back and
+// validates each corpus with every library, reporting MB/s of JSON text
+// and allocations per operation.
+func BenchmarkCompare(b *testing.B) {
+	for _, name := range append(corpora, "generated") {
+		b.Run(name, func(b *testing.B) {
+			data := largeJSON
+			if name != "generated" {
+				data = loadCorpus(b, name)
+			}
+			var doc interface{}
+			if err := json.Unmarshal(data, &doc); err != nil {
+				b.Fatal(err)
+			}
+
+			for _, lib := range libraries {
+				b.Run("Unmarshal/"+lib.name, func(b *testing.B) {
+					b.SetBytes(int64(len(data)))
+					b.ReportAllocs()
+					for i := 0; i < b.N; i++ {
+						var v interface{}
+						if err := lib.unmarshal(data, &v); err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+			}
+			for _, lib := range libraries {
+				b.Run("Marshal/"+lib.name, func(b *testing.B) {
+					b.SetBytes(int64(len(data)))
+					b.ReportAllocs()
+					for i := 0; i < b.N; i++ {
+						if _, err := lib.marshal(doc); err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+			}
+			for _, lib := range libraries {
+				b.Run("Valid/"+lib.name, func(b *testing.B) {
+					b.SetBytes(int64(len(data)))
+					b.ReportAllocs()
+					for i := 0; i < b.N; i++ {
+						if !lib.valid(data) {
+							b.Fatal("rejected valid JSON")
+						}
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+#!/bin/sh
+# Downloads the full documents of the simdjson benchmark suite into
+# testdata, for BenchmarkCompare with SIMDJSON_CORPUS=testdata. The copies
+# in ../testdata/corpus are used otherwise.
+set -e
+cd "$(dirname "$0")"
+mkdir -p testdata
+for f in twitter.json citm_catalog.json canada.json; do
+	curl -fsSL -o "testdata/$f" "https://raw.githubusercontent.com/simdjson/simdjson/master/jsonexamples/$f"
+done
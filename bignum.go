@@ -0,0 +1,112 @@
+package simdjson
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// Codecs for math/big, so numbers beyond int64 and float64 are decoded
+// from, and written back to, their exact text. Decimal types from other
+// packages can be supported the same way with RegisterCodec, Reader.Number
+// and Writer.Number.
+func init() {
+	RegisterCodec(marshalBigInt, unmarshalBigInt)
+	RegisterCodec(marshalBigFloat, unmarshalBigFloat)
+}
+
+var (
+	bigIntType   = reflect.TypeFor[big.Int]()
+	bigFloatType = reflect.TypeFor[big.Float]()
+
+	// Bounds of the magnitudes written without an exponent, as for float64
+	bigFloatMin = big.NewFloat(1e-6)
+	bigFloatMax = big.NewFloat(1e21)
+)
+
+func marshalBigInt(w *Writer, x big.Int) error {
+	w.e.buf = x.Append(w.e.buf, 10)
+	return nil
+}
+
+// unmarshalBigInt decodes an integer of any size. A number with a fraction
+// or exponent is an UnmarshalTypeError, even with Options.CoerceNumbers,
+// as 1e1000000 would need a megabit. null leaves x unchanged.
+func unmarshalBigInt(r *Reader, x *big.Int) error {
+	if r.Null() {
+		return nil
+	}
+	s, err := r.number()
+	if err != nil {
+		return err
+	}
+	if r.tokens[r.pos-1].IsFloat() {
+		return r.numberError(s, bigIntType, reflect.Int, 0)
+	}
+	x.SetString(s, 10)
+	return nil
+}
+
+// marshalBigFloat writes x with the fewest digits that read back as x at
+// its precision, in the notation AppendFloat chooses for a float64 of the
+// same magnitude. Infinities are an error.
+func marshalBigFloat(w *Writer, x big.Float) error {
+	if x.IsInf() {
+		return errors.New("unsupported float value")
+	}
+	var abs big.Float
+	abs.Abs(&x)
+	if abs.Sign() != 0 && (abs.Cmp(bigFloatMin) < 0 || abs.Cmp(bigFloatMax) >= 0) {
+		w.e.buf = shortenExponent(x.Append(w.e.buf, 'e', -1))
+	} else {
+		w.e.buf = x.Append(w.e.buf, 'f', -1)
+	}
+	return nil
+}
+
+// shortenExponent shortens an exponent of e-09 at the end of dst to e-9,
+// as AppendFloat does.
+func shortenExponent(dst []byte) []byte {
+	if n := len(dst); n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+		dst[n-2] = dst[n-1]
+		dst = dst[:n-1]
+	}
+	return dst
+}
+
+// unmarshalBigFloat decodes a number into x, rounding to x's precision if
+// it has one and otherwise choosing one that keeps every digit written.
+// null leaves x unchanged.
+func unmarshalBigFloat(r *Reader, x *big.Float) error {
+	if r.Null() {
+		return nil
+	}
+	s, err := r.number()
+	if err != nil {
+		return err
+	}
+	if x.Prec() == 0 {
+		x.SetPrec(bigFloatPrec(s))
+	}
+	if _, ok := x.SetString(s); !ok {
+		return r.numberError(s, bigFloatType, reflect.Float64, 64)
+	}
+	return nil
+}
+
+// bigFloatPrec returns a precision in bits at which the decimal digits of
+// the number s are written back unchanged, and at least that of a float64.
+func bigFloatPrec(s string) uint {
+	digits := 0
+	for i := 0; i < len(s) && s[i] != 'e' && s[i] != 'E'; i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			digits++
+		}
+	}
+	prec := uint(math.Ceil(float64(digits)*math.Log2(10))) + 1
+	if prec < 64 {
+		prec = 64
+	}
+	return prec
+}
@@ -0,0 +1,93 @@
+package simdjson
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// bignumDecimal keeps a number's text, as a decimal type would.
+type bignumDecimal struct {
+	text string
+}
+
+func (d bignumDecimal) MarshalJSONTo(w *Writer) error {
+	return w.Number(d.text)
+}
+
+func (d *bignumDecimal) UnmarshalJSONFrom(r *Reader) error {
+	s, err := r.Number()
+	d.text = s
+	return err
+}
+
+type bignumLedger struct {
+	Balance big.Int       `json:"balance"`
+	Credit  *big.Int      `json:"credit"`
+	Rate    *big.Float    `json:"rate"`
+	Amounts []*big.Float  `json:"amounts"`
+	Fee     bignumDecimal `json:"fee"`
+}
+
+func TestBigNumbers(t *testing.T) {
+	input := `{"balance":123456789012345678901234567890,"credit":-18446744073709551617,` +
+		`"rate":0.1000000000000000000000001,"amounts":[100,1e400,1.5e-7,-0],"fee":1.10}`
+	var ledger bignumLedger
+	if err := Unmarshal([]byte(input), &ledger); err != nil {
+		t.Fatal(err)
+	}
+	if s := ledger.Balance.String(); s != "123456789012345678901234567890" {
+		t.Errorf("Expected the balance exactly, got %s", s)
+	}
+	if ledger.Rate.Prec() <= 64 {
+		t.Errorf("Expected a precision holding 25 digits, got %d", ledger.Rate.Prec())
+	}
+	if ledger.Fee.text != "1.10" {
+		t.Errorf("Expected fee 1.10, got %s", ledger.Fee.text)
+	}
+
+	data, err := Marshal(&ledger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"balance":123456789012345678901234567890,"credit":-18446744073709551617,` +
+		`"rate":0.1000000000000000000000001,"amounts":[100,1e+400,1.5e-7,-0],"fee":1.10}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	ledger.Balance.SetInt64(7)
+	if err := Unmarshal([]byte(`{"balance":null,"credit":null}`), &ledger); err != nil {
+		t.Fatal(err)
+	}
+	if ledger.Balance.Int64() != 7 || ledger.Credit != nil {
+		t.Errorf("Expected null to keep the balance and clear the credit, got %s, %v", &ledger.Balance, ledger.Credit)
+	}
+
+	// A preset precision is kept
+	f := new(big.Float).SetPrec(8)
+	if err := DecodeInto([]byte(`3.14159`), f); err != nil || f.Prec() != 8 || f.Text('g', -1) != "3.14" {
+		t.Errorf("Expected 3.14 at 8 bits, got %s at %d, %v", f.Text('g', -1), f.Prec(), err)
+	}
+}
+
+func TestBigNumberErrors(t *testing.T) {
+	var n big.Int
+	err := DecodeInto([]byte(`1.5`), &n)
+	var typeErr *UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Value != "number 1.5" || typeErr.Type != bigIntType {
+		t.Errorf("Expected an UnmarshalTypeError for 1.5 into big.Int, got %v", err)
+	}
+	if err := DecodeInto([]byte(`"1"`), &n); err == nil {
+		t.Error("Expected an error for a string")
+	}
+	if _, err := Marshal(new(big.Float).SetInf(false)); err == nil {
+		t.Error("Expected an error for an infinity")
+	}
+	for _, s := range []string{"", "1.", "+1", "0x10", "1 "} {
+		if _, err := Marshal(bignumDecimal{s}); err == nil || !strings.Contains(err.Error(), "invalid number") {
+			t.Errorf("Expected an invalid number error for %q, got %v", s, err)
+		}
+	}
+}
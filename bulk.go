@@ -0,0 +1,186 @@
+package simdjson
+
+import (
+	"reflect"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// Bulk decoders for the homogeneous containers that dominate real
+// workloads: numeric slices (time series, embeddings, ID lists) and the
+// string-keyed maps used for loosely typed payloads. They store parsed
+// values straight into the container instead of going through a
+// reflect.Value per element.
+
+var (
+	int64Type     = reflect.TypeOf(int64(0))
+	float64Type   = reflect.TypeOf(float64(0))
+	stringType    = reflect.TypeOf("")
+	boolType      = reflect.TypeOf(false)
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// bulkPlan returns the bulk decoder for slice type t, or nil if its element
+// type has none or a codec of its own. With Options.CollectErrors the
+// decoder defers to the per-element plan, which collects errors.
+func bulkPlan(t reflect.Type) decodeFunc {
+	if codecFor(t.Elem()) != nil {
+		return nil
+	}
+	var bulk decodeFunc
+	switch t.Elem() {
+	case int64Type:
+		bulk = func(r *Reader, v reflect.Value) error { return bulkSlice(r, v, readInt64) }
+	case float64Type:
+		bulk = func(r *Reader, v reflect.Value) error { return bulkSlice(r, v, readFloat64) }
+	case stringType:
+		bulk = func(r *Reader, v reflect.Value) error { return bulkSlice(r, v, (*Reader).String) }
+	case boolType:
+		bulk = func(r *Reader, v reflect.Value) error { return bulkSlice(r, v, (*Reader).Bool) }
+	default:
+		return nil
+	}
+	slow := newSlicePlan(t)
+	return func(r *Reader, v reflect.Value) error {
+		if r.allErrs {
+			return slow(r, v)
+		}
+		return bulk(r, v)
+	}
+}
+
+func readInt64(r *Reader) (int64, error)     { return r.Int(64) }
+func readFloat64(r *Reader) (float64, error) { return r.Float(64) }
+
+// bulkSlice decodes an array into the []E held by v, reusing its backing
+// array. The slice is sized up front from the array's token span, so a
+// flat array is filled without reallocation.
+func bulkSlice[E any](r *Reader, v reflect.Value, read func(*Reader) (E, error)) error {
+	if r.Null() {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if r.peek() != scanner.TokenArrayBegin {
+		return r.typeError(v.Type())
+	}
+
+	// Every element but the last is followed by a comma token
+	end, err := scanner.SkipTokens(r.tokens, r.pos)
+	if err != nil {
+		return r.fail(errUnexpectedEnd)
+	}
+	n := (end - r.pos) / 2
+
+	// v may be a named slice type; its layout is still that of []E
+	p := (*[]E)(v.Addr().UnsafePointer())
+	old := *p
+	s := old[:0]
+	if cap(s) < n {
+		s = make([]E, 0, n)
+	}
+
+	r.pos++
+	var zero E
+	for r.More() {
+		// A null leaves an element within the old length unchanged, as
+		// encoding/json does
+		if r.Null() {
+			if i := len(s); i < len(old) {
+				s = append(s, old[i])
+			} else {
+				s = append(s, zero)
+			}
+			continue
+		}
+		e, err := read(r)
+		if err != nil {
+			return err
+		}
+		s = append(s, e)
+	}
+	if err := r.EndArray(); err != nil {
+		return err
+	}
+	*p = s
+	return nil
+}
+
+// bulkMapPlan returns the bulk decoder for map type t, or nil if t is not
+// a map[string]string or map[string]interface{} (named or not) or string
+// has a codec. As bulkPlan, the map[string]string decoder defers to the
+// per-member plan with Options.CollectErrors; any value fits an
+// interface{}, so there are no errors to collect for the other.
+func bulkMapPlan(t reflect.Type) decodeFunc {
+	if t.Key() != stringType {
+		return nil
+	}
+	switch t.Elem() {
+	case stringType:
+		if codecFor(stringType) != nil {
+			return nil
+		}
+		slow := newMapPlan(t)
+		return func(r *Reader, v reflect.Value) error {
+			if r.allErrs {
+				return slow(r, v)
+			}
+			return decodeStringMap(r, v)
+		}
+	case interfaceType:
+		return decodeInterfaceMap
+	}
+	return nil
+}
+
+func decodeStringMap(r *Reader, v reflect.Value) error {
+	if r.Null() {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if r.peek() != scanner.TokenObjectBegin {
+		return r.typeError(v.Type())
+	}
+	r.pos++
+
+	p := (*map[string]string)(v.Addr().UnsafePointer())
+	if *p == nil {
+		*p = make(map[string]string)
+	}
+	m := *p
+	for r.More() {
+		key, err := r.keyString()
+		if err != nil {
+			return err
+		}
+		if r.Null() {
+			m[key] = ""
+			continue
+		}
+		if r.peek() != scanner.TokenString {
+			return r.typeError(v.Type().Elem())
+		}
+		s, err := r.String()
+		if err != nil {
+			return err
+		}
+		m[key] = s
+	}
+	return r.EndObject()
+}
+
+func decodeInterfaceMap(r *Reader, v reflect.Value) error {
+	if r.Null() {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if r.peek() != scanner.TokenObjectBegin {
+		return r.typeError(v.Type())
+	}
+	r.pos++
+
+	p := (*map[string]interface{})(v.Addr().UnsafePointer())
+	if *p == nil {
+		*p = make(map[string]interface{})
+	}
+	return r.objectInto(*p)
+}
@@ -0,0 +1,152 @@
+package simdjson
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/b64"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/jsonenc"
+)
+
+// BytesFormat selects how []byte values are written as JSON. The format of
+// a single struct field can be set with the format tag option, which takes
+// the name shown next to each constant:
+//
+//	Key []byte `json:"key,format:base64url"`
+//
+// The rawbytes tag option is short for format:raw.
+//
+// Unknown format names are ignored, as encoding/json ignores unknown
+// options.
+type BytesFormat uint8
+
+const (
+	// BytesBase64 is standard padded base64, as encoding/json writes
+	// (format:base64).
+	BytesBase64 BytesFormat = iota
+	// BytesBase64URL is padded base64 with the URL-safe alphabet
+	// (format:base64url).
+	BytesBase64URL
+	// BytesRawBase64 is standard base64 without padding (format:rawbase64).
+	BytesRawBase64
+	// BytesRawBase64URL is URL-safe base64 without padding, as used by JWT
+	// and WebAuthn (format:rawbase64url).
+	BytesRawBase64URL
+	// BytesHex is lowercase hexadecimal (format:hex). Either case decodes.
+	BytesHex
+	// BytesArray is an array of numbers from 0 to 255 (format:array).
+	BytesArray
+	// BytesRaw is a string of the bytes themselves, for text that needs no
+	// encoding (format:raw). Invalid UTF-8 is written as U+FFFD, as in
+	// strings, so only valid UTF-8 round-trips.
+	BytesRaw
+)
+
+var bytesFormatNames = [...]string{
+	BytesBase64:       "base64",
+	BytesBase64URL:    "base64url",
+	BytesRawBase64:    "rawbase64",
+	BytesRawBase64URL: "rawbase64url",
+	BytesHex:          "hex",
+	BytesArray:        "array",
+	BytesRaw:          "raw",
+}
+
+func (f BytesFormat) String() string {
+	if int(f) < len(bytesFormatNames) {
+		return bytesFormatNames[f]
+	}
+	return "BytesFormat(" + strconv.Itoa(int(f)) + ")"
+}
+
+// parseBytesFormat returns the format named by a format:name or rawbytes
+// option among the comma-separated tag options.
+func parseBytesFormat(opts string) (BytesFormat, bool) {
+	if hasTagOption(opts, "rawbytes") {
+		return BytesRaw, true
+	}
+	name, ok := tagOptionValue(opts, "format:")
+	if !ok {
+		return 0, false
+	}
+	for f, n := range bytesFormatNames {
+		if n == name {
+			return BytesFormat(f), true
+		}
+	}
+	return 0, false
+}
+
+// base64Encoding returns the base64 alphabet and padding of f, nil if f is
+// not a base64 format.
+func (f BytesFormat) base64Encoding() *b64.Encoding {
+	switch f {
+	case BytesBase64:
+		return b64.StdEncoding
+	case BytesBase64URL:
+		return b64.URLEncoding
+	case BytesRawBase64:
+		return b64.RawStdEncoding
+	case BytesRawBase64URL:
+		return b64.RawURLEncoding
+	}
+	return nil
+}
+
+// appendBytes appends b in format f, quoted unless f is BytesArray. html
+// escapes HTML characters in BytesRaw strings.
+func appendBytes(dst, b []byte, f BytesFormat, html bool) []byte {
+	switch f {
+	case BytesRaw:
+		dst = append(dst, '"')
+		dst = jsonenc.AppendString(dst, string(b), html)
+		return append(dst, '"')
+	case BytesHex:
+		dst = append(dst, '"')
+		dst = hex.AppendEncode(dst, b)
+		return append(dst, '"')
+	case BytesArray:
+		dst = append(dst, '[')
+		for i, c := range b {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if c >= 100 {
+				dst = append(dst, '0'+c/100)
+			}
+			if c >= 10 {
+				dst = append(dst, '0'+c/10%10)
+			}
+			dst = append(dst, '0'+c%10)
+		}
+		return append(dst, ']')
+	}
+	enc := f.base64Encoding()
+	if enc == nil {
+		enc = b64.StdEncoding
+	}
+	dst = append(dst, '"')
+	dst = enc.AppendEncode(dst, b)
+	return append(dst, '"')
+}
+
+// decodeBytesText decodes the content of a JSON string holding bytes in
+// format f. BytesArray values are never strings.
+func decodeBytesText(s []byte, f BytesFormat) ([]byte, error) {
+	if f == BytesRaw {
+		return append([]byte{}, s...), nil
+	}
+	if f == BytesHex {
+		b := make([]byte, hex.DecodedLen(len(s)))
+		n, err := hex.Decode(b, s)
+		return b[:n], err
+	}
+	enc := f.base64Encoding()
+	if enc == nil {
+		return nil, errors.New("expected array of bytes")
+	}
+	b := make([]byte, enc.DecodedLen(len(s)))
+	n, err := enc.Decode(b, s)
+	return b[:n], err
+}
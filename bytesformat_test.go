@@ -0,0 +1,185 @@
+package simdjson
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBytesFormat(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0x00, 0x10}
+	tests := []struct {
+		format   BytesFormat
+		expected string
+	}{
+		{BytesBase64, `"+/8AEA=="`},
+		{BytesBase64URL, `"-_8AEA=="`},
+		{BytesRawBase64, `"+/8AEA"`},
+		{BytesRawBase64URL, `"-_8AEA"`},
+		{BytesHex, `"fbff0010"`},
+		{BytesArray, `[251,255,0,16]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			got, err := MarshalWithOptions(data, &MarshalOptions{BytesFormat: tt.format})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			var back []byte
+			p := NewParser(&Options{BytesFormat: tt.format})
+			if err := p.Unmarshal(got, &back); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(back, data) {
+				t.Errorf("Expected %v, got %v", data, back)
+			}
+		})
+	}
+
+	// Any format accepts arrays, as encoding/json does
+	var b []byte
+	if err := Unmarshal([]byte(`[1,2,255]`), &b); err != nil || !bytes.Equal(b, []byte{1, 2, 255}) {
+		t.Errorf("Expected [1 2 255], got %v (%v)", b, err)
+	}
+	if err := Unmarshal([]byte(`[]`), &b); err != nil || b == nil || len(b) != 0 {
+		t.Errorf("Expected empty slice, got %#v (%v)", b, err)
+	}
+	for _, bad := range []string{`[256]`, `[-1]`, `["a"]`, `"not base64!"`} {
+		if err := Unmarshal([]byte(bad), &b); err == nil {
+			t.Errorf("Expected error for %s", bad)
+		}
+	}
+
+	// Escaped slashes are unescaped before decoding
+	if err := Unmarshal([]byte(`"+\/8AEA=="`), &b); err != nil || !bytes.Equal(b, data) {
+		t.Errorf("Expected %v, got %v (%v)", data, b, err)
+	}
+}
+
+// Values long enough for the vectorized codec round-trip in every base64
+// format, and decode as encoding/base64 decodes them
+func TestBytesFormatLong(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	for _, format := range []BytesFormat{BytesBase64, BytesBase64URL, BytesRawBase64, BytesRawBase64URL} {
+		t.Run(format.String(), func(t *testing.T) {
+			got, err := MarshalWithOptions(data, &MarshalOptions{BytesFormat: format})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var back []byte
+			if err := NewParser(&Options{BytesFormat: format}).Unmarshal(got, &back); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(back, data) {
+				t.Errorf("Expected %d bytes back, got %d", len(data), len(back))
+			}
+
+			got[100] = '!'
+			if err := NewParser(&Options{BytesFormat: format}).Unmarshal(got, &back); err == nil {
+				t.Error("Expected an error for a character outside the alphabet")
+			}
+		})
+	}
+}
+
+func TestBytesFormatTag(t *testing.T) {
+	type tagged struct {
+		URL   []byte `json:"url,format:rawbase64url"`
+		Hex   []byte `json:"hex,omitempty,format:hex"`
+		Array []byte `json:"array,format:array"`
+		Plain []byte `json:"plain"`
+		Other []byte `json:"other,format:unknown"`
+	}
+	v := tagged{
+		URL:   []byte{0xfb, 0xff},
+		Hex:   []byte{0xab, 0xcd},
+		Array: []byte{1, 2},
+		Plain: []byte{0xfb, 0xff},
+		Other: []byte{0xfb, 0xff},
+	}
+	expected := `{"url":"-_8","hex":"abcd","array":[1,2],"plain":"+/8=","other":"+/8="}`
+
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	// The tag takes precedence over the option
+	got, _ = MarshalWithOptions(v, &MarshalOptions{BytesFormat: BytesHex})
+	expected = `{"url":"-_8","hex":"abcd","array":[1,2],"plain":"fbff","other":"fbff"}`
+	if string(got) != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	var back tagged
+	if err := Unmarshal([]byte(`{"url":"-_8","hex":"ABCD","array":[1,2],"plain":"+/8=","other":"+/8="}`), &back); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(back.URL, v.URL) || !bytes.Equal(back.Hex, v.Hex) || !bytes.Equal(back.Array, v.Array) ||
+		!bytes.Equal(back.Plain, v.Plain) || !bytes.Equal(back.Other, v.Other) {
+		t.Errorf("Expected %v, got %v", v, back)
+	}
+	if err := Unmarshal([]byte(`{"url":"+/8="}`), &back); err == nil {
+		t.Error("Expected error for standard base64 in a base64url field")
+	}
+
+	// A nil slice in array format is empty with NilAsEmpty
+	got, _ = MarshalWithOptions(tagged{}, &MarshalOptions{NilAsEmpty: true})
+	expected = `{"url":"","array":[],"plain":"","other":""}`
+	if string(got) != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestBytesRaw(t *testing.T) {
+	type message struct {
+		Body  []byte `json:"body,rawbytes"`
+		Sig   []byte `json:"sig,format:raw"`
+		Plain []byte `json:"plain"`
+	}
+	v := message{Body: []byte("héllo \"<w>\"\n"), Sig: []byte{}, Plain: []byte("hi")}
+	expected := `{"body":"héllo \"\u003cw\u003e\"\n","sig":"","plain":"aGk="}`
+
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	var back message
+	if err := Unmarshal(got, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(back.Body, v.Body) || back.Sig == nil || len(back.Sig) != 0 || !bytes.Equal(back.Plain, v.Plain) {
+		t.Errorf("Expected %q, got %q", v, back)
+	}
+
+	// Invalid UTF-8 is replaced as in strings
+	got, err = MarshalWithOptions([]byte{'a', 0xff}, &MarshalOptions{BytesFormat: BytesRaw})
+	if err != nil || string(got) != "\"a\uFFFD\"" {
+		t.Errorf("Expected \"a\uFFFD\", got %s, %v", got, err)
+	}
+
+	// The decoded bytes do not alias the input
+	data := []byte(`"abc"`)
+	var b []byte
+	if err := NewParser(&Options{BytesFormat: BytesRaw}).Unmarshal(data, &b); err != nil || string(b) != "abc" {
+		t.Fatalf("Expected abc, got %q, %v", b, err)
+	}
+	data[1] = 'x'
+	if string(b) != "abc" {
+		t.Errorf("Expected a copy of the input, got %q", b)
+	}
+}
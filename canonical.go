@@ -0,0 +1,213 @@
+package simdjson
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// Canonicalize returns data in the JSON Canonicalization Scheme (RFC 8785)
+// form: no whitespace, object members sorted by the UTF-16 code units of
+// their keys, numbers formatted as ECMAScript formats doubles, and strings
+// with only the escapes JSON requires. Documents that differ only in
+// formatting canonicalize to the same bytes, so the result can be hashed
+// or signed and checked by other JCS implementations.
+//
+// As RFC 8785 requires I-JSON input, duplicate keys and numbers beyond
+// float64 range are errors.
+func Canonicalize(data []byte) ([]byte, error) {
+	s := scanner.New()
+	defer s.Release()
+	d, err := parseDocument(s, data)
+	if err != nil {
+		return nil, err
+	}
+	defer d.release()
+
+	return appendCanonical(make([]byte, 0, len(data)), d, 0)
+}
+
+// MarshalCanonical returns the JSON encoding of v in canonical form; see
+// Canonicalize. Integers beyond ±2^53 lose precision, since canonical
+// numbers are doubles.
+func MarshalCanonical(v interface{}) ([]byte, error) {
+	data, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Canonicalize(data)
+}
+
+// appendCanonical appends the canonical form of the value at pos.
+func appendCanonical(dst []byte, d *document, pos int) ([]byte, error) {
+	switch d.kind(pos) {
+	case scanner.TokenObjectBegin:
+		type member struct {
+			key string
+			val int
+		}
+		var members []member
+		d.members(pos, func(k, v int) {
+			members = append(members, member{string(d.str(k)), v})
+		})
+		sort.Slice(members, func(i, j int) bool {
+			return lessUTF16(members[i].key, members[j].key)
+		})
+
+		dst = append(dst, '{')
+		for i, m := range members {
+			if i > 0 {
+				if m.key == members[i-1].key {
+					return nil, errors.New("duplicate key " + strconv.Quote(m.key))
+				}
+				dst = append(dst, ',')
+			}
+			dst = appendCanonicalString(dst, m.key)
+			dst = append(dst, ':')
+			var err error
+			if dst, err = appendCanonical(dst, d, m.val); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, '}'), nil
+	case scanner.TokenArrayBegin:
+		dst = append(dst, '[')
+		var err error
+		n := 0
+		d.elements(pos, func(e int) {
+			if err != nil {
+				return
+			}
+			if n > 0 {
+				dst = append(dst, ',')
+			}
+			n++
+			dst, err = appendCanonical(dst, d, e)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return append(dst, ']'), nil
+	case scanner.TokenString:
+		return appendCanonicalString(dst, string(d.str(pos))), nil
+	case scanner.TokenNumber:
+		raw := d.raw(pos)
+		f, err := strconv.ParseFloat(string(raw), 64)
+		if err != nil {
+			return nil, errors.New("number out of range: " + string(raw))
+		}
+		return appendCanonicalNumber(dst, f), nil
+	}
+	return append(dst, d.raw(pos)...), nil
+}
+
+// lessUTF16 reports whether a sorts before b when both are compared as
+// UTF-16 code units, as RFC 8785 orders keys. This differs from byte order
+// only between characters above U+FFFF, which encode as surrogates
+// (0xD800-0xDFFF), and characters from U+E000 to U+FFFF.
+func lessUTF16(a, b string) bool {
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if ra != rb {
+			switch {
+			case ra > 0xFFFF && rb <= 0xFFFF:
+				return 0xD800 < rb
+			case rb > 0xFFFF && ra <= 0xFFFF:
+				return ra < 0xD800
+			}
+			return ra < rb
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return len(a) < len(b)
+}
+
+// appendCanonicalString appends s quoted with the escapes of RFC 8785:
+// short escapes for the quote, backslash and \b \t \n \f \r, \u00xx for
+// the other control characters, and everything else as is.
+func appendCanonicalString(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	dst = append(dst, '"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			dst = append(dst, '\\', c)
+		case c >= 0x20:
+			dst = append(dst, c)
+		case c == '\b':
+			dst = append(dst, '\\', 'b')
+		case c == '\t':
+			dst = append(dst, '\\', 't')
+		case c == '\n':
+			dst = append(dst, '\\', 'n')
+		case c == '\f':
+			dst = append(dst, '\\', 'f')
+		case c == '\r':
+			dst = append(dst, '\\', 'r')
+		default:
+			dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+		}
+	}
+	return append(dst, '"')
+}
+
+// appendCanonicalNumber appends f as ECMAScript's Number.prototype.toString
+// formats it: the shortest digits that round-trip, in plain notation for
+// exponents from -7 to 20 and in exponent notation otherwise.
+func appendCanonicalNumber(dst []byte, f float64) []byte {
+	if f == 0 {
+		// Including -0
+		return append(dst, '0')
+	}
+	if f < 0 {
+		dst = append(dst, '-')
+		f = -f
+	}
+
+	var buf [32]byte
+	b := strconv.AppendFloat(buf[:0], f, 'e', -1, 64)
+	e := bytes.IndexByte(b, 'e')
+	exp, _ := strconv.Atoi(string(b[e+1:]))
+	digits := b[:e]
+	if len(digits) > 1 {
+		// Drop the decimal point after the first digit
+		digits = append(digits[:1], digits[2:]...)
+	}
+
+	// The value is 0.digits × 10^n
+	n, k := exp+1, len(digits)
+	switch {
+	case k <= n && n <= 21:
+		dst = append(dst, digits...)
+		for i := k; i < n; i++ {
+			dst = append(dst, '0')
+		}
+	case 0 < n && n <= 21:
+		dst = append(dst, digits[:n]...)
+		dst = append(dst, '.')
+		dst = append(dst, digits[n:]...)
+	case -6 < n && n <= 0:
+		dst = append(dst, '0', '.')
+		for i := n; i < 0; i++ {
+			dst = append(dst, '0')
+		}
+		dst = append(dst, digits...)
+	default:
+		dst = append(dst, digits[0])
+		if k > 1 {
+			dst = append(dst, '.')
+			dst = append(dst, digits[1:]...)
+		}
+		dst = append(dst, 'e')
+		if n-1 > 0 {
+			dst = append(dst, '+')
+		}
+		dst = strconv.AppendInt(dst, int64(n-1), 10)
+	}
+	return dst
+}
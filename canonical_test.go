@@ -0,0 +1,131 @@
+package simdjson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		// RFC 8785, section 3.2.2
+		{"rfc_example", `{
+			"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+			"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+			"literals": [null, true, false]
+		}`, `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`},
+		// RFC 8785, section 3.2.3
+		{"rfc_sorting", `{
+			"\u20ac": "Euro Sign",
+			"\r": "Carriage Return",
+			"\ufb33": "Hebrew Letter Dalet With Dagesh",
+			"1": "One",
+			"\ud83d\ude00": "Emoji: Grinning Face",
+			"\u0080": "Control",
+			"\u00f6": "Latin Small Letter O With Diaeresis"
+		}`, "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\"," +
+			"\"€\":\"Euro Sign\",\"\U0001F600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}"},
+
+		{"nested", `{"b": {"z": 1, "a": [ {"y":2, "x":1} ]}, "a": "x"}`, `{"a":"x","b":{"a":[{"x":1,"y":2}],"z":1}}`},
+		{"scalar", ` -0.0 `, `0`},
+		{"control_chars", `"\u0001\u001f\t\u007f"`, "\"\\u0001\\u001f\\t\u007f\""},
+		{"escaped_key_order", `{"\u0062":1,"a":2}`, `{"a":2,"b":1}`},
+		{"empty", `[{}, [], ""]`, `[{},[],""]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Canonicalize([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+
+	for _, input := range []string{`{"a":1,"a":2}`, `{"\u0061":1,"a":2}`, `[1e400]`, `{"a":}`} {
+		if _, err := Canonicalize([]byte(input)); err == nil {
+			t.Errorf("Expected error for %s", input)
+		}
+	}
+}
+
+func TestCanonicalNumbers(t *testing.T) {
+	// RFC 8785, Appendix B
+	tests := []struct {
+		bits     uint64
+		expected string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+
+	for _, tt := range tests {
+		if got := string(appendCanonicalNumber(nil, math.Float64frombits(tt.bits))); got != tt.expected {
+			t.Errorf("%#016x: expected %s, got %s", tt.bits, tt.expected, got)
+		}
+	}
+}
+
+func TestMarshalCanonical(t *testing.T) {
+	type item struct {
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
+	}
+	v := map[string]interface{}{
+		"z":      []item{{"b", 1.50}, {"a", 1e21}},
+		"a":      int64(1) << 53,
+		"\u00e9": "<&>",
+	}
+
+	result, err := MarshalCanonical(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"a":9007199254740992,"z":[{"name":"b","price":1.5},{"name":"a","price":1e+21}],"é":"<&>"}`
+	if string(result) != expected {
+		t.Errorf("Expected %s, got %s", expected, result)
+	}
+
+	if _, err := MarshalCanonical(math.NaN()); err == nil {
+		t.Error("Expected error for NaN")
+	}
+}
+
+func TestLessUTF16(t *testing.T) {
+	keys := []string{"", "a", "ab", "b", "\u00f6", "\ud7ff", "\U0001F600", "\ue000", "\uffff"}
+	for i := range keys {
+		for j := range keys {
+			if got := lessUTF16(keys[i], keys[j]); got != (i < j) {
+				t.Errorf("lessUTF16(%q, %q): expected %v, got %v", keys[i], keys[j], i < j, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,506 @@
+package simdjson
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/jsonenc"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// CBOR (RFC 8949) major types
+const (
+	cborUnsigned = 0 << 5
+	cborNegative = 1 << 5
+	cborBytes    = 2 << 5
+	cborText     = 3 << 5
+	cborArray    = 4 << 5
+	cborMap      = 5 << 5
+	cborTag      = 6 << 5
+	cborSimple   = 7 << 5
+)
+
+var (
+	errCBOREnd      = errors.New("unexpected end of CBOR data")
+	errCBORTrailing = errors.New("unexpected data after top-level CBOR item")
+	errCBORDepth    = errors.New("exceeded max nesting depth")
+)
+
+// ToCBOR transcodes a JSON document to CBOR (RFC 8949) without decoding it
+// into Go values. The output uses the preferred serialization: definite
+// lengths, the shortest integer encodings, and floats in the smallest of
+// half, single and double precision that holds the value exactly.
+// Integers beyond 64 bits become bignums (tags 2 and 3).
+func ToCBOR(data []byte) ([]byte, error) {
+	s := scanner.New()
+	defer s.Release()
+	d, err := parseDocument(s, data)
+	if err != nil {
+		return nil, err
+	}
+	defer d.release()
+
+	return appendCBOR(make([]byte, 0, len(data)), d, 0)
+}
+
+// appendCBOR appends the CBOR encoding of the value at pos.
+func appendCBOR(dst []byte, d *document, pos int) ([]byte, error) {
+	var err error
+	switch d.kind(pos) {
+	case scanner.TokenObjectBegin:
+		n := 0
+		d.members(pos, func(_, _ int) { n++ })
+		dst = appendCBORHead(dst, cborMap, uint64(n))
+		d.members(pos, func(k, v int) {
+			if err == nil {
+				key := d.str(k)
+				dst = appendCBORHead(dst, cborText, uint64(len(key)))
+				dst = append(dst, key...)
+				dst, err = appendCBOR(dst, d, v)
+			}
+		})
+	case scanner.TokenArrayBegin:
+		n := 0
+		d.elements(pos, func(_ int) { n++ })
+		dst = appendCBORHead(dst, cborArray, uint64(n))
+		d.elements(pos, func(e int) {
+			if err == nil {
+				dst, err = appendCBOR(dst, d, e)
+			}
+		})
+	case scanner.TokenString:
+		s := d.str(pos)
+		dst = appendCBORHead(dst, cborText, uint64(len(s)))
+		dst = append(dst, s...)
+	case scanner.TokenNumber:
+		dst, err = appendCBORNumber(dst, d.raw(pos))
+	case scanner.TokenFalse:
+		dst = append(dst, cborSimple|20)
+	case scanner.TokenTrue:
+		dst = append(dst, cborSimple|21)
+	case scanner.TokenNull:
+		dst = append(dst, cborSimple|22)
+	}
+	return dst, err
+}
+
+// appendCBORHead appends the initial bytes of an item of the given major
+// type with argument n: a length, a count, a tag or an integer value.
+func appendCBORHead(dst []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(dst, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(dst, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(dst, major|27), n)
+}
+
+// appendCBORNumber appends a JSON number as a CBOR integer if it is written
+// as one, and as a float otherwise. -0 stays a float to keep its sign.
+func appendCBORNumber(dst []byte, num []byte) ([]byte, error) {
+	s := string(num)
+	if !bytes.ContainsAny(num, ".eE") && s != "-0" {
+		if num[0] == '-' {
+			if n, err := strconv.ParseUint(s[1:], 10, 64); err == nil {
+				return appendCBORHead(dst, cborNegative, n-1), nil
+			}
+			if s == "-18446744073709551616" {
+				return appendCBORHead(dst, cborNegative, math.MaxUint64), nil
+			}
+		} else if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return appendCBORHead(dst, cborUnsigned, n), nil
+		}
+
+		// A bignum: tag 2 holds n, tag 3 holds -1-n for negative n
+		n, _ := new(big.Int).SetString(s, 10)
+		tag := uint64(2)
+		if n.Sign() < 0 {
+			tag = 3
+			n.Not(n)
+		}
+		b := n.Bytes()
+		dst = appendCBORHead(dst, cborTag, tag)
+		dst = appendCBORHead(dst, cborBytes, uint64(len(b)))
+		return append(dst, b...), nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, errors.New("number out of range: " + s)
+	}
+	if f32 := float32(f); float64(f32) == f {
+		if h, ok := float16Bits(f32); ok {
+			return binary.BigEndian.AppendUint16(append(dst, cborSimple|25), h), nil
+		}
+		return binary.BigEndian.AppendUint32(append(dst, cborSimple|26), math.Float32bits(f32)), nil
+	}
+	return binary.BigEndian.AppendUint64(append(dst, cborSimple|27), math.Float64bits(f)), nil
+}
+
+// float16Bits returns the IEEE 754 half-precision encoding of f, or false
+// if f is not exactly representable in half precision.
+func float16Bits(f float32) (uint16, bool) {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23&0xff) - 127
+	mant := bits & 0x7fffff
+	switch {
+	case bits&0x7fffffff == 0:
+		return sign, true
+	case exp >= -14 && exp <= 15:
+		// Normal: the low 13 bits of the mantissa must be zero
+		if mant&0x1fff != 0 {
+			return 0, false
+		}
+		return sign | uint16(exp+15)<<10 | uint16(mant>>13), true
+	case exp >= -24 && exp < -14:
+		// Subnormal: the value is m × 2^-24 for a 10-bit m
+		full, shift := mant|0x800000, uint(-(exp + 1))
+		if full&(1<<shift-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(full>>shift), true
+	}
+	return 0, false
+}
+
+// float16 returns the value of the half-precision float h.
+func float16(h uint16) float64 {
+	exp, mant := int(h>>10&0x1f), float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		f = math.Inf(1)
+		if mant != 0 {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
+
+// FromCBOR transcodes a CBOR (RFC 8949) item to JSON without decoding it
+// into Go values, following the conversions of RFC 8949, section 6.1:
+//
+//   - byte strings become base64url strings without padding, or base64 or
+//     hex strings when tagged 22 or 23
+//   - bignums (tags 2 and 3) become integers
+//   - other tags are dropped and their content converted
+//   - NaN, infinities and undefined become null
+//   - integer map keys become strings; other non-string keys are errors
+//
+// Indefinite-length strings, arrays and maps are accepted.
+func FromCBOR(data []byte) ([]byte, error) {
+	d := cborDecoder{data: data}
+	dst, err := d.value(make([]byte, 0, len(data)*2))
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(data) {
+		return nil, errCBORTrailing
+	}
+	return dst, nil
+}
+
+// cborDecoder reads CBOR items from data.
+type cborDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+// head reads the initial bytes of an item. For indefinite lengths, info is
+// 31 and n is zero.
+func (d *cborDecoder) head() (major, info byte, n uint64, err error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, 0, errCBOREnd
+	}
+	b := d.data[d.pos]
+	d.pos++
+	major, info = b&0xe0, b&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		size := 1 << (info - 24)
+		if len(d.data)-d.pos < size {
+			return 0, 0, 0, errCBOREnd
+		}
+		for _, c := range d.data[d.pos : d.pos+size] {
+			n = n<<8 | uint64(c)
+		}
+		d.pos += size
+		return major, info, n, nil
+	case info == 31 && major != cborUnsigned && major != cborNegative && major != cborTag:
+		return major, info, 0, nil
+	}
+	return 0, 0, 0, errors.New("invalid CBOR initial byte 0x" + strconv.FormatUint(uint64(b), 16))
+}
+
+// breakNext reports whether the next byte ends an indefinite-length item,
+// consuming it if so.
+func (d *cborDecoder) breakNext() (bool, error) {
+	if d.pos >= len(d.data) {
+		return false, errCBOREnd
+	}
+	if d.data[d.pos] == 0xff {
+		d.pos++
+		return true, nil
+	}
+	return false, nil
+}
+
+// str reads the contents of a byte or text string whose head has been
+// read, joining the chunks of an indefinite-length string.
+func (d *cborDecoder) str(major, info byte, n uint64) ([]byte, error) {
+	if info != 31 {
+		if n > uint64(len(d.data)-d.pos) {
+			return nil, errCBOREnd
+		}
+		s := d.data[d.pos : d.pos+int(n)]
+		d.pos += int(n)
+		return s, nil
+	}
+
+	var s []byte
+	for {
+		if done, err := d.breakNext(); done || err != nil {
+			return s, err
+		}
+		chunkMajor, chunkInfo, n, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == 31 {
+			return nil, errors.New("invalid chunk in indefinite-length CBOR string")
+		}
+		chunk, err := d.str(chunkMajor, chunkInfo, n)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, chunk...)
+	}
+}
+
+// value appends the next item as JSON.
+func (d *cborDecoder) value(dst []byte) ([]byte, error) {
+	major, info, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborUnsigned:
+		return strconv.AppendUint(dst, n, 10), nil
+	case cborNegative:
+		return appendCBORNegative(dst, n), nil
+	case cborBytes:
+		b, err := d.str(major, info, n)
+		if err != nil {
+			return nil, err
+		}
+		return appendBase64URL(dst, b), nil
+	case cborText:
+		s, err := d.str(major, info, n)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(s) {
+			return nil, errors.New("invalid UTF-8 in CBOR text string")
+		}
+		return appendJSONString(dst, s), nil
+	case cborArray, cborMap:
+		if d.depth++; d.depth > scanner.MaxDepth {
+			return nil, errCBORDepth
+		}
+		defer func() { d.depth-- }()
+		if major == cborArray {
+			return d.array(dst, info, n)
+		}
+		return d.object(dst, info, n)
+	case cborTag:
+		return d.tagged(dst, n)
+	}
+
+	switch info {
+	case 20:
+		return append(dst, "false"...), nil
+	case 21:
+		return append(dst, "true"...), nil
+	case 22, 23:
+		// null and undefined
+		return append(dst, "null"...), nil
+	case 25:
+		return appendCBORFloat(dst, float16(uint16(n))), nil
+	case 26:
+		return appendCBORFloat(dst, float64(math.Float32frombits(uint32(n)))), nil
+	case 27:
+		return appendCBORFloat(dst, math.Float64frombits(n)), nil
+	case 31:
+		return nil, errors.New("unexpected CBOR break")
+	}
+	return nil, errors.New("unsupported CBOR simple value " + strconv.FormatUint(n, 10))
+}
+
+func (d *cborDecoder) array(dst []byte, info byte, n uint64) ([]byte, error) {
+	// Every element takes at least a byte
+	if info != 31 && n > uint64(len(d.data)-d.pos) {
+		return nil, errCBOREnd
+	}
+	dst = append(dst, '[')
+	for i := uint64(0); info == 31 || i < n; i++ {
+		if info == 31 {
+			if done, err := d.breakNext(); err != nil {
+				return nil, err
+			} else if done {
+				break
+			}
+		}
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = d.value(dst); err != nil {
+			return nil, err
+		}
+	}
+	return append(dst, ']'), nil
+}
+
+func (d *cborDecoder) object(dst []byte, info byte, n uint64) ([]byte, error) {
+	if info != 31 && n > uint64(len(d.data)-d.pos)/2 {
+		return nil, errCBOREnd
+	}
+	dst = append(dst, '{')
+	for i := uint64(0); info == 31 || i < n; i++ {
+		if info == 31 {
+			if done, err := d.breakNext(); err != nil {
+				return nil, err
+			} else if done {
+				break
+			}
+		}
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = d.key(dst); err != nil {
+			return nil, err
+		}
+		dst = append(dst, ':')
+		if dst, err = d.value(dst); err != nil {
+			return nil, err
+		}
+	}
+	return append(dst, '}'), nil
+}
+
+// key appends a map key as a JSON string. Integer keys are converted to
+// their decimal form.
+func (d *cborDecoder) key(dst []byte) ([]byte, error) {
+	start := d.pos
+	major, _, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborText:
+		d.pos = start
+		return d.value(dst)
+	case cborUnsigned:
+		return append(strconv.AppendUint(append(dst, '"'), n, 10), '"'), nil
+	case cborNegative:
+		return append(appendCBORNegative(append(dst, '"'), n), '"'), nil
+	}
+	return nil, errors.New("unsupported CBOR map key type " + strconv.Itoa(int(major>>5)))
+}
+
+// tagged appends the content of an item with tag n.
+func (d *cborDecoder) tagged(dst []byte, tag uint64) ([]byte, error) {
+	if d.depth++; d.depth > scanner.MaxDepth {
+		return nil, errCBORDepth
+	}
+	defer func() { d.depth-- }()
+
+	// Bignums and byte string encoding hints only apply to byte strings
+	if d.pos >= len(d.data) {
+		return nil, errCBOREnd
+	}
+	if d.data[d.pos]&0xe0 != cborBytes || tag < 2 || (tag > 3 && tag < 21) || tag > 23 {
+		return d.value(dst)
+	}
+	major, info, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.str(major, info, n)
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 2, 3:
+		v := new(big.Int).SetBytes(b)
+		if tag == 3 {
+			v.Not(v)
+		}
+		return v.Append(dst, 10), nil
+	case 21:
+		return appendBase64URL(dst, b), nil
+	case 22:
+		dst = append(dst, '"')
+		dst = base64.StdEncoding.AppendEncode(dst, b)
+		return append(dst, '"'), nil
+	}
+	dst = append(dst, '"')
+	dst = hex.AppendEncode(dst, b)
+	return append(dst, '"'), nil
+}
+
+// appendCBORNegative appends the CBOR negative integer -1-n.
+func appendCBORNegative(dst []byte, n uint64) []byte {
+	dst = append(dst, '-')
+	if n == math.MaxUint64 {
+		return append(dst, "18446744073709551616"...)
+	}
+	return strconv.AppendUint(dst, n+1, 10)
+}
+
+// appendCBORFloat appends f, or null if JSON cannot represent it. Half and
+// single precision values are formatted with the digits of their exact
+// double value, so they read back unchanged.
+func appendCBORFloat(dst []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return append(dst, "null"...)
+	}
+	return jsonenc.AppendFloat(dst, f, 64)
+}
+
+func appendBase64URL(dst, b []byte) []byte {
+	dst = append(dst, '"')
+	dst = base64.RawURLEncoding.AppendEncode(dst, b)
+	return append(dst, '"')
+}
+
+// appendJSONString appends s, which is valid UTF-8, as a JSON string.
+func appendJSONString(dst, s []byte) []byte {
+	dst = append(dst, '"')
+	dst = jsonenc.AppendString(dst, string(s), false)
+	return append(dst, '"')
+}
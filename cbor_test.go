@@ -0,0 +1,239 @@
+package simdjson
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"testing"
+)
+
+// RFC 8949, Appendix A
+var cborExamples = []struct {
+	json string
+	cbor string
+}{
+	{`0`, "00"},
+	{`1`, "01"},
+	{`10`, "0a"},
+	{`23`, "17"},
+	{`24`, "1818"},
+	{`25`, "1819"},
+	{`100`, "1864"},
+	{`1000`, "1903e8"},
+	{`1000000`, "1a000f4240"},
+	{`1000000000000`, "1b000000e8d4a51000"},
+	{`18446744073709551615`, "1bffffffffffffffff"},
+	{`18446744073709551616`, "c249010000000000000000"},
+	{`-18446744073709551616`, "3bffffffffffffffff"},
+	{`-18446744073709551617`, "c349010000000000000000"},
+	{`-1`, "20"},
+	{`-10`, "29"},
+	{`-100`, "3863"},
+	{`-1000`, "3903e7"},
+	{`0.0`, "f90000"},
+	{`-0.0`, "f98000"},
+	{`1.0`, "f93c00"},
+	{`1.1`, "fb3ff199999999999a"},
+	{`1.5`, "f93e00"},
+	{`65504.0`, "f97bff"},
+	{`100000.0`, "fa47c35000"},
+	{`3.4028234663852886e+38`, "fa7f7fffff"},
+	{`1.0e+300`, "fb7e37e43c8800759c"},
+	{`5.960464477539063e-8`, "f90001"},
+	{`0.00006103515625`, "f90400"},
+	{`-4.0`, "f9c400"},
+	{`-4.1`, "fbc010666666666666"},
+	{`false`, "f4"},
+	{`true`, "f5"},
+	{`null`, "f6"},
+	{`""`, "60"},
+	{`"a"`, "6161"},
+	{`"IETF"`, "6449455446"},
+	{`"\"\\"`, "62225c"},
+	{`"ü"`, "62c3bc"},
+	{`"水"`, "63e6b0b4"},
+	{`"𐅑"`, "64f0908591"},
+	{`[]`, "80"},
+	{`[1,2,3]`, "83010203"},
+	{`[1,[2,3],[4,5]]`, "8301820203820405"},
+	{`[1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,20,21,22,23,24,25]`,
+		"98190102030405060708090a0b0c0d0e0f101112131415161718181819"},
+	{`{}`, "a0"},
+	{`{"a":1,"b":[2,3]}`, "a26161016162820203"},
+	{`["a",{"b":"c"}]`, "826161a161626163"},
+	{`{"a":"A","b":"B","c":"C","d":"D","e":"E"}`, "a56161614161626142616361436164614461656145"},
+}
+
+func TestToCBOR(t *testing.T) {
+	for _, tt := range cborExamples {
+		t.Run(tt.json, func(t *testing.T) {
+			result, err := ToCBOR([]byte(tt.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(result); got != tt.cbor {
+				t.Errorf("Expected %s, got %s", tt.cbor, got)
+			}
+		})
+	}
+
+	// Whitespace and escapes do not reach the output
+	result, err := ToCBOR([]byte(` { "a" : [ 1 , "ü" ] } `))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(result); got != "a16161820162c3bc" {
+		t.Errorf("Expected a16161820162c3bc, got %s", got)
+	}
+
+	for _, input := range []string{`{"a":}`, `[1e400]`} {
+		if _, err := ToCBOR([]byte(input)); err == nil {
+			t.Errorf("Expected error for %s", input)
+		}
+	}
+}
+
+func TestFromCBOR(t *testing.T) {
+	for _, tt := range cborExamples {
+		t.Run(tt.json, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.cbor)
+			result, err := FromCBOR(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(result, []byte(tt.json)) {
+				t.Errorf("Expected %s, got %s", tt.json, result)
+			}
+		})
+	}
+
+	tests := []struct {
+		name     string
+		cbor     string
+		expected string
+	}{
+		{"indefinite_bytes", "5f42010243030405ff", `"AQIDBAU"`},
+		{"indefinite_text", "7f657374726561646d696e67ff", `"streaming"`},
+		{"indefinite_empty_array", "9fff", `[]`},
+		{"indefinite_nested", "9f018202039f0405ffff", `[1,[2,3],[4,5]]`},
+		{"indefinite_map", "bf61610161629f0203ffff", `{"a":1,"b":[2,3]}`},
+		{"indefinite_map_mixed", "bf6346756ef563416d7421ff", `{"Fun":true,"Amt":-2}`},
+		{"date_tag", "c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`},
+		{"epoch_tag", "c11a514b67b0", `1363896240`},
+		{"base16_hint", "d74401020304", `"01020304"`},
+		{"base64_hint", "d6440102fbff", `"AQL7/w=="`},
+		{"base64url", "440102fbff", `"AQL7_w"`},
+		{"embedded_cbor", "d818456449455446", `"ZElFVEY"`},
+		{"undefined", "f7", `null`},
+		{"infinity", "f97c00", `null`},
+		{"nan", "f97e00", `null`},
+		{"float32_infinity", "fa7f800000", `null`},
+		{"float32", "fa3dcccccd", `0.10000000149011612`},
+		{"integer_keys", "a201020304", `{"1":2,"3":4}`},
+		{"negative_key", "a12001", `{"-1":1}`},
+		{"escaped_text", "6322610a", `"\"a\n"`},
+		{"min_negative", "3bffffffffffffffff", `-18446744073709551616`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.cbor)
+			result, err := FromCBOR(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+			if !Valid(result) {
+				t.Errorf("Invalid JSON %s", result)
+			}
+		})
+	}
+
+	errorCases := []string{
+		"",                   // empty
+		"1b0000",             // truncated integer
+		"0000",               // trailing data
+		"ff",                 // break outside indefinite item
+		"1c",                 // reserved additional information
+		"61ff",               // invalid UTF-8
+		"5bffffffffffffffff", // length beyond input
+		"9bffffffffffffffff", // count beyond input
+		"a18001",             // array key
+		"5f6161ff",           // text chunk in byte string
+		"9f01",               // unterminated indefinite array
+		"f818",               // unsupported simple value
+	}
+	for _, c := range errorCases {
+		data, _ := hex.DecodeString(c)
+		if _, err := FromCBOR(data); err == nil {
+			t.Errorf("Expected error for %s", c)
+		}
+	}
+
+	deep := append(bytes.Repeat([]byte{0x81}, 20000), 0x00)
+	if _, err := FromCBOR(deep); err == nil {
+		t.Error("Expected error for deep nesting")
+	}
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	docs := []string{
+		`{"id":123,"name":"sensor","readings":[21.5,22.25,-0.5,1e-7],"ok":true,"meta":null}`,
+		`[0,-1,255,256,65535,65536,4294967295,4294967296,-9223372036854775808,123456789012345678901234567890]`,
+		`{"nested":{"deep":[[[{"x":"é😀"}]]]}}`,
+		createLargeArray(100),
+	}
+
+	for _, doc := range docs {
+		cbor, err := ToCBOR([]byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := FromCBOR(cbor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Equal(result, []byte(doc)) {
+			t.Errorf("Expected %.80s, got %.80s", doc, result)
+		}
+	}
+}
+
+func TestFloat16(t *testing.T) {
+	for h := 0; h <= 0xffff; h++ {
+		f := float16(uint16(h))
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			continue
+		}
+		got, ok := float16Bits(float32(f))
+		if !ok || (got != uint16(h) && f != 0) {
+			t.Fatalf("%#04x: float16Bits(%v) = %#04x, %v", h, f, got, ok)
+		}
+	}
+	if _, ok := float16Bits(0.1); ok {
+		t.Error("Expected 0.1 not to fit in half precision")
+	}
+}
+
+func BenchmarkToCBOR(b *testing.B) {
+	doc := []byte(createLargeArray(1000))
+	b.SetBytes(int64(len(doc)))
+	for i := 0; i < b.N; i++ {
+		if _, err := ToCBOR(doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFromCBOR(b *testing.B) {
+	data, _ := ToCBOR([]byte(createLargeArray(1000)))
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := FromCBOR(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,643 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/biggeezerdevelopment/simdjson-go"
+)
+
+const runtimeImport = "github.com/biggeezerdevelopment/simdjson-go"
+
+// field is an exported struct field as seen by encoding/json.
+type field struct {
+	goName    string
+	jsonName  string
+	omitempty bool
+	required  bool
+	typ       ast.Expr
+
+	// Runtime constant of the format tag option, "" if there is none
+	bytesFormat string
+
+	// Digits of the prec tag option, "" if there is none
+	floatPrec string
+}
+
+// generator emits code for the struct types of one source file.
+type generator struct {
+	buf       bytes.Buffer
+	specs     map[string]*ast.TypeSpec // every type declared in the file
+	generated map[string]bool          // structs receiving methods
+
+	prec    string          // prec tag option of the field being encoded
+	nonNull bool            // the field being encoded is behind an omitempty guard
+	imports map[string]bool // standard packages the generated code uses
+}
+
+// generate returns the formatted source of the methods for the named
+// struct types in filename, or for all its structs if names is empty. src
+// is passed to go/parser and may be nil to read filename. Fields without a
+// name in their json tag are named by naming.
+func generate(filename string, src interface{}, names []string, naming simdjson.FieldNaming) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		specs:     make(map[string]*ast.TypeSpec),
+		generated: make(map[string]bool),
+		imports:   make(map[string]bool),
+	}
+	var order []string
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if ts.TypeParams != nil {
+				continue
+			}
+			g.specs[ts.Name.Name] = ts
+			if _, ok := ts.Type.(*ast.StructType); ok && len(names) == 0 {
+				order = append(order, ts.Name.Name)
+			}
+		}
+	}
+	for _, name := range names {
+		ts, ok := g.specs[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s not found in %s", name, filename)
+		}
+		if _, ok := ts.Type.(*ast.StructType); !ok {
+			return nil, fmt.Errorf("type %s is not a struct", name)
+		}
+		order = append(order, name)
+	}
+	if len(order) == 0 {
+		return nil, fmt.Errorf("no struct types found in %s", filename)
+	}
+	for _, name := range order {
+		g.generated[name] = true
+	}
+
+	for _, name := range order {
+		fields, err := structFields(g.specs[name].Type.(*ast.StructType), naming)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		g.marshal(name, fields)
+		g.unmarshal(name, fields)
+	}
+
+	// The imports are known once the methods are generated
+	var code bytes.Buffer
+	fmt.Fprintf(&code, "// Code generated by simdjson-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&code, "package %s\n\nimport (\n", file.Name.Name)
+	for _, path := range slices.Sorted(maps.Keys(g.imports)) {
+		fmt.Fprintf(&code, "%q\n", path)
+	}
+	fmt.Fprintf(&code, "\nsimdjson %q\n)\n", runtimeImport)
+	code.Write(g.buf.Bytes())
+
+	out, err := format.Source(code.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v", err)
+	}
+	return out, nil
+}
+
+// structFields lists the fields encoding/json would encode.
+func structFields(st *ast.StructType, naming simdjson.FieldNaming) ([]field, error) {
+	var fields []field
+	for _, f := range st.Fields.List {
+		tag := ""
+		if f.Tag != nil {
+			unquoted, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(unquoted).Get("json")
+		}
+		if len(f.Names) == 0 {
+			if tag == "-" {
+				continue
+			}
+			return nil, fmt.Errorf("embedded field %s is not supported", types.ExprString(f.Type))
+		}
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if hasOption(opts, "unknown") {
+			return nil, fmt.Errorf("field %s: unknown member fields are not supported", f.Names[0].Name)
+		}
+		if hasOption(opts, "inline") {
+			return nil, fmt.Errorf("field %s: inline fields are not supported", f.Names[0].Name)
+		}
+		for _, id := range f.Names {
+			if !id.IsExported() {
+				continue
+			}
+			jsonName := name
+			if jsonName == "" {
+				jsonName = naming.Name(id.Name)
+			}
+			fields = append(fields, field{
+				goName:      id.Name,
+				jsonName:    jsonName,
+				omitempty:   hasOption(opts, "omitempty"),
+				required:    hasOption(opts, "required"),
+				typ:         f.Type,
+				bytesFormat: bytesFormat(opts),
+				floatPrec:   floatPrec(opts),
+			})
+		}
+	}
+	return fields, nil
+}
+
+func hasOption(opts, name string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
+
+// bytesFormats maps the names of the format tag option to the runtime's
+// BytesFormat constants.
+var bytesFormats = map[string]string{
+	"base64":       "BytesBase64",
+	"base64url":    "BytesBase64URL",
+	"rawbase64":    "BytesRawBase64",
+	"rawbase64url": "BytesRawBase64URL",
+	"hex":          "BytesHex",
+	"array":        "BytesArray",
+	"raw":          "BytesRaw",
+}
+
+// bytesFormat returns the constant named by a format:name or rawbytes
+// option, "" if there is none. Unknown names are ignored, as the runtime
+// ignores them.
+func bytesFormat(opts string) string {
+	if hasOption(opts, "rawbytes") {
+		return "BytesRaw"
+	}
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if name, ok := strings.CutPrefix(opt, "format:"); ok && bytesFormats[name] != "" {
+			return bytesFormats[name]
+		}
+	}
+	return ""
+}
+
+// floatPrec returns the digits of a prec:n option, "" if there is none.
+// Invalid digits are ignored, as the runtime ignores them.
+func floatPrec(opts string) string {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if prec, ok := strings.CutPrefix(opt, "prec:"); ok {
+			if n, err := strconv.Atoi(prec); err == nil && n >= 0 {
+				return strconv.Itoa(n)
+			}
+		}
+	}
+	return ""
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// Kinds of types the generator has a fast path for.
+const (
+	kindOther = iota
+	kindString
+	kindBool
+	kindInt
+	kindUint
+	kindFloat
+	kindBytes
+	kindInterface
+	kindStruct // a struct that is being generated
+	kindPointer
+	kindSlice
+	kindMap
+)
+
+// basicBits maps predeclared numeric types to their kind and the bitSize
+// argument of the Reader and Writer methods.
+var basicBits = map[string]struct{ kind, bits int }{
+	"string":  {kindString, 0},
+	"bool":    {kindBool, 0},
+	"int":     {kindInt, 0},
+	"int8":    {kindInt, 8},
+	"int16":   {kindInt, 16},
+	"int32":   {kindInt, 32},
+	"rune":    {kindInt, 32},
+	"int64":   {kindInt, 64},
+	"uint":    {kindUint, 0},
+	"uint8":   {kindUint, 8},
+	"byte":    {kindUint, 8},
+	"uint16":  {kindUint, 16},
+	"uint32":  {kindUint, 32},
+	"uint64":  {kindUint, 64},
+	"float32": {kindFloat, 32},
+	"float64": {kindFloat, 64},
+}
+
+// classify returns the kind of t, the bitSize for numeric kinds, and the
+// type expression that describes its structure, looking through named
+// types declared in the same file.
+func (g *generator) classify(t ast.Expr) (kind, bits int, under ast.Expr) {
+	for depth := 0; depth < 16; depth++ {
+		switch x := t.(type) {
+		case *ast.ParenExpr:
+			t = x.X
+			continue
+		case *ast.Ident:
+			if x.Name == "any" {
+				return kindInterface, 0, x
+			}
+			if ts, ok := g.specs[x.Name]; ok {
+				if _, isStruct := ts.Type.(*ast.StructType); isStruct {
+					if g.generated[x.Name] {
+						return kindStruct, 0, x
+					}
+					return kindOther, 0, x
+				}
+				t = ts.Type
+				continue
+			}
+			if b, ok := basicBits[x.Name]; ok {
+				return b.kind, b.bits, x
+			}
+		case *ast.InterfaceType:
+			if len(x.Methods.List) == 0 {
+				return kindInterface, 0, x
+			}
+		case *ast.StarExpr:
+			return kindPointer, 0, x
+		case *ast.ArrayType:
+			if x.Len != nil {
+				// Fixed-size arrays use the reflection path
+				return kindOther, 0, t
+			}
+			if k, bits, _ := g.classify(x.Elt); k == kindUint && bits == 8 {
+				return kindBytes, 0, x
+			}
+			return kindSlice, 0, x
+		case *ast.MapType:
+			if k, _, _ := g.classify(x.Key); k == kindString {
+				return kindMap, 0, x
+			}
+		}
+		return kindOther, 0, t
+	}
+	return kindOther, 0, t
+}
+
+// convert returns expr, of type from, converted to the type named to,
+// omitting the conversion when the types are spelled the same.
+func convert(to string, from ast.Expr, expr string) string {
+	if types.ExprString(from) == to {
+		return expr
+	}
+	return to + "(" + expr + ")"
+}
+
+// jsonKey returns the Go literal for the JSON encoding of name followed by
+// a colon, prefixed by a comma if comma is set.
+func jsonKey(name string, comma bool) string {
+	b, _ := json.Marshal(name)
+	s := string(b) + ":"
+	if comma {
+		s = "," + s
+	}
+	return strconv.Quote(s)
+}
+
+func (g *generator) marshal(name string, fields []field) {
+	g.printf("\n// MarshalJSONTo writes v to w as a JSON object.\n")
+	g.printf("func (v %s) MarshalJSONTo(w *simdjson.Writer) error {\n", name)
+	g.printf("w.RawByte('{')\n")
+
+	// Once a field is always written every later key needs a comma; until
+	// then, whether an omitempty field was written is tracked in comma.
+	wrote, declared := false, false
+	for _, f := range fields {
+		expr := "v." + f.goName
+		present := ""
+		if f.omitempty {
+			present = g.nonEmpty(expr, f.typ)
+		}
+		switch {
+		case wrote:
+			if present != "" {
+				g.printf("if %s {\n", present)
+			}
+			g.printf("w.RawString(%s)\n", jsonKey(f.jsonName, true))
+			g.encodeField(expr, f)
+			if present != "" {
+				g.printf("}\n")
+			}
+		case present != "":
+			if !declared {
+				g.printf("comma := false\n")
+				declared = true
+			}
+			g.printf("if %s {\n", present)
+			g.printf("if comma { w.RawByte(',') }\n")
+			g.printf("comma = true\n")
+			g.printf("w.RawString(%s)\n", jsonKey(f.jsonName, false))
+			g.encodeField(expr, f)
+			g.printf("}\n")
+		default:
+			if declared {
+				g.printf("if comma { w.RawByte(',') }\n")
+			}
+			g.printf("w.RawString(%s)\n", jsonKey(f.jsonName, false))
+			g.encodeField(expr, f)
+			wrote = true
+		}
+	}
+	g.printf("w.RawByte('}')\n")
+	g.printf("return nil\n}\n")
+}
+
+// nonEmpty returns the negation of the test encoding/json's omitempty
+// applies, or "" if values of t are never considered empty.
+func (g *generator) nonEmpty(expr string, t ast.Expr) string {
+	if isNullType(t) {
+		// As the runtime leaves out only absent Nulls
+		return "!" + expr + ".IsZero()"
+	}
+	switch kind, _, _ := g.classify(t); kind {
+	case kindString:
+		return expr + ` != ""`
+	case kindBool:
+		return expr
+	case kindInt, kindUint, kindFloat:
+		return expr + " != 0"
+	case kindBytes, kindSlice, kindMap:
+		return "len(" + expr + ") != 0"
+	case kindPointer, kindInterface:
+		return expr + " != nil"
+	}
+	return ""
+}
+
+// isNullType reports whether t is an instance of simdjson.Null.
+func isNullType(t ast.Expr) bool {
+	x, ok := t.(*ast.IndexExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := x.X.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Null"
+}
+
+// encodeField emits code writing field f, found at expr.
+func (g *generator) encodeField(expr string, f field) {
+	if kind, _, _ := g.classify(f.typ); kind == kindBytes && f.bytesFormat != "" {
+		g.printf("w.BytesAs(%s, simdjson.%s)\n", convert("[]byte", f.typ, expr), f.bytesFormat)
+		return
+	}
+	g.prec = f.floatPrec
+	g.nonNull = f.omitempty
+	g.encode(expr, f.typ, 1)
+	g.prec, g.nonNull = "", false
+}
+
+// encode emits code writing the value expr of type t.
+func (g *generator) encode(expr string, t ast.Expr, depth int) {
+	kind, bits, under := g.classify(t)
+	// An omitempty guard already ruled out nil pointers, slices and maps;
+	// the elements below the field get their own checks
+	nilCheck := !g.nonNull
+	g.nonNull = false
+	switch kind {
+	case kindString:
+		g.printf("w.String(%s)\n", convert("string", t, expr))
+	case kindBool:
+		g.printf("w.Bool(%s)\n", convert("bool", t, expr))
+	case kindInt:
+		g.printf("w.Int(%s)\n", convert("int64", t, expr))
+	case kindUint:
+		g.printf("w.Uint(%s)\n", convert("uint64", t, expr))
+	case kindFloat:
+		if g.prec != "" {
+			g.printf("if err := w.FloatPrec(%s, %d, %s); err != nil { return err }\n", convert("float64", t, expr), bits, g.prec)
+			break
+		}
+		g.printf("if err := w.Float(%s, %d); err != nil { return err }\n", convert("float64", t, expr), bits)
+	case kindBytes:
+		g.printf("w.Bytes(%s)\n", convert("[]byte", t, expr))
+	case kindStruct:
+		g.printf("if err := %s.MarshalJSONTo(w); err != nil { return err }\n", expr)
+	case kindPointer:
+		if nilCheck {
+			g.printf("if %s == nil {\nw.Null()\n} else {\n", expr)
+		}
+		g.encode("(*"+expr+")", under.(*ast.StarExpr).X, depth)
+		if nilCheck {
+			g.printf("}\n")
+		}
+	case kindSlice:
+		i, x := fmt.Sprintf("i%d", depth), fmt.Sprintf("x%d", depth)
+		if nilCheck {
+			g.printf("if %s == nil {\nw.NilSlice()\n} else {\n", expr)
+		}
+		g.printf("w.RawByte('[')\n")
+		g.printf("for %s, %s := range %s {\n", i, x, expr)
+		g.printf("if %s > 0 { w.RawByte(',') }\n", i)
+		g.encode(x, under.(*ast.ArrayType).Elt, depth+1)
+		g.printf("}\nw.RawByte(']')\n")
+		if nilCheck {
+			g.printf("}\n")
+		}
+	case kindMap:
+		n, k, x := fmt.Sprintf("n%d", depth), fmt.Sprintf("k%d", depth), fmt.Sprintf("x%d", depth)
+		if nilCheck {
+			g.printf("if %s == nil {\nw.NilMap()\n} else {\n", expr)
+		}
+		// Members are sorted by key, as the runtime and encoding/json do
+		g.imports["maps"], g.imports["slices"] = true, true
+		g.printf("w.RawByte('{')\n")
+		g.printf("for %s, %s := range slices.Sorted(maps.Keys(%s)) {\n", n, k, expr)
+		g.printf("if %s > 0 { w.RawByte(',') }\n", n)
+		g.printf("w.String(string(%s))\nw.RawByte(':')\n", k)
+		g.printf("%s := %s[%s]\n", x, expr, k)
+		g.encode(x, under.(*ast.MapType).Value, depth+1)
+		g.printf("}\nw.RawByte('}')\n")
+		if nilCheck {
+			g.printf("}\n")
+		}
+	default:
+		g.printf("if err := w.Value(%s); err != nil { return err }\n", expr)
+	}
+}
+
+func (g *generator) unmarshal(name string, fields []field) {
+	g.printf("\n// UnmarshalJSONFrom reads a JSON object from r into v.\n")
+	g.printf("func (v *%s) UnmarshalJSONFrom(r *simdjson.Reader) error {\n", name)
+	g.printf("if r.Null() { return nil }\n")
+	g.printf("if err := r.BeginObject(); err != nil { return err }\n")
+
+	// Required fields found are tracked in found, by their order
+	var required []field
+	for _, f := range fields {
+		if f.required {
+			required = append(required, f)
+		}
+	}
+	if required != nil {
+		g.printf("var found [%d]bool\n", len(required))
+	}
+	g.printf("for r.More() {\n")
+	g.printf("key, err := r.Key()\nif err != nil { return err }\n")
+	if len(fields) > 0 {
+		g.printf("match:\n")
+	}
+	g.printf("switch string(key) {\n")
+	n := 0
+	for _, f := range fields {
+		g.printf("case %s:\n", strconv.Quote(f.jsonName))
+		if f.required {
+			g.printf("found[%d] = true\n", n)
+			n++
+		}
+		g.decodeField("v."+f.goName, f)
+	}
+	// Keys matching no field exactly fall back to a case-insensitive match
+	// in field order, as the runtime and encoding/json do
+	g.printf("default:\n")
+	if len(fields) > 0 {
+		g.imports["bytes"] = true
+		g.printf("for _, name := range [...]string{")
+		for i, f := range fields {
+			if i > 0 {
+				g.printf(", ")
+			}
+			g.printf("%s", strconv.Quote(f.jsonName))
+		}
+		g.printf("} {\n")
+		g.printf("if bytes.EqualFold(key, []byte(name)) {\nkey = []byte(name)\ngoto match\n}\n}\n")
+	}
+	g.printf("if err := r.Skip(); err != nil { return err }\n")
+	g.printf("}\n}\n")
+	if required == nil {
+		g.printf("return r.EndObject()\n}\n")
+		return
+	}
+	g.printf("if err := r.EndObject(); err != nil { return err }\n")
+	g.printf("var missing []string\n")
+	for i, f := range required {
+		g.printf("if !found[%d] { missing = append(missing, %s) }\n", i, strconv.Quote(f.jsonName))
+	}
+	g.printf("if missing != nil {\n")
+	g.printf("return &simdjson.MissingFieldsError{Struct: %s, Fields: missing}\n}\n", strconv.Quote(name))
+	g.printf("return nil\n}\n")
+}
+
+// decodeField emits code reading field f into expr.
+func (g *generator) decodeField(expr string, f field) {
+	if kind, _, _ := g.classify(f.typ); kind == kindBytes && f.bytesFormat != "" {
+		g.printf("if r.Null() {\n%s = nil\n} else {\n", expr)
+		g.printf("x1, err := r.BytesAs(simdjson.%s)\nif err != nil { return err }\n", f.bytesFormat)
+		g.printf("%s = %s\n}\n", expr, convert(types.ExprString(f.typ), &ast.ArrayType{Elt: ast.NewIdent("byte")}, "x1"))
+		return
+	}
+	g.decode(expr, f.typ, 1)
+}
+
+// decode emits code reading the next value into the addressable expr of
+// type t. A JSON null leaves values unchanged and sets pointers, slices
+// and maps to nil, as encoding/json does.
+func (g *generator) decode(expr string, t ast.Expr, depth int) {
+	kind, bits, under := g.classify(t)
+	typ := types.ExprString(t)
+	x := fmt.Sprintf("x%d", depth)
+
+	scalar := func(call, result string) {
+		g.printf("if !r.Null() {\n")
+		g.printf("%s, err := r.%s\nif err != nil { return err }\n", x, call)
+		g.printf("%s = %s\n}\n", expr, convert(typ, ast.NewIdent(result), x))
+	}
+
+	switch kind {
+	case kindString:
+		scalar("String()", "string")
+	case kindBool:
+		scalar("Bool()", "bool")
+	case kindInt:
+		scalar(fmt.Sprintf("Int(%d)", bits), "int64")
+	case kindUint:
+		scalar(fmt.Sprintf("Uint(%d)", bits), "uint64")
+	case kindFloat:
+		scalar(fmt.Sprintf("Float(%d)", bits), "float64")
+	case kindBytes:
+		g.printf("if r.Null() {\n%s = nil\n} else {\n", expr)
+		g.printf("%s, err := r.Bytes()\nif err != nil { return err }\n", x)
+		g.printf("%s = %s\n}\n", expr, convert(typ, &ast.ArrayType{Elt: ast.NewIdent("byte")}, x))
+	case kindStruct:
+		g.printf("if err := %s.UnmarshalJSONFrom(r); err != nil { return err }\n", expr)
+	case kindPointer:
+		elem := under.(*ast.StarExpr).X
+		g.printf("if r.Null() {\n%s = nil\n} else {\n", expr)
+		g.printf("if %s == nil { %s = new(%s) }\n", expr, expr, types.ExprString(elem))
+		g.decode("(*"+expr+")", elem, depth)
+		g.printf("}\n")
+	case kindSlice:
+		elem := under.(*ast.ArrayType).Elt
+		s := fmt.Sprintf("s%d", depth)
+		g.printf("if r.Null() {\n%s = nil\n} else {\n", expr)
+		g.printf("if err := r.BeginArray(); err != nil { return err }\n")
+		g.printf("%s := %s[:0]\n", s, expr)
+		g.printf("for r.More() {\nvar %s %s\n", x, types.ExprString(elem))
+		g.printf("if len(%s) < len(%s) { %s = %s[len(%s)] }\n", s, expr, x, expr, s)
+		g.decode(x, elem, depth+1)
+		g.printf("%s = append(%s, %s)\n}\n", s, s, x)
+		g.printf("if err := r.EndArray(); err != nil { return err }\n")
+		g.printf("if %s == nil { %s = %s{} }\n", s, s, typ)
+		g.printf("%s = %s\n}\n", expr, s)
+	case kindMap:
+		m := under.(*ast.MapType)
+		k, key := fmt.Sprintf("k%d", depth), fmt.Sprintf("key%d", depth)
+		g.printf("if r.Null() {\n%s = nil\n} else {\n", expr)
+		g.printf("if err := r.BeginObject(); err != nil { return err }\n")
+		g.printf("if %s == nil { %s = make(%s) }\n", expr, expr, typ)
+		g.printf("for r.More() {\n")
+		g.printf("%s, err := r.Key()\nif err != nil { return err }\n", k)
+		g.printf("%s := %s(%s)\n", key, types.ExprString(m.Key), k)
+		g.printf("var %s %s\n", x, types.ExprString(m.Value))
+		g.decode(x, m.Value, depth+1)
+		g.printf("%s[%s] = %s\n}\n", expr, key, x)
+		g.printf("if err := r.EndObject(); err != nil { return err }\n}\n")
+	default:
+		g.printf("if err := r.Decode(&%s); err != nil { return err }\n", expr)
+	}
+}
@@ -0,0 +1,84 @@
+// Command simdjson-gen generates reflection-free MarshalJSONTo and
+// UnmarshalJSONFrom methods for Go struct types. Marshal and Unmarshal
+// call the generated methods instead of walking the value with reflect.
+//
+// Usage:
+//
+//	simdjson-gen [-type T1,T2] [-naming style] [-output file] file.go
+//
+// Without -type every struct type declared in file.go is generated. The
+// output defaults to file_simdjson.go next to the input. The usual way to
+// run it is a go:generate directive in file.go:
+//
+//	//go:generate simdjson-gen -type Person file.go
+//
+// Fields of basic types, pointers, slices, string-keyed maps and other
+// generated structs are encoded inline; anything else falls back to the
+// reflection path for that field only. omitempty is honoured for types
+// whose kind is known from the source file.
+//
+// Fields without a name in their json tag use their Go name, or with
+// -naming snake, camel or kebab the name the same simdjson.FieldNaming
+// would give them. The generated methods use these names whatever the
+// FieldNaming option Marshal and Unmarshal are called with.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/biggeezerdevelopment/simdjson-go"
+)
+
+// namings maps the values of -naming to field namings.
+var namings = map[string]simdjson.FieldNaming{
+	"":      simdjson.GoFieldNames,
+	"snake": simdjson.SnakeCase,
+	"camel": simdjson.CamelCase,
+	"kebab": simdjson.KebabCase,
+}
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of type names; default all structs")
+	output := flag.String("output", "", "output file name; default <file>_simdjson.go")
+	namingFlag := flag.String("naming", "", "naming of untagged fields: snake, camel or kebab; default the Go name")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: simdjson-gen [-type T1,T2] [-naming style] [-output file] file.go\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	input := flag.Arg(0)
+	var names []string
+	if *typeNames != "" {
+		names = strings.Split(*typeNames, ",")
+	}
+
+	naming, ok := namings[*namingFlag]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "simdjson-gen: unknown naming %q\n", *namingFlag)
+		os.Exit(2)
+	}
+
+	src, err := generate(input, nil, names, naming)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "simdjson-gen:", err)
+		os.Exit(1)
+	}
+
+	out := *output
+	if out == "" {
+		out = strings.TrimSuffix(input, filepath.Ext(input)) + "_simdjson.go"
+	}
+	if err := os.WriteFile(out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "simdjson-gen:", err)
+		os.Exit(1)
+	}
+}
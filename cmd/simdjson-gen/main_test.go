@@ -0,0 +1,153 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/biggeezerdevelopment/simdjson-go"
+)
+
+// The example package commits the output of go:generate; regenerating it
+// must reproduce the file exactly.
+func TestGenerateGolden(t *testing.T) {
+	got, err := generate("../../examples/order.go", nil, []string{"Order", "LineItem", "Customer"}, simdjson.GoFieldNames)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	want, err := os.ReadFile("../../examples/order_simdjson.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Error("examples/order_simdjson.go is stale; run go generate ./examples")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src := `package p
+
+type Flag bool
+
+type Inner struct{ N int }
+
+type T struct {
+	Opt    string            ` + "`json:\"opt,omitempty\"`" + `
+	Req    Flag              ` + "`json:\",omitempty\"`" + `
+	Name   string            ` + "`json:\",required\"`" + `
+	Arr    [2]int
+	Keys   map[Flag]int
+	Inner  Inner
+	Ptr    *Inner
+	Any    any
+	Key    []byte ` + "`json:\"key,format:base64url\"`" + `
+	Text   []byte ` + "`json:\"text,rawbytes\"`" + `
+	Opt    simdjson.Null[int] ` + "`json:\"opt,omitempty\"`" + `
+	Ratio  []float32 ` + "`json:\"ratio,prec:2\"`" + `
+	Tags   []string ` + "`json:\",omitempty\"`" + `
+	Meta   map[string][]int ` + "`json:\",omitempty\"`" + `
+	Next   *Inner ` + "`json:\",omitempty\"`" + `
+	Skip   int ` + "`json:\"-\"`" + `
+	hidden int
+}
+`
+	out, err := generate("p.go", src, []string{"T"}, simdjson.GoFieldNames)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "out.go", out, 0); err != nil {
+		t.Fatalf("Generated code does not parse: %v\n%s", err, out)
+	}
+
+	code := string(out)
+	checks := []string{
+		"func (v T) MarshalJSONTo(w *simdjson.Writer) error",
+		"func (v *T) UnmarshalJSONFrom(r *simdjson.Reader) error",
+		"comma := false",
+		`w.RawString("\"Req\":")`,
+		"if v.Req {",
+		"w.Value(v.Arr)",
+		"r.Decode(&v.Arr)",
+		"w.Value(v.Inner)", // Inner is not generated, so it uses reflection
+		"r.Decode(&v.Any)",
+		"w.BytesAs(v.Key, simdjson.BytesBase64URL)",
+		"w.BytesAs(v.Text, simdjson.BytesRaw)",
+		"if !v.Opt.IsZero() {",
+		"r.Decode(&v.Opt)",
+		"w.FloatPrec(float64(x1), 32, 2)",
+		"found[0] = true",
+		`return &simdjson.MissingFieldsError{Struct: "T", Fields: missing}`,
+		"r.BytesAs(simdjson.BytesBase64URL)",
+		"if len(v.Meta) != 0 {",
+		"if x1 == nil {", // elements of an omitempty map keep their nil check
+		"if v.Ptr == nil {",
+		"if v.Next != nil {",
+		"range slices.Sorted(maps.Keys(v.Meta))",
+		"if bytes.EqualFold(key, []byte(name)) {",
+	}
+	for _, want := range checks {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated code to contain %q\n%s", want, code)
+		}
+	}
+	for _, unwanted := range []string{`"Skip"`, `"hidden"`, "func (v Inner)"} {
+		if strings.Contains(code, unwanted) {
+			t.Errorf("Generated code should not contain %q", unwanted)
+		}
+	}
+
+	// The omitempty guard already rules out nil when encoding
+	marshal := code[:strings.Index(code, "UnmarshalJSONFrom")]
+	for _, unwanted := range []string{"if v.Tags == nil", "if v.Meta == nil", "if v.Next == nil"} {
+		if strings.Contains(marshal, unwanted) {
+			t.Errorf("MarshalJSONTo should not contain %q\n%s", unwanted, marshal)
+		}
+	}
+}
+
+func TestGenerateNaming(t *testing.T) {
+	src := `package p
+
+type T struct {
+	UserID    int
+	HTTPPort  int ` + "`json:\",omitempty\"`" + `
+	Tagged    int ` + "`json:\"Keep\"`" + `
+}
+`
+	out, err := generate("p.go", src, nil, simdjson.SnakeCase)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	code := string(out)
+	for _, want := range []string{`"\"user_id\":"`, `case "http_port":`, `case "Keep":`} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Expected generated code to contain %q\n%s", want, code)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		types []string
+	}{
+		{"missing_type", "package p\ntype A struct{}", []string{"B"}},
+		{"not_struct", "package p\ntype A int", []string{"A"}},
+		{"no_structs", "package p\ntype A int", nil},
+		{"embedded", "package p\ntype B struct{}\ntype A struct{ B }", []string{"A"}},
+		{"unknown", "package p\ntype A struct{ Rest map[string]any `json:\",unknown\"` }", []string{"A"}},
+		{"inline", "package p\ntype B struct{ X int }\ntype A struct{ B B `json:\",inline\"` }", []string{"A"}},
+		{"syntax", "package p\ntype A struct{", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := generate("p.go", tt.src, tt.types, simdjson.GoFieldNames); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
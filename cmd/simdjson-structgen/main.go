@@ -0,0 +1,92 @@
+// Command simdjson-structgen writes Go struct definitions for example JSON
+// documents.
+//
+// Usage:
+//
+//	simdjson-structgen [-type name] [-package name] [-output file] [file ...]
+//
+// The files, or standard input if none are given, hold sample documents,
+// any number per file as in NDJSON. Their shapes are merged as the schema
+// package infers them, so members missing from some samples get
+// omitempty, members that are sometimes null become pointers, and numbers
+// are int64 unless some sample has a fraction or exponent. Nested objects
+// become types named after the path leading to them.
+//
+// The output is a gofmt-formatted file declaring -type, Root by default,
+// in -package, main by default, written to standard output unless -output
+// names a file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+
+	"github.com/biggeezerdevelopment/simdjson-go/schema"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("simdjson-structgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	typeName := fs.String("type", "Root", "name of the type for the top-level value")
+	pkg := fs.String("package", "main", "package of the generated file")
+	output := fs.String("output", "", "output file name; default standard output")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: simdjson-structgen [-type name] [-package name] [-output file] [file ...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	for _, name := range []string{*typeName, *pkg} {
+		if !token.IsIdentifier(name) {
+			fmt.Fprintf(stderr, "simdjson-structgen: %q is not a Go identifier\n", name)
+			return 2
+		}
+	}
+
+	s := &schema.Schema{}
+	if fs.NArg() == 0 {
+		data, err := io.ReadAll(stdin)
+		if err == nil {
+			err = s.Add(data)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "simdjson-structgen:", err)
+			return 1
+		}
+	}
+	for _, file := range fs.Args() {
+		data, err := os.ReadFile(file)
+		if err == nil {
+			err = s.Add(data)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "simdjson-structgen: %s: %v\n", file, err)
+			return 1
+		}
+	}
+
+	src, err := s.GoFile(*pkg, *typeName)
+	if err != nil {
+		fmt.Fprintln(stderr, "simdjson-structgen:", err)
+		return 1
+	}
+	if *output == "" {
+		_, err = stdout.Write(src)
+	} else {
+		err = os.WriteFile(*output, src, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "simdjson-structgen:", err)
+		return 1
+	}
+	return 0
+}
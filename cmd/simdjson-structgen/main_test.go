@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runCmd(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func TestStdin(t *testing.T) {
+	status, out, stderr := runCmd(t, `{"id": 1, "name": "a"}
+{"id": 2, "name": null, "address": {"zip_code": "123"}}`, "-type", "User", "-package", "models")
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr)
+	}
+	expected := "package models\n\n" +
+		"type User struct {\n" +
+		"\tID      int64        `json:\"id\"`\n" +
+		"\tName    *string      `json:\"name\"`\n" +
+		"\tAddress *UserAddress `json:\"address,omitempty\"`\n" +
+		"}\n\n" +
+		"type UserAddress struct {\n" +
+		"\tZipCode string `json:\"zip_code\"`\n" +
+		"}\n"
+	if out != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json")
+	b := filepath.Join(dir, "b.json")
+	out := filepath.Join(dir, "out.go")
+	if err := os.WriteFile(a, []byte(`{"n": 1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte(`{"n": 1.5, "s": "x"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	status, stdout, stderr := runCmd(t, "", "-output", out, a, b)
+	if status != 0 || stdout != "" {
+		t.Fatalf("Expected status 0 and no output, got %d: %s%s", status, stdout, stderr)
+	}
+	src, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "out.go", src, 0); err != nil {
+		t.Fatalf("Generated code does not parse: %v\n%s", err, src)
+	}
+	for _, want := range []string{"package main", "type Root struct", "N float64 `json:\"n\"`", "S string  `json:\"s,omitempty\"`"} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, src)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		status int
+	}{
+		{"invalid json", nil, `{"a":`, 1},
+		{"missing file", []string{"does-not-exist.json"}, "", 1},
+		{"bad type name", []string{"-type", "my-type"}, `{}`, 2},
+		{"bad package", []string{"-package", "1pkg"}, `{}`, 2},
+		{"bad flag", []string{"-nope"}, `{}`, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, stderr := runCmd(t, tt.stdin, tt.args...)
+			if status != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, status)
+			}
+			if stderr == "" {
+				t.Error("Expected a message on stderr")
+			}
+		})
+	}
+}
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+	"github.com/biggeezerdevelopment/simdjson-go/scanner"
+)
+
+// newFlagSet returns a flag set for the named command that reports errors
+// to stderr instead of exiting.
+func newFlagSet(name, usage string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: simdjson", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func runValidate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+	status := 0
+	for _, name := range args {
+		data, name, err := readInput([]string{name}, stdin)
+		if err == nil {
+			err = simdjson.ValidateWithError(data)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", name, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+func runPretty(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := newFlagSet("pretty", "pretty [-indent s] [file]", stderr)
+	indent := fs.String("indent", "  ", "indentation per level")
+	if fs.Parse(args) != nil || fs.NArg() > 1 {
+		return 2
+	}
+	if *indent == "" {
+		fmt.Fprintln(stderr, "simdjson: -indent must not be empty; use minify")
+		return 2
+	}
+	return reformat(fs.Args(), *indent, stdin, stdout, stderr)
+}
+
+func runMinify(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 1 {
+		fmt.Fprintln(stderr, "usage: simdjson minify [file]")
+		return 2
+	}
+	return reformat(args, "", stdin, stdout, stderr)
+}
+
+func reformat(args []string, indent string, stdin io.Reader, stdout, stderr io.Writer) int {
+	in, err := openInput(args, stdin)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	defer in.Close()
+	if err := simdjson.IndentStream(in, stdout, indent); err != nil {
+		var syntaxErr *simdjson.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			name := "<stdin>"
+			if len(args) > 0 && args[0] != "-" {
+				name = args[0]
+			}
+			err = fmt.Errorf("%s: %w", name, err)
+		}
+		return fail(stderr, err)
+	}
+	return 0
+}
+
+func runGet(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Fprintln(stderr, "usage: simdjson get <path> [file]")
+		return 2
+	}
+	steps, err := parsePath(args[0])
+	if err != nil {
+		return fail(stderr, err)
+	}
+	data, name, err := readInput(args[1:], stdin)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	if err := simdjson.ValidateWithError(data); err != nil {
+		return fail(stderr, fmt.Errorf("%s: %w", name, err))
+	}
+
+	s := scanner.New()
+	defer s.Release()
+	d, err := index(s, data)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	var out []byte
+	matched := false
+	d.walk(0, steps, func(pos int) {
+		out = d.format(out, pos, "")
+		out = append(out, '\n')
+		matched = true
+	})
+	if _, err := stdout.Write(out); err != nil {
+		return fail(stderr, err)
+	}
+	if !matched {
+		return 1
+	}
+	return 0
+}
+
+// filter selects NDJSON records by the values a path selects from them.
+type filter struct {
+	steps   []step
+	op      string      // empty to test for a value other than null or false
+	operand interface{} // decoded like values selected from records
+}
+
+var operators = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+func runFilter(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	const usage = "ndjson-filter [-v] <path> [<op> <value>] [file]"
+	fs := newFlagSet("ndjson-filter", usage, stderr)
+	invert := fs.Bool("v", false, "print the records that do not match")
+	if fs.Parse(args) != nil {
+		return 2
+	}
+	args = fs.Args()
+	if len(args) < 1 {
+		fs.Usage()
+		return 2
+	}
+
+	var f filter
+	var err error
+	if f.steps, err = parsePath(args[0]); err != nil {
+		return fail(stderr, err)
+	}
+	args = args[1:]
+	if len(args) >= 2 && operators[args[0]] {
+		f.op = args[0]
+		if simdjson.Unmarshal([]byte(args[1]), &f.operand) != nil {
+			// Bare words are strings, sparing the shell quoting
+			f.operand = args[1]
+		}
+		args = args[2:]
+	}
+	if len(args) > 1 {
+		fs.Usage()
+		return 2
+	}
+
+	in, err := openInput(args, stdin)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	defer in.Close()
+	return f.run(in, stdout, stderr, *invert)
+}
+
+// run copies the matching records of r to w. Invalid records are reported
+// with their line number and skipped.
+func (f *filter) run(r io.Reader, w io.Writer, stderr io.Writer, invert bool) int {
+	br := bufio.NewReaderSize(r, 64*1024)
+	bw := bufio.NewWriterSize(w, 64*1024)
+	s := scanner.New()
+	defer s.Release()
+
+	status := 0
+	var line []byte
+	for n := 1; ; n++ {
+		var err error
+		line, err = readLine(br, line[:0])
+		if err != nil && err != io.EOF {
+			return fail(stderr, err)
+		}
+		if record, _ := scanner.NextRecord(line); record != nil {
+			ok, rerr := f.match(s, record)
+			if rerr != nil {
+				fmt.Fprintf(stderr, "line %d: %v\n", n, rerr)
+				status = 1
+			} else if ok != invert {
+				bw.Write(record)
+				bw.WriteByte('\n')
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fail(stderr, err)
+	}
+	return status
+}
+
+// readLine appends the next line of r to buf, without its newline.
+func readLine(r *bufio.Reader, buf []byte) ([]byte, error) {
+	for {
+		chunk, err := r.ReadSlice('\n')
+		buf = append(buf, chunk...)
+		if err != bufio.ErrBufferFull {
+			return bytes.TrimSuffix(buf, []byte("\n")), err
+		}
+	}
+}
+
+// match reports whether any value the path selects from record passes the
+// filter.
+func (f *filter) match(s *scanner.Scanner, record []byte) (bool, error) {
+	if err := simdjson.ValidateWithError(record); err != nil {
+		return false, err
+	}
+	d, err := index(s, record)
+	if err != nil {
+		return false, err
+	}
+
+	matched := false
+	d.walk(0, f.steps, func(pos int) {
+		if matched {
+			return
+		}
+		if f.op == "" {
+			raw := d.raw(pos)
+			matched = string(raw) != "null" && string(raw) != "false"
+			return
+		}
+		var v interface{}
+		if simdjson.Unmarshal(d.raw(pos), &v) == nil {
+			matched = compare(v, f.op, f.operand)
+		}
+	})
+	return matched, nil
+}
+
+// compare applies op to two decoded JSON values. Numbers compare by value
+// whether they decoded as int64 or float64; only numbers and strings are
+// ordered.
+func compare(a interface{}, op string, b interface{}) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		if !ok {
+			return op == "!="
+		}
+		switch op {
+		case "==":
+			return x == y
+		case "!=":
+			return x != y
+		case "<":
+			return x < y
+		case "<=":
+			return x <= y
+		case ">":
+			return x > y
+		}
+		return x >= y
+	}
+	if x, ok := a.(string); ok {
+		y, ok := b.(string)
+		if !ok {
+			return op == "!="
+		}
+		switch op {
+		case "==":
+			return x == y
+		case "!=":
+			return x != y
+		case "<":
+			return x < y
+		case "<=":
+			return x <= y
+		case ">":
+			return x > y
+		}
+		return x >= y
+	}
+
+	switch op {
+	case "==":
+		return equal(a, b)
+	case "!=":
+		return !equal(a, b)
+	}
+	return false
+}
+
+func number(x interface{}) (float64, bool) {
+	switch x := x.(type) {
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+	return 0, false
+}
+
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			w, ok := b[k]
+			if !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	return a == b
+}
+
+func runBench(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := newFlagSet("bench", "bench [-time d] [file]", stderr)
+	benchtime := fs.Duration("time", time.Second, "run time per benchmark")
+	if fs.Parse(args) != nil || fs.NArg() > 1 {
+		return 2
+	}
+
+	var data []byte
+	name := "generated"
+	if fs.NArg() == 1 {
+		var err error
+		if data, name, err = readInput(fs.Args(), stdin); err != nil {
+			return fail(stderr, err)
+		}
+		if err := simdjson.ValidateWithError(data); err != nil {
+			return fail(stderr, fmt.Errorf("%s: %w", name, err))
+		}
+	} else {
+		data = generate(1 << 20)
+	}
+	fmt.Fprintf(stdout, "%s: %d bytes, %s kernel\n", name, len(data), simdjson.ActiveKernel())
+
+	s := scanner.New()
+	defer s.Release()
+	p := simdjson.NewParser(nil)
+	benchmarks := []struct {
+		name string
+		fn   func() error
+	}{
+		{"validate", func() error {
+			if !simdjson.Valid(data) {
+				return errors.New("invalid JSON")
+			}
+			return nil
+		}},
+		{"index", func() error {
+			_, err := s.StructuralIndices(data)
+			return err
+		}},
+		{"unmarshal", func() error {
+			var v interface{}
+			return simdjson.Unmarshal(data, &v)
+		}},
+		{"parse", func() error {
+			r, err := p.Parse(data)
+			if err == nil {
+				r.Release()
+			}
+			return err
+		}},
+	}
+
+	for _, b := range benchmarks {
+		n, elapsed := 0, time.Duration(0)
+		start := time.Now()
+		for elapsed < *benchtime {
+			if err := b.fn(); err != nil {
+				return fail(stderr, fmt.Errorf("%s: %w", b.name, err))
+			}
+			n++
+			elapsed = time.Since(start)
+		}
+		perOp := elapsed / time.Duration(n)
+		mbps := float64(len(data)) * float64(n) / elapsed.Seconds() / 1e6
+		fmt.Fprintf(stdout, "%-10s %8d ops %12s/op %10.2f MB/s\n", b.name, n, perOp, mbps)
+	}
+	return 0
+}
+
+// generate returns an array of records of about size bytes, mixing the
+// value types typical API payloads contain.
+func generate(size int) []byte {
+	buf := []byte{'['}
+	for i := 0; len(buf) < size; i++ {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, `{"id":`...)
+		buf = strconv.AppendInt(buf, int64(i), 10)
+		buf = append(buf, `,"name":"user `...)
+		buf = strconv.AppendInt(buf, int64(i), 10)
+		buf = append(buf, `","score":`...)
+		buf = strconv.AppendFloat(buf, math.Sqrt(float64(i)), 'g', -1, 64)
+		buf = append(buf, `,"active":`...)
+		buf = strconv.AppendBool(buf, i%3 == 0)
+		buf = append(buf, `,"tags":["a","b\n"],"address":{"city":"Zürich","zip":null}}`...)
+	}
+	return append(buf, ']')
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"github.com/biggeezerdevelopment/simdjson-go/scanner"
+)
+
+// document is a validated JSON document and its structural indices, which
+// the commands walk instead of decoding the document. String quotes appear
+// in the indices in pairs, and scalars by their first byte.
+type document struct {
+	s    *scanner.Scanner
+	data []byte
+	idx  []uint32
+}
+
+// index scans data, which must be valid JSON, with s. The indices are owned
+// by s and only valid until its next use.
+func index(s *scanner.Scanner, data []byte) (*document, error) {
+	idx, err := s.StructuralIndices(data)
+	if err != nil {
+		return nil, err
+	}
+	return &document{s: s, data: data, idx: idx}, nil
+}
+
+// at returns the byte at index position pos.
+func (d *document) at(pos int) byte {
+	return d.data[d.idx[pos]]
+}
+
+// skip returns the index position after the value starting at pos.
+func (d *document) skip(pos int) int {
+	next, err := d.s.SkipValue(pos)
+	if err != nil {
+		// The document was validated, so the brackets match
+		panic(err)
+	}
+	return next
+}
+
+// end returns the byte offset just past the token at pos, which is the
+// last token of a value: a closing bracket, a closing quote or a scalar.
+func (d *document) end(pos int) int {
+	switch d.at(pos) {
+	case '}', ']', '"':
+		return int(d.idx[pos]) + 1
+	}
+	end := len(d.data)
+	if pos+1 < len(d.idx) {
+		end = int(d.idx[pos+1])
+	}
+	for end > int(d.idx[pos]) && isSpace(d.data[end-1]) {
+		end--
+	}
+	return end
+}
+
+// raw returns the bytes of the value starting at pos as written.
+func (d *document) raw(pos int) []byte {
+	return d.data[d.idx[pos]:d.end(d.skip(pos)-1)]
+}
+
+// format appends the value starting at pos to dst, indented by indent per
+// level, or minified if indent is empty. Strings and numbers are copied
+// verbatim.
+func (d *document) format(dst []byte, pos int, indent string) []byte {
+	depth := 0
+	newline := func() {
+		if indent == "" {
+			return
+		}
+		dst = append(dst, '\n')
+		for i := 0; i < depth; i++ {
+			dst = append(dst, indent...)
+		}
+	}
+
+	for end := d.skip(pos); pos < end; pos++ {
+		switch c := d.at(pos); c {
+		case '{', '[':
+			dst = append(dst, c)
+			if c2 := d.at(pos + 1); c2 == '}' || c2 == ']' {
+				dst = append(dst, c2)
+				pos++
+				continue
+			}
+			depth++
+			newline()
+		case '}', ']':
+			depth--
+			newline()
+			dst = append(dst, c)
+		case ',':
+			dst = append(dst, ',')
+			newline()
+		case ':':
+			dst = append(dst, ':')
+			if indent != "" {
+				dst = append(dst, ' ')
+			}
+		case '"':
+			dst = append(dst, d.data[d.idx[pos]:d.idx[pos+1]+1]...)
+			pos++
+		default:
+			dst = append(dst, d.data[d.idx[pos]:d.end(pos)]...)
+		}
+	}
+	return dst
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,113 @@
+// Command simdjson validates, formats and queries JSON documents with the
+// simdjson parser.
+//
+// Usage:
+//
+//	simdjson validate [file ...]
+//	simdjson pretty [-indent s] [file]
+//	simdjson minify [file]
+//	simdjson get <path> [file]
+//	simdjson ndjson-filter [-v] <path> [<op> <value>] [file]
+//	simdjson bench [-time d] [file]
+//
+// Files default to standard input. validate reports the location of the
+// first syntax error of each invalid document and exits with status 1.
+// pretty and minify reformat a document without decoding it, keeping key
+// order, number spelling and string escapes exactly as written. They
+// stream their input, so files of any size take constant memory, and
+// reformat each value of NDJSON on its own line.
+//
+// get prints the values a path selects, one per line and minified, and
+// exits with status 1 if there are none. Paths are a subset of JSONPath:
+//
+//	$.store.book[0].title
+//	$.store.book[*].author
+//	$['key with spaces'].*
+//
+// ndjson-filter copies the records of newline-delimited JSON for which
+// the path selects a value that compares to the given JSON value with op,
+// one of == != < <= > >=. Without op, records are kept when the path
+// selects anything other than null or false. -v inverts the selection.
+// Values that are not valid JSON compare as strings, so
+//
+//	simdjson ndjson-filter $.level == error logs.ndjson
+//
+// keeps the records whose level is "error".
+//
+// bench measures the throughput of validation, structural indexing and
+// parsing on a file, or on a generated document if none is given.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+}
+
+var commands = []command{
+	{"validate", "validate [file ...]", runValidate},
+	{"pretty", "pretty [-indent s] [file]", runPretty},
+	{"minify", "minify [file]", runMinify},
+	{"get", "get <path> [file]", runGet},
+	{"ndjson-filter", "ndjson-filter [-v] <path> [<op> <value>] [file]", runFilter},
+	{"bench", "bench [-time d] [file]", runBench},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdin, stdout, stderr)
+		}
+	}
+	if args[0] != "help" && args[0] != "-h" && args[0] != "-help" {
+		fmt.Fprintf(stderr, "simdjson: unknown command %q\n", args[0])
+	}
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage:")
+	for _, c := range commands {
+		fmt.Fprintln(w, "  simdjson", c.usage)
+	}
+}
+
+// readInput reads the file named by args, which holds at most one name,
+// or stdin if there is none or it is "-".
+func readInput(args []string, stdin io.Reader) ([]byte, string, error) {
+	if len(args) == 0 || args[0] == "-" {
+		data, err := io.ReadAll(stdin)
+		return data, "<stdin>", err
+	}
+	data, err := os.ReadFile(args[0])
+	return data, args[0], err
+}
+
+// openInput is readInput for commands that stream their input.
+func openInput(args []string, stdin io.Reader) (io.ReadCloser, error) {
+	if len(args) == 0 || args[0] == "-" {
+		return io.NopCloser(stdin), nil
+	}
+	return os.Open(args[0])
+}
+
+func fail(stderr io.Writer, err error) int {
+	fmt.Fprintln(stderr, "simdjson:", err)
+	return 1
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const doc = ` {"store": {"book": [
+	{"title": "A", "price": 8.95, "tags": []},
+	{"title": "Bé", "price": 12, "author": {"name": "x"}}
+], "key with spaces": {"k": "v"}, "esc\"aped": 1.50e2}} `
+
+func runCmd(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		status   int
+		expected string
+	}{
+		{"validate", []string{"validate"}, doc, 0, ""},
+		{"validate_invalid", []string{"validate"}, `{"a":}`, 1, ""},
+		{"minify", []string{"minify"}, doc,
+			0, `{"store":{"book":[{"title":"A","price":8.95,"tags":[]},{"title":"Bé","price":12,"author":{"name":"x"}}],"key with spaces":{"k":"v"},"esc\"aped":1.50e2}}` + "\n"},
+		{"pretty", []string{"pretty", "-indent", "\t"}, `{"a":[1,{"b":null},[]],"c":{}}`,
+			0, "{\n\t\"a\": [\n\t\t1,\n\t\t{\n\t\t\t\"b\": null\n\t\t},\n\t\t[]\n\t],\n\t\"c\": {}\n}\n"},
+		{"pretty_scalar", []string{"pretty"}, " -1e5 ", 0, "-1e5\n"},
+		{"pretty_invalid", []string{"pretty"}, `[1,]`, 1, ""},
+		{"minify_ndjson", []string{"minify"}, "{\"a\": 1}\n[ 2 ]\n", 0, "{\"a\":1}\n[2]\n"},
+		{"get_member", []string{"get", "$.store.book[1].author"}, doc, 0, `{"name":"x"}` + "\n"},
+		{"get_wildcard", []string{"get", "$.store.book[*].title"}, doc, 0, "\"A\"\n\"Bé\"\n"},
+		{"get_bracket", []string{"get", "$.store['key with spaces'].*"}, doc, 0, "\"v\"\n"},
+		{"get_escaped_key", []string{"get", `.store.esc"aped`}, doc, 0, "1.50e2\n"},
+		{"get_root", []string{"get", "$"}, `[1, 2]`, 0, "[1,2]\n"},
+		{"get_no_match", []string{"get", "$.store.book[2]"}, doc, 1, ""},
+		{"get_type_mismatch", []string{"get", "$.store[0]"}, doc, 1, ""},
+		{"get_bad_path", []string{"get", "$.store[x]"}, doc, 1, ""},
+		{"filter_exists", []string{"ndjson-filter", "$.ok"},
+			"{\"ok\":true}\n{\"ok\":false}\n{\"ok\":null}\n{}\n{\"ok\":0}\n", 0, "{\"ok\":true}\n{\"ok\":0}\n"},
+		{"filter_number", []string{"ndjson-filter", "$.n", ">=", "2"},
+			"{\"n\":1}\r\n\n{\"n\":2.0}\n{\"n\":\"3\"}\n{\"n\":3}", 0, "{\"n\":2.0}\n{\"n\":3}\n"},
+		{"filter_string", []string{"ndjson-filter", "$.level", "==", "error"},
+			"{\"level\":\"error\"}\n{\"level\":\"info\"}\n", 0, "{\"level\":\"error\"}\n"},
+		{"filter_json_operand", []string{"ndjson-filter", "$.tags", "==", `["a",1]`},
+			"{\"tags\":[\"a\",1.0]}\n{\"tags\":[\"a\"]}\n", 0, "{\"tags\":[\"a\",1.0]}\n"},
+		{"filter_wildcard", []string{"ndjson-filter", "$.xs[*]", "<", "0"},
+			"{\"xs\":[1,-1]}\n{\"xs\":[1,2]}\n", 0, "{\"xs\":[1,-1]}\n"},
+		{"filter_invert", []string{"ndjson-filter", "-v", "$.level", "!=", "error"},
+			"{\"level\":\"error\"}\n{\"level\":\"info\"}\n", 0, "{\"level\":\"error\"}\n"},
+		{"filter_invalid_record", []string{"ndjson-filter", "$.a"}, "{\"a\":1}\n{\"a\":\n{\"a\":2}\n",
+			1, "{\"a\":1}\n{\"a\":2}\n"},
+		{"unknown_command", []string{"frobnicate"}, "", 2, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, stdout, stderr := runCmd(t, tt.stdin, tt.args...)
+			if status != tt.status {
+				t.Errorf("Expected status %d, got %d (stderr %q)", tt.status, status, stderr)
+			}
+			if stdout != tt.expected {
+				t.Errorf("Expected output %q, got %q", tt.expected, stdout)
+			}
+		})
+	}
+}
+
+func TestValidateFiles(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(good, []byte(doc), 0o644)
+	os.WriteFile(bad, []byte("{\n  \"a\": tru\n}"), 0o644)
+
+	status, _, stderr := runCmd(t, "", "validate", good, bad, filepath.Join(dir, "missing.json"))
+	if status != 1 {
+		t.Errorf("Expected status 1, got %d", status)
+	}
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], bad+": ") || !strings.Contains(lines[0], "line 2") {
+		t.Errorf("Expected errors for bad.json and missing.json, got %q", stderr)
+	}
+
+	if status, _, stderr := runCmd(t, "", "validate", good); status != 0 {
+		t.Errorf("Expected status 0, got %d (%s)", status, stderr)
+	}
+	if status, _, _ := runCmd(t, "", "validate"); status != 1 {
+		t.Errorf("Expected empty input to be invalid, got status %d", status)
+	}
+}
+
+func TestBench(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "doc.json")
+	os.WriteFile(file, []byte(doc), 0o644)
+
+	status, stdout, stderr := runCmd(t, "", "bench", "-time", "1ms", file)
+	if status != 0 {
+		t.Fatalf("Expected status 0, got %d (%s)", status, stderr)
+	}
+	for _, name := range []string{"validate", "index", "unmarshal", "parse"} {
+		if !strings.Contains(stdout, name+" ") {
+			t.Errorf("Expected a %s result in %q", name, stdout)
+		}
+	}
+
+	if data := generate(4096); !bytes.HasPrefix(data, []byte(`[{"id":0,`)) || len(data) < 4096 {
+		t.Errorf("Unexpected generated document %.40s... (%d bytes)", data, len(data))
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []step
+	}{
+		{"$", nil},
+		{"", nil},
+		{"$.a.b", []step{{key: "a", index: -1}, {key: "b", index: -1}}},
+		{".a[0][*].*", []step{{key: "a", index: -1}, {index: 0}, {index: -1, wildcard: true}, {index: -1, wildcard: true}}},
+		{`$["x.y"]['z']`, []step{{key: "x.y", index: -1}, {key: "z", index: -1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			steps, err := parsePath(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(steps, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, steps)
+			}
+		})
+	}
+
+	for _, path := range []string{"a", "$.", "$..a", "$[", "$[-1]", "$['a'", "$['a'x"} {
+		if _, err := parsePath(path); err == nil {
+			t.Errorf("Expected error for %q", path)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+)
+
+// step is one selector of a path: an object member, an array element, or
+// every member or element.
+type step struct {
+	key      string
+	index    int // -1 for a key step
+	wildcard bool
+}
+
+// parsePath parses the JSONPath subset documented in the package comment:
+// an optional $, then .name, .*, [n], [*], ['name'] or ["name"] steps.
+func parsePath(s string) ([]step, error) {
+	p := strings.TrimPrefix(s, "$")
+	var steps []step
+	for p != "" {
+		switch {
+		case strings.HasPrefix(p, ".*"):
+			steps = append(steps, step{index: -1, wildcard: true})
+			p = p[2:]
+		case p[0] == '.':
+			n := strings.IndexAny(p[1:], ".[")
+			if n < 0 {
+				n = len(p) - 1
+			}
+			if n == 0 {
+				return nil, fmt.Errorf("invalid path %q: empty member name", s)
+			}
+			steps = append(steps, step{key: p[1 : n+1], index: -1})
+			p = p[n+1:]
+		case strings.HasPrefix(p, "[*]"):
+			steps = append(steps, step{index: -1, wildcard: true})
+			p = p[3:]
+		case strings.HasPrefix(p, "['") || strings.HasPrefix(p, `["`):
+			quote := p[1]
+			n := strings.IndexByte(p[2:], quote)
+			if n < 0 || !strings.HasPrefix(p[n+3:], "]") {
+				return nil, fmt.Errorf("invalid path %q: unterminated member name", s)
+			}
+			steps = append(steps, step{key: p[2 : n+2], index: -1})
+			p = p[n+4:]
+		case p[0] == '[':
+			n := strings.IndexByte(p, ']')
+			if n < 0 {
+				return nil, fmt.Errorf("invalid path %q: missing ]", s)
+			}
+			i, err := strconv.Atoi(p[1:n])
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid path %q: bad index %q", s, p[1:n])
+			}
+			steps = append(steps, step{index: i})
+			p = p[n+1:]
+		default:
+			return nil, fmt.Errorf("invalid path %q: unexpected %q", s, p[0])
+		}
+	}
+	return steps, nil
+}
+
+// walk calls fn with the index position of every value the steps select
+// from the value starting at pos, in document order.
+func (d *document) walk(pos int, steps []step, fn func(pos int)) {
+	if len(steps) == 0 {
+		fn(pos)
+		return
+	}
+	st, rest := steps[0], steps[1:]
+
+	switch d.at(pos) {
+	case '{':
+		if st.index >= 0 {
+			return
+		}
+		// Each member is a key's quote pair, a colon and the value
+		for pos++; d.at(pos) != '}'; {
+			if st.wildcard || d.keyEquals(pos, st.key) {
+				d.walk(pos+3, rest, fn)
+			}
+			pos = d.skip(pos + 3)
+			if d.at(pos) == ',' {
+				pos++
+			}
+		}
+	case '[':
+		if !st.wildcard && st.index < 0 {
+			return
+		}
+		for i := 0; ; i++ {
+			pos++
+			if d.at(pos) == ']' {
+				return
+			}
+			if st.wildcard || st.index == i {
+				d.walk(pos, rest, fn)
+				if !st.wildcard {
+					return
+				}
+			}
+			pos = d.skip(pos)
+			if d.at(pos) != ',' {
+				return
+			}
+		}
+	}
+}
+
+// keyEquals reports whether the key whose opening quote is at pos is key.
+func (d *document) keyEquals(pos int, key string) bool {
+	raw := d.data[d.idx[pos]+1 : d.idx[pos+1]]
+	if bytes.IndexByte(raw, '\\') < 0 {
+		return string(raw) == key
+	}
+	var s string
+	if err := simdjson.Unmarshal(d.data[d.idx[pos]:d.idx[pos+1]+1], &s); err != nil {
+		return false
+	}
+	return s == key
+}
@@ -0,0 +1,80 @@
+package simdjson
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// codec holds the functions installed by RegisterCodec for a type; either
+// may be nil.
+type codec struct {
+	marshal   func(w *Writer, v reflect.Value) error
+	unmarshal decodeFunc
+}
+
+var (
+	codecsMu sync.Mutex
+	// Replaced, never modified, so lookups need no lock
+	codecs atomic.Pointer[map[reflect.Type]*codec]
+)
+
+// RegisterCodec installs functions that encode and decode values of type
+// T in place of the usual reflection, for types that are hot or that need
+// a particular JSON form: decimals written as numbers, IDs written as
+// strings, times in a fixed layout. Marshal and Unmarshal, and everything
+// built on them, use marshal for every value of type T and unmarshal for
+// every value they decode into one, including struct fields, elements and
+// the values behind pointers to T; a nil function leaves that direction
+// as it was. unmarshal is also handed nulls, which it may read with
+// r.Null().
+//
+// Codecs are meant to be registered during initialization; registering
+// one later is safe but discards the cached decode plans of every type.
+// They take precedence over MarshalJSONTo and UnmarshalJSONFrom methods,
+// though generated methods encode the fields whose types they know inline
+// without consulting codecs. T must not be a pointer or interface type.
+func RegisterCodec[T any](marshal func(w *Writer, v T) error, unmarshal func(r *Reader, v *T) error) {
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
+		panic("simdjson: RegisterCodec of pointer or interface type " + t.String())
+	}
+
+	c := &codec{}
+	if marshal != nil {
+		c.marshal = func(w *Writer, v reflect.Value) error {
+			if v.CanAddr() {
+				// Avoids boxing a copy of v
+				return marshal(w, *v.Addr().Interface().(*T))
+			}
+			return marshal(w, v.Interface().(T))
+		}
+	}
+	if unmarshal != nil {
+		c.unmarshal = func(r *Reader, v reflect.Value) error {
+			return unmarshal(r, v.Addr().Interface().(*T))
+		}
+	}
+
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	m := make(map[reflect.Type]*codec)
+	if old := codecs.Load(); old != nil {
+		for k, v := range *old {
+			m[k] = v
+		}
+	}
+	m[t] = c
+	codecs.Store(&m)
+	// Plans built before now may have decoded T by reflection
+	resetPlans()
+}
+
+// codecFor returns the codec registered for t, or nil.
+func codecFor(t reflect.Type) *codec {
+	m := codecs.Load()
+	if m == nil {
+		return nil
+	}
+	return (*m)[t]
+}
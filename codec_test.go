@@ -0,0 +1,267 @@
+package simdjson
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// codecCents is money in cents, written as a decimal string by its codec.
+type codecCents int64
+
+// codecID is written by its codec with a prefix, and decoded by reflection.
+type codecID int
+
+// codecLate is registered after being decoded by reflection.
+type codecLate string
+
+type codecOrder struct {
+	Total  codecCents            `json:"total"`
+	Tip    *codecCents           `json:"tip"`
+	Lines  []codecCents          `json:"lines"`
+	ByItem map[string]codecCents `json:"by_item"`
+	ID     codecID               `json:"id"`
+}
+
+func init() {
+	RegisterCodec(func(w *Writer, c codecCents) error {
+		w.String(strconv.FormatInt(int64(c)/100, 10) + "." + strconv.FormatInt(int64(c)%100+100, 10)[1:])
+		return nil
+	}, func(r *Reader, c *codecCents) error {
+		if r.Null() {
+			*c = 0
+			return nil
+		}
+		s, err := r.String()
+		if err != nil {
+			return err
+		}
+		whole, frac, ok := strings.Cut(s, ".")
+		if !ok || len(frac) != 2 {
+			return errors.New("bad amount " + strconv.Quote(s))
+		}
+		n, err := strconv.ParseInt(whole+frac, 10, 64)
+		*c = codecCents(n)
+		return err
+	})
+	RegisterCodec(func(w *Writer, id codecID) error {
+		w.String("id-" + strconv.Itoa(int(id)))
+		return nil
+	}, nil)
+}
+
+func TestCodec(t *testing.T) {
+	tip := codecCents(50)
+	order := codecOrder{Total: 1234, Tip: &tip, Lines: []codecCents{1000, 234}, ByItem: map[string]codecCents{"a": 5}, ID: 7}
+	data, err := Marshal(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"total":"12.34","tip":"0.50","lines":["10.00","2.34"],"by_item":{"a":"0.05"},"id":"id-7"}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	// Top-level values are not addressable
+	if data, err := Marshal(codecCents(1)); err != nil || string(data) != `"0.01"` {
+		t.Errorf(`Expected "0.01", got %s, %v`, data, err)
+	}
+
+	var got codecOrder
+	input := `{"total":"12.34","tip":"0.50","lines":["10.00","2.34"],"by_item":{"a":"0.05"},"id":7}`
+	if err := Unmarshal([]byte(input), &got); err != nil {
+		t.Fatal(err)
+	}
+	order.ID = 7
+	if !reflect.DeepEqual(got, order) {
+		t.Errorf("Expected %+v, got %+v", order, got)
+	}
+
+	got = codecOrder{Total: 5}
+	if err := Unmarshal([]byte(`{"total":null,"tip":null}`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Total != 0 || got.Tip != nil {
+		t.Errorf("Expected null to reach the codec and clear the pointer, got %+v", got)
+	}
+
+	var c codecCents
+	if err := DecodeInto([]byte(`"1.5"`), &c); err == nil || !strings.Contains(err.Error(), "bad amount") {
+		t.Errorf("Expected the codec's error, got %v", err)
+	}
+}
+
+func TestCodecRegisteredLate(t *testing.T) {
+	var v []codecLate
+	if err := Unmarshal([]byte(`["a"]`), &v); err != nil || v[0] != "a" {
+		t.Fatalf("Expected [a], got %v, %v", v, err)
+	}
+
+	RegisterCodec(nil, func(r *Reader, l *codecLate) error {
+		s, err := r.String()
+		*l = codecLate(strings.ToUpper(s))
+		return err
+	})
+	if err := Unmarshal([]byte(`["a"]`), &v); err != nil || v[0] != "A" {
+		t.Errorf("Expected the codec to replace the cached plan, got %v, %v", v, err)
+	}
+	if data, err := Marshal(codecLate("b")); err != nil || string(data) != `"b"` {
+		t.Errorf(`Expected "b" encoded by reflection, got %s, %v`, data, err)
+	}
+}
+
+type codecRacy string
+
+// TestCodecRegisteredDuringDecode registers a codec while another
+// goroutine builds a plan using its type; a plan built across the
+// registration must not stay cached after it. The struct has many fields
+// of other types after the first, so that the registration lands between
+// planning the first and caching the struct.
+func TestCodecRegisteredDuringDecode(t *testing.T) {
+	fields := []reflect.StructField{{Name: "F0", Type: reflect.TypeFor[codecRacy]()}}
+	for i := 1; i < 2000; i++ {
+		inner := reflect.StructOf([]reflect.StructField{{Name: "X" + strconv.Itoa(i), Type: reflect.TypeFor[int]()}})
+		fields = append(fields, reflect.StructField{Name: "F" + strconv.Itoa(i), Type: inner})
+	}
+	st := reflect.StructOf(fields)
+
+	done := make(chan error)
+	go func() {
+		done <- Unmarshal([]byte(`{"F0":"a"}`), reflect.New(st).Interface())
+	}()
+	time.Sleep(2 * time.Millisecond)
+	RegisterCodec(nil, func(r *Reader, c *codecRacy) error {
+		s, err := r.String()
+		*c = codecRacy(strings.ToUpper(s))
+		return err
+	})
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	v := reflect.New(st)
+	if err := Unmarshal([]byte(`{"F0":"a"}`), v.Interface()); err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Elem().Field(0).String(); got != "A" {
+		t.Errorf("Expected the codec after registration, got %q", got)
+	}
+}
+
+func TestRegisterCodecPanics(t *testing.T) {
+	for name, register := range map[string]func(){
+		"pointer":   func() { RegisterCodec[*codecCents](nil, nil) },
+		"interface": func() { RegisterCodec[error](nil, nil) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected a panic")
+				}
+			}()
+			register()
+		})
+	}
+}
+
+// appendMillis is a duration in milliseconds, appended as seconds.
+type appendMillis int64
+
+func (m appendMillis) AppendJSON(dst []byte) ([]byte, error) {
+	dst = strconv.AppendInt(dst, int64(m)/1000, 10)
+	frac := int64(m) % 1000
+	return append(dst, '.', byte('0'+frac/100), byte('0'+frac/10%10), byte('0'+frac%10)), nil
+}
+
+// appendPoint is appended as an array, by a method on its pointer.
+type appendPoint struct{ X, Y int }
+
+func (p *appendPoint) AppendJSON(dst []byte) ([]byte, error) {
+	dst = append(dst, '[')
+	dst = strconv.AppendInt(dst, int64(p.X), 10)
+	dst = append(dst, ',')
+	dst = strconv.AppendInt(dst, int64(p.Y), 10)
+	return append(dst, ']'), nil
+}
+
+// appendFailing fails to append itself.
+type appendFailing struct{}
+
+func (appendFailing) AppendJSON(dst []byte) ([]byte, error) {
+	return dst, errors.New("cannot append")
+}
+
+// appendCoded has both a codec and an AppendJSON method.
+type appendCoded int
+
+func (appendCoded) AppendJSON(dst []byte) ([]byte, error) {
+	return append(dst, `"method"`...), nil
+}
+
+type appendTrace struct {
+	Took   appendMillis   `json:"took"`
+	At     appendPoint    `json:"at"`
+	Path   []appendPoint  `json:"path"`
+	Spans  []appendMillis `json:"spans"`
+	Origin *appendPoint   `json:"origin"`
+}
+
+func init() {
+	RegisterCodec(func(w *Writer, c appendCoded) error {
+		w.String("codec")
+		return nil
+	}, nil)
+}
+
+func TestAppender(t *testing.T) {
+	trace := &appendTrace{
+		Took:  1500,
+		At:    appendPoint{1, 2},
+		Path:  []appendPoint{{3, 4}, {5, 6}},
+		Spans: []appendMillis{7, 12000},
+	}
+	expected := `{"took":1.500,"at":[1,2],"path":[[3,4],[5,6]],"spans":[0.007,12.000],"origin":null}`
+	data, err := Marshal(trace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"value receiver", appendMillis(42), `0.042`},
+		{"pointer receiver", &appendPoint{7, 8}, `[7,8]`},
+		// Not addressable, so the pointer method cannot be called
+		{"unaddressable", appendPoint{7, 8}, `{"X":7,"Y":8}`},
+		{"in interface", map[string]interface{}{"p": &appendPoint{1, 1}}, `{"p":[1,1]}`},
+		{"codec first", []appendCoded{1}, `["codec"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+
+	if _, err := Marshal([]appendFailing{{}}); err == nil || err.Error() != "cannot append" {
+		t.Errorf("Expected the method's error, got %v", err)
+	}
+
+	// Only the result is allocated
+	if n := testing.AllocsPerRun(100, func() { Marshal(trace) }); n > 1 {
+		t.Errorf("Expected 1 allocation, got %v", n)
+	}
+}
@@ -0,0 +1,258 @@
+package simdjson
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// ColumnType is the Go type a Column collects its values as.
+type ColumnType int
+
+const (
+	Int64Column ColumnType = iota
+	Float64Column
+	StringColumn
+	BoolColumn
+)
+
+func (t ColumnType) String() string {
+	switch t {
+	case Int64Column:
+		return "int64"
+	case Float64Column:
+		return "float64"
+	case StringColumn:
+		return "string"
+	case BoolColumn:
+		return "bool"
+	}
+	return "ColumnType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// A Column collects the values of one field across NDJSON records, for
+// ExtractColumns. Only the slice for its Type is filled; every record
+// appends one value to it and one flag to Valid, so all the columns of a
+// batch have the same length and can be handed to a columnar writer such
+// as Arrow or Parquet as they are.
+type Column struct {
+	// Path is the key of the field, or the keys leading to it through
+	// nested objects separated by dots, as in "user.id".
+	Path string
+	Type ColumnType
+
+	Int64s   []int64
+	Float64s []float64
+	Strings  []string
+	Bools    []bool
+
+	// Valid reports for each record whether it has a non-null value at
+	// Path. Where it does not, the value is the zero value.
+	Valid []bool
+}
+
+// Len returns the number of values in the column.
+func (c *Column) Len() int {
+	return len(c.Valid)
+}
+
+// Reset empties the column, keeping its storage for the next batch.
+func (c *Column) Reset() {
+	c.truncate(0)
+}
+
+func (c *Column) truncate(n int) {
+	c.Valid = c.Valid[:n]
+	switch c.Type {
+	case Int64Column:
+		c.Int64s = c.Int64s[:n]
+	case Float64Column:
+		c.Float64s = c.Float64s[:n]
+	case StringColumn:
+		clear(c.Strings[n:])
+		c.Strings = c.Strings[:n]
+	case BoolColumn:
+		c.Bools = c.Bools[:n]
+	}
+}
+
+// appendNull appends a missing value.
+func (c *Column) appendNull() {
+	c.Valid = append(c.Valid, false)
+	switch c.Type {
+	case Int64Column:
+		c.Int64s = append(c.Int64s, 0)
+	case Float64Column:
+		c.Float64s = append(c.Float64s, 0)
+	case StringColumn:
+		c.Strings = append(c.Strings, "")
+	case BoolColumn:
+		c.Bools = append(c.Bools, false)
+	}
+}
+
+// set stores the value at pos in d as the value of record row.
+func (c *Column) set(d *document, pos, row int) error {
+	kind := d.kind(pos)
+	if kind == scanner.TokenNull {
+		// A later duplicate key may null an earlier value
+		c.truncate(row)
+		c.appendNull()
+		return nil
+	}
+
+	var err error
+	switch {
+	case c.Type == Int64Column && kind == scanner.TokenNumber:
+		c.Int64s[row], err = strconv.ParseInt(string(d.raw(pos)), 10, 64)
+	case c.Type == Float64Column && kind == scanner.TokenNumber:
+		c.Float64s[row], err = strconv.ParseFloat(string(d.raw(pos)), 64)
+	case c.Type == StringColumn && kind == scanner.TokenString:
+		c.Strings[row] = string(d.str(pos))
+	case c.Type == BoolColumn && (kind == scanner.TokenTrue || kind == scanner.TokenFalse):
+		c.Bools[row] = kind == scanner.TokenTrue
+	default:
+		return errors.New(c.Path + ": cannot extract " + tokenKind(kind) + " as " + c.Type.String())
+	}
+	if err != nil {
+		return errors.New(c.Path + ": cannot extract " + string(d.raw(pos)) + " as " + c.Type.String())
+	}
+	c.Valid[row] = true
+	return nil
+}
+
+func tokenKind(kind scanner.TokenType) string {
+	switch kind {
+	case scanner.TokenObjectBegin:
+		return "object"
+	case scanner.TokenArrayBegin:
+		return "array"
+	case scanner.TokenString:
+		return "string"
+	case scanner.TokenNumber:
+		return "number"
+	case scanner.TokenTrue, scanner.TokenFalse:
+		return "bool"
+	}
+	return "value"
+}
+
+// columnNode is a key in the tree of column paths.
+type columnNode struct {
+	columns  []*Column
+	children map[string]*columnNode
+}
+
+func newColumnTree(columns []*Column) *columnNode {
+	root := &columnNode{}
+	for _, c := range columns {
+		node := root
+		for _, key := range strings.Split(c.Path, ".") {
+			if node.children == nil {
+				node.children = make(map[string]*columnNode)
+			}
+			child := node.children[key]
+			if child == nil {
+				child = &columnNode{}
+				node.children[key] = child
+			}
+			node = child
+		}
+		node.columns = append(node.columns, c)
+	}
+	return root
+}
+
+// extract stores the fields of the object at pos in the columns below node.
+func (node *columnNode) extract(d *document, pos, row int) error {
+	var err error
+	d.members(pos, func(k, v int) {
+		if err != nil {
+			return
+		}
+		child := node.children[string(d.str(k))]
+		if child == nil {
+			return
+		}
+		for _, c := range child.columns {
+			if err = c.set(d, v, row); err != nil {
+				return
+			}
+		}
+		if child.children != nil && d.kind(v) == scanner.TokenObjectBegin {
+			err = child.extract(d, v, row)
+		}
+	})
+	return err
+}
+
+// ExtractColumns scans the NDJSON records in data and appends the value
+// of each column's field to it, returning the number of records. The
+// records are read in one pass, without building maps or Go values for
+// them, and fields no column selects are skipped.
+//
+// Int64 columns accept numbers written as integers, Float64 columns any
+// number, String columns strings and Bool columns true and false; another
+// value is an error. A record that lacks a field, or has null, appends an
+// invalid zero value. Records are separated by newlines and blank lines
+// are skipped, as scanner.NextRecord frames them.
+//
+// Call ExtractColumns on successive chunks of a stream, with Reset in
+// between, to collect fixed-size batches. On error, which names the line
+// of the failing record, the columns hold the records before it.
+func ExtractColumns(data []byte, columns ...*Column) (int, error) {
+	tree := newColumnTree(columns)
+	s := scanner.New()
+	defer s.Release()
+
+	rows := 0
+	if len(columns) > 0 {
+		rows = columns[0].Len()
+	}
+	for _, c := range columns {
+		if c.Len() != rows {
+			return 0, errors.New("columns have different lengths")
+		}
+	}
+
+	n := 0
+	for line := 1; len(data) > 0; line++ {
+		record := data
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			record, data = data[:i], data[i+1:]
+		} else {
+			data = nil
+		}
+		if len(bytes.TrimSpace(record)) == 0 {
+			continue
+		}
+
+		if err := extractRecord(s, tree, columns, record, rows+n); err != nil {
+			for _, c := range columns {
+				c.truncate(rows + n)
+			}
+			return n, errors.New("line " + strconv.Itoa(line) + ": " + err.Error())
+		}
+		n++
+	}
+	return n, nil
+}
+
+func extractRecord(s *scanner.Scanner, tree *columnNode, columns []*Column, record []byte, row int) error {
+	d, err := parseDocument(s, record)
+	if err != nil {
+		return err
+	}
+	defer d.release()
+
+	for _, c := range columns {
+		c.appendNull()
+	}
+	if d.kind(0) != scanner.TokenObjectBegin {
+		return nil
+	}
+	return tree.extract(d, 0, row)
+}
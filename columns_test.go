@@ -0,0 +1,148 @@
+package simdjson
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestExtractColumns(t *testing.T) {
+	data := []byte(`{"id":1,"name":"a","score":1.5,"ok":true,"user":{"id":10,"tags":["x"]}}
+{"id":2,"name":"bé","score":2,"ok":false,"user":{"id":null}}
+
+{"name":null,"extra":{"id":99},"user":"none"}
+{"id":4,"score":-0.25e1,"user":{"id":40},"id":5}
+[1,2]
+`)
+	id := &Column{Path: "id", Type: Int64Column}
+	name := &Column{Path: "name", Type: StringColumn}
+	score := &Column{Path: "score", Type: Float64Column}
+	ok := &Column{Path: "ok", Type: BoolColumn}
+	userID := &Column{Path: "user.id", Type: Int64Column}
+
+	n, err := ExtractColumns(data, id, name, score, ok, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Fatalf("Expected 5 records, got %d", n)
+	}
+
+	tests := []struct {
+		name     string
+		got      interface{}
+		expected interface{}
+	}{
+		{"id", id.Int64s, []int64{1, 2, 0, 5, 0}},
+		{"id_valid", id.Valid, []bool{true, true, false, true, false}},
+		{"name", name.Strings, []string{"a", "bé", "", "", ""}},
+		{"name_valid", name.Valid, []bool{true, true, false, false, false}},
+		{"score", score.Float64s, []float64{1.5, 2, 0, -2.5, 0}},
+		{"ok", ok.Bools, []bool{true, false, false, false, false}},
+		{"ok_valid", ok.Valid, []bool{true, true, false, false, false}},
+		{"user_id", userID.Int64s, []int64{10, 0, 0, 40, 0}},
+		{"user_id_valid", userID.Valid, []bool{true, false, false, true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, tt.got)
+			}
+		})
+	}
+	if id.Float64s != nil || id.Strings != nil || id.Bools != nil {
+		t.Error("Expected only Int64s to be filled")
+	}
+}
+
+func TestExtractColumnsBatches(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 100; i++ {
+		sb.WriteString(`{"n":` + strconv.Itoa(i) + "}\r\n")
+	}
+	lines := strings.SplitAfter(sb.String(), "\n")
+
+	col := &Column{Path: "n", Type: Int64Column}
+	for batch := 0; batch < 4; batch++ {
+		col.Reset()
+		chunk := strings.Join(lines[batch*25:(batch+1)*25], "")
+		n, err := ExtractColumns([]byte(chunk), col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 25 || col.Len() != 25 {
+			t.Fatalf("Expected 25 records, got %d (%d values)", n, col.Len())
+		}
+		if col.Int64s[0] != int64(batch*25) {
+			t.Errorf("Batch %d: expected first value %d, got %d", batch, batch*25, col.Int64s[0])
+		}
+	}
+
+	// Appending without Reset continues the columns
+	if _, err := ExtractColumns([]byte(`{"n":-1}`), col); err != nil {
+		t.Fatal(err)
+	}
+	if col.Len() != 26 || col.Int64s[25] != -1 {
+		t.Errorf("Expected -1 appended, got %v", col.Int64s)
+	}
+}
+
+func TestExtractColumnsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		column Column
+		err    string
+	}{
+		{"string_as_int", "{\"a\":1}\n{\"a\":\"2\"}", Column{Path: "a", Type: Int64Column}, "line 2: a: cannot extract string as int64"},
+		{"float_as_int", `{"a":1.5}`, Column{Path: "a", Type: Int64Column}, "line 1: a: cannot extract 1.5 as int64"},
+		{"overflow", `{"a":9223372036854775808}`, Column{Path: "a", Type: Int64Column}, "line 1: a: cannot extract 9223372036854775808 as int64"},
+		{"object_as_string", `{"a":{}}`, Column{Path: "a", Type: StringColumn}, "line 1: a: cannot extract object as string"},
+		{"number_as_bool", "\n\n{\"a\":0}", Column{Path: "a", Type: BoolColumn}, "line 3: a: cannot extract number as bool"},
+		{"invalid_json", "{\"a\":true}\n{\"a\":", Column{Path: "a", Type: BoolColumn}, "line 2: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			col := tt.column
+			other := &Column{Path: "b", Type: StringColumn}
+			n, err := ExtractColumns([]byte(tt.data), &col, other)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+				t.Fatalf("Expected error %q, got %v", tt.err, err)
+			}
+			if col.Len() != n || other.Len() != n {
+				t.Errorf("Expected %d values after error, got %d and %d", n, col.Len(), other.Len())
+			}
+		})
+	}
+
+	a := &Column{Path: "a", Type: Int64Column, Valid: []bool{true}, Int64s: []int64{1}}
+	if _, err := ExtractColumns([]byte(`{"a":2}`), a, &Column{Path: "b"}); err == nil {
+		t.Error("Expected error for columns of different lengths")
+	}
+}
+
+func BenchmarkExtractColumns(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 1000; i++ {
+		sb.WriteString(`{"id":` + strconv.Itoa(i) + `,"name":"user` + strconv.Itoa(i) + `","score":` + strconv.Itoa(i) + `.5,"active":true,"tags":["a","b"],"meta":{"created":"2024-01-01"}}` + "\n")
+	}
+	data := []byte(sb.String())
+	columns := []*Column{
+		{Path: "id", Type: Int64Column},
+		{Path: "name", Type: StringColumn},
+		{Path: "score", Type: Float64Column},
+		{Path: "meta.created", Type: StringColumn},
+	}
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, c := range columns {
+			c.Reset()
+		}
+		if _, err := ExtractColumns(data, columns...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Package easyjson is a migration shim for code written against
+// github.com/mailru/easyjson. It has the package-level entry points such
+// code calls, with the same names, backed by simdjson:
+//
+//	import "github.com/biggeezerdevelopment/simdjson-go/compat/easyjson"
+//
+//	data, err := easyjson.Marshal(order)
+//
+// easyjson's generated MarshalEasyJSON and UnmarshalEasyJSON methods take
+// its own writer and lexer types, so they cannot run on simdjson. Generate
+// MarshalJSONTo and UnmarshalJSONFrom methods with cmd/simdjson-gen
+// instead, for the same types; they are what Marshaler and Unmarshaler
+// name here. The functions accept any value, and fall back to reflection
+// for types without generated methods.
+package easyjson
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+)
+
+// Marshaler is implemented by types with methods generated by
+// cmd/simdjson-gen.
+type Marshaler = simdjson.MarshalerTo
+
+// Unmarshaler is implemented by pointers to types with methods generated
+// by cmd/simdjson-gen.
+type Unmarshaler = simdjson.UnmarshalerFrom
+
+// RawMessage is a raw encoded JSON value.
+type RawMessage = simdjson.RawMessage
+
+// Marshal returns the JSON encoding of v.
+func Marshal(v interface{}) ([]byte, error) {
+	return simdjson.Marshal(v)
+}
+
+// MarshalToWriter writes the JSON encoding of v to w, returning the bytes
+// written. Nothing is written if encoding fails.
+func MarshalToWriter(v interface{}, w io.Writer) (written int, err error) {
+	data, err := simdjson.Marshal(v)
+	if err != nil {
+		return 0, err
+	}
+	return w.Write(data)
+}
+
+// MarshalToHTTPResponseWriter sends the JSON encoding of v as the body of
+// a response, setting its Content-Type and Content-Length. started reports
+// whether the response was begun, which it is not if encoding fails.
+func MarshalToHTTPResponseWriter(v interface{}, w http.ResponseWriter) (started bool, written int, err error) {
+	data, err := simdjson.Marshal(v)
+	if err != nil {
+		return false, 0, err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	written, err = w.Write(data)
+	return true, written, err
+}
+
+// Unmarshal decodes data into the value pointed to by v.
+func Unmarshal(data []byte, v interface{}) error {
+	return simdjson.Unmarshal(data, v)
+}
+
+// UnmarshalFromReader decodes the JSON value read from r into the value
+// pointed to by v.
+func UnmarshalFromReader(r io.Reader, v interface{}) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return simdjson.Unmarshal(data, v)
+}
@@ -0,0 +1,102 @@
+package easyjson
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+)
+
+// order has methods as cmd/simdjson-gen would generate them.
+type order struct {
+	ID int
+}
+
+func (o order) MarshalJSONTo(w *simdjson.Writer) error {
+	w.RawString(`{"id":`)
+	w.Int(int64(o.ID))
+	w.RawByte('}')
+	return nil
+}
+
+func (o *order) UnmarshalJSONFrom(r *simdjson.Reader) error {
+	if err := r.BeginObject(); err != nil {
+		return err
+	}
+	for r.More() {
+		key, err := r.Key()
+		if err != nil {
+			return err
+		}
+		if string(key) != "id" {
+			if err := r.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		n, err := r.Int(64)
+		if err != nil {
+			return err
+		}
+		o.ID = int(n)
+	}
+	return r.EndObject()
+}
+
+var (
+	_ Marshaler   = order{}
+	_ Unmarshaler = (*order)(nil)
+)
+
+func TestMarshal(t *testing.T) {
+	data, err := Marshal(order{ID: 7})
+	if err != nil || string(data) != `{"id":7}` {
+		t.Errorf(`Expected {"id":7}, got %s, %v`, data, err)
+	}
+
+	// Types without generated methods use reflection
+	data, err = Marshal(map[string]bool{"ok": true})
+	if err != nil || string(data) != `{"ok":true}` {
+		t.Errorf(`Expected {"ok":true}, got %s, %v`, data, err)
+	}
+
+	var buf bytes.Buffer
+	if n, err := MarshalToWriter(order{ID: 8}, &buf); err != nil || n != buf.Len() || buf.String() != `{"id":8}` {
+		t.Errorf(`Expected {"id":8}, got %s (%d), %v`, buf.String(), n, err)
+	}
+	if n, err := MarshalToWriter(make(chan int), &buf); err == nil || n != 0 {
+		t.Errorf("Expected an error writing nothing, got %d, %v", n, err)
+	}
+}
+
+func TestMarshalToHTTPResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	started, n, err := MarshalToHTTPResponseWriter(order{ID: 9}, rec)
+	if err != nil || !started || n != len(`{"id":9}`) {
+		t.Fatalf("Expected the response started, got %v, %d, %v", started, n, err)
+	}
+	if rec.Body.String() != `{"id":9}` || rec.Header().Get("Content-Type") != "application/json" || rec.Header().Get("Content-Length") != "8" {
+		t.Errorf("Unexpected response %v %s", rec.Header(), rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	if started, _, err := MarshalToHTTPResponseWriter(make(chan int), rec); err == nil || started || rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("Expected an error before the response started, got %v, %v", started, err)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var o order
+	if err := Unmarshal([]byte(`{"x":[1],"id":3}`), &o); err != nil || o.ID != 3 {
+		t.Errorf("Expected id 3, got %+v, %v", o, err)
+	}
+	if err := UnmarshalFromReader(strings.NewReader(`{"id":4}`), &o); err != nil || o.ID != 4 {
+		t.Errorf("Expected id 4, got %+v, %v", o, err)
+	}
+	if err := UnmarshalFromReader(strings.NewReader(`{"id":`), &o); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
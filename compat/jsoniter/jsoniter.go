@@ -0,0 +1,184 @@
+// Package jsoniter is a migration shim for code written against
+// github.com/json-iterator/go. It has the entry points such code uses most,
+// with the same names and signatures, backed by simdjson, so switching is
+// a change of import path:
+//
+//	import jsoniter "github.com/biggeezerdevelopment/simdjson-go/compat/jsoniter"
+//
+//	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+//
+//	data, err := jsoniter.ConfigFastest.Marshal(v)
+//
+// Only the Config fields simdjson can honour are present, so code setting
+// others fails to compile rather than silently behaving differently. The
+// Any, Iterator and Stream APIs have no counterpart; simdjson.Value and
+// simdjson.Reader cover the same ground.
+package jsoniter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+)
+
+// API is a frozen Config, safe for concurrent use.
+type API interface {
+	Marshal(v interface{}) ([]byte, error)
+	MarshalToString(v interface{}) (string, error)
+	MarshalIndent(v interface{}, prefix, indent string) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	UnmarshalFromString(str string, v interface{}) error
+	Valid(data []byte) bool
+	NewEncoder(w io.Writer) *Encoder
+	NewDecoder(r io.Reader) *Decoder
+}
+
+// Config customizes an API. Map keys are always sorted and RawMessages
+// always validated, as in encoding/json.
+type Config struct {
+	// EscapeHTML escapes '<', '>' and '&' in strings.
+	EscapeHTML bool
+
+	// SortMapKeys is accepted for compatibility; keys are sorted either
+	// way.
+	SortMapKeys bool
+
+	// IndentionStep, if positive, indents Marshal's output by that many
+	// spaces per level.
+	IndentionStep int
+
+	// MarshalFloatWith6Digits writes floats rounded to 6 digits after the
+	// point, without trailing zeros, trading precision for speed as
+	// jsoniter does. Encoders write floats in full.
+	MarshalFloatWith6Digits bool
+}
+
+// The configurations jsoniter predefines.
+var (
+	// ConfigDefault escapes HTML.
+	ConfigDefault = Config{EscapeHTML: true}.Froze()
+
+	// ConfigCompatibleWithStandardLibrary behaves like encoding/json.
+	ConfigCompatibleWithStandardLibrary = Config{EscapeHTML: true, SortMapKeys: true}.Froze()
+
+	// ConfigFastest neither escapes HTML nor writes floats in full.
+	ConfigFastest = Config{MarshalFloatWith6Digits: true}.Froze()
+)
+
+// RawMessage is a raw encoded JSON value.
+type RawMessage = simdjson.RawMessage
+
+// Encoder writes JSON values to an output stream.
+type Encoder = simdjson.Encoder
+
+// Decoder reads JSON values from an input stream.
+type Decoder = simdjson.Decoder
+
+// frozenConfig is the API of a Config.
+type frozenConfig struct {
+	opts   simdjson.MarshalOptions
+	indent string
+}
+
+// Froze returns the API that c describes.
+func (c Config) Froze() API {
+	f := &frozenConfig{opts: simdjson.DefaultMarshalOptions()}
+	f.opts.EscapeHTML = c.EscapeHTML
+	if c.MarshalFloatWith6Digits {
+		f.opts.FloatPrecision = 6
+		f.opts.TrimFloatZeros = true
+	}
+	if c.IndentionStep > 0 {
+		f.indent = strings.Repeat(" ", c.IndentionStep)
+	}
+	return f
+}
+
+func (f *frozenConfig) Marshal(v interface{}) ([]byte, error) {
+	if f.indent != "" {
+		return f.MarshalIndent(v, "", f.indent)
+	}
+	return simdjson.MarshalWithOptions(v, &f.opts)
+}
+
+func (f *frozenConfig) MarshalToString(v interface{}) (string, error) {
+	data, err := f.Marshal(v)
+	return string(data), err
+}
+
+func (f *frozenConfig) MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+	data, err := simdjson.MarshalWithOptions(v, &f.opts)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := json.Indent(&b, data, prefix, indent); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (f *frozenConfig) Unmarshal(data []byte, v interface{}) error {
+	return simdjson.Unmarshal(data, v)
+}
+
+func (f *frozenConfig) UnmarshalFromString(str string, v interface{}) error {
+	return simdjson.Unmarshal([]byte(str), v)
+}
+
+func (f *frozenConfig) Valid(data []byte) bool {
+	return simdjson.Valid(data)
+}
+
+func (f *frozenConfig) NewEncoder(w io.Writer) *Encoder {
+	e := simdjson.NewEncoder(w)
+	e.SetEscapeHTML(f.opts.EscapeHTML)
+	return e
+}
+
+func (f *frozenConfig) NewDecoder(r io.Reader) *Decoder {
+	return simdjson.NewDecoder(r)
+}
+
+// Marshal is ConfigDefault.Marshal.
+func Marshal(v interface{}) ([]byte, error) {
+	return ConfigDefault.Marshal(v)
+}
+
+// MarshalToString is ConfigDefault.MarshalToString.
+func MarshalToString(v interface{}) (string, error) {
+	return ConfigDefault.MarshalToString(v)
+}
+
+// MarshalIndent is ConfigDefault.MarshalIndent.
+func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+	return ConfigDefault.MarshalIndent(v, prefix, indent)
+}
+
+// Unmarshal is ConfigDefault.Unmarshal.
+func Unmarshal(data []byte, v interface{}) error {
+	return ConfigDefault.Unmarshal(data, v)
+}
+
+// UnmarshalFromString is ConfigDefault.UnmarshalFromString.
+func UnmarshalFromString(str string, v interface{}) error {
+	return ConfigDefault.UnmarshalFromString(str, v)
+}
+
+// Valid is ConfigDefault.Valid.
+func Valid(data []byte) bool {
+	return ConfigDefault.Valid(data)
+}
+
+// NewEncoder is ConfigDefault.NewEncoder.
+func NewEncoder(w io.Writer) *Encoder {
+	return ConfigDefault.NewEncoder(w)
+}
+
+// NewDecoder is ConfigDefault.NewDecoder.
+func NewDecoder(r io.Reader) *Decoder {
+	return ConfigDefault.NewDecoder(r)
+}
@@ -0,0 +1,81 @@
+package jsoniter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type point struct {
+	Name string  `json:"name"`
+	X    float64 `json:"x"`
+}
+
+func TestConfigs(t *testing.T) {
+	v := point{Name: "<a>", X: 1.0 / 3}
+	tests := []struct {
+		name     string
+		api      API
+		expected string
+	}{
+		{"default", ConfigDefault, `{"name":"\u003ca\u003e","x":0.3333333333333333}`},
+		{"standard library", ConfigCompatibleWithStandardLibrary, `{"name":"\u003ca\u003e","x":0.3333333333333333}`},
+		{"fastest", ConfigFastest, `{"name":"<a>","x":0.333333}`},
+		{"indented", Config{IndentionStep: 2}.Froze(), "{\n  \"name\": \"<a>\",\n  \"x\": 0.3333333333333333\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.api.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+			s, err := tt.api.MarshalToString(v)
+			if err != nil || s != tt.expected {
+				t.Errorf("Expected %s as a string, got %s, %v", tt.expected, s, err)
+			}
+
+			var back point
+			if err := tt.api.UnmarshalFromString(s, &back); err != nil || back.Name != v.Name {
+				t.Errorf("Expected %+v back, got %+v, %v", v, back, err)
+			}
+		})
+	}
+
+	if _, err := ConfigFastest.Marshal(make(chan int)); err == nil {
+		t.Error("Expected an error for an unsupported type")
+	}
+}
+
+func TestPackageFunctions(t *testing.T) {
+	data, err := MarshalIndent(map[string]int{"b": 2, "a": 1}, ">", "\t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "{\n>\t\"a\": 1,\n>\t\"b\": 2\n>}"; string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, data)
+	}
+
+	var m map[string]RawMessage
+	if err := Unmarshal([]byte(`{"a":[1, 2]}`), &m); err != nil || string(m["a"]) != `[1, 2]` {
+		t.Errorf("Expected [1, 2], got %s, %v", m["a"], err)
+	}
+	if Valid([]byte(`{"a":`)) || !Valid([]byte(`[]`)) {
+		t.Error("Expected Valid to report only complete JSON as valid")
+	}
+
+	var buf bytes.Buffer
+	if err := ConfigFastest.NewEncoder(&buf).Encode(point{Name: "<b>"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"<b>"`) {
+		t.Errorf("Expected the encoder not to escape HTML, got %s", buf.String())
+	}
+
+	var p point
+	if err := NewDecoder(strings.NewReader(`{"name":"c","x":2}`)).Decode(&p); err != nil || p.X != 2 {
+		t.Errorf("Expected x 2, got %+v, %v", p, err)
+	}
+}
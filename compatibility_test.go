@@ -3,9 +3,13 @@ package simdjson
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -149,6 +153,415 @@ func TestMarshalCompatibility(t *testing.T) {
 	}
 }
 
+// TestEscapeHTMLCompatibility checks string escaping byte for byte against
+// encoding/json, with and without HTML escaping
+func TestEscapeHTMLCompatibility(t *testing.T) {
+	strs := []string{
+		"plain",
+		"<script>alert('x & y')</script>",
+		"a > b && c < d, in a string long enough to span words",
+		"quote \" backslash \\ slash /",
+		"\b\f\n\r\t\x00\x01\x1f\x7f",
+		"é 世界 😀",
+		"line\u2028separator\u2029",
+		"invalid \xff\xfe utf-8 \xe2\x80",
+		"map key <&>",
+	}
+
+	for _, escape := range []bool{true, false} {
+		for _, str := range strs {
+			v := map[string]interface{}{str: []string{str}}
+
+			var stdBuf, ourBuf bytes.Buffer
+			stdEnc := json.NewEncoder(&stdBuf)
+			stdEnc.SetEscapeHTML(escape)
+			if err := stdEnc.Encode(v); err != nil {
+				t.Fatal(err)
+			}
+			ourEnc := NewEncoder(&ourBuf)
+			ourEnc.SetEscapeHTML(escape)
+			if err := ourEnc.Encode(v); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := strings.TrimSuffix(stdBuf.String(), "\n")
+			if ourBuf.String() != expected {
+				t.Errorf("SetEscapeHTML(%v): expected %s, got %s", escape, expected, ourBuf.String())
+			}
+
+			if escape {
+				ours, _ := Marshal(v)
+				if string(ours) != expected {
+					t.Errorf("Marshal: expected %s, got %s", expected, ours)
+				}
+			}
+		}
+	}
+}
+
+// TestFloatCompatibility checks float formatting byte for byte against
+// encoding/json
+func TestFloatCompatibility(t *testing.T) {
+	floats := []float64{
+		0, math.Copysign(0, -1), 1, -1, 0.1, 1.5, 100, 1e6, 1e20, 1e21, 123456789,
+		1e-6, 1e-7, 0.000001234, 1.7976931348623157e308, 5e-324, 2.5e-10,
+		math.MaxFloat32, math.SmallestNonzeroFloat32, 1 << 53, 9007199254740993,
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		floats = append(floats, math.Float64frombits(r.Uint64()))
+	}
+
+	for _, f := range floats {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			continue
+		}
+		for _, v := range []interface{}{f, float32(f), []float32{float32(f)}} {
+			expected, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+			got, err := Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(expected) {
+				t.Errorf("%T %v: expected %s, got %s", v, f, expected, got)
+			}
+		}
+	}
+}
+
+type textKey struct{ a, b int }
+
+func (k textKey) MarshalText() ([]byte, error) {
+	return []byte(strconv.Itoa(k.a) + "-" + strconv.Itoa(k.b)), nil
+}
+
+type stringKey string
+
+func (k stringKey) MarshalText() ([]byte, error) {
+	return []byte("text-" + k), nil
+}
+
+// TestMapKeyCompatibility checks map key formatting and ordering byte for
+// byte against encoding/json
+func TestMapKeyCompatibility(t *testing.T) {
+	values := []interface{}{
+		map[string]int{"b": 2, "a": 1, "c": 3, "": 0, "B": 4},
+		map[int]string{10: "ten", -1: "minus one", 2: "two", 0: "zero"},
+		map[int8]bool{-128: true, 127: false},
+		map[uint64]int{18446744073709551615: 1, 0: 0, 9: 9},
+		map[uintptr]int{1: 1},
+		map[textKey]int{{1, 2}: 12, {0, 9}: 9, {10, 0}: 100},
+		map[*textKey]int{{3, 4}: 34},
+		map[stringKey]int{"z": 1, "y": 2},
+		map[string]map[int]int{"outer": {3: 3, 1: 1, 2: 2}},
+		map[int]interface{}{1: []int{1}, 2: map[int]int{5: 5}},
+		map[string]int{},
+	}
+
+	for _, v := range values {
+		expected, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Marshal(v)
+		if err != nil {
+			t.Fatalf("%T: %v", v, err)
+		}
+		if string(got) != string(expected) {
+			t.Errorf("%T: expected %s, got %s", v, expected, got)
+		}
+	}
+
+	for _, v := range []interface{}{map[float64]int{1: 1}, map[bool]int{true: 1}, map[[2]int]int{{1, 2}: 1}} {
+		if _, err := Marshal(v); err == nil {
+			t.Errorf("%T: expected error", v)
+		}
+	}
+}
+
+// TestNilCompatibility checks nil slices and maps against encoding/json,
+// and the NilAsEmpty option
+func TestNilCompatibility(t *testing.T) {
+	type withNils struct {
+		Slice []int            `json:"slice"`
+		Map   map[string]int   `json:"map"`
+		Bytes []byte           `json:"bytes"`
+		Ptr   *[]int           `json:"ptr"`
+		Any   interface{}      `json:"any"`
+		Inner map[string][]int `json:"inner"`
+	}
+	v := withNils{Any: []string(nil), Inner: map[string][]int{"a": nil}}
+
+	expected, _ := json.Marshal(v)
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(expected) {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	opts := DefaultMarshalOptions()
+	opts.NilAsEmpty = true
+	got, err = MarshalWithOptions(v, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"slice":[],"map":{},"bytes":"","ptr":null,"any":[],"inner":{"a":[]}}`
+	if string(got) != want {
+		t.Errorf("NilAsEmpty: expected %s, got %s", want, got)
+	}
+
+	got, _ = MarshalWithOptions("<>", &MarshalOptions{})
+	if string(got) != `"<>"` {
+		t.Errorf("Expected unescaped output with zero options, got %s", got)
+	}
+	got, _ = MarshalWithOptions([]int(nil), nil)
+	if string(got) != "null" {
+		t.Errorf("Expected null with default options, got %s", got)
+	}
+}
+
+// TestNullDecodeCompatibility checks decoding null and into existing
+// pointers and elements against encoding/json
+func TestNullDecodeCompatibility(t *testing.T) {
+	type inner struct{ A, B int }
+	type fields struct {
+		I   int
+		S   string
+		Arr [2]int
+		St  inner
+		P   *int
+		PP  **int
+		PPP ***inner
+		M   map[string]int
+		Sl  []int
+		Any interface{}
+		Str fmt.Stringer
+	}
+	newFields := func() interface{} {
+		one := 1
+		p := &one
+		in := &inner{B: 2}
+		pin := &in
+		return &fields{I: 1, S: "s", Arr: [2]int{1, 2}, St: inner{B: 4}, P: &one, PP: &p, PPP: &pin,
+			M: map[string]int{"a": 1}, Sl: []int{5, 6}, Any: 3}
+	}
+
+	tests := []struct {
+		name string
+		doc  string
+		new  func() interface{}
+	}{
+		{"null_fields", `{"I":null,"S":null,"Arr":null,"St":null,"P":null,"PP":null,"PPP":null,"M":null,"Sl":null,"Any":null,"Str":null}`, newFields},
+		{"nested_pointers", `{"PP":5,"PPP":{"A":3}}`, newFields},
+		{"nil_nested_pointers", `{"PP":5,"PPP":{"A":3}}`, func() interface{} { return &fields{} }},
+		{"null_elements", `{"Arr":[null,7],"Sl":[null,2,null],"M":{"a":null}}`, newFields},
+		{"top_level_pointers", `7`, func() interface{} { var p ***int; return &p }},
+		{"top_level_null", `null`, func() interface{} { x := 5; p := &x; return &p }},
+		{"int_slice", `[null,2]`, func() interface{} { s := []int{5, 6}; return &s }},
+		{"string_slice", `[null,"x"]`, func() interface{} { s := []string{"a", "b"}; return &s }},
+		{"struct_slice", `[{"A":1},null]`, func() interface{} { s := []inner{{B: 1}, {B: 2}}; return &s }},
+		{"pointer_slice", `[{"A":1},null]`, func() interface{} { s := []*inner{{B: 1}, {B: 2}}; return &s }},
+		{"interface_pointer", `{"A":1}`, func() interface{} { var i interface{} = &inner{B: 2}; return &i }},
+		{"interface_pointer_null", `null`, func() interface{} { var i interface{} = &inner{B: 2}; return &i }},
+		{"interface_pointer_pointer_null", `null`, func() interface{} {
+			in := &inner{}
+			var i interface{} = &in
+			return &i
+		}},
+		{"interface_value", `{"A":1}`, func() interface{} { var i interface{} = inner{B: 2}; return &i }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, got := tt.new(), tt.new()
+			if err := json.Unmarshal([]byte(tt.doc), want); err != nil {
+				t.Fatal(err)
+			}
+			if err := Unmarshal([]byte(tt.doc), got); err != nil {
+				t.Fatal(err)
+			}
+			expected, _ := json.Marshal(want)
+			result, _ := json.Marshal(got)
+			if string(result) != string(expected) {
+				t.Errorf("Expected %s, got %s", expected, result)
+			}
+		})
+	}
+}
+
+// TestArrayCompatibility checks decoding into Go arrays of another length
+// against encoding/json: missing elements are zeroed and extra ones dropped
+func TestArrayCompatibility(t *testing.T) {
+	type point struct{ X, Y int }
+	tests := []struct {
+		name string
+		doc  string
+		new  func() interface{}
+	}{
+		{"exact", `[1,2,3]`, func() interface{} { return &[3]int{7, 8, 9} }},
+		{"shorter", `[1]`, func() interface{} { return &[3]int{7, 8, 9} }},
+		{"empty", `[]`, func() interface{} { return &[3]int{7, 8, 9} }},
+		{"longer", `[1,2,3,4,5]`, func() interface{} { return &[3]int{} }},
+		{"longer_mixed_extras", `[1,2,"x",{"a":[1]},null]`, func() interface{} { return &[2]int{} }},
+		{"zero_length", `[1,2]`, func() interface{} { return &[0]int{} }},
+		{"null_keeps", `null`, func() interface{} { return &[2]int{7, 8} }},
+		{"null_element_keeps", `[null,2]`, func() interface{} { return &[2]int{7, 8} }},
+		{"strings", `["a"]`, func() interface{} { return &[2]string{"x", "y"} }},
+		{"structs", `[{"X":1}]`, func() interface{} { return &[2]point{{Y: 5}, {Y: 6}} }},
+		{"pointers", `[{"X":1}]`, func() interface{} { return &[2]*point{{Y: 5}, {Y: 6}} }},
+		{"nested", `[[1],[2,3,4],[]]`, func() interface{} { return &[2][2]int{{7, 7}, {8, 8}} }},
+		{"bytes", `[1,2,3]`, func() interface{} { return &[2]byte{9, 9} }},
+		{"in_struct", `{"A":[1],"B":[1,2,3]}`, func() interface{} {
+			return &struct{ A, B [2]float64 }{[2]float64{7, 8}, [2]float64{7, 8}}
+		}},
+		{"in_slice", `[[1],[1,2,3]]`, func() interface{} { s := [][2]int{}; return &s }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, got := tt.new(), tt.new()
+			if err := json.Unmarshal([]byte(tt.doc), want); err != nil {
+				t.Fatal(err)
+			}
+			if err := Unmarshal([]byte(tt.doc), got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected %v, got %v", want, got)
+			}
+		})
+	}
+
+	for _, doc := range []string{`"ab"`, `{"0":1}`, `[1,"x"]`, `[1,2`} {
+		var a [2]int
+		if err := Unmarshal([]byte(doc), &a); err == nil {
+			t.Errorf("Expected error for %s", doc)
+		}
+	}
+}
+
+// TestExistingValueCompatibility checks that decoding into maps and slices
+// that already hold values merges and reuses them as encoding/json does
+func TestExistingValueCompatibility(t *testing.T) {
+	type point struct{ X, Y int }
+	tests := []struct {
+		name string
+		doc  string
+		new  func() interface{}
+	}{
+		{"map_merge", `{"b":3,"c":4}`, func() interface{} { return &map[string]int{"a": 1, "b": 2} }},
+		{"map_null_value", `{"a":null}`, func() interface{} { return &map[string]int{"a": 1} }},
+		{"map_struct_values", `{"a":{"Y":5}}`, func() interface{} { return &map[string]point{"a": {1, 2}} }},
+		{"map_pointer_values", `{"a":{"Y":5}}`, func() interface{} { return &map[string]*point{"a": {1, 2}} }},
+		{"string_map", `{"b":"y","c":null}`, func() interface{} { return &map[string]string{"a": "x", "b": "x"} }},
+		{"interface_map", `{"b":["y"]}`, func() interface{} { return &map[string]interface{}{"a": true, "b": "x"} }},
+		{"map_null", `null`, func() interface{} { return &map[string]int{"a": 1} }},
+		{"slice_shorter", `[9]`, func() interface{} { return &[]int{1, 2, 3, 4} }},
+		{"slice_longer", `[1,2,3,4,5]`, func() interface{} { return &[]int{7, 8} }},
+		{"slice_empty", `[]`, func() interface{} { return &[]int{1, 2} }},
+		{"slice_null", `null`, func() interface{} { return &[]int{1, 2} }},
+		{"slice_null_elements", `[null,5,null]`, func() interface{} { return &[]int{1, 2} }},
+		{"slice_structs", `[{"Y":5},{"Y":6}]`, func() interface{} { return &[]point{{1, 2}} }},
+		{"slice_pointers", `[{"Y":5},null]`, func() interface{} { return &[]*point{{1, 2}, {3, 4}} }},
+		{"slice_maps", `[{"b":2}]`, func() interface{} { return &[]map[string]int{{"a": 1}} }},
+		{"slice_interfaces", `[{"b":"y"},true]`, func() interface{} { return &[]interface{}{map[string]interface{}{"a": "x"}} }},
+		{"bulk_strings", `["x",null,"z"]`, func() interface{} { return &[]string{"a", "b"} }},
+		{"bulk_floats", `[null,2.5]`, func() interface{} { return &[]float64{1, 2, 3} }},
+		{"in_struct", `{"M":{"b":2},"S":[3]}`, func() interface{} {
+			return &struct {
+				M map[string]int
+				S []int
+			}{map[string]int{"a": 1}, []int{1, 2}}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, got := tt.new(), tt.new()
+			if err := json.Unmarshal([]byte(tt.doc), want); err != nil {
+				t.Fatal(err)
+			}
+			if err := Unmarshal([]byte(tt.doc), got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected %v, got %v", want, got)
+			}
+		})
+	}
+
+	// Backing arrays with room for the elements are reused, whatever the
+	// length of the slice
+	for _, doc := range []string{`[]`, `[1,2]`, `[1,2,3,4]`} {
+		ints := make([]int, 3, 4)
+		backing := &ints[:1][0]
+		if err := Unmarshal([]byte(doc), &ints); err != nil || &ints[:1][0] != backing {
+			t.Errorf("Expected %s to reuse the backing array, got %v (%v)", doc, ints, err)
+		}
+	}
+	raw := make(RawMessage, 0, 16)
+	backing := &raw[:1][0]
+	if err := Unmarshal([]byte(`[1]`), &raw); err != nil || &raw[0] != backing {
+		t.Errorf("Expected RawMessage to reuse its backing array, got %s (%v)", raw, err)
+	}
+}
+
+// TestNumberTypeErrorCompatibility checks that numbers which do not fit
+// their target fail with the UnmarshalTypeError encoding/json reports
+func TestNumberTypeErrorCompatibility(t *testing.T) {
+	type small int8
+	tests := []struct {
+		name string
+		doc  string
+		new  func() interface{}
+	}{
+		{"int8_overflow", `300`, func() interface{} { return new(int8) }},
+		{"int8_underflow", `-129`, func() interface{} { return new(int8) }},
+		{"named_int8", `128`, func() interface{} { return new(small) }},
+		{"int_fraction", `1.5`, func() interface{} { return new(int) }},
+		{"int_exponent", `1e2`, func() interface{} { return new(int) }},
+		{"int64_overflow", `9223372036854775808`, func() interface{} { return new(int64) }},
+		{"uint_negative", `-1`, func() interface{} { return new(uint) }},
+		{"uint16_overflow", `65536`, func() interface{} { return new(uint16) }},
+		{"float32_overflow", `1e39`, func() interface{} { return new(float32) }},
+		{"float64_overflow", `-1e400`, func() interface{} { return new(float64) }},
+		{"interface_overflow", `1e400`, func() interface{} { return new(interface{}) }},
+		{"field", `{"N":70000}`, func() interface{} { return &struct{ N uint16 }{} }},
+		{"element", `[1,2.5]`, func() interface{} { return &[]int{} }},
+		{"bulk_element", `[1,2.5]`, func() interface{} { return &[]int64{} }},
+		{"string_into_int", `{"N":"1"}`, func() interface{} { return &struct{ N int }{} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdErr := json.Unmarshal([]byte(tt.doc), tt.new())
+			var want *json.UnmarshalTypeError
+			if !errors.As(stdErr, &want) {
+				t.Fatalf("encoding/json: expected UnmarshalTypeError, got %v", stdErr)
+			}
+
+			err := Unmarshal([]byte(tt.doc), tt.new())
+			var got *UnmarshalTypeError
+			if !errors.As(err, &got) {
+				t.Fatalf("Expected UnmarshalTypeError, got %v", err)
+			}
+			if got.Value != want.Value || got.Type != want.Type {
+				t.Errorf("Expected %s into %v, got %s into %v", want.Value, want.Type, got.Value, got.Type)
+			}
+			// Our offset is where the value starts, not where it ends
+			if value := strings.TrimPrefix(got.Value, "number "); !strings.HasPrefix(tt.doc[got.Offset:], value) &&
+				!strings.HasPrefix(tt.doc[got.Offset:], `"`) {
+				t.Errorf("Expected offset of %s, got %d", value, got.Offset)
+			}
+		})
+	}
+}
+
 // TestValidationCompatibility tests JSON validation
 func TestValidationCompatibility(t *testing.T) {
 	testCases := []struct {
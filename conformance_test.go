@@ -0,0 +1,82 @@
+package simdjson
+
+import (
+	"embed"
+	"path"
+	"strings"
+	"testing"
+)
+
+// The test_parsing cases of JSONTestSuite (github.com/nst/JSONTestSuite):
+// y_ documents must be accepted, n_ documents rejected, and i_ documents
+// are left to the implementation.
+//
+//go:embed testdata/JSONTestSuite/test_parsing
+var jsonTestSuite embed.FS
+
+const jsonTestSuiteDir = "testdata/JSONTestSuite/test_parsing"
+
+// TestConformance runs every JSONTestSuite case through Valid,
+// ValidateWithError, Unmarshal and the Parser, and logs a score:
+//
+//	go test -run Conformance -v
+func TestConformance(t *testing.T) {
+	entries, err := jsonTestSuite.ReadDir(jsonTestSuiteDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var total, passed, accepted, implDefined int
+	for _, e := range entries {
+		name := e.Name()
+		data, err := jsonTestSuite.ReadFile(path.Join(jsonTestSuiteDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run(strings.TrimSuffix(name, ".json"), func(t *testing.T) {
+			results := map[string]bool{
+				"Valid":             Valid(data),
+				"ValidateWithError": ValidateWithError(data) == nil,
+			}
+			var v interface{}
+			results["Unmarshal"] = Unmarshal(data, &v) == nil
+			p := NewParser(nil)
+			if res, err := p.Parse(data); err == nil {
+				results["Parse"] = true
+				res.Release()
+			} else {
+				results["Parse"] = false
+			}
+
+			switch name[0] {
+			case 'y', 'n':
+				total++
+				ok := true
+				for api, got := range results {
+					if got != (name[0] == 'y') {
+						t.Errorf("Expected %s to return ok=%v, got ok=%v for %.80q", api, name[0] == 'y', got, data)
+						ok = false
+					}
+				}
+				if ok {
+					passed++
+				}
+			case 'i':
+				implDefined++
+				// Decoding may still fail where validation succeeds, for
+				// numbers out of float64 range
+				if results["Unmarshal"] && !results["Valid"] {
+					t.Errorf("Unmarshal accepted %q, which Valid rejects", data)
+				}
+				if results["Valid"] {
+					accepted++
+				}
+				t.Logf("Valid=%v Unmarshal=%v", results["Valid"], results["Unmarshal"])
+			}
+		})
+	}
+
+	t.Logf("JSONTestSuite conformance: %d/%d y_ and n_ cases pass, %d of %d i_ cases accepted",
+		passed, total, accepted, implDefined)
+}
@@ -0,0 +1,93 @@
+package simdjson
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingContext is canceled from the nth call to Err on.
+type countingContext struct {
+	context.Context
+	n, calls int
+}
+
+func (c *countingContext) Done() <-chan struct{} {
+	return make(chan struct{})
+}
+
+func (c *countingContext) Err() error {
+	if c.calls++; c.calls >= c.n {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestUnmarshalContext(t *testing.T) {
+	// 200k short objects: a few checks while tokenizing, many more while
+	// decoding
+	data := []byte("[" + strings.Repeat(`{"a":1},`, 200000) + `{"a":1}]`)
+	type item struct{ A int }
+
+	t.Run("NotCanceled", func(t *testing.T) {
+		ctx := &countingContext{Context: context.Background(), n: 1 << 30}
+		var v []item
+		if err := UnmarshalContext(ctx, data, &v); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(v) != 200001 {
+			t.Errorf("Expected 200001 items, got %d", len(v))
+		}
+		if ctx.calls < 100 {
+			t.Errorf("Expected the context checked regularly, got %d checks", ctx.calls)
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		// Before tokenizing, while tokenizing and while decoding
+		for _, n := range []int{1, 3, 50} {
+			ctx := &countingContext{Context: context.Background(), n: n}
+			var v []item
+			if err := UnmarshalContext(ctx, data, &v); !errors.Is(err, context.Canceled) {
+				t.Errorf("Canceled at check %d: expected context.Canceled, got %v", n, err)
+			}
+			if ctx.calls != n {
+				t.Errorf("Canceled at check %d: expected decoding to stop, got %d checks", n, ctx.calls)
+			}
+		}
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+		var v []item
+		if err := UnmarshalContext(ctx, data, &v); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("Decoder", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var v []item
+		if err := NewDecoder(bytes.NewReader(data)).DecodeContext(ctx, &v); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		cancel()
+		if err := NewDecoder(bytes.NewReader(data)).DecodeContext(ctx, &v); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("PooledDecoderReset", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var v []item
+		UnmarshalContext(ctx, data, &v)
+		if err := Unmarshal(data, &v); err != nil {
+			t.Errorf("Expected a later Unmarshal to ignore the context, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package simdjson
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// corpusNames are the documents in testdata/corpus; see its README.
+var corpusNames = []string{"twitter.json", "citm_catalog.json", "canada.json"}
+
+// loadCorpus returns the document of the corpus named name.
+func loadCorpus(tb testing.TB, name string) []byte {
+	tb.Helper()
+	f, err := os.Open(filepath.Join("testdata", "corpus", name+".gz"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return data
+}
+
+func TestCorpora(t *testing.T) {
+	for _, name := range corpusNames {
+		t.Run(name, func(t *testing.T) {
+			data := loadCorpus(t, name)
+			if !json.Valid(data) || !Valid(data) {
+				t.Fatal("Expected a valid document")
+			}
+			var v interface{}
+			if err := Unmarshal(data, &v); err != nil {
+				t.Fatal(err)
+			}
+
+			// Re-encoded, it matches encoding/json's reading of it
+			var got, expected interface{}
+			out, err := Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			json.Unmarshal(out, &got)
+			json.Unmarshal(data, &expected)
+			a, _ := json.Marshal(got)
+			b, _ := json.Marshal(expected)
+			if !bytes.Equal(a, b) {
+				t.Error("Expected the document to survive Unmarshal and Marshal")
+			}
+		})
+	}
+}
@@ -0,0 +1,381 @@
+// Package csvjson converts CSV (RFC 4180) to NDJSON or a JSON array of
+// objects, one per record, with field names taken from the header row. The
+// input is streamed through a fixed buffer, so files of any size convert in
+// constant memory, and unquoted fields are split a word at a time with the
+// same SWAR kernels the JSON scanner uses.
+package csvjson
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/jsonenc"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// Format selects the shape of the output.
+type Format int
+
+const (
+	// NDJSON writes one object per line.
+	NDJSON Format = iota
+	// Array writes a single JSON array holding every object.
+	Array
+)
+
+// Options configure a conversion. The zero value reads a comma-separated
+// file with a header row and writes every field as a string in NDJSON.
+type Options struct {
+	// Comma is the field separator, ',' if zero. It must not be a quote,
+	// '\r' or '\n'.
+	Comma byte
+
+	// Header holds the field names. If nil, the first record of the input
+	// is the header.
+	Header []string
+
+	// Format is the output format.
+	Format Format
+
+	// Numbers writes fields that are valid JSON numbers as numbers rather
+	// than strings. Fields JSON does not accept as numbers, such as those
+	// with leading zeros (zip codes, "007") or a leading '+', stay strings.
+	Numbers bool
+
+	// Booleans writes fields that are exactly true or false as booleans.
+	Booleans bool
+
+	// EmptyAsNull writes empty fields as null rather than "".
+	EmptyAsNull bool
+}
+
+var (
+	ErrBareQuote   = errors.New("bare quote in unquoted field")
+	ErrQuote       = errors.New("extraneous or missing quote in quoted field")
+	ErrFieldCount  = errors.New("wrong number of fields")
+	ErrInvalidUTF8 = errors.New("invalid UTF-8")
+)
+
+// A ParseError is returned for malformed input. Err is one of the errors
+// above.
+type ParseError struct {
+	// Line is the line on which the record starts, from 1.
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return "csvjson: line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+const (
+	bufferSize = 64 * 1024
+	flushSize  = 32 * 1024
+)
+
+// Convert reads CSV from r and writes the JSON conversion to w. Blank
+// lines are skipped, \r\n line endings are accepted, and a leading UTF-8
+// byte order mark (as Excel writes) is dropped. Every record must have as
+// many fields as the header. NDJSON output ends each object with a
+// newline; Array output is a single line.
+func Convert(w io.Writer, r io.Reader, opts Options) error {
+	c := &converter{r: r, comma: opts.Comma, buf: make([]byte, 0, bufferSize)}
+	if c.comma == 0 {
+		c.comma = ','
+	}
+	if c.comma == '"' || c.comma == '\r' || c.comma == '\n' {
+		return errors.New("csvjson: invalid separator " + strconv.QuoteRune(rune(c.comma)))
+	}
+	for len(c.buf) < 3 && !c.eof {
+		if err := c.fill(); err != nil {
+			return err
+		}
+	}
+	if bytes.HasPrefix(c.buf, []byte("\xef\xbb\xbf")) {
+		c.pos = 3
+	}
+
+	header := opts.Header
+	if header == nil {
+		ok, err := c.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			header = make([]string, len(c.ends))
+			for i := range header {
+				header[i] = string(c.field(i))
+			}
+		}
+	}
+
+	// The keys are quoted once, with the separators that precede them
+	keys := make([][]byte, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		if seen[name] {
+			return errors.New("csvjson: duplicate field name " + strconv.Quote(name))
+		}
+		seen[name] = true
+		if !utf8.ValidString(name) {
+			return errors.New("csvjson: invalid UTF-8 in field name " + strconv.Quote(name))
+		}
+		if i == 0 {
+			keys[i] = append(keys[i], '{')
+		} else {
+			keys[i] = append(keys[i], ',')
+		}
+		keys[i] = appendString(keys[i], []byte(name))
+		keys[i] = append(keys[i], ':')
+	}
+
+	var out []byte
+	if opts.Format == Array {
+		out = append(out, '[')
+	}
+	n := 0
+	for {
+		ok, err := c.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		if len(c.ends) != len(header) {
+			return &ParseError{Line: c.recordLine, Err: ErrFieldCount}
+		}
+
+		if opts.Format == Array && n > 0 {
+			out = append(out, ',')
+		}
+		n++
+		for i, key := range keys {
+			out = append(out, key...)
+			f := c.field(i)
+			switch {
+			case len(f) == 0 && opts.EmptyAsNull:
+				out = append(out, "null"...)
+			case opts.Numbers && jsonenc.ValidNumber(f),
+				opts.Booleans && (string(f) == "true" || string(f) == "false"):
+				out = append(out, f...)
+			default:
+				if !utf8.Valid(f) {
+					return &ParseError{Line: c.recordLine, Err: ErrInvalidUTF8}
+				}
+				out = appendString(out, f)
+			}
+		}
+		out = append(out, '}')
+		if opts.Format != Array {
+			out = append(out, '\n')
+		}
+
+		if len(out) >= flushSize {
+			if _, err := w.Write(out); err != nil {
+				return err
+			}
+			out = out[:0]
+		}
+	}
+	if opts.Format == Array {
+		out = append(out, ']', '\n')
+	}
+	if len(out) > 0 {
+		if _, err := w.Write(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// converter splits CSV records out of a buffered reader.
+type converter struct {
+	r     io.Reader
+	comma byte
+
+	// The unread input is buf[pos:]
+	buf []byte
+	pos int
+	eof bool
+
+	line       int // lines consumed so far
+	recordLine int // line on which the current record starts
+
+	// The fields of the current record, unquoted and concatenated; field i
+	// ends at ends[i]
+	record []byte
+	ends   []int
+}
+
+func (c *converter) field(i int) []byte {
+	start := 0
+	if i > 0 {
+		start = c.ends[i-1]
+	}
+	return c.record[start:c.ends[i]]
+}
+
+// next reads the next non-blank record, reporting false at the end of the
+// input.
+func (c *converter) next() (bool, error) {
+	for {
+		n, err := c.parseRecord(c.buf[c.pos:], c.eof)
+		if err != nil {
+			return false, &ParseError{Line: c.line + 1, Err: err}
+		}
+		if n < 0 {
+			if err := c.fill(); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		c.recordLine = c.line + 1
+		c.line += bytes.Count(c.buf[c.pos:c.pos+n], []byte{'\n'})
+		c.pos += n
+		if len(c.ends) > 0 {
+			return true, nil
+		}
+		if n == 0 {
+			return false, nil
+		}
+	}
+}
+
+// fill reads more input after the unread part of the buffer, growing it
+// when a record does not fit.
+func (c *converter) fill() error {
+	if c.pos > 0 {
+		c.buf = c.buf[:copy(c.buf, c.buf[c.pos:])]
+		c.pos = 0
+	}
+	if len(c.buf) == cap(c.buf) {
+		c.buf = append(c.buf, make([]byte, len(c.buf))...)[:len(c.buf)]
+	}
+	n, err := c.r.Read(c.buf[len(c.buf):cap(c.buf)])
+	c.buf = c.buf[:len(c.buf)+n]
+	if err == io.EOF {
+		c.eof = true
+		return nil
+	}
+	return err
+}
+
+// parseRecord parses the record at the start of data into record and
+// ends, returning the number of bytes it takes, including its line ending.
+// A blank line parses as a record with no fields. It returns -1 if data
+// ends before the record does and more input may follow.
+func (c *converter) parseRecord(data []byte, atEOF bool) (int, error) {
+	c.record = c.record[:0]
+	c.ends = c.ends[:0]
+	if len(data) == 0 {
+		if !atEOF {
+			return -1, nil
+		}
+		return 0, nil
+	}
+	if data[0] == '\n' {
+		return 1, nil
+	}
+	if data[0] == '\r' && (len(data) == 1 && atEOF || len(data) > 1 && data[1] == '\n') {
+		return min(len(data), 2), nil
+	}
+
+	i := 0
+	for {
+		if i < len(data) && data[i] == '"' {
+			// A quoted field, in which "" is a quote
+			i++
+			for {
+				j := bytes.IndexByte(data[i:], '"')
+				if j < 0 {
+					if atEOF {
+						return 0, ErrQuote
+					}
+					return -1, nil
+				}
+				c.record = append(c.record, data[i:i+j]...)
+				i += j + 1
+				if i == len(data) || data[i] != '"' {
+					break
+				}
+				c.record = append(c.record, '"')
+				i++
+			}
+			if i == len(data) && !atEOF {
+				// The next byte may be a quote
+				return -1, nil
+			}
+			if i < len(data) && data[i] == '\r' {
+				if i+1 == len(data) && !atEOF {
+					return -1, nil
+				}
+				if i+1 == len(data) || data[i+1] == '\n' {
+					i++
+				}
+			}
+			if i < len(data) && data[i] != c.comma && data[i] != '\n' {
+				return 0, ErrQuote
+			}
+		} else {
+			j := i + scanner.IndexDelimiter(data[i:], c.comma)
+			if j == len(data) && !atEOF {
+				return -1, nil
+			}
+			if j < len(data) && data[j] == '"' {
+				return 0, ErrBareQuote
+			}
+			field := data[i:j]
+			if (j == len(data) || data[j] == '\n') && len(field) > 0 && field[len(field)-1] == '\r' {
+				field = field[:len(field)-1]
+			}
+			c.record = append(c.record, field...)
+			i = j
+		}
+		c.ends = append(c.ends, len(c.record))
+
+		if i == len(data) {
+			return i, nil
+		}
+		if data[i] == '\n' {
+			return i + 1, nil
+		}
+		i++ // the separator
+	}
+}
+
+// appendString appends s as a JSON string.
+func appendString(dst, s []byte) []byte {
+	const hex = "0123456789abcdef"
+	dst = append(dst, '"')
+	start := 0
+	for i, c := range s {
+		if c >= 0x20 && c != '"' && c != '\\' {
+			continue
+		}
+		dst = append(dst, s[start:i]...)
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+		}
+		start = i + 1
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
@@ -0,0 +1,193 @@
+package csvjson
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+)
+
+func convert(input string, opts Options) (string, error) {
+	var buf bytes.Buffer
+	err := Convert(&buf, strings.NewReader(input), opts)
+	return buf.String(), err
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     Options
+		expected string
+	}{
+		{"basic", "a,b\n1,x\n2,y\n", Options{}, "{\"a\":\"1\",\"b\":\"x\"}\n{\"a\":\"2\",\"b\":\"y\"}\n"},
+		{"no_trailing_newline", "a,b\n1,x", Options{}, "{\"a\":\"1\",\"b\":\"x\"}\n"},
+		{"crlf", "a,b\r\n1,x\r\n", Options{}, "{\"a\":\"1\",\"b\":\"x\"}\n"},
+		{"blank_lines", "a\n\n1\r\n\r\n2\n\n", Options{}, "{\"a\":\"1\"}\n{\"a\":\"2\"}\n"},
+		{"bom", "\xef\xbb\xbfa\n1\n", Options{}, "{\"a\":\"1\"}\n"},
+		{"header_only", "a,b\n", Options{}, ""},
+		{"empty", "", Options{}, ""},
+		{"empty_fields", "a,b,c\n,,\n", Options{}, "{\"a\":\"\",\"b\":\"\",\"c\":\"\"}\n"},
+		{"quoted", "a,b\n\"x, y\",\"say \"\"hi\"\"\"\n", Options{}, "{\"a\":\"x, y\",\"b\":\"say \\\"hi\\\"\"}\n"},
+		{"quoted_newline", "a,b\n\"line1\nline2\",2\n", Options{}, "{\"a\":\"line1\\nline2\",\"b\":\"2\"}\n"},
+		{"quoted_crlf", "a,b\r\n\"x\",\"y\"\r\n", Options{}, "{\"a\":\"x\",\"b\":\"y\"}\n"},
+		{"quoted_last_at_eof", "a\n\"x\"", Options{}, "{\"a\":\"x\"}\n"},
+		{"escapes", "a\n\"tab\there\\back\"\n", Options{}, "{\"a\":\"tab\\there\\\\back\"}\n"},
+		{"unicode", "名前\n世界\n", Options{}, "{\"名前\":\"世界\"}\n"},
+		{"separator", "a;b\n1;x,y\n", Options{Comma: ';'}, "{\"a\":\"1\",\"b\":\"x,y\"}\n"},
+		{"tabs", "a\tb\n1\t2\n", Options{Comma: '\t'}, "{\"a\":\"1\",\"b\":\"2\"}\n"},
+		{"header_option", "1,x\n2,y\n", Options{Header: []string{"n", "s"}}, "{\"n\":\"1\",\"s\":\"x\"}\n{\"n\":\"2\",\"s\":\"y\"}\n"},
+		{"array", "a\n1\n2\n", Options{Format: Array}, "[{\"a\":\"1\"},{\"a\":\"2\"}]\n"},
+		{"empty_array", "a\n", Options{Format: Array}, "[]\n"},
+		{
+			"numbers",
+			"a,b,c,d,e,f,g\n1,-2.5,1e3,007,+1,1.,0x10\n",
+			Options{Numbers: true},
+			"{\"a\":1,\"b\":-2.5,\"c\":1e3,\"d\":\"007\",\"e\":\"+1\",\"f\":\"1.\",\"g\":\"0x10\"}\n",
+		},
+		{
+			"booleans",
+			"a,b,c\ntrue,false,TRUE\n",
+			Options{Booleans: true},
+			"{\"a\":true,\"b\":false,\"c\":\"TRUE\"}\n",
+		},
+		{
+			"no_inference",
+			"a,b\n1,true\n",
+			Options{},
+			"{\"a\":\"1\",\"b\":\"true\"}\n",
+		},
+		{
+			"empty_as_null",
+			"a,b,c\n,\"\",x\n",
+			Options{EmptyAsNull: true},
+			"{\"a\":null,\"b\":null,\"c\":\"x\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := convert(tt.input, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+
+			// The output must not depend on how the input is split
+			var buf bytes.Buffer
+			if err := Convert(&buf, iotest.OneByteReader(strings.NewReader(tt.input)), tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("One byte reads: expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		err   error
+		line  int
+	}{
+		{"bare_quote", "a\nx\"y\n", Options{}, ErrBareQuote, 2},
+		{"unterminated", "a\n1\n\"x\n", Options{}, ErrQuote, 3},
+		{"after_quote", "a\n\"x\"y\n", Options{}, ErrQuote, 2},
+		{"too_many", "a\n1\n1,2\n", Options{}, ErrFieldCount, 3},
+		{"too_few", "a,b\n1\n", Options{}, ErrFieldCount, 2},
+		{"after_multiline", "a\n\"1\n2\"\n3,4\n", Options{}, ErrFieldCount, 4},
+		{"invalid_utf8", "a\n\xff\n", Options{}, ErrInvalidUTF8, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := convert(tt.input, tt.opts)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Expected ParseError, got %v", err)
+			}
+			if !errors.Is(err, tt.err) || perr.Line != tt.line {
+				t.Errorf("Expected line %d: %v, got %v", tt.line, tt.err, err)
+			}
+		})
+	}
+
+	for _, input := range []string{"a,a\n1,2\n", "\xff\n1\n"} {
+		if _, err := convert(input, Options{}); err == nil {
+			t.Errorf("Expected error for header %q", input)
+		}
+	}
+	if _, err := convert("a\n", Options{Comma: '"'}); err == nil {
+		t.Error("Expected error for quote separator")
+	}
+}
+
+// TestConvertMatchesEncodingCSV checks the fields against encoding/csv,
+// with records long enough to cross buffer refills.
+func TestConvertMatchesEncodingCSV(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("id,text,note\n")
+	w := csv.NewWriter(&input)
+	for i := 0; i < 5000; i++ {
+		w.Write([]string{
+			strings.Repeat("9", i%7+1),
+			strings.Repeat("x,\"y\"\n", i%13),
+			"plain " + strings.Repeat("z", i%100),
+		})
+	}
+	w.Flush()
+
+	var buf bytes.Buffer
+	if err := Convert(&buf, strings.NewReader(input.String()), Options{Format: Array}); err != nil {
+		t.Fatal(err)
+	}
+	if !simdjson.Valid(buf.Bytes()) {
+		t.Fatal("Output is not valid JSON")
+	}
+	var got []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(input.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(records)-1 {
+		t.Fatalf("Expected %d records, got %d", len(records)-1, len(got))
+	}
+	for i, record := range records[1:] {
+		for j, name := range records[0] {
+			if got[i][name] != record[j] {
+				t.Fatalf("Record %d field %s: expected %q, got %q", i, name, record[j], got[i][name])
+			}
+		}
+	}
+}
+
+func BenchmarkConvert(b *testing.B) {
+	var input strings.Builder
+	input.WriteString("id,name,score,active\n")
+	for i := 0; i < 10000; i++ {
+		input.WriteString("12345,\"Doe, Jane\",98.5,true\n")
+	}
+	data := input.String()
+	opts := Options{Numbers: true, Booleans: true}
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := Convert(&bytes.Buffer{}, strings.NewReader(data), opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
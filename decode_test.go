@@ -0,0 +1,1087 @@
+package simdjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+type decodeNode struct {
+	Name     string        `json:"name"`
+	Children []*decodeNode `json:"children,omitempty"`
+}
+
+type decodeTarget struct {
+	ID      int64             `json:"id"`
+	Score   float32           `json:"score"`
+	Small   int8              `json:"small"`
+	Tags    []string          `json:"tags"`
+	Attrs   map[string]int    `json:"attrs"`
+	Fixed   [2]int            `json:"fixed"`
+	Ptr     *string           `json:"ptr"`
+	Any     interface{}       `json:"any"`
+	Raw     []byte            `json:"raw"`
+	Nested  *decodeNode       `json:"nested"`
+	Skipped string            `json:"-"`
+	Renamed string            `json:",omitempty"`
+	Extra   map[string]string `json:"extra"`
+}
+
+func TestDecode(t *testing.T) {
+	input := `{"id":12,"score":1.5,"small":-3,"tags":["a","b\n"],"attrs":{"x":1},"fixed":[7,8,9],` +
+		`"ptr":"p","any":{"k":[1,"two",null]},"raw":"AAEC","nested":{"name":"root","children":[{"name":"leaf"}]},` +
+		`"Skipped":"no","renamed":"case-insensitive","extra":null,"unknown":[{"deep":true}]}`
+
+	got, err := Decode[decodeTarget]([]byte(input))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	var want decodeTarget
+	if err := json.Unmarshal([]byte(input), &want); err != nil {
+		t.Fatal(err)
+	}
+	// encoding/json produces float64 numbers inside interface{} values
+	want.Any = map[string]interface{}{"k": []interface{}{int64(1), "two", nil}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestDecodeInto(t *testing.T) {
+	dst := decodeTarget{Tags: []string{"old", "values", "here"}, Attrs: map[string]int{"keep": 1}}
+	if err := DecodeInto([]byte(`{"tags":["new"],"attrs":{"add":2}}`), &dst); err != nil {
+		t.Fatalf("DecodeInto failed: %v", err)
+	}
+	if !reflect.DeepEqual(dst.Tags, []string{"new"}) {
+		t.Errorf("Expected [new], got %v", dst.Tags)
+	}
+	if !reflect.DeepEqual(dst.Attrs, map[string]int{"keep": 1, "add": 2}) {
+		t.Errorf("Expected merged map, got %v", dst.Attrs)
+	}
+
+	var n int
+	if err := DecodeInto[int]([]byte("42"), nil); err == nil {
+		t.Error("Expected error for nil destination")
+	}
+	if err := DecodeInto([]byte(" 42 "), &n); err != nil || n != 42 {
+		t.Errorf("Expected 42, got %d (%v)", n, err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		fn    func([]byte) error
+	}{
+		{"string_into_int", `"x"`, func(b []byte) error { _, err := Decode[int](b); return err }},
+		{"overflow", `300`, func(b []byte) error { _, err := Decode[int8](b); return err }},
+		{"negative_uint", `-1`, func(b []byte) error { _, err := Decode[uint](b); return err }},
+		{"float_into_int", `1.5`, func(b []byte) error { _, err := Decode[int](b); return err }},
+		{"object_into_slice", `{}`, func(b []byte) error { _, err := Decode[[]int](b); return err }},
+		{"missing_comma", `[1 2]`, func(b []byte) error { _, err := Decode[[]int](b); return err }},
+		{"trailing_data", `1 2`, func(b []byte) error { _, err := Decode[int](b); return err }},
+		{"unterminated", `{"a":1`, func(b []byte) error { _, err := Decode[map[string]int](b); return err }},
+		{"int_keys", `{"1":1}`, func(b []byte) error { _, err := Decode[map[int]int](b); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fn([]byte(tt.input)); err == nil {
+				t.Errorf("Expected error for %s", tt.input)
+			}
+		})
+	}
+}
+
+func TestCoerceNumbers(t *testing.T) {
+	type target struct {
+		I8  int8
+		I   int
+		U8  uint8
+		U   uint
+		F32 float32
+		Big int64
+	}
+	tests := []struct {
+		input    string
+		expected target
+	}{
+		{`{"I8":300,"I":1.9,"U8":-5,"U":2.5e1,"F32":1e39,"Big":1e30}`,
+			target{I8: 127, I: 1, U8: 0, U: 25, F32: math.MaxFloat32, Big: math.MaxInt64}},
+		{`{"I8":-300.5,"I":-1.9,"U8":256,"U":-0.5,"F32":-1e39,"Big":-92233720368547758070}`,
+			target{I8: -128, I: -1, U8: 255, U: 0, F32: -math.MaxFloat32, Big: math.MinInt64}},
+		{`{"Big":9007199254740993.5,"I":1e-5}`, target{Big: 9007199254740993}},
+		{`{"I8":12,"U8":200,"F32":1.5}`, target{I8: 12, U8: 200, F32: 1.5}},
+	}
+
+	p := NewParser(&Options{CopyStrings: true, CoerceNumbers: true})
+	for _, tt := range tests {
+		var got target
+		if err := p.Unmarshal([]byte(tt.input), &got); err != nil {
+			t.Errorf("%s: %v", tt.input, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%s: expected %+v, got %+v", tt.input, tt.expected, got)
+		}
+	}
+
+	var v interface{}
+	if err := p.Unmarshal([]byte(`1e400`), &v); err != nil || v != math.MaxFloat64 {
+		t.Errorf("Expected MaxFloat64, got %v (%v)", v, err)
+	}
+	var s []int64
+	if err := p.Unmarshal([]byte(`[1.5,-2.5]`), &s); err != nil || len(s) != 2 || s[0] != 1 || s[1] != -2 {
+		t.Errorf("Expected [1 -2], got %v (%v)", s, err)
+	}
+}
+
+func TestUnmarshalWithOptions(t *testing.T) {
+	var n struct {
+		Count int8   `json:"count"`
+		Name  string `json:"name"`
+	}
+	data := []byte(`{"count":300,"name":"abc"}`)
+	if err := UnmarshalWithOptions(data, &n, &Options{CoerceNumbers: true}); err != nil || n.Count != 127 {
+		t.Fatalf("Expected 300 clamped to 127, got %d, %v", n.Count, err)
+	}
+	if unsafe.StringData(n.Name) != &data[21] {
+		t.Error("Expected the name to alias the input without CopyStrings")
+	}
+
+	// The pooled decoder is back to the defaults for the next call
+	if err := Unmarshal(data, &n); err == nil {
+		t.Error("Expected Unmarshal not to coerce after UnmarshalWithOptions")
+	}
+	if err := UnmarshalWithOptions([]byte(`{"name":"abc"}`), &n, nil); err != nil || unsafe.StringData(n.Name) == &data[21] {
+		t.Errorf("Expected nil options to copy strings, got %v", err)
+	}
+
+	var v interface{}
+	bom := []byte("\xEF\xBB\xBF[1]")
+	if err := UnmarshalWithOptions(bom, &v, &Options{SkipBOM: true}); err != nil {
+		t.Errorf("Expected SkipBOM to skip the byte order mark, got %v", err)
+	}
+	if ValidWithOptions(bom, nil) || !ValidWithOptions(bom, &Options{SkipBOM: true}) {
+		t.Error("Expected ValidWithOptions to accept the byte order mark only with SkipBOM")
+	}
+}
+
+func TestDecodeRecursive(t *testing.T) {
+	got, err := Decode[decodeNode]([]byte(`{"name":"a","children":[{"name":"b","children":[{"name":"c"}]}]}`))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if got.Children[0].Children[0].Name != "c" {
+		t.Errorf("Expected nested child c, got %+v", got)
+	}
+}
+
+func BenchmarkDecodeStruct(b *testing.B) {
+	data := []byte(`{"id":12,"score":1.5,"tags":["a","b"],"attrs":{"x":1},"nested":{"name":"root"}}`)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Decode[decodeTarget](data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type float64s []float64
+
+func TestBulkSlices(t *testing.T) {
+	ints, err := Decode[[]int64]([]byte(`[1, -2, 9223372036854775807, null]`))
+	if err != nil || !reflect.DeepEqual(ints, []int64{1, -2, 9223372036854775807, 0}) {
+		t.Errorf("Unexpected []int64 %v (%v)", ints, err)
+	}
+
+	floats, err := Decode[float64s]([]byte(`[1.5,2e3,-0.25,7]`))
+	if err != nil || !reflect.DeepEqual(floats, float64s{1.5, 2000, -0.25, 7}) {
+		t.Errorf("Unexpected float64s %v (%v)", floats, err)
+	}
+
+	strs, err := Decode[[]string]([]byte(`["a","b\"c","é"]`))
+	if err != nil || !reflect.DeepEqual(strs, []string{"a", `b"c`, "é"}) {
+		t.Errorf("Unexpected []string %v (%v)", strs, err)
+	}
+
+	bools, err := Decode[[]bool]([]byte(`[true,false,true]`))
+	if err != nil || !reflect.DeepEqual(bools, []bool{true, false, true}) {
+		t.Errorf("Unexpected []bool %v (%v)", bools, err)
+	}
+
+	empty, err := Decode[[]int64]([]byte(`[]`))
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("Expected empty non-nil slice, got %#v (%v)", empty, err)
+	}
+
+	// The backing array of the destination is reused
+	dst := make([]int64, 0, 8)
+	backing := &dst[:1][0]
+	if err := DecodeInto([]byte(`[4,5,6]`), &dst); err != nil || &dst[0] != backing {
+		t.Errorf("Expected reuse of the backing array, got %v (%v)", dst, err)
+	}
+
+	var nested struct {
+		Series [][]float64 `json:"series"`
+	}
+	if err := Unmarshal([]byte(`{"series":[[1,2],[3]]}`), &nested); err != nil ||
+		!reflect.DeepEqual(nested.Series, [][]float64{{1, 2}, {3}}) {
+		t.Errorf("Unexpected nested series %v (%v)", nested.Series, err)
+	}
+
+	for _, bad := range []string{`[1,"x"]`, `[1.5]`, `[1,]`, `[1 2]`, `[1`, `{}`} {
+		if _, err := Decode[[]int64]([]byte(bad)); err == nil {
+			t.Errorf("Expected error for %s", bad)
+		}
+	}
+	if _, err := Decode[[]bool]([]byte(`[1]`)); err == nil {
+		t.Error("Expected error for number in []bool")
+	}
+}
+
+func BenchmarkDecodeFloat64s(b *testing.B) {
+	data := []byte("[")
+	for i := 0; i < 10000; i++ {
+		if i > 0 {
+			data = append(data, ',')
+		}
+		data = append(data, "0.123456789"...)
+	}
+	data = append(data, ']')
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	var dst []float64
+	for i := 0; i < b.N; i++ {
+		if err := DecodeInto(data, &dst); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type labels map[string]string
+
+func TestBulkMaps(t *testing.T) {
+	strs, err := Decode[map[string]string]([]byte(`{"a":"1","b\n":"x\"y","c":null}`))
+	if err != nil || !reflect.DeepEqual(strs, map[string]string{"a": "1", "b\n": `x"y`, "c": ""}) {
+		t.Errorf("Unexpected map[string]string %v (%v)", strs, err)
+	}
+
+	named, err := Decode[labels]([]byte(`{"env":"prod"}`))
+	if err != nil || named["env"] != "prod" {
+		t.Errorf("Unexpected labels %v (%v)", named, err)
+	}
+
+	// Existing entries are kept, as with encoding/json
+	dst := map[string]string{"keep": "me"}
+	if err := DecodeInto([]byte(`{"new":"v"}`), &dst); err != nil || len(dst) != 2 {
+		t.Errorf("Expected merged map, got %v (%v)", dst, err)
+	}
+
+	ifaces, err := Decode[map[string]interface{}]([]byte(`{"n":1,"f":1.5,"s":"x","a":[true,null],"o":{"k":"v"}}`))
+	want := map[string]interface{}{
+		"n": int64(1), "f": 1.5, "s": "x",
+		"a": []interface{}{true, nil},
+		"o": map[string]interface{}{"k": "v"},
+	}
+	if err != nil || !reflect.DeepEqual(ifaces, want) {
+		t.Errorf("Unexpected map[string]interface{} %v (%v)", ifaces, err)
+	}
+
+	var nested struct {
+		Tags map[string]string `json:"tags"`
+	}
+	if err := Unmarshal([]byte(`{"tags":{"k":"v"}}`), &nested); err != nil || nested.Tags["k"] != "v" {
+		t.Errorf("Unexpected nested tags %v (%v)", nested.Tags, err)
+	}
+
+	for _, bad := range []string{`{"a":1}`, `{"a" "b"}`, `{"a":"b",}`, `{"a":"b"`, `[]`} {
+		if _, err := Decode[map[string]string]([]byte(bad)); err == nil {
+			t.Errorf("Expected error for %s", bad)
+		}
+	}
+	if _, err := Decode[map[string]interface{}]([]byte(`{"a":}`)); err == nil {
+		t.Error("Expected error for missing value")
+	}
+}
+
+// withCodec registers a codec for T for the rest of the test, restoring
+// the previous codecs afterwards
+func withCodec[T any](t *testing.T, unmarshal func(r *Reader, v *T) error) {
+	old := codecs.Load()
+	t.Cleanup(func() {
+		codecs.Store(old)
+		decodePlans.Clear()
+	})
+	RegisterCodec(nil, unmarshal)
+}
+
+func TestBulkFallbacks(t *testing.T) {
+	t.Run("collect errors", func(t *testing.T) {
+		p := NewParser(&Options{CollectErrors: true})
+		var ints []int64
+		err := p.Unmarshal([]byte(`[1,"x",3,true]`), &ints)
+		var errs DecodeErrors
+		if !errors.As(err, &errs) || len(errs) != 2 || errs[0].Path != "/1" || errs[1].Path != "/3" {
+			t.Fatalf("Expected errors at /1 and /3, got %v", err)
+		}
+		if !reflect.DeepEqual(ints, []int64{1, 0, 3, 0}) {
+			t.Errorf("Expected [1 0 3 0], got %v", ints)
+		}
+
+		var m map[string]string
+		err = p.Unmarshal([]byte(`{"a":"x","b":2,"c":"z"}`), &m)
+		if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "/b" {
+			t.Fatalf("Expected an error at /b, got %v", err)
+		}
+		if !reflect.DeepEqual(m, map[string]string{"a": "x", "c": "z"}) {
+			t.Errorf("Expected the valid members decoded, got %v", m)
+		}
+	})
+
+	t.Run("codec", func(t *testing.T) {
+		withCodec(t, func(r *Reader, v *int64) error {
+			// Quoted numbers, as some APIs send 64-bit IDs
+			s, err := r.String()
+			if err != nil {
+				return err
+			}
+			*v, err = strconv.ParseInt(s, 10, 64)
+			return err
+		})
+		ints, err := Decode[[]int64]([]byte(`["1","-2"]`))
+		if err != nil || !reflect.DeepEqual(ints, []int64{1, -2}) {
+			t.Errorf("Expected the int64 codec used, got %v (%v)", ints, err)
+		}
+
+		withCodec(t, func(r *Reader, v *string) error {
+			s, err := r.String()
+			*v = strings.ToUpper(s)
+			return err
+		})
+		strs, err := Decode[[]string]([]byte(`["a","b"]`))
+		if err != nil || !reflect.DeepEqual(strs, []string{"A", "B"}) {
+			t.Errorf("Expected the string codec used, got %v (%v)", strs, err)
+		}
+		m, err := Decode[map[string]string]([]byte(`{"k":"v"}`))
+		if err != nil || m["k"] != "V" {
+			t.Errorf("Expected the string codec used for values, got %v (%v)", m, err)
+		}
+	})
+
+	// Back to the bulk decoders once the codecs are gone
+	if ints, err := Decode[[]int64]([]byte(`[1,2]`)); err != nil || !reflect.DeepEqual(ints, []int64{1, 2}) {
+		t.Errorf("Unexpected []int64 %v (%v)", ints, err)
+	}
+}
+
+func BenchmarkDecodeStringMap(b *testing.B) {
+	data := []byte(`{"host":"example.com","path":"/api/v1/users","method":"GET","status":"200",` +
+		`"agent":"Mozilla/5.0","referer":"https://example.com/","lang":"en-US","encoding":"gzip"}`)
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var m map[string]string
+		if err := Unmarshal(data, &m); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func mapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestParserInternKeys(t *testing.T) {
+	data := []byte(`[{"id":1,"name":"a"},{"id":2,"name":"b"},{"id":3,"name":"c"}]`)
+
+	p := NewParser(&Options{InternKeys: true})
+	var records []map[string]interface{}
+	if err := p.Unmarshal(data, &records); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[2]["name"] != "c" {
+		t.Fatalf("Unexpected records %v", records)
+	}
+
+	// Every occurrence of a key shares the first one's bytes
+	first := map[string]*byte{}
+	for _, rec := range records {
+		for _, k := range mapKeys(rec) {
+			if p, ok := first[k]; !ok {
+				first[k] = unsafe.StringData(k)
+			} else if p != unsafe.StringData(k) {
+				t.Errorf("Expected key %q to be interned", k)
+			}
+		}
+	}
+
+	// Interned keys are copies, so the input can be reused
+	for i := range data {
+		data[i] = ' '
+	}
+	if _, ok := records[0]["id"]; !ok {
+		t.Errorf("Expected key to survive input reuse, got %v", mapKeys(records[0]))
+	}
+
+	// The table carries over to the next document
+	var next map[string]string
+	if err := p.Unmarshal([]byte(`{"name":"d"}`), &next); err != nil {
+		t.Fatal(err)
+	}
+	for k := range next {
+		if unsafe.StringData(k) != first["name"] {
+			t.Error("Expected key to be interned across documents")
+		}
+	}
+}
+
+func TestParserReset(t *testing.T) {
+	p := NewParser(&Options{InternKeys: true})
+	var first, second map[string]int
+	if err := p.Unmarshal([]byte(`{"alpha":1,"beta":2}`), &first); err != nil {
+		t.Fatal(err)
+	}
+	p.Reset()
+	if len(p.d.reader.keys.m) != 0 {
+		t.Errorf("Expected Reset to empty the intern table, got %d keys", len(p.d.reader.keys.m))
+	}
+	if err := p.Unmarshal([]byte(`{"alpha":3}`), &second); err != nil || second["alpha"] != 3 {
+		t.Fatalf("Expected alpha:3 after Reset, got %v, %v", second, err)
+	}
+	for k := range second {
+		for k0 := range first {
+			if k == k0 && unsafe.StringData(k) == unsafe.StringData(k0) {
+				t.Error("Expected keys from before Reset not to be reused")
+			}
+		}
+	}
+
+	// A Parser without interning resets too
+	NewParser(nil).Reset()
+}
+
+func TestDecoderReset(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`{"name":"first"}`))
+	var n decodeNode
+	if err := dec.Decode(&n); err != nil || n.Name != "first" {
+		t.Fatalf("Expected first, got %q, %v", n.Name, err)
+	}
+	buf := unsafe.SliceData(dec.buf)
+
+	dec.Reset(strings.NewReader(`{"name":"second"}`))
+	if err := dec.Decode(&n); err != nil || n.Name != "second" {
+		t.Fatalf("Expected second, got %q, %v", n.Name, err)
+	}
+	if unsafe.SliceData(dec.buf) != buf {
+		t.Error("Expected Reset to keep the buffer")
+	}
+
+	// Inputs beyond the buffer grow it
+	large := `{"name":"` + strings.Repeat("x", 10000) + `"}`
+	dec.Reset(strings.NewReader(large))
+	if err := dec.Decode(&n); err != nil || len(n.Name) != 10000 {
+		t.Fatalf("Expected a 10000 byte name, got %d bytes, %v", len(n.Name), err)
+	}
+	if err := dec.Decode(&n); err == nil {
+		t.Error("Expected an error once the input is exhausted")
+	}
+}
+
+func TestInternTableBounds(t *testing.T) {
+	tab := newInternTable()
+	long := strings.Repeat("k", maxInternedKeyLen+1)
+	if tab.intern([]byte(long)) != long || len(tab.m) != 0 {
+		t.Errorf("Expected long key not to be interned, table has %d entries", len(tab.m))
+	}
+	for i := 0; i < maxInternedKeys+10; i++ {
+		tab.intern([]byte(strconv.Itoa(i)))
+	}
+	if len(tab.m) != maxInternedKeys {
+		t.Errorf("Expected %d entries, got %d", maxInternedKeys, len(tab.m))
+	}
+}
+
+func BenchmarkParserInternKeys(b *testing.B) {
+	var buf strings.Builder
+	buf.WriteByte('[')
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`{"id":` + strconv.Itoa(i) + `,"name":"user","timestamp":1700000000}`)
+	}
+	buf.WriteByte(']')
+	data := []byte(buf.String())
+
+	for _, intern := range []bool{false, true} {
+		b.Run("intern="+strconv.FormatBool(intern), func(b *testing.B) {
+			p := NewParser(&Options{InternKeys: intern})
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var v []map[string]interface{}
+				if err := p.Unmarshal(data, &v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestParserParse(t *testing.T) {
+	data := []byte(`{"name":"Alice","tags":["a","bé",["x"]],"n":42,"f":1.5,"ok":true,"nil":null,"obj":{"k":"v"}}`)
+	want := map[string]interface{}{
+		"name": "Alice",
+		"tags": []interface{}{"a", "bé", []interface{}{"x"}},
+		"n":    int64(42),
+		"f":    1.5,
+		"ok":   true,
+		"nil":  nil,
+		"obj":  map[string]interface{}{"k": "v"},
+	}
+
+	p := NewParser(nil)
+	res, err := p.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.Value(), want) {
+		t.Fatalf("Expected %v, got %v", want, res.Value())
+	}
+
+	// A Result does not alias the input
+	copy(data, bytes.Repeat([]byte{' '}, len(data)))
+	if !reflect.DeepEqual(res.Value(), want) {
+		t.Errorf("Expected result to survive input reuse, got %v", res.Value())
+	}
+
+	// Appending to an arena slice must not clobber its neighbours
+	tags := res.Value().(map[string]interface{})["tags"].([]interface{})
+	inner := tags[2].([]interface{})
+	_ = append(inner, "clobbered")
+	if tags[0] != "a" {
+		t.Errorf("Expected append to reallocate, got %v", tags)
+	}
+
+	res.Release()
+	res.Release()
+	if res.Value() != nil {
+		t.Error("Expected nil value after Release")
+	}
+
+	// Recycled arenas produce fresh, correct trees
+	for i := 0; i < 3; i++ {
+		res, err := p.Parse([]byte(`[{"id":1},{"id":2}]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []interface{}{map[string]interface{}{"id": int64(1)}, map[string]interface{}{"id": int64(2)}}
+		if !reflect.DeepEqual(res.Value(), want) {
+			t.Errorf("Expected %v, got %v", want, res.Value())
+		}
+		res.Release()
+	}
+
+	for _, bad := range []string{`[1,`, `{"a"}`, `[1] 2`, ``} {
+		if _, err := p.Parse([]byte(bad)); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestArenaLargeValues(t *testing.T) {
+	long := strings.Repeat("x", arenaStringSlab)
+	var buf strings.Builder
+	buf.WriteString(`["` + long + `",[`)
+	for i := 0; i < arenaValueSlab; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(strconv.Itoa(i))
+	}
+	buf.WriteString(`]]`)
+
+	res, err := NewParser(nil).Parse([]byte(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Release()
+	root := res.Value().([]interface{})
+	if root[0] != long {
+		t.Error("Expected long string to round-trip")
+	}
+	if nums := root[1].([]interface{}); len(nums) != arenaValueSlab || nums[arenaValueSlab-1] != int64(arenaValueSlab-1) {
+		t.Errorf("Unexpected large array of length %d", len(nums))
+	}
+}
+
+func BenchmarkParserParse(b *testing.B) {
+	var buf strings.Builder
+	buf.WriteByte('[')
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`{"id":` + strconv.Itoa(i) + `,"name":"user","tags":["a","b"]}`)
+	}
+	buf.WriteByte(']')
+	data := []byte(buf.String())
+
+	b.Run("unmarshal", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var v interface{}
+			if err := Unmarshal(data, &v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("arena", func(b *testing.B) {
+		p := NewParser(nil)
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			res, err := p.Parse(data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			res.Release()
+		}
+	})
+}
+
+func TestCopyStrings(t *testing.T) {
+	// The package-level API never aliases the input
+	data := []byte(`{"key":"value"}`)
+	var m map[string]string
+	if err := Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	copy(data, bytes.Repeat([]byte{'x'}, len(data)))
+	if m["key"] != "value" {
+		t.Errorf("Expected copied strings, got %v", m)
+	}
+
+	// Zero-copy mode points into the input
+	data = []byte(`{"key":"value","esc":"a\nb"}`)
+	inData := func(s string) bool {
+		p := uintptr(unsafe.Pointer(unsafe.StringData(s)))
+		start := uintptr(unsafe.Pointer(&data[0]))
+		return p >= start && p < start+uintptr(len(data))
+	}
+	zc := NewParser(&Options{CopyStrings: false})
+	m = nil
+	if err := zc.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range m {
+		if k == "key" && (!inData(k) || !inData(v)) {
+			t.Error("Expected unescaped key and value to alias the input")
+		}
+		if k == "esc" && (!inData(k) || inData(v) || v != "a\nb") {
+			t.Errorf("Expected escaped value to be a copy, got %q", v)
+		}
+	}
+
+	res, err := zc.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.Root().Get("key").Interface().(string); !inData(s) {
+		t.Error("Expected Parse string to alias the input in zero-copy mode")
+	}
+
+	// A detached value survives both input reuse and Release
+	kept := res.Root().Detach()
+	res.Release()
+	copy(data, bytes.Repeat([]byte{'x'}, len(data)))
+	if _, err := NewParser(nil).Parse([]byte(`{"other":[1,2,3]}`)); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"key": "value", "esc": "a\nb"}
+	if !reflect.DeepEqual(kept, want) {
+		t.Errorf("Expected detached %v, got %v", want, kept)
+	}
+}
+
+func TestValue(t *testing.T) {
+	res, err := NewParser(nil).Parse([]byte(`{"users":[{"name":"a","age":30,"score":1.5,"ok":true}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := res.Root().Get("users").Index(0)
+	if user.Get("name").Interface() != "a" || user.Get("age").Interface() != int64(30) {
+		t.Errorf("Unexpected user %v", user.Interface())
+	}
+	if res.Root().Get("missing").Interface() != nil || res.Root().Get("users").Index(5).Interface() != nil ||
+		user.Get("name").Index(0).Interface() != nil {
+		t.Error("Expected null Values for missing nodes")
+	}
+
+	fields := map[string]interface{}{}
+	for k, v := range user.Fields() {
+		fields[k] = v.Interface()
+	}
+	if !reflect.DeepEqual(fields, user.Interface()) {
+		t.Errorf("Expected Fields to yield %v, got %v", user.Interface(), fields)
+	}
+	var indexes []int
+	for i, v := range res.Root().Get("users").Values() {
+		if v.Get("name").Interface() != "a" {
+			t.Errorf("Unexpected element %d: %v", i, v.Interface())
+		}
+		indexes = append(indexes, i)
+	}
+	if !reflect.DeepEqual(indexes, []int{0}) {
+		t.Errorf("Expected Values to yield index 0, got %v", indexes)
+	}
+	for range user.Values() {
+		t.Error("Expected Values of an object to yield nothing")
+	}
+	for range user.Get("name").Fields() {
+		t.Error("Expected Fields of a string to yield nothing")
+	}
+	for range user.Fields() {
+		break
+	}
+
+	kept := user.Detach()
+	res.Release()
+
+	// Reuse the recycled arena so stale slab memory would show
+	for i := 0; i < 3; i++ {
+		r, err := NewParser(nil).Parse([]byte(`[{"name":"zzz","age":99,"score":9.5,"ok":false}]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Release()
+	}
+	want := map[string]interface{}{"name": "a", "age": int64(30), "score": 1.5, "ok": true}
+	if !reflect.DeepEqual(kept, want) {
+		t.Errorf("Expected detached %v, got %v", want, kept)
+	}
+}
+
+func TestFieldMatcher(t *testing.T) {
+	fields := func(names ...string) []fieldInfo {
+		f := make([]fieldInfo, len(names))
+		for i, n := range names {
+			f[i] = fieldInfo{name: n, index: i}
+		}
+		return f
+	}
+
+	tests := []struct {
+		name    string
+		fields  []string
+		perfect bool
+	}{
+		{"single", []string{"id"}, true},
+		{"typical", []string{"id", "name", "email", "created_at", "updated_at", "tags", "active", ""}, true},
+		{"inseparable", []string{"abc", "axc"}, false},
+		{"duplicate", []string{"id", "id"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newFieldMatcher(fields(tt.fields...))
+			if (m.slots != nil) != tt.perfect {
+				t.Errorf("Expected perfect hash %v, got %v", tt.perfect, m.slots != nil)
+			}
+			for i, name := range tt.fields {
+				want := i
+				if tt.name == "duplicate" {
+					want = 0
+				}
+				if got := m.match([]byte(name)); got != want {
+					t.Errorf("Expected %q at %d, got %d", name, want, got)
+				}
+				if got := m.match([]byte(strings.ToUpper(name))); got != want {
+					t.Errorf("Expected case-insensitive %q at %d, got %d", name, want, got)
+				}
+			}
+			if got := m.match([]byte("unknown")); got != -1 {
+				t.Errorf("Expected -1 for unknown key, got %d", got)
+			}
+		})
+	}
+
+	many := make([]string, 200)
+	for i := range many {
+		many[i] = "field" + strconv.Itoa(i)
+	}
+	m := newFieldMatcher(fields(many...))
+	for i, name := range many {
+		if got := m.match([]byte(name)); got != i {
+			t.Errorf("Expected %q at %d, got %d", name, i, got)
+		}
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		m.match([]byte("field123"))
+		m.match([]byte("a-key-that-is-longer-than-thirty-two-bytes"))
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
+
+func TestStructKeyMatching(t *testing.T) {
+	type record struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Email string
+	}
+	got, err := Decode[record]([]byte(`{"name":"a","ID":7,"email":"e","extra":{"id":9}}`))
+	want := record{ID: 7, Name: "a", Email: "e"}
+	if err != nil || got != want {
+		t.Errorf("Expected %+v, got %+v (%v)", want, got, err)
+	}
+}
+
+func TestUnknownMembers(t *testing.T) {
+	type inner struct {
+		A int `json:"a"`
+	}
+	type open struct {
+		ID    int                   `json:"id"`
+		Inner inner                 `json:"inner"`
+		Rest  map[string]RawMessage `json:",unknown"`
+	}
+	input := `{"id":1,"x":[1, {"y":null}],"inner":{"a":2,"b":3},"name":"né","id2":true}`
+
+	var v open
+	if err := Unmarshal([]byte(input), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.ID != 1 || v.Inner.A != 2 {
+		t.Errorf("Expected known fields decoded, got %+v", v)
+	}
+	expected := map[string]string{"x": `[1, {"y":null}]`, "name": `"né"`, "id2": "true"}
+	if len(v.Rest) != len(expected) {
+		t.Errorf("Expected %d unknown members, got %d", len(expected), len(v.Rest))
+	}
+	for k, want := range expected {
+		if got := string(v.Rest[k]); got != want {
+			t.Errorf("%s: expected %s, got %s", k, want, got)
+		}
+	}
+
+	// Members follow the fields, sorted, and never repeat a field
+	v.Rest["id"] = RawMessage(`99`)
+	out, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":1,"inner":{"a":2},"id2":true,"name":"né","x":[1, {"y":null}]}`
+	if string(out) != want {
+		t.Errorf("Expected %s, got %s", want, out)
+	}
+	if !Equal(out, []byte(`{"id":1,"x":[1,{"y":null}],"inner":{"a":2},"name":"né","id2":true}`)) {
+		t.Errorf("Round trip changed the document: %s", out)
+	}
+
+	// Any string-keyed map works, its values decoded as usual
+	var loose struct {
+		Name string                 `json:"name"`
+		More map[string]interface{} `json:"more,unknown"`
+	}
+	if err := Unmarshal([]byte(`{"name":"a","n":1.5,"ok":true}`), &loose); err != nil {
+		t.Fatal(err)
+	}
+	if loose.Name != "a" || loose.More["n"] != 1.5 || loose.More["ok"] != true || len(loose.More) != 2 {
+		t.Errorf("Expected name and two unknown members, got %+v", loose)
+	}
+
+	// Without unknown members the map stays nil and writes nothing
+	var none open
+	if err := Unmarshal([]byte(`{"id":3}`), &none); err != nil {
+		t.Fatal(err)
+	}
+	if none.Rest != nil {
+		t.Errorf("Expected nil map, got %v", none.Rest)
+	}
+	if out, _ := Marshal(none); string(out) != `{"id":3,"inner":{"a":0}}` {
+		t.Errorf("Expected no unknown members, got %s", out)
+	}
+}
+
+func TestRawMessage(t *testing.T) {
+	var v struct {
+		Raw  RawMessage
+		Null RawMessage
+		Nil  RawMessage
+	}
+	if err := Unmarshal([]byte(`{"Raw":{"a": [1,2]},"Null":null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if string(v.Raw) != `{"a": [1,2]}` || string(v.Null) != "null" || v.Nil != nil {
+		t.Errorf("Expected raw values, got %q %q %q", v.Raw, v.Null, v.Nil)
+	}
+
+	out, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Raw":{"a": [1,2]},"Null":null,"Nil":null}`; string(out) != want {
+		t.Errorf("Expected %s, got %s", want, out)
+	}
+	if _, err := Marshal(RawMessage(`{"a":`)); err == nil {
+		t.Error("Expected error for invalid RawMessage")
+	}
+}
+
+func TestRequiredFields(t *testing.T) {
+	type address struct {
+		City string `json:"city,required"`
+	}
+	type user struct {
+		ID      int      `json:"id,required"`
+		Name    string   `json:"name,omitempty,required"`
+		Email   string   `json:"email"`
+		Address *address `json:"address"`
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string // error, "" for none
+	}{
+		{"all_present", `{"id":1,"name":"a"}`, ""},
+		{"null_counts", `{"id":null,"name":null}`, ""},
+		{"case_insensitive", `{"ID":1,"Name":"a"}`, ""},
+		{"one_missing", `{"id":1,"email":"x"}`, `missing required field "name" in user`},
+		{"all_missing", `{}`, `missing required fields "id", "name" in user`},
+		{"nested", `{"id":1,"name":"a","address":{}}`, `missing required field "city" in address`},
+		{"null_object", `null`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var u user
+			err := Unmarshal([]byte(tt.input), &u)
+			switch {
+			case tt.expected == "" && err != nil:
+				t.Errorf("Expected no error, got %v", err)
+			case tt.expected != "" && (err == nil || err.Error() != tt.expected):
+				t.Errorf("Expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	var missing *MissingFieldsError
+	_, err := Decode[[]user]([]byte(`[{"id":1,"name":"a"},{"id":2}]`))
+	if !errors.As(err, &missing) || len(missing.Fields) != 1 || missing.Fields[0] != "name" {
+		t.Errorf("Expected MissingFieldsError for name, got %v", err)
+	}
+
+	// More required fields than fit in one word of bits
+	fields := make([]reflect.StructField, 70)
+	for i := range fields {
+		fields[i] = reflect.StructField{
+			Name: "F" + strconv.Itoa(i),
+			Type: reflect.TypeOf(0),
+			Tag:  reflect.StructTag(`json:"f` + strconv.Itoa(i) + `,required"`),
+		}
+	}
+	big := reflect.New(reflect.StructOf(fields)).Interface()
+	var doc strings.Builder
+	doc.WriteString("{")
+	for i := 0; i < 70; i++ {
+		if i == 3 || i == 66 {
+			continue
+		}
+		if doc.Len() > 1 {
+			doc.WriteString(",")
+		}
+		doc.WriteString(`"f` + strconv.Itoa(i) + `":1`)
+	}
+	doc.WriteString("}")
+	err = Unmarshal([]byte(doc.String()), big)
+	if !errors.As(err, &missing) || strings.Join(missing.Fields, " ") != "f3 f66" {
+		t.Errorf("Expected f3 and f66 missing, got %v", err)
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+	type item struct {
+		SKU string `json:"sku,required"`
+		Qty uint8  `json:"qty"`
+	}
+	type order struct {
+		ID     int             `json:"id,required"`
+		Email  string          `json:"email,required"`
+		Items  []item          `json:"items"`
+		Counts map[string]int  `json:"counts"`
+		Pair   [2]bool         `json:"pair"`
+		Note   string          `json:"note"`
+		Extra  map[string]item `json:",unknown"`
+	}
+	input := `{"id":"x","items":[{"sku":"a","qty":300},{"qty":1},{"sku":"c","qty":2}],` +
+		`"counts":{"a/b":1.5,"ok":2},"pair":[true,1],"note":"kept","other":{"qty":-1}}`
+
+	var o order
+	p := NewParser(&Options{CopyStrings: true, CollectErrors: true})
+	err := p.Unmarshal([]byte(input), &o)
+	var errs DecodeErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected DecodeErrors, got %v", err)
+	}
+
+	expected := []string{
+		`/id: cannot unmarshal string into int`,
+		`/items/0/qty: cannot unmarshal number 300 into uint8`,
+		`/items/1/sku: missing required field "sku" in item`,
+		`/counts/a~1b: cannot unmarshal number 1.5 into int`,
+		`/pair/1: cannot unmarshal number into bool`,
+		`/other/qty: cannot unmarshal number -1 into uint8`,
+		`/other/sku: missing required field "sku" in item`,
+		`/email: missing required field "email" in order`,
+	}
+	if len(errs) != len(expected) {
+		t.Errorf("Expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+	for i := 0; i < len(errs) && i < len(expected); i++ {
+		if got := errs[i].Error(); got != expected[i] {
+			t.Errorf("Error %d: expected %s, got %s", i, expected[i], got)
+		}
+	}
+
+	// Everything else is decoded
+	if o.Note != "kept" || len(o.Items) != 3 || o.Items[2].SKU != "c" || o.Counts["ok"] != 2 || !o.Pair[0] {
+		t.Errorf("Expected the valid values decoded, got %+v", o)
+	}
+	var typeErr *UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Value != "string" {
+		t.Errorf("Expected errors.As to find the first UnmarshalTypeError, got %v", typeErr)
+	}
+
+	// Syntax errors still stop decoding, and without the option the
+	// first error is returned
+	if err := p.Unmarshal([]byte(`{"id":"x","items":[1 2]}`), &o); errors.As(err, &errs) {
+		t.Errorf("Expected a syntax error, got %v", err)
+	}
+	if err := Unmarshal([]byte(input), &o); !errors.As(err, &typeErr) || errors.As(err, &errs) {
+		t.Errorf("Expected the first UnmarshalTypeError alone, got %v", err)
+	}
+	if err := p.Unmarshal([]byte(`{"id":1,"email":"e"}`), &o); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package simdjson
+
+import (
+	"errors"
+	"strconv"
+)
+
+// A FieldError is an error in the value at Path, a JSON Pointer (RFC 6901)
+// relative to the document decoded.
+type FieldError struct {
+	Path string
+	Err  error // an *UnmarshalTypeError or *MissingFieldsError
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeErrors lists, in document order, every value that could not be
+// decoded with Options.CollectErrors. The rest of the document is decoded
+// as usual.
+type DecodeErrors []*FieldError
+
+func (e DecodeErrors) Error() string {
+	msg := strconv.Itoa(len(e)) + " decode errors: "
+	if len(e) == 1 {
+		msg = ""
+	}
+	for i, fe := range e {
+		if i > 0 {
+			msg += "; "
+		}
+		msg += fe.Error()
+	}
+	return msg
+}
+
+func (e DecodeErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fe := range e {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// collect handles the error a plan returned for the member key or element
+// at start: with Options.CollectErrors, errors about the value rather than
+// the syntax are added to errs under key, the value skipped and nil
+// returned. Any other error is returned as is.
+func (r *Reader) collect(err error, errs *DecodeErrors, start int, key string) error {
+	if !r.allErrs {
+		return err
+	}
+	path := "/" + pointerEscaper.Replace(key)
+
+	var nested DecodeErrors
+	var typeErr *UnmarshalTypeError
+	var missing *MissingFieldsError
+	switch {
+	case errors.As(err, &nested):
+		for _, fe := range nested {
+			*errs = append(*errs, &FieldError{Path: path + fe.Path, Err: fe.Err})
+		}
+	case errors.As(err, &typeErr):
+		*errs = append(*errs, &FieldError{Path: path, Err: typeErr})
+	case errors.As(err, &missing):
+		*errs = append(*errs, missingErrors(path, missing)...)
+	default:
+		return err
+	}
+
+	r.err = nil
+	r.pos = start
+	return r.Skip()
+}
+
+// missingErrors returns a FieldError for each field of m, found in the
+// object at path.
+func missingErrors(path string, m *MissingFieldsError) DecodeErrors {
+	errs := make(DecodeErrors, len(m.Fields))
+	for i, f := range m.Fields {
+		errs[i] = &FieldError{
+			Path: path + "/" + pointerEscaper.Replace(f),
+			Err:  &MissingFieldsError{Struct: m.Struct, Fields: []string{f}},
+		}
+	}
+	return errs
+}
@@ -1,40 +1,54 @@
 package simdjson
 
 import (
+	"context"
 	"errors"
+	"math/bits"
 	"reflect"
+	"strconv"
 	"sync"
-	
+	"sync/atomic"
+	"unsafe"
+
 	"github.com/biggeezerdevelopment/simdjson-go/internal/parser"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/pool"
 	internalScanner "github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
 )
 
 type decoder struct {
-	parser  *parser.Parser
 	scanner *internalScanner.Scanner
 	data    []byte
-}
+	reader  Reader
 
-var decoderPool = sync.Pool{
-	New: func() interface{} {
-		return &decoder{
-			parser:  parser.New(),
-			scanner: internalScanner.New(),
-		}
-	},
+	// Input encodings accepted besides plain UTF-8; see Options
+	skipBOM bool
+	utf16   bool
 }
 
+// decoderPool holds idle decoders without their scanners: a decoder takes
+// a scanner from the scanner pool when checked out and returns it when
+// released, so a scanner is only ever owned by one decoder in use, and a
+// decoder dropped by the pool takes no scanner with it.
+var decoderPool = pool.New("decoder", func() *decoder {
+	return new(decoder)
+}, func(d *decoder) int {
+	return cap(d.reader.scratch) + 16*cap(d.reader.stack)
+})
+
 func newDecoder(data []byte) *decoder {
-	d := decoderPool.Get().(*decoder)
+	d := decoderPool.Get()
+	d.scanner = internalScanner.New()
 	d.data = data
 	return d
 }
 
 func (d *decoder) release() {
 	d.data = nil
-	if d.scanner != nil {
-		d.scanner.Release()
-	}
+	d.setContext(nil)
+	d.setOptions(&defaultOptions)
+	d.reader.reset(nil, nil)
+	d.scanner.Release()
+	d.scanner = nil
 	decoderPool.Put(d)
 }
 
@@ -43,244 +57,942 @@ func (d *decoder) unmarshal(v interface{}) error {
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return errors.New("unmarshal requires non-nil pointer")
 	}
-	
-	// Parse JSON into intermediate representation
-	parsed, err := d.parser.Parse(d.data)
-	if err != nil {
-		return err
+
+	return d.run(planFor(rv.Type().Elem()), rv.Elem())
+}
+
+// setOptions configures d as o describes. An intern table is only made
+// for InternKeys; the other options are plain fields, so that passing
+// Options to a call does not allocate.
+func (d *decoder) setOptions(o *Options) {
+	d.reader.keys = nil
+	if o.InternKeys {
+		d.reader.keys = newInternTable()
 	}
-	
-	// Decode into target value
-	return d.decode(parsed, rv.Elem())
+	d.reader.zeroCopy = !o.CopyStrings
+	d.skipBOM = o.SkipBOM
+	d.utf16 = o.UTF16
+	d.reader.bytesFmt = o.BytesFormat
+	d.reader.coerce = o.CoerceNumbers
+	d.reader.naming = o.FieldNaming
+	d.reader.allErrs = o.CollectErrors
+	d.reader.dups = o.CollectDuplicateKeys
+	d.reader.ordered = o.PreserveKeyOrder
+	d.reader.timeFmt = o.TimeFormat
+	d.reader.timeLoc = o.TimeLocation
+	d.reader.allowNaN = o.AllowNonFinite
+	d.scanner.NonFinite = o.AllowNonFinite
+	d.scanner.Limits = internalScanner.Limits(o.Limits)
 }
 
-func (d *decoder) decode(src interface{}, dst reflect.Value) error {
-	if src == nil {
-		dst.Set(reflect.Zero(dst.Type()))
-		return nil
+// setContext makes run give up with the error of ctx once it is canceled,
+// checking while tokenizing and decoding. A nil ctx, or one that can never
+// be canceled, is not checked.
+func (d *decoder) setContext(ctx context.Context) {
+	if ctx != nil && ctx.Done() == nil {
+		ctx = nil
+	}
+	d.reader.ctx = ctx
+	d.scanner.Cancel = nil
+	if ctx != nil {
+		d.scanner.Cancel = ctx.Err
 	}
-	
-	// Handle pointer types
-	if dst.Kind() == reflect.Ptr {
-		if dst.IsNil() {
-			dst.Set(reflect.New(dst.Type().Elem()))
+}
+
+// run tokenizes the document and decodes it into dst with plan.
+func (d *decoder) run(plan decodeFunc, dst reflect.Value) error {
+	if ctx := d.reader.ctx; ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
-		return d.decode(src, dst.Elem())
 	}
-	
-	// Handle interface{} type
-	if dst.Kind() == reflect.Interface && dst.Type().NumMethod() == 0 {
-		dst.Set(reflect.ValueOf(src))
-		return nil
+	data := d.data
+	if d.skipBOM || d.utf16 {
+		var err error
+		if data, err = decodeText(data, d.skipBOM, d.utf16); err != nil {
+			return err
+		}
 	}
-	
-	switch v := src.(type) {
-	case bool:
-		return d.decodeBool(v, dst)
-	case float64:
-		return d.decodeNumber(v, dst)
-	case int64:
-		return d.decodeInt(v, dst)
-	case string:
-		return d.decodeString(v, dst)
-	case []interface{}:
-		return d.decodeArray(v, dst)
-	case map[string]interface{}:
-		return d.decodeObject(v, dst)
-	default:
-		return errors.New("unexpected value type")
+
+	if metricsOn.Load() {
+		metricDocuments.Add(1)
+		metricBytes.Add(uint64(len(data)))
 	}
+	d.scanner.Progress = progressFor(len(data))
+	tokens, err := d.scanner.SimpleTokenize(data)
+	if err != nil {
+		if le, ok := err.(*internalScanner.LimitError); ok {
+			return (*LimitError)(le)
+		}
+		if enc := detectEncoding(data); enc != encodingUTF8 {
+			// Explain the failure rather than report a stray byte
+			_, err = decodeText(data, false, false)
+		}
+		return err
+	}
+	defer internalScanner.PutTokenSlice(tokens)
+
+	r := &d.reader
+	r.reset(data, tokens)
+
+	if err := plan(r, dst); err != nil {
+		return err
+	}
+	if r.pos != len(tokens) {
+		return errTrailingData
+	}
+	return nil
 }
 
-func (d *decoder) decodeBool(src bool, dst reflect.Value) error {
-	switch dst.Kind() {
+// unmarshalFrom decodes the document with a generated decoder, which reads
+// the tokens directly without reflection.
+func (d *decoder) unmarshalFrom(u UnmarshalerFrom) error {
+	return d.run(func(r *Reader, _ reflect.Value) error {
+		return u.UnmarshalJSONFrom(r)
+	}, reflect.Value{})
+}
+
+// decodeFunc reads the next value from r into v, which is addressable.
+type decodeFunc func(r *Reader, v reflect.Value) error
+
+// decodePlans caches the decodeFunc of every type decoded so far, so the
+// reflection needed to build a plan is paid once per type.
+var decodePlans sync.Map // map[reflect.Type]decodeFunc
+
+// plansGen counts the resets of decodePlans. plansMu orders them against
+// the caching of new plans, so a plan built across a reset is not kept.
+var (
+	plansMu  sync.RWMutex
+	plansGen uint64
+)
+
+// resetPlans discards the cached decode plans, for registrations that
+// change how types decode.
+func resetPlans() {
+	plansMu.Lock()
+	defer plansMu.Unlock()
+	plansGen++
+	decodePlans.Clear()
+}
+
+var unmarshalerFromType = reflect.TypeOf((*UnmarshalerFrom)(nil)).Elem()
+
+// planFor returns the cached decode plan for t, building it on first use.
+func planFor(t reflect.Type) decodeFunc {
+	if f, ok := decodePlans.Load(t); ok {
+		return f.(decodeFunc)
+	}
+
+	// Recursive types reach planFor again while their plan is being built;
+	// publish an indirect plan first so they find it instead of looping.
+	var (
+		wg   sync.WaitGroup
+		plan decodeFunc
+	)
+	plansMu.RLock()
+	gen := plansGen
+	plansMu.RUnlock()
+	wg.Add(1)
+	f, loaded := decodePlans.LoadOrStore(t, decodeFunc(func(r *Reader, v reflect.Value) error {
+		wg.Wait()
+		return plan(r, v)
+	}))
+	if loaded {
+		return f.(decodeFunc)
+	}
+
+	plan = newPlan(t)
+	wg.Done()
+	plansMu.RLock()
+	defer plansMu.RUnlock()
+	if plansGen != gen {
+		// A codec or union registered meanwhile may be missing from plan
+		// or the plans it was built from; plan t again next time
+		decodePlans.Delete(t)
+		return plan
+	}
+	decodePlans.Store(t, plan)
+	return plan
+}
+
+func newPlan(t reflect.Type) decodeFunc {
+	if c := codecFor(t); c != nil && c.unmarshal != nil {
+		return c.unmarshal
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerFromType) {
+		return func(r *Reader, v reflect.Value) error {
+			return v.Addr().Interface().(UnmarshalerFrom).UnmarshalJSONFrom(r)
+		}
+	}
+
+	switch t.Kind() {
 	case reflect.Bool:
-		dst.SetBool(src)
-		return nil
+		return decodeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := t.Bits()
+		return func(r *Reader, v reflect.Value) error {
+			if r.Null() {
+				return nil
+			}
+			if r.peek() != internalScanner.TokenNumber {
+				return r.typeError(v.Type())
+			}
+			s, err := r.number()
+			if err != nil {
+				return err
+			}
+			n, err := r.parseInt(s, bits, v.Type())
+			if err != nil {
+				return err
+			}
+			v.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		bits := t.Bits()
+		return func(r *Reader, v reflect.Value) error {
+			if r.Null() {
+				return nil
+			}
+			if r.peek() != internalScanner.TokenNumber {
+				return r.typeError(v.Type())
+			}
+			s, err := r.number()
+			if err != nil {
+				return err
+			}
+			n, err := r.parseUint(s, bits, v.Type())
+			if err != nil {
+				return err
+			}
+			v.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		bits := t.Bits()
+		return func(r *Reader, v reflect.Value) error {
+			if r.Null() {
+				return nil
+			}
+			if f, ok := r.nonFiniteString(); ok {
+				v.SetFloat(f)
+				return nil
+			}
+			if r.peek() != internalScanner.TokenNumber {
+				return r.typeError(v.Type())
+			}
+			s, err := r.number()
+			if err != nil {
+				return err
+			}
+			f, err := r.parseFloat(s, bits, v.Type())
+			if err != nil {
+				return err
+			}
+			v.SetFloat(f)
+			return nil
+		}
+	case reflect.String:
+		return decodeString
 	case reflect.Interface:
-		if dst.Type().NumMethod() == 0 {
-			dst.Set(reflect.ValueOf(src))
+		if t.NumMethod() == 0 {
+			return decodeInterface
+		}
+		if kinds := unionFor(t); kinds != nil {
+			return newUnionPlan(t, kinds, defaultDiscriminator)
+		}
+		return func(r *Reader, v reflect.Value) error {
+			if ok, err := decodeHeldPointer(r, v); ok {
+				return err
+			}
+			if r.Null() {
+				v.Set(reflect.Zero(t))
+				return nil
+			}
+			return errors.New("cannot unmarshal into " + t.String())
+		}
+	case reflect.Ptr:
+		return newPtrPlan(t)
+	case reflect.Slice:
+		if t == rawMessageType {
+			return decodeRaw
+		}
+		if t.Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(t.Elem()).Implements(unmarshalerFromType) {
+			return decodeBytes
+		}
+		if plan := bulkPlan(t); plan != nil {
+			return plan
+		}
+		return newSlicePlan(t)
+	case reflect.Array:
+		return newArrayPlan(t)
+	case reflect.Map:
+		if t == duplicatesType {
+			return newDuplicatesPlan()
+		}
+		if plan := bulkMapPlan(t); plan != nil {
+			return plan
+		}
+		if t.Key().Kind() == reflect.String {
+			return newMapPlan(t)
+		}
+		return func(r *Reader, v reflect.Value) error {
+			if r.Null() {
+				v.Set(reflect.Zero(v.Type()))
+				return nil
+			}
+			return errors.New("map key must be string")
+		}
+	case reflect.Struct:
+		if isSQLNull(t) {
+			return newSQLNullPlan(t)
+		}
+		return newStructPlan(t)
+	}
+
+	return func(r *Reader, v reflect.Value) error {
+		if r.Null() {
 			return nil
 		}
+		return errors.New("cannot unmarshal into " + v.Type().String())
 	}
-	return errors.New("cannot unmarshal bool into " + dst.Type().String())
 }
 
-func (d *decoder) decodeNumber(src float64, dst reflect.Value) error {
-	switch dst.Kind() {
-	case reflect.Float32:
-		dst.SetFloat(src)
-		return nil
-	case reflect.Float64:
-		dst.SetFloat(src)
-		return nil
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		dst.SetInt(int64(src))
-		return nil
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		dst.SetUint(uint64(src))
+func decodeBool(r *Reader, v reflect.Value) error {
+	if r.Null() {
 		return nil
-	case reflect.Interface:
-		if dst.Type().NumMethod() == 0 {
-			dst.Set(reflect.ValueOf(src))
-			return nil
-		}
 	}
-	return errors.New("cannot unmarshal number into " + dst.Type().String())
+	switch r.peek() {
+	case internalScanner.TokenTrue, internalScanner.TokenFalse:
+	default:
+		return r.typeError(v.Type())
+	}
+	b, err := r.Bool()
+	if err != nil {
+		return err
+	}
+	v.SetBool(b)
+	return nil
 }
 
-func (d *decoder) decodeInt(src int64, dst reflect.Value) error {
-	switch dst.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		dst.SetInt(src)
+func decodeString(r *Reader, v reflect.Value) error {
+	if r.Null() {
 		return nil
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		dst.SetUint(uint64(src))
+	}
+	if r.peek() != internalScanner.TokenString {
+		return r.typeError(v.Type())
+	}
+	s, err := r.String()
+	if err != nil {
+		return err
+	}
+	v.SetString(s)
+	return nil
+}
+
+func decodeBytes(r *Reader, v reflect.Value) error {
+	return decodeBytesAs(r, v, r.bytesFmt)
+}
+
+// newBytesPlan returns the plan of a []byte field with a format tag option.
+func newBytesPlan(f BytesFormat) decodeFunc {
+	return func(r *Reader, v reflect.Value) error {
+		return decodeBytesAs(r, v, f)
+	}
+}
+
+func decodeBytesAs(r *Reader, v reflect.Value, f BytesFormat) error {
+	if r.Null() {
+		v.SetBytes(nil)
 		return nil
-	case reflect.Float32, reflect.Float64:
-		dst.SetFloat(float64(src))
+	}
+	switch r.peek() {
+	case internalScanner.TokenString, internalScanner.TokenArrayBegin:
+	default:
+		return r.typeError(v.Type())
+	}
+	b, err := r.BytesAs(f)
+	if err != nil {
+		return err
+	}
+	v.SetBytes(b)
+	return nil
+}
+
+func decodeInterface(r *Reader, v reflect.Value) error {
+	if ok, err := decodeHeldPointer(r, v); ok {
+		return err
+	}
+	x, err := r.value()
+	if err != nil {
+		return err
+	}
+	if x == nil {
+		v.Set(reflect.Zero(v.Type()))
 		return nil
-	case reflect.Interface:
-		if dst.Type().NumMethod() == 0 {
-			dst.Set(reflect.ValueOf(src))
+	}
+	v.Set(reflect.ValueOf(x))
+	return nil
+}
+
+// decodeHeldPointer decodes into the target of the non-nil pointer held by
+// the interface v, as encoding/json does, and reports whether v held one.
+// A null clears v instead, unless the target is itself a pointer, which is
+// then set to nil.
+func decodeHeldPointer(r *Reader, v reflect.Value) (bool, error) {
+	if v.IsNil() {
+		return false, nil
+	}
+	p := v.Elem()
+	if p.Kind() != reflect.Ptr || p.IsNil() {
+		return false, nil
+	}
+	if r.peek() == internalScanner.TokenNull && p.Elem().Kind() != reflect.Ptr {
+		return false, nil
+	}
+	return true, planFor(p.Type().Elem())(r, p.Elem())
+}
+
+func newPtrPlan(t reflect.Type) decodeFunc {
+	elem := t.Elem()
+	plan := planFor(elem)
+	return func(r *Reader, v reflect.Value) error {
+		if r.Null() {
+			v.Set(reflect.Zero(t))
 			return nil
 		}
+		if v.IsNil() {
+			v.Set(reflect.New(elem))
+		}
+		return plan(r, v.Elem())
 	}
-	return errors.New("cannot unmarshal int into " + dst.Type().String())
 }
 
-func (d *decoder) decodeString(src string, dst reflect.Value) error {
-	switch dst.Kind() {
-	case reflect.String:
-		dst.SetString(src)
-		return nil
-	case reflect.Interface:
-		if dst.Type().NumMethod() == 0 {
-			dst.Set(reflect.ValueOf(src))
+func newSlicePlan(t reflect.Type) decodeFunc {
+	return newSliceOf(t, planFor(t.Elem()))
+}
+
+// newSliceOf returns the plan of slice type t with plan for its elements.
+func newSliceOf(t reflect.Type, plan decodeFunc) decodeFunc {
+	elem := t.Elem()
+	return func(r *Reader, v reflect.Value) error {
+		if r.Null() {
+			v.Set(reflect.Zero(t))
 			return nil
 		}
+		if r.peek() != internalScanner.TokenArrayBegin {
+			return r.typeError(t)
+		}
+		r.pos++
+
+		// Elements within the old length are decoded into, so a null
+		// leaves them unchanged as in encoding/json; the rest start zeroed
+		n, old := 0, v.Len()
+		var errs DecodeErrors
+		for r.More() {
+			if n >= v.Cap() {
+				grown := reflect.MakeSlice(t, n, 2*n+4)
+				reflect.Copy(grown, v)
+				v.Set(grown)
+			}
+			v.SetLen(n + 1)
+			el := v.Index(n)
+			if n >= old {
+				el.Set(reflect.Zero(elem))
+			}
+			start := r.pos
+			if err := plan(r, el); err != nil {
+				if err := r.collect(err, &errs, start, strconv.Itoa(n)); err != nil {
+					return err
+				}
+			}
+			n++
+		}
+		if err := r.EndArray(); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeSlice(t, 0, 0))
+		}
+		v.SetLen(n)
+		if errs != nil {
+			return errs
+		}
+		return nil
 	}
-	return errors.New("cannot unmarshal string into " + dst.Type().String())
 }
 
-func (d *decoder) decodeArray(src []interface{}, dst reflect.Value) error {
-	switch dst.Kind() {
-	case reflect.Slice:
-		// Create or resize slice
-		if dst.IsNil() || dst.Len() < len(src) {
-			dst.Set(reflect.MakeSlice(dst.Type(), len(src), len(src)))
+func newArrayPlan(t reflect.Type) decodeFunc {
+	elem := t.Elem()
+	plan := planFor(elem)
+	return func(r *Reader, v reflect.Value) error {
+		if r.Null() {
+			return nil
 		}
-		
-		for i, v := range src {
-			if err := d.decode(v, dst.Index(i)); err != nil {
-				return err
+		if r.peek() != internalScanner.TokenArrayBegin {
+			return r.typeError(t)
+		}
+		r.pos++
+
+		n := 0
+		var errs DecodeErrors
+		for r.More() {
+			if n >= v.Len() {
+				// Extra elements are dropped, as in encoding/json
+				if err := r.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			start := r.pos
+			if err := plan(r, v.Index(n)); err != nil {
+				if err := r.collect(err, &errs, start, strconv.Itoa(n)); err != nil {
+					return err
+				}
 			}
+			n++
+		}
+		for ; n < v.Len(); n++ {
+			v.Index(n).Set(reflect.Zero(elem))
+		}
+		if err := r.EndArray(); err != nil {
+			return err
+		}
+		if errs != nil {
+			return errs
 		}
 		return nil
-		
-	case reflect.Array:
-		if dst.Len() < len(src) {
-			return errors.New("array too small")
+	}
+}
+
+func newMapPlan(t reflect.Type) decodeFunc {
+	return newMapOf(t, planFor(t.Elem()))
+}
+
+// newMapOf returns the plan of string-keyed map type t with plan for its
+// values.
+func newMapOf(t reflect.Type, plan decodeFunc) decodeFunc {
+	keyType, elem := t.Key(), t.Elem()
+	return func(r *Reader, v reflect.Value) error {
+		if r.Null() {
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		if r.peek() != internalScanner.TokenObjectBegin {
+			return r.typeError(t)
+		}
+		r.pos++
+
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
 		}
-		
-		for i, v := range src {
-			if err := d.decode(v, dst.Index(i)); err != nil {
+		key := reflect.New(keyType).Elem()
+		val := reflect.New(elem).Elem()
+		var errs DecodeErrors
+		for r.More() {
+			k, err := r.keyString()
+			if err != nil {
 				return err
 			}
+			key.SetString(k)
+			val.Set(reflect.Zero(elem))
+			start := r.pos
+			if err := plan(r, val); err != nil {
+				if err := r.collect(err, &errs, start, k); err != nil {
+					return err
+				}
+				continue
+			}
+			v.SetMapIndex(key, val)
+		}
+		if err := r.EndObject(); err != nil {
+			return err
+		}
+		if errs != nil {
+			return errs
 		}
 		return nil
-		
-	case reflect.Interface:
-		if dst.Type().NumMethod() == 0 {
-			dst.Set(reflect.ValueOf(src))
-			return nil
+	}
+}
+
+// structPlan maps JSON keys to the fields of one struct type.
+type structPlan struct {
+	typ     reflect.Type
+	fields  []fieldInfo
+	plans   []decodeFunc // plan of each field
+	matcher *fieldMatcher
+
+	// Map field collecting the members no field matches, if any
+	unknown     int
+	unknownPlan decodeFunc // plan of its values
+
+	// Bit of each field in the set of required fields found, 0 if the
+	// field is optional; all is the set of every required field. Only the
+	// first 64 required fields are tracked in bits, the rest in a slice.
+	requiredBit []uint64
+	all         uint64
+	many        bool
+}
+
+func newStructPlan(t reflect.Type) decodeFunc {
+	// The keys depend on the Reader's field naming, so there is a plan
+	// per naming, each built when first needed
+	var plans [numFieldNamings]atomic.Pointer[structPlan]
+	plans[GoFieldNames].Store(buildStructPlan(t, GoFieldNames))
+
+	return func(r *Reader, v reflect.Value) error {
+		sp := plans[r.naming].Load()
+		if sp == nil {
+			sp = buildStructPlan(t, r.naming)
+			plans[r.naming].Store(sp)
 		}
+		return sp.decode(r, v)
 	}
-	
-	return errors.New("cannot unmarshal array into " + dst.Type().String())
 }
 
-func (d *decoder) decodeObject(src map[string]interface{}, dst reflect.Value) error {
-	switch dst.Kind() {
-	case reflect.Map:
-		// Create map if nil
-		if dst.IsNil() {
-			dst.Set(reflect.MakeMap(dst.Type()))
-		}
-		
-		keyType := dst.Type().Key()
-		elemType := dst.Type().Elem()
-		
-		for k, v := range src {
-			keyVal := reflect.New(keyType).Elem()
-			if keyType.Kind() == reflect.String {
-				keyVal.SetString(k)
-			} else {
-				return errors.New("map key must be string")
+func buildStructPlan(t reflect.Type, naming FieldNaming) *structPlan {
+	info := cachedStruct(t, naming)
+	sp := &structPlan{typ: t, fields: info.fields, unknown: info.unknown}
+	if sp.unknown >= 0 {
+		sp.unknownPlan = planFor(t.Field(sp.unknown).Type.Elem())
+	}
+	sp.matcher = newFieldMatcher(sp.fields)
+	sp.plans = make([]decodeFunc, len(sp.fields))
+	for i, f := range sp.fields {
+		sp.plans[i] = planFor(f.typ)
+		if f.discriminator != "" {
+			sp.plans[i] = newDiscriminatedPlan(f.typ, f.discriminator)
+		}
+		if f.hasBytesFormat && !reflect.PointerTo(f.typ).Implements(unmarshalerFromType) &&
+			!reflect.PointerTo(f.typ.Elem()).Implements(unmarshalerFromType) {
+			sp.plans[i] = newBytesPlan(f.bytesFormat)
+		}
+	}
+	n := 0
+	for i, f := range sp.fields {
+		if !f.required {
+			continue
+		}
+		if sp.requiredBit == nil {
+			sp.requiredBit = make([]uint64, len(sp.fields))
+		}
+		if n < 64 {
+			sp.requiredBit[i] = 1 << n
+			sp.all |= 1 << n
+		} else {
+			sp.many = true
+		}
+		n++
+	}
+	return sp
+}
+
+func (sp *structPlan) decode(r *Reader, v reflect.Value) error {
+	if r.Null() {
+		return nil
+	}
+	if r.peek() != internalScanner.TokenObjectBegin {
+		return r.typeError(sp.typ)
+	}
+	r.pos++
+
+	var found uint64
+	var foundMany []bool
+	if sp.many {
+		foundMany = make([]bool, len(sp.fields))
+	}
+	var errs DecodeErrors
+	for r.More() {
+		k, err := r.Key()
+		if err != nil {
+			return err
+		}
+		i := sp.matcher.match(k)
+		if i >= 0 && sp.requiredBit != nil {
+			found |= sp.requiredBit[i]
+			if foundMany != nil {
+				foundMany[i] = true
+			}
+		}
+		start := r.pos
+		if i < 0 && sp.unknown >= 0 {
+			key := string(k)
+			if err := sp.decodeUnknown(r, v.Field(sp.unknown), key); err != nil {
+				if err := r.collect(err, &errs, start, key); err != nil {
+					return err
+				}
 			}
-			
-			elemVal := reflect.New(elemType).Elem()
-			if err := d.decode(v, elemVal); err != nil {
+			continue
+		}
+		if i < 0 {
+			if err := r.Skip(); err != nil {
 				return err
 			}
-			
-			dst.SetMapIndex(keyVal, elemVal)
+			continue
 		}
-		return nil
-		
-	case reflect.Struct:
-		return d.decodeStruct(src, dst)
-		
-	case reflect.Interface:
-		if dst.Type().NumMethod() == 0 {
-			dst.Set(reflect.ValueOf(src))
-			return nil
+		if err := sp.plans[i](r, sp.fields[i].settable(v)); err != nil {
+			if err := r.collect(err, &errs, start, sp.fields[i].name); err != nil {
+				return err
+			}
 		}
 	}
-	
-	return errors.New("cannot unmarshal object into " + dst.Type().String())
+	if err := r.EndObject(); err != nil {
+		return err
+	}
+	if found != sp.all || sp.many {
+		if err := sp.missing(found, foundMany); err != nil {
+			if !r.allErrs {
+				return err
+			}
+			errs = append(errs, missingErrors("", err.(*MissingFieldsError))...)
+		}
+	}
+	if errs != nil {
+		return errs
+	}
+	return nil
 }
 
-func (d *decoder) decodeStruct(src map[string]interface{}, dst reflect.Value) error {
-	typ := dst.Type()
-	
-	// Build field map
-	fields := make(map[string]int)
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
-		
-		// Get JSON tag
-		tag := field.Tag.Get("json")
-		if tag == "-" {
+// missing returns a MissingFieldsError listing the required fields not in
+// found, or nil if there are none.
+func (sp *structPlan) missing(found uint64, foundMany []bool) error {
+	var keys []string
+	for i, f := range sp.fields {
+		if !f.required {
 			continue
 		}
-		
-		name := field.Name
-		if tag != "" {
-			// Parse tag (simplified - doesn't handle all cases)
-			if idx := findComma(tag); idx != -1 {
-				name = tag[:idx]
-			} else {
-				name = tag
-			}
+		if bit := sp.requiredBit[i]; bit != 0 && found&bit == 0 || bit == 0 && !foundMany[i] {
+			keys = append(keys, f.name)
 		}
-		
-		fields[name] = i
 	}
-	
-	// Set struct fields
-	for k, v := range src {
-		if idx, ok := fields[k]; ok {
-			field := dst.Field(idx)
-			if field.CanSet() {
-				if err := d.decode(v, field); err != nil {
-					return err
+	if keys == nil {
+		return nil
+	}
+	return &MissingFieldsError{Struct: sp.typ.Name(), Fields: keys}
+}
+
+// A MissingFieldsError reports the keys of required fields, those tagged
+// with the required option, that an object being decoded lacks. A key
+// with a null value is present.
+type MissingFieldsError struct {
+	Struct string   // name of the struct type, "" if it has none
+	Fields []string // JSON keys of the missing fields
+}
+
+func (e *MissingFieldsError) Error() string {
+	msg := "missing required field"
+	if len(e.Fields) > 1 {
+		msg += "s"
+	}
+	for i, f := range e.Fields {
+		if i > 0 {
+			msg += ","
+		}
+		msg += " " + strconv.Quote(f)
+	}
+	if e.Struct != "" {
+		msg += " in " + e.Struct
+	}
+	return msg
+}
+
+// decodeUnknown decodes the value of member key into the unknown map m.
+func (sp *structPlan) decodeUnknown(r *Reader, m reflect.Value, key string) error {
+	t := m.Type()
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(t))
+	}
+	val := reflect.New(t.Elem()).Elem()
+	if err := sp.unknownPlan(r, val); err != nil {
+		return err
+	}
+	k := reflect.New(t.Key()).Elem()
+	k.SetString(key)
+	m.SetMapIndex(k, val)
+	return nil
+}
+
+// value reads the next value as the generic Go representation: maps,
+// slices, strings, bools, nil, and int64 for integers that fit, float64
+// otherwise (including -0).
+func (r *Reader) value() (interface{}, error) {
+	switch r.peek() {
+	case internalScanner.TokenObjectBegin:
+		r.pos++
+		if r.ordered {
+			obj := new(Object)
+			return obj, r.membersInto(obj)
+		}
+		var obj map[string]interface{}
+		if r.arena != nil {
+			obj = r.arena.newMap()
+		} else {
+			obj = make(map[string]interface{})
+		}
+		return obj, r.objectInto(obj)
+	case internalScanner.TokenArrayBegin:
+		r.pos++
+		if r.arena != nil {
+			return r.arenaArray()
+		}
+		arr := make([]interface{}, 0, 8)
+		for r.More() {
+			val, err := r.value()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, val)
+		}
+		return arr, r.EndArray()
+	case internalScanner.TokenString:
+		if r.arena != nil {
+			return r.arenaString()
+		}
+		return r.String()
+	case internalScanner.TokenNumber:
+		isFloat := r.tokens[r.pos].IsFloat()
+		s, err := r.number()
+		if err != nil {
+			return nil, err
+		}
+		// -0 stays a float64 so the sign survives a round trip
+		if !isFloat && s != "-0" {
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				if r.arena != nil {
+					return r.arena.intValue(n), nil
 				}
+				return n, nil
 			}
 		}
+		f, err := r.parseFloat(s, 64, nil)
+		if err != nil {
+			return nil, err
+		}
+		if r.arena != nil {
+			return r.arena.floatValue(f), nil
+		}
+		return f, nil
+	case internalScanner.TokenTrue, internalScanner.TokenFalse:
+		return r.Bool()
+	case internalScanner.TokenNull:
+		r.pos++
+		return nil, nil
 	}
-	
-	return nil
+	if r.pos >= len(r.tokens) {
+		return nil, r.fail(errUnexpectedEnd)
+	}
+	return nil, r.fail(errors.New("unexpected token"))
+}
+
+// arenaArray reads the elements of an array whose '[' has already been
+// consumed. They are collected on r.stack, which nested arrays share, and
+// copied into the arena once the length is known.
+func (r *Reader) arenaArray() (interface{}, error) {
+	start := len(r.stack)
+	defer func() {
+		clear(r.stack[start:])
+		r.stack = r.stack[:start]
+	}()
+	for r.More() {
+		val, err := r.value()
+		if err != nil {
+			return nil, err
+		}
+		r.stack = append(r.stack, val)
+	}
+	return r.arena.sliceValue(r.stack[start:]), r.EndArray()
+}
+
+// arenaString reads a string value, boxed in the arena. Its bytes are
+// copied there too, unless zero-copy mode lets them alias the input.
+func (r *Reader) arenaString() (interface{}, error) {
+	raw, escaped, err := r.stringBytes()
+	if err != nil {
+		return nil, err
+	}
+	if escaped {
+		r.scratch, err = parser.AppendUnescaped(r.scratch[:0], raw)
+		if err != nil {
+			return nil, r.fail(err)
+		}
+		return r.arena.stringValue(r.arena.string(r.scratch)), nil
+	}
+	if r.zeroCopy {
+		return r.arena.stringValue(*(*string)(unsafe.Pointer(&raw))), nil
+	}
+	return r.arena.stringValue(r.arena.string(raw)), nil
+}
+
+// objectInto reads the members of an object whose '{' has already been
+// consumed into obj.
+func (r *Reader) objectInto(obj map[string]interface{}) error {
+	for r.More() {
+		key, err := r.keyString()
+		if err != nil {
+			return err
+		}
+		val, err := r.value()
+		if err != nil {
+			return err
+		}
+		obj[key] = val
+	}
+	return r.EndObject()
+}
+
+// An UnmarshalTypeError describes a JSON value that cannot be stored in a
+// Go value of some type: a string for an int, say, or a number beyond the
+// range of an int8 or with a fraction for an int.
+type UnmarshalTypeError struct {
+	Value  string       // the JSON value: "string", "number 300", ...
+	Type   reflect.Type // the type it could not be stored in
+	Offset int64        // offset of the value in the input
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return "cannot unmarshal " + e.Value + " into " + e.Type.String()
+}
+
+// typeError reports that the next value cannot be stored in a t.
+func (r *Reader) typeError(t reflect.Type) error {
+	kind := "value"
+	switch r.peek() {
+	case internalScanner.TokenObjectBegin:
+		kind = "object"
+	case internalScanner.TokenArrayBegin:
+		kind = "array"
+	case internalScanner.TokenString:
+		kind = "string"
+	case internalScanner.TokenNumber:
+		kind = "number"
+	case internalScanner.TokenTrue, internalScanner.TokenFalse:
+		kind = "bool"
+	case internalScanner.TokenNone:
+		return r.fail(errUnexpectedEnd)
+	}
+	return r.fail(&UnmarshalTypeError{Value: kind, Type: t, Offset: int64(r.tokens[r.pos].Start)})
+}
+
+var (
+	intTypes   = [...]reflect.Type{reflect.TypeOf(int8(0)), reflect.TypeOf(int16(0)), reflect.TypeOf(int32(0)), int64Type}
+	uintTypes  = [...]reflect.Type{reflect.TypeOf(uint8(0)), reflect.TypeOf(uint16(0)), reflect.TypeOf(uint32(0)), reflect.TypeOf(uint64(0))}
+	floatTypes = [...]reflect.Type{reflect.TypeOf(float32(0)), float64Type}
+)
+
+// basicNumberType returns the predeclared type of kind (Int, Uint or
+// Float64) and bitSize, where 0 means int or uint.
+func basicNumberType(kind reflect.Kind, bitSize int) reflect.Type {
+	switch {
+	case bitSize == 0 && kind == reflect.Int:
+		return reflect.TypeOf(0)
+	case bitSize == 0:
+		return reflect.TypeOf(uint(0))
+	case kind == reflect.Float64:
+		return floatTypes[bitSize/32-1]
+	case kind == reflect.Int:
+		return intTypes[bits.TrailingZeros(uint(bitSize))-3]
+	}
+	return uintTypes[bits.TrailingZeros(uint(bitSize))-3]
 }
 
 func findComma(s string) int {
@@ -0,0 +1,73 @@
+package simdjson
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+)
+
+// Magic numbers at the start of compressed streams. Neither can start
+// JSON text, so sniffing them cannot mistake a document for compressed.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// zstdReader decompresses zstd streams. It is set when built with the zstd
+// tag, which brings in github.com/klauspost/compress; see zstd.go.
+var zstdReader func(io.Reader) (io.Reader, error)
+
+var errNoZstd = errors.New("zstd input requires building with -tags zstd")
+
+// NewDecoderAuto returns a Decoder like NewDecoder that detects gzip and
+// zstd input by its magic number and decompresses it as it is read, so
+// compressed files and response bodies need no plumbing of their own.
+// Uncompressed input is read as is. gzip is supported by the standard
+// library; zstd needs a build with the zstd tag, and is an error without.
+func NewDecoderAuto(r io.Reader) *Decoder {
+	d := NewDecoder(nil)
+	d.auto = true
+	d.Reset(r)
+	return d
+}
+
+// autoReader reads r, decompressed if it starts with the magic number of a
+// gzip or zstd stream. The magic is sniffed on the first Read.
+type autoReader struct {
+	r   io.Reader
+	src io.Reader
+	err error
+}
+
+func (a *autoReader) Read(p []byte) (int, error) {
+	if a.src == nil && a.err == nil {
+		a.src, a.err = decompress(a.r)
+	}
+	if a.err != nil {
+		return 0, a.err
+	}
+	return a.src.Read(p)
+}
+
+// decompress returns a reader of r's contents, decompressed if they are
+// compressed.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(zstdMagic))
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return zr, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		if zstdReader == nil {
+			return nil, errNoZstd
+		}
+		return zstdReader(br)
+	}
+	return br, nil
+}
@@ -0,0 +1,75 @@
+package simdjson
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestNewDecoderAuto(t *testing.T) {
+	doc := `{"name":"root","children":[{"name":"leaf"}]}`
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{"plain", []byte(doc)},
+		{"gzip", gzipped(t, doc)},
+		{"gzip members", append(gzipped(t, doc[:10]), gzipped(t, doc[10:])...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var n decodeNode
+			if err := NewDecoderAuto(bytes.NewReader(tt.input)).Decode(&n); err != nil {
+				t.Fatal(err)
+			}
+			if n.Name != "root" || len(n.Children) != 1 || n.Children[0].Name != "leaf" {
+				t.Errorf("Expected root with a leaf, got %+v", n)
+			}
+		})
+	}
+
+	// Reset keeps detecting compression
+	dec := NewDecoderAuto(strings.NewReader(`{"name":"plain"}`))
+	var n decodeNode
+	if err := dec.Decode(&n); err != nil || n.Name != "plain" {
+		t.Fatalf("Expected plain, got %q, %v", n.Name, err)
+	}
+	dec.Reset(bytes.NewReader(gzipped(t, `{"name":"compressed"}`)))
+	if err := dec.Decode(&n); err != nil || n.Name != "compressed" {
+		t.Errorf("Expected compressed after Reset, got %q, %v", n.Name, err)
+	}
+}
+
+func TestNewDecoderAutoErrors(t *testing.T) {
+	var v interface{}
+	truncated := gzipped(t, `{"a":[1,2,3]}`)
+	truncated = truncated[:len(truncated)-6]
+	if err := NewDecoderAuto(bytes.NewReader(truncated)).Decode(&v); err == nil {
+		t.Error("Expected an error for a truncated gzip stream")
+	}
+	if err := NewDecoderAuto(bytes.NewReader([]byte{0x1f, 0x8b, 0})).Decode(&v); err == nil {
+		t.Error("Expected an error for a bad gzip header")
+	}
+
+	zstd := []byte{0x28, 0xb5, 0x2f, 0xfd, 0, 0, 0}
+	err := NewDecoderAuto(bytes.NewReader(zstd)).Decode(&v)
+	if zstdReader == nil && !errors.Is(err, errNoZstd) {
+		t.Errorf("Expected zstd to need the build tag, got %v", err)
+	}
+	if err == nil {
+		t.Error("Expected an error for a bad zstd frame")
+	}
+}
@@ -0,0 +1,101 @@
+package simdjson
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/jsonenc"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// MarshalDeterministic returns the JSON encoding of v in a form that
+// depends only on the JSON value it encodes, for golden files in tests,
+// reproducible build outputs and content hashes. Marshal already sorts map
+// keys; MarshalDeterministic also sorts the members of every object by
+// key, struct fields and ordered Objects included, and rewrites the
+// RawMessage values inside v the same way: no whitespace, strings escaped
+// as Marshal escapes them, and floats, numbers with a fraction or
+// exponent, in the fewest digits that read back as the same float64.
+// Integers are kept as written, however long, and -0 is written as 0.
+// Repeated keys keep their order.
+//
+// Unlike MarshalCanonical, keys are sorted in byte order, integers are not
+// rounded to float64 and strings keep Marshal's escapes, so the output is
+// stable but is not RFC 8785 canonical JSON.
+func MarshalDeterministic(v interface{}) ([]byte, error) {
+	data, err := marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := scanner.New()
+	defer s.Release()
+	d, err := parseDocument(s, data)
+	if err != nil {
+		return nil, err
+	}
+	defer d.release()
+	return appendDeterministic(make([]byte, 0, len(data)), d, 0), nil
+}
+
+// appendDeterministic appends the deterministic form of the value at pos.
+func appendDeterministic(dst []byte, d *document, pos int) []byte {
+	switch d.kind(pos) {
+	case scanner.TokenObjectBegin:
+		type member struct {
+			key string
+			val int
+		}
+		var members []member
+		d.members(pos, func(k, v int) {
+			members = append(members, member{string(d.str(k)), v})
+		})
+		sort.SliceStable(members, func(i, j int) bool {
+			return members[i].key < members[j].key
+		})
+
+		dst = append(dst, '{')
+		for i, m := range members {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = append(dst, '"')
+			dst = jsonenc.AppendString(dst, m.key, true)
+			dst = append(dst, '"', ':')
+			dst = appendDeterministic(dst, d, m.val)
+		}
+		return append(dst, '}')
+	case scanner.TokenArrayBegin:
+		dst = append(dst, '[')
+		n := 0
+		d.elements(pos, func(e int) {
+			if n > 0 {
+				dst = append(dst, ',')
+			}
+			n++
+			dst = appendDeterministic(dst, d, e)
+		})
+		return append(dst, ']')
+	case scanner.TokenString:
+		dst = append(dst, '"')
+		dst = jsonenc.AppendString(dst, string(d.str(pos)), true)
+		return append(dst, '"')
+	case scanner.TokenNumber:
+		raw := d.raw(pos)
+		if !d.tokens[pos].IsFloat() {
+			if string(raw) == "-0" {
+				return append(dst, '0')
+			}
+			return append(dst, raw...)
+		}
+		f, err := strconv.ParseFloat(string(raw), 64)
+		if err != nil {
+			// Beyond float64, so kept as written
+			return append(dst, raw...)
+		}
+		if f == 0 {
+			return append(dst, '0')
+		}
+		return jsonenc.AppendFloat(dst, f, 64)
+	}
+	return append(dst, d.raw(pos)...)
+}
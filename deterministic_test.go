@@ -0,0 +1,53 @@
+package simdjson
+
+import "testing"
+
+func TestMarshalDeterministic(t *testing.T) {
+	type item struct {
+		Zeta  int         `json:"zeta"`
+		Alpha string      `json:"alpha"`
+		Raw   RawMessage  `json:"raw"`
+		Any   interface{} `json:"any"`
+	}
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"fields", item{Zeta: 1, Alpha: "<a>", Raw: RawMessage(`null`)}, `{"alpha":"\u003ca\u003e","any":null,"raw":null,"zeta":1}`},
+		{"raw", RawMessage(` { "b" : [ 1.50, 1e2, -0, 0.0 ], "a" : "é\/" } `), `{"a":"é/","b":[1.5,100,0,0]}`},
+		{"big integers", RawMessage(`[123456789012345678901234567890, 1e400]`), `[123456789012345678901234567890,1e400]`},
+		{"repeated keys", RawMessage(`{"b":1,"a":2,"b":0}`), `{"a":2,"b":1,"b":0}`},
+		{"map", map[string]interface{}{"y": []int{2, 1}, "x": 0.25}, `{"x":0.25,"y":[2,1]}`},
+		{"float32", float32(0.1), `0.1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := MarshalDeterministic(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+
+	// An ordered Object gives the same bytes as a map with its members
+	var obj Object
+	if err := Unmarshal([]byte(`{"b":{"d":1,"c":2},"a":true}`), &obj); err != nil {
+		t.Fatal(err)
+	}
+	got, err := MarshalDeterministic(&obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := MarshalDeterministic(map[string]interface{}{"a": true, "b": map[string]int{"c": 2, "d": 1}})
+	if string(got) != string(want) {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+
+	if _, err := MarshalDeterministic(make(chan int)); err == nil {
+		t.Error("Expected an error for an unsupported type")
+	}
+}
@@ -0,0 +1,129 @@
+package simdjson
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// Equal reports whether a and b hold the same JSON value, ignoring
+// whitespace, member order, string escapes and the spelling of numbers
+// (1, 1.0 and 1e0 are equal). The documents are compared token by token
+// without decoding them. Invalid documents are not equal to anything.
+func Equal(a, b []byte) bool {
+	s := scanner.New()
+	defer s.Release()
+	da, err := parseDocument(s, a)
+	if err != nil {
+		return false
+	}
+	defer da.release()
+
+	s2 := scanner.New()
+	defer s2.Release()
+	db, err := parseDocument(s2, b)
+	if err != nil {
+		return false
+	}
+	defer db.release()
+
+	return equalValues(da, 0, db, 0)
+}
+
+// A Difference is a value that differs between two documents compared by
+// Diff.
+type Difference struct {
+	// Path is the JSON Pointer (RFC 6901) of the value, "" for the root.
+	Path string
+	// A and B are the value in each document as written, nil where the
+	// document has no value at Path.
+	A, B []byte
+}
+
+func (d Difference) String() string {
+	switch {
+	case d.A == nil:
+		return d.Path + ": added " + string(d.B)
+	case d.B == nil:
+		return d.Path + ": removed " + string(d.A)
+	}
+	return d.Path + ": " + string(d.A) + " != " + string(d.B)
+}
+
+// Diff returns the differences between a and b, comparing values as Equal
+// does. Objects are compared member by member and arrays element by
+// element, so a difference is reported at the deepest path where the
+// documents diverge: members and trailing elements present in only one
+// document, and values that are not equal or not of the same type. Object
+// members are reported in document order, those only in b last. Diff
+// returns nil if the documents are equal.
+func Diff(a, b []byte) ([]Difference, error) {
+	s := scanner.New()
+	defer s.Release()
+	da, err := parseDocument(s, a)
+	if err != nil {
+		return nil, err
+	}
+	defer da.release()
+
+	s2 := scanner.New()
+	defer s2.Release()
+	db, err := parseDocument(s2, b)
+	if err != nil {
+		return nil, err
+	}
+	defer db.release()
+
+	return diffValues(nil, "", da, 0, db, 0), nil
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// diffValues appends the differences between the value at apos in a and
+// the value at bpos in b, found at path.
+func diffValues(diffs []Difference, path string, a *document, apos int, b *document, bpos int) []Difference {
+	switch {
+	case a.kind(apos) == scanner.TokenObjectBegin && b.kind(bpos) == scanner.TokenObjectBegin:
+		ak, bk := a.keyIndex(apos), b.keyIndex(bpos)
+		a.members(apos, func(k, v int) {
+			key := string(a.str(k))
+			if ak[key] != v {
+				return
+			}
+			p := path + "/" + pointerEscaper.Replace(key)
+			if bv, ok := bk[key]; ok {
+				diffs = diffValues(diffs, p, a, v, b, bv)
+			} else {
+				diffs = append(diffs, Difference{Path: p, A: a.raw(v)})
+			}
+		})
+		b.members(bpos, func(k, v int) {
+			key := string(b.str(k))
+			if _, ok := ak[key]; !ok && bk[key] == v {
+				diffs = append(diffs, Difference{Path: path + "/" + pointerEscaper.Replace(key), B: b.raw(v)})
+			}
+		})
+		return diffs
+	case a.kind(apos) == scanner.TokenArrayBegin && b.kind(bpos) == scanner.TokenArrayBegin:
+		var ae, be []int
+		a.elements(apos, func(e int) { ae = append(ae, e) })
+		b.elements(bpos, func(e int) { be = append(be, e) })
+		for i := 0; i < len(ae) || i < len(be); i++ {
+			p := path + "/" + strconv.Itoa(i)
+			switch {
+			case i >= len(be):
+				diffs = append(diffs, Difference{Path: p, A: a.raw(ae[i])})
+			case i >= len(ae):
+				diffs = append(diffs, Difference{Path: p, B: b.raw(be[i])})
+			default:
+				diffs = diffValues(diffs, p, a, ae[i], b, be[i])
+			}
+		}
+		return diffs
+	}
+	if !equalValues(a, apos, b, bpos) {
+		diffs = append(diffs, Difference{Path: path, A: a.raw(apos), B: b.raw(bpos)})
+	}
+	return diffs
+}
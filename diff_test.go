@@ -0,0 +1,94 @@
+package simdjson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected bool
+	}{
+		{"identical", `{"a":1}`, `{"a":1}`, true},
+		{"whitespace", `{"a": [1, 2]}`, " {\n\t\"a\":[1,2]\n} ", true},
+		{"member_order", `{"a":1,"b":{"c":2,"d":3}}`, `{"b":{"d":3,"c":2},"a":1}`, true},
+		{"number_spelling", `[1, 100, 0.5, -0]`, `[1.0, 1e2, 5E-1, 0]`, true},
+		{"escapes", `{"key":"é\/"}`, `{"key":"é/"}`, true},
+		{"duplicate_keys_last_wins", `{"a":1,"a":2}`, `{"a":2}`, true},
+		{"scalars", `null`, `null`, true},
+
+		{"different_value", `{"a":1}`, `{"a":2}`, false},
+		{"different_type", `{"a":1}`, `{"a":"1"}`, false},
+		{"missing_member", `{"a":1,"b":2}`, `{"a":1}`, false},
+		{"extra_member", `{"a":1}`, `{"a":1,"b":null}`, false},
+		{"array_order", `[1,2]`, `[2,1]`, false},
+		{"array_length", `[1,2]`, `[1,2,3]`, false},
+		{"true_false", `true`, `false`, false},
+		{"large_integers", `9007199254740993`, `9007199254740992`, false},
+		{"invalid", `{"a":1`, `{"a":1`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Equal([]byte(tt.a), []byte(tt.b)); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+			if got := Equal([]byte(tt.b), []byte(tt.a)); got != tt.expected {
+				t.Errorf("Expected %v with arguments swapped, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected []string
+	}{
+		{"equal", `{"a":[1,{"b":2}]}`, `{"a":[1.0,{"b":2}]}`, nil},
+		{"root", `1`, `"x"`, []string{`: 1 != "x"`}},
+		{"member_changed", `{"a":1,"b":2}`, `{"a":1,"b":3}`, []string{`/b: 2 != 3`}},
+		{"members_added_removed", `{"a":1,"b":2}`, `{"c":3,"b":2}`, []string{`/a: removed 1`, `/c: added 3`}},
+		{"nested", `{"a":{"b":[1,2,{"c":true}]}}`, `{"a":{"b":[1,3,{"c":false}]}}`,
+			[]string{`/a/b/1: 2 != 3`, `/a/b/2/c: true != false`}},
+		{"array_length", `[1,2,3]`, `[1]`, []string{`/1: removed 2`, `/2: removed 3`}},
+		{"array_grows", `[]`, `[{"a": 1}]`, []string{`/0: added {"a": 1}`}},
+		{"type_change", `{"a":{"b":1}}`, `{"a":[1]}`, []string{`/a: {"b":1} != [1]`}},
+		{"pointer_escapes", `{"a/b":1,"c~d":1}`, `{"a/b":2,"c~d":2}`, []string{`/a~1b: 1 != 2`, `/c~0d: 1 != 2`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs, err := Diff([]byte(tt.a), []byte(tt.b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, d := range diffs {
+				got = append(got, d.String())
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := Diff([]byte(`{}`), []byte(`{`)); err == nil {
+		t.Error("Expected error for invalid document")
+	}
+}
+
+func BenchmarkEqual(b *testing.B) {
+	a := []byte(createLargeArray(1000))
+	c := append([]byte(nil), a...)
+	b.SetBytes(int64(len(a)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !Equal(a, c) {
+			b.Fatal("Expected documents to be equal")
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package simdjson
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/parser"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// document is a validated JSON document and its tokens. Functions that
+// rewrite or compare documents walk the tokens and copy the values they
+// leave alone as written, instead of decoding them.
+type document struct {
+	data    []byte
+	tokens  []scanner.Token
+	scratch []byte
+}
+
+// parseDocument validates data and tokenizes it with s. The document must
+// be released before s is reused.
+func parseDocument(s *scanner.Scanner, data []byte) (*document, error) {
+	if err := ValidateWithError(data); err != nil {
+		return nil, err
+	}
+	tokens, err := s.SimpleTokenize(data)
+	if err != nil {
+		return nil, err
+	}
+	return &document{data: data, tokens: tokens}, nil
+}
+
+func (d *document) release() {
+	scanner.PutTokenSlice(d.tokens)
+	d.tokens = nil
+}
+
+func (d *document) kind(pos int) scanner.TokenType {
+	return d.tokens[pos].Type
+}
+
+// skip returns the position of the token after the value at pos.
+func (d *document) skip(pos int) int {
+	next, err := scanner.SkipTokens(d.tokens, pos)
+	if err != nil {
+		// The document was validated, so the brackets match
+		panic(err)
+	}
+	return next
+}
+
+// raw returns the value at pos as written, including any whitespace
+// inside it.
+func (d *document) raw(pos int) []byte {
+	return d.data[d.tokens[pos].Start:d.tokens[d.skip(pos)-1].End]
+}
+
+// members calls fn with the key and value positions of each member of the
+// object at pos, in document order.
+func (d *document) members(pos int, fn func(key, val int)) {
+	for pos++; d.kind(pos) != scanner.TokenObjectEnd; {
+		fn(pos, pos+2)
+		if pos = d.skip(pos + 2); d.kind(pos) == scanner.TokenComma {
+			pos++
+		}
+	}
+}
+
+// elements calls fn with the position of each element of the array at pos.
+func (d *document) elements(pos int, fn func(elem int)) {
+	for pos++; d.kind(pos) != scanner.TokenArrayEnd; {
+		fn(pos)
+		if pos = d.skip(pos); d.kind(pos) == scanner.TokenComma {
+			pos++
+		}
+	}
+}
+
+// str returns the unescaped contents of the string at pos. The result is
+// only valid until the next call.
+func (d *document) str(pos int) []byte {
+	tok := d.tokens[pos]
+	raw := d.data[tok.Start+1 : tok.End-1]
+	if !tok.HasEscape() {
+		return raw
+	}
+	// Escapes were checked by the validator
+	d.scratch, _ = parser.AppendUnescaped(d.scratch[:0], raw)
+	return d.scratch
+}
+
+// child returns the position of the value st selects in the value at pos,
+// or -1 if there is none, and the number of elements if pos is an array.
+// Of duplicate keys the last is selected, as in decoding.
+func (d *document) child(pos int, st editStep) (int, int) {
+	found, n := -1, 0
+	switch d.kind(pos) {
+	case scanner.TokenObjectBegin:
+		if st.index < 0 {
+			d.members(pos, func(k, v int) {
+				if string(d.str(k)) == st.key {
+					found = v
+				}
+			})
+		}
+	case scanner.TokenArrayBegin:
+		d.elements(pos, func(elem int) {
+			if n == st.index {
+				found = elem
+			}
+			n++
+		})
+	}
+	return found, n
+}
+
+// keyIndex maps each key of the object at pos to the position of its
+// value. Of duplicate keys the last wins, as in decoding.
+func (d *document) keyIndex(pos int) map[string]int {
+	keys := make(map[string]int)
+	d.members(pos, func(k, v int) {
+		keys[string(d.str(k))] = v
+	})
+	return keys
+}
+
+// equalValues reports whether the value at apos in a and the value at bpos
+// in b are the same JSON value: objects with the same members in any
+// order, arrays with equal elements, strings with the same contents after
+// unescaping, and numbers with the same decimal value however written.
+func equalValues(a *document, apos int, b *document, bpos int) bool {
+	if a.kind(apos) != b.kind(bpos) {
+		return false
+	}
+	switch a.kind(apos) {
+	case scanner.TokenObjectBegin:
+		if equal, ok := equalMembersInOrder(a, apos, b, bpos); ok {
+			return equal
+		}
+		ak, bk := a.keyIndex(apos), b.keyIndex(bpos)
+		if len(ak) != len(bk) {
+			return false
+		}
+		for key, av := range ak {
+			bv, ok := bk[key]
+			if !ok || !equalValues(a, av, b, bv) {
+				return false
+			}
+		}
+		return true
+	case scanner.TokenArrayBegin:
+		var ae, be []int
+		a.elements(apos, func(e int) { ae = append(ae, e) })
+		b.elements(bpos, func(e int) { be = append(be, e) })
+		if len(ae) != len(be) {
+			return false
+		}
+		for i := range ae {
+			if !equalValues(a, ae[i], b, be[i]) {
+				return false
+			}
+		}
+		return true
+	case scanner.TokenString:
+		// Copy the first string: a and b may be the same document,
+		// sharing scratch space
+		as := string(a.str(apos))
+		return as == string(b.str(bpos))
+	case scanner.TokenNumber:
+		return equalNumbers(a.raw(apos), b.raw(bpos))
+	}
+	return true
+}
+
+// equalMembersInOrder compares objects whose keys are written the same
+// way in the same order, as they usually are, without indexing their keys.
+// ok is false if the keys differ, leaving the objects to be compared by
+// key. (With duplicate keys, only the last of which counts, this may find
+// differences in values that do not count.)
+func equalMembersInOrder(a *document, apos int, b *document, bpos int) (equal, ok bool) {
+	var ak, bk []int
+	a.members(apos, func(k, _ int) { ak = append(ak, k) })
+	b.members(bpos, func(k, _ int) { bk = append(bk, k) })
+	if len(ak) != len(bk) {
+		return false, false
+	}
+	for i := range ak {
+		if !bytes.Equal(a.raw(ak[i]), b.raw(bk[i])) {
+			return false, false
+		}
+	}
+	for i := range ak {
+		if !equalValues(a, ak[i]+2, b, bk[i]+2) {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+// equalNumbers compares two JSON numbers by their exact decimal values, so
+// 1, 1.0 and 10e-1 are equal and large integers are not rounded.
+func equalNumbers(x, y []byte) bool {
+	if bytes.Equal(x, y) {
+		return true
+	}
+	xneg, xdigits, xexp, ok := decimalParts(x)
+	if !ok {
+		return false
+	}
+	yneg, ydigits, yexp, ok := decimalParts(y)
+	if !ok {
+		return false
+	}
+	if len(xdigits) == 0 || len(ydigits) == 0 {
+		// -0 equals 0
+		return len(xdigits) == len(ydigits)
+	}
+	return xneg == yneg && xexp == yexp && bytes.Equal(xdigits, ydigits)
+}
+
+// decimalParts splits a JSON number into its sign, its significant digits
+// without leading or trailing zeros, and the exponent of the last of those
+// digits. ok is false if the exponent does not fit in an int.
+func decimalParts(num []byte) (neg bool, digits []byte, exp int, ok bool) {
+	if num[0] == '-' {
+		neg = true
+		num = num[1:]
+	}
+	mantissa := num
+	if i := bytes.IndexAny(num, "eE"); i >= 0 {
+		mantissa = num[:i]
+		e, err := strconv.Atoi(string(num[i+1:]))
+		if err != nil {
+			return false, nil, 0, false
+		}
+		exp = e
+	}
+	intPart, frac := mantissa, []byte(nil)
+	if i := bytes.IndexByte(mantissa, '.'); i >= 0 {
+		intPart, frac = mantissa[:i], mantissa[i+1:]
+	}
+	exp -= len(frac)
+	digits = append(append(digits, intPart...), frac...)
+
+	digits = bytes.TrimLeft(digits, "0")
+	trimmed := bytes.TrimRight(digits, "0")
+	exp += len(digits) - len(trimmed)
+	return neg, trimmed, exp, true
+}
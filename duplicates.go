@@ -0,0 +1,60 @@
+package simdjson
+
+import (
+	"reflect"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+var duplicatesType = reflect.TypeOf(map[string][]Value(nil))
+
+// newDuplicatesPlan returns the plan of map[string][]Value, which collects
+// every value of each key under Options.CollectDuplicateKeys and is an
+// ordinary map of arrays otherwise.
+func newDuplicatesPlan() decodeFunc {
+	plain := newMapPlan(duplicatesType)
+	return func(r *Reader, v reflect.Value) error {
+		if !r.dups {
+			return plain(r, v)
+		}
+		return decodeDuplicates(r, v)
+	}
+}
+
+func decodeDuplicates(r *Reader, v reflect.Value) error {
+	if r.Null() {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if r.peek() != scanner.TokenObjectBegin {
+		return r.typeError(v.Type())
+	}
+	r.pos++
+
+	// The keys of the object replace those already in the map rather than
+	// add to their values, as a key decoded into any other map would
+	p := (*map[string][]Value)(v.Addr().UnsafePointer())
+	m := make(map[string][]Value)
+	for r.More() {
+		key, err := r.keyString()
+		if err != nil {
+			return err
+		}
+		x, err := r.value()
+		if err != nil {
+			return err
+		}
+		m[key] = append(m[key], Value{x})
+	}
+	if err := r.EndObject(); err != nil {
+		return err
+	}
+	if *p == nil {
+		*p = m
+		return nil
+	}
+	for key, vals := range m {
+		(*p)[key] = vals
+	}
+	return nil
+}
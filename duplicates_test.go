@@ -0,0 +1,73 @@
+package simdjson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCollectDuplicateKeys(t *testing.T) {
+	opts := DefaultOptions()
+	opts.CollectDuplicateKeys = true
+	data := []byte(`{"Set-Cookie":"a=1","Host":"x","Set-Cookie":"b=2","n":{"k":1,"k":2},"Set-Cookie":null}`)
+
+	var m map[string][]Value
+	if err := UnmarshalWithOptions(data, &m, &opts); err != nil {
+		t.Fatal(err)
+	}
+	var cookies []interface{}
+	for _, v := range m["Set-Cookie"] {
+		cookies = append(cookies, v.Interface())
+	}
+	if expected := []interface{}{"a=1", "b=2", nil}; !reflect.DeepEqual(cookies, expected) {
+		t.Errorf("Expected %v, got %v", expected, cookies)
+	}
+	if len(m["Host"]) != 1 || m["Host"][0].Interface() != "x" {
+		t.Errorf("Expected one Host, got %v", m["Host"])
+	}
+	// Nested objects keep the last value
+	if k := m["n"][0].Get("k").Interface(); k != int64(2) {
+		t.Errorf("Expected the last nested value, got %v", k)
+	}
+
+	out, err := Marshal(m["Set-Cookie"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `["a=1","b=2",null]` {
+		t.Errorf("Expected the values to encode as their nodes, got %s", out)
+	}
+
+	// Keys replace those of a map decoded into
+	m = map[string][]Value{"Host": {{"old"}}, "Other": {{"kept"}}}
+	if err := UnmarshalWithOptions([]byte(`{"Host":"y","Host":"z"}`), &m, &opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(m["Host"]) != 2 || m["Host"][1].Interface() != "z" || len(m["Other"]) != 1 {
+		t.Errorf("Expected Host replaced and Other kept, got %v", m)
+	}
+
+	// Inside other types
+	var s struct {
+		Headers map[string][]Value `json:"headers"`
+	}
+	if err := UnmarshalWithOptions([]byte(`{"headers":{"a":1,"a":true}}`), &s, &opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Headers["a"]) != 2 || s.Headers["a"][1].Interface() != true {
+		t.Errorf("Expected both values of a, got %v", s.Headers)
+	}
+}
+
+func TestDuplicateKeysWithoutOption(t *testing.T) {
+	// Without the option the map holds arrays
+	var m map[string][]Value
+	if err := Unmarshal([]byte(`{"a":[1,"x"],"a":[2]}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m["a"]) != 1 || m["a"][0].Interface() != int64(2) {
+		t.Errorf("Expected the last array, got %v", m)
+	}
+	if err := Unmarshal([]byte(`{"a":1}`), &m); err == nil {
+		t.Error("Expected an error for a value that is not an array")
+	}
+}
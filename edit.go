@@ -0,0 +1,311 @@
+package simdjson
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/jsonenc"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// An Editor sets and deletes values in a JSON document and returns the
+// edited document. New values are encoded with Marshal and spliced in;
+// everything else, whitespace included, is copied from the input as
+// written, so editing one field of a large document neither decodes nor
+// re-encodes the rest. Each edit copies and re-tokenizes the document.
+//
+// In a document indented across lines, new values are indented to match:
+// the unit of indentation is taken from the first container whose members
+// start on lines of their own, and new members go on lines of their own at
+// the depth of their siblings, so editing a pretty-printed config file
+// changes only the lines edited. Documents on one line get values as
+// compact as Marshal writes them.
+//
+// Paths name a value by its member keys separated by dots and array
+// indexes in brackets, as in "users[2].name". The empty path is the whole
+// document.
+type Editor struct {
+	doc    document
+	owned  bool   // doc.data is a copy the Editor may modify
+	indent string // unit of indentation, empty if the document has none
+}
+
+// NewEditor returns an Editor for data, which must be valid JSON. data is
+// not modified.
+func NewEditor(data []byte) (*Editor, error) {
+	if err := ValidateWithError(data); err != nil {
+		return nil, err
+	}
+	e := &Editor{doc: document{data: data}}
+	if err := e.tokenize(); err != nil {
+		return nil, err
+	}
+	e.indent = e.detectIndent()
+	return e, nil
+}
+
+// Bytes returns the document as edited so far. It must not be modified and
+// is only valid until the next edit.
+func (e *Editor) Bytes() []byte {
+	return e.doc.data
+}
+
+// Set sets the value at path to the encoding of value. A missing object
+// member is added at the end of its object, along with any objects leading
+// to it, and an index one past the end of an array appends to it.
+func (e *Editor) Set(path string, value interface{}) error {
+	steps, err := parseEditPath(path)
+	if err != nil {
+		return err
+	}
+	raw, err := Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	pos, n := 0, 0
+	for ; n < len(steps); n++ {
+		child, _ := e.doc.child(pos, steps[n])
+		if child < 0 {
+			break
+		}
+		pos = child
+	}
+	d := &e.doc
+	if n == len(steps) {
+		start := d.tokens[pos].Start
+		return e.splice(start, d.tokens[d.skip(pos)-1].End, e.format(raw, e.lineIndent(start)))
+	}
+
+	// Build the missing objects from the inside out
+	for i := len(steps) - 1; i > n; i-- {
+		if steps[i].index >= 0 {
+			return errors.New("path not found: " + path)
+		}
+		raw = append(appendKey([]byte{'{'}, steps[i].key), append(raw, '}')...)
+	}
+	st := steps[n]
+	end := d.skip(pos) - 1
+	empty := end == pos+1
+
+	// An indented member goes on a line of its own, at the depth of the
+	// last one or one deeper than its container if it is the first
+	var insert []byte
+	outer := e.lineIndent(d.tokens[pos].Start)
+	inner := outer + e.indent
+	if !empty {
+		insert = []byte{','}
+		inner = e.lineIndent(d.tokens[end-1].Start)
+	}
+	if e.indent != "" {
+		insert = append(append(insert, '\n'), inner...)
+	}
+	raw = e.format(raw, inner)
+	switch {
+	case d.kind(pos) == scanner.TokenObjectBegin && st.index < 0:
+		insert = appendKey(insert, st.key)
+		if e.indent != "" {
+			insert = append(insert, ' ')
+		}
+		insert = append(insert, raw...)
+	case d.kind(pos) == scanner.TokenArrayBegin && st.index >= 0:
+		if _, length := e.doc.child(pos, st); st.index != length {
+			return errors.New("path not found: " + path)
+		}
+		insert = append(insert, raw...)
+	default:
+		return errors.New("path not found: " + path)
+	}
+	// Insert after the last member or element, before any whitespace. An
+	// empty indented container is rewritten to hold the member on its own
+	// line.
+	at := d.tokens[end].Start
+	switch {
+	case !empty:
+		at = d.tokens[end-1].End
+	case e.indent != "":
+		insert = append(append(insert, '\n'), outer...)
+		return e.splice(d.tokens[pos].End, at, insert)
+	}
+	return e.splice(at, at, insert)
+}
+
+// Delete removes the object member or array element at path, along with
+// the comma that separates it from the next or previous one. Deleting a
+// path that does not exist does nothing.
+func (e *Editor) Delete(path string) error {
+	steps, err := parseEditPath(path)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return errors.New("cannot delete the whole document")
+	}
+
+	// Loop so that no duplicate of a deleted key is left behind
+	for key := steps[len(steps)-1].index < 0; ; {
+		pos := 0
+		for _, st := range steps {
+			if pos, _ = e.doc.child(pos, st); pos < 0 {
+				return nil
+			}
+		}
+
+		d := &e.doc
+		start, end := pos, d.skip(pos)
+		if key {
+			start -= 2 // the key and colon
+		}
+		var from, to uint32
+		switch {
+		case d.kind(end) == scanner.TokenComma:
+			from, to = d.tokens[start].Start, d.tokens[end+1].Start
+		case d.kind(start-1) == scanner.TokenComma:
+			from, to = d.tokens[start-1].Start, d.tokens[end-1].End
+		case e.indent != "":
+			// The only member: leave an empty indented container as {}
+			// or [] rather than on lines of its own
+			from, to = d.tokens[start-1].End, d.tokens[end].Start
+		default:
+			from, to = d.tokens[start].Start, d.tokens[end-1].End
+		}
+		if err := e.splice(from, to, nil); err != nil || !key {
+			return err
+		}
+	}
+}
+
+// splice replaces data[start:end] with raw and re-tokenizes.
+func (e *Editor) splice(start, end uint32, raw []byte) error {
+	if !e.owned {
+		e.doc.data = bytes.Clone(e.doc.data)
+		e.owned = true
+	}
+	e.doc.data = slices.Replace(e.doc.data, int(start), int(end), raw...)
+	return e.tokenize()
+}
+
+func (e *Editor) tokenize() error {
+	s := scanner.New()
+	defer s.Release()
+	tokens, err := s.SimpleTokenize(e.doc.data)
+	if err != nil {
+		return err
+	}
+	// The tokens live as long as the Editor, so they are not pooled
+	e.doc.tokens = append(e.doc.tokens[:0], tokens...)
+	scanner.PutTokenSlice(tokens)
+	return nil
+}
+
+// detectIndent returns the unit of indentation of the document: the
+// indentation that the line of the first member of the first non-empty
+// container adds to the line of the container, if the member starts a line.
+func (e *Editor) detectIndent() string {
+	d := &e.doc
+	for pos := range d.tokens {
+		switch d.kind(pos) {
+		case scanner.TokenObjectBegin, scanner.TokenArrayBegin:
+		default:
+			continue
+		}
+		if pos+1 >= len(d.tokens) || d.skip(pos) == pos+2 {
+			continue
+		}
+		between := d.data[d.tokens[pos].End:d.tokens[pos+1].Start]
+		if bytes.IndexByte(between, '\n') < 0 {
+			return ""
+		}
+		outer, inner := e.lineIndent(d.tokens[pos].Start), e.lineIndent(d.tokens[pos+1].Start)
+		if len(inner) <= len(outer) || !strings.HasPrefix(inner, outer) {
+			return ""
+		}
+		return inner[len(outer):]
+	}
+	return ""
+}
+
+// lineIndent returns the spaces and tabs that start the line holding the
+// byte at offset.
+func (e *Editor) lineIndent(offset uint32) string {
+	data := e.doc.data
+	start := bytes.LastIndexByte(data[:offset], '\n') + 1
+	end := start
+	for end < len(data) && (data[end] == ' ' || data[end] == '\t') {
+		end++
+	}
+	return string(data[start:end])
+}
+
+// format returns the compact encoding raw indented in the document's style
+// for a value on a line indented by prefix, or raw as it is if the
+// document is not indented.
+func (e *Editor) format(raw []byte, prefix string) []byte {
+	if e.indent == "" {
+		return raw
+	}
+	var b bytes.Buffer
+	if err := IndentStream(bytes.NewReader(raw), &b, e.indent); err != nil {
+		return raw
+	}
+	// Strings hold no raw newlines, so every newline is between tokens
+	out := bytes.TrimSuffix(b.Bytes(), []byte{'\n'})
+	return bytes.ReplaceAll(out, []byte{'\n'}, []byte("\n"+prefix))
+}
+
+// appendKey appends key, quoted as Marshal quotes it, and a colon.
+func appendKey(dst []byte, key string) []byte {
+	dst = append(dst, '"')
+	dst = jsonenc.AppendString(dst, key, true)
+	return append(dst, '"', ':')
+}
+
+// An editStep selects an object member by key or, if index is not -1, an
+// array element.
+type editStep struct {
+	key   string
+	index int
+}
+
+// parseEditPath splits an Editor path into steps.
+func parseEditPath(path string) ([]editStep, error) {
+	var steps []editStep
+	for p := path; p != ""; {
+		if p[0] == '[' {
+			n := strings.IndexByte(p, ']')
+			if n < 0 {
+				return nil, errors.New("invalid path " + strconv.Quote(path) + ": missing ]")
+			}
+			i, err := strconv.Atoi(p[1:n])
+			if err != nil || i < 0 {
+				return nil, errors.New("invalid path " + strconv.Quote(path) + ": bad index " + strconv.Quote(p[1:n]))
+			}
+			steps = append(steps, editStep{index: i})
+			p = p[n+1:]
+		} else {
+			n := strings.IndexAny(p, ".[")
+			if n < 0 {
+				n = len(p)
+			}
+			if n == 0 {
+				return nil, errors.New("invalid path " + strconv.Quote(path) + ": empty member name")
+			}
+			steps = append(steps, editStep{key: p[:n], index: -1})
+			p = p[n:]
+		}
+
+		switch {
+		case p == "" || p[0] == '[':
+		case p[0] == '.' && len(p) > 1 && p[1] != '.' && p[1] != '[':
+			p = p[1:]
+		case p[0] == '.':
+			return nil, errors.New("invalid path " + strconv.Quote(path) + ": empty member name")
+		default:
+			return nil, errors.New("invalid path " + strconv.Quote(path) + ": unexpected " + strconv.QuoteRune(rune(p[0])))
+		}
+	}
+	return steps, nil
+}
@@ -0,0 +1,194 @@
+package simdjson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEditorSet(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		path     string
+		value    interface{}
+		expected string
+	}{
+		{"replace member", `{"a": {"b": 1}, "c": [1, 2]}`, "a.b", "x", `{"a": {"b": "x"}, "c": [1, 2]}`},
+		{"replace element", `{"a": {"b": 1}, "c": [1, 2]}`, "c[1]", map[string]int{"d": 3}, `{"a": {"b": 1}, "c": [1, {"d":3}]}`},
+		{"replace root", ` [1] `, "", true, ` true `},
+		{"add member", "{\n  \"a\": 1\n}", "b", 2, "{\n  \"a\": 1,\n  \"b\": 2\n}"},
+		{"add to empty object", `{ }`, "a", 1, `{ "a":1}`},
+		{"add nested objects", `{"a": {}}`, "a.b.c", 1, `{"a": {"b":{"c":1}}}`},
+		{"append element", `[1, 2]`, "[2]", 3, `[1, 2,3]`},
+		{"append to empty array", `{"a": []}`, "a[0]", "x", `{"a": ["x"]}`},
+		{"escaped key", `{"a\u0062": 1}`, "ab", 2, `{"a\u0062": 2}`},
+		{"last duplicate", `{"a": 1, "a": 2}`, "a", 3, `{"a": 1, "a": 3}`},
+		{"new key quoted", `{}`, "<k>", 1, `{"\u003ck\u003e":1}`},
+		{"append object", `[1]`, "[1].a", 1, `[1,{"a":1}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEditor([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Set(tt.path, tt.value); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := string(e.Bytes()); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEditorDelete(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		path     string
+		expected string
+	}{
+		{"first member", `{"a": 1, "b": 2}`, "a", `{"b": 2}`},
+		{"last member", `{"a": 1, "b": 2}`, "b", `{"a": 1}`},
+		{"only member", `{ "a": 1 }`, "a", `{  }`},
+		{"middle element", "[\n  1,\n  2,\n  3\n]", "[1]", "[\n  1,\n  3\n]"},
+		{"nested", `{"users": [{"id": 1}, {"id": 2}]}`, "users[1].id", `{"users": [{"id": 1}, {}]}`},
+		{"duplicates", `{"a": 1, "b": 2, "a": 3}`, "a", `{"b": 2}`},
+		{"missing", `{"a": 1}`, "b.c", `{"a": 1}`},
+		{"out of range", `[1]`, "[3]", `[1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEditor([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Delete(tt.path); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := string(e.Bytes()); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEditorIndent(t *testing.T) {
+	config := `{
+    "name": "svc",
+    "spec": {
+        "replicas": 1,
+        "ports": [
+            80
+        ],
+        "labels": {}
+    }
+}
+`
+	tests := []struct {
+		name     string
+		input    string
+		edit     func(e *Editor) error
+		expected string
+	}{
+		{"replace scalar", config, func(e *Editor) error { return e.Set("spec.replicas", 3) },
+			strings.Replace(config, `"replicas": 1`, `"replicas": 3`, 1)},
+		{"replace with object", config, func(e *Editor) error { return e.Set("name", map[string]int{"a": 1}) },
+			strings.Replace(config, `"name": "svc"`, "\"name\": {\n        \"a\": 1\n    }", 1)},
+		{"add member", config, func(e *Editor) error { return e.Set("spec.paused", false) },
+			strings.Replace(config, "\"labels\": {}\n", "\"labels\": {},\n        \"paused\": false\n", 1)},
+		{"append element", config, func(e *Editor) error { return e.Set("spec.ports[1]", 443) },
+			strings.Replace(config, "80\n", "80,\n            443\n", 1)},
+		{"add to empty object", config, func(e *Editor) error { return e.Set("spec.labels.tier", "web") },
+			strings.Replace(config, `"labels": {}`, "\"labels\": {\n            \"tier\": \"web\"\n        }", 1)},
+		{"add nested objects", config, func(e *Editor) error { return e.Set("meta.owner.team", "infra") },
+			strings.Replace(config, "    }\n}", "    },\n    \"meta\": {\n        \"owner\": {\n            \"team\": \"infra\"\n        }\n    }\n}", 1)},
+		{"delete member", config, func(e *Editor) error { return e.Delete("spec.labels") },
+			strings.Replace(config, ",\n        \"labels\": {}", "", 1)},
+		{"delete only element", config, func(e *Editor) error { return e.Delete("spec.ports[0]") },
+			strings.Replace(config, "[\n            80\n        ]", "[]", 1)},
+		{"tabs", "[\n\t{\n\t\t\"a\": 1\n\t}\n]", func(e *Editor) error { return e.Set("[0].b", []int{2}) },
+			"[\n\t{\n\t\t\"a\": 1,\n\t\t\"b\": [\n\t\t\t2\n\t\t]\n\t}\n]"},
+		{"first container on one line", "{\"a\": {\n  \"b\": 1\n}}", func(e *Editor) error { return e.Set("c", 2) },
+			"{\"a\": {\n  \"b\": 1\n},\"c\":2}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEditor([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.edit(e); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := string(e.Bytes()); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEditorSequence(t *testing.T) {
+	input := []byte(`{"name": "svc", "replicas": 1, "ports": [80, 443]}`)
+	e, err := NewEditor(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []func() error{
+		func() error { return e.Set("replicas", 3) },
+		func() error { return e.Delete("ports[0]") },
+		func() error { return e.Set("ports[1]", 8443) },
+		func() error { return e.Set("labels.tier", "web") },
+		func() error { return e.Delete("name") },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("Step %d: unexpected error: %v", i, err)
+		}
+	}
+
+	expected := `{"replicas": 3, "ports": [443,8443],"labels":{"tier":"web"}}`
+	if got := string(e.Bytes()); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+	if string(input) != `{"name": "svc", "replicas": 1, "ports": [80, 443]}` {
+		t.Errorf("Expected the input unchanged, got %s", input)
+	}
+}
+
+func TestEditorErrors(t *testing.T) {
+	if _, err := NewEditor([]byte(`{"a":`)); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+
+	e, err := NewEditor([]byte(`{"a": [1], "s": "x"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"a[5]", "a[2].b", "s.b", "b[0]", "a.b", "x[0].y"} {
+		if err := e.Set(path, 1); err == nil {
+			t.Errorf("Expected Set(%q) to fail", path)
+		}
+	}
+	for _, path := range []string{"a[", "a[-1]", "a..b", ".a", "a.", "a[0]b", "a.[0]"} {
+		if err := e.Set(path, 1); err == nil {
+			t.Errorf("Expected invalid path %q to fail", path)
+		}
+		if err := e.Delete(path); err == nil {
+			t.Errorf("Expected Delete of invalid path %q to fail", path)
+		}
+	}
+	if err := e.Delete(""); err == nil {
+		t.Error("Expected deleting the whole document to fail")
+	}
+	if err := e.Set("a", make(chan int)); err == nil {
+		t.Error("Expected an unsupported value to fail")
+	}
+	if got := string(e.Bytes()); got != `{"a": [1], "s": "x"}` {
+		t.Errorf("Expected failed edits to change nothing, got %s", got)
+	}
+}
@@ -0,0 +1,220 @@
+package simdjson
+
+import (
+	"bytes"
+	"io"
+	"slices"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// elementChunk is how much DecodeArrayElements reads at a time.
+const elementChunk = 64 << 10
+
+// expectArray is what DecodeArrayElements expects the input to start with.
+const expectArray = "'['"
+
+// DecodeArrayElements reads a JSON array from the input and calls fn once
+// per element, in order, with a Decoder whose input is just that element,
+// so fn decodes it with dec.Decode or skips it by not calling Decode. Only
+// the element being decoded is held in memory, so a huge top-level array
+// can be processed as it is read. dec is only valid during the call to fn.
+//
+// As with Decode, the array must be the whole of the input. The grammar
+// between the elements is checked as they are found, failing with a
+// *SyntaxError; the elements themselves are only checked by Decode, whose
+// error offsets count from the start of the element. An error from fn
+// stops reading and is returned as is. Input of only whitespace returns
+// io.EOF. dec.DecodeArrayElements reads the arrays nested in an element
+// likewise.
+func (d *Decoder) DecodeArrayElements(fn func(dec *Decoder) error) error {
+	s := scanner.New()
+	defer s.Release()
+
+	ar := arrayReader{fn: fn, elemStart: -1}
+	r := d.r
+	if r == nil {
+		// The Decoder of an element passed to fn reads the element
+		r = bytes.NewReader(d.buf)
+	} else {
+		ar.buf = d.buf[:0]
+		defer func() { d.buf = ar.buf[:0] }()
+	}
+	for {
+		ar.buf = slices.Grow(ar.buf, elementChunk)
+		start := len(ar.buf)
+		n, err := r.Read(ar.buf[start : start+elementChunk])
+		if n > 0 {
+			ar.buf = ar.buf[:start+n]
+			chunk := ar.buf[start:]
+			s.Feed(chunk)
+			if err := ar.chunk(chunk, s.GetStructuralIndices(), s.Offset()); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if s.Finish() != nil {
+		return ar.syntaxErrorAt(nil, 0, scanner.ExpectQuote)
+	}
+	switch {
+	case !ar.started:
+		return io.EOF
+	case !ar.done:
+		expected := scanner.ExpectArrayNext
+		if ar.elemStart < 0 {
+			expected = scanner.ExpectValueOrEnd
+		}
+		return ar.syntaxErrorAt(nil, 0, expected)
+	}
+	return nil
+}
+
+// arrayReader is the state DecodeArrayElements carries from one chunk to
+// the next.
+type arrayReader struct {
+	fn  func(*Decoder) error
+	dec Decoder // handed to fn for each element
+
+	// buf holds the input from the start of the element being read, or
+	// from the chunk being read between elements; base is its offset in
+	// the stream
+	buf  []byte
+	base int64
+
+	started, done bool
+	depth         int   // nesting depth, 1 directly inside the array
+	inString      bool  // between the quotes of a string
+	elemStart     int64 // stream offset of the element being read, or -1
+	comma         bool  // a comma was read and no element since
+
+	// For locating errors: where the chunk starts and ends, the lines
+	// before it and the offset at which the current line starts
+	offset, end int64
+	lines       int
+	lineStart   int64
+}
+
+// chunk finds the elements in the next chunk of input, whose structural
+// indices are idx and which starts at offset in the stream, and passes
+// each one completed to fn.
+func (ar *arrayReader) chunk(chunk []byte, idx []uint32, offset int64) error {
+	ar.offset = offset
+	for _, i := range idx {
+		c := chunk[i]
+		pos := offset + int64(i)
+		if ar.inString {
+			// The closing quote, as indices are not emitted inside strings
+			ar.inString = false
+			continue
+		}
+		if ar.done {
+			return ar.syntaxErrorAt(chunk, int(i), scanner.ExpectEOF)
+		}
+		if !ar.started {
+			if c != '[' {
+				return ar.syntaxErrorAt(chunk, int(i), expectArray)
+			}
+			ar.started = true
+			ar.depth = 1
+			continue
+		}
+
+		if ar.depth > 1 {
+			switch c {
+			case '{', '[':
+				ar.depth++
+			case '}', ']':
+				ar.depth--
+			case '"':
+				ar.inString = true
+			}
+			continue
+		}
+		switch c {
+		case ',':
+			if ar.elemStart < 0 {
+				return ar.syntaxErrorAt(chunk, int(i), scanner.ExpectValue)
+			}
+			if err := ar.element(pos); err != nil {
+				return err
+			}
+			ar.comma = true
+		case ']':
+			if ar.elemStart >= 0 {
+				if err := ar.element(pos); err != nil {
+					return err
+				}
+			} else if ar.comma {
+				return ar.syntaxErrorAt(chunk, int(i), scanner.ExpectValue)
+			}
+			ar.done = true
+		default:
+			if ar.elemStart >= 0 {
+				return ar.syntaxErrorAt(chunk, int(i), scanner.ExpectArrayNext)
+			}
+			ar.elemStart = pos
+			ar.comma = false
+			switch c {
+			case '{', '[':
+				ar.depth++
+			case '"':
+				ar.inString = true
+			}
+		}
+	}
+	ar.next(chunk)
+	return nil
+}
+
+// element passes the element from elemStart up to end to fn.
+func (ar *arrayReader) element(end int64) error {
+	ar.dec.buf = ar.buf[ar.elemStart-ar.base : end-ar.base]
+	ar.elemStart = -1
+	err := ar.fn(&ar.dec)
+	ar.dec.buf = nil
+	return err
+}
+
+// next finishes with chunk, counting its lines and dropping the input
+// before the element being read from buf.
+func (ar *arrayReader) next(chunk []byte) {
+	ar.end = ar.offset + int64(len(chunk))
+	ar.lines += bytes.Count(chunk, []byte{'\n'})
+	if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+		ar.lineStart = ar.offset + int64(i) + 1
+	}
+
+	keep := ar.end
+	if ar.elemStart >= 0 {
+		keep = ar.elemStart
+	}
+	n := copy(ar.buf, ar.buf[keep-ar.base:])
+	ar.buf = ar.buf[:n]
+	ar.base = keep
+}
+
+// syntaxErrorAt returns a *SyntaxError at chunk[i]; chunk is nil at the
+// end of the input.
+func (ar *arrayReader) syntaxErrorAt(chunk []byte, i int, expected string) error {
+	offset := ar.end
+	line, lineStart := ar.lines+1, ar.lineStart
+	if chunk != nil {
+		offset = ar.offset + int64(i)
+		line += bytes.Count(chunk[:i], []byte{'\n'})
+		if j := bytes.LastIndexByte(chunk[:i], '\n'); j >= 0 {
+			lineStart = ar.offset + int64(j) + 1
+		}
+	}
+	return &SyntaxError{
+		Offset:   offset,
+		Line:     line,
+		Column:   int(offset-lineStart) + 1,
+		Expected: expected,
+	}
+}
@@ -0,0 +1,166 @@
+package simdjson
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecodeArrayElements(t *testing.T) {
+	doc := ` [ 1, "a,]\"[", {"b":[2,{"c":"}"}]}, [], null , -1.5e3,true ] `
+	expected := []interface{}{
+		int64(1), "a,]\"[",
+		map[string]interface{}{"b": []interface{}{int64(2), map[string]interface{}{"c": "}"}}},
+		[]interface{}{}, nil, -1500.0, true,
+	}
+	for name, r := range map[string]io.Reader{
+		"whole":    strings.NewReader(doc),
+		"one byte": iotest.OneByteReader(strings.NewReader(doc)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var got []interface{}
+			err := NewDecoder(r).DecodeArrayElements(func(dec *Decoder) error {
+				var v interface{}
+				err := dec.Decode(&v)
+				got = append(got, v)
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("Expected %v, got %v", expected, got)
+			}
+		})
+	}
+
+	n := 0
+	if err := NewDecoder(strings.NewReader(`[]`)).DecodeArrayElements(func(*Decoder) error {
+		n++
+		return nil
+	}); err != nil || n != 0 {
+		t.Errorf("Expected no elements, got %d (%v)", n, err)
+	}
+}
+
+func TestDecodeArrayElementsLarge(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	var doc bytes.Buffer
+	doc.WriteByte('[')
+	for i := 0; i < 50000; i++ {
+		if i > 0 {
+			doc.WriteString(",\n")
+		}
+		doc.WriteString(`{"id":` + strconv.Itoa(i) + `,"name":"item ` + strconv.Itoa(i) + `"}`)
+	}
+	doc.WriteByte(']')
+
+	dec := NewDecoder(&doc)
+	n, skipped := 0, 0
+	err := dec.DecodeArrayElements(func(dec *Decoder) error {
+		if n%2 == 1 {
+			// Elements not decoded are skipped
+			n++
+			skipped++
+			return nil
+		}
+		var it item
+		if err := dec.Decode(&it); err != nil {
+			return err
+		}
+		if it.ID != n || it.Name != "item "+strconv.Itoa(n) {
+			t.Fatalf("Expected item %d, got %+v", n, it)
+		}
+		n++
+		return nil
+	})
+	if err != nil || n != 50000 || skipped != 25000 {
+		t.Errorf("Expected 50000 elements, got %d (%v)", n, err)
+	}
+	if cap(dec.buf) > 2*elementChunk {
+		t.Errorf("Expected the buffer to hold about a chunk, got %d bytes", cap(dec.buf))
+	}
+}
+
+func TestDecodeArrayElementsNested(t *testing.T) {
+	var sums []int
+	err := NewDecoder(strings.NewReader(`[[1,2],[3],[]]`)).DecodeArrayElements(func(dec *Decoder) error {
+		sum := 0
+		sums = append(sums, 0)
+		return dec.DecodeArrayElements(func(dec *Decoder) error {
+			var n int
+			err := dec.Decode(&n)
+			sum += n
+			sums[len(sums)-1] = sum
+			return err
+		})
+	})
+	if err != nil || !reflect.DeepEqual(sums, []int{3, 3, 0}) {
+		t.Errorf("Expected sums [3 3 0], got %v (%v)", sums, err)
+	}
+}
+
+func TestDecodeArrayElementsErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		offset   int64
+		line     int
+		column   int
+		expected string
+	}{
+		{`{"a":1}`, 0, 1, 1, "'['"},
+		{`[1,]`, 3, 1, 4, "value"},
+		{`[,1]`, 1, 1, 2, "value"},
+		{"[1\n 2]", 4, 2, 2, "',' or ']'"},
+		{`[1`, 2, 1, 3, "',' or ']'"},
+		{`[1,`, 3, 1, 4, "value or ']'"},
+		{`["ab`, 4, 1, 5, "closing '\"'"},
+		{`[1] 2`, 4, 1, 5, "end of input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			err := NewDecoder(strings.NewReader(tt.input)).DecodeArrayElements(func(*Decoder) error { return nil })
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("Expected a *SyntaxError, got %v", err)
+			}
+			if se.Offset != tt.offset || se.Line != tt.line || se.Column != tt.column || se.Expected != tt.expected {
+				t.Errorf("Expected %s at offset %d (%d:%d), got %s at offset %d (%d:%d)",
+					tt.expected, tt.offset, tt.line, tt.column, se.Expected, se.Offset, se.Line, se.Column)
+			}
+		})
+	}
+
+	if err := NewDecoder(strings.NewReader(" \n")).DecodeArrayElements(nil); err != io.EOF {
+		t.Errorf("Expected io.EOF for no input, got %v", err)
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err := NewDecoder(strings.NewReader(`[1,2,3]`)).DecodeArrayElements(func(*Decoder) error {
+		n++
+		if n == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 2 {
+		t.Errorf("Expected the callback's error after 2 elements, got %v after %d", err, n)
+	}
+
+	err = NewDecoder(strings.NewReader(`[1, {"a" 1}]`)).DecodeArrayElements(func(dec *Decoder) error {
+		var v interface{}
+		return dec.Decode(&v)
+	})
+	if err == nil {
+		t.Errorf("Expected an invalid element to fail Decode, got %v", err)
+	}
+}
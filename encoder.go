@@ -1,34 +1,83 @@
 package simdjson
 
 import (
-	"encoding/base64"
+	"bytes"
+	"encoding"
 	"errors"
+	"io"
 	"math"
 	"reflect"
+	"sort"
 	"strconv"
-	"sync"
+	"time"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/jsonenc"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/pool"
 )
 
 type encoder struct {
 	buf    []byte
 	scratch [64]byte
-}
+	w      Writer // handed to MarshalerTo implementations
 
-var encoderPool = sync.Pool{
-	New: func() interface{} {
-		return &encoder{
-			buf: make([]byte, 0, 4096),
-		}
-	},
+	escapeHTML bool
+	nilAsEmpty bool
+	bytesFormat BytesFormat
+	naming     FieldNaming
+	timeFormat string
+	timeLoc    *time.Location
+
+	// Digits written after the point of floats, or -1 for the fewest
+	// that read back as the same float; see MarshalOptions.FloatPrecision
+	floatPrec int
+	trimZeros bool
+	nonFinite NonFiniteFloats
+
+	// With out set, arrays and maps write buf to out whenever it reaches
+	// flushSize bytes; see Encoder.SetFlushSize
+	out       io.Writer
+	flushSize int
 }
 
+var encoderPool = pool.New("encoder", func() *encoder {
+	e := &encoder{
+		buf: make([]byte, 0, 4096),
+	}
+	e.w.e = e
+	return e
+}, func(e *encoder) int {
+	return cap(e.buf)
+})
+
 func newEncoder() *encoder {
-	e := encoderPool.Get().(*encoder)
+	e := encoderPool.Get()
 	e.buf = e.buf[:0]
+	e.escapeHTML = true
+	e.nilAsEmpty = false
+	e.bytesFormat = BytesBase64
+	e.naming = GoFieldNames
+	e.timeFormat = ""
+	e.timeLoc = nil
+	e.floatPrec = -1
+	e.trimZeros = false
+	e.nonFinite = NonFiniteError
+	e.out = nil
 	return e
 }
 
+// flushFull writes the buffered output to out once it holds flushSize
+// bytes, when streaming.
+func (e *encoder) flushFull() error {
+	if e.out == nil || len(e.buf) < e.flushSize {
+		return nil
+	}
+	_, err := e.out.Write(e.buf)
+	e.buf = e.buf[:0]
+	return err
+}
+
 func (e *encoder) release() {
+	e.out = nil
 	if cap(e.buf) > 64*1024 {
 		e.buf = make([]byte, 0, 4096)
 	}
@@ -60,6 +109,24 @@ func (e *encoder) encode(v reflect.Value) error {
 		v = v.Elem()
 	}
 	
+	if c := codecFor(v.Type()); c != nil && c.marshal != nil {
+		return c.marshal(&e.w, v)
+	}
+
+	if a, ok := asAppender(v); ok {
+		buf, err := a.AppendJSON(e.buf)
+		if err != nil {
+			return err
+		}
+		e.buf = buf
+		return nil
+	}
+
+	// Generated encoders bypass reflection for the whole value
+	if v.Kind() == reflect.Struct && v.CanInterface() && v.Type().Implements(marshalerToType) {
+		return v.Interface().(MarshalerTo).MarshalJSONTo(&e.w)
+	}
+	
 	switch v.Kind() {
 	case reflect.Bool:
 		return e.encodeBool(v.Bool())
@@ -67,21 +134,37 @@ func (e *encoder) encode(v reflect.Value) error {
 		return e.encodeInt(v.Int())
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return e.encodeUint(v.Uint())
-	case reflect.Float32, reflect.Float64:
-		return e.encodeFloat(v.Float())
+	case reflect.Float32:
+		return e.encodeFloat(v.Float(), 32)
+	case reflect.Float64:
+		return e.encodeFloat(v.Float(), 64)
 	case reflect.String:
 		return e.encodeString(v.String())
 	case reflect.Slice:
+		if v.Type() == rawMessageType {
+			return e.encodeRaw(v.Bytes())
+		}
+		if v.IsNil() {
+			e.encodeNil(v.Kind(), v.Type().Elem().Kind() == reflect.Uint8)
+			return nil
+		}
 		if v.Type().Elem().Kind() == reflect.Uint8 {
-			// []byte - encode as base64 string
+			// []byte - encode as a string, base64 unless configured otherwise
 			return e.encodeBytes(v.Bytes())
 		}
 		return e.encodeArray(v)
 	case reflect.Array:
 		return e.encodeArray(v)
 	case reflect.Map:
+		if v.IsNil() {
+			e.encodeNil(v.Kind(), false)
+			return nil
+		}
 		return e.encodeMap(v)
 	case reflect.Struct:
+		if isSQLNull(v.Type()) {
+			return e.encodeSQLNull(v)
+		}
 		return e.encodeStruct(v)
 	case reflect.Interface:
 		if v.IsNil() {
@@ -94,6 +177,37 @@ func (e *encoder) encode(v reflect.Value) error {
 	}
 }
 
+// asAppender returns v as an Appender, if its type or, when v is
+// addressable, its pointer type implements one. The pointer is preferred
+// as it needs no copy of v boxed.
+func asAppender(v reflect.Value) (Appender, bool) {
+	t := v.Type()
+	switch {
+	case !v.CanInterface():
+	case v.CanAddr() && reflect.PointerTo(t).Implements(appenderType):
+		return v.Addr().Interface().(Appender), true
+	case t.Implements(appenderType):
+		return v.Interface().(Appender), true
+	}
+	return nil, false
+}
+
+// encodeNil writes a nil slice or map: null, as in encoding/json, or with
+// nilAsEmpty an empty array, object or, for a []byte not in BytesArray
+// format, string.
+func (e *encoder) encodeNil(kind reflect.Kind, bytes bool) {
+	switch {
+	case !e.nilAsEmpty:
+		e.buf = append(e.buf, "null"...)
+	case bytes && e.bytesFormat != BytesArray:
+		e.buf = append(e.buf, `""`...)
+	case kind == reflect.Map:
+		e.buf = append(e.buf, "{}"...)
+	default:
+		e.buf = append(e.buf, "[]"...)
+	}
+}
+
 func (e *encoder) encodeBool(b bool) error {
 	if b {
 		e.buf = append(e.buf, "true"...)
@@ -113,82 +227,34 @@ func (e *encoder) encodeUint(u uint64) error {
 	return nil
 }
 
-func (e *encoder) encodeFloat(f float64) error {
+func (e *encoder) encodeFloat(f float64, bitSize int) error {
 	if math.IsNaN(f) || math.IsInf(f, 0) {
+		if e.appendNonFinite(f) {
+			return nil
+		}
 		return errors.New("unsupported float value")
 	}
-	
-	// Use optimized float formatting
-	e.buf = strconv.AppendFloat(e.buf, f, 'g', -1, 64)
+	if e.floatPrec < 0 {
+		e.buf = jsonenc.AppendFloat(e.buf, f, bitSize)
+		return nil
+	}
+	e.buf = strconv.AppendFloat(e.buf, f, 'f', e.floatPrec, bitSize)
+	if e.trimZeros && e.floatPrec > 0 {
+		e.buf = bytes.TrimRight(e.buf, "0")
+		e.buf = bytes.TrimSuffix(e.buf, []byte{'.'})
+	}
 	return nil
 }
 
 func (e *encoder) encodeString(s string) error {
 	e.buf = append(e.buf, '"')
-	
-	// Fast path for strings without special characters
-	if !needsEscape(s) {
-		e.buf = append(e.buf, s...)
-		e.buf = append(e.buf, '"')
-		return nil
-	}
-	
-	// Slow path with escaping
-	e.buf = appendEscapedString(e.buf, s)
+	e.buf = jsonenc.AppendString(e.buf, s, e.escapeHTML)
 	e.buf = append(e.buf, '"')
 	return nil
 }
 
-func needsEscape(s string) bool {
-	for _, r := range s {
-		if r < 0x20 || r == '"' || r == '\\' {
-			return true
-		}
-	}
-	return false
-}
-
-func appendEscapedString(dst []byte, s string) []byte {
-	for _, r := range s {
-		switch r {
-		case '"':
-			dst = append(dst, '\\', '"')
-		case '\\':
-			dst = append(dst, '\\', '\\')
-		case '\b':
-			dst = append(dst, '\\', 'b')
-		case '\f':
-			dst = append(dst, '\\', 'f')
-		case '\n':
-			dst = append(dst, '\\', 'n')
-		case '\r':
-			dst = append(dst, '\\', 'r')
-		case '\t':
-			dst = append(dst, '\\', 't')
-		default:
-			if r < 0x20 {
-				dst = append(dst, '\\', 'u')
-				dst = append(dst, "0000"...)
-				hex := strconv.FormatInt(int64(r), 16)
-				copy(dst[len(dst)-len(hex):], hex)
-			} else {
-				dst = append(dst, string(r)...)
-			}
-		}
-	}
-	return dst
-}
-
 func (e *encoder) encodeBytes(b []byte) error {
-	e.buf = append(e.buf, '"')
-	
-	// Use base64 encoding for byte slices
-	encodedLen := base64.StdEncoding.EncodedLen(len(b))
-	start := len(e.buf)
-	e.buf = append(e.buf, make([]byte, encodedLen)...)
-	base64.StdEncoding.Encode(e.buf[start:], b)
-	
-	e.buf = append(e.buf, '"')
+	e.buf = appendBytes(e.buf, b, e.bytesFormat, e.escapeHTML)
 	return nil
 }
 
@@ -203,80 +269,121 @@ func (e *encoder) encodeArray(v reflect.Value) error {
 		if err := e.encode(v.Index(i)); err != nil {
 			return err
 		}
+		if err := e.flushFull(); err != nil {
+			return err
+		}
 	}
 	
 	e.buf = append(e.buf, ']')
 	return nil
 }
 
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// mapEntry is a map value with its key formatted as a JSON object key.
+type mapEntry struct {
+	key string
+	val reflect.Value
+}
+
+// encodeMap writes the members of a map sorted by key, as encoding/json
+// does. Keys implementing encoding.TextMarshaler are written as the text
+// they marshal to, other keys of string kind as they are, and integer keys
+// in decimal.
 func (e *encoder) encodeMap(v reflect.Value) error {
-	if v.Type().Key().Kind() != reflect.String {
-		return errors.New("map key must be string")
-	}
-	
 	e.buf = append(e.buf, '{')
+	if _, err := e.encodeMembers(v, true, nil); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, '}')
+	return nil
+}
+
+// encodeMembers writes the entries of map v as object members sorted by
+// key, except those whose key is in skip, and reports whether it wrote
+// none. first tells whether a member has been written before them.
+func (e *encoder) encodeMembers(v reflect.Value, first bool, skip map[string]bool) (bool, error) {
+	kt := v.Type().Key()
+	text := kt.Implements(textMarshalerType)
+	switch kt.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		if !text {
+			return first, errors.New("unsupported map key type: " + kt.String())
+		}
+	}
+
+	entries := make([]mapEntry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key(), text)
+		if err != nil {
+			return first, err
+		}
+		if skip[key] {
+			continue
+		}
+		entries = append(entries, mapEntry{key, iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
 	
-	keys := v.MapKeys()
-	for i, key := range keys {
-		if i > 0 {
+	for _, entry := range entries {
+		if !first {
 			e.buf = append(e.buf, ',')
 		}
+		first = false
 		
 		// Encode key
-		if err := e.encodeString(key.String()); err != nil {
-			return err
+		if err := e.encodeString(entry.key); err != nil {
+			return first, err
 		}
 		
 		e.buf = append(e.buf, ':')
 		
 		// Encode value
-		if err := e.encode(v.MapIndex(key)); err != nil {
-			return err
+		if err := e.encode(entry.val); err != nil {
+			return first, err
+		}
+		if err := e.flushFull(); err != nil {
+			return first, err
 		}
 	}
-	
-	e.buf = append(e.buf, '}')
-	return nil
+	return first, nil
+}
+
+// mapKey formats a map key as encodeMap writes it; text reports whether
+// the key type implements encoding.TextMarshaler.
+func mapKey(k reflect.Value, text bool) (string, error) {
+	switch {
+	case text:
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", nil
+		}
+		b, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	case k.Kind() == reflect.String:
+		return k.String(), nil
+	case k.CanInt():
+		return strconv.FormatInt(k.Int(), 10), nil
+	}
+	return strconv.FormatUint(k.Uint(), 10), nil
 }
 
 func (e *encoder) encodeStruct(v reflect.Value) error {
 	e.buf = append(e.buf, '{')
 	
-	typ := v.Type()
+	info := cachedStruct(v.Type(), e.naming)
 	first := true
-	
-	for i := 0; i < v.NumField(); i++ {
-		field := v.Field(i)
-		structField := typ.Field(i)
-		
-		// Skip unexported fields
-		if structField.PkgPath != "" {
-			continue
-		}
+	for _, f := range info.fields {
+		field, ok := f.value(v)
 		
-		// Handle JSON tags
-		tag := structField.Tag.Get("json")
-		if tag == "-" {
-			continue
-		}
-		
-		name := structField.Name
-		omitempty := false
-		
-		if tag != "" {
-			// Parse tag
-			if idx := findComma(tag); idx != -1 {
-				name = tag[:idx]
-				if tag[idx+1:] == "omitempty" {
-					omitempty = true
-				}
-			} else {
-				name = tag
-			}
-		}
-		
-		// Skip empty fields if omitempty
-		if omitempty && isEmptyValue(field) {
+		// Skip empty fields if omitempty, and those of nil inline
+		// pointers
+		if !ok || f.omitempty && isEmptyValue(field) {
 			continue
 		}
 		
@@ -286,18 +393,43 @@ func (e *encoder) encodeStruct(v reflect.Value) error {
 		first = false
 		
 		// Encode field name
-		if err := e.encodeString(name); err != nil {
+		if err := e.encodeString(f.name); err != nil {
 			return err
 		}
 		
 		e.buf = append(e.buf, ':')
 		
 		// Encode field value
+		if f.hasBytesFormat {
+			format := e.bytesFormat
+			e.bytesFormat = f.bytesFormat
+			e.encode(field)
+			e.bytesFormat = format
+			continue
+		}
+		if f.floatPrec >= 0 {
+			prec := e.floatPrec
+			e.floatPrec = f.floatPrec
+			err := e.encode(field)
+			e.floatPrec = prec
+			if err != nil {
+				return err
+			}
+			continue
+		}
 		if err := e.encode(field); err != nil {
 			return err
 		}
 	}
 	
+	// Members captured by the unknown field follow, unless a field has
+	// their name
+	if info.unknown >= 0 {
+		if _, err := e.encodeMembers(v.Field(info.unknown), first, info.known); err != nil {
+			return err
+		}
+	}
+	
 	e.buf = append(e.buf, '}')
 	return nil
 }
@@ -316,6 +448,8 @@ func isEmptyValue(v reflect.Value) bool {
 		return v.Float() == 0
 	case reflect.Interface, reflect.Ptr:
 		return v.IsNil()
+	case reflect.Struct:
+		return v.Type().Implements(nullableType) && v.Interface().(nullable).absent()
 	}
 	return false
 }
@@ -325,5 +459,5 @@ func escapeStringSIMD(dst, src []byte) int {
 	// This would use SIMD to scan for characters that need escaping
 	// and process multiple bytes at once
 	// For now, fallback to scalar implementation
-	return len(appendEscapedString(dst, string(src)))
+	return len(jsonenc.AppendString(dst, string(src), false))
 }
\ No newline at end of file
@@ -0,0 +1,28 @@
+package simdjson
+
+import (
+	"github.com/biggeezerdevelopment/simdjson-go/internal/jsonenc"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// ValidateUTF8 reports whether b is entirely valid UTF-8, using the same
+// SIMD kernel the parser validates strings with. It is useful on its own
+// for checking log lines or protocol fields before they are framed.
+func ValidateUTF8(b []byte) bool {
+	s := scanner.New()
+	defer s.Release()
+
+	return s.SIMDValidateUTF8(b)
+}
+
+// AppendEscapedString appends s to dst as a quoted JSON string, escaped
+// exactly as Marshal writes strings: control characters, quotes and
+// backslashes are escaped, as are '<', '>' and '&', and U+2028 and U+2029
+// so the result is valid JavaScript, and invalid UTF-8 is replaced with
+// U+FFFD. Runs of bytes that need no escaping are found a word at a time
+// and copied whole.
+func AppendEscapedString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	dst = jsonenc.AppendString(dst, s, true)
+	return append(dst, '"')
+}
@@ -0,0 +1,48 @@
+package simdjson
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateUTF8(t *testing.T) {
+	tests := []struct {
+		input string
+		valid bool
+	}{
+		{"", true},
+		{"plain ascii", true},
+		{"héllo 世界 🎉", true},
+		{strings.Repeat("é", 100), true},
+		{"\xff", false},
+		{"truncated \xe4\xb8", false},
+		{"overlong \xc0\xaf", false},
+		{"surrogate \xed\xa0\x80", false},
+		{strings.Repeat("a", 63) + "\x80", false},
+	}
+	for _, tt := range tests {
+		if got := ValidateUTF8([]byte(tt.input)); got != tt.valid {
+			t.Errorf("%q: expected %v, got %v", tt.input, tt.valid, got)
+		}
+	}
+}
+
+func TestAppendEscapedString(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"plain",
+		"quote \" backslash \\ slash /",
+		"control \x00\x01\n\r\t\x1f",
+		"<script>&</script>",
+		"line \u2028 paragraph \u2029",
+		"invalid \xff utf-8",
+		strings.Repeat("long run without escapes ", 10) + "\n",
+	} {
+		expected, _ := json.Marshal(s)
+		got := AppendEscapedString([]byte("prefix:"), s)
+		if string(got) != "prefix:"+string(expected) {
+			t.Errorf("%q: expected %s, got %s", s, expected, got[len("prefix:"):])
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package examples
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+)
+
+func sampleOrder() Order {
+	return Order{
+		ID:       42,
+		Status:   "shipped",
+		Customer: &Customer{ID: 7, Name: "Ada \"Countess\" Lovelace", Tags: []string{"vip", "early"}},
+		Items: []LineItem{
+			{SKU: "A-1", Quantity: 2, Price: 9.99, Discount: 0.1},
+			{SKU: "B-2", Quantity: 1, Price: 100},
+		},
+		Notes:   map[string]string{"gift": "yes", "door": "back", "wrap": "no", "call": "first"},
+		Paid:    true,
+		Payload: []byte{0, 1, 2, 250},
+		Extra:   "free-form",
+		Matrix:  [][]int8{{1, -2}, {}, nil},
+		Secret:  "hidden",
+	}
+}
+
+func TestGeneratedMarshal(t *testing.T) {
+	orders := map[string]Order{
+		"full":         sampleOrder(),
+		"empty":        {},
+		"nil_customer": {ID: 1, Items: []LineItem{}},
+	}
+
+	for name, order := range orders {
+		t.Run(name, func(t *testing.T) {
+			want, err := json.Marshal(order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := simdjson.Marshal(order)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("Expected %s, got %s", want, got)
+			}
+
+			// Pointers go through the same generated method
+			got, err = simdjson.Marshal(&order)
+			if err != nil || string(got) != string(want) {
+				t.Errorf("Pointer marshal: expected %s, got %s (%v)", want, got, err)
+			}
+		})
+	}
+}
+
+// TestGeneratedNilAsEmpty checks that generated code follows the nil
+// encoding option like reflection does
+func TestGeneratedNilAsEmpty(t *testing.T) {
+	type plainOrder Order // drops the generated methods
+	opts := simdjson.DefaultMarshalOptions()
+	opts.NilAsEmpty = true
+
+	order := Order{Customer: &Customer{}, Matrix: [][]int8{nil}}
+	got, err := simdjson.MarshalWithOptions(order, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := simdjson.MarshalWithOptions(plainOrder(order), &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	for _, member := range []string{`"items":[]`, `"tags":[]`, `"matrix":[[]]`} {
+		if !strings.Contains(string(got), member) {
+			t.Errorf("Expected %s in %s", member, got)
+		}
+	}
+}
+
+func TestGeneratedUnmarshal(t *testing.T) {
+	data, err := json.Marshal(sampleOrder())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want, got Order
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+	if err := simdjson.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestGeneratedUnmarshalDetails(t *testing.T) {
+	var o Order
+	input := `{"unknown":{"nested":[1,2,{"x":null}]},"customer":{"name":"Béa","tags":null},` +
+		`"items":[],"notes":null,"paid":null,"status":"new"}`
+	if err := simdjson.Unmarshal([]byte(input), &o); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if o.Customer == nil || o.Customer.Name != "Béa" || o.Customer.Tags != nil {
+		t.Errorf("Unexpected customer %+v", o.Customer)
+	}
+	if o.Items == nil || len(o.Items) != 0 {
+		t.Errorf("Expected empty non-nil items, got %#v", o.Items)
+	}
+	if o.Status != "new" {
+		t.Errorf("Expected status new, got %q", o.Status)
+	}
+
+	// Keys matching no field exactly match case-insensitively
+	o = Order{}
+	input = `{"ID":5,"Status":"new","CUSTOMER":{"NAME":"q","Tags":["a"]},"Items":[{"SKU":"A-1","Qty":3}]}`
+	if err := simdjson.Unmarshal([]byte(input), &o); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if o.ID != 5 || o.Status != "new" || o.Customer == nil || o.Customer.Name != "q" || len(o.Customer.Tags) != 1 ||
+		len(o.Items) != 1 || o.Items[0].SKU != "A-1" || o.Items[0].Quantity != 3 {
+		t.Errorf("Unexpected case-insensitive decode %+v %+v", o, o.Customer)
+	}
+
+	o = Order{Customer: &Customer{ID: 3}}
+	if err := simdjson.Unmarshal([]byte(`{"customer":null}`), &o); err != nil || o.Customer != nil {
+		t.Errorf("Expected null to clear the pointer, got %+v (%v)", o.Customer, err)
+	}
+}
+
+func TestGeneratedUnmarshalErrors(t *testing.T) {
+	inputs := []string{
+		`{"id":1 "status":"x"}`,
+		`{"id":-1}`,
+		`{"id":"1"}`,
+		`{"items":[{"qty":1.5}]}`,
+		`{"matrix":[[300]]}`,
+		`{"paid":1}`,
+		`{"payload":"not base64!"}`,
+		`{"id":1}{}`,
+		`{"id":1`,
+		`[]`,
+	}
+
+	for _, input := range inputs {
+		var o Order
+		if err := simdjson.Unmarshal([]byte(input), &o); err == nil {
+			t.Errorf("Expected error for %s", input)
+		}
+	}
+}
+
+func BenchmarkGeneratedUnmarshal(b *testing.B) {
+	data, _ := json.Marshal(sampleOrder())
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var o Order
+		if err := simdjson.Unmarshal(data, &o); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
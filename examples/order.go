@@ -0,0 +1,37 @@
+package examples
+
+//go:generate go run ../cmd/simdjson-gen -type Order,LineItem,Customer order.go
+
+// Status is the lifecycle state of an Order.
+type Status string
+
+// Customer places orders.
+type Customer struct {
+	ID    int64    `json:"id"`
+	Name  string   `json:"name"`
+	Email string   `json:"email,omitempty"`
+	Tags  []string `json:"tags"`
+}
+
+// LineItem is one product in an Order.
+type LineItem struct {
+	SKU      string  `json:"sku"`
+	Quantity int     `json:"qty"`
+	Price    float64 `json:"price"`
+	Discount float32 `json:"discount,omitempty"`
+}
+
+// Order is the example type used to exercise simdjson-gen.
+type Order struct {
+	ID       uint32            `json:"id"`
+	Status   Status            `json:"status"`
+	Customer *Customer         `json:"customer"`
+	Items    []LineItem        `json:"items"`
+	Notes    map[string]string `json:"notes,omitempty"`
+	Paid     bool              `json:"paid"`
+	Payload  []byte            `json:"payload,omitempty"`
+	Extra    interface{}       `json:"extra"`
+	Matrix   [][]int8          `json:"matrix,omitempty"`
+	Secret   string            `json:"-"`
+	internal int
+}
@@ -0,0 +1,473 @@
+// Code generated by simdjson-gen. DO NOT EDIT.
+
+package examples
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+)
+
+// MarshalJSONTo writes v to w as a JSON object.
+func (v Order) MarshalJSONTo(w *simdjson.Writer) error {
+	w.RawByte('{')
+	w.RawString("\"id\":")
+	w.Uint(uint64(v.ID))
+	w.RawString(",\"status\":")
+	w.String(string(v.Status))
+	w.RawString(",\"customer\":")
+	if v.Customer == nil {
+		w.Null()
+	} else {
+		if err := (*v.Customer).MarshalJSONTo(w); err != nil {
+			return err
+		}
+	}
+	w.RawString(",\"items\":")
+	if v.Items == nil {
+		w.NilSlice()
+	} else {
+		w.RawByte('[')
+		for i1, x1 := range v.Items {
+			if i1 > 0 {
+				w.RawByte(',')
+			}
+			if err := x1.MarshalJSONTo(w); err != nil {
+				return err
+			}
+		}
+		w.RawByte(']')
+	}
+	if len(v.Notes) != 0 {
+		w.RawString(",\"notes\":")
+		w.RawByte('{')
+		for n1, k1 := range slices.Sorted(maps.Keys(v.Notes)) {
+			if n1 > 0 {
+				w.RawByte(',')
+			}
+			w.String(string(k1))
+			w.RawByte(':')
+			x1 := v.Notes[k1]
+			w.String(x1)
+		}
+		w.RawByte('}')
+	}
+	w.RawString(",\"paid\":")
+	w.Bool(v.Paid)
+	if len(v.Payload) != 0 {
+		w.RawString(",\"payload\":")
+		w.Bytes(v.Payload)
+	}
+	w.RawString(",\"extra\":")
+	if err := w.Value(v.Extra); err != nil {
+		return err
+	}
+	if len(v.Matrix) != 0 {
+		w.RawString(",\"matrix\":")
+		w.RawByte('[')
+		for i1, x1 := range v.Matrix {
+			if i1 > 0 {
+				w.RawByte(',')
+			}
+			if x1 == nil {
+				w.NilSlice()
+			} else {
+				w.RawByte('[')
+				for i2, x2 := range x1 {
+					if i2 > 0 {
+						w.RawByte(',')
+					}
+					w.Int(int64(x2))
+				}
+				w.RawByte(']')
+			}
+		}
+		w.RawByte(']')
+	}
+	w.RawByte('}')
+	return nil
+}
+
+// UnmarshalJSONFrom reads a JSON object from r into v.
+func (v *Order) UnmarshalJSONFrom(r *simdjson.Reader) error {
+	if r.Null() {
+		return nil
+	}
+	if err := r.BeginObject(); err != nil {
+		return err
+	}
+	for r.More() {
+		key, err := r.Key()
+		if err != nil {
+			return err
+		}
+	match:
+		switch string(key) {
+		case "id":
+			if !r.Null() {
+				x1, err := r.Uint(32)
+				if err != nil {
+					return err
+				}
+				v.ID = uint32(x1)
+			}
+		case "status":
+			if !r.Null() {
+				x1, err := r.String()
+				if err != nil {
+					return err
+				}
+				v.Status = Status(x1)
+			}
+		case "customer":
+			if r.Null() {
+				v.Customer = nil
+			} else {
+				if v.Customer == nil {
+					v.Customer = new(Customer)
+				}
+				if err := (*v.Customer).UnmarshalJSONFrom(r); err != nil {
+					return err
+				}
+			}
+		case "items":
+			if r.Null() {
+				v.Items = nil
+			} else {
+				if err := r.BeginArray(); err != nil {
+					return err
+				}
+				s1 := v.Items[:0]
+				for r.More() {
+					var x1 LineItem
+					if len(s1) < len(v.Items) {
+						x1 = v.Items[len(s1)]
+					}
+					if err := x1.UnmarshalJSONFrom(r); err != nil {
+						return err
+					}
+					s1 = append(s1, x1)
+				}
+				if err := r.EndArray(); err != nil {
+					return err
+				}
+				if s1 == nil {
+					s1 = []LineItem{}
+				}
+				v.Items = s1
+			}
+		case "notes":
+			if r.Null() {
+				v.Notes = nil
+			} else {
+				if err := r.BeginObject(); err != nil {
+					return err
+				}
+				if v.Notes == nil {
+					v.Notes = make(map[string]string)
+				}
+				for r.More() {
+					k1, err := r.Key()
+					if err != nil {
+						return err
+					}
+					key1 := string(k1)
+					var x1 string
+					if !r.Null() {
+						x2, err := r.String()
+						if err != nil {
+							return err
+						}
+						x1 = x2
+					}
+					v.Notes[key1] = x1
+				}
+				if err := r.EndObject(); err != nil {
+					return err
+				}
+			}
+		case "paid":
+			if !r.Null() {
+				x1, err := r.Bool()
+				if err != nil {
+					return err
+				}
+				v.Paid = x1
+			}
+		case "payload":
+			if r.Null() {
+				v.Payload = nil
+			} else {
+				x1, err := r.Bytes()
+				if err != nil {
+					return err
+				}
+				v.Payload = x1
+			}
+		case "extra":
+			if err := r.Decode(&v.Extra); err != nil {
+				return err
+			}
+		case "matrix":
+			if r.Null() {
+				v.Matrix = nil
+			} else {
+				if err := r.BeginArray(); err != nil {
+					return err
+				}
+				s1 := v.Matrix[:0]
+				for r.More() {
+					var x1 []int8
+					if len(s1) < len(v.Matrix) {
+						x1 = v.Matrix[len(s1)]
+					}
+					if r.Null() {
+						x1 = nil
+					} else {
+						if err := r.BeginArray(); err != nil {
+							return err
+						}
+						s2 := x1[:0]
+						for r.More() {
+							var x2 int8
+							if len(s2) < len(x1) {
+								x2 = x1[len(s2)]
+							}
+							if !r.Null() {
+								x3, err := r.Int(8)
+								if err != nil {
+									return err
+								}
+								x2 = int8(x3)
+							}
+							s2 = append(s2, x2)
+						}
+						if err := r.EndArray(); err != nil {
+							return err
+						}
+						if s2 == nil {
+							s2 = []int8{}
+						}
+						x1 = s2
+					}
+					s1 = append(s1, x1)
+				}
+				if err := r.EndArray(); err != nil {
+					return err
+				}
+				if s1 == nil {
+					s1 = [][]int8{}
+				}
+				v.Matrix = s1
+			}
+		default:
+			for _, name := range [...]string{"id", "status", "customer", "items", "notes", "paid", "payload", "extra", "matrix"} {
+				if bytes.EqualFold(key, []byte(name)) {
+					key = []byte(name)
+					goto match
+				}
+			}
+			if err := r.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return r.EndObject()
+}
+
+// MarshalJSONTo writes v to w as a JSON object.
+func (v LineItem) MarshalJSONTo(w *simdjson.Writer) error {
+	w.RawByte('{')
+	w.RawString("\"sku\":")
+	w.String(v.SKU)
+	w.RawString(",\"qty\":")
+	w.Int(int64(v.Quantity))
+	w.RawString(",\"price\":")
+	if err := w.Float(v.Price, 64); err != nil {
+		return err
+	}
+	if v.Discount != 0 {
+		w.RawString(",\"discount\":")
+		if err := w.Float(float64(v.Discount), 32); err != nil {
+			return err
+		}
+	}
+	w.RawByte('}')
+	return nil
+}
+
+// UnmarshalJSONFrom reads a JSON object from r into v.
+func (v *LineItem) UnmarshalJSONFrom(r *simdjson.Reader) error {
+	if r.Null() {
+		return nil
+	}
+	if err := r.BeginObject(); err != nil {
+		return err
+	}
+	for r.More() {
+		key, err := r.Key()
+		if err != nil {
+			return err
+		}
+	match:
+		switch string(key) {
+		case "sku":
+			if !r.Null() {
+				x1, err := r.String()
+				if err != nil {
+					return err
+				}
+				v.SKU = x1
+			}
+		case "qty":
+			if !r.Null() {
+				x1, err := r.Int(0)
+				if err != nil {
+					return err
+				}
+				v.Quantity = int(x1)
+			}
+		case "price":
+			if !r.Null() {
+				x1, err := r.Float(64)
+				if err != nil {
+					return err
+				}
+				v.Price = x1
+			}
+		case "discount":
+			if !r.Null() {
+				x1, err := r.Float(32)
+				if err != nil {
+					return err
+				}
+				v.Discount = float32(x1)
+			}
+		default:
+			for _, name := range [...]string{"sku", "qty", "price", "discount"} {
+				if bytes.EqualFold(key, []byte(name)) {
+					key = []byte(name)
+					goto match
+				}
+			}
+			if err := r.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return r.EndObject()
+}
+
+// MarshalJSONTo writes v to w as a JSON object.
+func (v Customer) MarshalJSONTo(w *simdjson.Writer) error {
+	w.RawByte('{')
+	w.RawString("\"id\":")
+	w.Int(v.ID)
+	w.RawString(",\"name\":")
+	w.String(v.Name)
+	if v.Email != "" {
+		w.RawString(",\"email\":")
+		w.String(v.Email)
+	}
+	w.RawString(",\"tags\":")
+	if v.Tags == nil {
+		w.NilSlice()
+	} else {
+		w.RawByte('[')
+		for i1, x1 := range v.Tags {
+			if i1 > 0 {
+				w.RawByte(',')
+			}
+			w.String(x1)
+		}
+		w.RawByte(']')
+	}
+	w.RawByte('}')
+	return nil
+}
+
+// UnmarshalJSONFrom reads a JSON object from r into v.
+func (v *Customer) UnmarshalJSONFrom(r *simdjson.Reader) error {
+	if r.Null() {
+		return nil
+	}
+	if err := r.BeginObject(); err != nil {
+		return err
+	}
+	for r.More() {
+		key, err := r.Key()
+		if err != nil {
+			return err
+		}
+	match:
+		switch string(key) {
+		case "id":
+			if !r.Null() {
+				x1, err := r.Int(64)
+				if err != nil {
+					return err
+				}
+				v.ID = x1
+			}
+		case "name":
+			if !r.Null() {
+				x1, err := r.String()
+				if err != nil {
+					return err
+				}
+				v.Name = x1
+			}
+		case "email":
+			if !r.Null() {
+				x1, err := r.String()
+				if err != nil {
+					return err
+				}
+				v.Email = x1
+			}
+		case "tags":
+			if r.Null() {
+				v.Tags = nil
+			} else {
+				if err := r.BeginArray(); err != nil {
+					return err
+				}
+				s1 := v.Tags[:0]
+				for r.More() {
+					var x1 string
+					if len(s1) < len(v.Tags) {
+						x1 = v.Tags[len(s1)]
+					}
+					if !r.Null() {
+						x2, err := r.String()
+						if err != nil {
+							return err
+						}
+						x1 = x2
+					}
+					s1 = append(s1, x1)
+				}
+				if err := r.EndArray(); err != nil {
+					return err
+				}
+				if s1 == nil {
+					s1 = []string{}
+				}
+				v.Tags = s1
+			}
+		default:
+			for _, name := range [...]string{"id", "name", "email", "tags"} {
+				if bytes.EqualFold(key, []byte(name)) {
+					key = []byte(name)
+					goto match
+				}
+			}
+			if err := r.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return r.EndObject()
+}
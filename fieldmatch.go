@@ -0,0 +1,114 @@
+package simdjson
+
+import "bytes"
+
+// fieldMatcher finds the struct field for an object key without hashing
+// the whole key. Keys are matched on the raw bytes between the quotes, so
+// keys without escapes are never unescaped or converted to a string.
+//
+// Candidates are selected by a perfect hash over the key's length and its
+// first and last bytes, found when the plan is built. The single candidate
+// is then confirmed with a string comparison, which the runtime implements
+// with vector instructions (SSE2/AVX2 on amd64, NEON on arm64) and which
+// does not allocate. Field sets the hash cannot separate, such as names
+// of equal length sharing their first and last bytes, use a map instead.
+type fieldMatcher struct {
+	names  []string // JSON name of each field
+	folds  [][]byte // names again, for case-insensitive matching
+	slots  []int32  // field index + 1 by hash slot, 0 if empty
+	seed   uint32
+	shift  uint32
+	byName map[string]int // used when no perfect hash was found
+}
+
+// Multipliers tried for the perfect hash, odd constants with well mixed
+// bits.
+var fieldHashSeeds = [...]uint32{
+	0x9e3779b1, 0x85ebca6b, 0xc2b2ae35, 0x27d4eb2f, 0x165667b1,
+	0xd3a2646c, 0xfd7046c5, 0xb55a4f09, 0x7feb352d, 0x846ca68b,
+}
+
+// maxPerfectHashBits bounds the hash table at 1<<maxPerfectHashBits slots.
+const maxPerfectHashBits = 12
+
+func newFieldMatcher(fields []fieldInfo) *fieldMatcher {
+	m := &fieldMatcher{
+		names: make([]string, len(fields)),
+		folds: make([][]byte, len(fields)),
+	}
+	for i, f := range fields {
+		m.names[i] = f.name
+		m.folds[i] = []byte(f.name)
+	}
+	if len(fields) == 0 {
+		return m
+	}
+
+	bits := uint32(1)
+	for 1<<bits < len(fields) {
+		bits++
+	}
+	for ; bits <= maxPerfectHashBits; bits++ {
+		for _, seed := range fieldHashSeeds {
+			if m.build(seed, bits) {
+				return m
+			}
+		}
+	}
+
+	m.slots = nil
+	m.byName = make(map[string]int, len(fields))
+	for i, name := range m.names {
+		if _, dup := m.byName[name]; !dup {
+			m.byName[name] = i
+		}
+	}
+	return m
+}
+
+// build tries to place every field in its own slot. Fields sharing a name
+// with an earlier one are skipped: the first field wins, as in byName.
+func (m *fieldMatcher) build(seed, bits uint32) bool {
+	m.seed, m.shift = seed, 32-bits
+	m.slots = make([]int32, 1<<bits)
+	for i, name := range m.names {
+		h := fieldHash(name, m.seed, m.shift)
+		if j := m.slots[h]; j != 0 {
+			if m.names[j-1] == name {
+				continue
+			}
+			return false
+		}
+		m.slots[h] = int32(i + 1)
+	}
+	return true
+}
+
+func fieldHash[S string | []byte](key S, seed, shift uint32) uint32 {
+	var x uint32
+	if n := len(key); n > 0 {
+		x = uint32(n) | uint32(key[0])<<8 | uint32(key[n-1])<<16
+	}
+	return (x * seed) >> shift
+}
+
+// match returns the index of the field for key, or -1. An exact match is
+// preferred, falling back to a case-insensitive one like encoding/json.
+func (m *fieldMatcher) match(key []byte) int {
+	// The string conversions below are only compared, so they don't
+	// allocate
+	if m.slots != nil {
+		if j := m.slots[fieldHash(key, m.seed, m.shift)]; j != 0 && m.names[j-1] == string(key) {
+			return int(j - 1)
+		}
+	} else if i, ok := m.byName[string(key)]; ok {
+		return i
+	}
+
+	for i, name := range m.folds {
+		if bytes.EqualFold(name, key) {
+			return i
+		}
+	}
+	return -1
+}
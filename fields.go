@@ -0,0 +1,252 @@
+package simdjson
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// fieldInfo describes a struct field as it appears in JSON.
+type fieldInfo struct {
+	name      string // JSON key
+	index     int
+	path      []int // indexes of the inline fields holding the field
+	omitempty bool
+	required  bool // Unmarshal fails if the key is missing
+	tagged    bool // the name comes from the json tag
+	typ       reflect.Type
+
+	// Format of a []byte field given by its format tag option
+	bytesFormat    BytesFormat
+	hasBytesFormat bool
+
+	// Digits after the point of the floats in the field given by its
+	// prec tag option, or -1
+	floatPrec int
+
+	// Member selecting the concrete type of an interface field, given by
+	// its type tag option; see RegisterType
+	discriminator string
+}
+
+// structInfo describes a struct type as it appears in JSON.
+type structInfo struct {
+	fields []fieldInfo
+
+	// unknown is the index of the map field tagged ",unknown", which
+	// holds the members that match no field, or -1. known holds the names
+	// of the fields then, so the map cannot write them a second time.
+	unknown int
+	known   map[string]bool
+}
+
+// structKey identifies a struct type with the naming of its untagged
+// fields.
+type structKey struct {
+	t      reflect.Type
+	naming FieldNaming
+}
+
+var fieldCache sync.Map // map[structKey]*structInfo
+
+// cachedFields returns the exported fields of struct type t with their
+// json tags parsed.
+func cachedFields(t reflect.Type) []fieldInfo {
+	return cachedStruct(t, GoFieldNames).fields
+}
+
+// cachedStruct returns the JSON description of struct type t, naming the
+// fields without a name in their tag with naming. It is computed once per
+// type and naming and shared by the encoder and decoder.
+func cachedStruct(t reflect.Type, naming FieldNaming) *structInfo {
+	key := structKey{t, naming}
+	if s, ok := fieldCache.Load(key); ok {
+		return s.(*structInfo)
+	}
+
+	info := &structInfo{unknown: -1}
+	info.fields = structFields(info, t, naming, nil, map[reflect.Type]bool{t: true})
+	if info.unknown >= 0 {
+		info.known = make(map[string]bool, len(info.fields))
+		for _, f := range info.fields {
+			info.known[f.name] = true
+		}
+	}
+
+	s, _ := fieldCache.LoadOrStore(key, info)
+	return s.(*structInfo)
+}
+
+// structFields returns the fields of struct type t, held by the inline
+// fields at path. The fields of a struct tagged ",inline", or embedded
+// without a name in its tag as encoding/json flattens it, take its place,
+// unless a field outside it has the same name; inside is what holds t and
+// the types holding it, which cannot be inlined again.
+func structFields(info *structInfo, t reflect.Type, naming FieldNaming, path []int, inside map[reflect.Type]bool) []fieldInfo {
+	fields := make([]fieldInfo, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := findComma(tag); idx != -1 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		// Skip unexported fields, except embedded structs, whose exported
+		// fields are still reachable. A nil pointer to an unexported struct
+		// could not be allocated to decode into, so those are skipped too.
+		st := inlineType(sf.Type)
+		embedded := sf.Anonymous && name == "" && st != nil
+		if sf.PkgPath != "" && !(sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			continue
+		}
+
+		if st != nil && (embedded || hasTagOption(opts, "inline")) && !inside[st] {
+			inside[st] = true
+			inner := structFields(info, st, naming, append(path[:len(path):len(path)], i), inside)
+			delete(inside, st)
+			fields = append(fields, inner...)
+			continue
+		}
+
+		f := fieldInfo{name: naming.Name(sf.Name), index: i, path: path, typ: sf.Type, floatPrec: -1}
+		if tag != "" {
+			if hasTagOption(opts, "unknown") && info.unknown < 0 && path == nil &&
+				sf.Type.Kind() == reflect.Map && sf.Type.Key().Kind() == reflect.String {
+				info.unknown = i
+				continue
+			}
+			if name != "" {
+				f.name, f.tagged = name, true
+			}
+			f.omitempty = hasTagOption(opts, "omitempty")
+			f.required = hasTagOption(opts, "required")
+			if isByteSlice(sf.Type) {
+				f.bytesFormat, f.hasBytesFormat = parseBytesFormat(opts)
+			}
+			f.discriminator, _ = tagOptionValue(opts, "type:")
+			if prec, ok := tagOptionValue(opts, "prec:"); ok {
+				if n, err := strconv.Atoi(prec); err == nil && n >= 0 {
+					f.floatPrec = n
+				}
+			}
+		}
+		fields = append(fields, f)
+	}
+	return dropShadowed(fields)
+}
+
+// dropShadowed resolves the fields sharing a name as encoding/json does:
+// those held by the fewest inline fields win, and of those the only one
+// named by its tag, or the only one at all. A name with several winners
+// is dropped.
+func dropShadowed(fields []fieldInfo) []fieldInfo {
+	type rank struct{ depth, tagged, count int }
+	ranks := make(map[string]rank, len(fields))
+	for _, f := range fields {
+		tagged := 0
+		if f.tagged {
+			tagged = 1
+		}
+		r, ok := ranks[f.name]
+		switch {
+		case !ok || len(f.path) < r.depth || len(f.path) == r.depth && tagged > r.tagged:
+			ranks[f.name] = rank{len(f.path), tagged, 1}
+		case len(f.path) == r.depth && tagged == r.tagged:
+			r.count++
+			ranks[f.name] = r
+		}
+	}
+	kept := fields[:0]
+	for _, f := range fields {
+		r := ranks[f.name]
+		if r.count == 1 && r.depth == len(f.path) && (r.tagged == 1) == f.tagged {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// inlineType returns the struct type t is or points to, or nil.
+func inlineType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// value returns field f of struct v, or false if one of the inline
+// pointers holding it is nil.
+func (f *fieldInfo) value(v reflect.Value) (reflect.Value, bool) {
+	for _, i := range f.path {
+		v = v.Field(i)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+	}
+	return v.Field(f.index), true
+}
+
+// settable returns field f of struct v, allocating the nil inline
+// pointers holding it.
+func (f *fieldInfo) settable(v reflect.Value) reflect.Value {
+	for _, i := range f.path {
+		v = v.Field(i)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+	}
+	return v.Field(f.index)
+}
+
+// hasTagOption reports whether the comma-separated tag options contain opt.
+func hasTagOption(opts, opt string) bool {
+	for opts != "" {
+		next := opts
+		if idx := findComma(opts); idx != -1 {
+			next, opts = opts[:idx], opts[idx+1:]
+		} else {
+			opts = ""
+		}
+		if next == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// tagOptionValue returns what follows prefix in the first of the
+// comma-separated tag options that starts with it.
+func tagOptionValue(opts, prefix string) (string, bool) {
+	for opts != "" {
+		next := opts
+		if idx := findComma(opts); idx != -1 {
+			next, opts = opts[:idx], opts[idx+1:]
+		} else {
+			opts = ""
+		}
+		if value, ok := strings.CutPrefix(next, prefix); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// isByteSlice reports whether t is a []byte, or a named slice of bytes,
+// which is encoded as a string rather than an array.
+func isByteSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
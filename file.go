@@ -0,0 +1,64 @@
+package simdjson
+
+import "os"
+
+// fileOptions are the options of ParseFile: strings and keys point into
+// the mapped file rather than being copied.
+var fileOptions = Options{}
+
+// ParseFile parses the JSON file at path into a Result like Parser.Parse
+// in zero-copy mode, without first copying the file into the Go heap.
+// Where the platform supports it the file is memory-mapped, and otherwise
+// read, so multi-gigabyte documents cost their page cache rather than a
+// heap copy plus garbage collection.
+//
+// Strings and keys without escape sequences point into the mapping, which
+// stays in place until Release. Release must therefore be called to unmap
+// the file, and after it nothing from the tree may be used, strings
+// included: they would point at unmapped memory and crash the program.
+// Use Value.Detach or copy what must outlive the Result. The file must not
+// be truncated or written to while mapped.
+func ParseFile(path string) (*Result, error) {
+	data, mapped, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d := newDecoder(data)
+	defer d.release()
+
+	d.setOptions(&fileOptions)
+	res, err := d.parse()
+	if err != nil {
+		if mapped {
+			unmapFile(data)
+		}
+		return nil, err
+	}
+	if mapped {
+		res.file = data
+	}
+	return res, nil
+}
+
+// UnmarshalFile parses the JSON file at path into the value pointed to by
+// v like Unmarshal. The file is memory-mapped where the platform supports
+// it, and otherwise read, and every string is copied out of it, so it is
+// unmapped before UnmarshalFile returns.
+func UnmarshalFile(path string, v interface{}) error {
+	data, mapped, err := mapFile(path)
+	if err != nil {
+		return err
+	}
+	if mapped {
+		defer unmapFile(data)
+	}
+	return Unmarshal(data, v)
+}
+
+// readFile is the fallback of mapFile where mapping is not supported or
+// fails.
+func readFile(path string) ([]byte, bool, error) {
+	data, err := os.ReadFile(path)
+	return data, false, err
+}
@@ -0,0 +1,71 @@
+package simdjson
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempJSON(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "doc.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseFile(t *testing.T) {
+	path := writeTempJSON(t, `{"name":"mapped","tags":["a","b\n"],"n":12}`)
+	res, err := ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := res.Value().(map[string]interface{})
+	if doc["name"] != "mapped" || doc["n"] != int64(12) {
+		t.Errorf("Expected name mapped and n 12, got %v", doc)
+	}
+	if tags := doc["tags"].([]interface{}); len(tags) != 2 || tags[1] != "b\n" {
+		t.Errorf("Expected tags [a b\\n], got %v", tags)
+	}
+	kept := res.Root().Get("name").Detach()
+	res.Release()
+	res.Release()
+	if kept != "mapped" {
+		t.Errorf("Expected a detached value to survive Release, got %v", kept)
+	}
+
+	for _, content := range []string{``, `{"a":`} {
+		if _, err := ParseFile(writeTempJSON(t, content)); err == nil {
+			t.Errorf("Expected an error for %q", content)
+		}
+	}
+	if _, err := ParseFile(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestUnmarshalFile(t *testing.T) {
+	path := writeTempJSON(t, `{"name":"root","children":[{"name":"leaf"}]}`)
+	var n decodeNode
+	if err := UnmarshalFile(path, &n); err != nil {
+		t.Fatal(err)
+	}
+	if n.Name != "root" || len(n.Children) != 1 || n.Children[0].Name != "leaf" {
+		t.Errorf("Expected root with a leaf, got %+v", n)
+	}
+
+	// Strings are copies, so they outlive the mapping
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if n.Children[0].Name != "leaf" {
+		t.Errorf("Expected leaf after the file is gone, got %q", n.Children[0].Name)
+	}
+
+	if err := UnmarshalFile(filepath.Join(t.TempDir(), "missing.json"), &n); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+}
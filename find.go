@@ -0,0 +1,245 @@
+package simdjson
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/jsonenc"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/parser"
+)
+
+// FindRaw returns the value of the member key of the top-level object in
+// data, as written, and whether it has one; of repeated keys the first
+// counts. It is meant for looking up one member in each of many documents,
+// most of which lack it, without parsing them: a document is first
+// searched for "key" with the vectorized bytes.Index, and only if it is
+// there, or the document has escape sequences that might spell the key,
+// are the top-level members walked to the match, skipping values without
+// decoding them.
+//
+// data is not validated; invalid JSON may report no member, or return the
+// bytes that would be the value. Use Valid first for untrusted input.
+func FindRaw(data []byte, key string) ([]byte, bool) {
+	var buf [64]byte
+	quoted := jsonenc.AppendString(append(buf[:0], '"'), key, false)
+	quoted = append(quoted, '"')
+	if bytes.Index(data, quoted) < 0 && bytes.IndexByte(data, '\\') < 0 {
+		return nil, false
+	}
+	return memberRaw(data, key)
+}
+
+// memberRaw returns the value of the member key of the object data starts
+// with, walking its members.
+func memberRaw(data []byte, key string) ([]byte, bool) {
+	i := skipSpace(data, 0)
+	if i == len(data) || data[i] != '{' {
+		return nil, false
+	}
+	i = skipSpace(data, i+1)
+	for i < len(data) && data[i] == '"' {
+		end := stringEnd(data, i)
+		if end < 0 {
+			return nil, false
+		}
+		match := keyEquals(data[i+1:end-1], key)
+		i = skipSpace(data, end)
+		if i == len(data) || data[i] != ':' {
+			return nil, false
+		}
+		i = skipSpace(data, i+1)
+		vend := valueEnd(data, i)
+		if vend < 0 {
+			return nil, false
+		}
+		if match {
+			return data[i:vend], true
+		}
+		i = skipSpace(data, vend)
+		if i == len(data) || data[i] != ',' {
+			return nil, false
+		}
+		i = skipSpace(data, i+1)
+	}
+	return nil, false
+}
+
+// ContainsKey reports whether the top-level object in data has the member
+// key, as FindRaw finds it.
+func ContainsKey(data []byte, key string) bool {
+	_, ok := FindRaw(data, key)
+	return ok
+}
+
+// Keys returns the keys of the object at path in data, in document order,
+// without decoding any values: each step of the path is found by skipping
+// the members or elements before it, as FindRaw skips them, and only the
+// keys of the object found are unescaped. It is meant for discovering the
+// shape of documents with unknown schemas. Paths are as for Editor, as in
+// "users[2].address", and the empty path is the whole document. Keys
+// repeated in the object are listed as often as they appear; of repeated
+// keys on the path the first counts.
+//
+// data is not validated beyond the bytes walked. Use Valid first for
+// untrusted input.
+func Keys(data []byte, path string) ([]string, error) {
+	steps, err := parseEditPath(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range steps {
+		var ok bool
+		if st.index >= 0 {
+			data, ok = elementRaw(data, st.index)
+		} else {
+			data, ok = memberRaw(data, st.key)
+		}
+		if !ok {
+			return nil, errors.New("path not found: " + path)
+		}
+	}
+
+	i := skipSpace(data, 0)
+	if i == len(data) || data[i] != '{' {
+		return nil, errors.New("value at " + strconv.Quote(path) + " is not an object")
+	}
+	var keys []string
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == '}' {
+		return keys, nil
+	}
+	for i < len(data) && data[i] == '"' {
+		end := stringEnd(data, i)
+		if end < 0 {
+			return nil, ErrInvalidJSON
+		}
+		key, err := parser.AppendUnescaped(nil, data[i+1:end-1])
+		if err != nil {
+			return nil, ErrInvalidJSON
+		}
+		keys = append(keys, string(key))
+		i = skipSpace(data, end)
+		if i == len(data) || data[i] != ':' {
+			return nil, ErrInvalidJSON
+		}
+		end = valueEnd(data, skipSpace(data, i+1))
+		if end < 0 {
+			return nil, ErrInvalidJSON
+		}
+		i = skipSpace(data, end)
+		if i < len(data) && data[i] == '}' {
+			return keys, nil
+		}
+		if i == len(data) || data[i] != ',' {
+			return nil, ErrInvalidJSON
+		}
+		i = skipSpace(data, i+1)
+	}
+	return nil, ErrInvalidJSON
+}
+
+// elementRaw returns element n of the array data starts with, walking its
+// elements.
+func elementRaw(data []byte, n int) ([]byte, bool) {
+	i := skipSpace(data, 0)
+	if i == len(data) || data[i] != '[' {
+		return nil, false
+	}
+	i = skipSpace(data, i+1)
+	for k := 0; i < len(data) && data[i] != ']'; k++ {
+		end := valueEnd(data, i)
+		if end < 0 {
+			return nil, false
+		}
+		if k == n {
+			return data[i:end], true
+		}
+		i = skipSpace(data, end)
+		if i == len(data) || data[i] != ',' {
+			return nil, false
+		}
+		i = skipSpace(data, i+1)
+	}
+	return nil, false
+}
+
+// keyEquals reports whether the contents of a key string, raw, spell key.
+func keyEquals(raw []byte, key string) bool {
+	if bytes.IndexByte(raw, '\\') < 0 {
+		return string(raw) == key
+	}
+	var buf [64]byte
+	unescaped, err := parser.AppendUnescaped(buf[:0], raw)
+	return err == nil && string(unescaped) == key
+}
+
+// skipSpace returns the index of the first byte from i on that is not
+// whitespace.
+func skipSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\n', '\r':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// stringEnd returns the index after the closing quote of the string whose
+// opening quote is data[i], or -1 if it is not closed.
+func stringEnd(data []byte, i int) int {
+	for j := i + 1; ; {
+		k := bytes.IndexByte(data[j:], '"')
+		if k < 0 {
+			return -1
+		}
+		q := j + k
+		// The quote closes the string unless an odd run of backslashes
+		// escapes it
+		n := 0
+		for q-n-1 > i && data[q-n-1] == '\\' {
+			n++
+		}
+		if n%2 == 0 {
+			return q + 1
+		}
+		j = q + 1
+	}
+}
+
+// valueEnd returns the index after the value starting at data[i], or -1
+// if it does not end.
+func valueEnd(data []byte, i int) int {
+	if i == len(data) {
+		return -1
+	}
+	switch data[i] {
+	case '"':
+		return stringEnd(data, i)
+	case '{', '[':
+		depth := 0
+		for j := i; j < len(data); j++ {
+			switch data[j] {
+			case '"':
+				end := stringEnd(data, j)
+				if end < 0 {
+					return -1
+				}
+				j = end - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				if depth--; depth == 0 {
+					return j + 1
+				}
+			}
+		}
+		return -1
+	case ',', ':', '}', ']':
+		return -1
+	}
+	return scalarEnd(data, i)
+}
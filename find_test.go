@@ -0,0 +1,120 @@
+package simdjson
+
+import (
+	"bytes"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestFindRaw(t *testing.T) {
+	tests := []struct {
+		doc      string
+		key      string
+		expected string
+		found    bool
+	}{
+		{`{"id":42,"name":"x"}`, "id", `42`, true},
+		{` { "a" : [1, {"b": "]"}] , "id" : "s\"}" } `, "id", `"s\"}"`, true},
+		{`{"a":{"id":1},"id":2}`, "id", `2`, true},
+		{`{"x":"\"id\":5","id":3}`, "id", `3`, true},
+		{`{"x":"\"id\":5"}`, "id", ``, false},
+		{`{"id":7}`, "id", `7`, true},
+		{`{"a\"b":true}`, `a"b`, `true`, true},
+		{`{"obj":{"k":[1,2]} ,"z":null}`, "obj", `{"k":[1,2]}`, true},
+		{`{"n":-1.5e3}`, "n", `-1.5e3`, true},
+		{`{"id":1,"id":2}`, "id", `1`, true},
+		{`{"name":"id"}`, "id", ``, false},
+		{`{}`, "id", ``, false},
+		{`[{"id":1}]`, "id", ``, false},
+		{`"id"`, "id", ``, false},
+		{`{"id":`, "id", ``, false},
+		{`{"é":"ü"}`, "é", `"ü"`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.doc, func(t *testing.T) {
+			raw, ok := FindRaw([]byte(tt.doc), tt.key)
+			if ok != tt.found || string(raw) != tt.expected {
+				t.Errorf("Expected %q, %v, got %q, %v", tt.expected, tt.found, raw, ok)
+			}
+			if ContainsKey([]byte(tt.doc), tt.key) != tt.found {
+				t.Errorf("Expected ContainsKey %v", tt.found)
+			}
+		})
+	}
+
+	data := []byte(`{"a":"\n","b":{"c":[1,2,3]},"id":"x"}`)
+	if allocs := testing.AllocsPerRun(100, func() { FindRaw(data, "id") }); allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	doc := []byte(` {"a": {"x": 1, "y\u00e9": [2], "x": 3}, "list": [{"p": "}"}, {"q": {}, "r": null}], "e": {}} `)
+	tests := []struct {
+		path     string
+		expected []string
+		err      bool
+	}{
+		{"", []string{"a", "list", "e"}, false},
+		{"a", []string{"x", "yé", "x"}, false},
+		{"list[1]", []string{"q", "r"}, false},
+		{"list[1].q", nil, false},
+		{"e", nil, false},
+		{"list", nil, true},
+		{"a.x", nil, true},
+		{"list[2]", nil, true},
+		{"b", nil, true},
+		{"a..x", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			keys, err := Keys(doc, tt.path)
+			if (err != nil) != tt.err {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if !slices.Equal(keys, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, keys)
+			}
+		})
+	}
+
+	for _, bad := range []string{`{"a":1`, `{"a" 1}`, `{"a":1,}`, `{"a":1 "b":2}`} {
+		if _, err := Keys([]byte(bad), ""); err != ErrInvalidJSON {
+			t.Errorf("Expected ErrInvalidJSON for %s, got %v", bad, err)
+		}
+	}
+}
+
+func BenchmarkFindRaw(b *testing.B) {
+	var docs [][]byte
+	for i := 0; i < 100; i++ {
+		doc := `{"user":{"name":"user ` + strconv.Itoa(i) + `","tags":["a","b"]},"events":[1,2,3,4,5,6,7,8]`
+		if i%10 == 0 {
+			doc += `,"trace_id":"t` + strconv.Itoa(i) + `"`
+		}
+		docs = append(docs, []byte(doc+"}"))
+	}
+	size := int64(len(bytes.Join(docs, nil)))
+
+	b.Run("FindRaw", func(b *testing.B) {
+		b.SetBytes(size)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, doc := range docs {
+				FindRaw(doc, "trace_id")
+			}
+		}
+	})
+	b.Run("Unmarshal", func(b *testing.B) {
+		b.SetBytes(size)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, doc := range docs {
+				var m map[string]interface{}
+				Unmarshal(doc, &m)
+				_ = m["trace_id"]
+			}
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package simdjson
+
+import (
+	"math"
+	"testing"
+)
+
+type precSample struct {
+	Load    float64            `json:"load"`
+	Ratio   float64            `json:"ratio,prec:3"`
+	Count   float32            `json:"count,prec:0"`
+	History []float64          `json:"history,prec:1"`
+	ByHost  map[string]float64 `json:"by_host"`
+}
+
+func TestFloatPrecision(t *testing.T) {
+	tenth := 0.1
+	v := precSample{
+		Load:    tenth + 0.2,
+		Ratio:   2.0 / 3,
+		Count:   41.5,
+		History: []float64{1, 2.25, -0.04},
+		ByHost:  map[string]float64{"a": 1.005, "b": 100},
+	}
+
+	tests := []struct {
+		name     string
+		opts     MarshalOptions
+		expected string
+	}{
+		{"shortest", MarshalOptions{},
+			`{"load":0.30000000000000004,"ratio":0.667,"count":42,"history":[1.0,2.2,-0.0],"by_host":{"a":1.005,"b":100}}`},
+		{"fixed", MarshalOptions{FloatPrecision: 2},
+			`{"load":0.30,"ratio":0.667,"count":42,"history":[1.0,2.2,-0.0],"by_host":{"a":1.00,"b":100.00}}`},
+		{"trimmed", MarshalOptions{FloatPrecision: 2, TrimFloatZeros: true},
+			`{"load":0.3,"ratio":0.667,"count":42,"history":[1,2.2,-0],"by_host":{"a":1,"b":100}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := MarshalWithOptions(v, &tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+
+			var back precSample
+			if err := Unmarshal(data, &back); err != nil {
+				t.Errorf("Expected the output to decode, got %v", err)
+			}
+		})
+	}
+
+	// Top-level values and those in interfaces follow the options too
+	data, err := MarshalWithOptions([]interface{}{1.0 / 8, float32(2)}, &MarshalOptions{FloatPrecision: 2})
+	if err != nil || string(data) != `[0.12,2.00]` {
+		t.Errorf("Expected [0.12,2.00], got %s, %v", data, err)
+	}
+
+	if _, err := MarshalWithOptions(math.NaN(), &MarshalOptions{FloatPrecision: 2}); err == nil {
+		t.Error("Expected an error for NaN")
+	}
+}
+
+func TestWriterFloatPrec(t *testing.T) {
+	e := newEncoder()
+	defer e.release()
+
+	e.trimZeros = true
+	for _, f := range []float64{3.14159, 2.5, 7} {
+		if err := e.w.FloatPrec(f, 64, 2); err != nil {
+			t.Fatal(err)
+		}
+		e.w.RawByte(' ')
+	}
+	if err := e.w.Float(0.1, 64); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "3.14 2.5 7 0.1"; string(e.buf) != expected {
+		t.Errorf("Expected %q, got %q", expected, e.buf)
+	}
+}
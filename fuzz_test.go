@@ -0,0 +1,401 @@
+package simdjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// Seed inputs for the fuzz targets, taken from the JSONTestSuite
+// (github.com/nst/JSONTestSuite) test_parsing cases: y_ must be accepted,
+// n_ rejected, and i_ are left to the implementation.
+var fuzzSeeds = []string{
+	// y_
+	`[[]   ]`,
+	`[""]`,
+	`[]`,
+	`["a"]`,
+	`[false]`,
+	`[null, 1, "1", {}]`,
+	`[null]`,
+	`[1
+]`,
+	` [1]`,
+	`[1,null,null,null,2]`,
+	`[2] `,
+	`[123e65]`,
+	`[0e+1]`,
+	`[0e1]`,
+	`[ 4]`,
+	`[-0.000000000000000000000000000000000000000000000000000000000000000000000000000001]`,
+	`[20e1]`,
+	`[-0]`,
+	`[-123]`,
+	`[-1]`,
+	`[1E22]`,
+	`[1E-2]`,
+	`[1E+2]`,
+	`[123e45]`,
+	`[123.456e78]`,
+	`[1e-2]`,
+	`[1e+2]`,
+	`[123]`,
+	`[123.456789]`,
+	`{"asd":"sdf", "dfg":"fgh"}`,
+	`{"a":"b","a":"c"}`,
+	`{"a":[]}`,
+	`{"title":"Полтора Землекопа" }`,
+	`{"a":"b"}`,
+	`{}`,
+	`{"":0}`,
+	`{"foo\u0000bar": 42}`,
+	"[\"`Īካ\"]",
+	`["𐐷"]`,
+	`["😹💍"]`,
+	`["\"\\\/\b\f\n\r\t"]`,
+	`["\\u0000"]`,
+	`["\""]`,
+	`["a/*b*/c/*d//e"]`,
+	`["\\a"]`,
+	`["\u0012"]`,
+	`["￿"]`,
+	`["asd"]`,
+	`[ "asd"]`,
+	`["􏿿"]`,
+	`["new line"]`,
+	`["𛿿"]`,
+	`["\u0000"]`,
+	`[","]`,
+	`["π"]`,
+	`["asd "]`,
+	`" "`,
+	`["𝄞"]`,
+	`[" "]`,
+	`["⍂㈴⍂"]`,
+	`["aクリス"]`,
+	`["􏿾"]`,
+	`"asd"`,
+	`false`,
+	`42`,
+	`-0.1`,
+	`null`,
+	` [] `,
+	`[[[[[[[[[[[[[[[[[[[["Not too deep"]]]]]]]]]]]]]]]]]]]`,
+
+	// n_
+	`[1 true]`,
+	`[a]`,
+	`["": 1]`,
+	`[""],`,
+	`[,1]`,
+	`[1,,2]`,
+	`["x",,]`,
+	`["x"]]`,
+	`["",]`,
+	`["x"`,
+	`[x`,
+	`[3[4]]`,
+	`[1:2]`,
+	`[,]`,
+	`[-]`,
+	`[   , ""]`,
+	`["a",
+4
+,1,`,
+	`[1,]`,
+	`[1,,]`,
+	`[*]`,
+	`[""`,
+	`[1,`,
+	`[1,
+1
+,1`,
+	`[{}`,
+	`["a" "b"]`,
+	`[++1234]`,
+	`[+1]`,
+	`[+Inf]`,
+	`[-01]`,
+	`[-1.0.]`,
+	`[-2.]`,
+	`[-NaN]`,
+	`[.-1]`,
+	`[.2e-3]`,
+	`[0.1.2]`,
+	`[0.3e+]`,
+	`[0.3e]`,
+	`[0.e1]`,
+	`[0E+]`,
+	`[0e]`,
+	`[1.0e+]`,
+	`[1.0e-]`,
+	`[1 000.0]`,
+	`[1eE2]`,
+	`[2.e+3]`,
+	`[9.e+]`,
+	`[Inf]`,
+	`[NaN]`,
+	`[012]`,
+	`[0x1]`,
+	`[1+2]`,
+	`[-foo]`,
+	`[- 1]`,
+	`[-012]`,
+	`[tru]`,
+	`[nul]`,
+	`[fals]`,
+	`[True]`,
+	`{"x", null}`,
+	`{"x"::"b"}`,
+	`{"a":"a" 123}`,
+	`{"a" b}`,
+	`{:"b"}`,
+	`{"a" "b"}`,
+	`{"a":`,
+	`{"a"`,
+	`{1:1}`,
+	`{null:null,null:null}`,
+	`{"id":0,,,,,}`,
+	`{'a':0}`,
+	`{"id":0,}`,
+	`{"a":"b"}/**/`,
+	`{"a":"b"}#`,
+	`{"a":/*comment*/"b"}`,
+	`{"a":"a`,
+	`{[: "x"}`,
+	`{"a":"b",}`,
+	`{"a":"b",,"c":"d"}`,
+	`{a: "b"}`,
+	`{"a":"a`,
+	`{"a":1 "b":2}`,
+	`{}}`,
+	`["\uD800\"]`,
+	`["\uD800\u"]`,
+	`["\uD800\u1"]`,
+	`["\x00"]`,
+	`["\\\"]`,
+	`["\	"]`,
+	`["\🌀"]`,
+	`["\"]`,
+	`["\u00A"]`,
+	`["\uqqqq"]`,
+	`[\n]`,
+	`"`,
+	`['single quote']`,
+	`abc`,
+	`"\`,
+	`"\UA66D"`,
+	`"a" "b"`,
+	`[]]`,
+	`]`,
+	`[`,
+	``,
+	`{`,
+	` `,
+	`["a\a"]`,
+	"[\"a\x00a\"]",
+	"[\"new\nline\"]",
+	"[\"\t\"]",
+	"{\"\x00\":\"\"}",
+	`[1]x`,
+	`[][]`,
+	`nul`,
+	`tru`,
+
+	// i_
+	"[\"\xff\"]",
+	"[\"\xed\xa0\x80\"]",
+	"[\"日ш\xfa\"]",
+	"[\"\xc0\xaf\"]",
+	`[1.0e+9999]`,
+	`[-1e9999]`,
+	`[100000000000000000000]`,
+	`[-123123123123123123123123123123]`,
+	`["\uDADA"]`,
+	`["\uD888ሴ"]`,
+	`["\uDFAA"]`,
+	"\xef\xbb\xbf{}",
+}
+
+func addFuzzSeeds(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add([]byte(s))
+	}
+}
+
+// FuzzUnmarshal decodes arbitrary input into dynamic and typed values. It
+// checks that nothing panics and that Unmarshal only accepts documents the
+// validator accepts.
+func FuzzUnmarshal(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		type record struct {
+			ID    int64             `json:"id"`
+			Name  string            `json:"name"`
+			Score float64           `json:"score"`
+			Tags  []string          `json:"tags"`
+			Attrs map[string]string `json:"attrs"`
+			Next  *record           `json:"next"`
+		}
+
+		var v interface{}
+		err := Unmarshal(data, &v)
+		if err == nil && !Valid(data) {
+			t.Errorf("Unmarshal accepted %q, which Valid rejects", data)
+		}
+		if valid := ValidateWithError(data) == nil; valid != Valid(data) {
+			t.Errorf("ValidateWithError and Valid disagree on %q", data)
+		}
+
+		var r record
+		Unmarshal(data, &r)
+		var rs []record
+		Unmarshal(data, &rs)
+
+		p := NewParser(&Options{InternKeys: true})
+		if res, err := p.Parse(data); err == nil {
+			res.Root().Detach()
+			res.Release()
+		}
+	})
+}
+
+// FuzzTokenize runs the structural scanner and the tokenizer the decoder
+// uses on arbitrary input. Indices and tokens must stay in bounds and in
+// order, and every kernel must index valid documents the same way.
+func FuzzTokenize(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		s := scanner.New()
+		defer s.Release()
+		valid := Valid(data)
+
+		tokens, err := s.SimpleTokenize(data)
+		if err != nil && valid {
+			t.Fatalf("Tokenize failed on valid %q: %v", data, err)
+		}
+		for i, tok := range tokens {
+			if tok.Start >= tok.End || int(tok.End) > len(data) || (i > 0 && tok.Start < tokens[i-1].End) {
+				t.Fatalf("Token %+v out of order or range in %q", tok, data)
+			}
+			if valid && tok.Type == scanner.TokenNone {
+				t.Fatalf("Untyped token %+v in valid %q", tok, data)
+			}
+		}
+
+		defer scanner.SetLevel(scanner.LevelAuto)
+		scanner.SetLevel(scanner.LevelScalar)
+		if err := s.Scan(data); err != nil {
+			if valid {
+				t.Fatalf("Scan failed on valid %q: %v", data, err)
+			}
+			return
+		}
+		indices := append([]uint32(nil), s.GetStructuralIndices()...)
+		for i, idx := range indices {
+			if int(idx) >= len(data) || (i > 0 && idx <= indices[i-1]) {
+				t.Fatalf("Index %d out of order or range in %q: %v", idx, data, indices)
+			}
+		}
+
+		if !valid {
+			return
+		}
+		for _, l := range []scanner.Level{scanner.LevelSWAR, scanner.LevelSSE42, scanner.LevelAVX2, scanner.LevelNEON} {
+			if !scanner.SetLevel(l) {
+				continue
+			}
+			if err := s.Scan(data); err != nil {
+				t.Fatalf("Level %v: scan failed on %q: %v", l, data, err)
+			}
+			if got := s.GetStructuralIndices(); !reflect.DeepEqual(got, indices) {
+				t.Errorf("Level %v: expected %v, got %v for %q", l, indices, got, data)
+			}
+		}
+	})
+}
+
+// FuzzRoundTrip checks that re-encoding a decoded document is stable:
+// the output is valid and decodes to the same value. Numbers only keep
+// their value; 1e2 comes back as the integer 100.
+func FuzzRoundTrip(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var v interface{}
+		if Unmarshal(data, &v) != nil {
+			return
+		}
+		out, err := Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal failed for %q: %v", data, err)
+		}
+		if !Valid(out) {
+			t.Fatalf("Marshal produced invalid %q from %q", out, data)
+		}
+
+		var v2 interface{}
+		if err := Unmarshal(out, &v2); err != nil {
+			t.Fatalf("Unmarshal failed on re-encoded %q: %v", out, err)
+		}
+		if !deepEqual(v, v2) {
+			t.Errorf("Round trip of %q changed %#v to %#v", data, v, v2)
+		}
+		out2, err := Marshal(v2)
+		if err != nil || len(out2) != len(out) {
+			t.Errorf("Second encoding of %q differs: %q, then %q (%v)", data, out, out2, err)
+		}
+	})
+}
+
+// FuzzDifferential compares validation and decoding with encoding/json.
+// Two differences are by design: encoding/json replaces invalid UTF-8
+// with U+FFFD where simdjson rejects it, and encoding/json limits nesting
+// to 10000 levels.
+func FuzzDifferential(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if !utf8.Valid(data) {
+			return
+		}
+
+		if ours, std := Valid(data), json.Valid(data); ours != std {
+			t.Errorf("Valid(%q) = %v, encoding/json says %v", data, ours, std)
+		}
+
+		var ours, std interface{}
+		ourErr := Unmarshal(data, &ours)
+		stdErr := json.Unmarshal(data, &std)
+		if stdErr != nil && strings.Contains(stdErr.Error(), "nesting depth") {
+			return
+		}
+		if (ourErr == nil) != (stdErr == nil) {
+			t.Fatalf("Unmarshal(%q): simdjson error %v, encoding/json error %v", data, ourErr, stdErr)
+		}
+		if ourErr == nil && !deepEqual(ours, std) {
+			t.Errorf("Unmarshal(%q): simdjson %#v, encoding/json %#v", data, ours, std)
+		}
+
+		var ourBuf, stdBuf bytes.Buffer
+		if ourErr == nil {
+			// Both decoded the same value, so both encoders must produce
+			// documents that decode back to it
+			out, err := Marshal(ours)
+			if err != nil {
+				t.Fatalf("Marshal(%#v): %v", ours, err)
+			}
+			ourBuf.Write(out)
+			stdOut, _ := json.Marshal(std)
+			stdBuf.Write(stdOut)
+
+			var a, b interface{}
+			if json.Unmarshal(ourBuf.Bytes(), &a) != nil || json.Unmarshal(stdBuf.Bytes(), &b) != nil || !deepEqual(a, b) {
+				t.Errorf("Encodings of %q differ: %s vs %s", data, ourBuf.Bytes(), stdBuf.Bytes())
+			}
+		}
+	})
+}
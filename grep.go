@@ -0,0 +1,109 @@
+package simdjson
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// A PathPredicate selects NDJSON records by the value at a path, for
+// GrepNDJSON.
+type PathPredicate struct {
+	// Path is the key of a member of the top-level object, or the keys
+	// leading to it through nested objects separated by dots, as in
+	// "request.status".
+	Path string
+
+	// Match reports whether a record is selected, given its value at Path
+	// as written, or nil if it has none.
+	Match func(value []byte) bool
+}
+
+// PathEquals returns a PathPredicate that selects the records whose value
+// at path is value, compared as Equal compares documents, so "error"
+// matches "error" and 200 matches 2e2. It fails if value cannot be
+// marshaled.
+func PathEquals(path string, value interface{}) (PathPredicate, error) {
+	opts := DefaultMarshalOptions()
+	opts.EscapeHTML = false
+	want, err := MarshalWithOptions(value, &opts)
+	if err != nil {
+		return PathPredicate{}, err
+	}
+	return PathPredicate{Path: path, Match: func(got []byte) bool {
+		if got == nil || valueKind(got) != valueKind(want) {
+			return false
+		}
+		if bytes.Equal(got, want) {
+			return true
+		}
+		switch valueKind(got) {
+		case '0':
+			return equalNumbers(got, want)
+		case '"':
+			// Strings without escapes are only equal if written alike
+			if bytes.IndexByte(got, '\\') < 0 && bytes.IndexByte(want, '\\') < 0 {
+				return false
+			}
+		case '{', '[':
+		default:
+			return false
+		}
+		return Equal(got, want)
+	}}, nil
+}
+
+// valueKind returns the first byte of the JSON value v, or '0' for any
+// number.
+func valueKind(v []byte) byte {
+	if v[0] == '-' || v[0] >= '0' && v[0] <= '9' {
+		return '0'
+	}
+	return v[0]
+}
+
+// GrepNDJSON copies the NDJSON records read from r for which p selects to
+// w, as written, and returns how many it copied. Records are not parsed:
+// the value at p.Path is located with FindRaw, one key at a time, so
+// filtering large logs by a field costs little more than reading them, and
+// memory stays at about the longest record. Blank lines are dropped, and
+// records are not validated, so invalid ones are copied if p selects what
+// FindRaw finds in them.
+func GrepNDJSON(r io.Reader, p PathPredicate, w io.Writer) (int, error) {
+	keys := strings.Split(p.Path, ".")
+	br := bufio.NewReaderSize(r, streamBufferSize)
+	bw := bufio.NewWriterSize(w, streamBufferSize)
+	n := 0
+	var line []byte
+	for {
+		var err error
+		line, err = readLine(br, line[:0])
+		if len(bytes.TrimSpace(line)) > 0 && p.Match(valueAt(line, keys)) {
+			if _, err := bw.Write(line); err != nil {
+				return n, err
+			}
+			n++
+		}
+		if err == io.EOF {
+			return n, bw.Flush()
+		}
+		if err != nil {
+			bw.Flush()
+			return n, err
+		}
+	}
+}
+
+// valueAt returns the value at the path of keys in the object data, or
+// nil if there is none.
+func valueAt(data []byte, keys []string) []byte {
+	for _, key := range keys {
+		v, ok := FindRaw(data, key)
+		if !ok {
+			return nil
+		}
+		data = v
+	}
+	return data
+}
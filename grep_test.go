@@ -0,0 +1,83 @@
+package simdjson
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestGrepNDJSON(t *testing.T) {
+	input := strings.Join([]string{
+		`{"level":"error","msg":"a","req":{"status":500}}`,
+		`{"level":"info","msg":"error","req":{"status":200}}`,
+		``,
+		`{"msg":"b","level":"error","req":{"status":5e2}}`,
+		`{"level":"warn","req":{"status":"500"}}`,
+		`{"nested":{"level":"error"}}`,
+		`{"level":"error","msg":"<tag> & more"}` + "\r",
+		`{"level":"error"}`,
+	}, "\n")
+
+	tests := []struct {
+		path     string
+		value    interface{}
+		expected []int // indexes of the lines copied
+	}{
+		{"level", "error", []int{0, 3, 6, 7}},
+		{"req.status", 500, []int{0, 3}},
+		{"req.status", "500", []int{4}},
+		{"msg", "<tag> & more", []int{6}},
+		{"nested.level", "error", []int{5}},
+		{"missing", "x", nil},
+		{"req", map[string]int{"status": 200}, []int{1}},
+	}
+	lines := strings.Split(input, "\n")
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			p, err := PathEquals(tt.path, tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var expected strings.Builder
+			for _, i := range tt.expected {
+				expected.WriteString(lines[i])
+				if i < len(lines)-1 {
+					expected.WriteByte('\n')
+				}
+			}
+			var out bytes.Buffer
+			n, err := GrepNDJSON(iotest.HalfReader(strings.NewReader(input)), p, &out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(tt.expected) || out.String() != expected.String() {
+				t.Errorf("Expected %d lines %q, got %d lines %q", len(tt.expected), expected.String(), n, out.String())
+			}
+		})
+	}
+
+	// A custom predicate sees nil for records without the path
+	var out bytes.Buffer
+	n, err := GrepNDJSON(strings.NewReader(input), PathPredicate{
+		Path:  "msg",
+		Match: func(v []byte) bool { return v == nil },
+	}, &out)
+	if err != nil || n != 3 {
+		t.Errorf("Expected 3 records without msg, got %d (%v)", n, err)
+	}
+
+	if _, err := PathEquals("x", func() {}); err == nil {
+		t.Error("Expected an error for a value that cannot be marshaled")
+	}
+
+	readErr := errors.New("read failed")
+	p, _ := PathEquals("level", "error")
+	out.Reset()
+	n, err = GrepNDJSON(io.MultiReader(strings.NewReader(lines[0]+"\n"), iotest.ErrReader(readErr)), p, &out)
+	if err != readErr || n != 1 || out.String() != lines[0]+"\n" {
+		t.Errorf("Expected the lines before a read error, got %d %q (%v)", n, out.String(), err)
+	}
+}
@@ -0,0 +1,154 @@
+package simdjson
+
+import (
+	"encoding/binary"
+	"slices"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// Hash returns a 64-bit hash of the JSON value in data that agrees with
+// Equal: documents that are Equal hash the same, whatever their member
+// order, whitespace, string escapes or spelling of numbers, so a hash can
+// key a cache or deduplicate documents without canonicalizing them first.
+// It is computed over the tokens without decoding, is the same on every
+// platform and run, and is not cryptographic. Invalid documents hash to 0.
+func Hash(data []byte) uint64 {
+	s := scanner.New()
+	defer s.Release()
+	d, err := parseDocument(s, data)
+	if err != nil {
+		return 0
+	}
+	defer d.release()
+
+	h := &hasher{d: d}
+	return finalizeHash(h.value(0))
+}
+
+const hashPrime = 0x9e3779b97f4a7c15
+
+// Seeds that tell the kinds of values apart
+const (
+	hashNull uint64 = iota + 1
+	hashTrue
+	hashFalse
+	hashNumber
+	hashString
+	hashArray
+	hashObject
+	hashMember
+)
+
+type hasher struct {
+	d *document
+	// Key and member hashes of the objects being hashed, innermost last
+	members []memberHash
+}
+
+type memberHash struct {
+	key, member uint64
+}
+
+// value returns the hash of the value at pos.
+func (h *hasher) value(pos int) uint64 {
+	d := h.d
+	switch d.kind(pos) {
+	case scanner.TokenNull:
+		return mixHash(hashNull, 0)
+	case scanner.TokenTrue:
+		return mixHash(hashTrue, 0)
+	case scanner.TokenFalse:
+		return mixHash(hashFalse, 0)
+	case scanner.TokenString:
+		return hashBytes(hashString, d.str(pos))
+	case scanner.TokenNumber:
+		raw := d.raw(pos)
+		neg, digits, exp, ok := decimalParts(raw)
+		if !ok {
+			// Equal only to numbers spelled the same
+			return hashBytes(hashNumber, raw)
+		}
+		sum := hashBytes(hashNumber, digits)
+		if len(digits) == 0 {
+			// -0 equals 0, whatever the exponent
+			return sum
+		}
+		if neg {
+			sum = mixHash(sum, 1)
+		}
+		return mixHash(sum, uint64(exp))
+	case scanner.TokenArrayBegin:
+		sum, n := uint64(hashArray), uint64(0)
+		d.elements(pos, func(elem int) {
+			sum = mixHash(sum, h.value(elem))
+			n++
+		})
+		return mixHash(sum, n)
+	case scanner.TokenObjectBegin:
+		return h.object(pos)
+	}
+	return 0
+}
+
+// object returns the hash of the object at pos, combining its members in
+// order of their key hashes so that member order does not matter. Of
+// duplicate keys only the last counts, as in Equal.
+func (h *hasher) object(pos int) uint64 {
+	d := h.d
+	start := len(h.members)
+	d.members(pos, func(k, v int) {
+		key := hashBytes(hashMember, d.str(k))
+		member := mixHash(key, h.value(v))
+		h.members = append(h.members, memberHash{key, member})
+	})
+	members := h.members[start:]
+	slices.SortStableFunc(members, func(a, b memberHash) int {
+		switch {
+		case a.key < b.key:
+			return -1
+		case a.key > b.key:
+			return 1
+		}
+		return 0
+	})
+
+	sum, n := uint64(hashObject), uint64(0)
+	for i, m := range members {
+		if i+1 < len(members) && members[i+1].key == m.key {
+			continue
+		}
+		sum = mixHash(sum, m.member)
+		n++
+	}
+	h.members = h.members[:start]
+	return mixHash(sum, n)
+}
+
+func mixHash(h, x uint64) uint64 {
+	h = (h ^ x) * hashPrime
+	return h ^ h>>29
+}
+
+// hashBytes mixes b into h eight bytes at a time.
+func hashBytes(h uint64, b []byte) uint64 {
+	for ; len(b) >= 8; b = b[8:] {
+		h = mixHash(h, binary.LittleEndian.Uint64(b))
+	}
+	// The length goes in the top byte, which the tail never fills
+	tail := uint64(len(b)) << 56
+	for i, c := range b {
+		tail |= uint64(c) << (8 * i)
+	}
+	return mixHash(h, tail)
+}
+
+// finalizeHash spreads every input bit over the result, as MurmurHash3's
+// finalizer does.
+func finalizeHash(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	return h ^ h>>33
+}
@@ -0,0 +1,65 @@
+package simdjson
+
+import (
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	same := []struct {
+		name string
+		a, b string
+	}{
+		{"whitespace", `{"a":[1,2]}`, " { \"a\" : [ 1 , 2 ] } "},
+		{"member order", `{"a":1,"b":{"c":true,"d":null}}`, `{"b":{"d":null,"c":true},"a":1}`},
+		{"string escapes", `"café/"`, `"caf\u00e9\/"`},
+		{"key escapes", `{"a":1}`, `{"\u0061":1}`},
+		{"number spelling", `[1, 1.5, 100, -0.25]`, `[1.0, 15e-1, 1E2, -2.50e-1]`},
+		{"negative zero", `[0, -0]`, `[0.0, 0e5]`},
+		{"duplicate keys", `{"a":1,"b":2,"a":3}`, `{"b":2,"a":3}`},
+		{"large integers", `12345678901234567890123`, `1.2345678901234567890123e22`},
+	}
+	for _, tt := range same {
+		t.Run(tt.name, func(t *testing.T) {
+			if !Equal([]byte(tt.a), []byte(tt.b)) {
+				t.Fatalf("Expected %s and %s to be Equal", tt.a, tt.b)
+			}
+			if ha, hb := Hash([]byte(tt.a)), Hash([]byte(tt.b)); ha != hb {
+				t.Errorf("Expected equal hashes, got %x and %x", ha, hb)
+			}
+		})
+	}
+
+	different := []struct {
+		name string
+		a, b string
+	}{
+		{"value", `{"a":1}`, `{"a":2}`},
+		{"key", `{"a":1}`, `{"b":1}`},
+		{"element order", `[1,2]`, `[2,1]`},
+		{"nesting", `[[1],2]`, `[1,[2]]`},
+		{"kinds", `"1"`, `1`},
+		{"null and false", `null`, `false`},
+		{"empty containers", `[]`, `{}`},
+		{"sign", `1`, `-1`},
+		{"exponent", `1`, `10`},
+		{"extra member", `{"a":1}`, `{"a":1,"b":1}`},
+		{"swapped values", `{"a":1,"b":2}`, `{"a":2,"b":1}`},
+		{"long strings", `"abcdefghijklmnop"`, `"abcdefghijklmnoq"`},
+		{"string lengths", `"a"`, `"a\u0000"`},
+	}
+	for _, tt := range different {
+		t.Run(tt.name, func(t *testing.T) {
+			if ha, hb := Hash([]byte(tt.a)), Hash([]byte(tt.b)); ha == hb {
+				t.Errorf("Expected different hashes for %s and %s, got %x", tt.a, tt.b, ha)
+			}
+		})
+	}
+
+	if h := Hash([]byte(`{"a":`)); h != 0 {
+		t.Errorf("Expected 0 for invalid JSON, got %x", h)
+	}
+	// The hash must not change between releases
+	if h := Hash([]byte(`{"id":1,"tags":["a","b"]}`)); h != 0xd27a92fa38f8b843 {
+		t.Errorf("Expected the hash of earlier releases, got %x", h)
+	}
+}
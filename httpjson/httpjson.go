@@ -0,0 +1,139 @@
+// Package httpjson connects simdjson to net/http. It takes care of the
+// details hand-written handlers tend to get wrong: request size limits,
+// content-type checks, buffer reuse, and not sending a status line before
+// the response body is known to encode.
+package httpjson
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+)
+
+// DefaultMaxBodySize is the largest request body DecodeRequest accepts.
+const DefaultMaxBodySize = 1 << 20
+
+var (
+	ErrUnsupportedMediaType = errors.New("httpjson: content type is not JSON")
+	ErrBodyTooLarge         = errors.New("httpjson: request body too large")
+	ErrEmptyBody            = errors.New("httpjson: empty request body")
+)
+
+// Buffers that grew past this size are dropped instead of pooled, so one
+// large request does not pin its buffer.
+const maxPooledBuffer = 64 * 1024
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// DecodeRequest decodes the JSON body of r into v. It is
+// DecodeRequestLimit with DefaultMaxBodySize.
+func DecodeRequest(r *http.Request, v interface{}) error {
+	return DecodeRequestLimit(r, v, DefaultMaxBodySize)
+}
+
+// DecodeRequestLimit decodes the JSON body of r into v, reading at most
+// limit bytes into a pooled buffer. It fails with ErrUnsupportedMediaType
+// if the request declares a Content-Type other than application/json or a
+// +json type, and with ErrBodyTooLarge or ErrEmptyBody as appropriate;
+// StatusCode maps these to HTTP statuses. The body is not closed.
+func DecodeRequestLimit(r *http.Request, v interface{}, limit int64) error {
+	if !isJSON(r.Header.Get("Content-Type")) {
+		return ErrUnsupportedMediaType
+	}
+	if r.ContentLength > limit {
+		return ErrBodyTooLarge
+	}
+	if r.Body == nil {
+		return ErrEmptyBody
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			bufferPool.Put(buf)
+		}
+	}()
+	if r.ContentLength > 0 {
+		buf.Grow(int(r.ContentLength))
+	}
+
+	// One byte past the limit tells a body of exactly limit bytes from a
+	// longer one
+	n, err := buf.ReadFrom(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return ErrBodyTooLarge
+	}
+	if n == 0 {
+		return ErrEmptyBody
+	}
+
+	// Unmarshal copies strings out of the input, so v does not refer to
+	// the buffer once it is back in the pool
+	return simdjson.Unmarshal(buf.Bytes(), v)
+}
+
+// isJSON reports whether contentType is empty or names a JSON media type.
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// StatusCode returns the HTTP status to answer a DecodeRequest error with:
+// 415 and 413 for ErrUnsupportedMediaType and ErrBodyTooLarge, 400 for
+// anything else.
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, ErrBodyTooLarge):
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// WriteJSON writes v as a JSON response with status code. The value is
+// encoded into a pooled buffer before anything is sent, so when encoding
+// fails WriteJSON returns the error with the response still untouched and
+// the caller can answer with an error status instead. Content-Type is set
+// unless the handler set it already, and Content-Length always.
+func WriteJSON(w http.ResponseWriter, code int, v interface{}) error {
+	return simdjson.NewEncoder(&responseWriter{w: w, code: code}).Encode(v)
+}
+
+// responseWriter sends the headers on the first Write. Encoder.Encode
+// writes the whole document in one call, which gives its length.
+type responseWriter struct {
+	w    http.ResponseWriter
+	code int
+}
+
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	h := rw.w.Header()
+	if h.Get("Content-Type") == "" {
+		h.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	h.Set("Content-Length", strconv.Itoa(len(p)))
+	rw.w.WriteHeader(rw.code)
+	return rw.w.Write(p)
+}
@@ -0,0 +1,136 @@
+package httpjson
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type payload struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestDecodeRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		err         error
+		status      int
+	}{
+		{"json", "application/json", `{"name":"a","age":3}`, nil, 0},
+		{"charset", "application/json; charset=utf-8", `{"name":"a"}`, nil, 0},
+		{"suffix", "application/vnd.api+json", `{}`, nil, 0},
+		{"no content type", "", `{}`, nil, 0},
+		{"text", "text/plain", `{}`, ErrUnsupportedMediaType, http.StatusUnsupportedMediaType},
+		{"malformed content type", "application/", `{}`, ErrUnsupportedMediaType, http.StatusUnsupportedMediaType},
+		{"empty", "application/json", ``, ErrEmptyBody, http.StatusBadRequest},
+		{"too large", "application/json", `{"name":"` + strings.Repeat("x", DefaultMaxBodySize) + `"}`, ErrBodyTooLarge, http.StatusRequestEntityTooLarge},
+		{"invalid", "application/json", `{"name":}`, nil, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			var p payload
+			err := DecodeRequest(r, &p)
+			if tt.status == 0 {
+				if err != nil {
+					t.Fatalf("DecodeRequest failed: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("Expected %v, got %v", tt.err, err)
+			}
+			if got := StatusCode(err); got != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, got)
+			}
+		})
+	}
+}
+
+func TestDecodeRequestLimit(t *testing.T) {
+	body := `{"name":"abc"}`
+
+	// A body of exactly limit bytes is accepted, also without a
+	// Content-Length to reject it early
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.ContentLength = -1
+	var p payload
+	if err := DecodeRequestLimit(r, &p, int64(len(body))); err != nil || p.Name != "abc" {
+		t.Errorf("Expected %q, got %q (%v)", "abc", p.Name, err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.ContentLength = -1
+	if err := DecodeRequestLimit(r, &p, int64(len(body)-1)); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("Expected ErrBodyTooLarge, got %v", err)
+	}
+}
+
+func TestDecodeRequestBufferReuse(t *testing.T) {
+	// Decoded strings must not alias the pooled buffer
+	var first payload
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"first"}`))
+	if err := DecodeRequest(r, &first); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		var p payload
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"other"}`))
+		if err := DecodeRequest(r, &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if first.Name != "first" {
+		t.Errorf("Expected %q, got %q", "first", first.Name)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := WriteJSON(w, http.StatusCreated, payload{Name: "a", Age: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("Unexpected Content-Type %q", ct)
+	}
+	if got := w.Body.String(); got != `{"name":"a","age":3}` {
+		t.Errorf("Unexpected body %s", got)
+	}
+	if cl := w.Header().Get("Content-Length"); cl != "20" {
+		t.Errorf("Expected Content-Length 20, got %q", cl)
+	}
+
+	// A handler-set Content-Type is kept
+	w = httptest.NewRecorder()
+	w.Header().Set("Content-Type", "application/problem+json")
+	if err := WriteJSON(w, http.StatusBadRequest, map[string]string{"title": "bad"}); err != nil {
+		t.Fatal(err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Expected Content-Type to be kept, got %q", ct)
+	}
+
+	// Encoding errors leave the response untouched
+	w = httptest.NewRecorder()
+	if err := WriteJSON(w, http.StatusOK, map[string]interface{}{"ch": make(chan int)}); err == nil {
+		t.Fatal("Expected error")
+	}
+	if w.Body.Len() != 0 || len(w.Header()) != 0 {
+		t.Errorf("Expected untouched response, got %d bytes and %v", w.Body.Len(), w.Header())
+	}
+}
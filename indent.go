@@ -0,0 +1,354 @@
+package simdjson
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/jsonenc"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// indentChunk is how much IndentStream reads at a time, and how much
+// output it holds before writing it.
+const indentChunk = 64 << 10
+
+// IndentStream copies the JSON values read from r to w, each on its own
+// line and indented by indent per level of nesting, or compacted if indent
+// is empty. r may hold any number of values separated by whitespace, as in
+// NDJSON.
+//
+// The input is read in chunks and indexed with the incremental scanner, and
+// strings and numbers are copied as written between structural indices
+// without being decoded, so memory stays constant however large the
+// input, apart from a byte per level of nesting. The grammar is checked as
+// the values are copied, but the contents of strings are not. Invalid
+// input returns a *SyntaxError, by which time w may have been given the
+// output for some of the input before it.
+func IndentStream(r io.Reader, w io.Writer, indent string) error {
+	s := scanner.New()
+	defer s.Release()
+
+	ind := &indenter{w: w, indent: indent, expect: expectTop}
+	buf := make([]byte, indentChunk)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			s.Feed(buf[:n])
+			if err := ind.chunk(buf[:n], s.GetStructuralIndices(), s.Offset()); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := ind.finish(s.Finish()); err != nil {
+		return err
+	}
+	_, err := w.Write(ind.out)
+	return err
+}
+
+// expectTop is the state between top-level values, where either another
+// value or the end of the input may follow. The other states are the
+// scanner's Expect descriptions.
+const expectTop = "value or end of input"
+
+// indenter is the state IndentStream carries from one chunk to the next.
+type indenter struct {
+	w      io.Writer
+	indent string
+	out    []byte // output not yet written to w
+
+	stack  []byte // '{' or '[' for each open container
+	expect string // what the grammar allows next
+	open   bool   // a container was opened and its first member not seen
+
+	inString bool   // copying a string that continues into the next chunk
+	key      bool   // the string is an object key
+	inScalar bool   // reading a number or literal that continues
+	scalar   []byte // the bytes of that number or literal so far
+
+	// For locating errors: where the chunk starts and ends, the lines
+	// before it and the offset at which the current line starts
+	offset, end int64
+	lines       int
+	lineStart   int64
+}
+
+// chunk copies the next chunk of input, whose structural indices are idx
+// and which starts at offset in the stream.
+func (ind *indenter) chunk(chunk []byte, idx []uint32, offset int64) error {
+	ind.offset = offset
+	start := 0
+	switch {
+	case ind.inString:
+		if len(idx) == 0 {
+			ind.out = append(ind.out, chunk...)
+			return ind.next(chunk)
+		}
+		// The closing quote, as indices are not emitted inside strings
+		end := int(idx[0]) + 1
+		ind.out = append(ind.out, chunk[:end]...)
+		ind.inString = false
+		ind.endString()
+		idx = idx[1:]
+	case ind.inScalar:
+		start = scalarEnd(chunk, 0)
+		ind.scalar = append(ind.scalar, chunk[:start]...)
+		if start == len(chunk) {
+			return ind.next(chunk)
+		}
+		ind.inScalar = false
+		if err := ind.endScalar(ind.scalar, chunk, -int(len(ind.scalar)-start)); err != nil {
+			return err
+		}
+	}
+
+	for k := 0; k < len(idx); k++ {
+		i := int(idx[k])
+		c := chunk[i]
+		switch c {
+		case '{', '[':
+			if err := ind.beginValue(chunk, i); err != nil {
+				return err
+			}
+			if len(ind.stack) >= scanner.MaxDepth {
+				return errors.New("exceeded max nesting depth")
+			}
+			ind.stack = append(ind.stack, c)
+			ind.out = append(ind.out, c)
+			ind.open = true
+			ind.expect = scanner.ExpectValueOrEnd
+			if c == '{' {
+				ind.expect = scanner.ExpectKeyOrEnd
+			}
+		case '}', ']':
+			if !ind.closes(c) {
+				return ind.syntaxError(chunk, i)
+			}
+			ind.stack = ind.stack[:len(ind.stack)-1]
+			if ind.open {
+				ind.open = false
+			} else {
+				ind.newline()
+			}
+			ind.out = append(ind.out, c)
+			ind.endValue()
+		case ',':
+			if ind.expect != scanner.ExpectObjectNext && ind.expect != scanner.ExpectArrayNext {
+				return ind.syntaxError(chunk, i)
+			}
+			ind.out = append(ind.out, ',')
+			ind.newline()
+			ind.expect = scanner.ExpectValue
+			if ind.stack[len(ind.stack)-1] == '{' {
+				ind.expect = scanner.ExpectKey
+			}
+		case ':':
+			if ind.expect != scanner.ExpectColon {
+				return ind.syntaxError(chunk, i)
+			}
+			ind.out = append(ind.out, ':')
+			if ind.indent != "" {
+				ind.out = append(ind.out, ' ')
+			}
+			ind.expect = scanner.ExpectValue
+		case '"':
+			ind.key = ind.expect == scanner.ExpectKey || ind.expect == scanner.ExpectKeyOrEnd
+			if ind.key {
+				ind.beginMember()
+			} else if err := ind.beginValue(chunk, i); err != nil {
+				return err
+			}
+			if k+1 == len(idx) {
+				ind.out = append(ind.out, chunk[i:]...)
+				ind.inString = true
+				return ind.next(chunk)
+			}
+			k++
+			ind.out = append(ind.out, chunk[i:idx[k]+1]...)
+			ind.endString()
+		default:
+			if err := ind.beginValue(chunk, i); err != nil {
+				return err
+			}
+			end := scalarEnd(chunk, i)
+			if end == len(chunk) {
+				ind.scalar = append(ind.scalar[:0], chunk[i:]...)
+				ind.inScalar = true
+				return ind.next(chunk)
+			}
+			if err := ind.endScalar(chunk[i:end], chunk, i); err != nil {
+				return err
+			}
+		}
+	}
+	return ind.next(chunk)
+}
+
+// next finishes with chunk, counting its lines and writing the output if
+// enough has built up.
+func (ind *indenter) next(chunk []byte) error {
+	ind.end = ind.offset + int64(len(chunk))
+	ind.lines += bytes.Count(chunk, []byte{'\n'})
+	if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+		ind.lineStart = ind.offset + int64(i) + 1
+	}
+	if len(ind.out) < indentChunk {
+		return nil
+	}
+	_, err := ind.w.Write(ind.out)
+	ind.out = ind.out[:0]
+	return err
+}
+
+// finish checks the state at the end of the input, given the error from
+// the scanner's Finish.
+func (ind *indenter) finish(err error) error {
+	ind.offset = ind.end
+	if err != nil {
+		return ind.syntaxErrorAt(nil, 0, scanner.ExpectQuote)
+	}
+	if ind.inScalar {
+		ind.inScalar = false
+		if err := ind.endScalar(ind.scalar, nil, -len(ind.scalar)); err != nil {
+			return err
+		}
+	}
+	if ind.expect != expectTop {
+		return ind.syntaxErrorAt(nil, 0, ind.expect)
+	}
+	return nil
+}
+
+// beginMember starts a key or array element, breaking the line after the
+// bracket of a container just opened.
+func (ind *indenter) beginMember() {
+	if ind.open {
+		ind.open = false
+		ind.newline()
+	}
+}
+
+// beginValue starts the value at chunk[i], or reports that none may
+// start there.
+func (ind *indenter) beginValue(chunk []byte, i int) error {
+	switch ind.expect {
+	case scanner.ExpectValue, scanner.ExpectValueOrEnd, expectTop:
+		ind.beginMember()
+		return nil
+	}
+	return ind.syntaxError(chunk, i)
+}
+
+// endValue moves past a complete value.
+func (ind *indenter) endValue() {
+	switch {
+	case len(ind.stack) == 0:
+		ind.out = append(ind.out, '\n')
+		ind.expect = expectTop
+	case ind.stack[len(ind.stack)-1] == '{':
+		ind.expect = scanner.ExpectObjectNext
+	default:
+		ind.expect = scanner.ExpectArrayNext
+	}
+}
+
+// endString moves past a complete key or string value.
+func (ind *indenter) endString() {
+	if ind.key {
+		ind.expect = scanner.ExpectColon
+	} else {
+		ind.endValue()
+	}
+}
+
+// endScalar checks and copies the number or literal b, which starts at
+// chunk[i]; i is negative if it started in an earlier chunk.
+func (ind *indenter) endScalar(b, chunk []byte, i int) error {
+	switch string(b) {
+	case "true", "false", "null":
+	default:
+		if !jsonenc.ValidNumber(b) {
+			expected := scanner.ExpectLiteral
+			if b[0] == '-' || b[0] >= '0' && b[0] <= '9' {
+				expected = scanner.ExpectDigit
+			}
+			return ind.syntaxErrorAt(chunk, i, expected)
+		}
+	}
+	ind.out = append(ind.out, b...)
+	ind.endValue()
+	return nil
+}
+
+// closes reports whether the bracket c may close the innermost container.
+func (ind *indenter) closes(c byte) bool {
+	if len(ind.stack) == 0 || ind.stack[len(ind.stack)-1] != c-2 {
+		// '{' and '[' are two below their closing brackets
+		return false
+	}
+	switch ind.expect {
+	case scanner.ExpectObjectNext, scanner.ExpectKeyOrEnd:
+		return c == '}'
+	case scanner.ExpectArrayNext, scanner.ExpectValueOrEnd:
+		return c == ']'
+	}
+	return false
+}
+
+// newline breaks the line and indents it to the current depth.
+func (ind *indenter) newline() {
+	if ind.indent == "" {
+		return
+	}
+	ind.out = append(ind.out, '\n')
+	for range ind.stack {
+		ind.out = append(ind.out, ind.indent...)
+	}
+}
+
+// syntaxError reports that chunk[i] is not what the grammar allows.
+func (ind *indenter) syntaxError(chunk []byte, i int) error {
+	return ind.syntaxErrorAt(chunk, i, ind.expect)
+}
+
+// syntaxErrorAt returns a *SyntaxError at chunk[i], or i bytes before
+// chunk if i is negative; chunk may be nil at the end of the input.
+func (ind *indenter) syntaxErrorAt(chunk []byte, i int, expected string) error {
+	offset := ind.offset + int64(i)
+	line, lineStart := ind.lines+1, ind.lineStart
+	if i > 0 {
+		line += bytes.Count(chunk[:i], []byte{'\n'})
+		if j := bytes.LastIndexByte(chunk[:i], '\n'); j >= 0 {
+			lineStart = ind.offset + int64(j) + 1
+		}
+	}
+	if expected == expectTop {
+		expected = scanner.ExpectEOF
+	}
+	return &SyntaxError{
+		Offset:   offset,
+		Line:     line,
+		Column:   int(offset-lineStart) + 1,
+		Expected: expected,
+	}
+}
+
+// scalarEnd returns the offset in chunk of the first byte from i on that
+// cannot be part of a number or literal.
+func scalarEnd(chunk []byte, i int) int {
+	for i < len(chunk) {
+		switch chunk[i] {
+		case ' ', '\t', '\n', '\r', '{', '}', '[', ']', ':', ',', '"':
+			return i
+		}
+		i++
+	}
+	return i
+}
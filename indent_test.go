@@ -0,0 +1,134 @@
+package simdjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestIndentStream(t *testing.T) {
+	docs := []string{
+		`{"a":[1,{"b":null},[]],"c":{},"d":"x"}`,
+		` [ "esc\"aped \\", "é", -1.5e+10 , true,false ] `,
+		`{"nested":{"deeper":{"deepest":[[[]]]}},"empty":""}`,
+		`"just a string"`,
+		`-0`,
+		`{}`,
+	}
+	for i, doc := range docs {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			for _, indent := range []string{"", "  ", "\t"} {
+				var expected bytes.Buffer
+				if indent == "" {
+					json.Compact(&expected, []byte(doc))
+				} else {
+					json.Indent(&expected, []byte(strings.TrimSpace(doc)), "", indent)
+				}
+				expected.WriteByte('\n')
+
+				var out bytes.Buffer
+				if err := IndentStream(strings.NewReader(doc), &out, indent); err != nil {
+					t.Fatal(err)
+				}
+				if out.String() != expected.String() {
+					t.Errorf("Expected %q, got %q", expected.String(), out.String())
+				}
+
+				// Strings and numbers split across reads
+				out.Reset()
+				if err := IndentStream(iotest.OneByteReader(strings.NewReader(doc)), &out, indent); err != nil {
+					t.Fatal(err)
+				}
+				if out.String() != expected.String() {
+					t.Errorf("Expected %q a byte at a time, got %q", expected.String(), out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestIndentStreamValues(t *testing.T) {
+	var out bytes.Buffer
+	if err := IndentStream(strings.NewReader("{\"a\":1}\n[2]\n3 null\n"), &out, " "); err != nil {
+		t.Fatal(err)
+	}
+	expected := "{\n \"a\": 1\n}\n[\n 2\n]\n3\nnull\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+
+	out.Reset()
+	if err := IndentStream(strings.NewReader(" \n"), &out, " "); err != nil || out.Len() != 0 {
+		t.Errorf("Expected no output for no values, got %q, %v", out.String(), err)
+	}
+}
+
+func TestIndentStreamLarge(t *testing.T) {
+	var doc bytes.Buffer
+	doc.WriteString(`{"items":[`)
+	for i := 0; i < 20000; i++ {
+		if i > 0 {
+			doc.WriteByte(',')
+		}
+		doc.WriteString(`{"id":` + strconv.Itoa(i) + `,"name":"item ` + strconv.Itoa(i) + `","tags":["x","y"]}`)
+	}
+	doc.WriteString(`]}`)
+
+	var expected bytes.Buffer
+	json.Indent(&expected, doc.Bytes(), "", "    ")
+	expected.WriteByte('\n')
+	var out bytes.Buffer
+	if err := IndentStream(bytes.NewReader(doc.Bytes()), &out, "    "); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), expected.Bytes()) {
+		t.Errorf("Expected %d bytes as json.Indent writes them, got %d", expected.Len(), out.Len())
+	}
+}
+
+func TestIndentStreamErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		offset   int64
+		line     int
+		column   int
+		expected string
+	}{
+		{`[1,]`, 3, 1, 4, "value"},
+		{`{"a" 1}`, 5, 1, 6, "':'"},
+		{`[1}`, 2, 1, 3, "',' or ']'"},
+		{`{1:2}`, 1, 1, 2, "string key or '}'"},
+		{`{"a":1,}`, 7, 1, 8, "string key"},
+		{"[\n  tru\n]", 4, 2, 3, "literal true, false or null"},
+		{`[01]`, 1, 1, 2, "digit"},
+		{`{"a":1`, 6, 1, 7, "',' or '}'"},
+		{`"abc`, 4, 1, 5, "closing '\"'"},
+		{`1]`, 1, 1, 2, "end of input"},
+		{`1.`, 0, 1, 1, "digit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			err := IndentStream(strings.NewReader(tt.input), &bytes.Buffer{}, "  ")
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("Expected a *SyntaxError, got %v", err)
+			}
+			if se.Offset != tt.offset || se.Line != tt.line || se.Column != tt.column || se.Expected != tt.expected {
+				t.Errorf("Expected %s at offset %d (%d:%d), got %s at offset %d (%d:%d)",
+					tt.expected, tt.offset, tt.line, tt.column, se.Expected, se.Offset, se.Line, se.Column)
+			}
+			if !errors.Is(err, ErrInvalidJSON) {
+				t.Error("Expected the error to match ErrInvalidJSON")
+			}
+
+			err = IndentStream(iotest.OneByteReader(strings.NewReader(tt.input)), &bytes.Buffer{}, "")
+			if !errors.As(err, &se) || se.Offset != tt.offset || se.Line != tt.line || se.Column != tt.column {
+				t.Errorf("Expected the same error a byte at a time, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package simdjson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type inlineMeta struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Kind      string `json:"kind"`
+}
+
+type inlineStatus struct {
+	Ready bool `json:"ready"`
+}
+
+type inlineDeployment struct {
+	Kind     string        `json:"kind"`
+	Meta     inlineMeta    `json:",inline"`
+	Status   *inlineStatus `json:",inline"`
+	Replicas int           `json:"replicas"`
+}
+
+func TestInline(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    inlineDeployment
+		expected string
+	}{
+		{
+			"nil_pointer",
+			inlineDeployment{Kind: "Deployment", Meta: inlineMeta{Name: "web", Kind: "ignored"}, Replicas: 3},
+			`{"kind":"Deployment","name":"web","replicas":3}`,
+		},
+		{
+			"pointer",
+			inlineDeployment{Meta: inlineMeta{Name: "web", Namespace: "prod"}, Status: &inlineStatus{Ready: true}},
+			`{"kind":"","name":"web","namespace":"prod","ready":true,"replicas":0}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+
+			var back inlineDeployment
+			if err := Unmarshal(data, &back); err != nil {
+				t.Fatal(err)
+			}
+			tt.value.Meta.Kind = "" // shadowed by the outer kind
+			if !reflect.DeepEqual(back, tt.value) {
+				t.Errorf("Expected %+v, got %+v", tt.value, back)
+			}
+		})
+	}
+}
+
+func TestInlineDecodeAllocates(t *testing.T) {
+	var d inlineDeployment
+	if err := Unmarshal([]byte(`{"ready":true,"name":"a"}`), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Status == nil || !d.Status.Ready || d.Meta.Name != "a" {
+		t.Errorf("Expected the status allocated and ready, got %+v", d)
+	}
+
+	d = inlineDeployment{}
+	if err := Unmarshal([]byte(`{"name":"b"}`), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Status != nil {
+		t.Errorf("Expected no status without its fields, got %+v", d.Status)
+	}
+}
+
+type inlineNode struct {
+	ID   int         `json:"id"`
+	Next *inlineNode `json:",inline"`
+}
+
+func TestInlineRecursive(t *testing.T) {
+	// A type cannot be inlined into itself, so Next is an ordinary field
+	data, err := Marshal(inlineNode{ID: 1, Next: &inlineNode{ID: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"id":1,"Next":{"id":2,"Next":null}}`; string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func TestInlineNotStruct(t *testing.T) {
+	v := struct {
+		Tags []string `json:"tags,inline"`
+	}{Tags: []string{"a"}}
+	data, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"tags":["a"]}`; string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+type embedX struct{ X int }
+
+type embedY struct {
+	X int
+	Y int `json:"y"`
+}
+
+type EmbedY embedY
+
+type embedZ struct {
+	X int `json:"X"`
+}
+
+type embedLower struct{ Lower string }
+
+type EmbedCount int
+
+type embedOuter struct {
+	embedX
+	Y int
+}
+
+type embedPointer struct {
+	*EmbedY
+	Z int
+}
+
+type embedMixed struct {
+	embedLower
+	EmbedCount
+	Named embedX `json:"named"`
+	Tag   embedX `json:"tag"`
+}
+
+type embedTagged struct {
+	embedX `json:"inner"`
+}
+
+type embedConflict struct {
+	embedX
+	embedY
+}
+
+type embedTagWins struct {
+	embedX
+	embedZ
+}
+
+type embedOuterWins struct {
+	embedY
+	Y string `json:"y"`
+}
+
+// TestEmbedded checks that untagged embedded structs are flattened and
+// their names resolved like encoding/json does, both ways
+func TestEmbedded(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		input string
+	}{
+		{"flattened", embedOuter{embedX{1}, 2}, `{"X":3,"Y":4}`},
+		{"pointer", embedPointer{&EmbedY{1, 2}, 3}, `{"X":4,"y":5,"Z":6}`},
+		{"nil_pointer", embedPointer{Z: 3}, `{"Z":4}`},
+		{"mixed", embedMixed{embedLower{"a"}, 5, embedX{1}, embedX{2}}, `{"Lower":"b","EmbedCount":6,"named":{"X":7}}`},
+		{"tagged", embedTagged{embedX{1}}, `{"inner":{"X":2}}`},
+		{"conflict", embedConflict{embedX{1}, embedY{2, 3}}, `{"X":4,"y":5}`},
+		{"tag_wins", embedTagWins{embedX{1}, embedZ{2}}, `{"X":3}`},
+		{"outer_wins", embedOuterWins{embedY{1, 2}, "s"}, `{"X":3,"y":"t"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("Expected %s, got %s", want, got)
+			}
+
+			typ := reflect.TypeOf(tt.value)
+			expected, decoded := reflect.New(typ), reflect.New(typ)
+			if err := json.Unmarshal([]byte(tt.input), expected.Interface()); err != nil {
+				t.Fatal(err)
+			}
+			if err := Unmarshal([]byte(tt.input), decoded.Interface()); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(expected.Elem().Interface(), decoded.Elem().Interface()) {
+				t.Errorf("Expected %+v, got %+v", expected.Elem(), decoded.Elem())
+			}
+		})
+	}
+}
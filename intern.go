@@ -0,0 +1,38 @@
+package simdjson
+
+// Bounds of a Parser's key intern table. Documents whose keys are data
+// (IDs, timestamps) rather than a schema would otherwise grow the table
+// without limit; once it is full, new keys are allocated as usual.
+const (
+	maxInternedKeys   = 4096
+	maxInternedKeyLen = 64
+)
+
+// internTable dedupes object keys so that the same key repeated across
+// many records, and across documents parsed by the same Parser, shares one
+// string allocation.
+type internTable struct {
+	m map[string]string
+}
+
+func newInternTable() *internTable {
+	return &internTable{m: make(map[string]string)}
+}
+
+// reset forgets every key, keeping the map's memory.
+func (t *internTable) reset() {
+	clear(t.m)
+}
+
+// intern returns a string equal to b, allocating only the first time a
+// key is seen.
+func (t *internTable) intern(b []byte) string {
+	if s, ok := t.m[string(b)]; ok {
+		return s
+	}
+	s := string(b)
+	if len(b) <= maxInternedKeyLen && len(t.m) < maxInternedKeys {
+		t.m[s] = s
+	}
+	return s
+}
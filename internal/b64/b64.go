@@ -0,0 +1,50 @@
+// Package b64 encodes and decodes base64 as encoding/base64 does, with
+// vector instructions for the bulk of the input where the CPU has them:
+// AVX2 on amd64. Elsewhere, and for what is left over, it calls
+// encoding/base64.
+package b64
+
+import (
+	"encoding/base64"
+	"slices"
+)
+
+// An Encoding is one of the four encodings of encoding/base64.
+type Encoding struct {
+	enc *base64.Encoding
+	url bool // the URL-safe alphabet
+}
+
+var (
+	StdEncoding    = &Encoding{base64.StdEncoding, false}
+	URLEncoding    = &Encoding{base64.URLEncoding, true}
+	RawStdEncoding = &Encoding{base64.RawStdEncoding, false}
+	RawURLEncoding = &Encoding{base64.RawURLEncoding, true}
+)
+
+// AppendEncode appends the encoding of src to dst.
+func (e *Encoding) AppendEncode(dst, src []byte) []byte {
+	n := e.enc.EncodedLen(len(src))
+	dst = slices.Grow(dst, n)
+	out := dst[len(dst) : len(dst)+n]
+	done := encodeBlocks(out, src, e.url)
+	e.enc.Encode(out[done/3*4:], src[done:])
+	return dst[:len(dst)+n]
+}
+
+// DecodedLen returns the most bytes n characters decode to.
+func (e *Encoding) DecodedLen(n int) int {
+	return e.enc.DecodedLen(n)
+}
+
+// Decode decodes src into dst, returning the bytes written, as
+// encoding/base64 does, with the same errors.
+func (e *Encoding) Decode(dst, src []byte) (int, error) {
+	done := decodeBlocks(dst, src, e.url)
+	written := done / 4 * 3
+	n, err := e.enc.Decode(dst[written:], src[done:])
+	if c, ok := err.(base64.CorruptInputError); ok {
+		err = c + base64.CorruptInputError(done)
+	}
+	return written + n, err
+}
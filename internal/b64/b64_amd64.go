@@ -0,0 +1,62 @@
+//go:build amd64 && !noasm
+
+package b64
+
+import "golang.org/x/sys/cpu"
+
+//go:noescape
+func encodeAVX2(dst, src *byte, blocks uint64, lut *[16]byte)
+
+//go:noescape
+func decodeAVX2(dst, src *byte, blocks uint64, tab *[4]byte) uint64
+
+// useKernels is whether the kernels run; tests clear it to check the
+// fallback.
+var useKernels = cpu.X86.HasAVX2
+
+// Offsets from index to character, modulo 256, in the ranges encodeAVX2
+// tells apart: A-Z, a-z, ten for the digits, then indices 62 and 63.
+var (
+	encodeStd = [16]byte{'A', 'a' - 26, 0xfc, 0xfc, 0xfc, 0xfc, 0xfc, 0xfc, 0xfc, 0xfc, 0xfc, 0xfc, 0xed, 0xf0}
+	encodeURL = [16]byte{'A', 'a' - 26, 0xfc, 0xfc, 0xfc, 0xfc, 0xfc, 0xfc, 0xfc, 0xfc, 0xfc, 0xfc, 0xef, '_' - 63}
+)
+
+// The characters of indices 62 and 63, each followed by its offset to the
+// index, modulo 256.
+var (
+	decodeStd = [4]byte{'+', 62 - '+', '/', 63 - '/'}
+	decodeURL = [4]byte{'-', 62 - '-', '_', 0xe0}
+)
+
+// encodeBlocks encodes as much of src as the kernel can into dst, which
+// has room for all of it, and returns the bytes of src encoded, a
+// multiple of 3.
+func encodeBlocks(dst, src []byte, url bool) int {
+	// The kernel reads 4 bytes past its last block
+	if !useKernels || len(src) < 28 {
+		return 0
+	}
+	blocks := (len(src) - 4) / 24
+	lut := &encodeStd
+	if url {
+		lut = &encodeURL
+	}
+	encodeAVX2(&dst[0], &src[0], uint64(blocks), lut)
+	return blocks * 24
+}
+
+// decodeBlocks decodes as much of src as the kernel can into dst, and
+// returns the characters of src decoded, a multiple of 4 none of which is
+// outside the alphabet.
+func decodeBlocks(dst, src []byte, url bool) int {
+	// The kernel writes 8 bytes past its last block
+	if !useKernels || len(src) < 32 || len(dst) < 32 {
+		return 0
+	}
+	blocks := min(len(src)/32, (len(dst)-8)/24)
+	tab := &decodeStd
+	if url {
+		tab = &decodeURL
+	}
+	return int(decodeAVX2(&dst[0], &src[0], uint64(blocks), tab)) * 32
+}
@@ -0,0 +1,210 @@
+//go:build amd64 && !noasm
+
+#include "textflag.h"
+
+// Shuffle gathering the three bytes of each group into b1 b0 b2 b1, in
+// each lane, for encodeAVX2
+DATA encShuf<>+0(SB)/8, $0x0405030401020001
+DATA encShuf<>+8(SB)/8, $0x0a0b090a07080607
+GLOBL encShuf<>(SB), (NOPTR+RODATA), $16
+
+// Masks and multipliers moving the four 6-bit indices of each group into
+// bytes of their own
+DATA encMask0<>+0(SB)/4, $0x0fc0fc00
+GLOBL encMask0<>(SB), (NOPTR+RODATA), $4
+DATA encMul0<>+0(SB)/4, $0x04000040
+GLOBL encMul0<>(SB), (NOPTR+RODATA), $4
+DATA encMask1<>+0(SB)/4, $0x003f03f0
+GLOBL encMask1<>(SB), (NOPTR+RODATA), $4
+DATA encMul1<>+0(SB)/4, $0x01000010
+GLOBL encMul1<>(SB), (NOPTR+RODATA), $4
+
+// 51 and 25, which split the indices into the ranges of the alphabet
+DATA encRanges<>+0(SB)/2, $0x1933
+GLOBL encRanges<>(SB), (NOPTR+RODATA), $2
+
+// encodeAVX2 encodes blocks of 24 bytes from src into 32 characters each
+// at dst, with lut holding the offsets from index to character of the
+// alphabet's ranges. It reads 4 bytes past the last block.
+// func encodeAVX2(dst, src *byte, blocks uint64, lut *[16]byte)
+TEXT ·encodeAVX2(SB), NOSPLIT, $0-32
+    MOVQ    dst+0(FP), DI
+    MOVQ    src+8(FP), SI
+    MOVQ    blocks+16(FP), CX
+    MOVQ    lut+24(FP), AX
+
+    VBROADCASTI128 (AX), Y15
+    VBROADCASTI128 encShuf<>(SB), Y14
+    VPBROADCASTD encMask0<>(SB), Y13
+    VPBROADCASTD encMul0<>(SB), Y12
+    VPBROADCASTD encMask1<>(SB), Y11
+    VPBROADCASTD encMul1<>(SB), Y10
+    VPBROADCASTB encRanges<>+0(SB), Y9
+    VPBROADCASTB encRanges<>+1(SB), Y8
+
+encodeLoop:
+    TESTQ   CX, CX
+    JZ      encodeDone
+
+    // Bytes 0-11 in the low lane, 12-23 in the high one
+    VMOVDQU (SI), X0
+    VINSERTI128 $1, 12(SI), Y0, Y0
+    VPSHUFB Y14, Y0, Y0
+
+    VPAND   Y13, Y0, Y1
+    VPMULHUW Y12, Y1, Y1
+    VPAND   Y11, Y0, Y2
+    VPMULLW Y10, Y2, Y2
+    VPOR    Y1, Y2, Y0
+
+    // Index 0-25 selects offset 0, 26-51 offset 1, 52-61 offsets 2-11,
+    // 62 offset 12 and 63 offset 13
+    VPSUBUSB Y9, Y0, Y1
+    VPCMPGTB Y8, Y0, Y2
+    VPSUBB  Y2, Y1, Y1
+    VPSHUFB Y1, Y15, Y1
+    VPADDB  Y1, Y0, Y0
+    VMOVDQU Y0, (DI)
+
+    ADDQ    $24, SI
+    ADDQ    $32, DI
+    DECQ    CX
+    JMP     encodeLoop
+
+encodeDone:
+    VZEROUPPER
+    RET
+
+// Bounds of the letter and digit ranges, one below the first character,
+// and the offsets from character to index in each
+DATA decLoUpper<>+0(SB)/8, $0x4040404040404040
+DATA decLoUpper<>+8(SB)/8, $0x4040404040404040
+DATA decLoUpper<>+16(SB)/8, $0x4040404040404040
+DATA decLoUpper<>+24(SB)/8, $0x4040404040404040
+GLOBL decLoUpper<>(SB), (NOPTR+RODATA), $32
+DATA decLoLower<>+0(SB)/8, $0x6060606060606060
+DATA decLoLower<>+8(SB)/8, $0x6060606060606060
+DATA decLoLower<>+16(SB)/8, $0x6060606060606060
+DATA decLoLower<>+24(SB)/8, $0x6060606060606060
+GLOBL decLoLower<>(SB), (NOPTR+RODATA), $32
+DATA decLoDigit<>+0(SB)/8, $0x2f2f2f2f2f2f2f2f
+DATA decLoDigit<>+8(SB)/8, $0x2f2f2f2f2f2f2f2f
+DATA decLoDigit<>+16(SB)/8, $0x2f2f2f2f2f2f2f2f
+DATA decLoDigit<>+24(SB)/8, $0x2f2f2f2f2f2f2f2f
+GLOBL decLoDigit<>(SB), (NOPTR+RODATA), $32
+DATA decOffUpper<>+0(SB)/8, $0xbfbfbfbfbfbfbfbf
+DATA decOffUpper<>+8(SB)/8, $0xbfbfbfbfbfbfbfbf
+DATA decOffUpper<>+16(SB)/8, $0xbfbfbfbfbfbfbfbf
+DATA decOffUpper<>+24(SB)/8, $0xbfbfbfbfbfbfbfbf
+GLOBL decOffUpper<>(SB), (NOPTR+RODATA), $32
+DATA decOffLower<>+0(SB)/8, $0xb9b9b9b9b9b9b9b9
+DATA decOffLower<>+8(SB)/8, $0xb9b9b9b9b9b9b9b9
+DATA decOffLower<>+16(SB)/8, $0xb9b9b9b9b9b9b9b9
+DATA decOffLower<>+24(SB)/8, $0xb9b9b9b9b9b9b9b9
+GLOBL decOffLower<>(SB), (NOPTR+RODATA), $32
+DATA decOffDigit<>+0(SB)/8, $0x0404040404040404
+DATA decOffDigit<>+8(SB)/8, $0x0404040404040404
+DATA decOffDigit<>+16(SB)/8, $0x0404040404040404
+DATA decOffDigit<>+24(SB)/8, $0x0404040404040404
+GLOBL decOffDigit<>(SB), (NOPTR+RODATA), $32
+
+// One above the last character of each range
+DATA decHi<>+0(SB)/4, $0x003a7b5b
+GLOBL decHi<>(SB), (NOPTR+RODATA), $4
+
+// Multipliers merging four 6-bit values into 24 bits, the shuffle putting
+// those bytes in order in each lane and the permutation joining the lanes
+DATA decMerge0<>+0(SB)/4, $0x01400140
+GLOBL decMerge0<>(SB), (NOPTR+RODATA), $4
+DATA decMerge1<>+0(SB)/4, $0x00011000
+GLOBL decMerge1<>(SB), (NOPTR+RODATA), $4
+DATA decShuf<>+0(SB)/8, $0x090a040506000102
+DATA decShuf<>+8(SB)/8, $0x808080800c0d0e08
+GLOBL decShuf<>(SB), (NOPTR+RODATA), $16
+DATA decPerm<>+0(SB)/8, $0x0000000100000000
+DATA decPerm<>+8(SB)/8, $0x0000000400000002
+DATA decPerm<>+16(SB)/8, $0x0000000600000005
+DATA decPerm<>+24(SB)/8, $0x0000000700000003
+GLOBL decPerm<>(SB), (NOPTR+RODATA), $32
+
+// decodeAVX2 decodes blocks of 32 characters from src into 24 bytes each
+// at dst, stopping before the first block holding a character outside the
+// alphabet, and returns the blocks decoded. tab holds the characters of
+// indices 62 and 63, each followed by its offset to the index. It writes 8
+// bytes past the last block.
+// func decodeAVX2(dst, src *byte, blocks uint64, tab *[4]byte) uint64
+TEXT ·decodeAVX2(SB), NOSPLIT, $0-40
+    MOVQ    dst+0(FP), DI
+    MOVQ    src+8(FP), SI
+    MOVQ    blocks+16(FP), CX
+    MOVQ    tab+24(FP), AX
+    XORQ    BX, BX
+
+    VPBROADCASTB 0(AX), Y10
+    VPBROADCASTB 1(AX), Y9
+    VPBROADCASTB 2(AX), Y8
+    VPBROADCASTB 3(AX), Y7
+    VPBROADCASTB decHi<>+0(SB), Y13
+    VPBROADCASTB decHi<>+1(SB), Y12
+    VPBROADCASTB decHi<>+2(SB), Y11
+    VPBROADCASTD decMerge0<>(SB), Y6
+    VPBROADCASTD decMerge1<>(SB), Y5
+    VBROADCASTI128 decShuf<>(SB), Y14
+    VMOVDQU decPerm<>(SB), Y15
+
+decodeLoop:
+    CMPQ    BX, CX
+    JAE     decodeDone
+    VMOVDQU (SI), Y0
+
+    // Y3 collects the offsets, Y4 the characters in the alphabet. Bytes
+    // from 0x80 are negative, so in no range.
+    VPCMPGTB decLoUpper<>(SB), Y0, Y1
+    VPCMPGTB Y0, Y13, Y2
+    VPAND   Y1, Y2, Y4
+    VPAND   decOffUpper<>(SB), Y4, Y3
+
+    VPCMPGTB decLoLower<>(SB), Y0, Y1
+    VPCMPGTB Y0, Y12, Y2
+    VPAND   Y1, Y2, Y1
+    VPAND   decOffLower<>(SB), Y1, Y2
+    VPOR    Y2, Y3, Y3
+    VPOR    Y1, Y4, Y4
+
+    VPCMPGTB decLoDigit<>(SB), Y0, Y1
+    VPCMPGTB Y0, Y11, Y2
+    VPAND   Y1, Y2, Y1
+    VPAND   decOffDigit<>(SB), Y1, Y2
+    VPOR    Y2, Y3, Y3
+    VPOR    Y1, Y4, Y4
+
+    VPCMPEQB Y10, Y0, Y1
+    VPAND   Y9, Y1, Y2
+    VPOR    Y2, Y3, Y3
+    VPOR    Y1, Y4, Y4
+
+    VPCMPEQB Y8, Y0, Y1
+    VPAND   Y7, Y1, Y2
+    VPOR    Y2, Y3, Y3
+    VPOR    Y1, Y4, Y4
+
+    VPMOVMSKB Y4, DX
+    CMPL    DX, $0xffffffff
+    JNE     decodeDone
+
+    VPADDB  Y3, Y0, Y0
+    VPMADDUBSW Y6, Y0, Y0
+    VPMADDWD Y5, Y0, Y0
+    VPSHUFB Y14, Y0, Y0
+    VPERMD  Y0, Y15, Y0
+    VMOVDQU Y0, (DI)
+
+    ADDQ    $32, SI
+    ADDQ    $24, DI
+    INCQ    BX
+    JMP     decodeLoop
+
+decodeDone:
+    MOVQ    BX, ret+32(FP)
+    VZEROUPPER
+    RET
@@ -0,0 +1,16 @@
+//go:build !amd64 || noasm
+
+package b64
+
+// useKernels is false: there are no kernels.
+var useKernels = false
+
+// encodeBlocks encodes nothing without the kernels.
+func encodeBlocks(dst, src []byte, url bool) int {
+	return 0
+}
+
+// decodeBlocks decodes nothing without the kernels.
+func decodeBlocks(dst, src []byte, url bool) int {
+	return 0
+}
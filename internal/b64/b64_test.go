@@ -0,0 +1,142 @@
+package b64
+
+import (
+	"bytes"
+	"encoding/base64"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+var encodings = []struct {
+	name string
+	enc  *Encoding
+	std  *base64.Encoding
+}{
+	{"std", StdEncoding, base64.StdEncoding},
+	{"url", URLEncoding, base64.URLEncoding},
+	{"rawstd", RawStdEncoding, base64.RawStdEncoding},
+	{"rawurl", RawURLEncoding, base64.RawURLEncoding},
+}
+
+// withFallback runs f with the kernels and, where they exist, without.
+func withFallback(t *testing.T, f func(t *testing.T)) {
+	t.Run("kernel", f)
+	if useKernels {
+		useKernels = false
+		defer func() { useKernels = true }()
+		t.Run("fallback", f)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	withFallback(t, func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		for _, e := range encodings {
+			for n := 0; n < 300; n++ {
+				src := make([]byte, n)
+				rng.Read(src)
+				expected := e.std.EncodeToString(src)
+				got := e.enc.AppendEncode([]byte("x"), src)
+				if string(got) != "x"+expected {
+					t.Fatalf("%s: Expected %s for %d bytes, got %s", e.name, expected, n, got[1:])
+				}
+			}
+		}
+	})
+}
+
+func TestDecode(t *testing.T) {
+	withFallback(t, func(t *testing.T) {
+		rng := rand.New(rand.NewSource(2))
+		for _, e := range encodings {
+			for n := 0; n < 300; n++ {
+				expected := make([]byte, n)
+				rng.Read(expected)
+				src := []byte(e.std.EncodeToString(expected))
+				dst := make([]byte, e.enc.DecodedLen(len(src)))
+				m, err := e.enc.Decode(dst, src)
+				if err != nil || !bytes.Equal(dst[:m], expected) {
+					t.Fatalf("%s: Expected %x for %s, got %x, %v", e.name, expected, src, dst[:m], err)
+				}
+			}
+		}
+	})
+}
+
+func TestDecodeErrors(t *testing.T) {
+	withFallback(t, func(t *testing.T) {
+		for _, e := range encodings {
+			src := []byte(e.std.EncodeToString(bytes.Repeat([]byte("simdjson"), 30)))
+			// Every character outside the alphabet, at every position of
+			// the first blocks, fails where encoding/base64 fails
+			for _, c := range []byte("=.\x00\x80\xff@[`{ :+-/_") {
+				for i := 0; i < 100; i++ {
+					bad := bytes.Clone(src)
+					bad[i] = c
+					dst := make([]byte, e.enc.DecodedLen(len(bad)))
+					wantDst := make([]byte, e.std.DecodedLen(len(bad)))
+					n, err := e.enc.Decode(dst, bad)
+					wantN, wantErr := e.std.Decode(wantDst, bad)
+					if n != wantN || !sameError(err, wantErr) || !bytes.Equal(dst[:n], wantDst[:wantN]) {
+						t.Fatalf("%s: Expected %d, %v for %q at %d, got %d, %v", e.name, wantN, wantErr, c, i, n, err)
+					}
+				}
+			}
+		}
+	})
+}
+
+func sameError(a, b error) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Error() == b.Error()
+}
+
+func TestDecodeNewlines(t *testing.T) {
+	withFallback(t, func(t *testing.T) {
+		expected := bytes.Repeat([]byte{0xfb, 0xff, 0x01}, 40)
+		src := base64.StdEncoding.EncodeToString(expected)
+		src = src[:64] + "\r\n" + src[64:]
+		dst := make([]byte, StdEncoding.DecodedLen(len(src)))
+		n, err := StdEncoding.Decode(dst, []byte(src))
+		if err != nil || !bytes.Equal(dst[:n], expected) {
+			t.Errorf("Expected newlines skipped, got %x, %v", dst[:n], err)
+		}
+	})
+}
+
+// A destination without room past the input is written only in bounds
+func TestDecodeExactDestination(t *testing.T) {
+	withFallback(t, func(t *testing.T) {
+		expected := []byte(strings.Repeat("abc", 64))
+		src := []byte(base64.RawStdEncoding.EncodeToString(expected))
+		buf := make([]byte, len(expected)+8)
+		n, err := RawStdEncoding.Decode(buf[:len(expected)], src)
+		if err != nil || !bytes.Equal(buf[:n], expected) || !bytes.Equal(buf[n:], make([]byte, 8)) {
+			t.Errorf("Expected %d bytes in bounds, got %d, %v", len(expected), n, err)
+		}
+	})
+}
+
+func BenchmarkEncode(b *testing.B) {
+	src := make([]byte, 64<<10)
+	rand.New(rand.NewSource(3)).Read(src)
+	dst := make([]byte, 0, StdEncoding.enc.EncodedLen(len(src)))
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		StdEncoding.AppendEncode(dst, src)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	data := make([]byte, 64<<10)
+	rand.New(rand.NewSource(4)).Read(data)
+	src := []byte(base64.StdEncoding.EncodeToString(data))
+	dst := make([]byte, StdEncoding.DecodedLen(len(src)))
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		StdEncoding.Decode(dst, src)
+	}
+}
@@ -0,0 +1,78 @@
+// Package classify computes the stage-1 bitmasks of JSON text, the
+// classification of each 64-byte block that simdjson builds its parsers
+// on. Bit i of a mask describes byte i of the block.
+package classify
+
+import "encoding/binary"
+
+// Masks classifies the bytes of a 64-byte block.
+type Masks struct {
+	Quote     uint64 // '"'
+	Backslash uint64 // '\\'
+	Op        uint64 // '{', '}', '[', ']', ':' and ','
+	Space     uint64 // JSON whitespace: ' ', '\t', '\n' and '\r'
+	Ctrl      uint64 // below 0x20, including '\t', '\n' and '\r'
+	High      uint64 // 0x80 and above: the bytes of UTF-8 sequences
+}
+
+// Blocks classifies the leading 64-byte blocks of src into dst, as many as
+// both hold, and returns their number. A trailing partial block is left
+// for the caller to pad.
+func Blocks(dst []Masks, src []byte) int {
+	n := min(len(dst), len(src)/64)
+	done := blocks(dst[:n], src)
+	for i := done; i < n; i++ {
+		dst[i] = block(src[i*64:])
+	}
+	return n
+}
+
+// HasKernel reports whether Blocks runs a vector kernel on this CPU rather
+// than the portable eight-bytes-at-a-time fallback.
+func HasKernel() bool {
+	return useKernel
+}
+
+// block classifies src[:64] eight bytes at a time.
+func block(src []byte) Masks {
+	var m Masks
+	for k := 0; k < 64; k += 8 {
+		w := binary.LittleEndian.Uint64(src[k:])
+		// Setting bit 5 folds [ into { and ] into }
+		b := w | lo*0x20
+		m.Quote |= pack(eq(w, '"')) << k
+		m.Backslash |= pack(eq(w, '\\')) << k
+		m.Op |= pack(eq(b, '{')|eq(b, '}')|eq(w, ':')|eq(w, ',')) << k
+		m.Space |= pack(eq(w, ' ')|eq(w, '\t')|eq(w, '\n')|eq(w, '\r')) << k
+		m.Ctrl |= pack(less(w, 0x20)) << k
+		m.High |= pack(w&hi) << k
+	}
+	return m
+}
+
+const (
+	lo  = 0x0101010101010101
+	lo7 = 0x7f7f7f7f7f7f7f7f
+	hi  = 0x8080808080808080
+)
+
+// eq sets the high bit of each byte lane of w that equals b, exactly in
+// every lane: the low seven bits of a lane are added without carrying into
+// the next.
+func eq(w uint64, b byte) uint64 {
+	x := w ^ (lo * uint64(b))
+	return ^((x & lo7) + lo7 | x) & hi
+}
+
+// less sets the high bit of each byte lane of w below b, which must be at
+// most 0x80.
+func less(w uint64, b byte) uint64 {
+	return ^((w & lo7) + lo*uint64(0x80-b) | w) & hi
+}
+
+// pack gathers the high bits of the byte lanes of m into the low eight
+// bits, lane 0 lowest: one multiplication shifts every lane's bit into the
+// top byte.
+func pack(m uint64) uint64 {
+	return (m >> 7) * 0x0102040810204080 >> 56
+}
@@ -0,0 +1,22 @@
+//go:build amd64 && !noasm
+
+package classify
+
+import "golang.org/x/sys/cpu"
+
+//go:noescape
+func classifyAVX2(dst *Masks, src *byte, blocks uint64)
+
+// useKernel is whether the kernel runs; tests clear it to check the
+// fallback.
+var useKernel = cpu.X86.HasAVX2
+
+// blocks classifies as many blocks as the kernel can and returns their
+// number.
+func blocks(dst []Masks, src []byte) int {
+	if !useKernel || len(dst) == 0 {
+		return 0
+	}
+	classifyAVX2(&dst[0], &src[0], uint64(len(dst)))
+	return len(dst)
+}
@@ -0,0 +1,131 @@
+//go:build amd64 && !noasm
+
+#include "textflag.h"
+
+// The bytes classifyAVX2 compares against, broadcast to a register each
+DATA classBytes<>+0(SB)/1, $0x22 // '"'
+DATA classBytes<>+1(SB)/1, $0x5c // '\\'
+DATA classBytes<>+2(SB)/1, $0x20 // bit 5, folding [ and ] into { and }
+DATA classBytes<>+3(SB)/1, $0x7b // '{'
+DATA classBytes<>+4(SB)/1, $0x7d // '}'
+DATA classBytes<>+5(SB)/1, $0x3a // ':'
+DATA classBytes<>+6(SB)/1, $0x2c // ','
+DATA classBytes<>+7(SB)/1, $0x1f // the largest control byte
+GLOBL classBytes<>(SB), (NOPTR+RODATA), $8
+
+// spaceTable maps the low nibble of a byte to the JSON whitespace byte
+// with that nibble, ' ', '\t', '\n' or '\r', and to 0 otherwise: a byte
+// is whitespace if VPSHUFB looks it up as itself. Bytes from 0x80 look up
+// 0, and only ' ' has nibble 0 among the others.
+DATA spaceTable<>+0(SB)/8, $0x0000000000000020
+DATA spaceTable<>+8(SB)/8, $0x00000d00000a0900
+DATA spaceTable<>+16(SB)/8, $0x0000000000000020
+DATA spaceTable<>+24(SB)/8, $0x00000d00000a0900
+GLOBL spaceTable<>(SB), (NOPTR+RODATA), $32
+
+// MASK32 stores in R the mask of the bytes of Y2 that are all ones
+#define MASK32(R) VPMOVMSKB Y2, R
+
+// classifyAVX2 classifies blocks of 64 bytes from src into a Masks each
+// at dst, 32 bytes per comparison.
+// func classifyAVX2(dst *Masks, src *byte, blocks uint64)
+TEXT ·classifyAVX2(SB), NOSPLIT, $0-24
+    MOVQ    dst+0(FP), DI
+    MOVQ    src+8(FP), SI
+    MOVQ    blocks+16(FP), CX
+
+    VPBROADCASTB classBytes<>+0(SB), Y15
+    VPBROADCASTB classBytes<>+1(SB), Y14
+    VPBROADCASTB classBytes<>+2(SB), Y13
+    VPBROADCASTB classBytes<>+3(SB), Y12
+    VPBROADCASTB classBytes<>+4(SB), Y11
+    VPBROADCASTB classBytes<>+5(SB), Y10
+    VPBROADCASTB classBytes<>+6(SB), Y9
+    VPBROADCASTB classBytes<>+7(SB), Y8
+    VMOVDQU spaceTable<>(SB), Y7
+
+loop:
+    TESTQ   CX, CX
+    JZ      done
+
+    VMOVDQU (SI), Y0
+    VMOVDQU 32(SI), Y1
+
+    // Quote
+    VPCMPEQB Y15, Y0, Y2
+    MASK32(AX)
+    VPCMPEQB Y15, Y1, Y2
+    MASK32(BX)
+    SHLQ    $32, BX
+    ORQ     BX, AX
+    MOVQ    AX, 0(DI)
+
+    // Backslash
+    VPCMPEQB Y14, Y0, Y2
+    MASK32(AX)
+    VPCMPEQB Y14, Y1, Y2
+    MASK32(BX)
+    SHLQ    $32, BX
+    ORQ     BX, AX
+    MOVQ    AX, 8(DI)
+
+    // Op
+    VPOR    Y13, Y0, Y3
+    VPCMPEQB Y12, Y3, Y2
+    VPCMPEQB Y11, Y3, Y3
+    VPOR    Y3, Y2, Y2
+    VPCMPEQB Y10, Y0, Y3
+    VPOR    Y3, Y2, Y2
+    VPCMPEQB Y9, Y0, Y3
+    VPOR    Y3, Y2, Y2
+    MASK32(AX)
+    VPOR    Y13, Y1, Y3
+    VPCMPEQB Y12, Y3, Y2
+    VPCMPEQB Y11, Y3, Y3
+    VPOR    Y3, Y2, Y2
+    VPCMPEQB Y10, Y1, Y3
+    VPOR    Y3, Y2, Y2
+    VPCMPEQB Y9, Y1, Y3
+    VPOR    Y3, Y2, Y2
+    MASK32(BX)
+    SHLQ    $32, BX
+    ORQ     BX, AX
+    MOVQ    AX, 16(DI)
+
+    // Space
+    VPSHUFB Y0, Y7, Y2
+    VPCMPEQB Y0, Y2, Y2
+    MASK32(AX)
+    VPSHUFB Y1, Y7, Y2
+    VPCMPEQB Y1, Y2, Y2
+    MASK32(BX)
+    SHLQ    $32, BX
+    ORQ     BX, AX
+    MOVQ    AX, 24(DI)
+
+    // Ctrl: a byte is at most 0x1f if the unsigned minimum keeps it
+    VPMINUB Y8, Y0, Y2
+    VPCMPEQB Y0, Y2, Y2
+    MASK32(AX)
+    VPMINUB Y8, Y1, Y2
+    VPCMPEQB Y1, Y2, Y2
+    MASK32(BX)
+    SHLQ    $32, BX
+    ORQ     BX, AX
+    MOVQ    AX, 32(DI)
+
+    // High: the sign bits of the bytes themselves
+    VPMOVMSKB Y0, AX
+    VPMOVMSKB Y1, BX
+    SHLQ    $32, BX
+    ORQ     BX, AX
+    MOVQ    AX, 40(DI)
+
+    ADDQ    $64, SI
+    ADDQ    $48, DI
+    DECQ    CX
+    JMP     loop
+
+done:
+    VZEROUPPER
+    RET
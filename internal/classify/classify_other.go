@@ -0,0 +1,11 @@
+//go:build !amd64 || noasm
+
+package classify
+
+// useKernel is false: there is no kernel.
+var useKernel = false
+
+// blocks classifies nothing without the kernel.
+func blocks(dst []Masks, src []byte) int {
+	return 0
+}
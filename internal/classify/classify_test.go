@@ -0,0 +1,102 @@
+package classify
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// withFallback runs f with the kernel and, where it exists, without.
+func withFallback(t *testing.T, f func(t *testing.T)) {
+	t.Run("kernel", f)
+	if useKernel {
+		useKernel = false
+		defer func() { useKernel = true }()
+		t.Run("fallback", f)
+	}
+}
+
+// reference classifies a block a byte at a time.
+func reference(src []byte) Masks {
+	var m Masks
+	for i, c := range src[:64] {
+		bit := uint64(1) << i
+		switch {
+		case c == '"':
+			m.Quote |= bit
+		case c == '\\':
+			m.Backslash |= bit
+		case c == '{' || c == '}' || c == '[' || c == ']' || c == ':' || c == ',':
+			m.Op |= bit
+		case c == ' ':
+			m.Space |= bit
+		case c == '\t' || c == '\n' || c == '\r':
+			m.Space |= bit
+			m.Ctrl |= bit
+		case c < 0x20:
+			m.Ctrl |= bit
+		case c >= 0x80:
+			m.High |= bit
+		}
+	}
+	return m
+}
+
+func TestBlocks(t *testing.T) {
+	withFallback(t, func(t *testing.T) {
+		// Every byte value in every position, and random JSON-like text
+		rng := rand.New(rand.NewSource(1))
+		var src []byte
+		for c := 0; c < 256; c++ {
+			for i := 0; i < 64; i++ {
+				src = append(src, byte(c+i))
+			}
+		}
+		alphabet := []byte("\"\\{}[]:, \t\n\rabz09{}-\x00\x1f\x7f\x80\xff[]")
+		for i := 0; i < 64*64; i++ {
+			src = append(src, alphabet[rng.Intn(len(alphabet))])
+		}
+		src = append(src, "partial"...)
+
+		dst := make([]Masks, len(src)/64+3)
+		n := Blocks(dst, src)
+		if n != len(src)/64 {
+			t.Fatalf("Expected %d blocks, got %d", len(src)/64, n)
+		}
+		for b := 0; b < n; b++ {
+			if want := reference(src[b*64:]); dst[b] != want {
+				t.Fatalf("Block %d: expected %+v, got %+v", b, want, dst[b])
+			}
+		}
+		if dst[n] != (Masks{}) {
+			t.Errorf("Expected the partial block left alone, got %+v", dst[n])
+		}
+
+		// dst bounds the blocks classified
+		if n := Blocks(dst[:2], src); n != 2 {
+			t.Errorf("Expected 2 blocks, got %d", n)
+		}
+	})
+}
+
+func BenchmarkBlocks(b *testing.B) {
+	src := make([]byte, 64*1024)
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []byte(`{"key": [1, 2.5, true], "name": "value"}`)
+	for i := range src {
+		src[i] = alphabet[rng.Intn(len(alphabet))]
+	}
+	dst := make([]Masks, len(src)/64)
+
+	run := func(b *testing.B) {
+		b.SetBytes(int64(len(src)))
+		for i := 0; i < b.N; i++ {
+			Blocks(dst, src)
+		}
+	}
+	b.Run("kernel", run)
+	if useKernel {
+		useKernel = false
+		defer func() { useKernel = true }()
+		b.Run("fallback", run)
+	}
+}
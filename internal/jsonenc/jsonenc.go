@@ -0,0 +1,128 @@
+// Package jsonenc holds the encoding primitives shared by the simdjson
+// encoder and the jsontext package: escaping strings and formatting floats
+// exactly as encoding/json does.
+package jsonenc
+
+import (
+	"math"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// AppendFloat appends f as encoding/json formats a float of bitSize bits:
+// the shortest digits that read back as the same value, in plain notation
+// unless its magnitude is below 1e-6 or from 1e21, and then with a
+// two-digit exponent at least (1e-07 is written 1e-7, as in ES6).
+func AppendFloat(dst []byte, f float64, bitSize int) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bitSize == 64 && (abs < 1e-6 || abs >= 1e21) ||
+			bitSize == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, bitSize)
+	if format == 'e' {
+		// Shorten e-09 to e-9
+		if n := len(dst); n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst
+}
+
+// AppendString appends s with the escapes a JSON string needs. As
+// in encoding/json, U+2028 and U+2029 are escaped so the output is valid
+// JavaScript, invalid UTF-8 is replaced with U+FFFD, and with html '<',
+// '>' and '&' become \u003c, \u003e and \u0026 so the output can be
+// embedded in an HTML <script> element. Runs of bytes that need none of
+// this are found a word at a time and copied whole.
+func AppendString(dst []byte, s string, html bool) []byte {
+	const hex = "0123456789abcdef"
+	for {
+		i := scanner.IndexEscape(s, html)
+		dst = append(dst, s[:i]...)
+		if i == len(s) {
+			return dst
+		}
+
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			switch {
+			case r == utf8.RuneError && size == 1:
+				dst = append(dst, "\uFFFD"...)
+			case r == '\u2028' || r == '\u2029':
+				dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xF])
+			default:
+				dst = append(dst, s[i:i+size]...)
+			}
+			s = s[i+size:]
+			continue
+		}
+
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\b':
+			dst = append(dst, '\\', 'b')
+		case '\f':
+			dst = append(dst, '\\', 'f')
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			// Other control characters, and <, > and &
+			dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+		}
+		s = s[i+1:]
+	}
+}
+
+// ValidNumber reports whether b is a number in the JSON grammar.
+func ValidNumber(b []byte) bool {
+	i := 0
+	if i < len(b) && b[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(b) && b[i] == '0':
+		i++
+	case i < len(b) && b[i] >= '1' && b[i] <= '9':
+		for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+			i++
+		}
+	default:
+		return false
+	}
+	if i < len(b) && b[i] == '.' {
+		i++
+		start := i
+		for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return false
+		}
+	}
+	if i < len(b) && (b[i] == 'e' || b[i] == 'E') {
+		i++
+		if i < len(b) && (b[i] == '+' || b[i] == '-') {
+			i++
+		}
+		start := i
+		for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return false
+		}
+	}
+	return i == len(b)
+}
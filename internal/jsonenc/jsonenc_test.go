@@ -0,0 +1,64 @@
+package jsonenc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAppendFloat(t *testing.T) {
+	tests := []struct {
+		f        float64
+		bitSize  int
+		expected string
+	}{
+		{0, 64, "0"},
+		{1.5, 64, "1.5"},
+		{1e20, 64, "100000000000000000000"},
+		{1e21, 64, "1e+21"},
+		{1e-7, 64, "1e-7"},
+		{-1e-7, 64, "-1e-7"},
+		{0.000001, 64, "0.000001"},
+		{float64(float32(0.1)), 32, "0.1"},
+		{math.MaxFloat64, 64, "1.7976931348623157e+308"},
+	}
+	for _, tt := range tests {
+		if got := string(AppendFloat(nil, tt.f, tt.bitSize)); got != tt.expected {
+			t.Errorf("AppendFloat(%v, %d): expected %s, got %s", tt.f, tt.bitSize, tt.expected, got)
+		}
+	}
+}
+
+func TestAppendString(t *testing.T) {
+	tests := []struct {
+		s        string
+		html     bool
+		expected string
+	}{
+		{"plain", false, "plain"},
+		{"q\"b\\", false, `q\"b\\`},
+		{"\b\f\n\r\t\x01", false, `\b\f\n\r\t\u0001`},
+		{"<a&b>", false, "<a&b>"},
+		{"<a&b>", true, `\u003ca\u0026b\u003e`},
+		{"\u2028\u2029", false, `\u2028\u2029`},
+		{"bad\xffutf8", false, "bad\ufffdutf8"},
+		{"é世", false, "é世"},
+	}
+	for _, tt := range tests {
+		if got := string(AppendString(nil, tt.s, tt.html)); got != tt.expected {
+			t.Errorf("AppendString(%q, %v): expected %s, got %s", tt.s, tt.html, tt.expected, got)
+		}
+	}
+}
+
+func TestValidNumber(t *testing.T) {
+	for _, s := range []string{"0", "-0", "1", "12.5", "-0.5e-3", "1E+9"} {
+		if !ValidNumber([]byte(s)) {
+			t.Errorf("Expected %s to be a number", s)
+		}
+	}
+	for _, s := range []string{"", "-", "01", "1.", ".5", "1e", "1e+", "+1", "1 ", "NaN", "Infinity"} {
+		if ValidNumber([]byte(s)) {
+			t.Errorf("Expected %q not to be a number", s)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"errors"
 	"strconv"
+	"unicode/utf16"
 	"unicode/utf8"
 	"unsafe"
 	
@@ -10,6 +11,10 @@ import (
 )
 
 type Parser struct {
+	// ZeroCopy makes strings without escapes alias the parsed data
+	// instead of copying them. The data then must outlive the result.
+	ZeroCopy bool
+
 	scanner     *scanner.Scanner
 	tokens      []scanner.Token
 	pos         int
@@ -187,27 +192,29 @@ func (p *Parser) parseString() (interface{}, error) {
 	
 	str := p.data[token.Start+1 : token.End-1]
 	
-	// Fast path: no escapes - use zero-copy string conversion
-	if !containsEscape(str) {
-		return unsafeString(str), nil
+	// Fast path: no escapes
+	if !token.HasEscape() {
+		if p.ZeroCopy {
+			return unsafeString(str), nil
+		}
+		return string(str), nil
 	}
 	
 	// Slow path: handle escapes
 	return p.unescapeString(str)
 }
 
-func containsEscape(b []byte) bool {
-	for _, c := range b {
-		if c == '\\' {
-			return true
-		}
+func (p *Parser) unescapeString(b []byte) (string, error) {
+	buf, err := AppendUnescaped(make([]byte, 0, len(b)), b)
+	if err != nil {
+		return "", err
 	}
-	return false
+	return string(buf), nil
 }
 
-func (p *Parser) unescapeString(b []byte) (string, error) {
-	buf := make([]byte, 0, len(b))
-	
+// AppendUnescaped appends the string content b, with its JSON escape
+// sequences decoded, to buf.
+func AppendUnescaped(buf, b []byte) ([]byte, error) {
 	for i := 0; i < len(b); i++ {
 		if b[i] != '\\' {
 			buf = append(buf, b[i])
@@ -215,7 +222,7 @@ func (p *Parser) unescapeString(b []byte) (string, error) {
 		}
 		
 		if i+1 >= len(b) {
-			return "", errors.New("invalid escape sequence")
+			return buf, errors.New("invalid escape sequence")
 		}
 		
 		i++
@@ -233,21 +240,50 @@ func (p *Parser) unescapeString(b []byte) (string, error) {
 		case 't':
 			buf = append(buf, '\t')
 		case 'u':
-			if i+4 >= len(b) {
-				return "", errors.New("invalid unicode escape")
-			}
-			r, err := strconv.ParseUint(string(b[i+1:i+5]), 16, 16)
-			if err != nil {
-				return "", err
+			r, ok := hex4(b, i+1)
+			if !ok {
+				return buf, errors.New("invalid unicode escape")
 			}
-			buf = append(buf, string(rune(r))...)
 			i += 4
+			// An escaped surrogate pair is one code point. Lone surrogates
+			// decode to U+FFFD, as in encoding/json
+			if utf16.IsSurrogate(r) && i+6 < len(b) && b[i+1] == '\\' && b[i+2] == 'u' {
+				if r2, ok := hex4(b, i+3); ok {
+					if pair := utf16.DecodeRune(r, r2); pair != utf8.RuneError {
+						r = pair
+						i += 6
+					}
+				}
+			}
+			buf = utf8.AppendRune(buf, r)
 		default:
-			return "", errors.New("invalid escape character")
+			return buf, errors.New("invalid escape character")
 		}
 	}
 	
-	return string(buf), nil
+	return buf, nil
+}
+
+// hex4 parses the four hex digits at b[i:].
+func hex4(b []byte, i int) (rune, bool) {
+	if i+4 > len(b) {
+		return 0, false
+	}
+	var r rune
+	for _, c := range b[i : i+4] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c -= 'a' - 10
+		case c >= 'A' && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
 }
 
 func (p *Parser) parseNumber() (interface{}, error) {
@@ -256,8 +292,9 @@ func (p *Parser) parseNumber() (interface{}, error) {
 	
 	numBytes := p.data[token.Start:token.End]
 	
-	// Try SIMD integer parsing first if no float indicators
-	if !containsFloatCharsBytes(numBytes) {
+	// Try SIMD integer parsing first if no float indicators. -0 is
+	// parsed as a float so it keeps its sign
+	if !token.IsFloat() && string(numBytes) != "-0" {
 		if val, ok := p.scanner.SIMDParseInteger(numBytes); ok {
 			return val, nil
 		}
@@ -277,24 +314,6 @@ func (p *Parser) parseNumber() (interface{}, error) {
 	return val, nil
 }
 
-func containsFloatChars(s string) bool {
-	for _, c := range s {
-		if c == '.' || c == 'e' || c == 'E' {
-			return true
-		}
-	}
-	return false
-}
-
-func containsFloatCharsBytes(b []byte) bool {
-	for _, c := range b {
-		if c == '.' || c == 'e' || c == 'E' {
-			return true
-		}
-	}
-	return false
-}
-
 // SIMD-optimized number parsing
 func parseNumberSIMD(b []byte) (float64, error) {
 	// This would use SIMD instructions to parse numbers faster
@@ -326,6 +326,9 @@ func TestParser_UnicodeStrings(t *testing.T) {
 		{"emoji", `"hello 😀"`, "hello 😀"},
 		{"escaped unicode", `"hello \u4e16\u754c"`, "hello 世界"},
 		{"mixed", `"ASCII and 中文 and \u0065moji 🎉"`, "ASCII and 中文 and emoji 🎉"},
+		{"surrogate pair", `"\ud83d\ude00!"`, "\U0001F600!"},
+		{"lone surrogate", `"\ud800\u0041"`, "\uFFFDA"},
+		{"reversed surrogates", `"\udc00\ud800"`, "\uFFFD\uFFFD"},
 	}
 
 	for _, tt := range tests {
@@ -483,4 +486,25 @@ func BenchmarkParser_Strings(b *testing.B) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestParser_ZeroCopy(t *testing.T) {
+	for _, zeroCopy := range []bool{false, true} {
+		data := []byte(`"hello"`)
+		p := New()
+		p.ZeroCopy = zeroCopy
+		result, err := p.Parse(data)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+
+		copy(data, `"HELLO"`)
+		expected := "hello"
+		if zeroCopy {
+			expected = "HELLO"
+		}
+		if result != expected {
+			t.Errorf("ZeroCopy=%v: Expected %q, got %q", zeroCopy, expected, result)
+		}
+	}
+}
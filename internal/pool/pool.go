@@ -0,0 +1,172 @@
+// Package pool holds the object pools simdjson reuses scanners, token
+// slices, encoders, decoders and arenas from, with the limits that
+// simdjson.SetPoolConfig sets and the counters simdjson.PoolStats reports.
+package pool
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Config bounds every pool; see simdjson.PoolConfig.
+type Config struct {
+	Disabled      bool
+	MaxBufferSize int
+	MaxObjects    int
+}
+
+// Stats are the counters of one pool. Hits, Misses and Drops count since
+// the program started; Retained and RetainedBytes since the pool was last
+// configured.
+type Stats struct {
+	Hits          uint64
+	Misses        uint64
+	Drops         uint64
+	Retained      int64
+	RetainedBytes int64
+}
+
+// Pool keeps idle Ts for reuse. With no MaxObjects they are kept in a
+// sync.Pool, and otherwise in a free list of at most that many.
+type Pool[T any] struct {
+	new  func() T
+	size func(T) int // bytes of buffers held by a T
+
+	state atomic.Pointer[state[T]]
+
+	hits, misses, drops atomic.Uint64
+}
+
+// state is a pool's storage under one Config. Configure replaces it, which
+// drops every object it holds.
+type state[T any] struct {
+	config Config
+	sp     sync.Pool
+
+	mu   sync.Mutex
+	free []T
+
+	retained, retainedBytes atomic.Int64
+}
+
+type configurable interface {
+	configure(Config)
+	stats() Stats
+}
+
+var (
+	mu     sync.Mutex
+	config Config
+	pools  = map[string]configurable{}
+)
+
+// New returns a pool, registered under name, of objects made by newFn.
+// size returns the bytes of buffers an object holds, as checked against
+// MaxBufferSize and counted in RetainedBytes.
+func New[T any](name string, newFn func() T, size func(T) int) *Pool[T] {
+	p := &Pool[T]{new: newFn, size: size}
+	mu.Lock()
+	defer mu.Unlock()
+	p.configure(config)
+	pools[name] = p
+	return p
+}
+
+// Configure replaces the Config of every pool, emptying them.
+func Configure(c Config) {
+	mu.Lock()
+	defer mu.Unlock()
+	config = c
+	for _, p := range pools {
+		p.configure(c)
+	}
+}
+
+// Read returns the counters of the pool registered under name, or zero
+// Stats if there is none.
+func Read(name string) Stats {
+	mu.Lock()
+	p := pools[name]
+	mu.Unlock()
+	if p == nil {
+		return Stats{}
+	}
+	return p.stats()
+}
+
+func (p *Pool[T]) configure(c Config) {
+	p.state.Store(&state[T]{config: c})
+}
+
+func (p *Pool[T]) stats() Stats {
+	st := p.state.Load()
+	return Stats{
+		Hits:          p.hits.Load(),
+		Misses:        p.misses.Load(),
+		Drops:         p.drops.Load(),
+		Retained:      st.retained.Load(),
+		RetainedBytes: st.retainedBytes.Load(),
+	}
+}
+
+// Get returns an idle object, or a new one if there is none.
+func (p *Pool[T]) Get() T {
+	st := p.state.Load()
+	if st.config.Disabled {
+		p.misses.Add(1)
+		return p.new()
+	}
+	x, ok := st.get()
+	if !ok {
+		p.misses.Add(1)
+		return p.new()
+	}
+	p.hits.Add(1)
+	st.retained.Add(-1)
+	st.retainedBytes.Add(-int64(p.size(x)))
+	return x
+}
+
+// Put returns x to the pool, unless pooling is disabled, x holds more than
+// MaxBufferSize bytes or MaxObjects are already idle.
+func (p *Pool[T]) Put(x T) {
+	st := p.state.Load()
+	n := p.size(x)
+	if st.config.Disabled || st.config.MaxBufferSize > 0 && n > st.config.MaxBufferSize || !st.put(x) {
+		p.drops.Add(1)
+		return
+	}
+	st.retained.Add(1)
+	st.retainedBytes.Add(int64(n))
+}
+
+func (st *state[T]) get() (T, bool) {
+	if st.config.MaxObjects <= 0 {
+		x, ok := st.sp.Get().(T)
+		return x, ok
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	var zero T
+	if len(st.free) == 0 {
+		return zero, false
+	}
+	x := st.free[len(st.free)-1]
+	st.free[len(st.free)-1] = zero
+	st.free = st.free[:len(st.free)-1]
+	return x, true
+}
+
+func (st *state[T]) put(x T) bool {
+	if st.config.MaxObjects <= 0 {
+		st.sp.Put(x)
+		return true
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if len(st.free) >= st.config.MaxObjects {
+		return false
+	}
+	st.free = append(st.free, x)
+	return true
+}
@@ -0,0 +1,51 @@
+package pool
+
+import "testing"
+
+func TestPool(t *testing.T) {
+	made := 0
+	p := New("test", func() []byte {
+		made++
+		return make([]byte, 0, 8)
+	}, func(b []byte) int {
+		return cap(b)
+	})
+	defer Configure(Config{})
+
+	Configure(Config{MaxObjects: 2})
+	a, b, c := p.Get(), p.Get(), p.Get()
+	if made != 3 {
+		t.Fatalf("Expected 3 objects made, got %d", made)
+	}
+	p.Put(a)
+	p.Put(b)
+	p.Put(c)
+	st := Read("test")
+	if st.Misses != 3 || st.Drops != 1 || st.Retained != 2 || st.RetainedBytes != 16 {
+		t.Errorf("Expected 3 misses, 1 drop and 2 objects of 16 bytes retained, got %+v", st)
+	}
+	p.Get()
+	if st = Read("test"); st.Hits != 1 || st.Retained != 1 || st.RetainedBytes != 8 || made != 3 {
+		t.Errorf("Expected a hit leaving 8 bytes retained, got %+v", st)
+	}
+
+	Configure(Config{MaxObjects: 2, MaxBufferSize: 8})
+	if st = Read("test"); st.Retained != 0 {
+		t.Errorf("Expected Configure to empty the pool, got %+v", st)
+	}
+	p.Put(make([]byte, 0, 16))
+	p.Put(make([]byte, 0, 8))
+	if st = Read("test"); st.Drops != 2 || st.Retained != 1 {
+		t.Errorf("Expected the larger buffer dropped, got %+v", st)
+	}
+
+	Configure(Config{Disabled: true})
+	p.Put(p.Get())
+	if st = Read("test"); st.Misses != 4 || st.Drops != 3 || st.Retained != 0 {
+		t.Errorf("Expected a miss and a drop with pooling disabled, got %+v", st)
+	}
+
+	if st := Read("missing"); st != (Stats{}) {
+		t.Errorf("Expected zero Stats for an unknown pool, got %+v", st)
+	}
+}
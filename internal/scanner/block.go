@@ -0,0 +1,87 @@
+package scanner
+
+// BlockState is what classifying JSON text 64 bytes at a time carries from
+// one block to the next: whether the previous block ended inside a string,
+// and whether it ended with a backslash that escapes the next block's
+// first byte. Escapes and strings may straddle any block boundary. The
+// zero BlockState is at the start of a document.
+type BlockState struct {
+	inString uint64 // all ones if the previous block ended inside a string
+	escaped  uint64 // 1 if the first byte of the next block is escaped
+}
+
+// oddBits has the bits of the odd byte positions of a block set.
+const oddBits = 0xAAAAAAAAAAAAAAAA
+
+// Next classifies block, which must be 64 bytes long unless it ends the
+// input, and advances st past it. Bit i of quotes is set when block[i] is
+// a quote that opens or closes a string, one not escaped by an odd run of
+// backslashes; bit i of inString is set when block[i] is inside a string,
+// counting the opening quote but not the closing one. As in simdjson,
+// backslashes escape whether or not they are in a string, which only
+// matters for invalid JSON.
+func (st *BlockState) Next(block []byte) (quotes, inString uint64) {
+	var backslash, quote uint64
+	for i, c := range block[:min(len(block), 64)] {
+		switch c {
+		case '\\':
+			backslash |= 1 << uint(i)
+		case '"':
+			quote |= 1 << uint(i)
+		}
+	}
+
+	quotes, inString, _ = st.next(quote, backslash)
+	return quotes, inString
+}
+
+// next advances st past a block given the masks of its quote and
+// backslash bytes. Besides the results of Next it returns the mask of the
+// bytes escaped by a backslash.
+func (st *BlockState) next(quote, backslash uint64) (quotes, inString, escaped uint64) {
+	escaped = st.escapedBytes(backslash)
+	quotes = quote &^ escaped
+	inString = prefixXOR(quotes) ^ st.inString
+	st.inString = uint64(int64(inString) >> 63)
+	return quotes, inString, escaped
+}
+
+// InString reports whether the last block classified ended inside a
+// string.
+func (st *BlockState) InString() bool {
+	return st.inString != 0
+}
+
+// escapedBytes returns the mask of the bytes of a block escaped by a
+// backslash, given the block's backslashes, with simdjson's branchless
+// method: in each run of backslashes, subtracting the run from its
+// successor bits marks the escapes at odd or even distance from the start
+// of the run, and the parity of the run's start picks which.
+func (st *BlockState) escapedBytes(backslash uint64) uint64 {
+	if backslash == 0 {
+		escaped := st.escaped
+		st.escaped = 0
+		return escaped
+	}
+	// A backslash escaped by the previous block escapes nothing itself
+	potential := backslash &^ st.escaped
+	codes := ((potential << 1) | oddBits) - potential
+	codes ^= oddBits
+	escaped := codes ^ (backslash | st.escaped)
+	st.escaped = (codes & backslash) >> 63
+	return escaped
+}
+
+// prefixXOR returns x with each bit replaced by the XOR of it and all the
+// bits below it, turning a mask of quotes into a mask of the bytes from
+// each opening quote up to its closing one. simdjson computes it with a
+// carry-less multiplication by all ones.
+func prefixXOR(x uint64) uint64 {
+	x ^= x << 1
+	x ^= x << 2
+	x ^= x << 4
+	x ^= x << 8
+	x ^= x << 16
+	x ^= x << 32
+	return x
+}
@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// referenceQuotes classifies data a byte at a time, as BlockState.Next
+// should a block at a time.
+func referenceQuotes(data []byte) (quotes, inString []bool) {
+	quotes = make([]bool, len(data))
+	inString = make([]bool, len(data))
+	escaped, in := false, false
+	for i, c := range data {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			quotes[i] = true
+			in = !in
+			inString[i] = in
+			continue
+		}
+		inString[i] = in
+	}
+	return quotes, inString
+}
+
+// checkBlocks classifies data with BlockState and compares every bit with
+// referenceQuotes.
+func checkBlocks(t *testing.T, data []byte) {
+	t.Helper()
+	quotes, inString := referenceQuotes(data)
+	var st BlockState
+	for base := 0; base < len(data); base += 64 {
+		q, in := st.Next(data[base:min(base+64, len(data))])
+		for i := base; i < min(base+64, len(data)); i++ {
+			bit := uint64(1) << uint(i-base)
+			if (q&bit != 0) != quotes[i] || (in&bit != 0) != inString[i] {
+				t.Fatalf("Input %q byte %d: expected quote %v in string %v, got %v %v",
+					data, i, quotes[i], inString[i], q&bit != 0, in&bit != 0)
+			}
+		}
+		if end := min(base+64, len(data)) - 1; st.InString() != inString[end] {
+			t.Fatalf("Input %q block at %d: expected InString %v", data, base, inString[end])
+		}
+	}
+}
+
+func TestBlockStateBoundaries(t *testing.T) {
+	// Every run of up to five backslashes, and the quote or letter it
+	// escapes, at every offset across the first two block boundaries
+	for offset := 0; offset < 140; offset++ {
+		for run := 0; run <= 5; run++ {
+			for _, after := range []string{`"`, `a`, `\`} {
+				data := `"` + strings.Repeat("a", offset) + strings.Repeat(`\`, run) + after + `b" "c"`
+				checkBlocks(t, []byte(data+strings.Repeat(" ", 200-len(data)%64)))
+			}
+		}
+	}
+}
+
+func TestBlockStateRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []byte(`"\a`)
+	for n := 0; n < 2000; n++ {
+		data := make([]byte, rng.Intn(300))
+		for i := range data {
+			data[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		checkBlocks(t, data)
+	}
+}
+
+func TestQuoteMaskMatchesBlocks(t *testing.T) {
+	data := []byte(`{"a":"x\\\"` + strings.Repeat(`\\`, 40) + `","b":"` + strings.Repeat("y", 70) + `\""}`)
+	quotes, _ := referenceQuotes(data)
+	masks := QuoteMask(data, nil)
+	if len(masks) != (len(data)+63)/64 {
+		t.Fatalf("Expected %d masks, got %d", (len(data)+63)/64, len(masks))
+	}
+	for i := range data {
+		if got := masks[i/64]&(1<<uint(i%64)) != 0; got != quotes[i] {
+			t.Errorf("Byte %d: expected quote %v, got %v", i, quotes[i], got)
+		}
+	}
+}
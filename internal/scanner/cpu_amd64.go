@@ -9,7 +9,3 @@ import (
 func hasAVX2() bool {
 	return cpu.X86.HasAVX2
 }
-
-func hasSSE42() bool {
-	return cpu.X86.HasSSE42
-}
\ No newline at end of file
@@ -0,0 +1,86 @@
+package scanner
+
+import (
+	"errors"
+)
+
+// feedState is the scanner state carried between Feed calls.
+type feedState struct {
+	active   bool  // a document is being fed
+	offset   int64 // stream offset of the start of the current chunk
+	inString bool  // the previous chunk ended inside a string
+	escaped  bool  // the previous chunk ended with an unconsumed backslash
+	boundary bool  // the previous byte was structural or whitespace
+}
+
+// Feed scans the next chunk of a document whose bytes arrive
+// incrementally, for example from a socket. String and escape state is
+// carried across chunk boundaries, so a chunk may end anywhere, including
+// between a backslash and the character it escapes.
+//
+// After Feed returns, GetStructuralIndices holds the structural indices of
+// this chunk only, as offsets into chunk; Offset reports where the chunk
+// starts in the stream. Call Finish after the last chunk.
+func (s *Scanner) Feed(chunk []byte) error {
+	if !s.feed.active {
+		s.feed = feedState{active: true, boundary: true}
+	} else {
+		s.feed.offset += int64(len(s.buf))
+	}
+
+	s.buf = chunk
+	s.structuralIndices = s.structuralIndices[:0]
+	s.links = s.links[:0]
+
+	st := &s.feed
+	for i, c := range chunk {
+		if st.inString {
+			switch {
+			case st.escaped:
+				st.escaped = false
+			case c == '\\':
+				st.escaped = true
+			case c == '"':
+				st.inString = false
+				st.boundary = true
+				s.structuralIndices = append(s.structuralIndices, uint32(i))
+			}
+			continue
+		}
+
+		class := s.charClassifier[c]
+		switch {
+		case c == '"':
+			st.inString = true
+			s.structuralIndices = append(s.structuralIndices, uint32(i))
+		case class == StructuralWhitespace:
+			st.boundary = true
+		case class != 0:
+			s.structuralIndices = append(s.structuralIndices, uint32(i))
+			st.boundary = true
+		default:
+			// Pseudo-structural: first byte of a number or literal
+			if st.boundary {
+				s.structuralIndices = append(s.structuralIndices, uint32(i))
+			}
+			st.boundary = false
+		}
+	}
+	return nil
+}
+
+// Offset returns the stream offset of the chunk passed to the last Feed.
+func (s *Scanner) Offset() int64 {
+	return s.feed.offset
+}
+
+// Finish ends the document started by the first Feed call, reporting an
+// error if it ended inside a string. The next Feed starts a new document.
+func (s *Scanner) Finish() error {
+	st := s.feed
+	s.feed = feedState{}
+	if st.inString {
+		return errors.New("unterminated string")
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func feedAll(t *testing.T, s *Scanner, chunks ...[]byte) []int64 {
+	t.Helper()
+	var indices []int64
+	for _, chunk := range chunks {
+		if err := s.Feed(chunk); err != nil {
+			t.Fatalf("Feed failed: %v", err)
+		}
+		for _, idx := range s.GetStructuralIndices() {
+			indices = append(indices, s.Offset()+int64(idx))
+		}
+	}
+	if err := s.Finish(); err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+	return indices
+}
+
+func TestScanner_FeedChunkBoundaries(t *testing.T) {
+	docs := []string{
+		`{"key":"value","n":-12.5e3,"ok":true,"nil":null}`,
+		`["a\"b","c\\","d\\\"e",[1,2,{"x":false}]]`,
+		`{"esc":"\\\\\"","u":"é"}`,
+		" [ 1 , \"two\" ,\ttrue ] ",
+	}
+
+	for _, doc := range docs {
+		data := []byte(doc)
+
+		s := New()
+		whole := feedAll(t, s, data)
+
+		for split := 0; split <= len(data); split++ {
+			got := feedAll(t, s, data[:split], data[split:])
+			if !reflect.DeepEqual(got, whole) {
+				t.Fatalf("Split at %d of %q: got %v, expected %v", split, doc, got, whole)
+			}
+		}
+
+		// One byte at a time
+		var chunks [][]byte
+		for i := range data {
+			chunks = append(chunks, data[i:i+1])
+		}
+		if got := feedAll(t, s, chunks...); !reflect.DeepEqual(got, whole) {
+			t.Fatalf("Byte-wise feed of %q: got %v, expected %v", doc, got, whole)
+		}
+		s.Release()
+	}
+}
+
+func TestScanner_FeedIndices(t *testing.T) {
+	s := New()
+	defer s.Release()
+
+	got := feedAll(t, s, []byte(`{"a":[1,tr`), []byte(`ue]}`))
+	expected := []int64{0, 1, 3, 4, 5, 6, 7, 8, 12, 13}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestScanner_FinishUnterminated(t *testing.T) {
+	s := New()
+	defer s.Release()
+
+	s.Feed([]byte(`{"key":"val`))
+	if err := s.Finish(); err == nil {
+		t.Error("Expected error for unterminated string")
+	}
+
+	// Finish resets the state for the next document
+	s.Feed([]byte(`"ok"`))
+	if err := s.Finish(); err != nil {
+		t.Errorf("Unexpected error after reset: %v", err)
+	}
+	if s.Offset() != 0 {
+		t.Errorf("Expected offset 0 after reset, got %d", s.Offset())
+	}
+}
@@ -0,0 +1,69 @@
+package scanner
+
+import (
+	"os"
+	"strconv"
+	"sync/atomic"
+)
+
+// Level identifies a family of scanning kernels.
+type Level int32
+
+const (
+	LevelAuto   Level = iota // best level supported by the CPU
+	LevelScalar              // portable byte-at-a-time loops
+	LevelSWAR                // 64-bit SWAR kernels (wasm, ppc64le, s390x)
+	LevelSSE42               // amd64 SSE4.2; there are no kernels, so never supported
+	LevelAVX2                // amd64 AVX2 assembly
+	LevelNEON                // arm64 NEON assembly
+)
+
+var levelNames = [...]string{
+	LevelAuto:   "auto",
+	LevelScalar: "scalar",
+	LevelSWAR:   "swar",
+	LevelSSE42:  "sse4.2",
+	LevelAVX2:   "avx2",
+	LevelNEON:   "neon",
+}
+
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return "Level(" + strconv.Itoa(int(l)) + ")"
+	}
+	return levelNames[l]
+}
+
+// activeLevel holds the Level used by Scan; it is never LevelAuto.
+var activeLevel atomic.Int32
+
+func init() {
+	activeLevel.Store(int32(bestLevel()))
+
+	// SIMDJSON_DISABLE_SIMD=1 forces the scalar kernels for debugging or to
+	// work around CPU errata; any value other than a false boolean counts.
+	if v := os.Getenv("SIMDJSON_DISABLE_SIMD"); v != "" {
+		if disable, err := strconv.ParseBool(v); err != nil || disable {
+			activeLevel.Store(int32(LevelScalar))
+		}
+	}
+}
+
+// SetLevel selects the kernels used by all scanners. LevelAuto restores
+// the best level for the CPU. It reports false, leaving the level
+// unchanged, if l is not supported on this CPU.
+func SetLevel(l Level) bool {
+	if l == LevelAuto {
+		l = bestLevel()
+	}
+	if l != LevelScalar && !supportsLevel(l) {
+		return false
+	}
+	activeLevel.Store(int32(l))
+	return true
+}
+
+// ActiveLevel returns the Level currently used by Scan.
+func ActiveLevel() Level {
+	return Level(activeLevel.Load())
+}
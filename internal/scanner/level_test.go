@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLevel(t *testing.T) {
+	defer SetLevel(LevelAuto)
+
+	if !SetLevel(LevelAuto) {
+		t.Fatal("SetLevel(LevelAuto) should always succeed")
+	}
+	if got := ActiveLevel(); got != bestLevel() {
+		t.Errorf("Expected %v, got %v", bestLevel(), got)
+	}
+	if HasSIMD() && ActiveLevel() == LevelScalar {
+		t.Error("Auto level should not be scalar when SIMD is available")
+	}
+
+	input := []byte(`{"a":[1,"two",{"three":true}],"b":"x\"y"}`)
+	s := New()
+	defer s.Release()
+	s.buf = input
+	s.structuralIndices = s.structuralIndices[:0]
+	s.scanScalar()
+	want := append([]uint32(nil), s.GetStructuralIndices()...)
+
+	for _, l := range []Level{LevelScalar, LevelSWAR, LevelSSE42, LevelAVX2, LevelNEON} {
+		if !SetLevel(l) {
+			if l == LevelScalar {
+				t.Fatal("Scalar level should always be supported")
+			}
+			continue
+		}
+		if got := ActiveLevel(); got != l {
+			t.Errorf("Expected active level %v, got %v", l, got)
+		}
+		if err := s.Scan(input); err != nil {
+			t.Fatalf("Level %v: scan failed: %v", l, err)
+		}
+		if l != LevelScalar && l != LevelSWAR {
+			// The assembly kernels are checked against scalar elsewhere
+			continue
+		}
+		if got := s.GetStructuralIndices(); !reflect.DeepEqual(want, got) {
+			t.Errorf("Level %v: expected %v, got %v", l, want, got)
+		}
+	}
+
+	SetLevel(LevelScalar)
+	if SetLevel(Level(100)) {
+		t.Error("Unknown level should be rejected")
+	}
+	if ActiveLevel() != LevelScalar {
+		t.Error("Rejected level should leave the active level unchanged")
+	}
+}
+
+func TestLevelString(t *testing.T) {
+	if LevelAVX2.String() != "avx2" || LevelScalar.String() != "scalar" {
+		t.Errorf("Unexpected level names %q, %q", LevelAVX2, LevelScalar)
+	}
+	if Level(-1).String() != "Level(-1)" {
+		t.Errorf("Unexpected name for invalid level: %q", Level(-1))
+	}
+}
@@ -0,0 +1,34 @@
+package scanner
+
+import "strconv"
+
+// Limits bounds the documents SimpleTokenize accepts, so that hostile
+// input is rejected while it is being scanned rather than after it has
+// been decoded. Zero fields are not checked.
+type Limits struct {
+	MaxBytes      int // length of the document
+	MaxTokens     int // tokens, brackets, colons and commas included
+	MaxObjectKeys int // members of any one object
+	MaxStringLen  int // bytes between the quotes of a string or key
+	MaxDepth      int // nesting of objects and arrays, at most MaxDepth
+}
+
+// A LimitError reports the first of the Limits a document exceeds.
+type LimitError struct {
+	Limit  string // name of the Limits field
+	Max    int    // its value
+	Offset int    // byte offset where it was exceeded
+}
+
+func (e *LimitError) Error() string {
+	return "document exceeds " + e.Limit + " of " + strconv.Itoa(e.Max) +
+		" at offset " + strconv.Itoa(e.Offset)
+}
+
+// orMax returns limit, or max if limit is zero or above it.
+func orMax(limit, max int) int {
+	if limit <= 0 || limit > max {
+		return max
+	}
+	return limit
+}
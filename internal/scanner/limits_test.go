@@ -0,0 +1,41 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits Limits
+		input  string
+		err    string
+	}{
+		{"SiblingObjects", Limits{MaxObjectKeys: 2}, `[{"a":1,"b":2},{"c":3,"d":4}]`, ""},
+		{"NestedKeysCountSeparately", Limits{MaxObjectKeys: 2}, `{"a":{"b":1,"c":2},"d":3}`, ""},
+		{"KeysAfterNested", Limits{MaxObjectKeys: 2}, `{"a":[{"b":1}],"c":2,"d":3}`, "document exceeds MaxObjectKeys of 2 at offset 21"},
+		{"EscapesCount", Limits{MaxStringLen: 4}, `"\u0041"`, "document exceeds MaxStringLen of 4 at offset 0"},
+		{"DepthAboveBuiltIn", Limits{MaxDepth: MaxDepth + 1}, strings.Repeat("[", MaxDepth+1), "exceeded max nesting depth"},
+		{"Unlimited", Limits{}, `{"abcdefghijklmnop":[[[[[[1]]]]]]}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			defer s.Release()
+			s.Limits = tt.limits
+
+			tokens, err := s.SimpleTokenize([]byte(tt.input))
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				PutTokenSlice(tokens)
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected %s, got %v", tt.err, err)
+			}
+		})
+	}
+}
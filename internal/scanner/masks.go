@@ -0,0 +1,17 @@
+package scanner
+
+// QuoteMask appends to dst one uint64 per 64-byte block of data in which
+// bit i is set when data[block*64+i] is a quote that opens or closes a
+// string, i.e. a quote not escaped by an odd run of backslashes. Unlike
+// SIMDQuoteMask the layout is the same on every architecture. Each block
+// is classified by BlockState.Next.
+func QuoteMask(data []byte, dst []uint64) []uint64 {
+	var st BlockState
+	for len(data) > 0 {
+		n := min(len(data), 64)
+		quotes, _ := st.Next(data[:n])
+		dst = append(dst, quotes)
+		data = data[n:]
+	}
+	return dst
+}
@@ -1,21 +1,40 @@
 package scanner
 
-import "sync"
+import (
+	"sync"
+	"unsafe"
 
-var tokenPool = sync.Pool{
-	New: func() interface{} {
-		return make([]Token, 0, 64)
-	},
-}
+	"github.com/biggeezerdevelopment/simdjson-go/internal/pool"
+)
+
+// tokenPool holds token slices by pointer, as putting a slice itself in a
+// sync.Pool allocates its header. The pointers emptied by getTokenSlice
+// are kept in holders for PutTokenSlice, so neither allocates once warm.
+var tokenPool = pool.New("tokens", func() *[]Token {
+	tokens := make([]Token, 0, 64)
+	return &tokens
+}, func(tokens *[]Token) int {
+	return cap(*tokens) * int(unsafe.Sizeof(Token{}))
+})
+
+var holders sync.Pool
 
 func getTokenSlice() []Token {
-	return tokenPool.Get().([]Token)
+	h := tokenPool.Get()
+	tokens := *h
+	*h = nil
+	holders.Put(h)
+	return tokens
 }
 
 func PutTokenSlice(tokens []Token) {
 	if cap(tokens) > 1024 { // Don't pool very large slices
 		return
 	}
-	tokens = tokens[:0]
-	tokenPool.Put(tokens)
-}
\ No newline at end of file
+	h, _ := holders.Get().(*[]Token)
+	if h == nil {
+		h = new([]Token)
+	}
+	*h = tokens[:0]
+	tokenPool.Put(h)
+}
@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// Scalar kernels shared as the fallback of every backend
+
+// parseIntegerScalar provides scalar integer parsing fallback
+func (s *Scanner) parseIntegerScalar(data []byte) (int64, bool) {
+	if len(data) == 0 {
+		return 0, false
+	}
+
+	var result uint64
+	var negative bool
+	start := 0
+	parsed := false
+
+	// Check for negative sign
+	if data[0] == '-' {
+		negative = true
+		start = 1
+		if len(data) == 1 {
+			return 0, false
+		}
+	}
+
+	// Parse digits; a negative number may reach one past MaxInt64
+	limit := uint64(math.MaxInt64)
+	if negative {
+		limit++
+	}
+	for i := start; i < len(data); i++ {
+		c := data[i]
+		if c < '0' || c > '9' {
+			break
+		}
+
+		parsed = true
+		digit := uint64(c - '0')
+
+		// Check for overflow
+		if result > (limit-digit)/10 {
+			return 0, false
+		}
+
+		result = result*10 + digit
+	}
+
+	if negative {
+		return -int64(result), parsed
+	}
+
+	return int64(result), parsed
+}
+
+// generateQuoteMaskScalar provides scalar quote mask generation
+func (s *Scanner) generateQuoteMaskScalar(data []byte) ([]uint64, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	maskCount := (len(data) + 63) / 64
+	masks := make([]uint64, maskCount)
+
+	inString := false
+	escaped := false
+
+	for i, b := range data {
+		if escaped {
+			escaped = false
+			continue
+		}
+
+		if b == '\\' && inString {
+			escaped = true
+			continue
+		}
+
+		if b == '"' {
+			maskIndex := i / 64
+			bitIndex := i % 64
+			masks[maskIndex] |= 1 << bitIndex
+			inString = !inString
+		}
+	}
+
+	return masks, nil
+}
+
+// validateUTF8Scalar provides scalar UTF-8 validation
+func (s *Scanner) validateUTF8Scalar(data []byte) bool {
+	return utf8.Valid(data)
+}
@@ -2,7 +2,9 @@ package scanner
 
 import (
 	"errors"
-	"sync"
+	"sync/atomic"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/pool"
 )
 
 const (
@@ -26,31 +28,90 @@ type Scanner struct {
 	// Reusable buffers
 	tempBuf          []byte
 	charClassifier   [256]uint64
+	stack            []byte
+	open             []uint32 // indices of the unclosed brackets in SimpleTokenize
+	links            []uint32 // see SkipValue; empty until it is first called
+	
+	// Incremental scanning state (see Feed)
+	feed             feedState
+	
+	// Cancel, if set, is called by SimpleTokenize every CancelInterval
+	// bytes; a non-nil result stops tokenizing and is returned
+	Cancel           func() error
+	
+	// Progress, if set, is called by SimpleTokenize every CancelInterval
+	// bytes with the number of bytes scanned
+	Progress         func(scanned int)
+	
+	// Limits bounds the documents SimpleTokenize accepts
+	Limits           Limits
+	
+	// NonFinite makes SimpleTokenize accept NaN, Infinity and -Infinity
+	// as number tokens with FlagFraction set, as JavaScript and Python
+	// write them
+	NonFinite        bool
 }
 
-var scannerPool = sync.Pool{
-	New: func() interface{} {
-		s := &Scanner{
-			structuralIndices: make([]uint32, 0, 1024),
-			tempBuf:          make([]byte, 64),
-		}
-		s.initCharClassifier()
-		return s
-	},
-}
+// maxPooledIndices caps the index buffer retained by a pooled scanner.
+const maxPooledIndices = 1 << 20
+
+var scannerPool = pool.New("scanner", func() *Scanner {
+	s := &Scanner{
+		structuralIndices: make([]uint32, 0, 1024),
+		tempBuf:          make([]byte, 64),
+	}
+	s.initCharClassifier()
+	return s
+}, (*Scanner).BufferSize)
 
 func New() *Scanner {
-	return scannerPool.Get().(*Scanner)
+	return scannerPool.Get()
+}
+
+// BufferSize returns the bytes of buffer capacity s holds, the size by
+// which the pool limits and counts it.
+func (s *Scanner) BufferSize() int {
+	return 4*cap(s.structuralIndices) + 8*cap(s.stringMask) + cap(s.tempBuf) +
+		cap(s.stack) + 4*cap(s.open) + 4*cap(s.links)
 }
 
 func (s *Scanner) Release() {
 	s.buf = nil
 	s.structuralIndices = s.structuralIndices[:0]
 	s.stringMask = s.stringMask[:0]
+	s.links = s.links[:0]
 	s.pos = 0
+	s.feed = feedState{}
+	s.Cancel = nil
+	s.Progress = nil
+	s.Limits = Limits{}
+	s.NonFinite = false
+	if cap(s.structuralIndices) > maxPooledIndices {
+		// Don't pin the index buffer of an unusually large document
+		s.structuralIndices = make([]uint32, 0, 1024)
+	}
+	if cap(s.links) > maxPooledIndices {
+		s.links = nil
+	}
 	scannerPool.Put(s)
 }
 
+// reserveIndices makes room for at least n more structural indices,
+// growing the buffer geometrically.
+func (s *Scanner) reserveIndices(n int) {
+	need := len(s.structuralIndices) + n
+	if need <= cap(s.structuralIndices) {
+		return
+	}
+	newCap := 2 * cap(s.structuralIndices)
+	if newCap < need {
+		newCap = need
+	}
+	grown := make([]uint32, len(s.structuralIndices), newCap)
+	copy(grown, s.structuralIndices)
+	s.structuralIndices = grown
+}
+
 func (s *Scanner) initCharClassifier() {
 	// Initialize character classifier lookup table
 	s.charClassifier['"'] = StructuralQuote
@@ -70,17 +131,33 @@ func (s *Scanner) initCharClassifier() {
 func (s *Scanner) Scan(data []byte) error {
 	s.buf = data
 	s.structuralIndices = s.structuralIndices[:0]
+	s.links = s.links[:0]
 	
-	if hasSIMD() {
+	if ActiveLevel() != LevelScalar {
+		if CountScans.Load() {
+			SIMDScans.Add(1)
+		}
 		return s.scanSIMD()
 	}
+	if CountScans.Load() {
+		ScalarScans.Add(1)
+	}
 	return s.scanScalar()
 }
 
+// SIMDScans and ScalarScans count the calls of Scan by the kernel that
+// served them, while CountScans is set.
+var (
+	CountScans  atomic.Bool
+	SIMDScans   atomic.Uint64
+	ScalarScans atomic.Uint64
+)
+
 // ScanSIMD forces SIMD scanning (exported for benchmarks)
 func (s *Scanner) ScanSIMD(data []byte) error {
 	s.buf = data
 	s.structuralIndices = s.structuralIndices[:0]
+	s.links = s.links[:0]
 	return s.scanSIMD()
 }
 
@@ -92,6 +169,7 @@ func HasSIMD() bool {
 func (s *Scanner) scanScalar() error {
 	inString := false
 	escaped := false
+	boundary := true // the previous byte was structural or whitespace
 	
 	for i := 0; i < len(s.buf); i++ {
 		c := s.buf[i]
@@ -108,19 +186,27 @@ func (s *Scanner) scanScalar() error {
 		
 		if c == '"' {
 			inString = !inString
+			boundary = true
 			s.structuralIndices = append(s.structuralIndices, uint32(i))
 			continue
 		}
 		
 		if !inString {
-			class := s.charClassifier[c]
-			if class != 0 && class != StructuralWhitespace {
+			switch class := s.charClassifier[c]; {
+			case class == StructuralWhitespace:
+				boundary = true
+			case class != 0:
 				s.structuralIndices = append(s.structuralIndices, uint32(i))
-			} else if class == 0 && (c >= '0' && c <= '9') || c == '-' || c == 't' || c == 'f' || c == 'n' {
-				// Start of a value (number, true, false, null)
-				if i == 0 || s.charClassifier[s.buf[i-1]] != 0 || s.buf[i-1] == ' ' || s.buf[i-1] == '\t' || s.buf[i-1] == '\n' || s.buf[i-1] == '\r' {
+				boundary = true
+			default:
+				// Pseudo-structural, as in the simdjson paper: any other
+				// byte following a structural character or whitespace
+				// starts a scalar, valid or not, and the rest of the
+				// scalar is not indexed
+				if boundary {
 					s.structuralIndices = append(s.structuralIndices, uint32(i))
 				}
+				boundary = false
 			}
 		}
 	}
@@ -132,47 +218,6 @@ func (s *Scanner) GetStructuralIndices() []uint32 {
 	return s.structuralIndices
 }
 
-func (s *Scanner) Validate(data []byte) bool {
-	// Try to tokenize - if it fails, JSON is invalid
-	tokens, err := s.SimpleTokenize(data)
-	if err != nil {
-		return false
-	}
-	
-	// Basic structural validation
-	depth := 0
-	stack := make([]TokenType, 0, 32)
-	
-	for _, token := range tokens {
-		switch token.Type {
-		case TokenObjectBegin:
-			depth++
-			stack = append(stack, TokenObjectBegin)
-		case TokenArrayBegin:
-			depth++
-			stack = append(stack, TokenArrayBegin)
-		case TokenObjectEnd:
-			depth--
-			if len(stack) == 0 || stack[len(stack)-1] != TokenObjectBegin {
-				return false
-			}
-			stack = stack[:len(stack)-1]
-		case TokenArrayEnd:
-			depth--
-			if len(stack) == 0 || stack[len(stack)-1] != TokenArrayBegin {
-				return false
-			}
-			stack = stack[:len(stack)-1]
-		}
-		
-		if depth < 0 {
-			return false
-		}
-	}
-	
-	return depth == 0 && len(stack) == 0
-}
-
 type TokenType uint8
 
 const (
@@ -190,10 +235,43 @@ const (
 	TokenComma
 )
 
+// TokenFlags describe the content of a string or number token, so that
+// decoders can pick a parsing routine without scanning its bytes again.
+type TokenFlags uint8
+
+const (
+	FlagEscape   TokenFlags = 1 << iota // a string holds a backslash escape
+	FlagNonASCII                        // a string holds bytes from 0x80
+	FlagNegative                        // a number starts with '-'
+	FlagFraction                        // a number has a fractional part
+	FlagExponent                        // a number has an exponent
+)
+
 type Token struct {
 	Type  TokenType
+	Flags TokenFlags // set by SimpleTokenize
 	Start uint32
 	End   uint32
+
+	// Link is set by SimpleTokenize for '{' and '[' to the index of the
+	// matching closing token, as in simdjson's tape, so SkipTokens passes
+	// over a subtree in one step. It is 0 if the bracket is never closed.
+	Link uint32
+}
+
+// HasEscape reports whether a string token holds a backslash escape.
+func (t Token) HasEscape() bool {
+	return t.Flags&FlagEscape != 0
+}
+
+// IsFloat reports whether a number token has a fraction or an exponent.
+func (t Token) IsFloat() bool {
+	return t.Flags&(FlagFraction|FlagExponent) != 0
+}
+
+// IsNegative reports whether a number token starts with '-'.
+func (t Token) IsNegative() bool {
+	return t.Flags&FlagNegative != 0
 }
 
 func (s *Scanner) Tokenize() ([]Token, error) {
@@ -1,6 +1,9 @@
 package scanner
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 	"unsafe"
 )
@@ -394,6 +397,29 @@ func BenchmarkScanner_ScalarVsSIMD(b *testing.B) {
 	}
 }
 
+func BenchmarkSimpleTokenizeIndented(b *testing.B) {
+	var v interface{} = []int{1, 2, 3}
+	for depth := 0; depth < 12; depth++ {
+		v = map[string]interface{}{"items": []interface{}{v, "x", 1.5}, "ok": true}
+	}
+	data, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		b.Fatal(err)
+	}
+	s := New()
+	defer s.Release()
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tokens, err := s.SimpleTokenize(data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		PutTokenSlice(tokens)
+	}
+}
+
 func TestSimpleTokenize(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -451,4 +477,252 @@ func TestSimpleTokenize(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestSimpleTokenizeIndented(t *testing.T) {
+	compact := `{"a":[1,{"b":"c"}],"d":null}`
+	s := New()
+	defer s.Release()
+	want, err := s.SimpleTokenize([]byte(compact))
+	if err != nil {
+		t.Fatalf("SimpleTokenize failed: %v", err)
+	}
+	types := make([]TokenType, len(want))
+	for i, tok := range want {
+		types[i] = tok.Type
+	}
+	PutTokenSlice(want)
+
+	for _, indent := range []string{" ", "    ", "\t", "\t\t", " \t"} {
+		for _, newline := range []string{"\n", "\r\n"} {
+			for depth := 0; depth < 12; depth++ {
+				// Every token on a line of its own, indented depth times
+				pad := newline + strings.Repeat(indent, depth)
+				var b strings.Builder
+				for i, tok := range strings.SplitAfter(`{|"a"|:|[|1|,|{|"b"|:|"c"|}|]|,|"d"|:|null|}`, "|") {
+					if i > 0 {
+						b.WriteString(pad)
+					}
+					b.WriteString(strings.TrimSuffix(tok, "|"))
+				}
+				b.WriteString(pad)
+				input := b.String()
+
+				tokens, err := s.SimpleTokenize([]byte(input))
+				if err != nil {
+					t.Fatalf("Input %q: %v", input, err)
+				}
+				if len(tokens) != len(types) {
+					t.Fatalf("Input %q: expected %d tokens, got %d", input, len(types), len(tokens))
+				}
+				for i, tok := range tokens {
+					if tok.Type != types[i] || input[tok.Start] == ' ' || input[tok.Start] == '\t' {
+						t.Errorf("Input %q token %d: expected type %v, got %v at %d", input, i, types[i], tok.Type, tok.Start)
+					}
+				}
+				PutTokenSlice(tokens)
+
+				// Other control bytes still end the run and are rejected
+				bad := strings.Replace(input, pad, pad+"\v", 1)
+				if _, err := s.SimpleTokenize([]byte(bad)); err == nil {
+					t.Errorf("Input %q: expected error", bad)
+				}
+			}
+		}
+	}
+}
+
+func TestSimpleTokenizeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"control character", "[\"a\x00b\"]"},
+		{"raw newline", "[\"a\nb\"]"},
+		{"invalid UTF-8", "[\"\xff\"]"},
+		{"encoded surrogate", "[\"\xed\xa0\x80\"]"},
+		{"truncated UTF-8", "[\"\xe6\x97\"]"},
+		{"unterminated string", `["abc`},
+		{"unterminated escape", `["abc\`},
+		{"too deep", strings.Repeat("[", MaxDepth+1) + strings.Repeat("]", MaxDepth+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			defer s.Release()
+
+			if _, err := s.SimpleTokenize([]byte(tt.input)); err == nil {
+				t.Errorf("Expected error for %.40q", tt.input)
+			}
+		})
+	}
+
+	s := New()
+	defer s.Release()
+	deep := strings.Repeat("[", MaxDepth) + strings.Repeat("]", MaxDepth)
+	if _, err := s.SimpleTokenize([]byte(deep)); err != nil {
+		t.Errorf("Expected %d levels to be accepted, got %v", MaxDepth, err)
+	}
+	if _, err := s.SimpleTokenize([]byte("[\"\\\"a\\\\\", \"日本\u2028\"]")); err != nil {
+		t.Errorf("Expected escapes and UTF-8 to be accepted, got %v", err)
+	}
+}
+
+func TestSimpleTokenizeFlags(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		flags TokenFlags
+	}{
+		{"plain string", `"abc"`, 0},
+		{"escaped string", `"a\nb"`, FlagEscape},
+		{"non-ASCII string", `"café"`, FlagNonASCII},
+		{"escaped non-ASCII string", `"\"café"`, FlagEscape | FlagNonASCII},
+		{"integer", `42`, 0},
+		{"negative integer", `-42`, FlagNegative},
+		{"fraction", `4.2`, FlagFraction},
+		{"exponent", `4e2`, FlagExponent},
+		{"negative fraction and exponent", `-4.2E-2`, FlagNegative | FlagFraction | FlagExponent},
+		{"literal", `true`, 0},
+	}
+
+	s := New()
+	defer s.Release()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := s.SimpleTokenize([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer PutTokenSlice(tokens)
+			if len(tokens) != 1 {
+				t.Fatalf("Expected 1 token, got %d", len(tokens))
+			}
+			if tokens[0].Flags != tt.flags {
+				t.Errorf("Expected flags %05b, got %05b", tt.flags, tokens[0].Flags)
+			}
+		})
+	}
+}
+
+func TestSimpleTokenizeNonFinite(t *testing.T) {
+	input := []byte(`[NaN,Infinity,-Infinity,-1]`)
+
+	s := New()
+	defer s.Release()
+	if _, err := s.SimpleTokenize(input); err == nil {
+		t.Fatal("Expected NaN to be rejected without NonFinite")
+	}
+
+	s.NonFinite = true
+	tokens, err := s.SimpleTokenize(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer PutTokenSlice(tokens)
+	expected := []struct {
+		text  string
+		flags TokenFlags
+	}{
+		{"NaN", FlagFraction},
+		{"Infinity", FlagFraction},
+		{"-Infinity", FlagNegative | FlagFraction},
+		{"-1", FlagNegative},
+	}
+	for i, want := range expected {
+		tok := tokens[1+2*i]
+		if tok.Type != TokenNumber || string(input[tok.Start:tok.End]) != want.text || tok.Flags != want.flags {
+			t.Errorf("Expected number %s with flags %05b, got %v %q with flags %05b",
+				want.text, want.flags, tok.Type, input[tok.Start:tok.End], tok.Flags)
+		}
+	}
+
+	for _, bad := range []string{`Nan`, `Inf`, `-Inf`, `+Infinity`} {
+		if _, err := s.SimpleTokenize([]byte(bad)); err == nil {
+			t.Errorf("Expected %s to be rejected", bad)
+		}
+	}
+}
+
+func TestScanner_ReserveIndices(t *testing.T) {
+	s := New()
+	defer s.Release()
+
+	s.structuralIndices = append(s.structuralIndices[:0], 1, 2, 3)
+	s.reserveIndices(5000)
+	if cap(s.structuralIndices)-len(s.structuralIndices) < 5000 {
+		t.Fatalf("Expected room for 5000 indices, got %d", cap(s.structuralIndices)-len(s.structuralIndices))
+	}
+	if len(s.structuralIndices) != 3 || s.structuralIndices[2] != 3 {
+		t.Errorf("Existing indices not preserved: %v", s.structuralIndices)
+	}
+}
+
+func TestScanner_SIMDMultiBlock(t *testing.T) {
+	// Larger than one SIMD block and dense in structural characters
+	var b strings.Builder
+	b.WriteString("[")
+	for b.Len() < 200*1024 {
+		b.WriteString(`[1,{"a":[]}],`)
+	}
+	b.WriteString("0]")
+	data := []byte(b.String())
+
+	s := New()
+	defer s.Release()
+	if err := s.ScanSIMD(data); err != nil {
+		t.Fatalf("ScanSIMD failed: %v", err)
+	}
+	simd := append([]uint32(nil), s.GetStructuralIndices()...)
+
+	if len(simd) < len(data)/2 {
+		t.Fatalf("Expected at least %d indices, got %d", len(data)/2, len(simd))
+	}
+	for i, idx := range simd {
+		if int(idx) >= len(data) {
+			t.Fatalf("Index %d out of range: %d", i, idx)
+		}
+		if i > 0 && idx <= simd[i-1] {
+			t.Fatalf("Indices not increasing at %d", i)
+		}
+	}
+
+	// Scanning again reuses the buffer
+	s.ScanSIMD(data)
+	allocs := testing.AllocsPerRun(10, func() {
+		s.ScanSIMD(data)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected buffer reuse, got %.1f allocations per scan", allocs)
+	}
+}
+
+func TestSimpleTokenizeCancel(t *testing.T) {
+	data := []byte("[" + strings.Repeat(`"abcdefg",`, 4*CancelInterval/10) + "0]")
+	errStop := errors.New("stop")
+
+	s := New()
+	defer s.Release()
+
+	calls := 0
+	s.Cancel = func() error {
+		if calls++; calls == 2 {
+			return errStop
+		}
+		return nil
+	}
+	if _, err := s.SimpleTokenize(data); err != errStop {
+		t.Errorf("Expected the Cancel error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected tokenizing to stop at the second check, got %d calls", calls)
+	}
+
+	s.Cancel = func() error { return nil }
+	tokens, err := s.SimpleTokenize(data)
+	if err != nil || len(tokens) != 2*(4*CancelInterval/10)+3 {
+		t.Errorf("Expected the document tokenized, got %d tokens, error %v", len(tokens), err)
+	}
+	PutTokenSlice(tokens)
+}
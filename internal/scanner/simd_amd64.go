@@ -3,138 +3,113 @@
 package scanner
 
 import (
-	"errors"
-	"unsafe"
-)
-
-//go:noescape
-func findStructuralIndicesAVX2(data unsafe.Pointer, length uint64, indices *uint32) uint64
-
-//go:noescape
-func findQuoteMaskAVX2(data unsafe.Pointer, length uint64, mask *uint64) uint64
-
-//go:noescape
-func validateUTF8AVX2(data unsafe.Pointer, length uint64) bool
-
-//go:noescape
-func parseIntegerAVX2(data unsafe.Pointer, length uint64) (int64, bool)
+	"math/bits"
 
-// SSE4.2 fallback functions
-//go:noescape
-func findStructuralIndicesSSE42(data unsafe.Pointer, length uint64, indices *uint32) uint64
+	"github.com/biggeezerdevelopment/simdjson-go/internal/classify"
+)
 
-//go:noescape
-func findQuoteMaskSSE42(data unsafe.Pointer, length uint64, mask *uint64) uint64
+// The AVX2 level scans as simdjson's stage 1 does: the classify package's
+// kernel computes the masks of each 64-byte block, BlockState turns them
+// into the strings of the block, and the structural indices are the set
+// bits of the result. There are no SSE4.2 kernels; CPUs without AVX2 use
+// the scalar ones.
 
-//go:noescape
-func validateUTF8SSE42(data unsafe.Pointer, length uint64) bool
+func hasSIMD() bool {
+	return hasAVX2()
+}
 
-//go:noescape
-func parseIntegerSSE42(data unsafe.Pointer, length uint64) (int64, bool)
+// bestLevel returns the fastest kernel family the CPU supports
+func bestLevel() Level {
+	if hasAVX2() {
+		return LevelAVX2
+	}
+	return LevelScalar
+}
 
-func hasSIMD() bool {
-	return hasAVX2() || hasSSE42()
+// supportsLevel reports whether the CPU can run the kernels of level l
+func supportsLevel(l Level) bool {
+	return l == LevelAVX2 && hasAVX2()
 }
 
+// simdBlocks is the number of 64-byte blocks classified per kernel call.
+// A block yields at most 64 indices, so reserving 64 free slots per block
+// before each call bounds the writes to the index buffer.
+const simdBlocks = 16
+
 func (s *Scanner) scanSIMD() error {
 	if len(s.buf) == 0 {
 		return nil
 	}
-	
-	// Ensure we have enough space for indices (estimate 1/8 of buffer size)
-	estimatedIndices := len(s.buf) / 8
-	if cap(s.structuralIndices) < estimatedIndices {
-		s.structuralIndices = make([]uint32, 0, estimatedIndices)
-	}
-	
-	dataPtr := unsafe.Pointer(&s.buf[0])
-	indicesPtr := unsafe.Pointer(&s.structuralIndices[0])
-	
-	// Resize slice to have capacity for potential indices
-	s.structuralIndices = s.structuralIndices[:cap(s.structuralIndices)]
-	
-	var count uint64
-	if hasAVX2() {
-		count = findStructuralIndicesAVX2(dataPtr, uint64(len(s.buf)), (*uint32)(indicesPtr))
-	} else if hasSSE42() {
-		count = findStructuralIndicesSSE42(dataPtr, uint64(len(s.buf)), (*uint32)(indicesPtr))
-	} else {
-		// Fallback to scalar
+	if ActiveLevel() != LevelAVX2 {
 		return s.scanScalar()
 	}
-	
-	// Resize slice to actual count
-	s.structuralIndices = s.structuralIndices[:count]
-	
+
+	// Start from an estimate of 1/8 of the input; the buffer is kept by the
+	// pooled scanner so steady-state scans do not allocate.
+	s.reserveIndices(len(s.buf) / 8)
+
+	var (
+		st       BlockState
+		masks    [simdBlocks]classify.Masks
+		tail     [64]byte
+		sepCarry uint64 = 1 // the previous byte was structural or whitespace
+	)
+	for base := 0; base < len(s.buf); {
+		n := classify.Blocks(masks[:], s.buf[base:])
+		if n == 0 {
+			// Spaces pad the last block without adding indices
+			copy(tail[:], s.buf[base:])
+			for i := len(s.buf) - base; i < 64; i++ {
+				tail[i] = ' '
+			}
+			n = classify.Blocks(masks[:1], tail[:])
+		}
+
+		s.reserveIndices(64 * n)
+		k := len(s.structuralIndices)
+		out := s.structuralIndices[:cap(s.structuralIndices)]
+		for _, m := range masks[:n] {
+			quotes, inString, _ := st.next(m.Quote, m.Backslash)
+			if m.Backslash&^inString != 0 {
+				// The scalar kernels index a backslash outside a string
+				// instead of letting it escape the next byte. Only invalid
+				// JSON has one, so scan it their way.
+				s.structuralIndices = s.structuralIndices[:0]
+				return s.scanScalar()
+			}
+
+			// Pseudo-structurals: the first byte of each run of other bytes
+			// outside strings, following a structural or whitespace
+			sep := (m.Op|m.Space)&^inString | quotes
+			scalar := ^(inString | quotes | m.Op | m.Space)
+			starts := scalar & (sep<<1 | sepCarry)
+			sepCarry = sep >> 63
+
+			for structurals := quotes | m.Op&^inString | starts; structurals != 0; structurals &= structurals - 1 {
+				out[k] = uint32(base + bits.TrailingZeros64(structurals))
+				k++
+			}
+			base += 64
+		}
+		s.structuralIndices = out[:k]
+	}
+
 	return nil
 }
 
-// SIMDQuoteMask generates a bitmask of quote positions for fast string detection
+// SIMDQuoteMask uses scalar mask generation: the AVX2 level has no quote
+// mask kernel of its own
 func (s *Scanner) SIMDQuoteMask(data []byte) ([]uint64, error) {
-	s.buf = data
-	if len(s.buf) == 0 {
-		return nil, nil
-	}
-	
-	var chunkSize, maskCount int
-	if hasAVX2() {
-		chunkSize = 32
-		maskCount = (len(s.buf) + 31) / 32
-	} else {
-		chunkSize = 16
-		maskCount = (len(s.buf) + 15) / 16
-	}
-	
-	masks := make([]uint64, maskCount)
-	dataPtr := unsafe.Pointer(&s.buf[0])
-	maskPtr := unsafe.Pointer(&masks[0])
-	
-	var actualCount uint64
-	if hasAVX2() {
-		actualCount = findQuoteMaskAVX2(dataPtr, uint64(len(s.buf)), (*uint64)(maskPtr))
-	} else if hasSSE42() {
-		actualCount = findQuoteMaskSSE42(dataPtr, uint64(len(s.buf)), (*uint64)(maskPtr))
-	} else {
-		return nil, errors.New("SIMD not available")
-	}
-	
-	return masks[:actualCount], nil
+	return s.generateQuoteMaskScalar(data)
 }
 
-// SIMDValidateUTF8 validates UTF-8 encoding using SIMD instructions
+// SIMDValidateUTF8 uses unicode/utf8, which already checks eight ASCII
+// bytes per step
 func (s *Scanner) SIMDValidateUTF8(data []byte) bool {
-	if len(data) == 0 {
-		return true
-	}
-	
-	dataPtr := unsafe.Pointer(&data[0])
-	if hasAVX2() {
-		return validateUTF8AVX2(dataPtr, uint64(len(data)))
-	} else if hasSSE42() {
-		return validateUTF8SSE42(dataPtr, uint64(len(data)))
-	}
-	
-	// Fallback to standard validation
-	return true // Simplified
-}
-
-// SIMDParseInteger attempts to parse an integer using SIMD acceleration
-func SIMDParseInteger(data []byte) (int64, bool) {
-	if len(data) == 0 {
-		return 0, false
-	}
-	
-	dataPtr := unsafe.Pointer(&data[0])
-	if hasAVX2() {
-		return parseIntegerAVX2(dataPtr, uint64(len(data)))
-	} else if hasSSE42() {
-		return parseIntegerSSE42(dataPtr, uint64(len(data)))
-	}
-	
-	return 0, false
+	return s.validateUTF8Scalar(data)
 }
 
-// SIMDParseInteger method for Scanner
+// SIMDParseInteger uses scalar parsing
 func (s *Scanner) SIMDParseInteger(data []byte) (int64, bool) {
-	return SIMDParseInteger(data)
-}
\ No newline at end of file
+	return s.parseIntegerScalar(data)
+}
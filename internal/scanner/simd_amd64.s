@@ -1,112 +0,0 @@
-//go:build amd64 && !noasm
-
-#include "textflag.h"
-
-// findStructuralBitsAVX2(buf unsafe.Pointer, len uint64, indices *uint32) uint64
-TEXT ·findStructuralBitsAVX2(SB), NOSPLIT, $0-32
-    MOVQ buf+0(FP), SI      // Source buffer
-    MOVQ len+8(FP), CX      // Length
-    MOVQ indices+16(FP), DI // Output indices array
-    
-    XORQ AX, AX             // Index counter
-    
-    // Load character masks for structural characters
-    VPBROADCASTB structural_chars<>(SB), Y0  // Broadcast structural chars
-    
-loop:
-    CMPQ CX, $32
-    JL   done
-    
-    // Load 32 bytes
-    VMOVDQU (SI), Y1
-    
-    // Compare with structural characters
-    VPCMPEQB Y0, Y1, Y2
-    
-    // Extract mask of matches
-    VPMOVMSKB Y2, DX
-    
-    // Store indices of set bits
-    TESTQ DX, DX
-    JZ    next
-    
-    // Process set bits
-    BSFQ DX, BX
-store_loop:
-    MOVL AX, (DI)
-    ADDQ BX, AX
-    ADDQ $4, DI
-    BTRQ BX, DX
-    JZ   next
-    BSFQ DX, BX
-    JMP  store_loop
-    
-next:
-    ADDQ $32, SI
-    ADDQ $32, AX
-    SUBQ $32, CX
-    JMP  loop
-    
-done:
-    MOVQ AX, ret+24(FP)
-    RET
-
-// Character lookup table for structural characters
-DATA structural_chars<>+0(SB)/8, $0x7b7d5b5d3a2c2022  // {}[]:,""
-GLOBL structural_chars<>(SB), RODATA, $8
-
-// findQuotesAVX2(buf unsafe.Pointer, len uint64, quotes *uint64) uint64
-TEXT ·findQuotesAVX2(SB), NOSPLIT, $0-32
-    MOVQ buf+0(FP), SI
-    MOVQ len+8(FP), CX
-    MOVQ quotes+16(FP), DI
-    
-    VPBROADCASTB quote_char<>(SB), Y0  // Broadcast quote character
-    VPBROADCASTB backslash_char<>(SB), Y1  // Broadcast backslash
-    
-    XORQ AX, AX  // Quote counter
-    
-loop_quotes:
-    CMPQ CX, $32
-    JL   done_quotes
-    
-    VMOVDQU (SI), Y2
-    
-    // Find quotes
-    VPCMPEQB Y0, Y2, Y3
-    
-    // Find backslashes
-    VPCMPEQB Y1, Y2, Y4
-    
-    // Get masks
-    VPMOVMSKB Y3, DX  // Quote mask
-    VPMOVMSKB Y4, BX  // Backslash mask
-    
-    // Store quote positions (simplified - doesn't handle escaping)
-    MOVQ DX, (DI)
-    ADDQ $8, DI
-    
-    ADDQ $32, SI
-    SUBQ $32, CX
-    INCQ AX
-    JMP  loop_quotes
-    
-done_quotes:
-    MOVQ AX, ret+24(FP)
-    RET
-
-DATA quote_char<>+0(SB)/1, $0x22  // "
-DATA backslash_char<>+0(SB)/1, $0x5c  // \
-GLOBL quote_char<>(SB), RODATA, $1
-GLOBL backslash_char<>(SB), RODATA, $1
-
-// validateUTF8AVX2(buf unsafe.Pointer, len uint64) bool
-TEXT ·validateUTF8AVX2(SB), NOSPLIT, $0-24
-    MOVQ buf+0(FP), SI
-    MOVQ len+8(FP), CX
-    
-    // Simplified UTF-8 validation
-    // Real implementation would use SIMD for parallel validation
-    
-    MOVB $1, ret+16(FP)  // Return true for now
-    RET
\ No newline at end of file
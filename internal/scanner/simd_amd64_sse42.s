@@ -1,292 +0,0 @@
-//go:build amd64 && !noasm
-
-#include "textflag.h"
-
-// SSE4.2 fallback implementations (16-byte chunks instead of 32-byte)
-
-// findStructuralIndicesSSE42 processes 16 bytes at a time using SSE4.2
-TEXT ·findStructuralIndicesSSE42(SB), NOSPLIT, $0-32
-    MOVQ    data+0(FP), SI        // Source data pointer
-    MOVQ    length+8(FP), CX      // Data length  
-    MOVQ    indices+16(FP), DI    // Output indices pointer
-    
-    XORQ    R8, R8                // Current position
-    XORQ    R9, R9                // Output index count
-    
-    // Load structural characters into XMM registers
-    MOVQ    $0x2C3A5D5B7D7B2200, AX  // Structural chars: \0"{[]},:"
-    MOVQ    AX, X0
-    PXOR    X1, X1                // Zero register for comparison
-
-sse_main_loop:
-    CMPQ    CX, $16               // Check if we have at least 16 bytes
-    JL      sse_remainder
-    
-    // Load 16 bytes
-    MOVDQU  (SI)(R8*1), X2
-    
-    // Use PCMPISTRM for pattern matching
-    MOVDQA  X0, X3                // Copy pattern
-    PCMPISTRM $0x00, X2, X3       // Compare any character match
-    MOVDQA  X0, X4                // Result in X0
-    
-    // Extract matches
-    PMOVMSKB X0, DX
-    TESTW   DX, DX
-    JZ      sse_next_chunk
-
-sse_process_bits:
-    BSFW    DX, BX                // Find lowest set bit
-    TESTW   BX, BX
-    JS      sse_next_chunk
-    
-    // Store index  
-    LEAQ    (R8)(BX*1), R10
-    MOVL    R10, (DI)(R9*4)
-    INCQ    R9
-    
-    // Clear bit and continue
-    BTRW    BX, DX
-    JNZ     sse_process_bits
-
-sse_next_chunk:
-    ADDQ    $16, R8
-    SUBQ    $16, CX
-    JMP     sse_main_loop
-
-sse_remainder:
-    // Process remaining bytes using scalar approach
-    TESTQ   CX, CX
-    JZ      sse_done
-
-sse_remainder_loop:
-    MOVB    (SI)(R8*1), AL
-    
-    // Check each structural character
-    CMPB    AL, $0x22             // "
-    JE      sse_store_index
-    CMPB    AL, $0x5C             // \
-    JE      sse_store_index  
-    CMPB    AL, $0x7B             // {
-    JE      sse_store_index
-    CMPB    AL, $0x7D             // }
-    JE      sse_store_index
-    CMPB    AL, $0x5B             // [
-    JE      sse_store_index
-    CMPB    AL, $0x5D             // ]
-    JE      sse_store_index
-    CMPB    AL, $0x3A             // :
-    JE      sse_store_index
-    CMPB    AL, $0x2C             // ,
-    JE      sse_store_index
-    JMP     sse_next_remainder
-
-sse_store_index:
-    MOVL    R8, (DI)(R9*4)
-    INCQ    R9
-
-sse_next_remainder:
-    INCQ    R8
-    DECQ    CX
-    JNZ     sse_remainder_loop
-
-sse_done:
-    MOVQ    R9, ret+24(FP)
-    RET
-
-// findQuoteMaskSSE42 creates quote bitmasks using SSE4.2
-TEXT ·findQuoteMaskSSE42(SB), NOSPLIT, $0-32
-    MOVQ    data+0(FP), SI
-    MOVQ    length+8(FP), CX
-    MOVQ    mask+16(FP), DI
-    
-    XORQ    R8, R8                // Current position
-    XORQ    R9, R9                // Mask count
-    
-    // Load quote character pattern
-    MOVQ    $0x2222222222222222, AX  // Repeated quote char
-    MOVQ    AX, X0
-
-sse_quote_loop:
-    CMPQ    CX, $16
-    JL      sse_quote_remainder
-    
-    MOVDQU  (SI)(R8*1), X1
-    PCMPEQB X0, X1                // Compare with quotes
-    PMOVMSKB X1, DX               // Extract mask
-    
-    // Store mask (16 bits in lower part of 64-bit value)
-    MOVW    DX, (DI)(R9*8)
-    INCQ    R9
-    
-    ADDQ    $16, R8
-    SUBQ    $16, CX
-    JMP     sse_quote_loop
-
-sse_quote_remainder:
-    TESTQ   CX, CX
-    JZ      sse_quote_done
-    
-    XORW    DX, DX
-    XORQ    R10, R10
-
-sse_quote_remainder_loop:
-    MOVB    (SI)(R8*1), AL
-    CMPB    AL, $0x22             // Quote character
-    JNE     sse_quote_next_rem
-    
-    BTSW    R10, DX               // Set bit at position
-
-sse_quote_next_rem:
-    INCQ    R8
-    INCQ    R10
-    DECQ    CX
-    JNZ     sse_quote_remainder_loop
-    
-    MOVW    DX, (DI)(R9*8)
-    INCQ    R9
-
-sse_quote_done:
-    MOVQ    R9, ret+24(FP)
-    RET
-
-// validateUTF8SSE42 validates UTF-8 using SSE4.2 string instructions
-TEXT ·validateUTF8SSE42(SB), NOSPLIT, $0-24
-    MOVQ    data+0(FP), SI
-    MOVQ    length+8(FP), CX
-    
-    XORQ    R8, R8
-    MOVB    $1, AL                // Assume valid
-
-    // ASCII range check pattern (0x00-0x7F)
-    MOVQ    $0x7F7F7F7F7F7F7F7F, BX
-    MOVQ    BX, X0
-
-sse_utf8_loop:
-    CMPQ    CX, $16
-    JL      sse_utf8_remainder
-    
-    MOVDQU  (SI)(R8*1), X1
-    
-    // Check if all bytes are <= 0x7F (ASCII)
-    PCMPGTB X1, X0                // 0x7F > data[i] for each byte
-    PMOVMSKB X0, DX
-    CMPW    DX, $0xFFFF           // All bits should be set for ASCII
-    JNE     sse_utf8_complex      // Has non-ASCII, need complex validation
-    
-sse_utf8_next_chunk:
-    ADDQ    $16, R8
-    SUBQ    $16, CX
-    JMP     sse_utf8_loop
-
-sse_utf8_complex:
-    // For non-ASCII bytes, we'd implement full UTF-8 validation
-    // For now, assume valid (simplified)
-    JMP     sse_utf8_next_chunk
-
-sse_utf8_remainder:
-    TESTQ   CX, CX
-    JZ      sse_utf8_valid
-
-sse_utf8_remainder_loop:
-    MOVB    (SI)(R8*1), BL
-    CMPB    BL, $0x7F
-    JLE     sse_utf8_ascii
-    
-    // Multi-byte UTF-8 would be validated here
-    // Simplified: assume valid
-
-sse_utf8_ascii:
-    INCQ    R8
-    DECQ    CX
-    JNZ     sse_utf8_remainder_loop
-
-sse_utf8_valid:
-    MOVB    AL, ret+16(FP)
-    RET
-
-// parseIntegerSSE42 parses integers with SSE4.2 digit detection
-TEXT ·parseIntegerSSE42(SB), NOSPLIT, $0-32
-    MOVQ    data+0(FP), SI
-    MOVQ    length+8(FP), CX
-    
-    XORQ    R8, R8                // Result
-    XORQ    R9, R9                // Position
-    MOVB    $1, AL                // Sign (positive)
-    MOVB    $1, BL                // Valid flag
-    
-    TESTQ   CX, CX
-    JZ      sse_parse_invalid
-    
-    // Check for minus sign
-    MOVB    (SI), DL
-    CMPB    DL, $'-'
-    JNE     sse_parse_digits
-    NEGB    AL                    // Negative
-    INCQ    SI
-    DECQ    CX
-
-sse_parse_digits:
-    TESTQ   CX, CX
-    JZ      sse_parse_invalid
-    
-    // Digit range for SIMD comparison
-    MOVQ    $0x3030303030303030, R10  // '0' repeated
-    MOVQ    R10, X0
-    MOVQ    $0x3939393939393939, R10  // '9' repeated  
-    MOVQ    R10, X1
-
-sse_parse_simd_loop:
-    CMPQ    CX, $16
-    JL      sse_parse_scalar
-    
-    MOVDQU  (SI)(R9*1), X2
-    
-    // Check if all are >= '0'
-    PCMPGTB X2, X0, X3            // data > '0'-1
-    // Check if all are <= '9'  
-    PCMPGTB X1, X2, X4            // '9' >= data
-    PAND    X3, X4                // Both conditions
-    PMOVMSKB X4, DX
-    
-    // If not all are digits, fall back to scalar
-    CMPW    DX, $0xFFFF
-    JNE     sse_parse_scalar
-    
-    // For simplicity, fall back to scalar for actual parsing
-    JMP     sse_parse_scalar
-
-sse_parse_scalar:
-    TESTQ   CX, CX
-    JZ      sse_parse_done
-
-sse_parse_scalar_loop:
-    MOVB    (SI)(R9*1), DL
-    SUBB    $'0', DL
-    CMPB    DL, $9
-    JA      sse_parse_done
-    
-    IMULQ   $10, R8
-    MOVZBQ  DL, R10
-    ADDQ    R10, R8
-    
-    INCQ    R9
-    DECQ    CX
-    JNZ     sse_parse_scalar_loop
-
-sse_parse_done:
-    TESTB   AL, AL
-    JGE     sse_parse_positive
-    NEGQ    R8
-
-sse_parse_positive:
-    MOVQ    R8, ret+16(FP)
-    MOVB    BL, ret+24(FP)
-    RET
-
-sse_parse_invalid:
-    XORQ    R8, R8
-    XORB    BL, BL
-    MOVQ    R8, ret+16(FP)
-    MOVB    BL, ret+24(FP)
-    RET
\ No newline at end of file
@@ -0,0 +1,93 @@
+//go:build amd64 && !noasm
+
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestAVX2Backend checks the mask-based scan against encoding/json on
+// valid documents and against the scalar kernel on everything else,
+// including the JSONTestSuite files shifted across the 64-byte blocks
+func TestAVX2Backend(t *testing.T) {
+	if !hasAVX2() {
+		t.Skip("AVX2 not available")
+	}
+	if bestLevel() != LevelAVX2 {
+		t.Fatalf("Expected best level %v, got %v", LevelAVX2, bestLevel())
+	}
+
+	s := New()
+	defer s.Release()
+
+	t.Run("reference", func(t *testing.T) {
+		for _, input := range referenceInputs() {
+			want, _ := referenceScan(t, []byte(input))
+			if err := s.ScanSIMD([]byte(input)); err != nil {
+				t.Fatalf("Input %q: %v", input, err)
+			}
+			if got := s.GetStructuralIndices(); !reflect.DeepEqual(want, got) {
+				t.Errorf("Input %q:\nexpected %v\ngot      %v", input, want, got)
+			}
+		}
+	})
+
+	t.Run("scalar", func(t *testing.T) {
+		inputs := []string{
+			``, `1`, `\`, `\"a"`, `[\"]`, `["a"\\"b"]`, `[1\2]`, "[\x01,\x1f2]", "\t\n\r [ ]",
+			`"unterminated`, `"ends with \`, `[1"a"]`, `["a"1]`, `[truefalse]`, "[\v]",
+		}
+		matches, err := filepath.Glob("../../testdata/JSONTestSuite/test_parsing/*.json")
+		if err != nil || len(matches) == 0 {
+			t.Fatalf("JSONTestSuite not found: %v", err)
+		}
+		for _, m := range matches {
+			data, err := os.ReadFile(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			inputs = append(inputs, string(data))
+		}
+
+		for _, input := range inputs {
+			for pad := 0; pad < 70; pad += 7 {
+				data := []byte(strings.Repeat(" ", pad) + input)
+				s.buf = data
+				s.structuralIndices = s.structuralIndices[:0]
+				s.scanScalar()
+				want := append([]uint32(nil), s.structuralIndices...)
+
+				if err := s.ScanSIMD(data); err != nil {
+					t.Fatalf("Input %q: %v", data, err)
+				}
+				if got := s.GetStructuralIndices(); !slices.Equal(want, got) {
+					t.Errorf("Input %q:\nscalar %v\navx2   %v", data, want, got)
+				}
+			}
+		}
+	})
+
+	t.Run("growth", func(t *testing.T) {
+		// Every byte is structural, so the 1/8 estimate is far too small
+		// and the buffer must grow batch by batch from nothing
+		data := []byte(strings.Repeat("[", 5000) + strings.Repeat("]", 5000))
+		s := &Scanner{}
+		s.initCharClassifier()
+		if err := s.ScanSIMD(data); err != nil {
+			t.Fatalf("ScanSIMD failed: %v", err)
+		}
+		if got := s.GetStructuralIndices(); len(got) != len(data) || got[len(got)-1] != uint32(len(data)-1) {
+			t.Fatalf("Expected %d indices ending at %d, got %d", len(data), len(data)-1, len(got))
+		}
+		for i, idx := range s.GetStructuralIndices() {
+			if idx != uint32(i) {
+				t.Fatalf("Index %d: expected %d, got %d", i, i, idx)
+			}
+		}
+	})
+}
@@ -12,11 +12,24 @@ func hasSIMD() bool {
 	return runtime.GOARCH == "arm64"
 }
 
+// bestLevel returns the fastest kernel family the CPU supports
+func bestLevel() Level {
+	return LevelNEON
+}
+
+// supportsLevel reports whether the CPU can run the kernels of level l
+func supportsLevel(l Level) bool {
+	return l == LevelNEON
+}
+
 // scanSIMD performs SIMD-accelerated JSON scanning
 func (s *Scanner) scanSIMD() error {
 	if len(s.buf) == 0 {
 		return nil
 	}
+	if ActiveLevel() != LevelNEON {
+		return s.scanScalar()
+	}
 
 	// Ensure we have capacity for indices
 	if cap(s.structuralIndices) < len(s.buf)/4 {
@@ -76,7 +89,7 @@ func (s *Scanner) SIMDParseInteger(data []byte) (int64, bool) {
 	}
 	
 	// Try NEON for integer parsing if data is large enough
-	if len(data) >= 4 {
+	if len(data) >= 4 && ActiveLevel() == LevelNEON {
 		result, valid := parseIntegerNEON(data)
 		if valid {
 			return result, true
@@ -87,51 +100,6 @@ func (s *Scanner) SIMDParseInteger(data []byte) (int64, bool) {
 	return s.parseIntegerScalar(data)
 }
 
-// parseIntegerScalar provides scalar integer parsing fallback
-func (s *Scanner) parseIntegerScalar(data []byte) (int64, bool) {
-	if len(data) == 0 {
-		return 0, false
-	}
-	
-	var result int64
-	var negative bool
-	start := 0
-	parsed := false
-	
-	// Check for negative sign
-	if data[0] == '-' {
-		negative = true
-		start = 1
-		if len(data) == 1 {
-			return 0, false
-		}
-	}
-	
-	// Parse digits
-	for i := start; i < len(data); i++ {
-		c := data[i]
-		if c < '0' || c > '9' {
-			break
-		}
-		
-		parsed = true
-		digit := int64(c - '0')
-		
-		// Check for overflow
-		if result > (9223372036854775807-digit)/10 {
-			return 0, false
-		}
-		
-		result = result*10 + digit
-	}
-	
-	if negative {
-		result = -result
-	}
-	
-	return result, parsed
-}
-
 // SIMDQuoteMask creates quote masks using NEON
 func (s *Scanner) SIMDQuoteMask(data []byte) ([]uint64, error) {
 	if len(data) == 0 {
@@ -143,7 +111,7 @@ func (s *Scanner) SIMDQuoteMask(data []byte) ([]uint64, error) {
 	masks := make([]uint64, maskCount)
 	
 	// Try NEON implementation
-	if len(data) >= 16 {
+	if len(data) >= 16 && ActiveLevel() == LevelNEON {
 		count := findQuoteMaskNEON(data, masks)
 		if count > 0 {
 			return masks[:count], nil
@@ -154,36 +122,6 @@ func (s *Scanner) SIMDQuoteMask(data []byte) ([]uint64, error) {
 	return s.generateQuoteMaskScalar(data)
 }
 
-// generateQuoteMaskScalar provides scalar quote mask generation
-func (s *Scanner) generateQuoteMaskScalar(data []byte) ([]uint64, error) {
-	maskCount := (len(data) + 63) / 64
-	masks := make([]uint64, maskCount)
-	
-	inString := false
-	escaped := false
-	
-	for i, b := range data {
-		if escaped {
-			escaped = false
-			continue
-		}
-		
-		if b == '\\' && inString {
-			escaped = true
-			continue
-		}
-		
-		if b == '"' {
-			maskIndex := i / 64
-			bitIndex := i % 64
-			masks[maskIndex] |= 1 << bitIndex
-			inString = !inString
-		}
-	}
-	
-	return masks, nil
-}
-
 // SIMDValidateUTF8 validates UTF-8 using NEON when possible
 func (s *Scanner) SIMDValidateUTF8(data []byte) bool {
 	if len(data) == 0 {
@@ -200,52 +138,5 @@ func (s *Scanner) SIMDValidateUTF8(data []byte) bool {
 	return s.validateUTF8Scalar(data)
 }
 
-// validateUTF8Scalar provides scalar UTF-8 validation
-func (s *Scanner) validateUTF8Scalar(data []byte) bool {
-	for i := 0; i < len(data); {
-		r, size := decodeRuneInString(string(data[i:]))
-		if r == '\uFFFD' && size == 1 {
-			return false
-		}
-		i += size
-	}
-	return true
-}
-
-// decodeRuneInString is a simplified version of utf8.DecodeRuneInString
-func decodeRuneInString(s string) (r rune, size int) {
-	if len(s) == 0 {
-		return '\uFFFD', 0
-	}
-	
-	c0 := s[0]
-	
-	// ASCII fast path
-	if c0 < 0x80 {
-		return rune(c0), 1
-	}
-	
-	// Multi-byte sequences - simplified validation
-	if c0 < 0xC0 {
-		return '\uFFFD', 1
-	}
-	
-	if c0 < 0xE0 {
-		if len(s) < 2 {
-			return '\uFFFD', 1
-		}
-		c1 := s[1]
-		if c1 < 0x80 || c1 >= 0xC0 {
-			return '\uFFFD', 1
-		}
-		r = rune(c0&0x1F)<<6 | rune(c1&0x3F)
-		if r < 0x80 {
-			return '\uFFFD', 1
-		}
-		return r, 2
-	}
-	
-	return '\uFFFD', 1
-}
 
 // ARM64-specific NEON utilities use the shared alignment functions
\ No newline at end of file
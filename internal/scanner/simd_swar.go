@@ -0,0 +1,50 @@
+//go:build wasm || ppc64le || s390x
+
+package scanner
+
+// Backend for wasm, ppc64le and s390x. None of them has vector assembly
+// kernels here: the Go compiler does not emit WebAssembly SIMD128
+// instructions, and VSX and the z/Architecture vector facility would need
+// hand-written assembly. All three use the SWAR kernels from swar.go,
+// which only need 64-bit integer operations and are byte-order
+// independent, so the same code serves little-endian POWER and big-endian
+// IBM Z.
+
+// hasSIMD reports true: the SWAR kernels are the accelerated path here
+func hasSIMD() bool {
+	return true
+}
+
+// bestLevel returns LevelSWAR
+func bestLevel() Level {
+	return LevelSWAR
+}
+
+// supportsLevel reports whether l is LevelSWAR
+func supportsLevel(l Level) bool {
+	return l == LevelSWAR
+}
+
+// scanSIMD scans with the SWAR kernel
+func (s *Scanner) scanSIMD() error {
+	if ActiveLevel() != LevelSWAR {
+		return s.scanScalar()
+	}
+	return s.scanSWAR()
+}
+
+// SIMDParseInteger uses scalar parsing
+func (s *Scanner) SIMDParseInteger(data []byte) (int64, bool) {
+	return s.parseIntegerScalar(data)
+}
+
+// SIMDQuoteMask uses scalar mask generation
+func (s *Scanner) SIMDQuoteMask(data []byte) ([]uint64, error) {
+	return s.generateQuoteMaskScalar(data)
+}
+
+// SIMDValidateUTF8 uses unicode/utf8, which already checks eight ASCII
+// bytes per step
+func (s *Scanner) SIMDValidateUTF8(data []byte) bool {
+	return s.validateUTF8Scalar(data)
+}
@@ -0,0 +1,75 @@
+//go:build wasm || ppc64le || s390x
+
+package scanner
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestSWARBackend checks the backend's kernels against encoding/json,
+// strconv and known UTF-8 verdicts rather than against the scalar loops
+// they share code with
+func TestSWARBackend(t *testing.T) {
+	if bestLevel() != LevelSWAR {
+		t.Fatalf("Expected best level %v, got %v", LevelSWAR, bestLevel())
+	}
+
+	s := New()
+	defer s.Release()
+
+	t.Run("structural", func(t *testing.T) {
+		for _, input := range referenceInputs() {
+			want, quotes := referenceScan(t, []byte(input))
+			if err := s.ScanSIMD([]byte(input)); err != nil {
+				t.Fatalf("Input %q: %v", input, err)
+			}
+			if got := s.GetStructuralIndices(); !reflect.DeepEqual(want, got) {
+				t.Errorf("Input %q:\nexpected %v\ngot      %v", input, want, got)
+			}
+			if got, err := s.SIMDQuoteMask([]byte(input)); err != nil || !reflect.DeepEqual(quotes, got) {
+				t.Errorf("Input %q: expected quote mask %x, got %x (%v)", input, quotes, got, err)
+			}
+		}
+	})
+
+	t.Run("integer", func(t *testing.T) {
+		inputs := []string{"0", "-0", "-42", "9223372036854775807", "-9223372036854775807", "-9223372036854775808", "-9223372036854775809", "9223372036854775808", "-", ""}
+		for n := 1; n <= 19; n++ {
+			inputs = append(inputs, strings.Repeat("9", n), "-"+strings.Repeat("1", n))
+		}
+		for _, input := range inputs {
+			want, err := strconv.ParseInt(input, 10, 64)
+			got, ok := s.SIMDParseInteger([]byte(input))
+			if ok != (err == nil) || ok && got != want {
+				t.Errorf("ParseInteger(%q): expected %d/%v, got %d/%v", input, want, err == nil, got, ok)
+			}
+		}
+	})
+
+	t.Run("utf8", func(t *testing.T) {
+		tests := []struct {
+			char  string
+			valid bool
+		}{
+			{"é", true},
+			{"世", true},
+			{"🎉", true},
+			{"\xff", false},
+			{"\xc0\xaf", false},     // overlong '/'
+			{"\xed\xa0\x80", false}, // surrogate
+			{"\xe4\xb8", false},     // truncated
+			{"\xf4\x90\x80\x80", false},
+		}
+		for n := 0; n < 70; n++ {
+			for _, tt := range tests {
+				input := strings.Repeat("a", n) + tt.char + "b"
+				if got := s.SIMDValidateUTF8([]byte(input)); got != tt.valid {
+					t.Errorf("ValidateUTF8(%q): expected %v, got %v", input, tt.valid, got)
+				}
+			}
+		}
+	})
+}
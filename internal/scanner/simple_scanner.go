@@ -2,30 +2,76 @@ package scanner
 
 import (
 	"errors"
+	"math"
+	"unicode/utf8"
 )
 
+// MaxDepth is the deepest nesting SimpleTokenize accepts. The decoders
+// recurse once per level, so the limit keeps hostile input from exhausting
+// the goroutine stack; encoding/json uses the same limit.
+const MaxDepth = 10000
+
+// CancelInterval is how many bytes SimpleTokenize scans between calls to
+// Scanner.Cancel.
+const CancelInterval = 64 << 10
+
 // SimpleTokenize tokenizes JSON without complex structural scanning
 func (s *Scanner) SimpleTokenize(data []byte) ([]Token, error) {
 	s.buf = data
 	if len(data) == 0 {
 		return nil, errors.New("empty input")
 	}
+	lim := &s.Limits
+	if lim.MaxBytes > 0 && len(data) > lim.MaxBytes {
+		return nil, &LimitError{Limit: "MaxBytes", Max: lim.MaxBytes, Offset: lim.MaxBytes}
+	}
+	maxDepth := orMax(lim.MaxDepth, MaxDepth)
+	maxTokens := orMax(lim.MaxTokens, math.MaxInt)
+	maxString := orMax(lim.MaxStringLen, math.MaxInt)
+	var keys []int // members so far of each open container
+	if lim.MaxObjectKeys > 0 {
+		keys = make([]int, 0, 16)
+	}
 	
 	tokens := getTokenSlice()
 	if cap(tokens) < len(data)/4 {
 		tokens = make([]Token, 0, len(data)/4)
 	}
 	i := 0
+	depth := 0
+	open := s.open[:0] // token indices of the unclosed brackets
+	defer func() { s.open = open[:0] }()
+	nextCheck := len(data)
+	if s.Cancel != nil || s.Progress != nil {
+		nextCheck = CancelInterval
+	}
 	
 	for i < len(data) {
-		// Skip whitespace
+		// Skip whitespace. The indentation following a newline is a run
+		// of spaces or tabs, skipped a word at a time
 		for i < len(data) && isWhitespace(data[i]) {
 			i++
+			if data[i-1] == '\n' && i < len(data) {
+				if c := data[i]; c == ' ' || c == '\t' {
+					i = swarSkipRun(data, i, c)
+				}
+			}
 		}
 		
 		if i >= len(data) {
 			break
 		}
+		if i >= nextCheck {
+			if s.Cancel != nil {
+				if err := s.Cancel(); err != nil {
+					return nil, err
+				}
+			}
+			if s.Progress != nil {
+				s.Progress(i)
+			}
+			nextCheck = i + CancelInterval
+		}
 		
 		c := data[i]
 		token := Token{Start: uint32(i)}
@@ -55,22 +101,52 @@ func (s *Scanner) SimpleTokenize(data []byte) ([]Token, error) {
 		
 		switch c {
 		case '{':
+			if depth++; depth > maxDepth {
+				return nil, s.depthError(i)
+			}
+			if keys != nil && depth > 0 {
+				keys = append(keys[:depth-1], 0)
+			}
+			open = append(open, uint32(len(tokens)))
 			token.Type = TokenObjectBegin
 			token.End = uint32(i + 1)
 			i++
 		case '}':
+			depth--
 			token.Type = TokenObjectEnd
+			if len(open) > 0 {
+				tokens[open[len(open)-1]].Link = uint32(len(tokens))
+				open = open[:len(open)-1]
+			}
 			token.End = uint32(i + 1)
 			i++
 		case '[':
+			if depth++; depth > maxDepth {
+				return nil, s.depthError(i)
+			}
+			if keys != nil && depth > 0 {
+				keys = append(keys[:depth-1], 0)
+			}
+			open = append(open, uint32(len(tokens)))
 			token.Type = TokenArrayBegin
 			token.End = uint32(i + 1)
 			i++
 		case ']':
+			depth--
 			token.Type = TokenArrayEnd
+			if len(open) > 0 {
+				tokens[open[len(open)-1]].Link = uint32(len(tokens))
+				open = open[:len(open)-1]
+			}
 			token.End = uint32(i + 1)
 			i++
 		case ':':
+			if keys != nil && depth > 0 && depth <= len(keys) && len(tokens) > 0 {
+				if keys[depth-1]++; keys[depth-1] > lim.MaxObjectKeys {
+					key := int(tokens[len(tokens)-1].Start)
+					return nil, &LimitError{Limit: "MaxObjectKeys", Max: lim.MaxObjectKeys, Offset: key}
+				}
+			}
 			token.Type = TokenColon
 			token.End = uint32(i + 1)
 			i++
@@ -79,25 +155,42 @@ func (s *Scanner) SimpleTokenize(data []byte) ([]Token, error) {
 			token.End = uint32(i + 1)
 			i++
 		case '"':
-			// Parse string
+			// Parse string. Escapes are checked when the string is
+			// unescaped; raw control characters and invalid UTF-8 are
+			// rejected here, as the validator does
 			i++ // Skip opening quote
-			for i < len(data) {
-				if data[i] == '"' {
-					// Check if escaped
-					escaped := false
-					backslashes := 0
-					for j := i - 1; j >= 0 && data[j] == '\\'; j-- {
-						backslashes++
-					}
-					escaped = backslashes%2 == 1
-					
-					if !escaped {
-						i++ // Skip closing quote
-						break
-					}
+			ascii := true
+			for {
+				if i >= len(data) {
+					return nil, errors.New("unterminated string")
+				}
+				c := data[i]
+				if c == '"' {
+					i++ // Skip closing quote
+					break
+				}
+				if c == '\\' {
+					token.Flags |= FlagEscape
+					i += 2
+					continue
+				}
+				if c < 0x20 {
+					return nil, errors.New("invalid control character in string")
+				}
+				if c >= utf8.RuneSelf {
+					ascii = false
 				}
 				i++
 			}
+			if !ascii {
+				if !utf8.Valid(data[token.Start+1 : i-1]) {
+					return nil, errors.New("invalid UTF-8 in string")
+				}
+				token.Flags |= FlagNonASCII
+			}
+			if i-int(token.Start)-2 > maxString {
+				return nil, &LimitError{Limit: "MaxStringLen", Max: maxString, Offset: int(token.Start)}
+			}
 			token.Type = TokenString
 			token.End = uint32(i)
 		case 't':
@@ -128,10 +221,14 @@ func (s *Scanner) SimpleTokenize(data []byte) ([]Token, error) {
 				return nil, errors.New("invalid token starting with 'n'")
 			}
 		default:
-			if c == '-' || (c >= '0' && c <= '9') {
+			if nf, ok := s.nonFinite(data, i); ok {
+				token = nf
+				i = int(nf.End)
+			} else if c == '-' || (c >= '0' && c <= '9') {
 				// Parse number
 				numStart := i
 				if c == '-' {
+					token.Flags |= FlagNegative
 					i++
 					if i >= len(data) || !(data[i] >= '0' && data[i] <= '9') {
 						return nil, errors.New("invalid number: missing digits after minus")
@@ -158,6 +255,7 @@ func (s *Scanner) SimpleTokenize(data []byte) ([]Token, error) {
 				
 				// Parse decimal part
 				if i < len(data) && data[i] == '.' {
+					token.Flags |= FlagFraction
 					i++
 					if i >= len(data) || !(data[i] >= '0' && data[i] <= '9') {
 						return nil, errors.New("invalid number: no digits after decimal")
@@ -169,6 +267,7 @@ func (s *Scanner) SimpleTokenize(data []byte) ([]Token, error) {
 				
 				// Parse exponent
 				if i < len(data) && (data[i] == 'e' || data[i] == 'E') {
+					token.Flags |= FlagExponent
 					i++
 					if i < len(data) && (data[i] == '+' || data[i] == '-') {
 						i++
@@ -193,12 +292,42 @@ func (s *Scanner) SimpleTokenize(data []byte) ([]Token, error) {
 			}
 		}
 		
+		if len(tokens) >= maxTokens {
+			return nil, &LimitError{Limit: "MaxTokens", Max: maxTokens, Offset: int(token.Start)}
+		}
 		tokens = append(tokens, token)
 	}
 	
 	return tokens, nil
 }
 
+// depthError reports nesting deeper than Limits.MaxDepth, or than MaxDepth
+// if that is lower or not set, at offset i.
+func (s *Scanner) depthError(i int) error {
+	if max := s.Limits.MaxDepth; max > 0 && max <= MaxDepth {
+		return &LimitError{Limit: "MaxDepth", Max: max, Offset: i}
+	}
+	return errors.New("exceeded max nesting depth")
+}
+
+// nonFinite returns the token of the NaN, Infinity or -Infinity at
+// data[i], if NonFinite is set and there is one.
+func (s *Scanner) nonFinite(data []byte, i int) (Token, bool) {
+	if !s.NonFinite {
+		return Token{}, false
+	}
+	for _, lit := range [...]string{"NaN", "Infinity", "-Infinity"} {
+		if len(data)-i >= len(lit) && string(data[i:i+len(lit)]) == lit {
+			token := Token{Type: TokenNumber, Flags: FlagFraction, Start: uint32(i), End: uint32(i + len(lit))}
+			if lit[0] == '-' {
+				token.Flags |= FlagNegative
+			}
+			return token, true
+		}
+	}
+	return Token{}, false
+}
+
 func isWhitespace(c byte) bool {
 	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
 }
\ No newline at end of file
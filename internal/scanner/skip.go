@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	errSkipOutOfRange = errors.New("skip position out of range")
+	errSkipUnbalanced = errors.New("unbalanced brackets")
+)
+
+// SkipValue advances past the value whose first structural index is at
+// position pos of GetStructuralIndices and returns the position of the
+// structural character that follows it. Objects and arrays are skipped by
+// matching brackets in the index, so their contents are never tokenized.
+// The first call after a scan links every bracket to its match in one
+// pass, as simdjson's tape does, and skips take constant time from then
+// on. Scan must have been called first.
+func (s *Scanner) SkipValue(pos int) (int, error) {
+	indices := s.structuralIndices
+	if pos < 0 || pos >= len(indices) {
+		return pos, errSkipOutOfRange
+	}
+
+	switch s.buf[indices[pos]] {
+	case '{', '[':
+		if len(s.links) != len(indices) {
+			s.linkBrackets()
+		}
+		if end := int(s.links[pos]); end > pos {
+			return end + 1, nil
+		}
+		return len(indices), errSkipUnbalanced
+	case '"':
+		// The closing quote is the next structural index
+		if pos+1 >= len(indices) {
+			return len(indices), errors.New("unterminated string")
+		}
+		return pos + 2, nil
+	case '}', ']', ',', ':':
+		return pos, errors.New("expected value")
+	default:
+		return pos + 1, nil
+	}
+}
+
+// linkBrackets sets links[i], for each opening bracket at position i of
+// the structural indices, to the position of its closing bracket, or 0 if
+// it has none.
+func (s *Scanner) linkBrackets() {
+	indices := s.structuralIndices
+	s.links = slices.Grow(s.links[:0], len(indices))[:len(indices)]
+	open := s.open[:0]
+	for i, idx := range indices {
+		switch s.buf[idx] {
+		case '{', '[':
+			open = append(open, uint32(i))
+			s.links[i] = 0
+		case '}', ']':
+			if len(open) > 0 {
+				s.links[open[len(open)-1]] = uint32(i)
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	s.open = open[:0]
+}
+
+// SkipTokens is the token-level counterpart of SkipValue: it returns the
+// index of the first token after the value starting at tokens[pos]. Objects
+// and arrays are skipped in constant time by following Token.Link, so the
+// tokens must be a whole slice returned by SimpleTokenize.
+func SkipTokens(tokens []Token, pos int) (int, error) {
+	if pos < 0 || pos >= len(tokens) {
+		return pos, errSkipOutOfRange
+	}
+
+	switch tokens[pos].Type {
+	case TokenObjectBegin, TokenArrayBegin:
+		if end := int(tokens[pos].Link); end > pos && end < len(tokens) {
+			return end + 1, nil
+		}
+		return len(tokens), errSkipUnbalanced
+	case TokenObjectEnd, TokenArrayEnd, TokenColon, TokenComma, TokenNone:
+		return pos, errors.New("expected value")
+	default:
+		return pos + 1, nil
+	}
+}
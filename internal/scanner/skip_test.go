@@ -0,0 +1,142 @@
+package scanner
+
+import (
+	"testing"
+)
+
+func TestScanner_SkipValue(t *testing.T) {
+	data := []byte(`{"a":{"b":[1,{"c":"}]"}],"d":"x"},"e":[[],[true]],"f":null}`)
+
+	s := New()
+	defer s.Release()
+	if err := s.Scan(data); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	indices := s.GetStructuralIndices()
+
+	// Locate the structural position of each top-level value
+	valueAt := func(key string) int {
+		for i, idx := range indices {
+			if data[idx] == '"' && string(data[idx+1:idx+1+uint32(len(key))]) == key && data[idx+1+uint32(len(key))] == '"' {
+				// skip key quotes and the colon
+				return i + 3
+			}
+		}
+		t.Fatalf("Key %q not found", key)
+		return -1
+	}
+
+	tests := []struct {
+		key  string
+		next byte
+	}{
+		{"a", ','},
+		{"e", ','},
+		{"f", '}'},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			next, err := s.SkipValue(valueAt(tt.key))
+			if err != nil {
+				t.Fatalf("SkipValue failed: %v", err)
+			}
+			if got := data[indices[next]]; got != tt.next {
+				t.Errorf("Expected to land on %q, got %q at %d", tt.next, got, indices[next])
+			}
+		})
+	}
+
+	// Skipping the whole document consumes every index
+	if next, err := s.SkipValue(0); err != nil || next != len(indices) {
+		t.Errorf("Expected %d, got %d (%v)", len(indices), next, err)
+	}
+}
+
+func TestScanner_SkipValueErrors(t *testing.T) {
+	s := New()
+	defer s.Release()
+
+	s.Scan([]byte(`[1,[2,3]`))
+	if _, err := s.SkipValue(0); err == nil {
+		t.Error("Expected error for unbalanced array")
+	}
+	if _, err := s.SkipValue(100); err == nil {
+		t.Error("Expected error for out of range position")
+	}
+}
+
+func TestSkipTokens(t *testing.T) {
+	s := New()
+	defer s.Release()
+
+	tokens, err := s.SimpleTokenize([]byte(`[{"a":[1,2]},"x",3]`))
+	if err != nil {
+		t.Fatalf("SimpleTokenize failed: %v", err)
+	}
+
+	// tokens[1] is the object; it ends at tokens[9]
+	next, err := SkipTokens(tokens, 1)
+	if err != nil {
+		t.Fatalf("SkipTokens failed: %v", err)
+	}
+	if next != 10 || tokens[next].Type != TokenComma {
+		t.Errorf("Expected comma at 10, got %d", next)
+	}
+
+	if next, _ := SkipTokens(tokens, 11); next != 12 {
+		t.Errorf("Expected scalar skip to 12, got %d", next)
+	}
+}
+
+func TestBracketLinks(t *testing.T) {
+	data := []byte(`[{"a":[1,2]},{},[[]],"x"]`)
+	s := New()
+	defer s.Release()
+
+	tokens, err := s.SimpleTokenize(data)
+	if err != nil {
+		t.Fatalf("SimpleTokenize failed: %v", err)
+	}
+	for i, tok := range tokens {
+		if tok.Type != TokenObjectBegin && tok.Type != TokenArrayBegin {
+			if tok.Link != 0 {
+				t.Errorf("Expected no link on token %d, got %d", i, tok.Link)
+			}
+			continue
+		}
+		// The link must be the closer a depth count finds
+		depth, end := 0, -1
+		for j := i; end < 0; j++ {
+			switch tokens[j].Type {
+			case TokenObjectBegin, TokenArrayBegin:
+				depth++
+			case TokenObjectEnd, TokenArrayEnd:
+				if depth--; depth == 0 {
+					end = j
+				}
+			}
+		}
+		if int(tok.Link) != end {
+			t.Errorf("Expected token %d to link to %d, got %d", i, end, tok.Link)
+		}
+	}
+
+	if err := s.Scan(data); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	// Links are rebuilt for each scan
+	if next, err := s.SkipValue(0); err != nil || next != len(s.GetStructuralIndices()) {
+		t.Errorf("Expected to skip the document, got %d, %v", next, err)
+	}
+	if err := s.Scan([]byte(`[[1],2]`)); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if next, err := s.SkipValue(1); err != nil || next != 4 {
+		t.Errorf("Expected 4 after the second scan, got %d, %v", next, err)
+	}
+
+	if _, err := SkipTokens([]Token{{Type: TokenArrayBegin}, {Type: TokenNumber}}, 0); err == nil {
+		t.Error("Expected an error for an unclosed array")
+	}
+}
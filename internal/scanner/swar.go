@@ -0,0 +1,204 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// SWAR ("SIMD within a register") kernels process eight bytes per step in
+// an ordinary 64-bit register. They accelerate targets whose Go compiler
+// exposes no vector instructions, such as WebAssembly.
+
+const (
+	swarLo = 0x0101010101010101
+	swarHi = 0x8080808080808080
+)
+
+// swarMatch sets the high bit of each byte lane of w that equals b. Lanes
+// above the lowest match may be false positives, so only the position of
+// the lowest set bit is meaningful.
+func swarMatch(w uint64, b byte) uint64 {
+	x := w ^ (swarLo * uint64(b))
+	return (x - swarLo) &^ x & swarHi
+}
+
+// swarStringEnd returns the offset of the first quote or backslash in
+// buf[i:], scanning eight bytes per step, or len(buf) if there is none.
+func swarStringEnd(buf []byte, i int) int {
+	for ; i+8 <= len(buf); i += 8 {
+		w := binary.LittleEndian.Uint64(buf[i:])
+		if m := swarMatch(w, '"') | swarMatch(w, '\\'); m != 0 {
+			return i + bits.TrailingZeros64(m)>>3
+		}
+	}
+	for ; i < len(buf); i++ {
+		if buf[i] == '"' || buf[i] == '\\' {
+			return i
+		}
+	}
+	return i
+}
+
+// swarSkipDigits returns the offset of the first byte in buf[i:] that is
+// not an ASCII digit, eight bytes per step, or len(buf) if there is none.
+// A lane is a digit if its low seven bits are at least '0' and below
+// '9'+1 and its high bit is clear; masking the high bits off before the
+// add and setting them before the subtraction keeps lanes from carrying
+// into each other.
+func swarSkipDigits(buf []byte, i int) int {
+	for ; i+8 <= len(buf); i += 8 {
+		w := binary.LittleEndian.Uint64(buf[i:])
+		above := w&^swarHi + swarLo*(0x80-'9'-1)
+		below := ^(w | swarHi - swarLo*'0')
+		if m := (above | below | w) & swarHi; m != 0 {
+			return i + bits.TrailingZeros64(m)/8
+		}
+	}
+	for i < len(buf) && isDigit(buf[i]) {
+		i++
+	}
+	return i
+}
+
+// swarStringPlain returns the offset of the first byte of buf[i:] that a
+// string validator must look at, a quote, backslash, control character or
+// non-ASCII byte, scanning eight bytes per step, or len(buf) if there is
+// none.
+func swarStringPlain(buf []byte, i int) int {
+	for ; i+8 <= len(buf); i += 8 {
+		w := binary.LittleEndian.Uint64(buf[i:])
+		m := ((w - swarLo*0x20) | w) & swarHi
+		m |= swarMatch(w, '"') | swarMatch(w, '\\')
+		if m != 0 {
+			return i + bits.TrailingZeros64(m)>>3
+		}
+	}
+	for ; i < len(buf); i++ {
+		if c := buf[i]; c < 0x20 || c >= 0x80 || c == '"' || c == '\\' {
+			return i
+		}
+	}
+	return i
+}
+
+// swarSkipRun returns the offset of the first byte of buf[i:] that is not
+// c, scanning eight bytes per step, or len(buf) if there is none. The
+// lowest set bit of a word xor'ed with c repeated marks the first other
+// byte. It jumps over the indentation of pretty-printed documents.
+func swarSkipRun(buf []byte, i int, c byte) int {
+	for ; i+8 <= len(buf); i += 8 {
+		if x := binary.LittleEndian.Uint64(buf[i:]) ^ (swarLo * uint64(c)); x != 0 {
+			return i + bits.TrailingZeros64(x)>>3
+		}
+	}
+	for i < len(buf) && buf[i] == c {
+		i++
+	}
+	return i
+}
+
+// IndexDelimiter returns the offset of the first comma, quote or newline
+// in buf, scanning eight bytes per step, or len(buf) if there is none. It
+// finds the end of an unquoted CSV field; comma is the field separator.
+func IndexDelimiter(buf []byte, comma byte) int {
+	i := 0
+	for ; i+8 <= len(buf); i += 8 {
+		w := binary.LittleEndian.Uint64(buf[i:])
+		if m := swarMatch(w, comma) | swarMatch(w, '"') | swarMatch(w, '\n'); m != 0 {
+			return i + bits.TrailingZeros64(m)>>3
+		}
+	}
+	for ; i < len(buf); i++ {
+		if c := buf[i]; c == comma || c == '"' || c == '\n' {
+			return i
+		}
+	}
+	return i
+}
+
+// IndexEscape returns the offset of the first byte of s that a JSON string
+// encoder cannot copy as is: a control character, quote, backslash or
+// non-ASCII byte, and with html also '<', '>' and '&'. It scans eight bytes
+// per step and returns len(s) if there is none.
+func IndexEscape(s string, html bool) int {
+	i := 0
+	for ; i+8 <= len(s); i += 8 {
+		w := uint64(s[i]) | uint64(s[i+1])<<8 | uint64(s[i+2])<<16 | uint64(s[i+3])<<24 |
+			uint64(s[i+4])<<32 | uint64(s[i+5])<<40 | uint64(s[i+6])<<48 | uint64(s[i+7])<<56
+		// Lanes below 0x20 borrow into their high bit, lanes from 0x80
+		// have it set already
+		m := ((w - swarLo*0x20) | w) & swarHi
+		m |= swarMatch(w, '"') | swarMatch(w, '\\')
+		if html {
+			m |= swarMatch(w, '<') | swarMatch(w, '>') | swarMatch(w, '&')
+		}
+		if m != 0 {
+			return i + bits.TrailingZeros64(m)>>3
+		}
+	}
+	for ; i < len(s); i++ {
+		switch c := s[i]; {
+		case c < 0x20 || c >= 0x80 || c == '"' || c == '\\':
+			return i
+		case html && (c == '<' || c == '>' || c == '&'):
+			return i
+		}
+	}
+	return i
+}
+
+// scanSWAR produces the same structural indices as scanScalar, but jumps
+// over string contents a word at a time instead of byte by byte.
+func (s *Scanner) scanSWAR() error {
+	inString := false
+	escaped := false
+	boundary := true // the previous byte was structural or whitespace
+
+	for i := 0; i < len(s.buf); i++ {
+		if inString && !escaped {
+			if i = swarStringEnd(s.buf, i); i >= len(s.buf) {
+				break
+			}
+		}
+
+		c := s.buf[i]
+
+		if escaped {
+			escaped = false
+			continue
+		}
+
+		if c == '\\' && inString {
+			escaped = true
+			continue
+		}
+
+		if c == '"' {
+			inString = !inString
+			boundary = true
+			s.structuralIndices = append(s.structuralIndices, uint32(i))
+			continue
+		}
+
+		if !inString {
+			switch class := s.charClassifier[c]; {
+			case class == StructuralWhitespace:
+				boundary = true
+			case class != 0:
+				s.structuralIndices = append(s.structuralIndices, uint32(i))
+				boundary = true
+			default:
+				// Pseudo-structural, as in the simdjson paper: any other
+				// byte following a structural character or whitespace
+				// starts a scalar, valid or not, and the rest of the
+				// scalar is not indexed
+				if boundary {
+					s.structuralIndices = append(s.structuralIndices, uint32(i))
+				}
+				boundary = false
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,284 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSWARMatch(t *testing.T) {
+	word := []byte(`ab"cd\ef`)
+	var w uint64
+	for i := 7; i >= 0; i-- {
+		w = w<<8 | uint64(word[i])
+	}
+	if m := swarMatch(w, '"'); m == 0 || m&0xff != 0 || m&0x8000 != 0 || m&0x800000 == 0 {
+		t.Errorf("Quote match mask wrong: %#x", m)
+	}
+	if m := swarMatch(w, 'z'); m != 0 {
+		t.Errorf("Expected no match, got %#x", m)
+	}
+}
+
+func TestSWARStringEnd(t *testing.T) {
+	for n := 0; n < 40; n++ {
+		buf := []byte(strings.Repeat("x", n) + `"`)
+		if got := swarStringEnd(buf, 0); got != n {
+			t.Errorf("Length %d: expected %d, got %d", n, n, got)
+		}
+		buf[n] = '\\'
+		if got := swarStringEnd(buf, 0); got != n {
+			t.Errorf("Length %d backslash: expected %d, got %d", n, n, got)
+		}
+	}
+	if got := swarStringEnd([]byte("abcdefghijk"), 0); got != 11 {
+		t.Errorf("Expected 11 for no match, got %d", got)
+	}
+}
+
+func TestSWARSkipDigits(t *testing.T) {
+	for n := 0; n < 40; n++ {
+		for c := 0; c < 256; c++ {
+			if isDigit(byte(c)) {
+				continue
+			}
+			buf := []byte("-" + strings.Repeat("0123456789", 4)[:n] + string(rune(0)) + "7")
+			buf[n+1] = byte(c)
+			if got := swarSkipDigits(buf, 1); got != n+1 {
+				t.Errorf("Length %d before %#x: expected %d, got %d", n, c, n+1, got)
+			}
+		}
+	}
+	if got := swarSkipDigits([]byte("x12345678901"), 1); got != 12 {
+		t.Errorf("Expected 12 for no match, got %d", got)
+	}
+}
+
+func TestSWARStringPlain(t *testing.T) {
+	for n := 0; n < 40; n++ {
+		for _, c := range []string{"\x00", "\x1f", "\"", "\\", "\x80", "é"} {
+			buf := []byte("\x1f" + strings.Repeat(" ~", n)[:n] + c + "\x01")
+			if got := swarStringPlain(buf, 1); got != n+1 {
+				t.Errorf("Length %d %q: expected %d, got %d", n, c, n+1, got)
+			}
+		}
+	}
+	if got := swarStringPlain([]byte("abcdefghijk"), 2); got != 11 {
+		t.Errorf("Expected 11 for no match, got %d", got)
+	}
+}
+
+func TestSWARSkipRun(t *testing.T) {
+	for n := 0; n < 40; n++ {
+		for _, c := range []byte{' ', '\t'} {
+			for _, end := range []string{"}", "\n", " \t", "\v", "\x00"} {
+				if end[0] == c {
+					continue
+				}
+				buf := []byte("\n" + strings.Repeat(string(c), n) + end)
+				if got := swarSkipRun(buf, 1, c); got != n+1 {
+					t.Errorf("Length %d %q before %q: expected %d, got %d", n, c, end, n+1, got)
+				}
+			}
+		}
+	}
+	if got := swarSkipRun([]byte("           "), 2, ' '); got != 11 {
+		t.Errorf("Expected 11 for no match, got %d", got)
+	}
+}
+
+func TestIndexDelimiter(t *testing.T) {
+	for n := 0; n < 40; n++ {
+		for _, c := range []byte{';', '"', '\n'} {
+			buf := []byte(strings.Repeat("x", n) + string(c) + ";")
+			if got := IndexDelimiter(buf, ';'); got != n {
+				t.Errorf("Length %d %q: expected %d, got %d", n, c, n, got)
+			}
+		}
+	}
+	if got := IndexDelimiter([]byte("a,b;c"), ';'); got != 3 {
+		t.Errorf("Expected comma to be ignored, got %d", got)
+	}
+	if got := IndexDelimiter([]byte("abcdefghijk"), ','); got != 11 {
+		t.Errorf("Expected 11 for no match, got %d", got)
+	}
+}
+
+func TestIndexEscape(t *testing.T) {
+	for n := 0; n < 20; n++ {
+		for _, c := range []string{"\x00", "\x1f", "\"", "\\", "\x80", "é", "<", ">", "&"} {
+			s := strings.Repeat(" ~", n)[:n] + c + "\x01"
+			if got := IndexEscape(s, true); got != n {
+				t.Errorf("Length %d %q: expected %d, got %d", n, c, n, got)
+			}
+			html := c == "<" || c == ">" || c == "&"
+			if got := IndexEscape(s, false); html && got != n+1 || !html && got != n {
+				t.Errorf("Length %d %q without html: got %d", n, c, got)
+			}
+		}
+	}
+	if got := IndexEscape("plain text without escapes", true); got != 26 {
+		t.Errorf("Expected 26 for no match, got %d", got)
+	}
+}
+
+func TestPseudoStructurals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []uint32
+	}{
+		{`[1,-2,true]`, []uint32{0, 1, 2, 3, 5, 6, 10}},
+		{`[12345,false]`, []uint32{0, 1, 6, 7, 12}},
+		{"1 2\t3\n4", []uint32{0, 2, 4, 6}},
+		{`"a"1`, []uint32{0, 2, 3}},
+		{`{"a":nul}`, []uint32{0, 1, 3, 4, 5, 8}},
+		// Bytes that cannot start a value are still indexed, so the
+		// parser reports them
+		{`[+1,.5,x]`, []uint32{0, 1, 3, 4, 6, 7, 8}},
+		{`[tru e]`, []uint32{0, 1, 5, 6}},
+		{`["t",f]`, []uint32{0, 1, 3, 4, 5, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			s := New()
+			defer s.Release()
+			s.buf = []byte(tt.input)
+			for name, scan := range map[string]func() error{"scalar": s.scanScalar, "swar": s.scanSWAR} {
+				s.structuralIndices = s.structuralIndices[:0]
+				scan()
+				if !reflect.DeepEqual(s.structuralIndices, tt.expected) {
+					t.Errorf("Expected %s indices %v, got %v", name, tt.expected, s.structuralIndices)
+				}
+			}
+
+			var fed []uint32
+			for i := range tt.input {
+				s.Feed([]byte(tt.input[i : i+1]))
+				for _, idx := range s.GetStructuralIndices() {
+					fed = append(fed, uint32(s.Offset())+idx)
+				}
+			}
+			s.Finish()
+			if !reflect.DeepEqual(fed, tt.expected) {
+				t.Errorf("Expected fed indices %v, got %v", tt.expected, fed)
+			}
+		})
+	}
+}
+
+func TestScanSWARMatchesScalar(t *testing.T) {
+	inputs := []string{
+		`{"key":"value"}`,
+		`[1,2,3,true,false,null,-4.5e6]`,
+		`{"a":"with \"escaped\" quotes and \\ backslashes","b":[{"c":"}]"}]}`,
+		`"` + strings.Repeat("long string without structure ", 20) + `"`,
+		`{"esc":"\\\\\\\"","x":"\u00e9 世界"}`,
+		" { \"k\" : [ 1 , 2 ] } ",
+		`["unterminated`,
+	}
+	for i := 0; i < 32; i++ {
+		inputs = append(inputs, `["`+strings.Repeat("a", i)+`\"`+strings.Repeat("b", i)+`",1]`)
+	}
+
+	for _, input := range inputs {
+		s := New()
+		s.buf = []byte(input)
+		s.structuralIndices = s.structuralIndices[:0]
+		s.scanScalar()
+		scalar := append([]uint32(nil), s.structuralIndices...)
+
+		s.structuralIndices = s.structuralIndices[:0]
+		s.scanSWAR()
+		swar := append([]uint32(nil), s.structuralIndices...)
+		s.Release()
+
+		if !reflect.DeepEqual(scalar, swar) {
+			t.Errorf("Input %q:\nscalar %v\nswar   %v", input, scalar, swar)
+		}
+	}
+}
+
+// referenceInputs returns valid documents whose strings, escapes and
+// separators fall on every offset around the 8-byte SWAR words and the
+// 64-byte mask blocks
+func referenceInputs() []string {
+	inputs := []string{
+		`{"key":"value"}`,
+		`[1,-2.5e3,true,false,null]`,
+		" { \"k\" : [ 1 , 2 ] } ",
+		`{"a":{"b":[{"c":"}]"}]}}`,
+		`{"x":"\u00e9 世界 🎉","y":""}`,
+	}
+	for n := 0; n < 140; n++ {
+		a := strings.Repeat("a", n)
+		inputs = append(inputs,
+			`["`+a+`",`+strconv.Itoa(n)+`]`,
+			`{"`+a+`\"":"\\`+a+`"}`,
+			strings.Repeat(" ", n)+`["\\\"`+a+`",{}]`,
+		)
+	}
+	return inputs
+}
+
+// referenceScan derives the structural indices and quote mask of a valid
+// document from encoding/json's tokenizer rather than from the scanner:
+// every separator, the first byte of every token and the closing quote
+// of every string
+func referenceScan(t *testing.T, data []byte) ([]uint32, []uint64) {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var indices []uint32
+	quotes := make([]uint64, (len(data)+63)/64)
+	prev := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Input %q: %v", data, err)
+		}
+		end := int(dec.InputOffset())
+		start := prev
+	scan:
+		for ; start < end; start++ {
+			switch data[start] {
+			case ' ', '\t', '\n', '\r':
+			case ',', ':':
+				indices = append(indices, uint32(start))
+			default:
+				break scan
+			}
+		}
+		indices = append(indices, uint32(start))
+		if _, ok := tok.(string); ok {
+			indices = append(indices, uint32(end-1))
+			quotes[start/64] |= 1 << (start % 64)
+			quotes[(end-1)/64] |= 1 << ((end - 1) % 64)
+		}
+		prev = end
+	}
+	return indices, quotes
+}
+
+func TestScanSWARReference(t *testing.T) {
+	for _, input := range referenceInputs() {
+		want, _ := referenceScan(t, []byte(input))
+
+		s := New()
+		s.buf = []byte(input)
+		s.structuralIndices = s.structuralIndices[:0]
+		s.scanSWAR()
+		got := append([]uint32(nil), s.structuralIndices...)
+		s.Release()
+
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("Input %q:\nexpected %v\ngot      %v", input, want, got)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package scanner
+
+import (
+	"math/bits"
+	"unicode/utf8"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/classify"
+)
+
+// Validate reports whether data is a single valid JSON value surrounded by
+// optional whitespace. Where the classify package has a vector kernel it
+// runs over stage-1 bitmasks with validateMasks; elsewhere, or with SIMD
+// disabled, building the masks eight bytes at a time costs more than the
+// byte-at-a-time Check, which it uses instead.
+func (s *Scanner) Validate(data []byte) bool {
+	if classify.HasKernel() && ActiveLevel() != LevelScalar {
+		return s.validateMasks(data)
+	}
+	offset, _ := s.Check(data)
+	return offset < 0
+}
+
+// validateMasks is Validate as simdjson does it, in two stages over each
+// 64-byte block. Stage 1 is the classify package's bitmasks of quotes,
+// backslashes, operators ({}[]:,), whitespace, control and non-ASCII
+// bytes; BlockState turns the quotes and backslashes into the strings of
+// the block, and only the runs of blocks with non-ASCII bytes go through
+// utf8.Valid. Stage 2
+// walks the structural positions of the block, the operators and the
+// first bytes of strings and of scalars outside strings, through the
+// grammar with a depth counter and one bit per open container recording
+// whether it is an array. Nothing is allocated; only documents nested
+// deeper than maxValidDepth are left to Check.
+func (s *Scanner) validateMasks(data []byte) bool {
+	var (
+		st          BlockState
+		scalarCarry uint64 // 1 if the previous block ended inside a scalar
+		utf8Start   = -1   // the first block of the open non-ASCII run
+		masks       [16]classify.Masks
+		tail        [64]byte
+
+		state  uint8                      = stValue
+		after  uint8                      = stDone // the state after a value
+		depth                             = 0
+		arrays [maxValidDepth / 64]uint64 // bit d is set if level d is an array
+	)
+	for base := 0; base < len(data); {
+		n := classify.Blocks(masks[:], data[base:])
+		if n == 0 {
+			// Spaces pad the last block without adding structure
+			copy(tail[:], data[base:])
+			for i := len(data) - base; i < 64; i++ {
+				tail[i] = ' '
+			}
+			n = classify.Blocks(masks[:1], tail[:])
+		}
+		for _, m := range masks[:n] {
+			quotes, inString, escaped := st.next(m.Quote, m.Backslash)
+			if m.Ctrl&inString != 0 {
+				return false // unescaped control character in a string
+			}
+			if escaped&inString != 0 && !checkEscapes(data, base, escaped&inString) {
+				return false
+			}
+			if m.Ctrl&^(inString|m.Space) != 0 {
+				return false // control character outside a string
+			}
+			// A UTF-8 sequence is all high bytes, so a run of blocks with
+			// them ends on a sequence boundary at the next ASCII block
+			switch {
+			case m.High != 0 && utf8Start < 0:
+				utf8Start = base
+			case m.High == 0 && utf8Start >= 0:
+				if !utf8.Valid(data[utf8Start:base]) {
+					return false
+				}
+				utf8Start = -1
+			}
+
+			scalar := ^(inString | quotes | m.Op | m.Space | m.Ctrl)
+			starts := scalar &^ (scalar<<1 | scalarCarry)
+			scalarCarry = scalar >> 63
+
+			// Stage 2: the grammar, one structural at a time
+			for structurals := m.Op&^inString | quotes&inString | starts; structurals != 0; structurals &= structurals - 1 {
+				i := base + bits.TrailingZeros64(structurals)
+				a := validStep[state][validClass[data[i]]]
+				if a < acString {
+					state = a
+					continue
+				}
+				switch a {
+				case acString:
+					state = after
+				case acScalar:
+					if !checkScalar(data, i) {
+						return false
+					}
+					state = after
+				case acObject, acArray:
+					if depth == maxValidDepth {
+						offset, _ := s.Check(data)
+						return offset < 0
+					}
+					if a == acArray {
+						arrays[depth/64] |= 1 << (depth % 64)
+						state, after = stValueOrEnd, stNextArr
+					} else {
+						arrays[depth/64] &^= 1 << (depth % 64)
+						state, after = stKeyOrEnd, stNextObj
+					}
+					depth++
+				case acClose:
+					depth--
+					switch {
+					case depth == 0:
+						after = stDone
+					case arrays[(depth-1)/64]>>((depth-1)%64)&1 != 0:
+						after = stNextArr
+					default:
+						after = stNextObj
+					}
+					state = after
+				default:
+					return false
+				}
+			}
+			base += 64
+		}
+	}
+	if utf8Start >= 0 && !utf8.Valid(data[utf8Start:]) {
+		return false
+	}
+	return !st.InString() && state == stDone
+}
+
+// maxValidDepth is the nesting Validate tracks without allocating.
+const maxValidDepth = 64 * 64
+
+// Grammar states of validateMasks: what the next structural may be.
+const (
+	stValue      = iota // a value
+	stValueOrEnd        // a value or ']'
+	stKeyOrEnd          // a key or '}'
+	stKey               // a key
+	stColon             // ':'
+	stNextObj           // ',' or '}'
+	stNextArr           // ',' or ']'
+	stDone              // nothing: the document is complete
+)
+
+// Actions of validStep beyond moving to a state.
+const (
+	acString = iota + stDone + 1 // a string value
+	acScalar                     // a number or literal value
+	acObject                     // '{' opening a value
+	acArray                      // '[' opening a value
+	acClose                      // the end of the innermost container
+	acFail                       // a syntax error
+)
+
+// Classes of the structural bytes; any other byte starts a scalar.
+const (
+	clScalar = iota
+	clQuote
+	clLBrace
+	clLBracket
+	clRBrace
+	clRBracket
+	clColon
+	clComma
+)
+
+// validClass is indexed by the byte at a structural position.
+var validClass = [256]uint8{
+	'"': clQuote, '{': clLBrace, '[': clLBracket, '}': clRBrace,
+	']': clRBracket, ':': clColon, ',': clComma,
+}
+
+// validStep is indexed by state and class: the next state or an action.
+// A closing bracket only closes a container of its own kind, since only
+// a state inside such a container accepts it.
+var validStep = [stDone + 1][clComma + 1]uint8{
+	stValue:      {acScalar, acString, acObject, acArray, acFail, acFail, acFail, acFail},
+	stValueOrEnd: {acScalar, acString, acObject, acArray, acFail, acClose, acFail, acFail},
+	stKeyOrEnd:   {acFail, stColon, acFail, acFail, acClose, acFail, acFail, acFail},
+	stKey:        {acFail, stColon, acFail, acFail, acFail, acFail, acFail, acFail},
+	stColon:      {acFail, acFail, acFail, acFail, acFail, acFail, stValue, acFail},
+	stNextObj:    {acFail, acFail, acFail, acFail, acClose, acFail, acFail, stKey},
+	stNextArr:    {acFail, acFail, acFail, acFail, acFail, acClose, acFail, stValue},
+	stDone:       {acFail, acFail, acFail, acFail, acFail, acFail, acFail, acFail},
+}
+
+// checkScalar checks the number or literal starting at i, which must run
+// up to the next whitespace or operator.
+func checkScalar(data []byte, i int) bool {
+	var end int
+	var bad string
+	switch c := data[i]; {
+	case c == 't':
+		end, bad = checkLiteral(data, i, "true")
+	case c == 'f':
+		end, bad = checkLiteral(data, i, "false")
+	case c == 'n':
+		end, bad = checkLiteral(data, i, "null")
+	case c == '-' || isDigit(c):
+		end, bad = checkNumber(data, i)
+	default:
+		return false
+	}
+	return bad == "" && (end == len(data) || scalarEnd[data[end]])
+}
+
+// scalarEnd is indexed by byte: the bytes that may follow a scalar.
+var scalarEnd = [256]bool{
+	' ': true, '\t': true, '\n': true, '\r': true,
+	',': true, ':': true, '[': true, ']': true, '{': true, '}': true,
+}
+
+// checkEscapes checks the bytes escaped by a backslash in a string, the
+// set bits of mask in the block at base.
+func checkEscapes(data []byte, base int, mask uint64) bool {
+	for ; mask != 0; mask &= mask - 1 {
+		i := base + bits.TrailingZeros64(mask)
+		if i >= len(data) {
+			return false
+		}
+		switch data[i] {
+		case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		case 'u':
+			if i+4 >= len(data) {
+				return false
+			}
+			for _, h := range data[i+1 : i+5] {
+				if !isHex(h) {
+					return false
+				}
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
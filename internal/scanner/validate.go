@@ -0,0 +1,256 @@
+package scanner
+
+import (
+	"unicode/utf8"
+)
+
+// Descriptions of what the validator expected at the offset it stopped.
+// They are constants so that Check never allocates.
+const (
+	ExpectValue      = "value"
+	ExpectValueOrEnd = "value or ']'"
+	ExpectKeyOrEnd   = "string key or '}'"
+	ExpectKey        = "string key"
+	ExpectColon      = "':'"
+	ExpectObjectNext = "',' or '}'"
+	ExpectArrayNext  = "',' or ']'"
+	ExpectEOF        = "end of input"
+	ExpectQuote      = "closing '\"'"
+	ExpectEscape     = "escape character"
+	ExpectHex        = "hex digit"
+	ExpectStringChar = "escaped control character"
+	ExpectUTF8       = "valid UTF-8"
+	ExpectDigit      = "digit"
+	ExpectLiteral    = "literal true, false or null"
+)
+
+// Check validates data against the JSON grammar (RFC 8259). It returns -1
+// and "" when data is a single valid JSON value surrounded by optional
+// whitespace, otherwise the byte offset of the first violation and one of
+// the Expect* descriptions. Check does not allocate once the scanner's
+// nesting stack has grown to the document's depth.
+func (s *Scanner) Check(data []byte) (int, string) {
+	stack := s.stack[:0]
+	defer func() { s.stack = stack[:0] }()
+
+	i := skipWhitespace(data, 0)
+	expect := ExpectValue
+
+	for {
+		// Expecting a value
+		if i >= len(data) {
+			return len(data), expect
+		}
+		switch c := data[i]; c {
+		case '{':
+			i = skipWhitespace(data, i+1)
+			if i < len(data) && data[i] == '}' {
+				i++
+				break
+			}
+			stack = append(stack, '{')
+			if i, expect = checkKey(data, i, ExpectKeyOrEnd); expect != "" {
+				return i, expect
+			}
+			expect = ExpectValue
+			continue
+		case '[':
+			i = skipWhitespace(data, i+1)
+			if i < len(data) && data[i] == ']' {
+				i++
+				break
+			}
+			stack = append(stack, '[')
+			expect = ExpectValueOrEnd
+			continue
+		case '"':
+			var bad string
+			if i, bad = checkString(data, i); bad != "" {
+				return i, bad
+			}
+		case 't':
+			if i, expect = checkLiteral(data, i, "true"); expect != "" {
+				return i, expect
+			}
+		case 'f':
+			if i, expect = checkLiteral(data, i, "false"); expect != "" {
+				return i, expect
+			}
+		case 'n':
+			if i, expect = checkLiteral(data, i, "null"); expect != "" {
+				return i, expect
+			}
+		default:
+			if c != '-' && (c < '0' || c > '9') {
+				return i, expect
+			}
+			var bad string
+			if i, bad = checkNumber(data, i); bad != "" {
+				return i, bad
+			}
+		}
+
+		// A value has been consumed; close containers until one continues
+		for {
+			i = skipWhitespace(data, i)
+			if len(stack) == 0 {
+				if i < len(data) {
+					return i, ExpectEOF
+				}
+				return -1, ""
+			}
+
+			top := stack[len(stack)-1]
+			next := ExpectArrayNext
+			if top == '{' {
+				next = ExpectObjectNext
+			}
+			if i >= len(data) {
+				return i, next
+			}
+
+			c := data[i]
+			if c == ',' {
+				i = skipWhitespace(data, i+1)
+				if top == '{' {
+					if i, expect = checkKey(data, i, ExpectKey); expect != "" {
+						return i, expect
+					}
+				}
+				expect = ExpectValue
+				break
+			}
+			if (top == '{' && c == '}') || (top == '[' && c == ']') {
+				stack = stack[:len(stack)-1]
+				i++
+				continue
+			}
+			return i, next
+		}
+	}
+}
+
+// checkKey validates an object key and the following colon starting at i.
+// On success it returns the offset of the value and "".
+func checkKey(data []byte, i int, expect string) (int, string) {
+	if i >= len(data) || data[i] != '"' {
+		return i, expect
+	}
+	i, bad := checkString(data, i)
+	if bad != "" {
+		return i, bad
+	}
+	i = skipWhitespace(data, i)
+	if i >= len(data) || data[i] != ':' {
+		return i, ExpectColon
+	}
+	return skipWhitespace(data, i+1), ""
+}
+
+// checkString validates the string starting at the quote at i and returns
+// the offset just past its closing quote.
+func checkString(data []byte, i int) (int, string) {
+	i++
+	for {
+		if i = swarStringPlain(data, i); i >= len(data) {
+			break
+		}
+		c := data[i]
+		switch {
+		case c == '"':
+			return i + 1, ""
+		case c == '\\':
+			i++
+			if i >= len(data) {
+				return i, ExpectEscape
+			}
+			switch data[i] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				i++
+			case 'u':
+				i++
+				for j := 0; j < 4; j++ {
+					if i >= len(data) || !isHex(data[i]) {
+						return i, ExpectHex
+					}
+					i++
+				}
+			default:
+				return i, ExpectEscape
+			}
+		case c < 0x20:
+			return i, ExpectStringChar
+		default:
+			r, size := utf8.DecodeRune(data[i:])
+			if r == utf8.RuneError && size <= 1 {
+				return i, ExpectUTF8
+			}
+			i += size
+		}
+	}
+	return i, ExpectQuote
+}
+
+// checkNumber validates the number starting at i and returns the offset
+// just past it.
+func checkNumber(data []byte, i int) (int, string) {
+	if data[i] == '-' {
+		i++
+	}
+	if i >= len(data) || !isDigit(data[i]) {
+		return i, ExpectDigit
+	}
+	if data[i] == '0' {
+		i++
+	} else {
+		for i++; i < len(data) && isDigit(data[i]); i++ {
+		}
+	}
+	if i < len(data) && data[i] == '.' {
+		i++
+		if i >= len(data) || !isDigit(data[i]) {
+			return i, ExpectDigit
+		}
+		i = swarSkipDigits(data, i)
+	}
+	if i < len(data) && (data[i] == 'e' || data[i] == 'E') {
+		i++
+		if i < len(data) && (data[i] == '+' || data[i] == '-') {
+			i++
+		}
+		if i >= len(data) || !isDigit(data[i]) {
+			return i, ExpectDigit
+		}
+		i = swarSkipDigits(data, i)
+	}
+	return i, ""
+}
+
+// checkLiteral validates that lit appears at i.
+func checkLiteral(data []byte, i int, lit string) (int, string) {
+	for j := 0; j < len(lit); j++ {
+		if i+j >= len(data) || data[i+j] != lit[j] {
+			return i + j, ExpectLiteral
+		}
+	}
+	return i + len(lit), ""
+}
+
+// whitespace is indexed by byte; a table beats four comparisons on the
+// long indentation runs of pretty-printed documents.
+var whitespace = [256]bool{' ': true, '\t': true, '\n': true, '\r': true}
+
+func skipWhitespace(data []byte, i int) int {
+	for i < len(data) && whitespace[data[i]] {
+		i++
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
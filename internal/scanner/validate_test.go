@@ -0,0 +1,204 @@
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestScanner_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		offset   int
+		expected string
+	}{
+		{"valid object", `{"a":[1,2.5e3,-0],"b":{"c":null}}`, -1, ""},
+		{"valid scalar", ` "x" `, -1, ""},
+		{"valid unicode", `["é","世界","🌍","é"]`, -1, ""},
+		{"empty", ``, 0, ExpectValue},
+		{"whitespace only", `  `, 2, ExpectValue},
+		{"unclosed object", `{"a":1`, 6, ExpectObjectNext},
+		{"mismatched close", `[1}`, 2, ExpectArrayNext},
+		{"key not string", `{1:2}`, 1, ExpectKeyOrEnd},
+		{"missing colon", `{"a" 1}`, 5, ExpectColon},
+		{"empty array slot", `[1,,2]`, 3, ExpectValue},
+		{"leading zero", `01`, 1, ExpectEOF},
+		{"bad exponent", `1e+`, 3, ExpectDigit},
+		{"bad literal", `nul`, 3, ExpectLiteral},
+		{"control char", "\"a\tb\"", 2, ExpectStringChar},
+		{"short unicode", `"\u12"`, 5, ExpectHex},
+		{"invalid utf8", "\"\xff\"", 1, ExpectUTF8},
+		{"unterminated", `"abc`, 4, ExpectQuote},
+		{"two values", `[1] [2]`, 4, ExpectEOF},
+		{"long string", `"abcdefghijklmnopqrstuvwxyz\"0123456789é\u00e9"`, -1, ""},
+		{"control char past word", "\"abcdefghijklm\nop\"", 14, ExpectStringChar},
+		{"invalid utf8 past word", "\"abcdefghijklmnop\xc3(\"", 17, ExpectUTF8},
+		{"unterminated long", `"abcdefghijklmnopq\"`, 20, ExpectQuote},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			defer s.Release()
+
+			offset, expected := s.Check([]byte(tt.input))
+			if offset != tt.offset || expected != tt.expected {
+				t.Errorf("Check(%q) = (%d, %q), expected (%d, %q)", tt.input, offset, expected, tt.offset, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScanner_CheckDeepNesting(t *testing.T) {
+	depth := 100000
+	data := []byte(strings.Repeat("[", depth) + strings.Repeat("]", depth))
+
+	s := New()
+	defer s.Release()
+
+	if offset, expected := s.Check(data); offset != -1 {
+		t.Errorf("Deeply nested input rejected at %d: expected %s", offset, expected)
+	}
+}
+
+// TestScanner_Validate checks validateMasks against Check and
+// encoding/json, with every input shifted across the 8-byte words and
+// 64-byte blocks stage 1 classifies
+func TestScanner_Validate(t *testing.T) {
+	inputs := []string{
+		`{"a":[1,2.5e3,-0],"b":{"c":null}}`, ` "x" `, `["é","世界","🌍"]`, ``, `  `,
+		`{"a":1`, `[1}`, `{1:2}`, `{"a" 1}`, `[1,,2]`, `01`, `1e+`, `nul`, `nulls`,
+		"\"a\tb\"", `"\u12"`, `"\u12345"`, "\"\xff\"", `"abc`, `[1] [2]`, `[1]]`, `{"a":1}}`,
+		`"\"\\\/\b\f\n\r\t"`, `"\x"`, `"\`, `\"a"`, `[1"a"]`, `["a"1]`, `[truefalse]`,
+		`{"a":1,}`, `[1,]`, `{"a"}`, `{"a":}`, `{:1}`, `[,1]`, `{"a":1 "b":2}`, `-`, `-01`, `1.`,
+		`.5`, `1e5`, `-0.0E-0`, `[-]`, `{"a":[{"b":[]},{}]}`, "[\v]", "[1\f]", "\r\n[\t]\n",
+		`"a\"b"`, `"a\\"b"`, `["\\\\"]`, `[]`, `{}`, `[{}]`, `{"":""}`, `true`, `falsey`,
+		`["` + strings.Repeat("é", 40) + `","` + strings.Repeat("a", 70) + `","🌍"]`,
+		`["` + strings.Repeat("é", 40) + "\xc3\"," + strings.Repeat(" ", 70) + `"é"]`,
+		`["` + strings.Repeat("a", 70) + "\xf0\x9f\x8c\"]",
+	}
+	matches, err := filepath.Glob("../../testdata/JSONTestSuite/test_parsing/*.json")
+	if err != nil || len(matches) == 0 {
+		t.Fatalf("JSONTestSuite not found: %v", err)
+	}
+	for _, m := range matches {
+		data, err := os.ReadFile(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, string(data))
+	}
+
+	s := New()
+	defer s.Release()
+	for _, input := range inputs {
+		for pad := 0; pad < 70; pad++ {
+			data := []byte(strings.Repeat(" ", pad) + input)
+			offset, _ := s.Check(data)
+			got := s.validateMasks(data)
+			if got != (offset < 0) {
+				t.Errorf("validateMasks(%q) = %v, Check says %v", data, got, offset < 0)
+			}
+			if utf8.Valid(data) && len(data) < 10000 && got != json.Valid(data) {
+				t.Errorf("validateMasks(%q) = %v, encoding/json says %v", data, got, json.Valid(data))
+			}
+			if s.Validate(data) != got {
+				t.Errorf("Validate(%q) = %v, expected %v", data, !got, got)
+			}
+		}
+	}
+}
+
+func TestScanner_ValidateDeepNesting(t *testing.T) {
+	s := New()
+	defer s.Release()
+
+	// Beyond maxValidDepth validateMasks hands over to Check
+	for _, depth := range []int{maxValidDepth - 1, maxValidDepth, maxValidDepth + 1, 100000} {
+		open, close := strings.Repeat("[", depth), strings.Repeat("]", depth)
+		if !s.validateMasks([]byte(open + close)) {
+			t.Errorf("Depth %d: expected valid", depth)
+		}
+		if s.validateMasks([]byte(open + close[1:] + "}")) {
+			t.Errorf("Depth %d: expected mismatched close rejected", depth)
+		}
+		if s.validateMasks([]byte(open + close[1:])) {
+			t.Errorf("Depth %d: expected unclosed array rejected", depth)
+		}
+	}
+
+	// The kinds of containers are tracked at every level
+	var b strings.Builder
+	for d := 0; d < 200; d++ {
+		if d%3 == 0 {
+			b.WriteString(`{"k":`)
+		} else {
+			b.WriteString(`[`)
+		}
+	}
+	prefix := b.String()
+	var closing strings.Builder
+	for d := 199; d >= 0; d-- {
+		if d%3 == 0 {
+			closing.WriteString(`}`)
+		} else {
+			closing.WriteString(`]`)
+		}
+	}
+	if !s.validateMasks([]byte(prefix + "1" + closing.String())) {
+		t.Error("Expected mixed nesting valid")
+	}
+	swapped := strings.Replace(closing.String(), "]}", "}]", 1)
+	if s.validateMasks([]byte(prefix + "1" + swapped)) {
+		t.Error("Expected swapped closing brackets rejected")
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(`{"id":12345,"name":"User Name Here","email":"user@example.com","age":25,` +
+			`"active":true,"tags":["tag1","tag2","tag3"],"profile":{"bio":"This is a bio text",` +
+			`"location":"San Francisco, CA","website":"https://example.com"}}`)
+	}
+	sb.WriteString("]")
+	data := []byte(sb.String())
+
+	s := New()
+	defer s.Release()
+	b.Run("Validate", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if !s.Validate(data) {
+				b.Fatal("invalid")
+			}
+		}
+	})
+	b.Run("Masks", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if !s.validateMasks(data) {
+				b.Fatal("invalid")
+			}
+		}
+	})
+	b.Run("Check", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if offset, _ := s.Check(data); offset >= 0 {
+				b.Fatal("invalid")
+			}
+		}
+	})
+}
@@ -1,8 +1,14 @@
 package simdjson
 
 import (
+	"context"
 	"errors"
 	"io"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 	
 	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
 )
@@ -12,24 +18,216 @@ var (
 	ErrUnsupportedType = errors.New("unsupported type")
 )
 
+// SyntaxError describes the first grammar violation found in a JSON document.
+type SyntaxError struct {
+	Offset   int64  // byte offset of the violation
+	Line     int    // 1-based line of the violation
+	Column   int    // 1-based column of the violation, in bytes
+	Expected string // what was expected at Offset
+}
+
+func (e *SyntaxError) Error() string {
+	return "invalid JSON at line " + strconv.Itoa(e.Line) + ", column " + strconv.Itoa(e.Column) +
+		" (offset " + strconv.FormatInt(e.Offset, 10) + "): expected " + e.Expected
+}
+
+func (e *SyntaxError) Is(target error) bool {
+	return target == ErrInvalidJSON
+}
+
+func newSyntaxError(data []byte, offset int, expected string) *SyntaxError {
+	line, col := 1, 1
+	for _, c := range data[:offset] {
+		if c == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return &SyntaxError{
+		Offset:   int64(offset),
+		Line:     line,
+		Column:   col,
+		Expected: expected,
+	}
+}
+
 func Marshal(v interface{}) ([]byte, error) {
+	if a := currentAuditor.Load(); a != nil {
+		return a.marshal(v)
+	}
+	return marshal(v)
+}
+
+func marshal(v interface{}) ([]byte, error) {
 	e := newEncoder()
 	defer e.release()
 	
 	return e.marshal(v)
 }
 
+// MarshalOptions configure MarshalWithOptions. Start from
+// DefaultMarshalOptions, which gives the behaviour of Marshal; the zero
+// value also turns off EscapeHTML.
+type MarshalOptions struct {
+	// EscapeHTML escapes '<', '>' and '&' in strings; see
+	// Encoder.SetEscapeHTML.
+	EscapeHTML bool
+
+	// NilAsEmpty encodes nil slices as [] and nil maps as {}, as many web
+	// APIs expect, instead of null as encoding/json does. A nil []byte
+	// becomes "".
+	NilAsEmpty bool
+
+	// BytesFormat is the format of []byte values, standard base64 by
+	// default. The format tag option overrides it for a field.
+	BytesFormat BytesFormat
+
+	// FieldNaming derives the keys of struct fields whose json tag gives
+	// no name from their Go names, SnakeCase for example. Generated code
+	// uses the naming given to simdjson-gen instead.
+	FieldNaming FieldNaming
+
+	// TimeFormat is the format of time.Time values: a layout as
+	// time.Format takes, written as a string, or TimeUnix or TimeUnixMilli,
+	// written as a number. The default is RFC 3339 with fractional
+	// seconds, as encoding/json writes.
+	TimeFormat string
+
+	// TimeLocation, if set, is the location times are converted to before
+	// they are written; otherwise each is written in its own.
+	TimeLocation *time.Location
+
+	// FloatPrecision, if positive, writes floats with that many digits
+	// after the decimal point, rounded, as metrics endpoints and systems
+	// with fixed-point output expect, instead of the fewest digits that
+	// read back as the same float. The prec tag option sets the digits for
+	// the floats in one field, and may be zero:
+	//
+	//	Ratio float64 `json:"ratio,prec:2"`
+	FloatPrecision int
+
+	// TrimFloatZeros drops the trailing zeros of floats written with a
+	// precision, and the point if no digits remain after it, so 1.50
+	// becomes 1.5 and 2.00 becomes 2.
+	TrimFloatZeros bool
+
+	// NonFinite is how NaN and infinite floats are written: an error by
+	// default, as in encoding/json, or null or strings for the datasets
+	// and JavaScript clients that have them.
+	NonFinite NonFiniteFloats
+}
+
+// DefaultMarshalOptions returns the options Marshal uses.
+func DefaultMarshalOptions() MarshalOptions {
+	return MarshalOptions{EscapeHTML: true}
+}
+
+// MarshalWithOptions returns the JSON encoding of v like Marshal, as
+// configured by opts; nil means DefaultMarshalOptions.
+func MarshalWithOptions(v interface{}, opts *MarshalOptions) ([]byte, error) {
+	o := DefaultMarshalOptions()
+	if opts != nil {
+		o = *opts
+	}
+
+	e := newEncoder()
+	defer e.release()
+	
+	e.escapeHTML = o.EscapeHTML
+	e.nilAsEmpty = o.NilAsEmpty
+	e.bytesFormat = o.BytesFormat
+	e.naming = o.FieldNaming
+	e.timeFormat = o.TimeFormat
+	e.timeLoc = o.TimeLocation
+	if o.FloatPrecision > 0 {
+		e.floatPrec = o.FloatPrecision
+	}
+	e.trimZeros = o.TrimFloatZeros
+	e.nonFinite = o.NonFinite
+	return e.marshal(v)
+}
+
 func Unmarshal(data []byte, v interface{}) error {
+	if a := currentAuditor.Load(); a != nil {
+		return a.unmarshal(data, v)
+	}
+	return unmarshal(data, v)
+}
+
+func unmarshal(data []byte, v interface{}) error {
 	d := newDecoder(data)
 	defer d.release()
 	
 	return d.unmarshal(v)
 }
 
+// UnmarshalWithOptions parses data into the value pointed to by v like
+// Unmarshal, as configured by opts; nil means DefaultOptions. It takes its
+// decoder from the same pool as Unmarshal, so options can vary per call
+// without a Parser and without allocating, unless InternKeys asks for an
+// intern table. With CopyStrings off, strings may alias data as they do
+// for a Parser.
+func UnmarshalWithOptions(data []byte, v interface{}, opts *Options) error {
+	o := &defaultOptions
+	if opts != nil {
+		o = opts
+	}
+
+	d := newDecoder(data)
+	defer d.release()
+	
+	d.setOptions(o)
+	return d.unmarshal(v)
+}
+
+// UnmarshalContext is like Unmarshal, but gives up with ctx.Err() once ctx
+// is canceled or its deadline passes, so that a request handler is not held
+// up by a pathological document. The context is checked every few thousand
+// tokens while decoding and every 64KB while tokenizing; v may be partly
+// filled in when it gives up.
+func UnmarshalContext(ctx context.Context, data []byte, v interface{}) error {
+	d := newDecoder(data)
+	defer d.release()
+	
+	d.setContext(ctx)
+	return d.unmarshal(v)
+}
+
+// Decode parses data into a new value of type T.
+//
+//	cfg, err := simdjson.Decode[Config](data)
+func Decode[T any](data []byte) (T, error) {
+	var v T
+	err := DecodeInto(data, &v)
+	return v, err
+}
+
+// DecodeInto parses data into *dst like Unmarshal, but the destination
+// type is known at compile time: types with generated UnmarshalJSONFrom
+// methods skip reflection entirely, and the decode plan for T is looked up
+// once per call instead of being rediscovered from an interface value.
+func DecodeInto[T any](data []byte, dst *T) error {
+	if dst == nil {
+		return errors.New("unmarshal requires non-nil pointer")
+	}
+	
+	d := newDecoder(data)
+	defer d.release()
+	
+	t := reflect.TypeOf(dst).Elem()
+	if u, ok := interface{}(dst).(UnmarshalerFrom); ok && codecFor(t) == nil {
+		return d.unmarshalFrom(u)
+	}
+	return d.run(planFor(t), reflect.ValueOf(dst).Elem())
+}
+
 type Decoder struct {
 	r       io.Reader
 	buf     []byte
 	scanner *scanner.Scanner
+	auto    bool // see NewDecoderAuto
 }
 
 func NewDecoder(r io.Reader) *Decoder {
@@ -40,42 +238,122 @@ func NewDecoder(r io.Reader) *Decoder {
 	}
 }
 
+// Reset makes d read from r, discarding the input read so far but keeping
+// its buffer and scanner, so that one Decoder can decode many streams
+// without reallocating them, as with bufio.Reader.Reset. A Decoder from
+// NewDecoderAuto keeps detecting compressed input.
+func (d *Decoder) Reset(r io.Reader) {
+	if d.auto {
+		r = &autoReader{r: r}
+	}
+	d.r = r
+	d.buf = d.buf[:0]
+}
+
 func (d *Decoder) Decode(v interface{}) error {
+	return d.DecodeContext(context.Background(), v)
+}
+
+// DecodeContext is like Decode, but gives up with ctx.Err() once ctx is
+// canceled, as UnmarshalContext does. Reading the input is not interrupted;
+// the context is checked once it has been read.
+func (d *Decoder) DecodeContext(ctx context.Context, v interface{}) error {
 	if d.r != nil {
-		data, err := io.ReadAll(d.r)
+		data, err := readAppend(d.buf[:0], d.r)
+		d.buf = data
 		if err != nil {
 			return err
 		}
-		d.buf = data
 	}
 	
 	dec := newDecoder(d.buf)
 	defer dec.release()
 	
+	dec.setContext(ctx)
 	return dec.unmarshal(v)
 }
 
+// readAppend appends the rest of r to dst like io.ReadAll, reusing the
+// capacity of dst.
+func readAppend(dst []byte, r io.Reader) ([]byte, error) {
+	for {
+		if len(dst) == cap(dst) {
+			dst = append(dst, 0)[:len(dst)]
+		}
+		n, err := r.Read(dst[len(dst):cap(dst)])
+		dst = dst[:len(dst)+n]
+		if err == io.EOF {
+			return dst, nil
+		}
+		if err != nil {
+			return dst, err
+		}
+	}
+}
+
+// An Encoder writes JSON values to an output stream. It is safe for
+// concurrent use: each Encode call encodes into its own pooled buffer,
+// returned when the call ends, and the writes to the stream are serialized
+// so that values from different goroutines are never interleaved.
 type Encoder struct {
-	w   io.Writer
-	buf []byte
-	enc *encoder
+	mu         sync.Mutex // serializes writes to w
+	w          io.Writer
+	escapeHTML atomic.Bool
+	flushSize  atomic.Int64
 }
 
 func NewEncoder(w io.Writer) *Encoder {
-	return &Encoder{
-		w:   w,
-		buf: make([]byte, 0, 4096),
-		enc: newEncoder(),
-	}
+	e := &Encoder{w: w}
+	e.escapeHTML.Store(true)
+	return e
+}
+
+// SetEscapeHTML specifies whether '<', '>' and '&' in strings are escaped
+// as \u003c, \u003e and \u0026, so the output is safe to embed in HTML.
+// It is on by default, as it is for Marshal and encoding/json; turn it off
+// for output that never reaches a browser to keep it readable.
+func (e *Encoder) SetEscapeHTML(on bool) {
+	e.escapeHTML.Store(on)
 }
 
+// SetFlushSize makes Encode stream its output: while it walks arrays,
+// slices and maps it writes what it has encoded whenever that reaches n
+// bytes, rather than holding the whole encoding in memory. This bounds the
+// memory needed to export huge values to about n bytes plus the largest
+// element. In exchange, a value that fails to encode midway leaves partial
+// output in the stream, and concurrent Encode calls wait for each other
+// rather than only for the final write. n <= 0 restores the default.
+func (e *Encoder) SetFlushSize(n int) {
+	e.flushSize.Store(int64(n))
+}
+
+// Encode writes the JSON encoding of v to the underlying writer. The value
+// is encoded into a pooled buffer first and written with a single Write,
+// so nothing is written if encoding fails, unless SetFlushSize selected
+// streaming.
 func (e *Encoder) Encode(v interface{}) error {
-	data, err := e.enc.marshal(v)
-	if err != nil {
+	enc := newEncoder()
+	defer enc.release()
+	
+	enc.escapeHTML = e.escapeHTML.Load()
+	if n := e.flushSize.Load(); n > 0 {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		enc.out, enc.flushSize = e.w, int(n)
+		if err := enc.encode(reflect.ValueOf(v)); err != nil {
+			return err
+		}
+		_, err := e.w.Write(enc.buf)
 		return err
 	}
-	
-	_, err = e.w.Write(data)
+
+	if err := enc.encode(reflect.ValueOf(v)); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := e.w.Write(enc.buf)
 	return err
 }
 
@@ -85,3 +363,49 @@ func Valid(data []byte) bool {
 	
 	return s.Validate(data)
 }
+
+// ValidWithOptions reports whether data is valid JSON like Valid, and also
+// whether it is within opts.Limits and, with opts.SkipBOM or opts.UTF16, is
+// in an encoding those options accept. nil opts means DefaultOptions.
+func ValidWithOptions(data []byte, opts *Options) bool {
+	if opts == nil {
+		return Valid(data)
+	}
+	if opts.SkipBOM || opts.UTF16 {
+		var err error
+		if data, err = decodeText(data, opts.SkipBOM, opts.UTF16); err != nil {
+			return false
+		}
+	}
+
+	s := scanner.New()
+	defer s.Release()
+	
+	if !s.Validate(data) {
+		return false
+	}
+	if opts.Limits == (Limits{}) {
+		return true
+	}
+	// Only the tokenizer checks limits
+	s.Limits = scanner.Limits(opts.Limits)
+	tokens, err := s.SimpleTokenize(data)
+	if err != nil {
+		return false
+	}
+	scanner.PutTokenSlice(tokens)
+	return true
+}
+
+// ValidateWithError reports whether data is valid JSON like Valid, but
+// returns a *SyntaxError locating the first violation instead of false.
+func ValidateWithError(data []byte) error {
+	s := scanner.New()
+	defer s.Release()
+	
+	offset, expected := s.Check(data)
+	if offset < 0 {
+		return nil
+	}
+	return newSyntaxError(data, offset, expected)
+}
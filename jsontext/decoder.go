@@ -0,0 +1,291 @@
+package jsontext
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/parser"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// A SyntaxError reports input that breaks the JSON grammar.
+type SyntaxError struct {
+	Offset   int64  // byte offset of the violation
+	Expected string // what was expected there
+}
+
+func (e *SyntaxError) Error() string {
+	return "invalid JSON at offset " + strconv.FormatInt(e.Offset, 10) + ": expected " + e.Expected
+}
+
+var errUnexpectedEnd = errors.New("unexpected end of JSON")
+
+// A Decoder reads a sequence of JSON values, separated by optional
+// whitespace, as tokens. The whole input is read and tokenized on the
+// first call, so a Decoder suits documents that fit in memory; tokens and
+// values it returns alias that copy of the input and stay valid.
+//
+// Object names are returned as string tokens, each followed by the tokens
+// of its value. Commas and colons are checked but not returned.
+type Decoder struct {
+	r      io.Reader
+	data   []byte
+	tokens []scanner.Token
+	pos    int
+	loaded bool
+	err    error
+
+	// Grammar state: the open objects and arrays, and for the innermost
+	// whether nothing has been read from it yet and, in an object, whether
+	// its next token is a value rather than a name
+	stack   []Kind
+	first   bool
+	inValue bool
+
+	scratch []byte
+}
+
+// NewDecoder returns a Decoder that reads r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// NewDecoderBytes returns a Decoder that reads data, without copying it.
+// data must not be modified while tokens read from it are in use.
+func NewDecoderBytes(data []byte) *Decoder {
+	return &Decoder{data: data}
+}
+
+// load reads and tokenizes the input.
+func (d *Decoder) load() error {
+	d.loaded = true
+	if d.r != nil {
+		data, err := io.ReadAll(d.r)
+		if err != nil {
+			return err
+		}
+		d.data = data
+	}
+	if len(bytes.TrimLeft(d.data, " \t\r\n")) == 0 {
+		return nil
+	}
+
+	s := scanner.New()
+	defer s.Release()
+	tokens, err := s.SimpleTokenize(d.data)
+	if err != nil {
+		return err
+	}
+	// Tokens are kept as long as the Decoder, so they are not pooled
+	d.tokens = append([]scanner.Token(nil), tokens...)
+	scanner.PutTokenSlice(tokens)
+	return nil
+}
+
+func (d *Decoder) fail(err error) error {
+	if d.err == nil {
+		d.err = err
+	}
+	return d.err
+}
+
+func (d *Decoder) syntaxError(i int, expected string) error {
+	offset := len(d.data)
+	if i < len(d.tokens) {
+		offset = int(d.tokens[i].Start)
+	}
+	return d.fail(&SyntaxError{Offset: int64(offset), Expected: expected})
+}
+
+// next returns the position of the token that starts the next value, name
+// or end of a container, consuming the comma or colon before it. At the end
+// of the input it returns -1, or an error inside a container.
+func (d *Decoder) next() (int, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+	if !d.loaded {
+		if err := d.load(); err != nil {
+			return 0, d.fail(err)
+		}
+	}
+
+	i := d.pos
+	if len(d.stack) == 0 {
+		if i >= len(d.tokens) {
+			return -1, nil
+		}
+		return i, nil
+	}
+
+	top := d.stack[len(d.stack)-1]
+	if i >= len(d.tokens) {
+		return 0, d.fail(errUnexpectedEnd)
+	}
+	switch t := d.tokens[i].Type; {
+	case d.inValue:
+		if t != scanner.TokenColon {
+			return 0, d.syntaxError(i, "':'")
+		}
+		i++
+	case t == scanner.TokenObjectEnd && top == '{', t == scanner.TokenArrayEnd && top == '[':
+		return i, nil
+	case !d.first:
+		if t != scanner.TokenComma {
+			if top == '{' {
+				return 0, d.syntaxError(i, "',' or '}'")
+			}
+			return 0, d.syntaxError(i, "',' or ']'")
+		}
+		i++
+	}
+	if i >= len(d.tokens) {
+		return 0, d.fail(errUnexpectedEnd)
+	}
+	if top == '{' && !d.inValue && d.tokens[i].Type != scanner.TokenString {
+		if d.first {
+			return 0, d.syntaxError(i, "string key or '}'")
+		}
+		return 0, d.syntaxError(i, "string key")
+	}
+	return i, nil
+}
+
+// PeekKind returns the kind of the next token, or 0 at the end of the
+// input or after an error.
+func (d *Decoder) PeekKind() Kind {
+	i, err := d.next()
+	if err != nil || i < 0 {
+		return 0
+	}
+	return kindOf(d.data[d.tokens[i].Start])
+}
+
+// ReadToken reads the next token. It returns io.EOF once every value in
+// the input has been read, and an error, the same on every later call, if
+// the input is not valid JSON.
+func (d *Decoder) ReadToken() (Token, error) {
+	i, err := d.next()
+	if err != nil {
+		return Token{}, err
+	}
+	if i < 0 {
+		return Token{}, io.EOF
+	}
+
+	tok := d.tokens[i]
+	t := Token{raw: d.data[tok.Start:tok.End], offset: int64(tok.Start)}
+	switch tok.Type {
+	case scanner.TokenObjectBegin, scanner.TokenArrayBegin:
+		d.value()
+		d.stack = append(d.stack, t.Kind())
+		d.first = true
+	case scanner.TokenObjectEnd, scanner.TokenArrayEnd:
+		if len(d.stack) == 0 || d.inValue {
+			return Token{}, d.syntaxError(i, "value")
+		}
+		if open := d.stack[len(d.stack)-1]; open == '[' && t.Kind() != ']' {
+			// Only a '}' right after '[' gets here
+			return Token{}, d.syntaxError(i, "value or ']'")
+		}
+		d.stack = d.stack[:len(d.stack)-1]
+		d.first = false
+	case scanner.TokenColon, scanner.TokenComma:
+		return Token{}, d.syntaxError(i, "value")
+	case scanner.TokenString:
+		if err := d.checkString(i); err != nil {
+			return Token{}, err
+		}
+		if len(d.stack) > 0 && d.stack[len(d.stack)-1] == '{' && !d.inValue {
+			// A name; its value comes next
+			d.first = false
+			d.inValue = true
+			break
+		}
+		d.value()
+	default:
+		d.value()
+	}
+	d.pos = i + 1
+	return t, nil
+}
+
+// value records that a value starts in the innermost container.
+func (d *Decoder) value() {
+	d.first = false
+	d.inValue = false
+}
+
+// checkString checks the escapes of the string token at i.
+func (d *Decoder) checkString(i int) error {
+	tok := d.tokens[i]
+	if !tok.HasEscape() {
+		return nil
+	}
+	s := d.data[tok.Start+1 : tok.End-1]
+	var err error
+	if d.scratch, err = parser.AppendUnescaped(d.scratch[:0], s); err != nil {
+		return d.syntaxError(i, "valid escape sequence")
+	}
+	return nil
+}
+
+// ReadValue reads the next value whole and returns it as written; it must
+// not be modified. At the end of a container it fails with a SyntaxError.
+func (d *Decoder) ReadValue() ([]byte, error) {
+	i, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 {
+		return nil, io.EOF
+	}
+	end, err := scanner.SkipTokens(d.tokens, i)
+	if err != nil {
+		switch d.tokens[i].Type {
+		case scanner.TokenObjectBegin, scanner.TokenArrayBegin:
+			return nil, d.fail(errUnexpectedEnd)
+		}
+		return nil, d.syntaxError(i, "value")
+	}
+	if d.tokens[i].Type == scanner.TokenString && len(d.stack) > 0 && d.stack[len(d.stack)-1] == '{' && !d.inValue {
+		return nil, d.syntaxError(i, "value")
+	}
+	if end-i == 1 && d.tokens[i].Type == scanner.TokenString {
+		if err := d.checkString(i); err != nil {
+			return nil, err
+		}
+	} else if end-i > 1 {
+		// Check the grammar inside the value
+		s := scanner.New()
+		offset, expected := s.Check(d.data[d.tokens[i].Start:d.tokens[end-1].End])
+		s.Release()
+		if offset >= 0 {
+			return nil, d.fail(&SyntaxError{Offset: int64(d.tokens[i].Start) + int64(offset), Expected: expected})
+		}
+	}
+	d.value()
+	d.pos = end
+	return d.data[d.tokens[i].Start:d.tokens[end-1].End], nil
+}
+
+// SkipValue reads the next value and discards it.
+func (d *Decoder) SkipValue() error {
+	_, err := d.ReadValue()
+	return err
+}
+
+// StackDepth returns how many objects and arrays are open.
+func (d *Decoder) StackDepth() int {
+	return len(d.stack)
+}
+
+// InputOffset returns the offset just past the last token or value read.
+func (d *Decoder) InputOffset() int64 {
+	if d.pos == 0 || d.pos > len(d.tokens) {
+		return 0
+	}
+	return int64(d.tokens[d.pos-1].End)
+}
@@ -0,0 +1,140 @@
+package jsontext
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// readAll reads every token of input, returning them as kinds and texts.
+func readAll(d *Decoder) ([]string, error) {
+	var toks []string
+	for {
+		tok, err := d.ReadToken()
+		if err == io.EOF {
+			return toks, nil
+		}
+		if err != nil {
+			return toks, err
+		}
+		toks = append(toks, tok.Kind().String()+":"+tok.String())
+	}
+}
+
+func TestDecoderReadToken(t *testing.T) {
+	d := NewDecoder(strings.NewReader(` {"a": [1, -2.5e3, true], "b!": {}, "c": null}
+		"x" [] `))
+	toks, err := readAll(d)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{
+		"{:{", "string:a", "[:[", "number:1", "number:-2.5e3", "true:true", "]:]",
+		"string:b!", "{:{", "}:}", "string:c", "null:null", "}:}",
+		"string:x", "[:[", "]:]",
+	}
+	if strings.Join(toks, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v, got %v", expected, toks)
+	}
+	if _, err := d.ReadToken(); err != io.EOF {
+		t.Errorf("Expected io.EOF again, got %v", err)
+	}
+}
+
+func TestDecoderOffsets(t *testing.T) {
+	data := []byte(`{"key": "value"}`)
+	d := NewDecoderBytes(data)
+	var offsets []int64
+	for {
+		tok, err := d.ReadToken()
+		if err != nil {
+			break
+		}
+		offsets = append(offsets, tok.Offset())
+		if string(data[tok.Offset():d.InputOffset()]) != string(tok.Raw()) {
+			t.Errorf("Expected the token at its offset, got %q", data[tok.Offset():d.InputOffset()])
+		}
+	}
+	if len(offsets) != 4 || offsets[1] != 1 || offsets[2] != 8 || offsets[3] != 15 {
+		t.Errorf("Expected offsets [0 1 8 15], got %v", offsets)
+	}
+}
+
+func TestDecoderSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		offset   int64
+		expected string
+	}{
+		{"MissingColon", `{"a" 1}`, 5, "':'"},
+		{"MissingComma", `[1 2]`, 3, "',' or ']'"},
+		{"NumberName", `{1:2}`, 1, "string key or '}'"},
+		{"NameAfterComma", `{"a":1,2:3}`, 7, "string key"},
+		{"Mismatched", `[}`, 1, "value or ']'"},
+		{"MismatchedAfterValue", `{"a":1]`, 6, "',' or '}'"},
+		{"TopLevelEnd", `1 ]`, 2, "value"},
+		{"TopLevelComma", `1, 2`, 1, "value"},
+		{"BadEscape", `["\x"]`, 1, "valid escape sequence"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoderBytes([]byte(tt.input))
+			_, err := readAll(d)
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("Expected SyntaxError, got %v", err)
+			}
+			if se.Offset != tt.offset || se.Expected != tt.expected {
+				t.Errorf("Expected %s at %d, got %s at %d", tt.expected, tt.offset, se.Expected, se.Offset)
+			}
+			if _, again := d.ReadToken(); again != err {
+				t.Errorf("Expected the same error again, got %v", again)
+			}
+		})
+	}
+
+	for _, input := range []string{`[1,2`, `{"a":`, `{"a"`} {
+		if _, err := readAll(NewDecoderBytes([]byte(input))); err == nil {
+			t.Errorf("%s: expected an error for truncated input", input)
+		}
+	}
+}
+
+func TestDecoderReadValue(t *testing.T) {
+	d := NewDecoderBytes([]byte(`{"skip": {"x": [1, {"y": 2}]}, "keep": "v", "raw": [1, 2]} 7`))
+	if _, err := d.ReadToken(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.ReadValue(); err == nil {
+		t.Error("Expected an error reading a name as a value")
+	}
+
+	d = NewDecoderBytes([]byte(`{"skip": {"x": [1, {"y": 2}]}, "keep": "v"} 7`))
+	d.ReadToken()
+	d.ReadToken()
+	if err := d.SkipValue(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if k := d.PeekKind(); k != '"' {
+		t.Errorf("Expected a string next, got %v", k)
+	}
+	name, _ := d.ReadToken()
+	v, err := d.ReadValue()
+	if name.String() != "keep" || string(v) != `"v"` || err != nil {
+		t.Errorf("Expected keep: \"v\", got %s: %s, %v", name, v, err)
+	}
+	if d.StackDepth() != 1 {
+		t.Errorf("Expected depth 1, got %d", d.StackDepth())
+	}
+	if _, err := d.ReadValue(); err == nil {
+		t.Error("Expected an error reading the end of an object as a value")
+	}
+
+	d = NewDecoderBytes([]byte(`[{"a":1 "b":2}]`))
+	d.ReadToken()
+	if _, err := d.ReadValue(); err == nil {
+		t.Error("Expected the grammar inside a value to be checked")
+	}
+}
@@ -0,0 +1,134 @@
+package jsontext
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+var errInvalidToken = errors.New("invalid token")
+
+// flushSize is how much output an Encoder buffers within a value before
+// writing it out.
+const flushSize = 4096
+
+// An Encoder writes a sequence of JSON values as tokens, adding the commas
+// and colons between them and a newline after each top-level value. Tokens
+// that would break the grammar, such as a number where an object name is
+// due, fail without writing anything.
+type Encoder struct {
+	w       io.Writer
+	buf     []byte
+	written int64
+	err     error
+
+	// Grammar state, as in Decoder
+	stack   []Kind
+	first   bool
+	inValue bool
+}
+
+// NewEncoder returns an Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// WriteToken writes the next token. Top-level values are written to the
+// underlying writer as soon as they are complete.
+func (e *Encoder) WriteToken(t Token) error {
+	k := t.Kind()
+	if k == 0 {
+		return errInvalidToken
+	}
+	return e.write(k, t.raw, true)
+}
+
+// WriteValue writes a whole value, which is checked to be valid JSON and
+// written as is, less surrounding whitespace. In an object where a name is
+// due, the value must be a string and is written as the name.
+func (e *Encoder) WriteValue(v []byte) error {
+	v = bytes.Trim(v, " \t\r\n")
+	s := scanner.New()
+	offset, expected := s.Check(v)
+	s.Release()
+	if offset >= 0 {
+		return &SyntaxError{Offset: int64(offset), Expected: expected}
+	}
+	return e.write(kindOf(v[0]), v, false)
+}
+
+// write writes raw, the encoding of a token or value of kind k. An object
+// or array written whole has open false.
+func (e *Encoder) write(k Kind, raw []byte, open bool) error {
+	if e.err != nil {
+		return e.err
+	}
+
+	if n := len(e.stack); n > 0 {
+		top := e.stack[n-1]
+		closing := k == '}' || k == ']'
+		switch {
+		case closing && (e.inValue || (top == '{') != (k == '}')):
+			return errors.New("unexpected '" + string(k) + "'")
+		case top == '{' && !e.inValue && !closing && k != '"':
+			return errors.New("expected string for object name, got " + k.String())
+		}
+		switch {
+		case e.inValue:
+			e.buf = append(e.buf, ':')
+		case !e.first && !closing:
+			e.buf = append(e.buf, ',')
+		}
+	} else if k == '}' || k == ']' {
+		return errors.New("unexpected '" + string(k) + "'")
+	}
+	e.buf = append(e.buf, raw...)
+
+	switch {
+	case open && (k == '{' || k == '['):
+		e.stack = append(e.stack, k)
+		e.first, e.inValue = true, false
+	case k == '}' || k == ']':
+		e.stack = e.stack[:len(e.stack)-1]
+		e.first, e.inValue = false, false
+	default:
+		if n := len(e.stack); n > 0 && e.stack[n-1] == '{' && !e.inValue {
+			// A name; its value comes next
+			e.first, e.inValue = false, true
+		} else {
+			e.first, e.inValue = false, false
+		}
+	}
+
+	if len(e.stack) == 0 {
+		e.buf = append(e.buf, '\n')
+		return e.flush()
+	}
+	if len(e.buf) >= flushSize {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *Encoder) flush() error {
+	n, err := e.w.Write(e.buf)
+	e.written += int64(n)
+	e.buf = e.buf[:0]
+	if err != nil {
+		e.err = err
+	}
+	return err
+}
+
+// StackDepth returns how many objects and arrays are open.
+func (e *Encoder) StackDepth() int {
+	return len(e.stack)
+}
+
+// OutputOffset returns how many bytes have been encoded so far, including
+// those not yet written to the underlying writer.
+func (e *Encoder) OutputOffset() int64 {
+	return e.written + int64(len(e.buf))
+}
@@ -0,0 +1,114 @@
+package jsontext
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEncoderWriteToken(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	for _, tok := range []Token{
+		BeginObject, String("a"), BeginArray, Int(1), Float(2.5), Null, EndArray,
+		String("b"), BeginObject, EndObject, EndObject,
+		String("top"), Bool(false),
+	} {
+		if err := e.WriteToken(tok); err != nil {
+			t.Fatalf("Unexpected error writing %s: %v", tok, err)
+		}
+	}
+	expected := "{\"a\":[1,2.5,null],\"b\":{}}\n\"top\"\nfalse\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+	if e.OutputOffset() != int64(len(expected)) {
+		t.Errorf("Expected offset %d, got %d", len(expected), e.OutputOffset())
+	}
+}
+
+func TestEncoderGrammar(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []Token
+	}{
+		{"NumberName", []Token{BeginObject, Int(1)}},
+		{"ObjectName", []Token{BeginObject, BeginObject}},
+		{"MissingValue", []Token{BeginObject, String("a"), EndObject}},
+		{"Mismatched", []Token{BeginArray, EndObject}},
+		{"TopLevelEnd", []Token{EndArray}},
+		{"NaN", []Token{BeginArray, Float(math.NaN())}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEncoder(io.Discard)
+			var err error
+			for _, tok := range tt.tokens {
+				if err = e.WriteToken(tok); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	// A rejected token leaves the output as it was
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.WriteToken(BeginArray)
+	e.WriteToken(Int(1))
+	e.WriteToken(EndObject)
+	e.WriteToken(EndArray)
+	if buf.String() != "[1]\n" {
+		t.Errorf("Expected [1], got %q", buf.String())
+	}
+}
+
+func TestEncoderWriteValue(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.WriteToken(BeginObject)
+	if err := e.WriteValue([]byte(` "raw" `)); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.WriteValue([]byte(`{"x": [1, 2]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if e.StackDepth() != 1 {
+		t.Errorf("Expected a whole value not to open the object, got depth %d", e.StackDepth())
+	}
+	if err := e.WriteValue([]byte(`{"x": [1, 2}`)); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+	e.WriteToken(EndObject)
+	if expected := "{\"raw\":{\"x\": [1, 2]}}\n"; buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := `{"users": [{"id": 1, "name": "Anné", "tags": []}, {"id": 2, "ok": true}], "n": null}`
+	d := NewDecoderBytes([]byte(input))
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	for {
+		tok, err := d.ReadToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := e.WriteToken(tok); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := strings.NewReplacer(": ", ":", ", ", ",").Replace(input) + "\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, buf.String())
+	}
+}
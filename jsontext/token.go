@@ -0,0 +1,190 @@
+// Package jsontext reads and writes JSON as a stream of tokens, without
+// mapping it to Go values. A Decoder splits its input into tokens, each
+// with the bytes it was written as and its offset; an Encoder writes
+// tokens back out, adding the commas and colons between them. Both check
+// the JSON grammar as they go.
+//
+// It shares its tokenizer and encoding with simdjson.Marshal and Unmarshal
+// and serves programs that filter or transform documents too large or too
+// loosely structured to decode into Go values. The API in this package is
+// stable.
+package jsontext
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/jsonenc"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/parser"
+)
+
+// Kind is the kind of a token or value, named by its first byte: 'n' for
+// null, 'f' false, 't' true, '"' a string, '0' a number, '{' and '}' the
+// bounds of an object and '[' and ']' those of an array. The zero Kind is
+// invalid.
+type Kind byte
+
+func (k Kind) String() string {
+	switch k {
+	case 'n':
+		return "null"
+	case 'f':
+		return "false"
+	case 't':
+		return "true"
+	case '"':
+		return "string"
+	case '0':
+		return "number"
+	case '{':
+		return "{"
+	case '}':
+		return "}"
+	case '[':
+		return "["
+	case ']':
+		return "]"
+	}
+	return "invalid"
+}
+
+// kindOf returns the kind of the value or delimiter starting with c.
+func kindOf(c byte) Kind {
+	switch c {
+	case 'n', 'f', 't', '"', '{', '}', '[', ']':
+		return Kind(c)
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return '0'
+	}
+	return 0
+}
+
+// A Token is a JSON literal, string, number or object or array delimiter.
+// Tokens read by a Decoder keep the bytes they were written as, which
+// alias the Decoder's input; tokens made with String, Int, Uint and Float
+// hold their encoding. The zero Token is invalid.
+type Token struct {
+	raw    []byte
+	offset int64 // in the Decoder's input, -1 for tokens made here
+}
+
+var (
+	Null        = Token{raw: []byte("null"), offset: -1}
+	False       = Token{raw: []byte("false"), offset: -1}
+	True        = Token{raw: []byte("true"), offset: -1}
+	BeginObject = Token{raw: []byte("{"), offset: -1}
+	EndObject   = Token{raw: []byte("}"), offset: -1}
+	BeginArray  = Token{raw: []byte("["), offset: -1}
+	EndArray    = Token{raw: []byte("]"), offset: -1}
+)
+
+var errKind = errors.New("token is of another kind")
+
+// Bool returns True or False.
+func Bool(b bool) Token {
+	if b {
+		return True
+	}
+	return False
+}
+
+// String returns a string token for s, escaped as simdjson.Marshal does
+// without HTML escaping.
+func String(s string) Token {
+	raw := make([]byte, 0, len(s)+2)
+	raw = append(raw, '"')
+	raw = jsonenc.AppendString(raw, s, false)
+	return Token{raw: append(raw, '"'), offset: -1}
+}
+
+// Int returns a number token for n.
+func Int(n int64) Token {
+	return Token{raw: strconv.AppendInt(nil, n, 10), offset: -1}
+}
+
+// Uint returns a number token for n.
+func Uint(n uint64) Token {
+	return Token{raw: strconv.AppendUint(nil, n, 10), offset: -1}
+}
+
+// Float returns a number token for f, formatted as simdjson.Marshal
+// formats a float64. NaN and infinities have no JSON form; writing their
+// token fails.
+func Float(f float64) Token {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Token{offset: -1}
+	}
+	return Token{raw: jsonenc.AppendFloat(nil, f, 64), offset: -1}
+}
+
+// Kind returns the kind of t.
+func (t Token) Kind() Kind {
+	if len(t.raw) == 0 {
+		return 0
+	}
+	return kindOf(t.raw[0])
+}
+
+// Raw returns t as written in JSON: a string with its quotes and escapes,
+// a number with its digits as given. It must not be modified.
+func (t Token) Raw() []byte {
+	return t.raw
+}
+
+// Offset returns the byte offset of t in the input of the Decoder that
+// read it, or -1 if t was not read by a Decoder.
+func (t Token) Offset() int64 {
+	return t.offset
+}
+
+// String returns the unescaped content of a string token, and the raw text
+// of any other token, so that a Token prints readably.
+func (t Token) String() string {
+	if t.Kind() != '"' {
+		return string(t.raw)
+	}
+	s, err := parser.AppendUnescaped(nil, t.raw[1:len(t.raw)-1])
+	if err != nil {
+		// A Decoder checks escapes before returning a string token
+		return string(t.raw)
+	}
+	return string(s)
+}
+
+// Bool returns the value of a true or false token.
+func (t Token) Bool() (bool, error) {
+	switch t.Kind() {
+	case 't':
+		return true, nil
+	case 'f':
+		return false, nil
+	}
+	return false, errKind
+}
+
+// Int returns the value of a number token that is an integer in the range
+// of int64.
+func (t Token) Int() (int64, error) {
+	if t.Kind() != '0' {
+		return 0, errKind
+	}
+	return strconv.ParseInt(string(t.raw), 10, 64)
+}
+
+// Uint returns the value of a number token that is an integer in the range
+// of uint64.
+func (t Token) Uint() (uint64, error) {
+	if t.Kind() != '0' {
+		return 0, errKind
+	}
+	return strconv.ParseUint(string(t.raw), 10, 64)
+}
+
+// Float returns the value of a number token as the nearest float64.
+func (t Token) Float() (float64, error) {
+	if t.Kind() != '0' {
+		return 0, errKind
+	}
+	return strconv.ParseFloat(string(t.raw), 64)
+}
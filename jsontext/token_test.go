@@ -0,0 +1,78 @@
+package jsontext
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTokenConstructors(t *testing.T) {
+	tests := []struct {
+		name string
+		tok  Token
+		kind Kind
+		raw  string
+	}{
+		{"Null", Null, 'n', "null"},
+		{"True", Bool(true), 't', "true"},
+		{"False", Bool(false), 'f', "false"},
+		{"String", String("a\"b\n<é>"), '"', `"a\"b\n<é>"`},
+		{"Int", Int(-42), '0', "-42"},
+		{"Uint", Uint(math.MaxUint64), '0', "18446744073709551615"},
+		{"Float", Float(1e21), '0', "1e+21"},
+		{"FloatSmall", Float(1e-7), '0', "1e-7"},
+		{"BeginObject", BeginObject, '{', "{"},
+		{"EndArray", EndArray, ']', "]"},
+		{"NaN", Float(math.NaN()), 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.tok.Kind() != tt.kind {
+				t.Errorf("Expected kind %v, got %v", tt.kind, tt.tok.Kind())
+			}
+			if string(tt.tok.Raw()) != tt.raw {
+				t.Errorf("Expected %s, got %s", tt.raw, tt.tok.Raw())
+			}
+			if tt.tok.Offset() != -1 {
+				t.Errorf("Expected offset -1, got %d", tt.tok.Offset())
+			}
+		})
+	}
+}
+
+func TestTokenValues(t *testing.T) {
+	if s := String("tab\there").String(); s != "tab\there" {
+		t.Errorf("Expected unescaped string, got %q", s)
+	}
+	if s := Int(7).String(); s != "7" {
+		t.Errorf("Expected 7, got %s", s)
+	}
+	if b, err := True.Bool(); !b || err != nil {
+		t.Errorf("Expected true, got %v, %v", b, err)
+	}
+	if n, err := Int(-3).Int(); n != -3 || err != nil {
+		t.Errorf("Expected -3, got %d, %v", n, err)
+	}
+	if _, err := Float(1.5).Int(); err == nil {
+		t.Error("Expected an error for a fraction as int")
+	}
+	if _, err := Int(-1).Uint(); err == nil {
+		t.Error("Expected an error for a negative uint")
+	}
+	if f, err := Float(0.1).Float(); f != 0.1 || err != nil {
+		t.Errorf("Expected 0.1, got %v, %v", f, err)
+	}
+	if _, err := String("1").Int(); err != errKind {
+		t.Errorf("Expected errKind for a string, got %v", err)
+	}
+	if _, err := Null.Bool(); err != errKind {
+		t.Errorf("Expected errKind for null, got %v", err)
+	}
+}
+
+func TestKindString(t *testing.T) {
+	for k, s := range map[Kind]string{'n': "null", '"': "string", '0': "number", '{': "{", 0: "invalid"} {
+		if k.String() != s {
+			t.Errorf("Kind %q: expected %s, got %s", byte(k), s, k.String())
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package jsontext
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+// A Rule changes the members of the objects Transform copies. Rules name
+// members by path: the keys leading to the member through nested objects,
+// separated by dots, where * matches any key. Arrays are transparent, so
+// "items.price" is the price member of every object in an items array.
+// Paths use the names members had in the input, before any Rename.
+type Rule struct {
+	path    []string
+	drop    bool
+	keep    map[string]bool
+	rename  string
+	rewrite func(value []byte) ([]byte, error)
+}
+
+func newRule(path string) Rule {
+	var keys []string
+	if path != "" {
+		keys = strings.Split(path, ".")
+	}
+	return Rule{path: keys}
+}
+
+// Drop removes the member at path.
+func Drop(path string) Rule {
+	r := newRule(path)
+	r.drop = true
+	return r
+}
+
+// Keep removes every member of the object at path but those named in
+// keys. The empty path is the top-level object.
+func Keep(path string, keys ...string) Rule {
+	r := newRule(path)
+	r.keep = make(map[string]bool, len(keys))
+	for _, k := range keys {
+		r.keep[k] = true
+	}
+	return r
+}
+
+// Rename gives the member at path the name name.
+func Rename(path, name string) Rule {
+	r := newRule(path)
+	r.rename = name
+	return r
+}
+
+// Rewrite replaces the value of the member at path with what fn returns
+// for it. fn gets the value as written and must return valid JSON; either
+// may be a whole object or array.
+func Rewrite(path string, fn func(value []byte) ([]byte, error)) Rule {
+	r := newRule(path)
+	r.rewrite = fn
+	return r
+}
+
+// matches reports whether the rule's path is path.
+func (r *Rule) matches(path []string) bool {
+	if len(r.path) != len(path) {
+		return false
+	}
+	for i, k := range r.path {
+		if k != "*" && k != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Transform copies the JSON values read from r to w, applying rules to
+// the objects in them. A member dropped by Drop or Keep is removed; of the
+// rest, the first matching Rename renames a member and the first matching
+// Rewrite replaces its value, and rules apply inside values that are not
+// rewritten.
+//
+// r may hold any number of values, such as NDJSON records. Each is read
+// and tokenized by itself, so memory is bounded by the largest top-level
+// value rather than the stream, and written compactly, followed by a
+// newline, as soon as it is done. Values before a syntax error are
+// written; the error's offset is in the stream.
+func Transform(r io.Reader, w io.Writer, rules ...Rule) error {
+	t := &transformer{rules: rules}
+	f := &framer{r: bufio.NewReader(r)}
+	e := NewEncoder(w)
+	for {
+		start := f.offset
+		value, err := f.next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		d := NewDecoderBytes(value)
+		for d.PeekKind() != 0 {
+			if err := t.value(d, e); err != nil {
+				return offsetError(err, start)
+			}
+		}
+		if _, err := d.ReadToken(); err != io.EOF {
+			return offsetError(err, start)
+		}
+	}
+}
+
+// offsetError moves a SyntaxError from a value to the stream it starts at
+// offset in.
+func offsetError(err error, offset int64) error {
+	var se *SyntaxError
+	if errors.As(err, &se) {
+		return &SyntaxError{Offset: offset + se.Offset, Expected: se.Expected}
+	}
+	return err
+}
+
+// transformer copies values token by token, tracking the path of the
+// member being copied.
+type transformer struct {
+	rules []Rule
+	path  []string
+}
+
+// value copies the next value from d to e.
+func (t *transformer) value(d *Decoder, e *Encoder) error {
+	tok, err := d.ReadToken()
+	if err != nil {
+		return err
+	}
+	if err := e.WriteToken(tok); err != nil {
+		return err
+	}
+	switch tok.Kind() {
+	case '{':
+		return t.object(d, e)
+	case '[':
+		for {
+			switch d.PeekKind() {
+			case ']':
+				return t.end(d, e)
+			case 0:
+				return t.end(d, e) // the error or end of input
+			}
+			if err := t.value(d, e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// object copies the members of the object whose '{' was just copied.
+func (t *transformer) object(d *Decoder, e *Encoder) error {
+	var keep map[string]bool
+	for i := range t.rules {
+		if t.rules[i].keep != nil && t.rules[i].matches(t.path) {
+			keep = t.rules[i].keep
+			break
+		}
+	}
+
+	for {
+		if k := d.PeekKind(); k == '}' || k == 0 {
+			return t.end(d, e)
+		}
+		name, err := d.ReadToken()
+		if err != nil {
+			return err
+		}
+		key := name.String()
+		t.path = append(t.path, key)
+		err = t.member(d, e, name, keep != nil && !keep[key])
+		t.path = t.path[:len(t.path)-1]
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// member copies the value of the member at t.path, whose name was just
+// read, unless drop is set or a Drop rule matches.
+func (t *transformer) member(d *Decoder, e *Encoder, name Token, drop bool) error {
+	var rename *Rule
+	var rewrite *Rule
+	for i := range t.rules {
+		r := &t.rules[i]
+		if r.keep != nil || !r.matches(t.path) {
+			continue
+		}
+		switch {
+		case r.drop:
+			drop = true
+		case r.rewrite != nil && rewrite == nil:
+			rewrite = r
+		case r.rename != "" && rename == nil:
+			rename = r
+		}
+	}
+	if drop {
+		return d.SkipValue()
+	}
+
+	if rename != nil {
+		name = String(rename.rename)
+	}
+	if err := e.WriteToken(name); err != nil {
+		return err
+	}
+	if rewrite == nil {
+		return t.value(d, e)
+	}
+	v, err := d.ReadValue()
+	if err != nil {
+		return err
+	}
+	if v, err = rewrite.rewrite(v); err != nil {
+		return err
+	}
+	return e.WriteValue(v)
+}
+
+// end copies the token closing an object or array.
+func (t *transformer) end(d *Decoder, e *Encoder) error {
+	tok, err := d.ReadToken()
+	if err == io.EOF {
+		err = errUnexpectedEnd
+	}
+	if err != nil {
+		return err
+	}
+	return e.WriteToken(tok)
+}
+
+// A framer splits a stream into pieces that each hold one or more whole
+// top-level values, reading no further than needed.
+type framer struct {
+	r      *bufio.Reader
+	buf    []byte
+	offset int64 // of the next byte in the stream
+}
+
+// next returns the next piece, which is only valid until the next call,
+// or io.EOF at the end of the stream.
+func (f *framer) next() ([]byte, error) {
+	f.buf = f.buf[:0]
+	depth := 0
+	inString, escaped, content := false, false, false
+	for {
+		c, err := f.r.ReadByte()
+		if err == io.EOF {
+			if !content {
+				return nil, io.EOF
+			}
+			// The Decoder reports anything left unfinished
+			return f.buf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		f.offset++
+		f.buf = append(f.buf, c)
+
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+				if depth == 0 {
+					return f.buf, nil
+				}
+			}
+		case c == '"':
+			inString, content = true, true
+		case c == '{' || c == '[':
+			depth++
+			content = true
+		case c == '}' || c == ']':
+			if depth--; depth <= 0 {
+				return f.buf, nil
+			}
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			if depth == 0 && content {
+				// The end of a number or literal
+				return f.buf, nil
+			}
+		default:
+			content = true
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package jsontext
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestTransform(t *testing.T) {
+	upper := func(v []byte) ([]byte, error) {
+		return bytes.ToUpper(v), nil
+	}
+	tests := []struct {
+		name     string
+		input    string
+		rules    []Rule
+		expected string
+	}{
+		{"copy", ` {"a": [1, "x", {"b": null}]} `, nil, `{"a":[1,"x",{"b":null}]}` + "\n"},
+		{"drop", `{"a": 1, "b": {"c": 2, "d": 3}}`, []Rule{Drop("b.c")}, `{"a":1,"b":{"d":3}}` + "\n"},
+		{"drop object", `{"a": 1, "b": {"c": 2}}`, []Rule{Drop("b")}, `{"a":1}` + "\n"},
+		{"keep", `{"id": 1, "name": "a", "secret": "s", "meta": {"x": 1, "y": 2}}`, []Rule{Keep("", "id", "meta"), Keep("meta", "y")}, `{"id":1,"meta":{"y":2}}` + "\n"},
+		{"rename", `{"user_id": 7, "tags": [{"tag_name": "a"}]}`, []Rule{Rename("user_id", "userId"), Rename("tags.tag_name", "name")}, `{"userId":7,"tags":[{"name":"a"}]}` + "\n"},
+		{"rewrite", `{"email": "a@b.c", "list": [1, 2]}`, []Rule{Rewrite("email", upper), Rewrite("list", func([]byte) ([]byte, error) { return []byte(`[]`), nil })}, `{"email":"A@B.C","list":[]}` + "\n"},
+		{"rename and rewrite", `{"a": "x"}`, []Rule{Rename("a", "b"), Rewrite("a", upper)}, `{"b":"X"}` + "\n"},
+		{"wildcard", `{"a": {"token": 1, "id": 2}, "b": {"token": 3}}`, []Rule{Drop("*.token")}, `{"a":{"id":2},"b":{}}` + "\n"},
+		{"through arrays", `[{"a": 1, "b": 2}, [{"a": 3}]]`, []Rule{Drop("a")}, `[{"b":2},[{}]]` + "\n"},
+		{"escaped name", `{"\u0061": 1, "b": 2}`, []Rule{Drop("a")}, `{"b":2}` + "\n"},
+		{"ndjson", "{\"a\":1,\"p\":2}\n{\"a\":3}\n\n7 \"s\" true\n", []Rule{Drop("p")}, "{\"a\":1}\n{\"a\":3}\n7\n\"s\"\ntrue\n"},
+		{"brackets in strings", `{"a": "}]\"{"} ["["]`, nil, `{"a":"}]\"{"}` + "\n" + `["["]` + "\n"},
+		{"empty", "  \n", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Transform(iotest.OneByteReader(strings.NewReader(tt.input)), &buf, tt.rules...); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	var buf bytes.Buffer
+	err := Transform(strings.NewReader("{\"a\":1}\n{\"a\" 2}"), &buf)
+	var se *SyntaxError
+	if !errors.As(err, &se) || se.Offset != 13 {
+		t.Errorf("Expected a SyntaxError at offset 13, got %v", err)
+	}
+	if buf.String() != "{\"a\":1}\n" {
+		t.Errorf("Expected the first value written, got %q", buf.String())
+	}
+
+	for _, input := range []string{`{"a": [1, 2`, `{"a": 1}}`, `[1, 2}`, `"abc`} {
+		if err := Transform(strings.NewReader(input), &buf); err == nil {
+			t.Errorf("Expected an error for %s", input)
+		}
+	}
+
+	errRewrite := errors.New("rewrite failed")
+	rules := []Rule{Rewrite("a", func([]byte) ([]byte, error) { return nil, errRewrite })}
+	if err := Transform(strings.NewReader(`{"a": 1}`), &buf, rules...); err != errRewrite {
+		t.Errorf("Expected the Rewrite error, got %v", err)
+	}
+	rules = []Rule{Rewrite("a", func([]byte) ([]byte, error) { return []byte(`{`), nil })}
+	if err := Transform(strings.NewReader(`{"a": 1}`), &buf, rules...); err == nil {
+		t.Error("Expected an error for an invalid rewritten value")
+	}
+}
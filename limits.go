@@ -0,0 +1,42 @@
+package simdjson
+
+import "strconv"
+
+// Limits bounds the documents a Parser accepts, as a defence against
+// hostile input such as huge strings or objects with millions of keys.
+// They are checked while the document is tokenized, before anything is
+// decoded, and the first one exceeded fails the call with a *LimitError.
+// Zero fields are not checked; nesting is always limited to 10000 levels.
+type Limits struct {
+	// MaxBytes is the longest document accepted, in bytes.
+	MaxBytes int
+
+	// MaxTokens is the most tokens a document may have. Every string,
+	// number, literal, bracket, colon and comma is a token, so an array
+	// of n numbers has 2n+1.
+	MaxTokens int
+
+	// MaxObjectKeys is the most members any one object may have.
+	MaxObjectKeys int
+
+	// MaxStringLen is the longest string or key accepted, in bytes as
+	// written between the quotes, escape sequences included.
+	MaxStringLen int
+
+	// MaxDepth is the deepest nesting of objects and arrays accepted. It
+	// can only lower the built-in limit of 10000.
+	MaxDepth int
+}
+
+// A LimitError reports that a document exceeds one of the Limits of the
+// Parser decoding it.
+type LimitError struct {
+	Limit  string // name of the Limits field, such as "MaxStringLen"
+	Max    int    // its value
+	Offset int    // byte offset in the document where it was exceeded
+}
+
+func (e *LimitError) Error() string {
+	return "document exceeds " + e.Limit + " of " + strconv.Itoa(e.Max) +
+		" at offset " + strconv.Itoa(e.Offset)
+}
@@ -0,0 +1,78 @@
+package simdjson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	limits := Limits{
+		MaxBytes:      200,
+		MaxTokens:     40,
+		MaxObjectKeys: 3,
+		MaxStringLen:  10,
+		MaxDepth:      4,
+	}
+	tests := []struct {
+		name   string
+		input  string
+		limit  string
+		offset int
+	}{
+		{"MaxBytes", `"` + strings.Repeat(" ", 200) + `"`, "MaxBytes", 200},
+		{"MaxTokens", `[` + strings.Repeat(`1,`, 19) + `1]`, "MaxTokens", 40},
+		{"MaxObjectKeys", `{"a":1,"b":{"c":2,"d":3,"e":4},"f":5,"g":6}`, "MaxObjectKeys", 37},
+		{"MaxStringLen", `{"name":"abcdefghijk"}`, "MaxStringLen", 8},
+		{"MaxStringLenKey", `{"abcdefghijk":1}`, "MaxStringLen", 1},
+		{"MaxDepth", `[[[[[1]]]]]`, "MaxDepth", 4},
+	}
+
+	p := NewParser(&Options{CopyStrings: true, Limits: limits})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			err := p.Unmarshal([]byte(tt.input), &v)
+			var le *LimitError
+			if !errors.As(err, &le) {
+				t.Fatalf("Expected LimitError, got %v", err)
+			}
+			if le.Limit != tt.limit || le.Offset != tt.offset {
+				t.Errorf("Expected %s at offset %d, got %s at offset %d", tt.limit, tt.offset, le.Limit, le.Offset)
+			}
+			if _, err := p.Parse([]byte(tt.input)); !errors.As(err, &le) {
+				t.Errorf("Expected Parse to fail with LimitError, got %v", err)
+			}
+			if err := UnmarshalWithOptions([]byte(tt.input), &v, &Options{Limits: limits}); !errors.As(err, &le) {
+				t.Errorf("Expected UnmarshalWithOptions to fail with LimitError, got %v", err)
+			}
+			if ValidWithOptions([]byte(tt.input), &Options{Limits: limits}) {
+				t.Error("Expected ValidWithOptions to reject the document")
+			}
+		})
+	}
+
+	t.Run("WithinLimits", func(t *testing.T) {
+		// At the string, key count and depth limits
+		input := `{"abcdefghij":[[[1]]],"b":"0123456789","c":{"x":1,"y":2,"z":3}}`
+		var v interface{}
+		if err := p.Unmarshal([]byte(input), &v); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if !ValidWithOptions([]byte(input), &Options{Limits: limits}) {
+			t.Error("Expected ValidWithOptions to accept the document")
+		}
+		if err := Unmarshal([]byte(tests[0].input), &v); err != nil {
+			t.Errorf("Expected Unmarshal to have no limits, got %v", err)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var v interface{}
+		err := p.Unmarshal([]byte(tests[3].input), &v)
+		expected := "document exceeds MaxStringLen of 10 at offset 8"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %s, got %v", expected, err)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package simdjson
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// MergePatch applies the JSON merge patch (RFC 7386) patch to doc and
+// returns the result. An object patch sets the members it names and
+// removes those it sets to null, recursively; any other patch replaces the
+// document. Values the patch does not touch are copied from doc as written
+// rather than decoded and re-encoded.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	s := scanner.New()
+	defer s.Release()
+	p, err := parseDocument(s, patch)
+	if err != nil {
+		return nil, err
+	}
+	defer p.release()
+
+	s2 := scanner.New()
+	defer s2.Release()
+	d, err := parseDocument(s2, doc)
+	if err != nil {
+		return nil, err
+	}
+	defer d.release()
+
+	return mergePatch(make([]byte, 0, len(doc)+len(patch)), d, 0, p, 0), nil
+}
+
+// mergePatch appends the result of applying the patch value at ppos to the
+// document value at dpos, or to nothing if d is nil.
+func mergePatch(dst []byte, d *document, dpos int, p *document, ppos int) []byte {
+	if p.kind(ppos) != scanner.TokenObjectBegin {
+		return append(dst, p.raw(ppos)...)
+	}
+	patched := p.keyIndex(ppos)
+
+	dst = append(dst, '{')
+	n := 0
+	member := func(key []byte) {
+		if n > 0 {
+			dst = append(dst, ',')
+		}
+		n++
+		dst = append(append(dst, key...), ':')
+	}
+
+	// Members of the document keep their order; the patch's new members
+	// follow in patch order
+	var existing map[string]bool
+	if d != nil && d.kind(dpos) == scanner.TokenObjectBegin {
+		existing = make(map[string]bool)
+		d.members(dpos, func(k, v int) {
+			key := string(d.str(k))
+			existing[key] = true
+			pv, ok := patched[key]
+			if !ok {
+				member(d.raw(k))
+				dst = append(dst, d.raw(v)...)
+			} else if p.kind(pv) != scanner.TokenNull {
+				member(d.raw(k))
+				dst = mergePatch(dst, d, v, p, pv)
+			}
+		})
+	}
+	p.members(ppos, func(k, v int) {
+		key := string(p.str(k))
+		if existing[key] || patched[key] != v || p.kind(v) == scanner.TokenNull {
+			return
+		}
+		member(p.raw(k))
+		dst = mergePatch(dst, nil, 0, p, v)
+	})
+	return append(dst, '}')
+}
+
+// CreateMergePatch returns a JSON merge patch (RFC 7386) that turns a into
+// b when applied with MergePatch. Members of b that equal those of a are
+// left out of the patch; values compare by content, so member order,
+// escapes and the spelling of numbers (1.0 and 1) do not matter.
+//
+// Merge patches cannot set a member to null, since null removes it, nor
+// add an object holding a null member: CreateMergePatch returns an error
+// if b has a null member that a lacks or holds another value for.
+func CreateMergePatch(a, b []byte) ([]byte, error) {
+	s := scanner.New()
+	defer s.Release()
+	da, err := parseDocument(s, a)
+	if err != nil {
+		return nil, err
+	}
+	defer da.release()
+
+	s2 := scanner.New()
+	defer s2.Release()
+	db, err := parseDocument(s2, b)
+	if err != nil {
+		return nil, err
+	}
+	defer db.release()
+
+	return createMergePatch(make([]byte, 0, 64), da, 0, db, 0)
+}
+
+// createMergePatch appends a patch from the value at apos in a to the
+// value at bpos in b.
+func createMergePatch(dst []byte, a *document, apos int, b *document, bpos int) ([]byte, error) {
+	if a.kind(apos) != scanner.TokenObjectBegin || b.kind(bpos) != scanner.TokenObjectBegin {
+		if err := checkNullMembers(b, bpos); err != nil {
+			return nil, err
+		}
+		return append(dst, b.raw(bpos)...), nil
+	}
+	ak, bk := a.keyIndex(apos), b.keyIndex(bpos)
+
+	dst = append(dst, '{')
+	n := 0
+	member := func(key []byte) {
+		if n > 0 {
+			dst = append(dst, ',')
+		}
+		n++
+		dst = append(append(dst, key...), ':')
+	}
+
+	a.members(apos, func(k, v int) {
+		key := string(a.str(k))
+		if _, ok := bk[key]; !ok && ak[key] == v {
+			member(a.raw(k))
+			dst = append(dst, "null"...)
+		}
+	})
+	var err error
+	b.members(bpos, func(k, v int) {
+		key := string(b.str(k))
+		if err != nil || bk[key] != v {
+			return
+		}
+		av, ok := ak[key]
+		if ok && equalValues(a, av, b, v) {
+			return
+		}
+		if b.kind(v) == scanner.TokenNull {
+			err = errNullMember(key)
+			return
+		}
+		member(b.raw(k))
+		if ok {
+			dst, err = createMergePatch(dst, a, av, b, v)
+			return
+		}
+		if err = checkNullMembers(b, v); err == nil {
+			dst = append(dst, b.raw(v)...)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, '}'), nil
+}
+
+// checkNullMembers returns an error if the value at pos in d is an object
+// with a null member, directly or in a nested object. A patch copying the
+// value would remove those members rather than set them. Arrays replace
+// the target as a whole, so the objects within them are not checked.
+func checkNullMembers(d *document, pos int) error {
+	if d.kind(pos) != scanner.TokenObjectBegin {
+		return nil
+	}
+	var err error
+	d.members(pos, func(k, v int) {
+		if err != nil {
+			return
+		}
+		if d.kind(v) == scanner.TokenNull {
+			err = errNullMember(string(d.str(k)))
+			return
+		}
+		err = checkNullMembers(d, v)
+	})
+	return err
+}
+
+func errNullMember(key string) error {
+	return errors.New("merge patch cannot set member " + strconv.Quote(key) + " to null")
+}
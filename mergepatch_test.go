@@ -0,0 +1,168 @@
+package simdjson
+
+import (
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+	}{
+		// RFC 7386, Appendix A
+		{"replace", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"remove", `{"a":"b"}`, `{"a":null}`, `{}`},
+		{"remove_one", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"array_replaces", `{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"replace_with_array", `{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{"nested", `{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{"array_of_objects", `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{"array_doc", `["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{"object_replaces_array", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"null_patch", `{"a":"foo"}`, `null`, `null`},
+		{"string_patch", `{"a":"foo"}`, `"bar"`, `"bar"`},
+		{"null_member_kept", `{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{"array_to_object", `[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{"deep_nulls_dropped", `{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+
+		{"untouched_copied_verbatim", "{ \"keep\" : [1, 2.50e1, \"x\"] , \"b\": 1}", `{"b":2}`,
+			`{"keep":[1, 2.50e1, "x"],"b":2}`},
+		{"escaped_keys", `{"a\u0062":1,"c":2}`, `{"ab":null,"c\u0064":3}`, `{"c":2,"c\u0064":3}`},
+		{"duplicate_patch_keys", `{"a":1}`, `{"a":2,"a":null}`, `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := MergePatch([]byte(`{"a":}`), []byte(`{}`)); err == nil {
+		t.Error("Expected error for invalid document")
+	}
+	if _, err := MergePatch([]byte(`{}`), []byte(`{"a"}`)); err == nil {
+		t.Error("Expected error for invalid patch")
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected string
+	}{
+		{"equal", `{"a":1,"b":[1,{"c":true}]}`, `{"b":[1.0,{"c":true}],"a":1}`, `{}`},
+		{"change", `{"a":1,"b":2}`, `{"a":1,"b":3}`, `{"b":3}`},
+		{"add_and_remove", `{"a":1,"b":2}`, `{"b":2,"c":"x"}`, `{"a":null,"c":"x"}`},
+		{"nested", `{"a":{"b":1,"c":2},"d":[1]}`, `{"a":{"b":1,"c":3},"d":[1,2]}`, `{"a":{"c":3},"d":[1,2]}`},
+		{"object_to_scalar", `{"a":{"b":1}}`, `{"a":"b"}`, `{"a":"b"}`},
+		{"not_objects", `[1,2]`, `[3]`, `[3]`},
+		{"escapes", `{"k":"é"}`, `{"\u006b":"\u00e9"}`, `{}`},
+		{"numbers", `{"n":100,"m":1}`, `{"n":1e2,"m":1.5}`, `{"m":1.5}`},
+		{"null_unchanged", `{"a":null,"b":{"c":null}}`, `{"a":null,"b":{"c":null},"d":1}`, `{"d":1}`},
+		{"null_in_array", `{}`, `{"a":[{"b":null},null]}`, `{"a":[{"b":null},null]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := CreateMergePatch([]byte(tt.a), []byte(tt.b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(patch) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, patch)
+			}
+
+			result, err := MergePatch([]byte(tt.a), patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want interface{}
+			Unmarshal(result, &got)
+			Unmarshal([]byte(tt.b), &want)
+			if !deepEqual(got, want) {
+				t.Errorf("Applying %s to %s: expected %s, got %s", patch, tt.a, tt.b, result)
+			}
+		})
+	}
+}
+
+func TestCreateMergePatchNullMembers(t *testing.T) {
+	// Applying a patch with these nulls would remove the members instead
+	// of reproducing b, so no patch is returned
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		key  string
+	}{
+		{"set_to_null", `{"a":1}`, `{"a":null}`, "a"},
+		{"added_null", `{}`, `{"a":null}`, "a"},
+		{"added_object", `{"x":1}`, `{"x":1,"a":{"b":1,"c":null}}`, "c"},
+		{"added_deep", `{}`, `{"a":{"b":{"c":null}}}`, "c"},
+		{"nested_added_object", `{"a":{"b":1}}`, `{"a":{"b":1,"c":{"d":null}}}`, "d"},
+		{"scalar_to_object", `{"a":"x"}`, `{"a":{"b":null}}`, "b"},
+		{"array_to_object", `[1]`, `{"a":null}`, "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := CreateMergePatch([]byte(tt.a), []byte(tt.b))
+			if err == nil {
+				result, _ := MergePatch([]byte(tt.a), patch)
+				t.Fatalf("Expected an error, got patch %s giving %s", patch, result)
+			}
+			if want := `merge patch cannot set member "` + tt.key + `" to null`; err.Error() != want {
+				t.Errorf("Expected %q, got %q", want, err)
+			}
+		})
+	}
+}
+
+func TestEqualNumbers(t *testing.T) {
+	tests := []struct {
+		x, y     string
+		expected bool
+	}{
+		{"1", "1", true},
+		{"1", "1.0", true},
+		{"100", "1e2", true},
+		{"100", "1E+2", true},
+		{"0.001", "1e-3", true},
+		{"-0", "0", true},
+		{"0.0e5", "0", true},
+		{"1", "-1", false},
+		{"12", "21", false},
+		{"9007199254740993", "9007199254740992", false},
+		{"1e400", "10e399", true},
+	}
+
+	for _, tt := range tests {
+		if got := equalNumbers([]byte(tt.x), []byte(tt.y)); got != tt.expected {
+			t.Errorf("equalNumbers(%s, %s): expected %v, got %v", tt.x, tt.y, tt.expected, got)
+		}
+	}
+}
+
+func BenchmarkMergePatch(b *testing.B) {
+	doc := []byte(createLargeArray(1000))
+	doc = append(append([]byte(`{"items":`), doc...), `,"version":1}`...)
+	patch := []byte(`{"version":2,"updated":"2024-01-01"}`)
+	b.SetBytes(int64(len(doc)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := MergePatch(doc, patch); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package simdjson
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// ParseProgress is what the hook set with SetProgressHook is told about a
+// document being tokenized.
+type ParseProgress struct {
+	Bytes   int           // bytes scanned so far
+	Total   int           // length of the document
+	Elapsed time.Duration // since decoding the document started
+}
+
+type progressHook struct {
+	every int
+	fn    func(ParseProgress)
+}
+
+var progress atomic.Pointer[progressHook]
+
+// SetProgressHook makes every document decoded or parsed from then on call
+// fn each time another every bytes of it have been tokenized, so that
+// long parses of large documents can report their progress. Progress is
+// checked every 64KB, which every is rounded up to. fn is called from the
+// goroutines doing the decoding, concurrently if they are, and must be
+// quick. A nil fn removes the hook.
+func SetProgressHook(every int, fn func(ParseProgress)) {
+	if fn == nil {
+		progress.Store(nil)
+		return
+	}
+	if every < scanner.CancelInterval {
+		every = scanner.CancelInterval
+	}
+	progress.Store(&progressHook{every: every, fn: fn})
+}
+
+// progressFor returns the Scanner.Progress func reporting on a document of
+// n bytes to the hook, or nil if there is none.
+func progressFor(n int) func(int) {
+	h := progress.Load()
+	if h == nil || n <= h.every {
+		return nil
+	}
+	start := time.Now()
+	next := h.every
+	return func(scanned int) {
+		if scanned < next {
+			return
+		}
+		next = scanned + h.every
+		h.fn(ParseProgress{Bytes: scanned, Total: n, Elapsed: time.Since(start)})
+	}
+}
+
+var (
+	metricsOn       atomic.Bool
+	metricDocuments atomic.Uint64
+	metricBytes     atomic.Uint64
+)
+
+// Metrics are counters of the work done since EnableMetrics turned them on.
+type Metrics struct {
+	Documents   uint64 // documents decoded or parsed
+	Bytes       uint64 // bytes of those documents
+	SIMDScans   uint64 // structural scans by a SIMD kernel
+	ScalarScans uint64 // structural scans by the scalar kernel
+}
+
+// EnableMetrics turns the counters read by ReadMetrics on or off. They are
+// off by default, as counting adds atomic updates shared by every
+// goroutine to each call.
+func EnableMetrics(on bool) {
+	metricsOn.Store(on)
+	scanner.CountScans.Store(on)
+}
+
+// ReadMetrics returns the current counters.
+func ReadMetrics() Metrics {
+	return Metrics{
+		Documents:   metricDocuments.Load(),
+		Bytes:       metricBytes.Load(),
+		SIMDScans:   scanner.SIMDScans.Load(),
+		ScalarScans: scanner.ScalarScans.Load(),
+	}
+}
+
+// String returns m as a JSON object.
+func (m Metrics) String() string {
+	return `{"documents":` + strconv.FormatUint(m.Documents, 10) +
+		`,"bytes":` + strconv.FormatUint(m.Bytes, 10) +
+		`,"simd_scans":` + strconv.FormatUint(m.SIMDScans, 10) +
+		`,"scalar_scans":` + strconv.FormatUint(m.ScalarScans, 10) + `}`
+}
+
+// MetricsVar returns a value whose String method formats the counters
+// current when it is called, which satisfies expvar.Var:
+//
+//	simdjson.EnableMetrics(true)
+//	expvar.Publish("simdjson", simdjson.MetricsVar())
+func MetricsVar() interface{ String() string } {
+	return metricsVar{}
+}
+
+type metricsVar struct{}
+
+func (metricsVar) String() string {
+	return ReadMetrics().String()
+}
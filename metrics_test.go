@@ -0,0 +1,84 @@
+package simdjson
+
+import (
+	"encoding/json"
+	"expvar"
+	"strings"
+	"testing"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+func TestProgressHook(t *testing.T) {
+	defer SetProgressHook(0, nil)
+
+	data := []byte(`[` + strings.Repeat(`"0123456789abcdef",`, 60000) + `0]`)
+	var calls []ParseProgress
+	SetProgressHook(256<<10, func(p ParseProgress) {
+		calls = append(calls, p)
+	})
+	var v []interface{}
+	if err := Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if expected := len(data) / (256 << 10); len(calls) != expected {
+		t.Fatalf("Expected %d progress calls for %d bytes, got %d", expected, len(data), len(calls))
+	}
+	for i, p := range calls {
+		if p.Bytes < (i+1)*256<<10 || p.Bytes >= (i+1)*256<<10+scanner.CancelInterval || p.Total != len(data) {
+			t.Errorf("Unexpected progress %+v at call %d", p, i)
+		}
+		if i > 0 && p.Elapsed < calls[i-1].Elapsed {
+			t.Errorf("Expected elapsed time to grow, got %v after %v", p.Elapsed, calls[i-1].Elapsed)
+		}
+	}
+
+	// Small documents and removed hooks are not reported
+	calls = nil
+	Unmarshal([]byte(`[1,2,3]`), &v)
+	SetProgressHook(0, nil)
+	Unmarshal(data, &v)
+	if len(calls) != 0 {
+		t.Errorf("Expected no calls, got %d", len(calls))
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	defer EnableMetrics(false)
+
+	var v interface{}
+	data := []byte(`{"a":[1,2,3]}`)
+	Unmarshal(data, &v)
+	if m := ReadMetrics(); m != (Metrics{}) {
+		t.Errorf("Expected no counting before EnableMetrics, got %+v", m)
+	}
+
+	EnableMetrics(true)
+	before := ReadMetrics()
+	Unmarshal(data, &v)
+	res, err := NewParser(nil).Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Release()
+	s := scanner.New()
+	s.Scan(data)
+	s.Release()
+	after := ReadMetrics()
+
+	if after.Documents-before.Documents != 2 || after.Bytes-before.Bytes != uint64(2*len(data)) {
+		t.Errorf("Expected 2 documents of %d bytes, got %+v", len(data), after)
+	}
+	if scans := after.SIMDScans + after.ScalarScans - before.SIMDScans - before.ScalarScans; scans != 1 {
+		t.Errorf("Expected 1 scan, got %d", scans)
+	}
+
+	var metricsVar expvar.Var = MetricsVar()
+	var decoded map[string]uint64
+	if err := json.Unmarshal([]byte(metricsVar.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["documents"] != after.Documents || decoded["bytes"] != after.Bytes {
+		t.Errorf("Expected the counters as JSON, got %s", metricsVar.String())
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package simdjson
+
+// mapFile reads the file at path, as memory mapping is not supported on
+// this platform.
+func mapFile(path string) ([]byte, bool, error) {
+	return readFile(path)
+}
+
+func unmapFile(data []byte) error {
+	return nil
+}
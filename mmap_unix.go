@@ -0,0 +1,39 @@
+//go:build unix
+
+package simdjson
+
+import (
+	"math"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// mapFile maps the file at path read-only, reporting whether it did.
+// Empty files, which cannot be mapped, and files such as pipes or those
+// under /proc that report no size, are read instead.
+func mapFile(path string) ([]byte, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+	size := fi.Size()
+	if !fi.Mode().IsRegular() || size <= 0 || size > math.MaxInt {
+		return readFile(path)
+	}
+	data, err := unix.Mmap(int(f.Fd()), 0, int(size), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return readFile(path)
+	}
+	return data, true, nil
+}
+
+func unmapFile(data []byte) error {
+	return unix.Munmap(data)
+}
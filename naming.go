@@ -0,0 +1,74 @@
+package simdjson
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FieldNaming derives the JSON names of struct fields without a name in
+// their json tag from their Go names. Names are split into words at case
+// changes, keeping acronyms together and digits with the word before them,
+// so UserID has the words User and ID, and HTTPServer2 HTTP and Server2.
+type FieldNaming uint8
+
+const (
+	// GoFieldNames uses the Go name as is, as encoding/json does.
+	GoFieldNames FieldNaming = iota
+	// SnakeCase joins the lowercased words with underscores: user_id.
+	SnakeCase
+	// CamelCase lowercases the first word only: userID, httpServer2.
+	CamelCase
+	// KebabCase joins the lowercased words with hyphens: user-id.
+	KebabCase
+
+	numFieldNamings = iota
+)
+
+// Name returns the JSON name of a field with Go name name.
+func (n FieldNaming) Name(name string) string {
+	switch n {
+	case SnakeCase:
+		return strings.ToLower(strings.Join(splitWords(name), "_"))
+	case KebabCase:
+		return strings.ToLower(strings.Join(splitWords(name), "-"))
+	case CamelCase:
+		words := splitWords(name)
+		if len(words) == 0 {
+			return name
+		}
+		return strings.ToLower(words[0]) + strings.Join(words[1:], "")
+	}
+	return name
+}
+
+// splitWords splits a Go identifier into words. A word starts at an upper
+// case letter that follows a lower case letter or digit, or that starts a
+// lower case run after an acronym; underscores separate words too.
+func splitWords(s string) []string {
+	var words []string
+	start := 0
+	var prev rune
+	for i, r := range s {
+		if r == '_' {
+			if i > start {
+				words = append(words, s[start:i])
+			}
+			start, prev = i+1, 0
+			continue
+		}
+		if i > start && unicode.IsUpper(r) {
+			next, _ := utf8.DecodeRuneInString(s[i+utf8.RuneLen(r):])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				unicode.IsUpper(prev) && unicode.IsLower(next) {
+				words = append(words, s[start:i])
+				start = i
+			}
+		}
+		prev = r
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+	return words
+}
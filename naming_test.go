@@ -0,0 +1,90 @@
+package simdjson
+
+import (
+	"testing"
+)
+
+func TestFieldNamingName(t *testing.T) {
+	tests := []struct {
+		name                string
+		snake, camel, kebab string
+	}{
+		{"Name", "name", "name", "name"},
+		{"UserID", "user_id", "userID", "user-id"},
+		{"ID", "id", "id", "id"},
+		{"HTTPServer", "http_server", "httpServer", "http-server"},
+		{"HTTPServer2", "http_server2", "httpServer2", "http-server2"},
+		{"Base64Data", "base64_data", "base64Data", "base64-data"},
+		{"CreatedAtUTC", "created_at_utc", "createdAtUTC", "created-at-utc"},
+		{"Already_Snake", "already_snake", "alreadySnake", "already-snake"},
+		{"X", "x", "x", "x"},
+		{"ÉtéTemps", "été_temps", "étéTemps", "été-temps"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GoFieldNames.Name(tt.name); got != tt.name {
+				t.Errorf("GoFieldNames: expected %s, got %s", tt.name, got)
+			}
+			if got := SnakeCase.Name(tt.name); got != tt.snake {
+				t.Errorf("SnakeCase: expected %s, got %s", tt.snake, got)
+			}
+			if got := CamelCase.Name(tt.name); got != tt.camel {
+				t.Errorf("CamelCase: expected %s, got %s", tt.camel, got)
+			}
+			if got := KebabCase.Name(tt.name); got != tt.kebab {
+				t.Errorf("KebabCase: expected %s, got %s", tt.kebab, got)
+			}
+		})
+	}
+}
+
+func TestFieldNaming(t *testing.T) {
+	type inner struct {
+		ItemCount int
+	}
+	type record struct {
+		UserID    int
+		FirstName string `json:",omitempty"`
+		Tagged    string `json:"Tagged_As_Is"`
+		Inner     inner
+	}
+	v := record{UserID: 7, FirstName: "Ann", Tagged: "t", Inner: inner{3}}
+
+	tests := []struct {
+		naming   FieldNaming
+		expected string
+	}{
+		{GoFieldNames, `{"UserID":7,"FirstName":"Ann","Tagged_As_Is":"t","Inner":{"ItemCount":3}}`},
+		{SnakeCase, `{"user_id":7,"first_name":"Ann","Tagged_As_Is":"t","inner":{"item_count":3}}`},
+		{CamelCase, `{"userID":7,"firstName":"Ann","Tagged_As_Is":"t","inner":{"itemCount":3}}`},
+		{KebabCase, `{"user-id":7,"first-name":"Ann","Tagged_As_Is":"t","inner":{"item-count":3}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			got, err := MarshalWithOptions(v, &MarshalOptions{FieldNaming: tt.naming})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			var back record
+			p := NewParser(&Options{CopyStrings: true, FieldNaming: tt.naming})
+			if err := p.Unmarshal(got, &back); err != nil {
+				t.Fatal(err)
+			}
+			if back != v {
+				t.Errorf("Expected %+v, got %+v", v, back)
+			}
+		})
+	}
+
+	// Plans for several namings of one type coexist
+	var back record
+	if err := Unmarshal([]byte(`{"user_id":1,"UserID":2}`), &back); err != nil || back.UserID != 2 {
+		t.Errorf("Expected Go names by default, got %+v (%v)", back, err)
+	}
+}
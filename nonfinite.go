@@ -0,0 +1,70 @@
+package simdjson
+
+import (
+	"math"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// NonFiniteFloats selects how NaN and infinite floats, which JSON has no
+// numbers for, are written.
+type NonFiniteFloats uint8
+
+const (
+	// NonFiniteError fails to encode them, as encoding/json does.
+	NonFiniteError NonFiniteFloats = iota
+	// NonFiniteNull writes them as null.
+	NonFiniteNull
+	// NonFiniteString writes them as the strings "NaN", "Infinity" and
+	// "-Infinity", which Options.AllowNonFinite reads back.
+	NonFiniteString
+)
+
+// appendNonFinite writes the NaN or infinity f as MarshalOptions.NonFinite
+// says, reporting false if it is an error.
+func (e *encoder) appendNonFinite(f float64) bool {
+	switch e.nonFinite {
+	case NonFiniteNull:
+		e.buf = append(e.buf, "null"...)
+	case NonFiniteString:
+		e.buf = append(e.buf, '"')
+		e.buf = append(e.buf, nonFiniteName(f)...)
+		e.buf = append(e.buf, '"')
+	default:
+		return false
+	}
+	return true
+}
+
+// nonFiniteName spells the NaN or infinity f as JavaScript does.
+func nonFiniteName(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case f > 0:
+		return "Infinity"
+	}
+	return "-Infinity"
+}
+
+// nonFiniteString consumes a string spelling NaN or an infinity, if the
+// next value is one and Options.AllowNonFinite is set.
+func (r *Reader) nonFiniteString() (float64, bool) {
+	if !r.allowNaN || r.peek() != scanner.TokenString {
+		return 0, false
+	}
+	tok := r.tokens[r.pos]
+	var f float64
+	switch string(r.data[tok.Start+1 : tok.End-1]) {
+	case "NaN":
+		f = math.NaN()
+	case "Infinity":
+		f = math.Inf(1)
+	case "-Infinity":
+		f = math.Inf(-1)
+	default:
+		return 0, false
+	}
+	r.pos++
+	return f, true
+}
@@ -0,0 +1,108 @@
+package simdjson
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+type nonFiniteSample struct {
+	Mean  float64            `json:"mean"`
+	Min   float32            `json:"min"`
+	Max   *float64           `json:"max"`
+	Extra map[string]float64 `json:"extra"`
+}
+
+func TestNonFiniteEncode(t *testing.T) {
+	inf := math.Inf(1)
+	v := nonFiniteSample{Mean: math.NaN(), Min: float32(math.Inf(-1)), Max: &inf, Extra: map[string]float64{"ok": 1.5}}
+
+	tests := []struct {
+		name     string
+		mode     NonFiniteFloats
+		expected string
+	}{
+		{"null", NonFiniteNull, `{"mean":null,"min":null,"max":null,"extra":{"ok":1.5}}`},
+		{"string", NonFiniteString, `{"mean":"NaN","min":"-Infinity","max":"Infinity","extra":{"ok":1.5}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := MarshalWithOptions(v, &MarshalOptions{NonFinite: tt.mode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+
+	if _, err := Marshal(v); err == nil {
+		t.Error("Expected an error for NaN by default")
+	}
+	if _, err := MarshalWithOptions(v, &MarshalOptions{NonFinite: NonFiniteError}); err == nil {
+		t.Error("Expected an error for NaN with NonFiniteError")
+	}
+}
+
+func TestNonFiniteDecode(t *testing.T) {
+	opts := &Options{AllowNonFinite: true}
+	for _, input := range []string{
+		`{"mean":NaN,"min":-Infinity,"max":Infinity,"extra":{"a":NaN}}`,
+		`{"mean":"NaN","min":"-Infinity","max":"Infinity","extra":{"a":"NaN"}}`,
+	} {
+		t.Run(input, func(t *testing.T) {
+			var v nonFiniteSample
+			if err := UnmarshalWithOptions([]byte(input), &v, opts); err != nil {
+				t.Fatal(err)
+			}
+			if !math.IsNaN(v.Mean) || !math.IsInf(float64(v.Min), -1) || v.Max == nil || !math.IsInf(*v.Max, 1) || !math.IsNaN(v.Extra["a"]) {
+				t.Errorf("Expected NaN, -Inf, +Inf and NaN, got %+v", v)
+			}
+
+			if err := Unmarshal([]byte(input), &v); err == nil {
+				t.Error("Expected an error without AllowNonFinite")
+			}
+		})
+	}
+
+	// Bare literals are float64 in interface{} values; strings stay strings
+	var vals []interface{}
+	if err := UnmarshalWithOptions([]byte(`[NaN,-Infinity,"NaN",2]`), &vals, opts); err != nil {
+		t.Fatal(err)
+	}
+	if f, ok := vals[0].(float64); !ok || !math.IsNaN(f) {
+		t.Errorf("Expected NaN, got %#v", vals[0])
+	}
+	if f, ok := vals[1].(float64); !ok || !math.IsInf(f, -1) {
+		t.Errorf("Expected -Inf, got %#v", vals[1])
+	}
+	if vals[2] != "NaN" || vals[3] != int64(2) {
+		t.Errorf(`Expected "NaN" and 2, got %#v`, vals[2:])
+	}
+
+	var n int
+	var te *UnmarshalTypeError
+	if err := UnmarshalWithOptions([]byte(`Infinity`), &n, opts); !errors.As(err, &te) {
+		t.Errorf("Expected an UnmarshalTypeError for an int, got %v", err)
+	}
+	var s string
+	if err := UnmarshalWithOptions([]byte(`NaN`), &s, opts); !errors.As(err, &te) {
+		t.Errorf("Expected an UnmarshalTypeError for a string, got %v", err)
+	}
+}
+
+func TestNonFiniteRoundTrip(t *testing.T) {
+	in := []float64{math.Inf(1), 0.5, math.Inf(-1)}
+	data, err := MarshalWithOptions(in, &MarshalOptions{NonFinite: NonFiniteString})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []float64
+	if err := UnmarshalWithOptions(data, &out, &Options{AllowNonFinite: true}); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 3 || out[0] != in[0] || out[1] != in[1] || out[2] != in[2] {
+		t.Errorf("Expected %v, got %v", in, out)
+	}
+}
@@ -0,0 +1,92 @@
+package simdjson
+
+import "reflect"
+
+// Null is an optional value that tells apart a member that is absent, one
+// that is null and one with a value, zero or not, without a pointer:
+//
+//	type Patch struct {
+//		Name simdjson.Null[string] `json:"name,omitempty"`
+//	}
+//
+// Decoding sets Present for a member that is there, and Valid and Value if
+// it is not null; an absent member leaves the Null as it was. A Null
+// encodes as Value if Valid and as null otherwise, and with omitempty it is
+// left out only if neither Valid nor Present is set, so a Null built to
+// clear a member still writes null. Value is decoded and encoded with the
+// Reader's and Writer's options, as any other value of type T.
+//
+// MarshalJSON and UnmarshalJSON give the same encoding with encoding/json,
+// where IsZero lets the omitzero option leave out absent members.
+type Null[T any] struct {
+	Value   T
+	Valid   bool // Value is set, not null
+	Present bool // the member was there, null or not
+}
+
+// NullOf returns a valid Null holding v.
+func NullOf[T any](v T) Null[T] {
+	return Null[T]{Value: v, Valid: true, Present: true}
+}
+
+// nullable is implemented by every Null type, for omitempty.
+type nullable interface {
+	absent() bool
+}
+
+var nullableType = reflect.TypeOf((*nullable)(nil)).Elem()
+
+func (n Null[T]) absent() bool {
+	return !n.Valid && !n.Present
+}
+
+// IsZero reports whether n is absent: neither valid nor present.
+func (n Null[T]) IsZero() bool {
+	return n.absent()
+}
+
+// MarshalJSONTo writes Value, or null if n is not valid.
+func (n Null[T]) MarshalJSONTo(w *Writer) error {
+	if !n.Valid {
+		w.Null()
+		return nil
+	}
+	return w.Value(n.Value)
+}
+
+// UnmarshalJSONFrom reads null, or a value into Value.
+func (n *Null[T]) UnmarshalJSONFrom(r *Reader) error {
+	var zero T
+	n.Value, n.Valid, n.Present = zero, false, true
+	if r.Null() {
+		return nil
+	}
+	v := reflect.ValueOf(&n.Value).Elem()
+	if err := planFor(v.Type())(r, v); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (n Null[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return marshal(n.Value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *Null[T]) UnmarshalJSON(data []byte) error {
+	var zero T
+	n.Value, n.Valid, n.Present = zero, false, true
+	if string(data) == "null" {
+		return nil
+	}
+	if err := unmarshal(data, &n.Value); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
@@ -0,0 +1,94 @@
+package simdjson
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type nullPatch struct {
+	Name  Null[string] `json:"name,omitempty"`
+	Age   Null[int]    `json:"age"`
+	Email Null[string] `json:"email,omitempty"`
+}
+
+func TestNullDecode(t *testing.T) {
+	var p nullPatch
+	if err := Unmarshal([]byte(`{"name":"","age":null}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name           string
+		got            Null[string]
+		valid, present bool
+	}{
+		{"zero", p.Name, true, true},
+		{"absent", p.Email, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got.Valid != tt.valid || tt.got.Present != tt.present {
+				t.Errorf("Expected valid %v, present %v, got %+v", tt.valid, tt.present, tt.got)
+			}
+		})
+	}
+	if p.Age.Valid || !p.Age.Present {
+		t.Errorf("Expected a present null, got %+v", p.Age)
+	}
+
+	// Decoding null clears a value
+	p.Age = NullOf(3)
+	if err := Unmarshal([]byte(`{"age":null}`), &p); err != nil || p.Age.Valid || p.Age.Value != 0 {
+		t.Errorf("Expected null to clear the value, got %+v, %v", p.Age, err)
+	}
+	if err := Unmarshal([]byte(`{"age":"x"}`), &p); err == nil {
+		t.Error("Expected an error for a string in a Null[int]")
+	}
+}
+
+func TestNullEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    nullPatch
+		expected string
+	}{
+		{"absent", nullPatch{}, `{"age":null}`},
+		{"values", nullPatch{Name: NullOf(""), Age: NullOf(0)}, `{"name":"","age":0}`},
+		{"cleared", nullPatch{Email: Null[string]{Present: true}}, `{"age":null,"email":null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+
+	// Writer options apply to the value
+	data, err := MarshalWithOptions(NullOf([]byte{1}), &MarshalOptions{BytesFormat: BytesHex})
+	if err != nil || string(data) != `"01"` {
+		t.Errorf(`Expected "01", got %s, %v`, data, err)
+	}
+}
+
+func TestNullStandardLibrary(t *testing.T) {
+	type patch struct {
+		Name Null[string] `json:"name,omitzero"`
+		Age  Null[int]    `json:"age"`
+	}
+	data, err := json.Marshal(patch{Age: NullOf(7)})
+	if err != nil || string(data) != `{"age":7}` {
+		t.Errorf(`Expected {"age":7}, got %s, %v`, data, err)
+	}
+
+	var p patch
+	if err := json.Unmarshal([]byte(`{"name":null,"age":8}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name.Valid || !p.Name.Present || p.Age != NullOf(8) {
+		t.Errorf("Expected a null name and age 8, got %+v", p)
+	}
+}
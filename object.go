@@ -0,0 +1,147 @@
+package simdjson
+
+import (
+	"iter"
+	"reflect"
+	"strings"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// Member is a key and its value in an Object.
+type Member struct {
+	Key   string
+	Value interface{}
+}
+
+// Object is a JSON object that keeps its members in document order,
+// repeated keys included, so that a document can be edited and written
+// back without reordering it. Options.PreserveKeyOrder decodes the objects
+// in interface{} values as *Object instead of map[string]interface{}, and
+// an Object decoded into keeps the order at every depth. Marshal writes the
+// members in order.
+//
+// Lookups scan the members, which suits the objects of configuration
+// files rather than large ones.
+type Object struct {
+	Members []Member
+}
+
+var objectType = reflect.TypeOf(Object{})
+
+// Len returns the number of members.
+func (o *Object) Len() int {
+	return len(o.Members)
+}
+
+// Get returns the value of key, the last one if the key is repeated as a
+// map would keep, and whether there is one.
+func (o *Object) Get(key string) (interface{}, bool) {
+	for i := len(o.Members) - 1; i >= 0; i-- {
+		if o.Members[i].Key == key {
+			return o.Members[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Set replaces the value Get returns for key, or appends the member if
+// there is no such key.
+func (o *Object) Set(key string, value interface{}) {
+	for i := len(o.Members) - 1; i >= 0; i-- {
+		if o.Members[i].Key == key {
+			o.Members[i].Value = value
+			return
+		}
+	}
+	o.Members = append(o.Members, Member{key, value})
+}
+
+// Delete removes every member with key, keeping the others in order.
+func (o *Object) Delete(key string) {
+	n := 0
+	for _, m := range o.Members {
+		if m.Key != key {
+			o.Members[n] = m
+			n++
+		}
+	}
+	clear(o.Members[n:])
+	o.Members = o.Members[:n]
+}
+
+// All returns an iterator over the members in order.
+func (o *Object) All() iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		for _, m := range o.Members {
+			if !yield(m.Key, m.Value) {
+				return
+			}
+		}
+	}
+}
+
+// UnmarshalJSONFrom decodes an object into o, replacing its members, with
+// the objects nested in it decoded as *Object too.
+func (o *Object) UnmarshalJSONFrom(r *Reader) error {
+	if r.Null() {
+		o.Members = nil
+		return nil
+	}
+	if r.peek() != scanner.TokenObjectBegin {
+		return r.typeError(objectType)
+	}
+	r.pos++
+	ordered := r.ordered
+	r.ordered = true
+	err := r.membersInto(o)
+	r.ordered = ordered
+	return err
+}
+
+// MarshalJSONTo encodes o with its members in order.
+func (o Object) MarshalJSONTo(w *Writer) error {
+	if o.Members == nil {
+		w.NilMap()
+		return nil
+	}
+	w.RawByte('{')
+	for i, m := range o.Members {
+		if i > 0 {
+			w.RawByte(',')
+		}
+		w.String(m.Key)
+		w.RawByte(':')
+		if err := w.Value(m.Value); err != nil {
+			return err
+		}
+	}
+	w.RawByte('}')
+	return nil
+}
+
+// membersInto appends the members of the object whose opening brace has
+// been read to o.
+func (r *Reader) membersInto(o *Object) error {
+	o.Members = o.Members[:0]
+	for r.More() {
+		key, err := r.keyString()
+		if err != nil {
+			return err
+		}
+		val, err := r.value()
+		if err != nil {
+			return err
+		}
+		o.Members = append(o.Members, Member{key, val})
+	}
+	return r.EndObject()
+}
+
+func detachObject(o *Object) *Object {
+	d := &Object{Members: make([]Member, len(o.Members))}
+	for i, m := range o.Members {
+		d.Members[i] = Member{strings.Clone(m.Key), detach(m.Value)}
+	}
+	return d
+}
@@ -0,0 +1,92 @@
+package simdjson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreserveKeyOrder(t *testing.T) {
+	data := `{"zeta":1,"alpha":{"y":true,"b":null,"a":[{"k2":"v","k1":1.5}]},"mid":"x","zeta":2}`
+	opts := DefaultOptions()
+	opts.PreserveKeyOrder = true
+
+	var v interface{}
+	if err := UnmarshalWithOptions([]byte(data), &v, &opts); err != nil {
+		t.Fatal(err)
+	}
+	obj, ok := v.(*Object)
+	if !ok {
+		t.Fatalf("Expected an *Object, got %T", v)
+	}
+	var keys []string
+	for k := range obj.All() {
+		keys = append(keys, k)
+	}
+	if expected := "zeta alpha mid zeta"; strings.Join(keys, " ") != expected {
+		t.Errorf("Expected keys %s, got %s", expected, strings.Join(keys, " "))
+	}
+	if zeta, _ := obj.Get("zeta"); zeta != int64(2) {
+		t.Errorf("Expected the last zeta, got %v", zeta)
+	}
+
+	out, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != data {
+		t.Errorf("Expected %s, got %s", data, out)
+	}
+
+	// Parse results and Values
+	res, err := NewParser(&opts).Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys = keys[:0]
+	for k := range res.Root().Get("alpha").Fields() {
+		keys = append(keys, k)
+	}
+	if strings.Join(keys, " ") != "y b a" {
+		t.Errorf("Expected the keys of alpha in order, got %s", strings.Join(keys, " "))
+	}
+	detached := res.Root().Detach()
+	res.Release()
+	if out, _ := Marshal(detached); string(out) != data {
+		t.Errorf("Expected the detached tree to keep its order, got %s", out)
+	}
+}
+
+func TestObject(t *testing.T) {
+	// Decoding into an Object keeps the order without the option
+	var o Object
+	if err := Unmarshal([]byte(`{"b":{"d":1,"c":2},"a":[]}`), &o); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := o.Get("b"); b == nil {
+		t.Fatal("Expected b")
+	} else if _, ok := b.(*Object); !ok {
+		t.Errorf("Expected nested objects as *Object, got %T", b)
+	}
+
+	o.Set("a", "replaced")
+	o.Set("e", false)
+	o.Delete("b")
+	if o.Len() != 2 {
+		t.Errorf("Expected 2 members, got %d", o.Len())
+	}
+	out, err := Marshal(&o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"a":"replaced","e":false}` {
+		t.Errorf("Expected the edited object in order, got %s", out)
+	}
+
+	out, _ = Marshal(struct{ O Object }{})
+	if string(out) != `{"O":null}` {
+		t.Errorf("Expected a nil Object as null, got %s", out)
+	}
+	if err := Unmarshal([]byte(`[1]`), &o); err == nil {
+		t.Error("Expected an error for an array")
+	}
+}
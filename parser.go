@@ -0,0 +1,177 @@
+package simdjson
+
+import (
+	"reflect"
+	"time"
+
+	internalScanner "github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// Options configures a Parser. Start from DefaultOptions, which gives the
+// behaviour of the package-level functions; the zero value also turns off
+// CopyStrings.
+type Options struct {
+	// InternKeys makes object keys decoded into maps and interface{}
+	// values share one string per distinct key, instead of one per
+	// occurrence. It pays off for large arrays of records with the same
+	// keys. Interned keys are copies and never alias the input.
+	InternKeys bool
+
+	// CopyStrings makes every decoded string and key a copy, so the input
+	// can be reused as soon as a call returns. Turning it off selects
+	// zero-copy mode: strings and keys without escape sequences point into
+	// the input instead, saving an allocation and a copy each. The input
+	// then must not be modified while anything decoded from it is in use;
+	// use Value.Detach to keep selected values longer.
+	CopyStrings bool
+
+	// SkipBOM ignores a UTF-8 byte order mark at the start of the input,
+	// as RFC 8259 allows parsers to. Without it such input fails with
+	// ErrBOM.
+	SkipBOM bool
+
+	// UTF16 accepts UTF-16 input, little or big endian, as RFC 4627 did,
+	// and transcodes it to UTF-8 before parsing. It is recognized by its
+	// byte order mark or, without one, by the zero byte in its first
+	// character. Unpaired surrogates become U+FFFD. Without UTF16 such
+	// input fails with ErrUTF16.
+	UTF16 bool
+
+	// BytesFormat is the format []byte values are decoded from, standard
+	// base64 by default; see MarshalOptions.BytesFormat. The format tag
+	// option overrides it for a field.
+	BytesFormat BytesFormat
+
+	// CoerceNumbers stores numbers that do not fit an integer or float
+	// field instead of failing with an UnmarshalTypeError: fractions are
+	// truncated toward zero and values beyond the type's range clamped to
+	// it, so 1.9 decodes into an int as 1 and 300 into an int8 as 127.
+	CoerceNumbers bool
+
+	// FieldNaming derives the keys of struct fields whose json tag gives
+	// no name from their Go names; see MarshalOptions.FieldNaming. Keys
+	// still match case-insensitively.
+	FieldNaming FieldNaming
+
+	// CollectErrors keeps decoding past values that do not fit their Go
+	// type and objects lacking required fields, and returns them all as
+	// DecodeErrors once the document is done, so a client can be told
+	// everything wrong with its request at once. Syntax errors still stop
+	// decoding.
+	CollectErrors bool
+
+	// CollectDuplicateKeys decodes objects into map[string][]Value targets
+	// with every value of each key, in the order they appear, for
+	// inspecting documents from producers that repeat keys, such as
+	// header-like data. Without it such a map is an ordinary map of
+	// arrays. Elsewhere the last value of a repeated key wins either way,
+	// including in the objects nested in the collected values.
+	CollectDuplicateKeys bool
+
+	// PreserveKeyOrder decodes the objects in interface{} values as
+	// *Object, which keeps their members in document order, instead of
+	// map[string]interface{}, so tools that edit documents can write them
+	// back unchanged. Parse results hold them too, allocated outside the
+	// arena.
+	PreserveKeyOrder bool
+
+	// TimeFormat is the format time.Time values are decoded from, RFC 3339
+	// strings by default; see MarshalOptions.TimeFormat.
+	TimeFormat string
+
+	// TimeLocation is the location decoded times are returned in, and
+	// that layouts without a zone are read in. Without it they are read
+	// as UTC, and times that give their zone keep it.
+	TimeLocation *time.Location
+
+	// AllowNonFinite accepts the NaN, Infinity and -Infinity that
+	// JavaScript and Python's json module write, though JSON has no such
+	// numbers, and the strings "NaN", "Infinity" and "-Infinity" for
+	// floats, as MarshalOptions.NonFinite writes them. They decode as
+	// float64 into interface{} values and fail for integers.
+	AllowNonFinite bool
+
+	// Limits bounds the size and shape of the documents accepted, for
+	// parsing untrusted input; see Limits.
+	Limits Limits
+}
+
+// DefaultOptions returns the options the package-level functions use.
+func DefaultOptions() Options {
+	return Options{CopyStrings: true}
+}
+
+// defaultOptions are what a pooled decoder is reset to when released.
+var defaultOptions = DefaultOptions()
+
+// Parser decodes documents like Unmarshal, but keeps its scanner and
+// buffers between calls instead of taking them from a shared pool, and
+// carries state such as the key intern table from one document to the
+// next. A Parser must not be used concurrently.
+type Parser struct {
+	d decoder
+}
+
+// NewParser returns a Parser configured by opts; nil means DefaultOptions.
+func NewParser(opts *Options) *Parser {
+	o := DefaultOptions()
+	if opts != nil {
+		o = *opts
+	}
+
+	p := &Parser{
+		d: decoder{scanner: internalScanner.New()},
+	}
+	p.d.setOptions(&o)
+	return p
+}
+
+// Reset forgets what p carried over from the documents it has decoded,
+// such as the interned keys, so it can be reused for unrelated input as a
+// new Parser with the same Options would be, while keeping its scanner and
+// buffers.
+func (p *Parser) Reset() {
+	if p.d.reader.keys != nil {
+		p.d.reader.keys.reset()
+	}
+	p.d.data = nil
+	p.d.reader.reset(nil, nil)
+}
+
+// Unmarshal parses data into the value pointed to by v.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	p.d.data = data
+	err := p.d.unmarshal(v)
+	p.d.data = nil
+	p.d.reader.reset(nil, nil)
+	return err
+}
+
+// Parse parses data into a tree of interface{} values allocated from an
+// arena; see Result for the ownership rules. Keys are interned if the
+// Parser was created with InternKeys. In zero-copy mode, unescaped strings
+// and keys point into data rather than the arena.
+func (p *Parser) Parse(data []byte) (*Result, error) {
+	p.d.data = data
+	res, err := p.d.parse()
+	p.d.data = nil
+	p.d.reader.reset(nil, nil)
+	return res, err
+}
+
+// parse decodes d.data into a Result backed by a pooled arena.
+func (d *decoder) parse() (*Result, error) {
+	a := arenaPool.Get()
+	var v interface{}
+
+	d.reader.arena = a
+	err := d.run(decodeInterface, reflect.ValueOf(&v).Elem())
+	d.reader.arena = nil
+
+	if err != nil {
+		a.reset()
+		arenaPool.Put(a)
+		return nil, err
+	}
+	return &Result{value: v, arena: a}, nil
+}
@@ -2,73 +2,145 @@ package simdjson
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
+	"os"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
 
-// TestPerformanceRegression ensures we maintain performance improvements
-func TestPerformanceRegression(t *testing.T) {
+var (
+	throughput          = flag.Bool("throughput", false, "run TestThroughputRegression against testdata/corpus/baseline.json (or set SIMDJSON_THROUGHPUT=1)")
+	updateBaseline      = flag.Bool("update-baseline", false, "record the throughput of this machine in testdata/corpus/baseline.json")
+	throughputTolerance = flag.Float64("throughput-tolerance", 0.2, "fraction of its baseline throughput a kernel may lose")
+)
+
+// throughputBaseline is testdata/corpus/baseline.json: the GB/s of each
+// kernel on each corpus, as "kernel/corpus", measured on the platform,
+// CPU model and scanning kernel it names.
+type throughputBaseline struct {
+	GOOS     string             `json:"goos"`
+	GOARCH   string             `json:"goarch"`
+	CPU      string             `json:"cpu"`
+	Kernel   string             `json:"kernel"`
+	GBPerSec map[string]float64 `json:"gb_per_sec"`
+}
+
+const baselinePath = "testdata/corpus/baseline.json"
+
+// TestThroughputRegression ensures the throughput on the corpora has not
+// dropped by more than -throughput-tolerance since the baseline was
+// recorded on this machine. Wall-clock throughput is only meaningful on
+// an otherwise idle machine, so the test runs only when asked for with
+// -throughput or SIMDJSON_THROUGHPUT=1, and should be run on its own:
+//
+//	go test -p 1 -run ThroughputRegression -throughput .
+func TestThroughputRegression(t *testing.T) {
+	if !*throughput && !*updateBaseline && os.Getenv("SIMDJSON_THROUGHPUT") != "1" {
+		t.Skip("Skipping throughput regression tests; enable them with -throughput or SIMDJSON_THROUGHPUT=1")
+	}
 	if testing.Short() {
-		t.Skip("Skipping performance regression tests in short mode")
+		t.Skip("Skipping throughput regression tests in short mode")
 	}
 
-	testCases := []struct {
-		name     string
-		json     []byte
-		minRatio float64 // Minimum speedup ratio we expect
-	}{
-		{
-			name:     "small_json",
-			json:     []byte(`{"name":"John","age":30,"city":"New York"}`),
-			minRatio: 0.8, // Allow slight overhead for small JSON
-		},
-		{
-			name:     "medium_json",
-			json:     generateMediumJSON(),
-			minRatio: 1.0, // Should be at least as fast
-		},
-		{
-			name:     "large_json",
-			json:     generateLargeJSON(1000),
-			minRatio: 1.5, // Should be significantly faster
-		},
-		{
-			name:     "very_large_json",
-			json:     generateLargeJSON(10000),
-			minRatio: 1.8, // Should show major improvement
-		},
+	var baseline throughputBaseline
+	if data, err := os.ReadFile(baselinePath); err == nil {
+		if err := json.Unmarshal(data, &baseline); err != nil {
+			t.Fatal(err)
+		}
+	}
+	current := currentMachine()
+	if !*updateBaseline {
+		if mismatch := baseline.mismatch(current); mismatch != "" {
+			t.Skipf("Baseline recorded with a different %s; record one with -update-baseline", mismatch)
+		}
 	}
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			// Warm up
-			for i := 0; i < 10; i++ {
-				var std, our interface{}
-				json.Unmarshal(tc.json, &std)
-				Unmarshal(tc.json, &our)
-			}
-
-			// Benchmark standard library
-			stdTime := benchmarkUnmarshal(tc.json, 100, func(data []byte, v interface{}) error {
-				return json.Unmarshal(data, v)
-			})
+	kernels := []struct {
+		name string
+		run  func(data []byte)
+	}{
+		{"validate", func(data []byte) { Valid(data) }},
+		{"unmarshal", func(data []byte) {
+			var v interface{}
+			Unmarshal(data, &v)
+		}},
+	}
+	measured := current
+	measured.GBPerSec = map[string]float64{}
+	for _, name := range corpusNames {
+		data := loadCorpus(t, name)
+		for _, k := range kernels {
+			key := k.name + "/" + name
+			t.Run(key, func(t *testing.T) {
+				res := testing.Benchmark(func(b *testing.B) {
+					b.SetBytes(int64(len(data)))
+					for i := 0; i < b.N; i++ {
+						k.run(data)
+					}
+				})
+				gbps := newThroughputReport(k.name, name, len(data), res).GBPerSec
+				measured.GBPerSec[key] = gbps
+				if *updateBaseline {
+					return
+				}
 
-			// Benchmark our implementation
-			ourTime := benchmarkUnmarshal(tc.json, 100, func(data []byte, v interface{}) error {
-				return Unmarshal(data, v)
+				base, ok := baseline.GBPerSec[key]
+				if !ok {
+					t.Skip("No baseline; record one with -update-baseline")
+				}
+				t.Logf("%.3f GB/s, baseline %.3f GB/s", gbps, base)
+				if gbps < base*(1-*throughputTolerance) {
+					t.Errorf("Throughput regression: expected at least %.3f GB/s, got %.3f", base*(1-*throughputTolerance), gbps)
+				}
 			})
+		}
+	}
 
-			ratio := float64(stdTime) / float64(ourTime)
-			
-			t.Logf("Performance ratio (std/ours): %.2f (std=%v, ours=%v)", ratio, stdTime, ourTime)
+	if *updateBaseline {
+		data, err := json.MarshalIndent(measured, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(baselinePath, append(data, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("Recorded the baseline of %s/%s %s %s", measured.GOOS, measured.GOARCH, measured.CPU, measured.Kernel)
+	}
+}
 
-			if ratio < tc.minRatio {
-				t.Errorf("Performance regression: expected ratio >= %.2f, got %.2f", tc.minRatio, ratio)
+// currentMachine describes this machine as a baseline would: its
+// platform, CPU model where the OS reports one, and the active scanning
+// kernel.
+func currentMachine() throughputBaseline {
+	m := throughputBaseline{GOOS: runtime.GOOS, GOARCH: runtime.GOARCH, Kernel: ActiveKernel()}
+	if info, err := os.ReadFile("/proc/cpuinfo"); err == nil {
+		for _, line := range strings.Split(string(info), "\n") {
+			if k, v, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(k) == "model name" {
+				m.CPU = strings.TrimSpace(v)
+				break
 			}
-		})
+		}
+	}
+	return m
+}
+
+// mismatch names the first of GOOS, GOARCH, CPU and kernel that differs
+// between b and m, or returns "" if b was recorded on a machine like m.
+func (b throughputBaseline) mismatch(m throughputBaseline) string {
+	switch {
+	case b.GOOS != m.GOOS:
+		return "GOOS (" + b.GOOS + ", not " + m.GOOS + ")"
+	case b.GOARCH != m.GOARCH:
+		return "GOARCH (" + b.GOARCH + ", not " + m.GOARCH + ")"
+	case b.CPU != m.CPU:
+		return "CPU (" + b.CPU + ", not " + m.CPU + ")"
+	case b.Kernel != m.Kernel:
+		return "kernel (" + b.Kernel + ", not " + m.Kernel + ")"
 	}
+	return ""
 }
 
 // TestValidationPerformance tests validation speed
@@ -137,6 +209,79 @@ func TestMemoryEfficiency(t *testing.T) {
 	}
 }
 
+// TestOptionsAllocations ensures passing per-call options costs no
+// allocations over the plain functions on small payloads
+func TestOptionsAllocations(t *testing.T) {
+	type small struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	data := []byte(`{"name":"John","age":30}`)
+	var v small
+
+	tests := []struct {
+		name   string
+		plain  func()
+		opts   func()
+		allocs float64 // the plain function's, which must not regress
+	}{
+		{
+			name:   "Unmarshal",
+			plain:  func() { Unmarshal(data, &v) },
+			opts:   func() { UnmarshalWithOptions(data, &v, &Options{CopyStrings: true, CoerceNumbers: true}) },
+			allocs: 1, // the name
+		},
+		{
+			name:   "Valid",
+			plain:  func() { Valid(data) },
+			opts:   func() { ValidWithOptions(data, &Options{Limits: Limits{MaxDepth: 4, MaxStringLen: 16}}) },
+			allocs: 0,
+		},
+		{
+			name:   "Marshal",
+			plain:  func() { Marshal(&v) },
+			opts:   func() { MarshalWithOptions(&v, &MarshalOptions{NilAsEmpty: true, FieldNaming: SnakeCase}) },
+			allocs: 1, // the output
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.plain()
+			tc.opts()
+			if allocs := testing.AllocsPerRun(100, tc.plain); allocs > tc.allocs {
+				t.Errorf("Expected at most %.0f allocations without options, got %.1f", tc.allocs, allocs)
+			}
+			if allocs := testing.AllocsPerRun(100, tc.opts); allocs > tc.allocs {
+				t.Errorf("Expected at most %.0f allocations with options, got %.1f", tc.allocs, allocs)
+			}
+		})
+	}
+}
+
+func BenchmarkOptions(b *testing.B) {
+	data := []byte(`{"name":"John","age":30,"city":"New York"}`)
+	opts := &Options{CopyStrings: true, CoerceNumbers: true}
+	type person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+		City string `json:"city"`
+	}
+	b.Run("Unmarshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var v person
+			Unmarshal(data, &v)
+		}
+	})
+	b.Run("UnmarshalWithOptions", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var v person
+			UnmarshalWithOptions(data, &v, opts)
+		}
+	})
+}
+
 // TestConcurrentPerformance tests performance under concurrent load
 func TestConcurrentPerformance(t *testing.T) {
 	if testing.Short() {
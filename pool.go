@@ -0,0 +1,82 @@
+package simdjson
+
+import "github.com/biggeezerdevelopment/simdjson-go/internal/pool"
+
+// PoolConfig sets how the scanners, token slices, encoders, decoders and
+// Parse arenas that calls reuse are pooled. The zero PoolConfig is the
+// default: each kind is kept in a sync.Pool, which garbage collection
+// empties of idle objects, and only the buffers grown by unusually large
+// documents are dropped when an object is returned.
+type PoolConfig struct {
+	// Disabled turns pooling off, so every call allocates what it needs
+	// and leaves it to the garbage collector.
+	Disabled bool
+
+	// MaxBufferSize, if positive, is the most bytes of buffers an object
+	// may hold and still be returned to its pool. Lower values bound the
+	// memory kept idle, at the cost of regrowing buffers for large
+	// documents.
+	MaxBufferSize int
+
+	// MaxObjects, if positive, is the most idle objects of each kind kept.
+	// They are held in a free list behind a mutex instead of a sync.Pool,
+	// so they are also kept across garbage collections.
+	MaxObjects int
+}
+
+// SetPoolConfig configures every pool and empties them. It is safe to call
+// at any time, but meant to be called once at startup.
+func SetPoolConfig(c PoolConfig) {
+	pool.Configure(pool.Config{
+		Disabled:      c.Disabled,
+		MaxBufferSize: c.MaxBufferSize,
+		MaxObjects:    c.MaxObjects,
+	})
+}
+
+// PoolCounts are the counters of one pool.
+type PoolCounts struct {
+	Hits   uint64 // objects reused from the pool
+	Misses uint64 // objects allocated because the pool had none
+	Drops  uint64 // objects not returned because of the PoolConfig
+
+	// The objects returned and not since reused, and the bytes of buffers
+	// they hold, since the last SetPoolConfig. Without MaxObjects these
+	// are upper bounds, as a sync.Pool frees idle objects at garbage
+	// collection without telling.
+	Retained      int64
+	RetainedBytes int64
+}
+
+// PoolUsage reports on every pool.
+type PoolUsage struct {
+	Scanners PoolCounts
+	Tokens   PoolCounts
+	Encoders PoolCounts
+	Decoders PoolCounts
+	Arenas   PoolCounts
+}
+
+// PoolStats returns the counters of the pools, for tuning PoolConfig: many
+// misses mean objects are allocated that pooling could save, while many
+// retained bytes mean memory is held idle.
+func PoolStats() PoolUsage {
+	return PoolUsage{
+		Scanners: poolCounts("scanner"),
+		Tokens:   poolCounts("tokens"),
+		Encoders: poolCounts("encoder"),
+		Decoders: poolCounts("decoder"),
+		Arenas:   poolCounts("arena"),
+	}
+}
+
+func poolCounts(name string) PoolCounts {
+	st := pool.Read(name)
+	return PoolCounts{
+		Hits:          st.Hits,
+		Misses:        st.Misses,
+		Drops:         st.Drops,
+		Retained:      st.Retained,
+		RetainedBytes: st.RetainedBytes,
+	}
+}
@@ -0,0 +1,117 @@
+package simdjson
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestPoolConfig(t *testing.T) {
+	defer SetPoolConfig(PoolConfig{})
+
+	type item struct {
+		Name string `json:"name"`
+		N    int    `json:"n"`
+	}
+	roundTrip := func() {
+		data, err := Marshal(item{"a", 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out item
+		if err := Unmarshal(data, &out); err != nil || out != (item{"a", 1}) {
+			t.Fatalf("Expected the item back, got %+v, %v", out, err)
+		}
+	}
+
+	SetPoolConfig(PoolConfig{MaxObjects: 1})
+	before := PoolStats()
+	for i := 0; i < 3; i++ {
+		roundTrip()
+	}
+	after := PoolStats()
+	if hits := after.Encoders.Hits - before.Encoders.Hits; hits != 2 {
+		t.Errorf("Expected 2 encoder hits, got %d", hits)
+	}
+	if hits := after.Decoders.Hits - before.Decoders.Hits; hits != 2 {
+		t.Errorf("Expected 2 decoder hits, got %d", hits)
+	}
+	if after.Encoders.Retained != 1 || after.Encoders.RetainedBytes != 4096 {
+		t.Errorf("Expected one encoder of 4096 bytes retained, got %+v", after.Encoders)
+	}
+	if after.Decoders.Retained != 1 {
+		t.Errorf("Expected one decoder retained, got %+v", after.Decoders)
+	}
+	if after.Scanners.Retained != 1 || after.Scanners.RetainedBytes <= 0 {
+		t.Errorf("Expected its scanner retained apart, got %+v", after.Scanners)
+	}
+
+	// Buffers over the limit are dropped
+	SetPoolConfig(PoolConfig{MaxBufferSize: 1024})
+	before = PoolStats()
+	roundTrip()
+	after = PoolStats()
+	if drops := after.Encoders.Drops - before.Encoders.Drops; drops != 1 || after.Encoders.Retained != 0 {
+		t.Errorf("Expected the encoder dropped, got %+v", after.Encoders)
+	}
+
+	SetPoolConfig(PoolConfig{Disabled: true})
+	before = PoolStats()
+	roundTrip()
+	p := NewParser(nil)
+	res, err := p.Parse([]byte(`{"a":[1,2,"x"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Release()
+	after = PoolStats()
+	if after.Encoders.Hits != before.Encoders.Hits || after.Arenas.Hits != before.Arenas.Hits {
+		t.Errorf("Expected no hits with pooling disabled, got %+v", after)
+	}
+	if after.Arenas.Misses-before.Arenas.Misses != 1 || after.Arenas.Drops-before.Arenas.Drops != 1 {
+		t.Errorf("Expected the arena allocated and dropped, got %+v", after.Arenas)
+	}
+}
+
+func TestDecoderPoolScanners(t *testing.T) {
+	d := newDecoder(nil)
+	if d.scanner == nil {
+		t.Fatal("Expected a checked out decoder to hold a scanner")
+	}
+	d.release()
+	if d.scanner != nil {
+		t.Error("Expected a released decoder to give up its scanner")
+	}
+
+	// Run with -race: decoders in use must never share a scanner
+	type doc struct {
+		ID   int      `json:"id"`
+		Tags []string `json:"tags"`
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				id := g*1000 + i
+				data := []byte(`{"id":` + strconv.Itoa(id) + `,"tags":["` + strconv.Itoa(g) + `"]}`)
+				var v doc
+				var err error
+				switch i % 3 {
+				case 0:
+					err = Unmarshal(data, &v)
+				case 1:
+					err = DecodeInto(data, &v)
+				default:
+					v, err = Decode[doc](data)
+				}
+				if err != nil || v.ID != id || len(v.Tags) != 1 || v.Tags[0] != strconv.Itoa(g) {
+					t.Errorf("Expected id %d, got %+v, %v", id, v, err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,37 @@
+package simdjson
+
+import (
+	"errors"
+	"reflect"
+)
+
+// RawMessage is a raw encoded JSON value. Unmarshal stores a copy of the
+// value's bytes in it instead of decoding it, and Marshal writes it as is,
+// so parts of a document can be passed through or decoded later. A nil
+// RawMessage is written as null.
+type RawMessage []byte
+
+var rawMessageType = reflect.TypeOf(RawMessage(nil))
+
+// encodeRaw appends a RawMessage after checking that it is valid JSON.
+func (e *encoder) encodeRaw(m RawMessage) error {
+	if m == nil {
+		e.buf = append(e.buf, "null"...)
+		return nil
+	}
+	if !Valid(m) {
+		return errors.New("invalid RawMessage: " + string(m))
+	}
+	e.buf = append(e.buf, m...)
+	return nil
+}
+
+func decodeRaw(r *Reader, v reflect.Value) error {
+	raw, err := r.Raw()
+	if err != nil {
+		return err
+	}
+	// The backing array is reused, as encoding/json reuses it
+	v.SetBytes(append(v.Bytes()[:0], raw...))
+	return nil
+}
@@ -0,0 +1,534 @@
+package simdjson
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/parser"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// UnmarshalerFrom is implemented by types that decode themselves from a
+// Reader without reflection, such as those generated by cmd/simdjson-gen.
+type UnmarshalerFrom interface {
+	UnmarshalJSONFrom(r *Reader) error
+}
+
+var (
+	errUnexpectedEnd   = errors.New("unexpected end of JSON")
+	errExpectedComma   = errors.New("expected comma")
+	errTrailingData    = errors.New("unexpected data after top-level value")
+	errUnterminatedStr = errors.New("unterminated string")
+)
+
+// Reader walks the tokens of a document one value at a time. It is the
+// decoding half of the API used by generated code: objects are read with
+// BeginObject, a More/Key loop and EndObject, arrays likewise with
+// BeginArray and EndArray. A Reader is only valid during the
+// UnmarshalJSONFrom call it is passed to.
+type Reader struct {
+	data     []byte
+	tokens   []scanner.Token
+	pos      int
+	err      error
+	scratch  []byte
+	keys     *internTable   // non-nil if the Parser interns keys
+	arena    *arena         // non-nil while Parser.Parse builds a Result
+	zeroCopy bool           // strings may alias data; see Options.CopyStrings
+	bytesFmt BytesFormat    // format of Bytes; see Options.BytesFormat
+	coerce   bool           // see Options.CoerceNumbers
+	naming   FieldNaming    // see Options.FieldNaming
+	allErrs  bool           // see Options.CollectErrors
+	dups     bool           // see Options.CollectDuplicateKeys
+	ordered  bool           // see Options.PreserveKeyOrder
+	timeFmt  string         // see Options.TimeFormat
+	timeLoc  *time.Location // see Options.TimeLocation
+	allowNaN bool           // see Options.AllowNonFinite
+	stack    []interface{}  // elements of the arrays being built in arena
+
+	// ctx, if non-nil, is checked by More once checkAt tokens are read
+	ctx     context.Context
+	checkAt int
+}
+
+// cancelTokens is how many tokens are read between checks of the context
+// passed to UnmarshalContext.
+const cancelTokens = 4096
+
+func (r *Reader) reset(data []byte, tokens []scanner.Token) {
+	r.data = data
+	r.tokens = tokens
+	r.pos = 0
+	r.err = nil
+	r.checkAt = math.MaxInt
+	if r.ctx != nil {
+		r.checkAt = cancelTokens
+	}
+}
+
+// canceled reports whether the context has been canceled, failing r with
+// its error if so.
+func (r *Reader) canceled() bool {
+	if err := r.ctx.Err(); err != nil {
+		r.fail(err)
+		return true
+	}
+	r.checkAt = r.pos + cancelTokens
+	return false
+}
+
+func (r *Reader) fail(err error) error {
+	if r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+func (r *Reader) peek() scanner.TokenType {
+	if r.pos >= len(r.tokens) {
+		return scanner.TokenNone
+	}
+	return r.tokens[r.pos].Type
+}
+
+// next consumes a token of type want.
+func (r *Reader) next(want scanner.TokenType, what string) (scanner.Token, error) {
+	if r.err != nil {
+		return scanner.Token{}, r.err
+	}
+	if r.pos >= len(r.tokens) {
+		return scanner.Token{}, r.fail(errUnexpectedEnd)
+	}
+	tok := r.tokens[r.pos]
+	if tok.Type != want {
+		return tok, r.fail(errors.New("expected " + what + " at offset " + strconv.Itoa(int(tok.Start))))
+	}
+	r.pos++
+	return tok, nil
+}
+
+// BeginObject consumes the '{' that opens an object.
+func (r *Reader) BeginObject() error {
+	_, err := r.next(scanner.TokenObjectBegin, "object")
+	return err
+}
+
+// EndObject consumes the '}' that closes an object.
+func (r *Reader) EndObject() error {
+	_, err := r.next(scanner.TokenObjectEnd, "'}'")
+	return err
+}
+
+// BeginArray consumes the '[' that opens an array.
+func (r *Reader) BeginArray() error {
+	_, err := r.next(scanner.TokenArrayBegin, "array")
+	return err
+}
+
+// EndArray consumes the ']' that closes an array.
+func (r *Reader) EndArray() error {
+	_, err := r.next(scanner.TokenArrayEnd, "']'")
+	return err
+}
+
+// More reports whether the current object or array has another member,
+// consuming the comma that separates it from the previous one. On a
+// syntax error it returns false and the following End call reports it.
+func (r *Reader) More() bool {
+	if r.err != nil {
+		return false
+	}
+	if r.pos >= r.checkAt && r.canceled() {
+		return false
+	}
+	if r.pos >= len(r.tokens) {
+		r.fail(errUnexpectedEnd)
+		return false
+	}
+	first := false
+	if r.pos > 0 {
+		prev := r.tokens[r.pos-1].Type
+		first = prev == scanner.TokenObjectBegin || prev == scanner.TokenArrayBegin
+	}
+	switch r.tokens[r.pos].Type {
+	case scanner.TokenObjectEnd, scanner.TokenArrayEnd:
+		return false
+	case scanner.TokenComma:
+		if first {
+			r.fail(errors.New("unexpected comma"))
+			return false
+		}
+		r.pos++
+		return true
+	}
+	if !first {
+		r.fail(errExpectedComma)
+		return false
+	}
+	return true
+}
+
+// stringBytes consumes a string token and returns its content between
+// the quotes, still escaped, and whether it holds any escapes.
+func (r *Reader) stringBytes() ([]byte, bool, error) {
+	tok, err := r.next(scanner.TokenString, "string")
+	if err != nil {
+		return nil, false, err
+	}
+	if tok.End-tok.Start < 2 || r.data[tok.End-1] != '"' {
+		return nil, false, r.fail(errUnterminatedStr)
+	}
+	return r.data[tok.Start+1 : tok.End-1], tok.HasEscape(), nil
+}
+
+// Key consumes an object key and the colon after it. The returned slice
+// is only valid until the next call on r.
+func (r *Reader) Key() ([]byte, error) {
+	raw, escaped, err := r.stringBytes()
+	if err != nil {
+		return nil, err
+	}
+	if escaped {
+		r.scratch, err = parser.AppendUnescaped(r.scratch[:0], raw)
+		if err != nil {
+			return nil, r.fail(err)
+		}
+		raw = r.scratch
+	}
+	if _, err := r.next(scanner.TokenColon, "':'"); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// keyString is Key for decoders that keep the key. With interning on, the
+// key comes from the intern table. Otherwise it is a copy, from the arena
+// while building a Result, unless zero-copy mode lets an unescaped key
+// alias the input.
+func (r *Reader) keyString() (string, error) {
+	if r.keys != nil {
+		k, err := r.Key()
+		if err != nil {
+			return "", err
+		}
+		return r.keys.intern(k), nil
+	}
+
+	raw, escaped, err := r.stringBytes()
+	if err != nil {
+		return "", err
+	}
+	var key string
+	if escaped {
+		r.scratch, err = parser.AppendUnescaped(r.scratch[:0], raw)
+		if err != nil {
+			return "", r.fail(err)
+		}
+		key = r.copyString(r.scratch)
+	} else if r.zeroCopy {
+		key = *(*string)(unsafe.Pointer(&raw))
+	} else {
+		key = r.copyString(raw)
+	}
+	if _, err := r.next(scanner.TokenColon, "':'"); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// copyString copies b into the arena if there is one, or the heap.
+func (r *Reader) copyString(b []byte) string {
+	if r.arena != nil {
+		return r.arena.string(b)
+	}
+	return string(b)
+}
+
+// String consumes a string value. In zero-copy mode (see
+// Options.CopyStrings) a string without escapes aliases the input.
+func (r *Reader) String() (string, error) {
+	raw, escaped, err := r.stringBytes()
+	if err != nil {
+		return "", err
+	}
+	if !escaped {
+		if r.zeroCopy {
+			return *(*string)(unsafe.Pointer(&raw)), nil
+		}
+		return string(raw), nil
+	}
+	r.scratch, err = parser.AppendUnescaped(r.scratch[:0], raw)
+	if err != nil {
+		return "", r.fail(err)
+	}
+	return string(r.scratch), nil
+}
+
+// Bytes consumes a []byte value in Options.BytesFormat, a base64-encoded
+// string by default. An array of numbers from 0 to 255 is accepted in any
+// format, as encoding/json accepts it.
+func (r *Reader) Bytes() ([]byte, error) {
+	return r.BytesAs(r.bytesFmt)
+}
+
+// BytesAs consumes a []byte value like Bytes, but in format f. Generated
+// code uses it for fields with a format tag option.
+func (r *Reader) BytesAs(f BytesFormat) ([]byte, error) {
+	if r.peek() == scanner.TokenArrayBegin {
+		return r.byteArray()
+	}
+	raw, escaped, err := r.stringBytes()
+	if err != nil {
+		return nil, err
+	}
+	if escaped {
+		if r.scratch, err = parser.AppendUnescaped(r.scratch[:0], raw); err != nil {
+			return nil, r.fail(err)
+		}
+		raw = r.scratch
+	}
+	b, err := decodeBytesText(raw, f)
+	if err != nil {
+		return nil, r.fail(err)
+	}
+	return b, nil
+}
+
+// byteArray consumes an array of numbers from 0 to 255.
+func (r *Reader) byteArray() ([]byte, error) {
+	r.pos++
+	b := []byte{}
+	for r.More() {
+		n, err := r.Uint(8)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, byte(n))
+	}
+	if err := r.EndArray(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Bool consumes true or false.
+func (r *Reader) Bool() (bool, error) {
+	switch r.peek() {
+	case scanner.TokenTrue:
+		r.pos++
+		return true, nil
+	case scanner.TokenFalse:
+		r.pos++
+		return false, nil
+	}
+	_, err := r.next(scanner.TokenTrue, "boolean")
+	return false, err
+}
+
+// Null consumes a null and reports true if the next value is null.
+func (r *Reader) Null() bool {
+	if r.err == nil && r.peek() == scanner.TokenNull {
+		r.pos++
+		return true
+	}
+	return false
+}
+
+func (r *Reader) number() (string, error) {
+	tok, err := r.next(scanner.TokenNumber, "number")
+	if err != nil {
+		return "", err
+	}
+	b := r.data[tok.Start:tok.End]
+	return *(*string)(unsafe.Pointer(&b)), nil
+}
+
+// Int consumes a number that fits a signed integer of bitSize bits, with
+// the same bitSize convention as strconv.ParseInt. Any other number is an
+// UnmarshalTypeError, unless Options.CoerceNumbers is set.
+func (r *Reader) Int(bitSize int) (int64, error) {
+	s, err := r.number()
+	if err != nil {
+		return 0, err
+	}
+	return r.parseInt(s, bitSize, nil)
+}
+
+// Uint consumes a number that fits an unsigned integer of bitSize bits.
+// Any other number is an UnmarshalTypeError, unless Options.CoerceNumbers
+// is set.
+func (r *Reader) Uint(bitSize int) (uint64, error) {
+	s, err := r.number()
+	if err != nil {
+		return 0, err
+	}
+	return r.parseUint(s, bitSize, nil)
+}
+
+// Float consumes a number as a float of bitSize (32 or 64) bits. A number
+// beyond the float's range is an UnmarshalTypeError, unless
+// Options.CoerceNumbers is set. With Options.AllowNonFinite, the strings
+// "NaN", "Infinity" and "-Infinity" are accepted too.
+func (r *Reader) Float(bitSize int) (float64, error) {
+	if f, ok := r.nonFiniteString(); ok {
+		return f, nil
+	}
+	s, err := r.number()
+	if err != nil {
+		return 0, err
+	}
+	return r.parseFloat(s, bitSize, nil)
+}
+
+// Number consumes a number and returns its text exactly as written, for
+// values that must not pass through a float64: big numbers, decimals and
+// identifiers longer than 53 bits.
+func (r *Reader) Number() (string, error) {
+	s, err := r.number()
+	if err != nil {
+		return "", err
+	}
+	return strings.Clone(s), nil
+}
+
+// parseInt parses the number s just consumed for a signed integer of
+// type t, or of the basic type of bitSize bits if t is nil. Coercion
+// truncates fractions toward zero and clamps to the type's range.
+func (r *Reader) parseInt(s string, bitSize int, t reflect.Type) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, bitSize)
+	if err == nil {
+		return n, nil
+	}
+	if !r.coerce {
+		return 0, r.numberError(s, t, reflect.Int, bitSize)
+	}
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+	max := int64(1)<<(bitSize-1) - 1
+	min := -max - 1
+	if err.(*strconv.NumError).Err == strconv.ErrRange {
+		if s[0] == '-' {
+			return min, nil
+		}
+		return max, nil
+	}
+	if dot := strings.IndexByte(s, '.'); dot > 0 && !strings.ContainsAny(s, "eE") {
+		// Plain decimals keep every digit of their integer part
+		if n, err := r.parseInt(s[:dot], bitSize, t); err == nil {
+			return n, nil
+		}
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	switch f = math.Trunc(f); {
+	case f >= float64(max):
+		return max, nil
+	case f <= float64(min):
+		return min, nil
+	}
+	return int64(f), nil
+}
+
+// parseUint is parseInt for an unsigned integer; coercion turns negative
+// numbers into 0.
+func (r *Reader) parseUint(s string, bitSize int, t reflect.Type) (uint64, error) {
+	n, err := strconv.ParseUint(s, 10, bitSize)
+	if err == nil {
+		return n, nil
+	}
+	if !r.coerce {
+		return 0, r.numberError(s, t, reflect.Uint, bitSize)
+	}
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+	max := uint64(1)<<(bitSize-1)<<1 - 1
+	if s[0] == '-' {
+		return 0, nil
+	}
+	if err.(*strconv.NumError).Err == strconv.ErrRange {
+		return max, nil
+	}
+	if dot := strings.IndexByte(s, '.'); dot > 0 && !strings.ContainsAny(s, "eE") {
+		if n, err := r.parseUint(s[:dot], bitSize, t); err == nil {
+			return n, nil
+		}
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	if f = math.Trunc(f); f >= float64(max) {
+		return max, nil
+	}
+	return uint64(f), nil
+}
+
+// parseFloat is parseInt for a float; coercion turns numbers beyond the
+// float's range into its largest finite value of the same sign.
+func (r *Reader) parseFloat(s string, bitSize int, t reflect.Type) (float64, error) {
+	f, err := strconv.ParseFloat(s, bitSize)
+	if err == nil || !math.IsInf(f, 0) {
+		return f, nil
+	}
+	if !r.coerce {
+		return 0, r.numberError(s, t, reflect.Float64, bitSize)
+	}
+	max := math.MaxFloat64
+	if bitSize == 32 {
+		max = math.MaxFloat32
+	}
+	return math.Copysign(max, f), nil
+}
+
+// numberError reports that the number s just consumed does not fit t, or
+// if t is nil the basic type of kind and bitSize.
+func (r *Reader) numberError(s string, t reflect.Type, kind reflect.Kind, bitSize int) error {
+	if t == nil {
+		t = basicNumberType(kind, bitSize)
+	}
+	return r.fail(&UnmarshalTypeError{
+		Value:  "number " + s,
+		Type:   t,
+		Offset: int64(r.tokens[r.pos-1].Start),
+	})
+}
+
+// Skip consumes the next value, whatever its type.
+func (r *Reader) Skip() error {
+	_, err := r.Raw()
+	return err
+}
+
+// Raw consumes the next value and returns its bytes in the document.
+func (r *Reader) Raw() ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	end, err := scanner.SkipTokens(r.tokens, r.pos)
+	if err != nil {
+		if r.pos >= len(r.tokens) {
+			err = errUnexpectedEnd
+		}
+		return nil, r.fail(err)
+	}
+	raw := r.data[r.tokens[r.pos].Start:r.tokens[end-1].End]
+	r.pos = end
+	return raw, nil
+}
+
+// Decode consumes the next value and stores it in v with Unmarshal. It is
+// the fallback generated code uses for types it has no fast path for.
+func (r *Reader) Decode(v interface{}) error {
+	if u, ok := v.(UnmarshalerFrom); ok && codecFor(reflect.TypeOf(v).Elem()) == nil {
+		return u.UnmarshalJSONFrom(r)
+	}
+	raw, err := r.Raw()
+	if err != nil {
+		return err
+	}
+	return Unmarshal(raw, v)
+}
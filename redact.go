@@ -0,0 +1,110 @@
+package simdjson
+
+import (
+	"strings"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/jsonenc"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// Redact returns a copy of data with the values at paths replaced by the
+// string replacement, for logging documents that hold secrets. A path is
+// the key of a member of the top-level object, or the keys leading to it
+// through nested objects separated by dots, where * matches any key:
+// "password" and "*.token" redact {"password":"a","auth":{"token":"b"}}
+// entirely. Arrays are transparent, so "users.password" also redacts the
+// password of every object in a users array, and a whole object or array
+// is redacted if its path is given.
+//
+// data is tokenized but not decoded, and everything that is not redacted
+// is copied as written.
+func Redact(data []byte, paths []string, replacement string) ([]byte, error) {
+	s := scanner.New()
+	defer s.Release()
+	d, err := parseDocument(s, data)
+	if err != nil {
+		return nil, err
+	}
+	defer d.release()
+
+	r := &redactor{d: d, dst: make([]byte, 0, len(data))}
+	r.replacement = append(r.replacement, '"')
+	r.replacement = jsonenc.AppendString(r.replacement, replacement, false)
+	r.replacement = append(r.replacement, '"')
+	r.walk(0, []*redactNode{newRedactTree(paths)})
+	return append(r.dst, data[r.copied:]...), nil
+}
+
+// redactNode is a key in the tree of redacted paths.
+type redactNode struct {
+	redact   bool
+	children map[string]*redactNode
+}
+
+func newRedactTree(paths []string) *redactNode {
+	root := &redactNode{}
+	for _, path := range paths {
+		node := root
+		for _, key := range strings.Split(path, ".") {
+			if node.children == nil {
+				node.children = make(map[string]*redactNode)
+			}
+			child := node.children[key]
+			if child == nil {
+				child = &redactNode{}
+				node.children[key] = child
+			}
+			node = child
+		}
+		node.redact = true
+	}
+	return root
+}
+
+type redactor struct {
+	d           *document
+	dst         []byte
+	copied      uint32 // offset in d.data up to which dst holds the output
+	replacement []byte
+}
+
+// walk redacts the members below nodes, the tree positions reached by the
+// path of the value at pos.
+func (r *redactor) walk(pos int, nodes []*redactNode) {
+	d := r.d
+	switch d.kind(pos) {
+	case scanner.TokenArrayBegin:
+		d.elements(pos, func(elem int) {
+			r.walk(elem, nodes)
+		})
+	case scanner.TokenObjectBegin:
+		d.members(pos, func(k, v int) {
+			key := string(d.str(k))
+			var next []*redactNode
+			for _, node := range nodes {
+				for _, child := range [2]*redactNode{node.children[key], node.children["*"]} {
+					if child == nil {
+						continue
+					}
+					if child.redact {
+						r.replace(v)
+						return
+					}
+					next = append(next, child)
+				}
+			}
+			if next != nil {
+				r.walk(v, next)
+			}
+		})
+	}
+}
+
+// replace copies the output up to the value at pos and writes the
+// replacement in its place.
+func (r *redactor) replace(pos int) {
+	d := r.d
+	r.dst = append(r.dst, d.data[r.copied:d.tokens[pos].Start]...)
+	r.dst = append(r.dst, r.replacement...)
+	r.copied = d.tokens[d.skip(pos)-1].End
+}
@@ -0,0 +1,52 @@
+package simdjson
+
+import (
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		paths    []string
+		expected string
+	}{
+		{"top-level member", `{"user": "a", "password": "p"}`, []string{"password"}, `{"user": "a", "password": "***"}`},
+		{"nested member", `{"auth": {"token": "t", "kind": "bearer"}}`, []string{"auth.token"}, `{"auth": {"token": "***", "kind": "bearer"}}`},
+		{"wildcard", `{"a": {"token": 1}, "b": {"token": [1, 2]}, "token": 3}`, []string{"*.token"}, `{"a": {"token": "***"}, "b": {"token": "***"}, "token": 3}`},
+		{"through arrays", `{"users": [{"password": "x"}, {"name": "b"}, [{"password": null}]]}`, []string{"users.password"}, `{"users": [{"password": "***"}, {"name": "b"}, [{"password": "***"}]]}`},
+		{"top-level array", `[{"password": "x"}, 1]`, []string{"password"}, `[{"password": "***"}, 1]`},
+		{"whole object", `{"card": {"number": "4111", "cvv": 123}, "id": 7}`, []string{"card"}, `{"card": "***", "id": 7}`},
+		{"several paths", `{"a": 1, "b": {"c": 2, "d": 3}}`, []string{"a", "b.d"}, `{"a": "***", "b": {"c": 2, "d": "***"}}`},
+		{"escaped key", `{"pass\u0077ord": "x"}`, []string{"password"}, `{"pass\u0077ord": "***"}`},
+		{"duplicate keys", `{"k": 1, "k": 2}`, []string{"k"}, `{"k": "***", "k": "***"}`},
+		{"no match", ` {"a": "x"} `, []string{"b", "a.b"}, ` {"a": "x"} `},
+		{"scalar document", `"password"`, []string{"password"}, `"password"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Redact([]byte(tt.input), tt.paths, "***")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRedactReplacement(t *testing.T) {
+	got, err := Redact([]byte(`{"a":1}`), []string{"a"}, `<"hidden">`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"a":"<\"hidden\">"}`; string(got) != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	if _, err := Redact([]byte(`{"a":`), []string{"a"}, ""); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,265 @@
+package simdjson
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// reportPath enables the report mode:
+//
+//	go test -run Report -report=report.json
+//
+// Use "-" to write the report to stdout.
+var reportPath = flag.String("report", "", "write a JSON conformance and performance report to this file (- for stdout)")
+
+// Report is the machine-readable summary produced by TestReport.
+type Report struct {
+	GoVersion   string             `json:"go_version"`
+	GOOS        string             `json:"goos"`
+	GOARCH      string             `json:"goarch"`
+	NumCPU      int                `json:"num_cpu"`
+	SIMD        bool               `json:"simd"`
+	Conformance ConformanceReport  `json:"conformance"`
+	Throughput  []ThroughputReport `json:"throughput"`
+	Allocations []AllocReport      `json:"allocations"`
+}
+
+// ConformanceReport records how often simdjson agrees with encoding/json.
+type ConformanceReport struct {
+	Cases    int      `json:"cases"`
+	Passed   int      `json:"passed"`
+	PassRate float64  `json:"pass_rate"`
+	Failures []string `json:"failures,omitempty"`
+}
+
+// ThroughputReport records the speed of one kernel over one payload.
+type ThroughputReport struct {
+	Kernel      string  `json:"kernel"`
+	Payload     string  `json:"payload"`
+	Bytes       int     `json:"bytes"`
+	NsPerOp     int64   `json:"ns_per_op"`
+	GBPerSec    float64 `json:"gb_per_sec"`
+	AllocsPerOp int64   `json:"allocs_per_op"`
+}
+
+// AllocReport records the allocation profile of one public API call.
+type AllocReport struct {
+	Operation   string `json:"operation"`
+	AllocsPerOp int64  `json:"allocs_per_op"`
+	BytesPerOp  int64  `json:"bytes_per_op"`
+}
+
+// reportCases are inputs checked for agreement with encoding/json. Each
+// input must be accepted or rejected by Valid and Unmarshal exactly as the
+// standard library does.
+var reportCases = []string{
+	// Accepted
+	`null`, `true`, `false`, `0`, `-0`, `42`, `-123`, `3.14`, `1e10`, `-1.5E-7`,
+	`""`, `"hello"`, `"esc \" \\ \/ \b \f \n \r \t"`, `"\u00e9\u4e16"`, `"Hello 世界 🌍"`,
+	`[]`, `[1,2,3]`, `[1,"two",true,null]`, `[[[]]]`,
+	`{}`, `{"key":"value"}`, `{"a":{"b":[1,{"c":null}]}}`,
+	" \t\n{\n\t \"key\" \t:\n \"value\" \t\n} \n\t ",
+
+	// Rejected
+	``, ` `, `{`, `}`, `[`, `]`, `[1,2,3`, `{"key":"value"`, `{"key":"value",}`,
+	`[1,]`, `[1,,2]`, `{"key:value}`, `{"a" 1}`, `{"a":}`, `{1:2}`, `[1 2]`,
+	`01`, `12.`, `.5`, `-`, `+1`, `1e`, `0x10`, `NaN`, `Infinity`,
+	`tru`, `nul`, `True`, `"abc`, `"\x"`, `"\u12"`, `{"key":"val\ue"}`,
+	"\"\x00\"", "\"a\nb\"", `[1] [2]`, `{} x`,
+}
+
+func TestReport(t *testing.T) {
+	if *reportPath == "" {
+		t.Skip("use -report=<file> to generate the report")
+	}
+
+	report := Report{
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+		NumCPU:    runtime.NumCPU(),
+		SIMD:      scanner.HasSIMD(),
+	}
+
+	report.Conformance = reportConformance()
+	report.Throughput = reportThroughput()
+	report.Allocations = reportAllocations()
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to encode report: %v", err)
+	}
+	out = append(out, '\n')
+
+	if *reportPath == "-" {
+		os.Stdout.Write(out)
+		return
+	}
+	if err := os.WriteFile(*reportPath, out, 0o644); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	t.Logf("Report written to %s (conformance %.1f%%)", *reportPath, report.Conformance.PassRate*100)
+}
+
+func reportConformance() ConformanceReport {
+	var r ConformanceReport
+	for _, input := range reportCases {
+		r.Cases++
+
+		data := []byte(input)
+		var stdResult, ourResult interface{}
+		stdErr := json.Unmarshal(data, &stdResult)
+		ourErr := Unmarshal(data, &ourResult)
+
+		switch {
+		case json.Valid(data) != Valid(data):
+			r.Failures = append(r.Failures, fmt.Sprintf("Valid(%q)", input))
+		case (stdErr == nil) != (ourErr == nil):
+			r.Failures = append(r.Failures, fmt.Sprintf("Unmarshal(%q) error", input))
+		case stdErr == nil && !deepEqual(stdResult, ourResult):
+			r.Failures = append(r.Failures, fmt.Sprintf("Unmarshal(%q) result", input))
+		default:
+			r.Passed++
+		}
+	}
+	r.PassRate = float64(r.Passed) / float64(r.Cases)
+	return r
+}
+
+func reportThroughput() []ThroughputReport {
+	payloads := []struct {
+		name string
+		data []byte
+	}{
+		{"small", []byte(`{"name":"John","age":30,"city":"New York"}`)},
+		{"complex", generateComplexJSON()},
+		{"large_array", []byte(createLargeArray(10000))},
+		{"strings", []byte(reportStringsPayload(1000))},
+	}
+
+	kernels := []struct {
+		name string
+		run  func(s *scanner.Scanner, data []byte)
+	}{
+		{"scan", func(s *scanner.Scanner, data []byte) { s.Scan(data) }},
+		{"tokenize", func(s *scanner.Scanner, data []byte) {
+			if tokens, err := s.SimpleTokenize(data); err == nil {
+				scanner.PutTokenSlice(tokens)
+			}
+		}},
+		{"validate", func(s *scanner.Scanner, data []byte) { s.Validate(data) }},
+	}
+	if scanner.HasSIMD() {
+		kernels = append(kernels, struct {
+			name string
+			run  func(s *scanner.Scanner, data []byte)
+		}{"scan_simd", func(s *scanner.Scanner, data []byte) { s.ScanSIMD(data) }})
+	}
+
+	var results []ThroughputReport
+	for _, p := range payloads {
+		for _, k := range kernels {
+			data, run := p.data, k.run
+			res := testing.Benchmark(func(b *testing.B) {
+				s := scanner.New()
+				defer s.Release()
+				b.SetBytes(int64(len(data)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					run(s, data)
+				}
+			})
+			results = append(results, newThroughputReport(k.name, p.name, len(data), res))
+		}
+
+		data := p.data
+		res := testing.Benchmark(func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var v interface{}
+				Unmarshal(data, &v)
+			}
+		})
+		results = append(results, newThroughputReport("unmarshal", p.name, len(data), res))
+	}
+	return results
+}
+
+func newThroughputReport(kernel, payload string, size int, res testing.BenchmarkResult) ThroughputReport {
+	r := ThroughputReport{
+		Kernel:      kernel,
+		Payload:     payload,
+		Bytes:       size,
+		NsPerOp:     res.NsPerOp(),
+		AllocsPerOp: res.AllocsPerOp(),
+	}
+	if res.T > 0 {
+		// bytes per nanosecond is numerically equal to GB/s
+		r.GBPerSec = float64(res.Bytes) * float64(res.N) / float64(res.T.Nanoseconds())
+	}
+	return r
+}
+
+func reportAllocations() []AllocReport {
+	type small struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+		City string `json:"city"`
+	}
+	data := []byte(`{"name":"John","age":30,"city":"New York"}`)
+	value := small{Name: "John", Age: 30, City: "New York"}
+
+	ops := []struct {
+		name string
+		run  func()
+	}{
+		{"Valid/small", func() { Valid(data) }},
+		{"Unmarshal/small_struct", func() {
+			var v small
+			Unmarshal(data, &v)
+		}},
+		{"Unmarshal/small_interface", func() {
+			var v interface{}
+			Unmarshal(data, &v)
+		}},
+		{"Marshal/small_struct", func() { Marshal(value) }},
+	}
+
+	var results []AllocReport
+	for _, op := range ops {
+		run := op.run
+		res := testing.Benchmark(func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				run()
+			}
+		})
+		results = append(results, AllocReport{
+			Operation:   op.name,
+			AllocsPerOp: res.AllocsPerOp(),
+			BytesPerOp:  res.AllocedBytesPerOp(),
+		})
+	}
+	return results
+}
+
+func reportStringsPayload(n int) string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `"string value number %d with \"escapes\" and unicode 世界"`, i)
+	}
+	b.WriteString("]")
+	return b.String()
+}
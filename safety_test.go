@@ -1,8 +1,13 @@
 package simdjson
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"runtime"
+	"strings"
 	"testing"
 	"unsafe"
 	
@@ -265,6 +270,132 @@ func TestRaceConditions(t *testing.T) {
 	}
 }
 
+// TestEncoderConcurrent encodes from many goroutines through one Encoder
+// into a writer that is not safe for concurrent use
+func TestEncoderConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+
+	const goroutines, iterations = 16, 100
+	done := make(chan error, goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func(i int) {
+			v := map[string]interface{}{"goroutine": i, "data": strings.Repeat("<x>", i*50)}
+			var err error
+			for j := 0; j < iterations && err == nil; j++ {
+				if j == iterations/2 {
+					enc.SetEscapeHTML(i%2 == 0)
+				}
+				err = enc.Encode(v)
+			}
+			done <- err
+		}(i)
+	}
+	for i := 0; i < goroutines; i++ {
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Every value must have been written whole
+	dec := json.NewDecoder(&buf)
+	n := 0
+	for dec.More() {
+		var v struct {
+			Goroutine int
+			Data      string
+		}
+		if err := dec.Decode(&v); err != nil {
+			t.Fatalf("Value %d: %v", n, err)
+		}
+		if v.Data != strings.Repeat("<x>", v.Goroutine*50) {
+			t.Fatalf("Value %d: corrupted data", n)
+		}
+		n++
+	}
+	if n != goroutines*iterations {
+		t.Errorf("Expected %d values, got %d", goroutines*iterations, n)
+	}
+}
+
+// recordingWriter records the size of each write
+type recordingWriter struct {
+	bytes.Buffer
+	writes []int
+	fail   bool
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	if w.fail {
+		return 0, errors.New("write failed")
+	}
+	w.writes = append(w.writes, len(p))
+	return w.Buffer.Write(p)
+}
+
+// TestEncoderFlushSize checks that streaming bounds the size of each write
+// without changing the output
+func TestEncoderFlushSize(t *testing.T) {
+	type row struct {
+		ID   int               `json:"id"`
+		Name string            `json:"name"`
+		Tags map[string]string `json:"tags"`
+	}
+	rows := make([]row, 10000)
+	for i := range rows {
+		rows[i] = row{ID: i, Name: strings.Repeat("n", i%50), Tags: map[string]string{"k": "v"}}
+	}
+	v := map[string]interface{}{"rows": rows, "matrix": [][]int{{1, 2}, {3, 4}}}
+
+	expected, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var w recordingWriter
+	enc := NewEncoder(&w)
+	enc.SetFlushSize(4096)
+	if err := enc.Encode(v); err != nil {
+		t.Fatal(err)
+	}
+	if w.String() != string(expected) {
+		t.Fatal("Streamed output differs from Marshal")
+	}
+	if len(w.writes) < len(expected)/4096 {
+		t.Errorf("Expected at least %d writes, got %d", len(expected)/4096, len(w.writes))
+	}
+	for _, n := range w.writes {
+		if n > 4096+200 {
+			t.Errorf("Write of %d bytes exceeds the flush size", n)
+		}
+	}
+
+	// Without a flush size the value is written once
+	w = recordingWriter{}
+	enc.SetFlushSize(0)
+	if err := enc.Encode(v); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.writes) != 1 {
+		t.Errorf("Expected 1 write, got %d", len(w.writes))
+	}
+
+	// Errors midway leave the output written so far
+	w = recordingWriter{}
+	enc.SetFlushSize(16)
+	if err := enc.Encode([]interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, math.NaN()}); err == nil {
+		t.Error("Expected error for NaN")
+	}
+	if !strings.HasPrefix(w.String(), "[1,2,3") {
+		t.Errorf("Expected partial output, got %q", w.String())
+	}
+
+	w = recordingWriter{fail: true}
+	if err := enc.Encode(rows); err == nil {
+		t.Error("Expected write error")
+	}
+}
+
 // TestMemoryLeaks tests for memory leaks in pooled objects
 func TestMemoryLeaks(t *testing.T) {
 	var m1, m2 runtime.MemStats
@@ -0,0 +1,193 @@
+// Package scanner exposes the stage-1 primitives simdjson is built on:
+// structural character indexing, string quote masks, UTF-8 validation and
+// NDJSON record framing. They let custom parsers reuse the SIMD kernels
+// without forking the module. The API in this package is stable.
+package scanner
+
+import (
+	"bytes"
+	"iter"
+
+	internal "github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// Scanner indexes the structural characters of JSON documents. A Scanner
+// holds reusable buffers and is not safe for concurrent use; obtain one per
+// goroutine with New and return it with Release.
+type Scanner struct {
+	s *internal.Scanner
+}
+
+// New returns a pooled Scanner.
+func New() *Scanner {
+	return &Scanner{s: internal.New()}
+}
+
+// Release returns the scanner's buffers to the pool. The scanner and any
+// slices it returned must not be used afterwards.
+func (s *Scanner) Release() {
+	if s.s != nil {
+		s.s.Release()
+		s.s = nil
+	}
+}
+
+// StructuralIndices returns the byte offsets of the structural characters
+// ({ } [ ] : , and string quotes) and value starts in data, in increasing
+// order. The returned slice is owned by the scanner and is only valid until
+// the next call.
+func (s *Scanner) StructuralIndices(data []byte) ([]uint32, error) {
+	if err := s.s.Scan(data); err != nil {
+		return nil, err
+	}
+	return s.s.GetStructuralIndices(), nil
+}
+
+// SkipValue returns the position, in the indices returned by the last
+// StructuralIndices call, of the first structural character after the value
+// starting at position pos. Nested objects and arrays are skipped by
+// matching brackets without examining their contents.
+func (s *Scanner) SkipValue(pos int) (int, error) {
+	return s.s.SkipValue(pos)
+}
+
+// Feed scans the next chunk of a document that arrives incrementally.
+// String and escape state is carried across chunks, so chunks may be split
+// at any byte. After Feed, Indices returns the structural indices of this
+// chunk as offsets into chunk, and Offset reports the stream position of
+// its first byte. Call Finish once the document is complete.
+func (s *Scanner) Feed(chunk []byte) error {
+	return s.s.Feed(chunk)
+}
+
+// Indices returns the structural indices produced by the last Feed.
+func (s *Scanner) Indices() []uint32 {
+	return s.s.GetStructuralIndices()
+}
+
+// Offset returns the stream offset of the chunk passed to the last Feed.
+func (s *Scanner) Offset() int64 {
+	return s.s.Offset()
+}
+
+// Finish completes the document being fed, reporting an error if it ended
+// inside a string, and prepares the scanner for the next document.
+func (s *Scanner) Finish() error {
+	return s.s.Finish()
+}
+
+// HasSIMD reports whether a SIMD kernel is available on this CPU.
+func HasSIMD() bool {
+	return internal.HasSIMD()
+}
+
+// QuoteMask appends to dst one uint64 per 64-byte block of data. Bit i of
+// word n is set when data[n*64+i] is a quote that opens or closes a string,
+// that is, one not escaped by a backslash.
+func QuoteMask(data []byte, dst []uint64) []uint64 {
+	return internal.QuoteMask(data, dst)
+}
+
+// BlockState carries string and escape state from one 64-byte block of
+// JSON text to the next, for kernels that classify text a block at a time
+// as simdjson's stage 1 does. An escape or string may straddle any block
+// boundary. The zero BlockState is at the start of a document.
+type BlockState struct {
+	s internal.BlockState
+}
+
+// Next classifies block, which must be 64 bytes long unless it ends the
+// input. Bit i of quotes is set when block[i] opens or closes a string,
+// and bit i of inString when block[i] is the opening quote or contents of
+// a string.
+//
+//	var st scanner.BlockState
+//	for len(data) > 0 {
+//		n := min(len(data), 64)
+//		quotes, inString := st.Next(data[:n])
+//		...
+//		data = data[n:]
+//	}
+func (b *BlockState) Next(block []byte) (quotes, inString uint64) {
+	return b.s.Next(block)
+}
+
+// InString reports whether the last block classified ended inside a
+// string.
+func (b *BlockState) InString() bool {
+	return b.s.InString()
+}
+
+// ValidUTF8 reports whether data is entirely valid UTF-8.
+func ValidUTF8(data []byte) bool {
+	s := internal.New()
+	defer s.Release()
+
+	return s.SIMDValidateUTF8(data)
+}
+
+// NextRecord splits the first NDJSON record off data. Records are separated
+// by '\n' (optionally preceded by '\r'); blank lines are skipped. Raw
+// newlines cannot appear inside valid JSON strings, so no quote tracking is
+// needed. NextRecord returns a nil record when data holds no more records.
+func NextRecord(data []byte) (record, rest []byte) {
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			record, rest = data, nil
+		} else {
+			record, rest = data[:i], data[i+1:]
+		}
+		record = bytes.TrimRight(record, "\r")
+		if len(bytes.TrimSpace(record)) > 0 {
+			return record, rest
+		}
+		data = rest
+	}
+	return nil, nil
+}
+
+// Records returns an iterator over the NDJSON records of data, framed as
+// by NextRecord, and the 1-based line number of each, for error messages.
+// Newlines are found with bytes.IndexByte, which uses the CPU's vector
+// instructions; a newline escaped inside a string is the two bytes \n and
+// never ends a record. The records alias data.
+//
+//	for line, record := range scanner.Records(data) {
+//		if err := simdjson.Unmarshal(record, &v); err != nil {
+//			return fmt.Errorf("line %d: %w", line, err)
+//		}
+//	}
+func Records(data []byte) iter.Seq2[int, []byte] {
+	return func(yield func(int, []byte) bool) {
+		rest := data
+		for line := 1; len(rest) > 0; line++ {
+			record := rest
+			if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+				record, rest = rest[:i], rest[i+1:]
+			} else {
+				rest = nil
+			}
+			record = bytes.TrimRight(record, "\r")
+			if len(bytes.TrimSpace(record)) == 0 {
+				continue
+			}
+			if !yield(line, record) {
+				return
+			}
+		}
+	}
+}
+
+// SplitNDJSON appends the records of an NDJSON document to dst, as framed
+// by NextRecord. The records alias data.
+func SplitNDJSON(data []byte, dst [][]byte) [][]byte {
+	for {
+		var record []byte
+		record, data = NextRecord(data)
+		if record == nil {
+			return dst
+		}
+		dst = append(dst, record)
+	}
+}
@@ -0,0 +1,205 @@
+package scanner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStructuralIndices(t *testing.T) {
+	s := New()
+	defer s.Release()
+
+	data := []byte(`{"a":[1,2]}`)
+	indices, err := s.StructuralIndices(data)
+	if err != nil {
+		t.Fatalf("StructuralIndices failed: %v", err)
+	}
+
+	// Every structural character must be reported
+	want := map[byte]bool{'{': true, '}': true, '[': true, ']': true, ':': true, ',': true, '"': true}
+	found := make(map[int]bool)
+	for _, idx := range indices {
+		found[int(idx)] = true
+	}
+	for i, c := range data {
+		if want[c] && !found[i] {
+			t.Errorf("Structural character %q at %d not reported", c, i)
+		}
+	}
+	for i := 1; i < len(indices); i++ {
+		if indices[i] <= indices[i-1] {
+			t.Errorf("Indices not increasing at %d: %v", i, indices)
+		}
+	}
+}
+
+func TestQuoteMask(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		quotes []int
+	}{
+		{"simple", `"ab"`, []int{0, 3}},
+		{"escaped quote", `"a\"b"`, []int{0, 5}},
+		{"escaped backslash", `"a\\"`, []int{0, 4}},
+		{"none", `123`, nil},
+		{"second block", strings.Repeat(" ", 70) + `""`, []int{70, 71}},
+		{"block boundary", strings.Repeat(" ", 62) + `"\"x"`, []int{62, 66}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			masks := QuoteMask([]byte(tt.input), nil)
+			if len(masks) != (len(tt.input)+63)/64 {
+				t.Fatalf("Expected %d masks, got %d", (len(tt.input)+63)/64, len(masks))
+			}
+
+			var got []int
+			for i := range tt.input {
+				if masks[i/64]&(1<<(uint(i)%64)) != 0 {
+					got = append(got, i)
+				}
+			}
+			if len(got) != len(tt.quotes) {
+				t.Fatalf("Expected quotes at %v, got %v", tt.quotes, got)
+			}
+			for i := range got {
+				if got[i] != tt.quotes[i] {
+					t.Fatalf("Expected quotes at %v, got %v", tt.quotes, got)
+				}
+			}
+		})
+	}
+}
+
+func TestBlockState(t *testing.T) {
+	// The string opens in the first block and an escaped quote straddles
+	// the boundary
+	data := []byte(strings.Repeat(" ", 60) + `"ab\` + `"c"  `)
+	var st BlockState
+	quotes, inString := st.Next(data[:64])
+	if quotes != 1<<60 || inString != ^uint64(1<<60-1) || !st.InString() {
+		t.Errorf("Expected the first block to open a string at 60, got %#x %#x", quotes, inString)
+	}
+	quotes, inString = st.Next(data[64:])
+	if quotes != 1<<2 || inString != 0b11 || st.InString() {
+		t.Errorf("Expected the second block to close it at 2, got %#x %#x", quotes, inString)
+	}
+}
+
+func TestValidUTF8(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"hello", true},
+		{"", true},
+		{"héllo 世界 😀", true},
+		{"\xff", false},
+		{"\xe4\xb8", false},
+		{"\xed\xa0\x80", false}, // surrogate
+	}
+
+	for _, tt := range tests {
+		if got := ValidUTF8([]byte(tt.input)); got != tt.expected {
+			t.Errorf("ValidUTF8(%q) = %v, expected %v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSplitNDJSON(t *testing.T) {
+	data := []byte("{\"a\":1}\n\n{\"b\":\"x\\ny\"}\r\n  \n[3]")
+	records := SplitNDJSON(data, nil)
+
+	expected := []string{`{"a":1}`, `{"b":"x\ny"}`, `[3]`}
+	if len(records) != len(expected) {
+		t.Fatalf("Expected %d records, got %d: %q", len(expected), len(records), records)
+	}
+	for i := range expected {
+		if string(records[i]) != expected[i] {
+			t.Errorf("Record %d: expected %q, got %q", i, expected[i], records[i])
+		}
+	}
+
+	if record, rest := NextRecord([]byte("\n\n")); record != nil || rest != nil {
+		t.Errorf("Expected no record, got %q, %q", record, rest)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	data := []byte("{\"a\":1}\n\n{\"b\":\"x\\ny\"}\r\n  \n[3]")
+
+	var lines []int
+	var records []string
+	for line, record := range Records(data) {
+		lines = append(lines, line)
+		records = append(records, string(record))
+	}
+	expected := []string{`{"a":1}`, `{"b":"x\ny"}`, `[3]`}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Expected records %q, got %q", expected, records)
+	}
+	if !reflect.DeepEqual(lines, []int{1, 3, 5}) {
+		t.Errorf("Expected lines [1 3 5], got %v", lines)
+	}
+
+	// Breaking out stops the iteration; a new range starts over
+	for range Records(data) {
+		break
+	}
+	n := 0
+	for range Records(data) {
+		n++
+	}
+	if n != 3 {
+		t.Errorf("Expected 3 records on a second range, got %d", n)
+	}
+}
+
+func TestFeed(t *testing.T) {
+	s := New()
+	defer s.Release()
+
+	var got []int64
+	for _, chunk := range []string{`["a\`, `"b",`, `1]`} {
+		if err := s.Feed([]byte(chunk)); err != nil {
+			t.Fatalf("Feed failed: %v", err)
+		}
+		for _, idx := range s.Indices() {
+			got = append(got, s.Offset()+int64(idx))
+		}
+	}
+	if err := s.Finish(); err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+
+	expected := []int64{0, 1, 6, 7, 8, 9}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestSkipValue(t *testing.T) {
+	s := New()
+	defer s.Release()
+
+	data := []byte(`[{"a":[1,2]},"x"]`)
+	indices, err := s.StructuralIndices(data)
+	if err != nil {
+		t.Fatalf("StructuralIndices failed: %v", err)
+	}
+
+	next, err := s.SkipValue(1)
+	if err != nil {
+		t.Fatalf("SkipValue failed: %v", err)
+	}
+	if data[indices[next]] != ',' || indices[next] != 12 {
+		t.Errorf("Expected the comma at offset 12, got offset %d", indices[next])
+	}
+}
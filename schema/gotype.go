@@ -0,0 +1,150 @@
+package schema
+
+import (
+	"bytes"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GoType returns Go source declaring a type named name for the values s
+// describes, followed by a type for each kind of nested object, named
+// after the member path leading to it. Integers become int64 and other
+// numbers float64; members missing from some objects get omitempty, and
+// values that may be null are pointers, except slices and maps, as are
+// nested structs that may be missing. Values of mixed types are
+// interface{}.
+func (s *Schema) GoType(name string) ([]byte, error) {
+	return s.goSource("", name)
+}
+
+// GoFile returns a Go source file in package pkg holding the declarations
+// GoType returns.
+func (s *Schema) GoFile(pkg, name string) ([]byte, error) {
+	return s.goSource("package "+pkg+"\n", name)
+}
+
+func (s *Schema) goSource(header, name string) ([]byte, error) {
+	g := &goWriter{names: map[string]bool{name: true}}
+	g.buf.WriteString(header)
+	g.decl(name, s)
+	for i := 0; i < len(g.pending); i++ {
+		g.decl(g.pending[i].name, g.pending[i].schema)
+	}
+	return format.Source(g.buf.Bytes())
+}
+
+type goWriter struct {
+	buf     bytes.Buffer
+	names   map[string]bool // type names in use
+	pending []namedSchema   // nested struct types still to declare
+}
+
+type namedSchema struct {
+	name   string
+	schema *Schema
+}
+
+// decl writes the declaration of the type name for s.
+func (g *goWriter) decl(name string, s *Schema) {
+	if g.buf.Len() > 0 {
+		g.buf.WriteString("\n")
+	}
+	g.buf.WriteString("type " + name + " ")
+	if s.Types&^Null != Object || len(s.Fields) == 0 {
+		g.buf.WriteString(g.typeOf(name, s) + "\n")
+		return
+	}
+
+	g.buf.WriteString("struct {\n")
+	used := map[string]bool{}
+	for _, f := range s.Fields {
+		field := uniqueName(goName(f.Name), used)
+		tag := f.Name
+		if !s.Required(f) {
+			tag += ",omitempty"
+		}
+		typ := g.typeOf(name+field, f.Schema)
+		if !s.Required(f) && f.Schema.Types == Object && len(f.Schema.Fields) > 0 {
+			// omitempty has no effect on a struct
+			typ = "*" + typ
+		}
+		g.buf.WriteString(field + " " + typ + " `json:" + strconv.Quote(tag) + "`\n")
+	}
+	g.buf.WriteString("}\n")
+}
+
+// typeOf returns the Go type for s, queuing a declaration named name if it
+// is a struct.
+func (g *goWriter) typeOf(name string, s *Schema) string {
+	var t string
+	switch s.Types &^ Null {
+	case Bool:
+		t = "bool"
+	case Integer:
+		t = "int64"
+	case Number, Integer | Number:
+		t = "float64"
+	case String:
+		t = "string"
+	case Array:
+		item := "interface{}"
+		if s.Items != nil {
+			item = g.typeOf(name+"Item", s.Items)
+		}
+		return "[]" + item
+	case Object:
+		if len(s.Fields) == 0 {
+			return "map[string]interface{}"
+		}
+		t = uniqueName(name, g.names)
+		g.pending = append(g.pending, namedSchema{t, s})
+	default:
+		return "interface{}"
+	}
+	if s.Types&Null != 0 {
+		return "*" + t
+	}
+	return t
+}
+
+// initialisms are written in capitals in Go names, as golint suggests.
+var initialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URI": true, "URL": true, "UUID": true,
+}
+
+// goName turns a JSON member name into an exported Go identifier:
+// "user_id" becomes UserID and "created-at" CreatedAt.
+func goName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		if upper := strings.ToUpper(w); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	name := b.String()
+	if name == "" || !unicode.IsUpper([]rune(name)[0]) {
+		name = "F" + name
+	}
+	return name
+}
+
+// uniqueName returns name, or name with a number appended if it is in
+// used, and adds the result to used.
+func uniqueName(name string, used map[string]bool) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
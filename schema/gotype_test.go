@@ -0,0 +1,95 @@
+package schema
+
+import (
+	"testing"
+)
+
+func TestGoType(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  string
+		expected string
+	}{
+		{"scalar", `1 2`, "type Count int64\n"},
+		{"nullable", `1.5 null`, "type Count *float64\n"},
+		{"mixed", `1 "a"`, "type Count interface{}\n"},
+		{"array", `[[1.5]]`, "type Count [][]float64\n"},
+		{"empty object", `{}`, "type Count map[string]interface{}\n"},
+		{
+			"struct",
+			`{"user_id": 1, "created-at": "t", "meta": {"ok": true}, "items": [{"sku": "a"}], "2fa": false}
+			 {"user_id": 2, "created-at": null, "meta": null, "items": []}`,
+			"type Count struct {\n" +
+				"\tUserID    int64            `json:\"user_id\"`\n" +
+				"\tCreatedAt *string          `json:\"created-at\"`\n" +
+				"\tMeta      *CountMeta       `json:\"meta\"`\n" +
+				"\tItems     []CountItemsItem `json:\"items\"`\n" +
+				"\tF2fa      bool             `json:\"2fa,omitempty\"`\n" +
+				"}\n\n" +
+				"type CountMeta struct {\n" +
+				"\tOk bool `json:\"ok\"`\n" +
+				"}\n\n" +
+				"type CountItemsItem struct {\n" +
+				"\tSku string `json:\"sku\"`\n" +
+				"}\n",
+		},
+		{
+			"name clashes",
+			`{"a_b": 1, "a-b": "x"}`,
+			"type Count struct {\n" +
+				"\tAB  int64  `json:\"a_b\"`\n" +
+				"\tAB2 string `json:\"a-b\"`\n" +
+				"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Infer([]byte(tt.samples))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.GoType("Count")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"name":     "Name",
+		"user_id":  "UserID",
+		"apiKey":   "ApiKey",
+		"http-url": "HTTPURL",
+		"café":     "Café",
+		"_private": "Private",
+		"123":      "F123",
+		"":         "F",
+		"日本":       "F日本",
+	}
+	for key, expected := range tests {
+		if got := goName(key); got != expected {
+			t.Errorf("Expected %s for %q, got %s", expected, key, got)
+		}
+	}
+}
+
+func TestGoFile(t *testing.T) {
+	s, err := Infer([]byte(`{"a": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.GoFile("models", "Thing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "package models\n\ntype Thing struct {\n\tA int64 `json:\"a\"`\n}\n"
+	if string(got) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,109 @@
+package schema
+
+import (
+	"bytes"
+
+	"github.com/biggeezerdevelopment/simdjson-go/jsontext"
+)
+
+// JSONSchemaDialect is the JSON Schema version JSONSchema writes.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns s as a JSON Schema that accepts every sample s has
+// seen: the types of each value, the range of numbers, the members of
+// objects, with those present in every object required, and the elements
+// of arrays. Members appear in order of first appearance.
+func (s *Schema) JSONSchema() ([]byte, error) {
+	var buf bytes.Buffer
+	w := &schemaWriter{e: jsontext.NewEncoder(&buf)}
+	w.write(jsontext.BeginObject, jsontext.String("$schema"), jsontext.String(JSONSchemaDialect))
+	w.members(s)
+	w.write(jsontext.EndObject)
+	if w.err != nil {
+		return nil, w.err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// schemaWriter writes tokens, keeping the first error.
+type schemaWriter struct {
+	e   *jsontext.Encoder
+	err error
+}
+
+func (w *schemaWriter) write(tokens ...jsontext.Token) {
+	for _, tok := range tokens {
+		if w.err == nil {
+			w.err = w.e.WriteToken(tok)
+		}
+	}
+}
+
+// schema writes the JSON Schema of s as an object.
+func (w *schemaWriter) schema(s *Schema) {
+	w.write(jsontext.BeginObject)
+	w.members(s)
+	w.write(jsontext.EndObject)
+}
+
+// members writes the keywords of the JSON Schema of s.
+func (w *schemaWriter) members(s *Schema) {
+	types := s.Types
+	if types&Number != 0 {
+		// Integers are numbers
+		types &^= Integer
+	}
+	switch names := typeList(types); len(names) {
+	case 0:
+		// Nothing seen, so anything goes
+	case 1:
+		w.write(jsontext.String("type"), jsontext.String(names[0]))
+	default:
+		w.write(jsontext.String("type"), jsontext.BeginArray)
+		for _, name := range names {
+			w.write(jsontext.String(name))
+		}
+		w.write(jsontext.EndArray)
+	}
+
+	if s.Types&(Integer|Number) != 0 {
+		w.write(jsontext.String("minimum"), jsontext.Float(s.Min))
+		w.write(jsontext.String("maximum"), jsontext.Float(s.Max))
+	}
+
+	if len(s.Fields) > 0 {
+		w.write(jsontext.String("properties"), jsontext.BeginObject)
+		for _, f := range s.Fields {
+			w.write(jsontext.String(f.Name))
+			w.schema(f.Schema)
+		}
+		w.write(jsontext.EndObject)
+
+		var required []jsontext.Token
+		for _, f := range s.Fields {
+			if s.Required(f) {
+				required = append(required, jsontext.String(f.Name))
+			}
+		}
+		if len(required) > 0 {
+			w.write(jsontext.String("required"), jsontext.BeginArray)
+			w.write(required...)
+			w.write(jsontext.EndArray)
+		}
+	}
+
+	if s.Items != nil && s.Items.Count > 0 {
+		w.write(jsontext.String("items"))
+		w.schema(s.Items)
+	}
+}
+
+func typeList(t Type) []string {
+	var names []string
+	for i, name := range typeNames {
+		if t&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
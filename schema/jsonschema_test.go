@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"testing"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+)
+
+func TestJSONSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  string
+		expected string
+	}{
+		{"empty", ``, `{"$schema":"` + JSONSchemaDialect + `"}`},
+		{"scalar", `1 2.5`, `{"$schema":"` + JSONSchemaDialect + `","type":"number","minimum":1,"maximum":2.5}`},
+		{"mixed", `"a" null`, `{"$schema":"` + JSONSchemaDialect + `","type":["null","string"]}`},
+		{
+			"object",
+			`{"id": 1, "tags": ["x"]} {"id": 2, "note": null}`,
+			`{"$schema":"` + JSONSchemaDialect + `","type":"object","properties":{"id":{"type":"integer","minimum":1,"maximum":2},` +
+				`"tags":{"type":"array","items":{"type":"string"}},"note":{"type":"null"}},"required":["id"]}`,
+		},
+		{"empty arrays", `[[]]`, `{"$schema":"` + JSONSchemaDialect + `","type":"array","items":{"type":"array"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Infer([]byte(tt.samples))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.JSONSchema()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+			if !simdjson.Valid(got) {
+				t.Error("Expected valid JSON")
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// Package schema infers the shape of JSON documents from samples: the
+// members objects have and how often, the types each value takes and the
+// range of numbers. The samples are read as tokens with jsontext, never
+// decoded, so large corpora and NDJSON logs scan quickly, and the merged
+// Schema can be written as a JSON Schema or as Go type definitions.
+package schema
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/biggeezerdevelopment/simdjson-go/jsontext"
+)
+
+// Type is a set of JSON types.
+type Type uint8
+
+const (
+	Null Type = 1 << iota
+	Bool
+	Integer // a number written without a fraction or exponent
+	Number  // any other number
+	String
+	Object
+	Array
+)
+
+var typeNames = [...]string{"null", "boolean", "integer", "number", "string", "object", "array"}
+
+// String lists the types in t, as JSON Schema names them, separated by
+// "|".
+func (t Type) String() string {
+	if t == 0 {
+		return "none"
+	}
+	return strings.Join(typeList(t), "|")
+}
+
+// A Schema describes the values found at one place in the samples: the
+// top level, a member of objects, or the elements of arrays. The zero
+// Schema has seen no values.
+type Schema struct {
+	Types Type // the types of the values seen
+	Count int  // values seen, nulls included
+
+	// Min and Max bound the numbers seen, if Types has Integer or Number.
+	Min, Max float64
+
+	// Objects is the number of objects seen, and Fields their members in
+	// order of first appearance. A member present in every object has
+	// Present == Objects.
+	Objects int
+	Fields  []*Field
+
+	// Items describes the elements of the arrays seen, nil if there were
+	// none.
+	Items *Schema
+
+	fields map[string]*Field
+}
+
+// A Field is a member of the objects a Schema has seen.
+type Field struct {
+	Name    string
+	Present int // objects the member was in
+	Schema  *Schema
+
+	lastObject int // the Objects count of the object it was last seen in
+}
+
+// Infer returns the Schema of the JSON values in data, which may hold any
+// number of them, separated by whitespace as in NDJSON.
+func Infer(data []byte) (*Schema, error) {
+	s := &Schema{}
+	if err := s.Add(data); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Add merges the JSON values in data into s. If data is not valid JSON, s
+// keeps the values before the error.
+func (s *Schema) Add(data []byte) error {
+	d := jsontext.NewDecoderBytes(data)
+	for d.PeekKind() != 0 {
+		if err := s.add(d); err != nil {
+			return err
+		}
+	}
+	if _, err := d.ReadToken(); err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// Required reports whether f was in every object s has seen.
+func (s *Schema) Required(f *Field) bool {
+	return f.Present == s.Objects
+}
+
+// add merges the next value read from d.
+func (s *Schema) add(d *jsontext.Decoder) error {
+	tok, err := d.ReadToken()
+	if err != nil {
+		return err
+	}
+	s.Count++
+
+	switch tok.Kind() {
+	case 'n':
+		s.Types |= Null
+	case 't', 'f':
+		s.Types |= Bool
+	case '"':
+		s.Types |= String
+	case '0':
+		f, err := tok.Float()
+		if err != nil && !isRangeError(err) {
+			return err
+		}
+		if s.Types&(Integer|Number) == 0 || f < s.Min {
+			s.Min = f
+		}
+		if s.Types&(Integer|Number) == 0 || f > s.Max {
+			s.Max = f
+		}
+		if strings.ContainsAny(string(tok.Raw()), ".eE") {
+			s.Types |= Number
+		} else {
+			s.Types |= Integer
+		}
+	case '{':
+		s.Types |= Object
+		s.Objects++
+		for {
+			if k := d.PeekKind(); k == '}' || k == 0 {
+				return end(d)
+			}
+			name, err := d.ReadToken()
+			if err != nil {
+				return err
+			}
+			f := s.field(name.String())
+			// A duplicate key counts once
+			if f.lastObject != s.Objects {
+				f.lastObject = s.Objects
+				f.Present++
+			}
+			if err := f.Schema.add(d); err != nil {
+				return err
+			}
+		}
+	case '[':
+		s.Types |= Array
+		if s.Items == nil {
+			s.Items = &Schema{}
+		}
+		for {
+			if k := d.PeekKind(); k == ']' || k == 0 {
+				return end(d)
+			}
+			if err := s.Items.add(d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// end reads the token closing an object or array.
+func end(d *jsontext.Decoder) error {
+	_, err := d.ReadToken()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func isRangeError(err error) bool {
+	ne, ok := err.(*strconv.NumError)
+	return ok && ne.Err == strconv.ErrRange
+}
+
+// field returns the field named name, adding it if it is new.
+func (s *Schema) field(name string) *Field {
+	if f := s.fields[name]; f != nil {
+		return f
+	}
+	if s.fields == nil {
+		s.fields = make(map[string]*Field)
+	}
+	f := &Field{Name: name, Schema: &Schema{}}
+	s.fields[name] = f
+	s.Fields = append(s.Fields, f)
+	return f
+}
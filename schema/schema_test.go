@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"testing"
+)
+
+func TestInfer(t *testing.T) {
+	s, err := Infer([]byte(`{"id": 1, "name": "a", "score": 2.5, "tags": ["x"], "a": 1, "a": 2}
+{"id": -3, "name": null, "score": 10, "tags": [], "extra": {"ok": true}}
+{"id": 7}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Types != Object || s.Count != 3 || s.Objects != 3 {
+		t.Fatalf("Expected 3 objects, got %v x%d", s.Types, s.Count)
+	}
+
+	expected := []struct {
+		name     string
+		types    Type
+		present  int
+		min, max float64
+	}{
+		{"id", Integer, 3, -3, 7},
+		{"name", String | Null, 2, 0, 0},
+		{"score", Integer | Number, 2, 2.5, 10},
+		{"tags", Array, 2, 0, 0},
+		{"a", Integer, 1, 1, 2},
+		{"extra", Object, 1, 0, 0},
+	}
+	if len(s.Fields) != len(expected) {
+		t.Fatalf("Expected %d fields, got %d", len(expected), len(s.Fields))
+	}
+	for i, e := range expected {
+		f := s.Fields[i]
+		if f.Name != e.name || f.Schema.Types != e.types || f.Present != e.present {
+			t.Errorf("Expected field %s %v in %d objects, got %s %v in %d", e.name, e.types, e.present, f.Name, f.Schema.Types, f.Present)
+		}
+		if f.Schema.Min != e.min || f.Schema.Max != e.max {
+			t.Errorf("Expected %s in [%g, %g], got [%g, %g]", e.name, e.min, e.max, f.Schema.Min, f.Schema.Max)
+		}
+		if s.Required(f) != (e.present == 3) {
+			t.Errorf("Expected %s required to be %v", e.name, e.present == 3)
+		}
+	}
+
+	tags := s.Fields[3].Schema
+	if tags.Items == nil || tags.Items.Types != String || tags.Items.Count != 1 {
+		t.Errorf("Expected tags of one string, got %+v", tags.Items)
+	}
+	if extra := s.Fields[5].Schema; len(extra.Fields) != 1 || extra.Fields[0].Schema.Types != Bool {
+		t.Errorf("Expected extra with a bool member, got %+v", extra)
+	}
+}
+
+func TestSchemaAdd(t *testing.T) {
+	var s Schema
+	if err := s.Add([]byte(`1`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add([]byte(`"a" [true]`)); err != nil {
+		t.Fatal(err)
+	}
+	if s.Types != Integer|String|Array || s.Count != 3 {
+		t.Errorf("Expected 3 values of integer|string|array, got %d of %v", s.Count, s.Types)
+	}
+
+	for _, input := range []string{`{"a": 1`, `[1, 2`, `{"a" 1}`, `[1] }`} {
+		if err := s.Add([]byte(input)); err == nil {
+			t.Errorf("Expected an error for %s", input)
+		}
+	}
+	if _, err := Infer([]byte(`{`)); err == nil {
+		t.Error("Expected Infer to fail on invalid JSON")
+	}
+}
+
+func TestTypeString(t *testing.T) {
+	tests := []struct {
+		t        Type
+		expected string
+	}{
+		{0, "none"},
+		{Null, "null"},
+		{Integer | Number, "integer|number"},
+		{Null | Bool | String | Object | Array, "null|boolean|string|object|array"},
+	}
+	for _, tt := range tests {
+		if got := tt.t.String(); got != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package simdjson
+
+import (
+	"errors"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// SIMDLevel selects the family of scanning kernels used by the parser.
+type SIMDLevel int
+
+const (
+	SIMDAuto   SIMDLevel = iota // best level supported by the CPU
+	SIMDScalar                  // portable scalar code, no SIMD
+	SIMDSWAR                    // 64-bit SWAR kernels (wasm, ppc64le, s390x)
+	SIMDSSE42                   // x86_64 SSE4.2; no kernels, SetSIMDLevel rejects it
+	SIMDAVX2                    // x86_64 AVX2
+	SIMDNEON                    // ARM64 NEON
+)
+
+// ErrSIMDLevelUnsupported is returned by SetSIMDLevel when the requested
+// kernels cannot run on this CPU.
+var ErrSIMDLevelUnsupported = errors.New("SIMD level not supported on this CPU")
+
+// SetSIMDLevel forces the kernels used by every subsequent parse, for
+// debugging, benchmarking or working around CPU errata. SIMDAuto restores
+// automatic selection. The scalar path can also be forced at startup by
+// setting the SIMDJSON_DISABLE_SIMD environment variable to 1.
+func SetSIMDLevel(level SIMDLevel) error {
+	if !scanner.SetLevel(scanner.Level(level)) {
+		return ErrSIMDLevelUnsupported
+	}
+	return nil
+}
+
+// ActiveKernel returns the name of the kernels currently in use: "avx2",
+// "neon", "swar" or "scalar".
+func ActiveKernel() string {
+	return scanner.ActiveLevel().String()
+}
@@ -0,0 +1,55 @@
+package simdjson
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestSetSIMDLevel(t *testing.T) {
+	defer SetSIMDLevel(SIMDAuto)
+
+	auto := ActiveKernel()
+	if err := SetSIMDLevel(SIMDScalar); err != nil {
+		t.Fatalf("Forcing scalar failed: %v", err)
+	}
+	if got := ActiveKernel(); got != "scalar" {
+		t.Errorf("Expected scalar, got %s", got)
+	}
+
+	var v map[string]interface{}
+	if err := Unmarshal([]byte(`{"a":[1,2,{"b":"c"}]}`), &v); err != nil {
+		t.Errorf("Unmarshal with scalar kernels failed: %v", err)
+	}
+
+	if err := SetSIMDLevel(SIMDLevel(99)); err != ErrSIMDLevelUnsupported {
+		t.Errorf("Expected ErrSIMDLevelUnsupported, got %v", err)
+	}
+	if got := ActiveKernel(); got != "scalar" {
+		t.Errorf("Rejected level changed the kernel to %s", got)
+	}
+
+	if err := SetSIMDLevel(SIMDAuto); err != nil {
+		t.Fatalf("Restoring auto failed: %v", err)
+	}
+	if got := ActiveKernel(); got != auto {
+		t.Errorf("Expected %s after restoring auto, got %s", auto, got)
+	}
+}
+
+func TestDisableSIMDEnv(t *testing.T) {
+	if os.Getenv("SIMDJSON_TEST_KERNEL") == "1" {
+		os.Stdout.WriteString(ActiveKernel())
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestDisableSIMDEnv$")
+	cmd.Env = append(os.Environ(), "SIMDJSON_TEST_KERNEL=1", "SIMDJSON_DISABLE_SIMD=1")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Subprocess failed: %v", err)
+	}
+	if len(out) < len("scalar") || string(out[:len("scalar")]) != "scalar" {
+		t.Errorf("Expected scalar kernel with SIMDJSON_DISABLE_SIMD=1, got %q", out)
+	}
+}
@@ -0,0 +1,67 @@
+// Package sqljson stores Go values in JSON and JSONB database columns
+// through database/sql, encoding and decoding them with simdjson.
+package sqljson
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	simdjson "github.com/biggeezerdevelopment/simdjson-go"
+)
+
+// JSONValue holds a value of type T stored as JSON. It implements
+// driver.Valuer and sql.Scanner, so it can be passed to Exec and Scan
+// directly:
+//
+//	var prefs sqljson.JSONValue[Preferences]
+//	err := db.QueryRow("SELECT prefs FROM users WHERE id = $1", id).Scan(&prefs)
+//
+// Valid is false for an SQL NULL, the same way as for sql.Null. A JSON
+// null stored in the column is a valid value and decodes like
+// simdjson.Unmarshal decodes null into T.
+type JSONValue[T any] struct {
+	V     T
+	Valid bool
+}
+
+// NewJSONValue returns a valid JSONValue holding v.
+func NewJSONValue[T any](v T) JSONValue[T] {
+	return JSONValue[T]{V: v, Valid: true}
+}
+
+// Value implements driver.Valuer. It returns the JSON encoding of V, or
+// nil for SQL NULL if Valid is false.
+func (j JSONValue[T]) Value() (driver.Value, error) {
+	if !j.Valid {
+		return nil, nil
+	}
+	return simdjson.Marshal(j.V)
+}
+
+// Scan implements sql.Scanner. It accepts the []byte and string values
+// drivers return for JSON columns, and nil for SQL NULL. Strings in V are
+// copied, so drivers may reuse src after Scan returns.
+func (j *JSONValue[T]) Scan(src interface{}) error {
+	var zero T
+	j.V = zero
+	switch src := src.(type) {
+	case nil:
+		j.Valid = false
+		return nil
+	case []byte:
+		if err := simdjson.Unmarshal(src, &j.V); err != nil {
+			j.Valid = false
+			return err
+		}
+	case string:
+		if err := simdjson.Unmarshal([]byte(src), &j.V); err != nil {
+			j.Valid = false
+			return err
+		}
+	default:
+		j.Valid = false
+		return fmt.Errorf("sqljson: cannot scan %T into JSONValue", src)
+	}
+	j.Valid = true
+	return nil
+}
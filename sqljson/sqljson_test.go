@@ -0,0 +1,114 @@
+package sqljson
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+type prefs struct {
+	Theme string   `json:"theme"`
+	Tags  []string `json:"tags"`
+}
+
+var (
+	_ driver.Valuer = JSONValue[prefs]{}
+	_ sql.Scanner   = (*JSONValue[prefs])(nil)
+)
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        driver.Valuer
+		expected driver.Value
+	}{
+		{"struct", NewJSONValue(prefs{Theme: "dark", Tags: []string{"a"}}), []byte(`{"theme":"dark","tags":["a"]}`)},
+		{"map", NewJSONValue(map[string]int{"a": 1}), []byte(`{"a":1}`)},
+		{"slice", NewJSONValue([]int{1, 2}), []byte(`[1,2]`)},
+		{"invalid", JSONValue[prefs]{V: prefs{Theme: "dark"}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.v.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := NewJSONValue(make(chan int)).Value(); err == nil {
+		t.Error("Expected error for unsupported type")
+	}
+}
+
+func TestScan(t *testing.T) {
+	expected := prefs{Theme: "dark", Tags: []string{"a", "b"}}
+	tests := []struct {
+		name string
+		src  interface{}
+	}{
+		{"bytes", []byte(`{"theme":"dark","tags":["a","b"]}`)},
+		{"string", `{"theme":"dark","tags":["a","b"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var j JSONValue[prefs]
+			if err := j.Scan(tt.src); err != nil {
+				t.Fatal(err)
+			}
+			if !j.Valid || !reflect.DeepEqual(j.V, expected) {
+				t.Errorf("Expected %+v, got %+v (valid %v)", expected, j.V, j.Valid)
+			}
+		})
+	}
+}
+
+func TestScanNull(t *testing.T) {
+	j := NewJSONValue(prefs{Theme: "dark"})
+	if err := j.Scan(nil); err != nil {
+		t.Fatal(err)
+	}
+	if j.Valid || j.V.Theme != "" {
+		t.Errorf("Expected invalid zero value, got %+v (valid %v)", j.V, j.Valid)
+	}
+
+	// A JSON null is a value, unlike SQL NULL
+	var m JSONValue[map[string]int]
+	if err := m.Scan([]byte("null")); err != nil {
+		t.Fatal(err)
+	}
+	if !m.Valid || m.V != nil {
+		t.Errorf("Expected valid nil map, got %v (valid %v)", m.V, m.Valid)
+	}
+}
+
+func TestScanErrors(t *testing.T) {
+	for _, src := range []interface{}{[]byte(`{"theme":`), `[1]`, 42} {
+		j := NewJSONValue(prefs{Theme: "dark"})
+		if err := j.Scan(src); err == nil {
+			t.Errorf("Expected error for %v", src)
+		}
+		if j.Valid {
+			t.Errorf("Expected invalid value after failed scan of %v", src)
+		}
+	}
+}
+
+func TestScanCopiesStrings(t *testing.T) {
+	// Drivers reuse the buffer passed to Scan for the next row
+	src := []byte(`{"theme":"dark"}`)
+	var j JSONValue[prefs]
+	if err := j.Scan(src); err != nil {
+		t.Fatal(err)
+	}
+	copy(src, `{"theme":"lite"}`)
+	if j.V.Theme != "dark" {
+		t.Errorf("Expected %q, got %q", "dark", j.V.Theme)
+	}
+}
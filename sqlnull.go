@@ -0,0 +1,40 @@
+package simdjson
+
+import (
+	"reflect"
+	"strings"
+)
+
+// isSQLNull reports whether t is one of the Null types of database/sql:
+// NullString, NullInt64, NullTime and the rest, or the generic Null[T].
+// They are all structs of a value followed by Valid, and are written as
+// the value, or null if not Valid, and null decodes into them as not
+// Valid, so rows scanned from a database serialize as their columns read.
+// Codecs registered for them take precedence.
+func isSQLNull(t reflect.Type) bool {
+	return t.PkgPath() == "database/sql" && strings.HasPrefix(t.Name(), "Null") &&
+		t.NumField() == 2 && t.Field(1).Name == "Valid" && t.Field(1).Type.Kind() == reflect.Bool
+}
+
+func (e *encoder) encodeSQLNull(v reflect.Value) error {
+	if !v.Field(1).Bool() {
+		e.buf = append(e.buf, "null"...)
+		return nil
+	}
+	return e.encode(v.Field(0))
+}
+
+func newSQLNullPlan(t reflect.Type) decodeFunc {
+	plan := planFor(t.Field(0).Type)
+	return func(r *Reader, v reflect.Value) error {
+		v.SetZero()
+		if r.Null() {
+			return nil
+		}
+		if err := plan(r, v.Field(0)); err != nil {
+			return err
+		}
+		v.Field(1).SetBool(true)
+		return nil
+	}
+}
@@ -0,0 +1,67 @@
+package simdjson
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+type sqlRow struct {
+	Name    sql.NullString  `json:"name"`
+	Age     sql.NullInt64   `json:"age"`
+	Score   sql.NullFloat64 `json:"score"`
+	Active  sql.NullBool    `json:"active"`
+	Created sql.NullTime    `json:"created"`
+	Rank    sql.Null[int16] `json:"rank"`
+}
+
+func TestSQLNull(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		row      sqlRow
+		expected string
+	}{
+		{"null", sqlRow{}, `{"name":null,"age":null,"score":null,"active":null,"created":null,"rank":null}`},
+		{
+			"valid",
+			sqlRow{
+				Name:    sql.NullString{String: "", Valid: true},
+				Age:     sql.NullInt64{Int64: 42, Valid: true},
+				Score:   sql.NullFloat64{Float64: 1.5, Valid: true},
+				Active:  sql.NullBool{Bool: false, Valid: true},
+				Created: sql.NullTime{Time: created, Valid: true},
+				Rank:    sql.Null[int16]{V: 3, Valid: true},
+			},
+			`{"name":"","age":42,"score":1.5,"active":false,"created":"2024-05-01T12:00:00Z","rank":3}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+
+			// Decoding over a valid row shows null clears it
+			back := tests[1].row
+			if err := Unmarshal(data, &back); err != nil {
+				t.Fatal(err)
+			}
+			if back != tt.row {
+				t.Errorf("Expected %+v, got %+v", tt.row, back)
+			}
+		})
+	}
+
+	var n sql.NullInt32
+	if err := Unmarshal([]byte(`"7"`), &n); err == nil || n.Valid {
+		t.Errorf("Expected an error and no value for a string, got %+v, %v", n, err)
+	}
+	if data, err := Marshal(&sql.NullByte{Byte: 9, Valid: true}); err != nil || string(data) != "9" {
+		t.Errorf("Expected 9, got %s, %v", data, err)
+	}
+}
@@ -0,0 +1,67 @@
+package simdjson
+
+import (
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// DocStats describes the shape of a JSON document.
+type DocStats struct {
+	Objects  int
+	Arrays   int
+	Keys     int // object members, counting duplicates
+	Strings  int // string values, not counting keys
+	Numbers  int
+	Bools    int
+	Nulls    int
+	MaxDepth int // of nested objects and arrays; 0 for a scalar document
+
+	// StringBytes is the length of all strings and keys as written,
+	// escapes included and quotes not
+	StringBytes int
+}
+
+// Stats validates data and counts its values from its tokens, without
+// decoding it. It serves capacity planning and schema inference, and
+// limits on the shape of untrusted input: see also Options.Limits, which
+// enforces such limits while decoding.
+func Stats(data []byte) (DocStats, error) {
+	var st DocStats
+	s := scanner.New()
+	defer s.Release()
+	d, err := parseDocument(s, data)
+	if err != nil {
+		return st, err
+	}
+	defer d.release()
+
+	depth := 0
+	for i, tok := range d.tokens {
+		switch tok.Type {
+		case scanner.TokenObjectBegin, scanner.TokenArrayBegin:
+			if tok.Type == scanner.TokenObjectBegin {
+				st.Objects++
+			} else {
+				st.Arrays++
+			}
+			if depth++; depth > st.MaxDepth {
+				st.MaxDepth = depth
+			}
+		case scanner.TokenObjectEnd, scanner.TokenArrayEnd:
+			depth--
+		case scanner.TokenString:
+			if i+1 < len(d.tokens) && d.tokens[i+1].Type == scanner.TokenColon {
+				st.Keys++
+			} else {
+				st.Strings++
+			}
+			st.StringBytes += int(tok.End-tok.Start) - 2
+		case scanner.TokenNumber:
+			st.Numbers++
+		case scanner.TokenTrue, scanner.TokenFalse:
+			st.Bools++
+		case scanner.TokenNull:
+			st.Nulls++
+		}
+	}
+	return st, nil
+}
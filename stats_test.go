@@ -0,0 +1,39 @@
+package simdjson
+
+import (
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected DocStats
+	}{
+		{"scalar", `42`, DocStats{Numbers: 1}},
+		{"string", `"a\nb"`, DocStats{Strings: 1, StringBytes: 4}},
+		{"empty containers", `[{}, []]`, DocStats{Objects: 1, Arrays: 2, MaxDepth: 2}},
+		{
+			"document",
+			`{"id": 1, "name": "ab", "tags": ["x", "yz"], "meta": {"ok": true, "gone": null, "n": [1.5, [false]]}}`,
+			DocStats{Objects: 2, Arrays: 3, Keys: 7, Strings: 3, Numbers: 2, Bools: 2, Nulls: 1, MaxDepth: 4, StringBytes: 26},
+		},
+		{"duplicate keys", `{"a": 1, "a": 2}`, DocStats{Objects: 1, Keys: 2, Numbers: 2, MaxDepth: 1, StringBytes: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := Stats([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if st != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, st)
+			}
+		})
+	}
+
+	if _, err := Stats([]byte(`{"a": [1, 2}`)); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
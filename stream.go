@@ -0,0 +1,120 @@
+package simdjson
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"iter"
+	"reflect"
+	"strconv"
+)
+
+// streamBufferSize is how much Stream reads from its reader at a time.
+// Longer records are gathered across reads.
+const streamBufferSize = 64 << 10
+
+// A RecordError is the error of the NDJSON record on Line, counting from 1.
+type RecordError struct {
+	Line int
+	Err  error
+}
+
+func (e *RecordError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Stream returns an iterator over the NDJSON records read from r, each
+// decoded into a T. Records are framed as by scanner.NextRecord: one per
+// line, with blank lines skipped. They are read and decoded one at a time
+// as the loop asks for them, by one decoder taken from the pool for the
+// whole iteration, so memory stays at about the longest record however
+// long the stream, and breaking out of the loop stops reading.
+//
+// A record that fails to decode yields what was decoded of it and a
+// *RecordError, and iteration goes on with the next record unless the loop
+// breaks. An error reading r is yielded last.
+//
+//	for event, err := range simdjson.Stream[Event](r) {
+//		if err != nil {
+//			return err
+//		}
+//		handle(event)
+//	}
+func Stream[T any](r io.Reader) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		plan := planFor(reflect.TypeFor[T]())
+		d := newDecoder(nil)
+		defer d.release()
+
+		br := bufio.NewReaderSize(r, streamBufferSize)
+		var line []byte
+		for n := 1; ; n++ {
+			var err error
+			line, err = readLine(br, line[:0])
+			record := bytes.TrimRight(line, "\r\n")
+			if len(bytes.TrimSpace(record)) > 0 {
+				var v T
+				d.data = record
+				if derr := d.run(plan, reflect.ValueOf(&v).Elem()); derr != nil {
+					if !yield(v, &RecordError{Line: n, Err: derr}) {
+						return
+					}
+				} else if !yield(v, nil) {
+					return
+				}
+			}
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+		}
+	}
+}
+
+// readLine appends the next line read from br to dst, its newline
+// included. The error is io.EOF after the last line.
+func readLine(br *bufio.Reader, dst []byte) ([]byte, error) {
+	for {
+		b, err := br.ReadSlice('\n')
+		dst = append(dst, b...)
+		if err != bufio.ErrBufferFull {
+			return dst, err
+		}
+	}
+}
+
+// A StreamItem is a record decoded by StreamChan, or the error instead.
+type StreamItem[T any] struct {
+	Value T
+	Err   error
+}
+
+// StreamChan is Stream for consumers that prefer a channel: it decodes the
+// records read from r in a goroutine of its own and sends them on the
+// returned channel, which has room for buffer items and is closed after
+// the last. The goroutine waits while the channel is full, so a slow
+// consumer holds back reading, and stops once ctx is canceled, although a
+// Read in progress is not interrupted.
+func StreamChan[T any](ctx context.Context, r io.Reader, buffer int) <-chan StreamItem[T] {
+	ch := make(chan StreamItem[T], buffer)
+	go func() {
+		defer close(ch)
+		for v, err := range Stream[T](r) {
+			select {
+			case ch <- StreamItem[T]{Value: v, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,99 @@
+package simdjson
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStream(t *testing.T) {
+	long := strings.Repeat("x", 3*streamBufferSize)
+	input := `{"name":"a"}` + "\n\n" + `{"name":"b","children":[{"name":"c"}]}` + "\r\n" +
+		`{"name":` + "\n" + `  ` + "\n" + `{"name":"` + long + `"}` + "\n" + `{"name":"last"}`
+
+	var names []string
+	var errs []error
+	for n, err := range Stream[decodeNode](strings.NewReader(input)) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		names = append(names, n.Name)
+	}
+	expected := []string{"a", "b", long, "last"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %d records, got %d", len(expected), len(names))
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected record %d to be %.20q, got %.20q", i, expected[i], names[i])
+		}
+	}
+	var re *RecordError
+	if len(errs) != 1 || !errors.As(errs[0], &re) || re.Line != 4 {
+		t.Errorf("Expected an error on line 4, got %v", errs)
+	}
+}
+
+func TestStreamStops(t *testing.T) {
+	// Breaking out stops reading
+	r := &countingReader{r: strings.NewReader(strings.Repeat(`{"name":"x"}`+"\n", 100000))}
+	count := 0
+	for range Stream[decodeNode](r) {
+		if count++; count == 3 {
+			break
+		}
+	}
+	if r.reads > 1 {
+		t.Errorf("Expected one read before the break, got %d", r.reads)
+	}
+
+	// Read errors end the stream
+	reader := io.MultiReader(strings.NewReader(`{"name":"a"}`+"\n"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	var last error
+	count = 0
+	for _, err := range Stream[decodeNode](reader) {
+		count++
+		last = err
+	}
+	if count != 2 || last != io.ErrUnexpectedEOF {
+		t.Errorf("Expected a record then the read error, got %d items ending with %v", count, last)
+	}
+}
+
+type countingReader struct {
+	r     io.Reader
+	reads int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	c.reads++
+	return c.r.Read(p)
+}
+
+func TestStreamChan(t *testing.T) {
+	input := `{"name":"a"}` + "\n" + `{"name":1}` + "\n" + `{"name":"c"}`
+	var items []StreamItem[decodeNode]
+	for item := range StreamChan[decodeNode](context.Background(), strings.NewReader(input), 1) {
+		items = append(items, item)
+	}
+	if len(items) != 3 || items[0].Value.Name != "a" || items[1].Err == nil || items[2].Value.Name != "c" {
+		t.Errorf("Expected a, an error and c, got %+v", items)
+	}
+
+	// Canceling closes the channel without draining the stream
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := StreamChan[decodeNode](ctx, strings.NewReader(strings.Repeat(`{"name":"x"}`+"\n", 100000)), 0)
+	<-ch
+	cancel()
+	count := 0
+	for range ch {
+		count++
+	}
+	if count > 1 {
+		t.Errorf("Expected at most one more item after cancel, got %d", count)
+	}
+}
@@ -0,0 +1,98 @@
+package simdjson
+
+import (
+	"bytes"
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	// ErrBOM is returned for input that starts with a UTF-8 byte order
+	// mark, unless Options.SkipBOM is set.
+	ErrBOM = errors.New("JSON input starts with a UTF-8 byte order mark")
+	// ErrUTF16 is returned for UTF-16 input, unless Options.UTF16 is set.
+	ErrUTF16 = errors.New("JSON input is UTF-16, not UTF-8")
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// textEncoding is the encoding of a document's text.
+type textEncoding int
+
+const (
+	encodingUTF8 textEncoding = iota
+	encodingUTF8BOM
+	encodingUTF16LE
+	encodingUTF16BE
+)
+
+// detectEncoding recognizes UTF-16 by its byte order mark or, without
+// one, by the zero bytes the first character has in UTF-16, as RFC 4627
+// section 3 describes: a JSON text starts with an ASCII character.
+// UTF-32, whose first character has three zero bytes, is not recognized.
+func detectEncoding(data []byte) textEncoding {
+	switch {
+	case bytes.HasPrefix(data, utf8BOM):
+		return encodingUTF8BOM
+	case len(data) < 2:
+		return encodingUTF8
+	case data[0] == 0xFE && data[1] == 0xFF:
+		return encodingUTF16BE
+	case data[0] == 0xFF && data[1] == 0xFE:
+		return encodingUTF16LE
+	case data[0] == 0 && data[1] != 0:
+		return encodingUTF16BE
+	case data[0] != 0 && data[1] == 0 && (len(data) < 4 || data[2] != 0 || data[3] != 0):
+		return encodingUTF16LE
+	}
+	return encodingUTF8
+}
+
+// decodeText returns data as UTF-8 without a byte order mark, as allowed
+// by skipBOM and allowUTF16. UTF-16 is transcoded into a new slice.
+func decodeText(data []byte, skipBOM, allowUTF16 bool) ([]byte, error) {
+	enc := detectEncoding(data)
+	switch {
+	case enc == encodingUTF8:
+		return data, nil
+	case enc == encodingUTF8BOM && skipBOM:
+		return data[len(utf8BOM):], nil
+	case enc == encodingUTF8BOM:
+		return nil, ErrBOM
+	case !allowUTF16:
+		return nil, ErrUTF16
+	}
+
+	if len(data)%2 != 0 {
+		return nil, errors.New("UTF-16 input has an odd number of bytes")
+	}
+	out := make([]byte, 0, len(data)/2+len(data)/4)
+	unit := func(i int) rune {
+		if enc == encodingUTF16BE {
+			return rune(data[i])<<8 | rune(data[i+1])
+		}
+		return rune(data[i+1])<<8 | rune(data[i])
+	}
+	i := 0
+	if r := unit(0); r == 0xFEFF {
+		i = 2
+	}
+	for ; i < len(data); i += 2 {
+		r := unit(i)
+		if utf16.IsSurrogate(r) {
+			if i+3 < len(data) {
+				if dec := utf16.DecodeRune(r, unit(i+2)); dec != utf8.RuneError {
+					r = dec
+					i += 2
+				}
+			}
+			if utf16.IsSurrogate(r) {
+				// An unpaired surrogate
+				r = utf8.RuneError
+			}
+		}
+		out = utf8.AppendRune(out, r)
+	}
+	return out, nil
+}
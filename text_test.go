@@ -0,0 +1,96 @@
+package simdjson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"utf8", `{"a":1}`, `{"a":1}`},
+		{"utf8_bom", "\xef\xbb\xbf{}", `{}`},
+		{"utf16le_bom", "\xff\xfe[\x001\x00]\x00", `[1]`},
+		{"utf16be_bom", "\xfe\xff\x00[\x001\x00]", `[1]`},
+		{"utf16le", "1\x00", `1`},
+		{"utf16be", "\x00\"\x00\xe9\x00\"", `"é"`},
+		{"surrogate_pair", "\"\x00\x3d\xd8\x00\xde\"\x00", `"😀"`},
+		{"lone_surrogate", "\"\x00\x3d\xd8\"\x00", "\"\xef\xbf\xbd\""},
+		{"reversed_surrogates", "\"\x00\x00\xde\x3d\xd8\"\x00", "\"\xef\xbf\xbd\xef\xbf\xbd\""},
+		{"utf32le_untouched", "1\x00\x00\x00", "1\x00\x00\x00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := decodeText([]byte(tt.input), true, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := decodeText([]byte("[\x001\x00]"), true, true); err == nil {
+		t.Error("Expected error for odd length")
+	}
+}
+
+func TestEncodingOptions(t *testing.T) {
+	var v interface{}
+	if err := Unmarshal([]byte("\xef\xbb\xbf{}"), &v); !errors.Is(err, ErrBOM) {
+		t.Errorf("Expected ErrBOM, got %v", err)
+	}
+	if err := Unmarshal([]byte("[\x001\x00]\x00"), &v); !errors.Is(err, ErrUTF16) {
+		t.Errorf("Expected ErrUTF16, got %v", err)
+	}
+	if err := Unmarshal([]byte("\xef\xbb{}"), &v); errors.Is(err, ErrBOM) || err == nil {
+		t.Errorf("Expected syntax error for incomplete BOM, got %v", err)
+	}
+
+	p := NewParser(&Options{CopyStrings: true, SkipBOM: true})
+	if err := p.Unmarshal([]byte("\xef\xbb\xbf{\"a\":1}"), &v); err != nil {
+		t.Errorf("Expected BOM to be skipped, got %v", err)
+	}
+	if err := p.Unmarshal([]byte("\xff\xfe[\x00]\x00"), &v); !errors.Is(err, ErrUTF16) {
+		t.Errorf("Expected ErrUTF16 without UTF16, got %v", err)
+	}
+
+	p = NewParser(&Options{UTF16: true})
+	for _, name := range []string{
+		"i_string_UTF-16LE_with_BOM.json",
+		"i_string_utf16BE_no_BOM.json",
+		"i_string_utf16LE_no_BOM.json",
+	} {
+		data, err := jsonTestSuite.ReadFile("testdata/JSONTestSuite/test_parsing/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		if err := p.Unmarshal(data, &got); err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if len(got) != 1 || got[0] != "é" {
+			t.Errorf("%s: expected [é], got %q", name, got)
+		}
+
+		res, err := p.Parse(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !deepEqual(res.Root().Detach(), []interface{}{"é"}) {
+			t.Errorf("%s: expected [é], got %v", name, res.Root().Interface())
+		}
+		res.Release()
+	}
+
+	p = NewParser(&Options{SkipBOM: true, UTF16: true})
+	for _, input := range []string{"\xef\xbb\xbf", "\xef\xbb{}", "\xff\xfe"} {
+		if err := p.Unmarshal([]byte(input), &v); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
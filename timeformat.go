@@ -0,0 +1,133 @@
+package simdjson
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+	"unsafe"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/jsonenc"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// Time formats that are numbers rather than layouts, for
+// MarshalOptions.TimeFormat and Options.TimeFormat. Any other non-empty
+// format is a layout as time.Format takes, written as a string.
+const (
+	// TimeUnix is whole seconds since the Unix epoch, with any fraction of
+	// a second dropped when writing.
+	TimeUnix = "unix"
+	// TimeUnixMilli is whole milliseconds since the Unix epoch.
+	TimeUnixMilli = "unixmilli"
+)
+
+// A codec for time.Time, so times are written in MarshalOptions.TimeFormat
+// and read in Options.TimeFormat, RFC 3339 strings by default as in
+// encoding/json.
+func init() {
+	RegisterCodec(marshalTime, unmarshalTime)
+}
+
+var (
+	timeType = reflect.TypeFor[time.Time]()
+
+	errTimeYear = errors.New("time year outside of range [0,9999]")
+)
+
+func marshalTime(w *Writer, t time.Time) error {
+	return w.Time(t)
+}
+
+// unmarshalTime decodes a time, leaving t unchanged for null as
+// encoding/json does.
+func unmarshalTime(r *Reader, t *time.Time) error {
+	if r.Null() {
+		return nil
+	}
+	v, err := r.Time()
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
+
+// Time appends t in MarshalOptions.TimeFormat, after converting it to
+// MarshalOptions.TimeLocation if set. In the default RFC 3339 format, a
+// year outside [0,9999] is an error, as in encoding/json.
+func (w *Writer) Time(t time.Time) error {
+	e := w.e
+	if e.timeLoc != nil {
+		t = t.In(e.timeLoc)
+	}
+	switch e.timeFormat {
+	case TimeUnix:
+		e.buf = strconv.AppendInt(e.buf, t.Unix(), 10)
+	case TimeUnixMilli:
+		e.buf = strconv.AppendInt(e.buf, t.UnixMilli(), 10)
+	case "":
+		if y := t.Year(); y < 0 || y > 9999 {
+			return errTimeYear
+		}
+		e.buf = append(e.buf, '"')
+		e.buf = t.AppendFormat(e.buf, time.RFC3339Nano)
+		e.buf = append(e.buf, '"')
+	default:
+		// A layout may hold characters that need escaping
+		b := t.AppendFormat(e.scratch[:0], e.timeFormat)
+		e.buf = append(e.buf, '"')
+		e.buf = jsonenc.AppendString(e.buf, unsafe.String(unsafe.SliceData(b), len(b)), e.escapeHTML)
+		e.buf = append(e.buf, '"')
+	}
+	return nil
+}
+
+// Time consumes a time in Options.TimeFormat: a number for TimeUnix and
+// TimeUnixMilli, otherwise a string in the layout, RFC 3339 by default.
+// With Options.TimeLocation set, times are returned in that location, and
+// layouts without a zone are read as times there; otherwise they are read
+// as UTC, and Unix times are returned in UTC.
+func (r *Reader) Time() (time.Time, error) {
+	loc := r.timeLoc
+	if loc == nil {
+		loc = time.UTC
+	}
+	if r.timeFmt == TimeUnix || r.timeFmt == TimeUnixMilli {
+		if r.err == nil && r.peek() != scanner.TokenNumber {
+			return time.Time{}, r.typeError(timeType)
+		}
+		s, err := r.number()
+		if err != nil {
+			return time.Time{}, err
+		}
+		n, err := r.parseInt(s, 64, timeType)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if r.timeFmt == TimeUnix {
+			return time.Unix(n, 0).In(loc), nil
+		}
+		return time.UnixMilli(n).In(loc), nil
+	}
+
+	if r.err == nil && r.peek() != scanner.TokenString {
+		return time.Time{}, r.typeError(timeType)
+	}
+	s, err := r.String()
+	if err != nil {
+		return time.Time{}, err
+	}
+	layout := r.timeFmt
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	t, err := time.ParseInLocation(layout, s, loc)
+	if err != nil {
+		return time.Time{}, r.fail(err)
+	}
+	if r.timeLoc != nil {
+		t = t.In(r.timeLoc)
+	}
+	return t, nil
+}
@@ -0,0 +1,128 @@
+package simdjson
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type timeEvent struct {
+	At   time.Time  `json:"at"`
+	Seen *time.Time `json:"seen"`
+}
+
+func TestTimeDefault(t *testing.T) {
+	at := time.Date(2024, 3, 9, 14, 30, 5, 123456789, time.FixedZone("", 2*60*60))
+	v := timeEvent{At: at}
+
+	expected, _ := json.Marshal(v)
+	data, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(expected) {
+		t.Errorf("Expected %s as encoding/json writes it, got %s", expected, data)
+	}
+
+	var got timeEvent
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.At.Equal(at) || got.Seen != nil {
+		t.Errorf("Expected %v, got %+v", at, got)
+	}
+	if _, offset := got.At.Zone(); offset != 2*60*60 {
+		t.Errorf("Expected the offset written to be kept, got %d", offset)
+	}
+
+	// null leaves the time unchanged
+	if err := Unmarshal([]byte(`{"at":null}`), &got); err != nil || !got.At.Equal(at) {
+		t.Errorf("Expected null to leave %v, got %v, %v", at, got.At, err)
+	}
+
+	if _, err := Marshal(time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("Expected an error for a year beyond 9999")
+	}
+}
+
+func TestTimeFormat(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	at := time.Date(2024, 3, 9, 14, 30, 5, 250000000, time.UTC)
+	seen := at.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		format   string
+		loc      *time.Location
+		expected string
+		// The time read back, if not at with its fractions dropped
+		back time.Time
+	}{
+		{"unix", TimeUnix, nil, `{"at":1709994605,"seen":1709998205}`, at.Truncate(time.Second)},
+		{"unix millis", TimeUnixMilli, nil, `{"at":1709994605250,"seen":1709998205250}`, at},
+		{"layout", time.DateTime, nil, `{"at":"2024-03-09 14:30:05","seen":"2024-03-09 15:30:05"}`, at.Truncate(time.Second)},
+		{"layout in location", time.DateTime, newYork, `{"at":"2024-03-09 09:30:05","seen":"2024-03-09 10:30:05"}`, at.Truncate(time.Second)},
+		{"unix in location", TimeUnix, newYork, `{"at":1709994605,"seen":1709998205}`, at.Truncate(time.Second)},
+		{"rfc 1123", time.RFC1123Z, newYork, `{"at":"Sat, 09 Mar 2024 09:30:05 -0500","seen":"Sat, 09 Mar 2024 10:30:05 -0500"}`, at.Truncate(time.Second)},
+		{"escaped layout", `"2006"`, nil, `{"at":"\"2024\"","seen":"\"2024\""}`, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := MarshalWithOptions(timeEvent{At: at, Seen: &seen}, &MarshalOptions{TimeFormat: tt.format, TimeLocation: tt.loc})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+
+			var got timeEvent
+			if err := UnmarshalWithOptions(data, &got, &Options{TimeFormat: tt.format, TimeLocation: tt.loc}); err != nil {
+				t.Fatal(err)
+			}
+			if !got.At.Equal(tt.back) || got.Seen == nil {
+				t.Errorf("Expected %v, got %+v", tt.back, got)
+			}
+			loc := tt.loc
+			if loc == nil {
+				loc = time.UTC
+			}
+			if got.At.Location() != loc {
+				t.Errorf("Expected the time in %v, got %v", loc, got.At.Location())
+			}
+		})
+	}
+}
+
+func TestTimeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+	}{
+		{"number for a layout", "", `{"at":1709994605}`},
+		{"string for unix", TimeUnix, `{"at":"2024-03-09T14:30:05Z"}`},
+		{"fraction for unix", TimeUnix, `{"at":1709994605.5}`},
+		{"object", TimeUnixMilli, `{"at":{}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v timeEvent
+			err := UnmarshalWithOptions([]byte(tt.input), &v, &Options{TimeFormat: tt.format})
+			var te *UnmarshalTypeError
+			if !errors.As(err, &te) || te.Type.String() != "time.Time" {
+				t.Errorf("Expected an UnmarshalTypeError for time.Time, got %v", err)
+			}
+		})
+	}
+
+	var v timeEvent
+	var pe *time.ParseError
+	if err := Unmarshal([]byte(`{"at":"yesterday"}`), &v); !errors.As(err, &pe) {
+		t.Errorf("Expected a *time.ParseError, got %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package simdjson
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/parser"
+	"github.com/biggeezerdevelopment/simdjson-go/internal/scanner"
+)
+
+// defaultDiscriminator is the member that selects the concrete type of an
+// interface value unless a type tag option names another.
+const defaultDiscriminator = "type"
+
+var (
+	unionsMu sync.Mutex
+	// Replaced, never modified, so lookups need no lock. Each interface
+	// type maps the discriminator values to the concrete types to decode,
+	// which are pointers if only the pointer implements the interface.
+	unions atomic.Pointer[map[reflect.Type]map[string]reflect.Type]
+)
+
+// RegisterType registers T as the concrete type that values of interface
+// type I are decoded into when the object being decoded has a "type"
+// member whose value is kind, so that polymorphic JSON can be decoded into
+// interface-typed fields, elements and map values. A field tagged with
+// type:key, such as
+//
+//	Shape Shape `json:"shape,type:kind"`
+//
+// selects by the member key instead, for the field itself or the elements
+// of a slice or map of I. The discriminator is decoded into T as any
+// other member, so a field of T that holds it is written back by Marshal.
+// Objects without the discriminator, or with a kind not registered, fail
+// with an UnmarshalTypeError.
+//
+// Either T or *T must implement I, which must not be the empty interface.
+// Like codecs, types are meant to be registered during initialization;
+// registering one later discards the cached decode plans of every type.
+func RegisterType[I, T any](kind string) {
+	it, ct := reflect.TypeFor[I](), reflect.TypeFor[T]()
+	if it.Kind() != reflect.Interface || it.NumMethod() == 0 {
+		panic("simdjson: RegisterType for " + it.String() + ", which is not a non-empty interface type")
+	}
+	if !ct.Implements(it) {
+		if !reflect.PointerTo(ct).Implements(it) {
+			panic("simdjson: RegisterType of " + ct.String() + ", which does not implement " + it.String())
+		}
+		ct = reflect.PointerTo(ct)
+	}
+
+	unionsMu.Lock()
+	defer unionsMu.Unlock()
+	m := make(map[reflect.Type]map[string]reflect.Type)
+	if old := unions.Load(); old != nil {
+		for k, v := range *old {
+			m[k] = v
+		}
+	}
+	kinds := make(map[string]reflect.Type, len(m[it])+1)
+	for k, v := range m[it] {
+		kinds[k] = v
+	}
+	kinds[kind] = ct
+	m[it] = kinds
+	unions.Store(&m)
+	// Plans built before now may have decoded I without the registry
+	resetPlans()
+}
+
+// unionFor returns the concrete types registered for interface type t by
+// discriminator value, or nil if there are none.
+func unionFor(t reflect.Type) map[string]reflect.Type {
+	m := unions.Load()
+	if m == nil || t.Kind() != reflect.Interface {
+		return nil
+	}
+	return (*m)[t]
+}
+
+// newDiscriminatedPlan returns the plan of a field tagged type:key: the
+// union plan of a registered interface type selecting by key, or of a
+// slice or string-keyed map of one, and the usual plan otherwise.
+func newDiscriminatedPlan(t reflect.Type, key string) decodeFunc {
+	switch {
+	case unionFor(t) != nil:
+		return newUnionPlan(t, unionFor(t), key)
+	case t.Kind() == reflect.Slice && unionFor(t.Elem()) != nil:
+		return newSliceOf(t, newUnionPlan(t.Elem(), unionFor(t.Elem()), key))
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && unionFor(t.Elem()) != nil:
+		return newMapOf(t, newUnionPlan(t.Elem(), unionFor(t.Elem()), key))
+	}
+	return planFor(t)
+}
+
+// variant is a concrete type of a union with its decode plan.
+type variant struct {
+	typ  reflect.Type
+	plan decodeFunc // plan of typ, or of its element if typ is a pointer
+}
+
+// newUnionPlan returns the plan of interface type t, which decodes objects
+// into the type registered in kinds for the value of their member key.
+func newUnionPlan(t reflect.Type, kinds map[string]reflect.Type, key string) decodeFunc {
+	variants := make(map[string]variant, len(kinds))
+	for kind, ct := range kinds {
+		elem := ct
+		if ct.Kind() == reflect.Ptr {
+			elem = ct.Elem()
+		}
+		variants[kind] = variant{ct, planFor(elem)}
+	}
+
+	return func(r *Reader, v reflect.Value) error {
+		if r.Null() {
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		if r.peek() != scanner.TokenObjectBegin {
+			return r.typeError(t)
+		}
+		kind, found, err := r.discriminator(key)
+		if err != nil {
+			return err
+		}
+		vr, ok := variants[string(kind)]
+		if !ok {
+			desc := "object without " + strconv.Quote(key)
+			if found {
+				desc = "object with " + strconv.Quote(key) + " " + strconv.Quote(string(kind))
+			}
+			return r.fail(&UnmarshalTypeError{Value: desc, Type: t, Offset: int64(r.tokens[r.pos].Start)})
+		}
+
+		if vr.typ.Kind() != reflect.Ptr {
+			x := reflect.New(vr.typ).Elem()
+			err := vr.plan(r, x)
+			v.Set(x)
+			return err
+		}
+		// A pointer of the same type already held is decoded into, as
+		// for an interface{}
+		if !v.IsNil() && v.Elem().Type() == vr.typ && !v.Elem().IsNil() {
+			return vr.plan(r, v.Elem().Elem())
+		}
+		p := reflect.New(vr.typ.Elem())
+		err = vr.plan(r, p.Elem())
+		v.Set(p)
+		return err
+	}
+}
+
+// discriminator returns the value of the string member key of the object
+// at r.pos, and whether it has one, without moving r. The first such
+// member counts if there are several.
+func (r *Reader) discriminator(key string) ([]byte, bool, error) {
+	start := r.pos
+	defer func() { r.pos = start }()
+
+	r.pos++
+	for r.More() {
+		k, err := r.Key()
+		if err != nil {
+			return nil, false, err
+		}
+		if string(k) == key && r.peek() == scanner.TokenString {
+			raw, escaped, err := r.stringBytes()
+			if err != nil || !escaped {
+				return raw, true, err
+			}
+			b, err := parser.AppendUnescaped(nil, raw)
+			if err != nil {
+				return nil, false, r.fail(err)
+			}
+			return b, true, nil
+		}
+		if err := r.Skip(); err != nil {
+			return nil, false, err
+		}
+	}
+	return nil, false, r.err
+}
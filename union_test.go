@@ -0,0 +1,147 @@
+package simdjson
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type shape interface{ area() float64 }
+
+type circle struct {
+	Type string  `json:"type,omitempty"`
+	R    float64 `json:"r"`
+}
+
+func (c circle) area() float64 { return 3 * c.R * c.R }
+
+type rect struct {
+	Type string  `json:"type,omitempty"`
+	W    float64 `json:"w"`
+	H    float64 `json:"h"`
+}
+
+func (r *rect) area() float64 { return r.W * r.H }
+
+func registerShapes() {
+	RegisterType[shape, circle]("circle")
+	RegisterType[shape, rect]("rect")
+}
+
+func TestRegisterType(t *testing.T) {
+	registerShapes()
+
+	var shapes []shape
+	data := `[{"r":1,"type":"circle"},{"type":"rect","w":2,"h":3},null,{"type":"circle","r":2}]`
+	if err := Unmarshal([]byte(data), &shapes); err != nil {
+		t.Fatal(err)
+	}
+	expected := []shape{circle{"circle", 1}, &rect{"rect", 2, 3}, nil, circle{"circle", 2}}
+	if !reflect.DeepEqual(shapes, expected) {
+		t.Errorf("Expected %v, got %v", expected, shapes)
+	}
+
+	// The discriminator round-trips through the field holding it
+	out, err := Marshal(shapes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again []shape
+	if err := Unmarshal(out, &again); err != nil || !reflect.DeepEqual(again, shapes) {
+		t.Errorf("Expected %s to decode as before, got %v (%v)", out, again, err)
+	}
+
+	var s shape
+	if err := Unmarshal([]byte(`{"type":"c\u0069rcle","r":1}`), &s); err != nil || s != (circle{"circle", 1}) {
+		t.Errorf("Expected an escaped discriminator to match, got %v (%v)", s, err)
+	}
+
+	// A pointer already held is decoded into
+	held := &rect{W: 5}
+	s = held
+	if err := Unmarshal([]byte(`{"type":"rect","h":4}`), &s); err != nil || s != held || held.area() != 20 {
+		t.Errorf("Expected the held rect to be decoded into, got %v (%v)", s, err)
+	}
+}
+
+func TestTypeTagOption(t *testing.T) {
+	registerShapes()
+
+	var doc struct {
+		Main   shape            `json:"main,type:kind"`
+		List   []shape          `json:"list,type:kind"`
+		Byname map[string]shape `json:"byname,type:kind"`
+		Plain  shape            `json:"plain"`
+	}
+	data := `{
+		"main": {"kind": "rect", "w": 1, "h": 2},
+		"list": [{"kind": "circle", "r": 1}],
+		"byname": {"a": {"r": 3, "kind": "circle"}},
+		"plain": {"type": "circle", "r": 4}
+	}`
+	if err := Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := doc.Main.(*rect); !ok || r.H != 2 {
+		t.Errorf("Expected a rect, got %#v", doc.Main)
+	}
+	if len(doc.List) != 1 || doc.List[0] != (circle{R: 1}) {
+		t.Errorf("Expected a circle in list, got %#v", doc.List)
+	}
+	if doc.Byname["a"] != (circle{R: 3}) {
+		t.Errorf("Expected a circle in byname, got %#v", doc.Byname)
+	}
+	if doc.Plain != (circle{"circle", 4}) {
+		t.Errorf("Expected a circle by type, got %#v", doc.Plain)
+	}
+}
+
+func TestRegisterTypeErrors(t *testing.T) {
+	registerShapes()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"type":"hexagon"}`, `cannot unmarshal object with "type" "hexagon" into simdjson.shape`},
+		{`{"r":1}`, `cannot unmarshal object without "type" into simdjson.shape`},
+		{`{"type":1}`, `cannot unmarshal object without "type" into simdjson.shape`},
+		{`[1]`, `cannot unmarshal array into simdjson.shape`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var s shape
+			err := Unmarshal([]byte(tt.input), &s)
+			var te *UnmarshalTypeError
+			if !errors.As(err, &te) || err.Error() != tt.expected {
+				t.Errorf("Expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	opts := DefaultOptions()
+	opts.CollectErrors = true
+	var shapes []shape
+	err := UnmarshalWithOptions([]byte(`[{"type":"circle","r":1},{"type":"blob"},{"type":"rect","w":"x"}]`), &shapes, &opts)
+	var errs DecodeErrors
+	if !errors.As(err, &errs) || len(errs) != 2 || errs[0].Path != "/1" || errs[1].Path != "/2/w" {
+		t.Errorf("Expected errors at /1 and /2/w, got %v", err)
+	}
+	if len(shapes) != 3 || shapes[0] != (circle{"circle", 1}) {
+		t.Errorf("Expected the valid shapes decoded, got %v", shapes)
+	}
+
+	for name, register := range map[string]func(){
+		"empty interface": func() { RegisterType[interface{}, circle]("circle") },
+		"not implemented": func() { RegisterType[shape, struct{}]("x") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for %s", name)
+				}
+			}()
+			register()
+		}()
+	}
+}
@@ -0,0 +1,73 @@
+package simdjson
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestValidateWithError(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		offset   int64
+		line     int
+		column   int
+		expected string
+	}{
+		{"empty", ``, 0, 1, 1, "value"},
+		{"missing colon", `{"a" 1}`, 5, 1, 6, "':'"},
+		{"missing comma", `[1 2]`, 3, 1, 4, "',' or ']'"},
+		{"trailing comma", `{"a":1,}`, 7, 1, 8, "string key"},
+		{"unclosed array", `[1,2`, 4, 1, 5, "',' or ']'"},
+		{"bad escape", `"a\x"`, 3, 1, 4, "escape character"},
+		{"bad number", `[12.]`, 4, 1, 5, "digit"},
+		{"trailing data", `{} x`, 3, 1, 4, "end of input"},
+		{"multiline", "{\n  \"a\": tru\n}", 12, 2, 11, "literal true, false or null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWithError([]byte(tt.input))
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Expected *SyntaxError, got %v", err)
+			}
+			if syntaxErr.Offset != tt.offset || syntaxErr.Line != tt.line || syntaxErr.Column != tt.column {
+				t.Errorf("Got offset=%d line=%d column=%d, expected offset=%d line=%d column=%d",
+					syntaxErr.Offset, syntaxErr.Line, syntaxErr.Column, tt.offset, tt.line, tt.column)
+			}
+			if syntaxErr.Expected != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, syntaxErr.Expected)
+			}
+			if !errors.Is(err, ErrInvalidJSON) {
+				t.Errorf("Expected error to match ErrInvalidJSON")
+			}
+		})
+	}
+}
+
+func TestValidateWithErrorMatchesValid(t *testing.T) {
+	for _, input := range reportCases {
+		data := []byte(input)
+		err := ValidateWithError(data)
+		if (err == nil) != Valid(data) {
+			t.Errorf("ValidateWithError(%q) = %v, Valid = %v", input, err, Valid(data))
+		}
+		if (err == nil) != json.Valid(data) {
+			t.Errorf("ValidateWithError(%q) = %v, std valid = %v", input, err, json.Valid(data))
+		}
+	}
+}
+
+func TestValidAllocations(t *testing.T) {
+	data := []byte(`{"users":[{"id":1,"name":"Alice","tags":["a","b"]},{"id":2,"name":"Bob"}]}`)
+	Valid(data)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		Valid(data)
+	})
+	if allocs != 0 {
+		t.Errorf("Valid allocated %.1f times per call, expected 0", allocs)
+	}
+}
@@ -0,0 +1,132 @@
+package simdjson
+
+import (
+	"iter"
+	"strings"
+)
+
+// Value is a node of a document parsed by Parser.Parse. It is valid as
+// long as the Result it came from, and in zero-copy mode only while the
+// input is unchanged; Detach copies it out of both. A Value can also be
+// decoded into, as the values of the map[string][]Value that
+// Options.CollectDuplicateKeys fills are, and encodes as its node.
+type Value struct {
+	x interface{}
+}
+
+// Root returns the root of the document as a Value.
+func (r *Result) Root() Value {
+	return Value{r.value}
+}
+
+// Interface returns the node as a map[string]interface{}, []interface{},
+// string, int64, float64, bool or nil, still owned by the Result. Objects
+// are *Object instead of maps if Options.PreserveKeyOrder was set.
+func (v Value) Interface() interface{} {
+	return v.x
+}
+
+// Get returns the member key of an object, or a null Value if v is not an
+// object or has no such member.
+func (v Value) Get(key string) Value {
+	if obj, ok := v.x.(*Object); ok {
+		val, _ := obj.Get(key)
+		return Value{val}
+	}
+	obj, _ := v.x.(map[string]interface{})
+	return Value{obj[key]}
+}
+
+// Index returns element i of an array, or a null Value if v is not an
+// array or i is out of range.
+func (v Value) Index(i int) Value {
+	arr, _ := v.x.([]interface{})
+	if i < 0 || i >= len(arr) {
+		return Value{}
+	}
+	return Value{arr[i]}
+}
+
+// Fields returns an iterator over the members of an object, in no
+// particular order unless it is an *Object. It yields nothing if v is not
+// an object.
+func (v Value) Fields() iter.Seq2[string, Value] {
+	return func(yield func(string, Value) bool) {
+		if obj, ok := v.x.(*Object); ok {
+			for k, val := range obj.All() {
+				if !yield(k, Value{val}) {
+					return
+				}
+			}
+			return
+		}
+		obj, _ := v.x.(map[string]interface{})
+		for k, val := range obj {
+			if !yield(k, Value{val}) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the elements of an array and their
+// indexes. It yields nothing if v is not an array.
+func (v Value) Values() iter.Seq2[int, Value] {
+	return func(yield func(int, Value) bool) {
+		arr, _ := v.x.([]interface{})
+		for i, val := range arr {
+			if !yield(i, Value{val}) {
+				return
+			}
+		}
+	}
+}
+
+// UnmarshalJSONFrom decodes the next value into v as Unmarshal decodes
+// into an interface{}.
+func (v *Value) UnmarshalJSONFrom(r *Reader) error {
+	x, err := r.value()
+	v.x = x
+	return err
+}
+
+// MarshalJSONTo encodes the node as Marshal encodes it.
+func (v Value) MarshalJSONTo(w *Writer) error {
+	return w.Value(v.x)
+}
+
+// Detach returns a deep copy of the node that owns all of its memory: it
+// stays valid after the Result is released and the input is reused.
+func (v Value) Detach() interface{} {
+	return detach(v.x)
+}
+
+func detach(x interface{}) interface{} {
+	// Every case builds a new interface{}; the originals may be boxed in
+	// arena memory
+	switch x := x.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, val := range x {
+			m[strings.Clone(k)] = detach(val)
+		}
+		return m
+	case *Object:
+		return detachObject(x)
+	case []interface{}:
+		arr := make([]interface{}, len(x))
+		for i, val := range x {
+			arr[i] = detach(val)
+		}
+		return arr
+	case string:
+		return strings.Clone(x)
+	case int64:
+		return x
+	case float64:
+		return x
+	case bool:
+		return x
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package simdjson
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+
+	"github.com/biggeezerdevelopment/simdjson-go/internal/jsonenc"
+)
+
+// MarshalerTo is implemented by types that encode themselves to a Writer
+// without reflection, such as those generated by cmd/simdjson-gen.
+type MarshalerTo interface {
+	MarshalJSONTo(w *Writer) error
+}
+
+var marshalerToType = reflect.TypeOf((*MarshalerTo)(nil)).Elem()
+
+// Appender is implemented by types that append their own JSON encoding to
+// the encoder's buffer, for hot types that know their form: unlike a
+// MarshalJSON method they return no slice of their own, so encoding them
+// allocates nothing. AppendJSON must append exactly one valid JSON value
+// to dst and return the extended slice; the output is not checked.
+// Marshal and Encoder call it for every value of the type, or of a pointer
+// to it if the method has a pointer receiver and the value is addressable,
+// after codecs and before any other method or reflection.
+type Appender interface {
+	AppendJSON(dst []byte) ([]byte, error)
+}
+
+var appenderType = reflect.TypeOf((*Appender)(nil)).Elem()
+
+// Writer appends JSON to the output of Marshal. It is the encoding half of
+// the API used by generated code, which writes punctuation and pre-quoted
+// keys with RawByte and RawString and values with the typed methods. A
+// Writer is only valid during the MarshalJSONTo call it is passed to.
+type Writer struct {
+	e *encoder
+}
+
+// RawByte appends c verbatim.
+func (w *Writer) RawByte(c byte) {
+	w.e.buf = append(w.e.buf, c)
+}
+
+// RawString appends s verbatim.
+func (w *Writer) RawString(s string) {
+	w.e.buf = append(w.e.buf, s...)
+}
+
+// String appends s as a quoted, escaped JSON string.
+func (w *Writer) String(s string) {
+	w.e.encodeString(s)
+}
+
+// Bytes appends b in MarshalOptions.BytesFormat, a base64-encoded string
+// by default. A nil b is written as null, or as empty if
+// MarshalOptions.NilAsEmpty is set.
+func (w *Writer) Bytes(b []byte) {
+	if b == nil {
+		w.e.encodeNil(reflect.Slice, true)
+		return
+	}
+	w.e.encodeBytes(b)
+}
+
+// BytesAs appends b like Bytes, but in format f. Generated code uses it
+// for fields with a format tag option.
+func (w *Writer) BytesAs(b []byte, f BytesFormat) {
+	format := w.e.bytesFormat
+	w.e.bytesFormat = f
+	w.Bytes(b)
+	w.e.bytesFormat = format
+}
+
+// Int appends a signed integer.
+func (w *Writer) Int(i int64) {
+	w.e.buf = strconv.AppendInt(w.e.buf, i, 10)
+}
+
+// Uint appends an unsigned integer.
+func (w *Writer) Uint(u uint64) {
+	w.e.buf = strconv.AppendUint(w.e.buf, u, 10)
+}
+
+// Float appends f formatted for a float of bitSize (32 or 64) bits, with
+// MarshalOptions.FloatPrecision digits after the point if set. NaN and
+// infinities cannot be represented in JSON and return an error.
+func (w *Writer) Float(f float64, bitSize int) error {
+	return w.e.encodeFloat(f, bitSize)
+}
+
+// FloatPrec appends f like Float, but with prec digits after the point.
+// Generated code uses it for fields with a prec tag option.
+func (w *Writer) FloatPrec(f float64, bitSize, prec int) error {
+	p := w.e.floatPrec
+	w.e.floatPrec = prec
+	err := w.e.encodeFloat(f, bitSize)
+	w.e.floatPrec = p
+	return err
+}
+
+// Number appends s, which must be a number in the JSON grammar, as it is
+// written, for values that have their own decimal form.
+func (w *Writer) Number(s string) error {
+	if !jsonenc.ValidNumber([]byte(s)) {
+		return errors.New("invalid number " + strconv.Quote(s))
+	}
+	w.e.buf = append(w.e.buf, s...)
+	return nil
+}
+
+// Bool appends true or false.
+func (w *Writer) Bool(b bool) {
+	w.e.encodeBool(b)
+}
+
+// Null appends null.
+func (w *Writer) Null() {
+	w.e.buf = append(w.e.buf, "null"...)
+}
+
+// NilSlice appends a nil slice: null, or [] if MarshalOptions.NilAsEmpty
+// is set.
+func (w *Writer) NilSlice() {
+	w.e.encodeNil(reflect.Slice, false)
+}
+
+// NilMap appends a nil map: null, or {} if MarshalOptions.NilAsEmpty is
+// set.
+func (w *Writer) NilMap() {
+	w.e.encodeNil(reflect.Map, false)
+}
+
+// Value appends v using reflection, exactly as Marshal would. It is the
+// fallback generated code uses for types it has no fast path for.
+func (w *Writer) Value(v interface{}) error {
+	return w.e.encode(reflect.ValueOf(v))
+}